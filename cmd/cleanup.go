@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanupMode   string
+	cleanupForce  bool
+	cleanupDryRun bool
+)
+
+// cleanupCmd represents the cleanup command
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Delete or empty managed Beyond Identity groups that are no longer synced",
+	Long: `Lists the Beyond Identity groups of every target whose name starts with the target's group
+prefix, finds those no configured group syncs any more, because their Google group was deleted
+or removed from sync.groups, and deletes them, or removes all their members with --mode empty.
+The groups are listed first and nothing changes until the cleanup is confirmed; --force skips
+the confirmation, e.g. in scripts.
+
+Example:
+  scim-sync cleanup --dry-run
+  scim-sync cleanup --mode empty --force`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCleanup(cmd.Context(), os.Stdin)
+	},
+}
+
+func init() {
+	cleanupCmd.Flags().StringVar(&cleanupMode, "mode", "", "delete or empty the orphaned groups (default sync.orphaned_groups.cleanup, or delete)")
+	cleanupCmd.Flags().BoolVar(&cleanupForce, "force", false, "clean up without asking for confirmation")
+	cleanupCmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "list the orphaned groups without changing them")
+	rootCmd.AddCommand(cleanupCmd)
+}
+
+// runCleanup lists the orphaned groups and, once confirmed on stdin, cleans them up
+func runCleanup(ctx context.Context, stdin io.Reader) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	mode := cleanupMode
+	if mode == "" {
+		mode = cfg.Sync.OrphanedGroups.Cleanup
+	}
+	if mode == "" {
+		mode = config.OrphanCleanupDelete
+	}
+	if mode != config.OrphanCleanupDelete && mode != config.OrphanCleanupEmpty {
+		return fmt.Errorf("invalid --mode %q: expected %s or %s", mode, config.OrphanCleanupDelete, config.OrphanCleanupEmpty)
+	}
+
+	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	// Google Workspace is only read, to tell which configured groups still exist
+	gwsClient, err := sync.NewGWSClient(cfg, httpClient, log)
+	if err != nil {
+		return fmt.Errorf("failed to create Google Workspace client: %w", err)
+	}
+	biClient := sync.NewPrimaryClient(cfg, httpClient)
+
+	engine := sync.NewEngine(gwsClient, biClient, cfg, log)
+	if err := engine.RegisterTargets(httpClient); err != nil {
+		return fmt.Errorf("failed to create target clients: %w", err)
+	}
+	if err := engine.ConfigureSource(httpClient); err != nil {
+		return fmt.Errorf("failed to configure membership source: %w", err)
+	}
+	if err := engine.ConfigureState(); err != nil {
+		return fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	orphaned, err := engine.FindOrphanedGroups(ctx)
+	if err != nil {
+		return err
+	}
+	if len(orphaned) == 0 {
+		fmt.Println("No orphaned groups found.")
+		return nil
+	}
+	if err := writeOrphanedGroups(orphaned); err != nil {
+		return err
+	}
+
+	if !cleanupDryRun && !cleanupForce {
+		action := "Delete"
+		if mode == config.OrphanCleanupEmpty {
+			action = "Remove all members of"
+		}
+		fmt.Printf("\n%s these %d groups? [y/N] ", action, len(orphaned))
+		answer, _ := bufio.NewReader(stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Cleanup cancelled.")
+			return nil
+		}
+	}
+
+	results, cleanupErr := engine.CleanupOrphanedGroups(ctx, orphaned, mode, cleanupDryRun)
+	fmt.Println()
+	if err := writeOrphanedGroups(results); err != nil {
+		return err
+	}
+	return cleanupErr
+}
+
+// writeOrphanedGroups prints the orphaned groups, with the outcome of their cleanup once known
+func writeOrphanedGroups(groups []sync.OrphanedGroup) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tGROUP\tID\tMEMBERS\tREASON\tSTATUS")
+	for _, g := range groups {
+		status := g.Status
+		if status == "" {
+			status = "orphaned"
+		}
+		if g.Err != nil {
+			status += ": " + g.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", g.Target, g.Name, g.GroupID, g.Members, g.Reason, status)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configMigrateDryRun bool
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+	// Leave the file alone so migrate can report on and preview the original layout
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+}
+
+// configMigrateCmd represents the config migrate subcommand
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the configuration file to the current schema",
+	Long: `Upgrade an older configuration layout to the current schema version (config_version),
+keeping comments and ${VAR} references. The original file is copied next to it as
+<file>.v<version>-<timestamp>.bak before it is rewritten.
+
+Other commands perform the same upgrade automatically when they load an older file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigMigrate()
+	},
+}
+
+func init() {
+	configMigrateCmd.Flags().BoolVar(&configMigrateDryRun, "dry-run", false, "print the upgraded configuration instead of writing it")
+
+	configCmd.AddCommand(configMigrateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// runConfigMigrate upgrades the configuration file in place
+func runConfigMigrate() error {
+	if cfgFile == "" {
+		return fmt.Errorf("no config file found")
+	}
+
+	result, err := config.MigrateFile(cfgFile, configMigrateDryRun)
+	if err != nil {
+		return err
+	}
+	if !result.Changed() {
+		fmt.Printf("Configuration file '%s' is already at version %d\n", cfgFile, result.To)
+		return nil
+	}
+
+	if configMigrateDryRun {
+		fmt.Fprintf(os.Stderr, "Would upgrade '%s' from version %d to %d:\n", cfgFile, result.From, result.To)
+		for _, step := range result.Applied {
+			fmt.Fprintf(os.Stderr, "  - %s\n", step)
+		}
+		fmt.Print(string(result.Data))
+		return nil
+	}
+
+	fmt.Printf("Upgraded '%s' from version %d to %d\n", cfgFile, result.From, result.To)
+	for _, step := range result.Applied {
+		fmt.Printf("  - %s\n", step)
+	}
+	fmt.Printf("Original saved to %s\n", result.Backup)
+	return nil
+}
+
+// autoMigrateConfig upgrades an older configuration file when a command loads it; the loaded
+// configuration is already upgraded in memory, so a read-only file only earns a warning
+func autoMigrateConfig() {
+	if cfg == nil || cfgFile == "" {
+		return
+	}
+
+	result, err := config.MigrateFile(cfgFile, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: config file %s uses an older layout and could not be upgraded: %v\n", cfgFile, err)
+		fmt.Fprintln(os.Stderr, "WARNING: run 'scim-sync config migrate' once the file is writable")
+		return
+	}
+	if result.Changed() {
+		fmt.Fprintf(os.Stderr, "Upgraded config file %s from version %d to %d; original saved to %s\n",
+			cfgFile, result.From, result.To, result.Backup)
+	}
+}
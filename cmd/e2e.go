@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/setup"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+var e2eReportPath string
+
+// e2eCmd represents the e2e command
+var e2eCmd = &cobra.Command{
+	Use:   "e2e",
+	Short: "Run an end-to-end sync scenario against sandbox tenants",
+	Long: `Create a canary Google group (scim-sync-e2e-<timestamp>) with the users listed in e2e.members,
+sync it, check the Beyond Identity group and users, remove a member, sync again and check the
+removal, then delete the groups and the users the sync created. The steps are written to a
+JUnit XML report. Run it with a config file pointing at sandbox tenants, e.g. --config e2e.yaml;
+it writes to both tenants, so app.test_mode and read-only mode must be off.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runE2E(cmd.Context())
+	},
+}
+
+func init() {
+	e2eCmd.Flags().StringVar(&e2eReportPath, "report", "e2e-report.xml", "write the steps to this file as a JUnit XML report")
+	rootCmd.AddCommand(e2eCmd)
+}
+
+// runE2E executes the end-to-end scenario
+func runE2E(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	if len(cfg.E2E.Members) < 2 {
+		return fmt.Errorf("e2e.members must list at least two sandbox users")
+	}
+	if cfg.App.TestMode || cfg.App.ReadOnly || len(cfg.Sync.TestModeOperations) > 0 {
+		return fmt.Errorf("e2e writes to both tenants: disable app.test_mode, app.read_only and sync.test_mode_operations")
+	}
+	if !cfg.Source.ReadsGoogleGroups() {
+		return fmt.Errorf("e2e syncs its canary group from Google Workspace: remove source.type: %s", cfg.Source.Type)
+	}
+	if cfg.GoogleWorkspace.API != "" && cfg.GoogleWorkspace.API != config.GWSAPIAdminSDK {
+		return fmt.Errorf("e2e creates its canary group with the Admin SDK: set google_workspace.api: %s", config.GWSAPIAdminSDK)
+	}
+
+	log := logger.Setup(cfg.App.LogLevel, false)
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	googleClient, err := gws.NewClientWithHTTPClient(cfg.GoogleWorkspace.ServiceAccountKeyPath, cfg.GoogleWorkspace.Domain, cfg.GoogleWorkspace.SuperAdminEmail, httpClient)
+	if err != nil {
+		return fmt.Errorf("failed to create Google Workspace client: %w", err)
+	}
+	biClient := sync.NewPrimaryClient(cfg, httpClient)
+
+	// Sync only the canary group, with its state kept in memory
+	e2e := setup.NewE2E(googleClient, biClient, cfg, time.Now())
+	cfg.Sync.Groups = []string{e2e.GroupEmail()}
+	cfg.Sync.OrgUnits = nil
+	cfg.Sync.AllUsers = false
+	cfg.Sync.AdminGroups = false
+	cfg.Sync.GroupTargets = nil
+	cfg.Sync.Incremental.Enabled = false
+
+	gwsClient, err := sync.NewGWSClient(cfg, httpClient, log)
+	if err != nil {
+		return fmt.Errorf("failed to create Google Workspace client: %w", err)
+	}
+	engine := sync.NewEngine(gwsClient, biClient, cfg, log)
+	engine.SetVersion(version)
+	e2e.SetSyncer(engine)
+
+	summary := e2e.Run(ctx)
+
+	file, err := os.Create(e2eReportPath)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	if err := setup.WriteJUnit(file, "scim-sync-e2e", summary, time.Now()); err != nil {
+		return err
+	}
+	fmt.Printf("📄 Wrote JUnit report to %s\n", e2eReportPath)
+
+	if summary.OverallStatus != "PASS" {
+		return fmt.Errorf("end-to-end test failed: %d of %d steps failed", summary.Failed, summary.TotalChecks)
+	}
+	return nil
+}
@@ -1,23 +1,45 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
-	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/server"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/setup"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/telemetry"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/wizard"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile string
-	cfg     *config.Config
+	cfgFile        string
+	cfgProfile     string
+	injectFaults   string
+	docsDeploy     bool
+	docsFormat     string
+	cfg            *config.Config
+	captureHTTPDir string
+	runDryRun      bool
+	runFull        bool
+	runEnrollment  bool
+	runUser        string
+	runGroup       string
+	runExplain     string
+	runReportPath  string
+	runFailOnError bool
 
 	// Build information (set via ldflags)
 	version = "dev"
@@ -25,6 +47,50 @@ var (
 	date    = "unknown"
 )
 
+// Exit codes of the process
+const (
+	exitFatal               = 1   // A command failed, or a run could not complete
+	exitCompletedWithErrors = 2   // A run completed, but some groups or users failed; with run --fail-on-error
+	exitInterrupted         = 130 // A run was stopped by Ctrl-C or SIGTERM after finishing its current group
+)
+
+// exitError makes the process exit with code instead of exitFatal
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// exitCode returns the code the process exits with after a command returned err
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFatal
+}
+
+// runExitError returns what a one-shot run that ended with result and err exits with: a run
+// stopped by an interrupt exits with exitInterrupted, a failed one with exitFatal, and one that
+// completed with failed groups or users with exitCompletedWithErrors when failOnError is set,
+// and otherwise with 0
+func runExitError(result *sync.SyncResult, err error, failOnError bool) error {
+	switch {
+	case err != nil && result != nil && result.Stopped:
+		return &exitError{code: exitInterrupted, err: err}
+	case err != nil:
+		return err
+	case failOnError && result != nil && len(result.Errors) > 0:
+		return &exitError{code: exitCompletedWithErrors, err: fmt.Errorf("sync completed with %d errors", len(result.Errors))}
+	}
+	return nil
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "scim-sync",
@@ -35,6 +101,10 @@ to Beyond Identity using SCIM protocol.
 This application supports two modes:
 - One-shot mode: Run synchronization once and exit
 - Server mode: Run continuously with scheduled synchronization and HTTP API`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		autoMigrateConfig()
+		return configureFaults()
+	},
 }
 
 // runCmd represents the run command
@@ -42,9 +112,14 @@ var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run SCIM synchronization once",
 	Long: `Run a single synchronization operation from Google Workspace to Beyond Identity.
-This will sync all configured groups and their members.`,
+This will sync all configured groups and their members. With --enrollment-only, nothing is
+provisioned and only the enrollment group is refreshed from current passkey status. With
+--group, only that configured group is synced and the rest are skipped.
+
+Exits with 0 when the run succeeds and 1 when it fails or cannot start. A run that completes
+with failed groups or users also exits with 0, or with 2 when --fail-on-error is set.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSync()
+		return runSync(cmd.Context())
 	},
 }
 
@@ -93,17 +168,21 @@ var setupValidateCmd = &cobra.Command{
 	Short: "Validate current setup and connectivity",
 	Long:  `Validate configuration file, environment variables, and test connectivity to external services.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSetupValidation()
+		return runSetupValidation(cmd.Context())
 	},
 }
 
 // setupDocsCmd represents the setup docs subcommand
 var setupDocsCmd = &cobra.Command{
-	Use:   "docs",
+	Use:   "docs [output-dir]",
 	Short: "Generate setup and API documentation",
-	Long:  `Generate comprehensive documentation including setup guide, API reference, and troubleshooting.`,
+	Long: `Generate comprehensive documentation including setup guide, API reference, and troubleshooting,
+populated with the endpoints, groups, port and prefix of the loaded configuration (or examples if
+none is found), as markdown or html. With --deploy, also write a systemd unit, docker-compose service and crontab line populated with
+the config file path, port and schedule to the deploy subdirectory.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runDocsGeneration()
+		return runDocsGeneration(args)
 	},
 }
 
@@ -122,8 +201,32 @@ var versionCmd = &cobra.Command{
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	// Identify this build in the User-Agent of every API request
+	httpclient.SetVersion(version, commit)
+	server.SetBuildInfo(version, commit, date)
+
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgProfile, "profile", "", "profile from the config file's profiles section (default $"+config.ProfileEnvVar+")")
+
+	// Fault injection for integration tests and alerting drills; hidden so it is not used by accident
+	rootCmd.PersistentFlags().StringVar(&injectFaults, "inject-faults", "", "inject API failures, e.g. api=google,429=0.2,5xx=0.05,latency=500ms,partial=0.5,seed=42")
+	_ = rootCmd.PersistentFlags().MarkHidden("inject-faults")
+
+	// Run flags
+	runCmd.Flags().StringVar(&captureHTTPDir, "capture-http", "", "write sanitized API request/response pairs to this directory")
+	runCmd.Flags().BoolVar(&runDryRun, "dry-run", false, "plan the changes without making them (enables test mode) and print them as a table")
+	runCmd.Flags().StringVar(&runReportPath, "report", "", "with --dry-run, also write the planned changes to this file as JSON")
+	runCmd.Flags().BoolVar(&runFull, "full", false, "sync every configured group even when sync.incremental is enabled")
+	runCmd.Flags().BoolVar(&runEnrollment, "enrollment-only", false, "only refresh the enrollment group from passkey status, without provisioning")
+	runCmd.Flags().StringVar(&runGroup, "group", "", "sync only this configured group, skipping the rest")
+	runCmd.MarkFlagsMutuallyExclusive("full", "enrollment-only", "group")
+	runCmd.Flags().StringVar(&runExplain, "explain", "", "print every decision the run makes about this user, e.g. with --dry-run to see why they would not be synced")
+	runCmd.Flags().BoolVar(&runFailOnError, "fail-on-error", false, "exit with code 2 when the run completes but any group or user failed")
+
+	// Docs flags
+	setupDocsCmd.Flags().BoolVar(&docsDeploy, "deploy", false, "also write deployment files (systemd unit, docker-compose service, crontab)")
+	setupDocsCmd.Flags().StringVar(&docsFormat, "format", setup.DocsFormatMarkdown, "output format: markdown or html")
 
 	// Add setup subcommands
 	setupCmd.AddCommand(setupWizardCmd)
@@ -138,13 +241,27 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 }
 
+// configureFaults makes every API client inject the failures given with --inject-faults
+func configureFaults() error {
+	if injectFaults == "" {
+		return nil
+	}
+	faults, err := httpclient.ParseFaults(injectFaults)
+	if err != nil {
+		return fmt.Errorf("invalid --inject-faults: %w", err)
+	}
+	httpclient.SetFaults(faults)
+	fmt.Fprintf(os.Stderr, "WARNING: injecting faults into API requests (%s)\n", faults)
+	return nil
+}
+
 // initConfig reads in config file and ENV variables
 func initConfig() {
 	var err error
 
 	if cfgFile != "" {
 		// Use config file from the flag
-		cfg, err = config.Load(cfgFile)
+		cfg, err = config.LoadProfile(cfgFile, cfgProfile)
 	} else {
 		// Find config file in standard locations
 		cfgFile, err = config.FindConfigFile()
@@ -152,7 +269,7 @@ func initConfig() {
 			// Only exit on run command, not on other commands
 			return
 		}
-		cfg, err = config.Load(cfgFile)
+		cfg, err = config.LoadProfile(cfgFile, cfgProfile)
 	}
 
 	if err != nil {
@@ -162,10 +279,16 @@ func initConfig() {
 
 	// Set defaults
 	cfg.SetDefaults()
+
+	// Disabling certificate verification exposes API tokens, so say so on every command
+	if cfg.Network.InsecureSkipVerify {
+		fmt.Fprintln(os.Stderr, "WARNING: network.insecure_skip_verify is enabled. TLS certificates are NOT verified and API tokens")
+		fmt.Fprintln(os.Stderr, "WARNING: can be intercepted. Use network.ca_bundle to trust a TLS-intercepting proxy instead.")
+	}
 }
 
 // runSync executes the main synchronization logic
-func runSync() error {
+func runSync(ctx context.Context) error {
 	if cfg == nil {
 		return fmt.Errorf("configuration not loaded")
 	}
@@ -175,47 +298,310 @@ func runSync() error {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	if !cfg.RunsOneShot() {
+		return fmt.Errorf("app.mode is %s: trigger runs with POST /sync on the server, or set app.mode: %s", cfg.App.Mode, config.DeploymentModeHybrid)
+	}
+
+	if err := applyRunFlags(); err != nil {
+		return err
+	}
+
 	// Setup logger
 	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
 
+	// Secrets on disk must not be readable by other users
+	if err := checkSecretPermissions(log); err != nil {
+		return err
+	}
+
 	// Log process start info
+	logger.LogStartup(log, logger.NewStartup(cfg, logger.ModeRun, version, commit, cfgFile))
 	logger.LogProcessStart(log, cfg.Sync.Groups, cfg.App.LogLevel)
 	log.Info("Starting main sync process")
 
-	// Create Google Workspace client
-	gwsClient, err := gws.NewClient(
-		cfg.GoogleWorkspace.ServiceAccountKeyPath,
-		cfg.GoogleWorkspace.Domain,
-		cfg.GoogleWorkspace.SuperAdminEmail,
-	)
+	// Build the HTTP client shared by both API clients
+	httpOpts, err := httpclient.OptionsFromConfig(cfg)
+	if err != nil {
+		log.Errorf("Failed to create HTTP client: %v", err)
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	if captureHTTPDir != "" {
+		if err := os.MkdirAll(captureHTTPDir, 0700); err != nil {
+			return fmt.Errorf("failed to create HTTP capture directory: %w", err)
+		}
+		log.Infof("Capturing sanitized HTTP traffic to %s", captureHTTPDir)
+		httpOpts.CaptureDir = captureHTTPDir
+	}
+
+	// Publish run, group and API call measurements to the configured metrics sinks
+	sinks := &sync.MetricsSinks{}
+	prometheus, err := telemetry.FromConfig(cfg.Metrics, sinks)
+	if err != nil {
+		log.Errorf("Failed to create metrics sinks: %v", err)
+		return fmt.Errorf("failed to create metrics sinks: %w", err)
+	}
+	if prometheus != nil && cfg.Metrics.Prometheus.TextfilePath != "" {
+		defer writePrometheusTextfile(log, prometheus, cfg.Metrics.Prometheus.TextfilePath)
+	}
+	httpOpts.Observer = sinks.ObserveHTTP
+	httpClient := httpclient.New(httpOpts)
+
+	// Create Google Workspace client for the configured group API, timing its requests by method
+	gwsCalls := sync.NewGWSCallMetrics(cfg, log)
+	gwsClient, err := sync.NewGWSClientWithCallMetrics(cfg, httpClient, gwsCalls, log)
 	if err != nil {
 		log.Errorf("Failed to create Google Workspace client: %v", err)
 		return fmt.Errorf("failed to create Google Workspace client: %w", err)
 	}
 
 	// Create Beyond Identity client
-	biClient := bi.NewClient(cfg.BeyondIdentity.APIToken, cfg.BeyondIdentity.SCIMBaseURL, cfg.BeyondIdentity.NativeAPIURL)
+	biClient := sync.NewPrimaryClient(cfg, httpClient)
 
 	// Create sync engine
 	engine := sync.NewEngine(gwsClient, biClient, cfg, log)
+	engine.SetMetricsSinks(sinks)
+	engine.SetVersion(version)
+
+	// Record the anonymous usage report, sent only when telemetry.enabled is set
+	engine.OnSyncFinished(telemetry.NewUsage(cfg, httpClient, version, telemetry.UsageModeRun, log).RunFinished)
+
+	// Register clients for any additional provisioning targets
+	if err := engine.RegisterTargets(httpClient); err != nil {
+		log.Errorf("Failed to create target clients: %v", err)
+		return fmt.Errorf("failed to create target clients: %w", err)
+	}
+
+	// Read membership from a CSV export instead of Google groups when configured
+	if err := engine.ConfigureSource(httpClient); err != nil {
+		log.Errorf("Failed to configure membership source: %v", err)
+		return fmt.Errorf("failed to configure membership source: %w", err)
+	}
+
+	// Load group mappings and orphaned groups from previous runs
+	if err := engine.ConfigureState(); err != nil {
+		log.Errorf("Failed to load sync state: %v", err)
+		return fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	// Read the audit log so runs can skip unchanged groups when sync.incremental is enabled
+	if err := engine.ConfigureIncremental(httpClient); err != nil {
+		log.Errorf("Failed to configure incremental sync: %v", err)
+		return fmt.Errorf("failed to configure incremental sync: %w", err)
+	}
+
+	// Clean up after a previous run that crashed holding the sync lock
+	if _, err := engine.Recover(); err != nil {
+		log.Errorf("Failed to recover from a previous crash: %v", err)
+		return fmt.Errorf("failed to recover from a previous crash: %w", err)
+	}
+
+	// Report missing API permissions before the first write fails
+	engine.LogCapabilities(ctx)
+
+	// Record the decisions made about one user, printed once the run finishes
+	engine.Explain(runExplain)
 
 	// Run synchronization
-	result, err := engine.Sync()
+	result, err := runStoppable(ctx, log, engine, selectRun(engine))
+	sync.LogGWSCalls(log, gwsCalls)
+	if runDryRun && result != nil {
+		if reportErr := writeDryRunReport(log, result, engine.PlanHash()); reportErr != nil {
+			return reportErr
+		}
+	}
+	if runExplain != "" && result != nil {
+		if explainErr := report.WriteExplanation(os.Stdout, runExplain, result.Explanation); explainErr != nil {
+			return explainErr
+		}
+	}
+	if err != nil && result != nil && result.Stopped {
+		log.Warnf("Sync stopped by interrupt after %d groups; the next run picks up the rest", result.GroupsProcessed)
+		return runExitError(result, err, runFailOnError)
+	}
 	if err != nil {
 		log.Errorf("Sync process failed: %v", err)
-		return err
+		if result != nil {
+			logErrorSummary(log, result)
+		}
+		return runExitError(result, err, runFailOnError)
 	}
 
 	// Log final results
+	for _, group := range result.OrphanedGroups {
+		log.Warnf("Group %s was deleted from the source and will not be retried until the sync configuration changes", group)
+	}
+	if len(result.GroupsCleanedUp) > 0 {
+		log.Infof("Cleaned up %d orphaned groups (%s): %s", len(result.GroupsCleanedUp), cfg.Sync.OrphanedGroups.Cleanup, strings.Join(result.GroupsCleanedUp, ", "))
+	}
+	for _, step := range result.SkippedSteps {
+		log.Warnf("Degraded run: %s", step)
+	}
+	for _, group := range result.PartialGroups {
+		log.Warnf("Group %s was synced from incomplete membership; members were added but none removed", group)
+	}
+	if len(result.ShadowDiscrepancies) > 0 {
+		log.Warnf("Shadow planner disagreed with the live run on %d changes", len(result.ShadowDiscrepancies))
+	}
+	if result.Incremental {
+		log.Infof("Incremental sync: %d groups were unchanged and skipped", result.GroupsUnchanged)
+	}
+	if len(result.SimulatedDiffs) > 0 {
+		log.Infof("Test mode operations %v: membership changes to %d groups were only logged", cfg.Sync.TestModeOperations, len(result.SimulatedDiffs))
+	}
+	if result.DeferredRetried > 0 {
+		log.Infof("Retried %d queued writes from earlier runs", result.DeferredRetried)
+	}
+	if result.QueueDepth > 0 {
+		log.Warnf("%d failed writes are queued and will be retried on the next run", result.QueueDepth)
+	}
+	if result.UsersSkipped > 0 {
+		log.Infof("Skipped %d users on the skip list (see 'scim-sync skiplist list')", result.UsersSkipped)
+	}
+	if result.UsersFiltered > 0 {
+		log.Infof("Filtered out %d group members with sync.filters", result.UsersFiltered)
+	}
+	for _, conflict := range result.UserConflicts {
+		log.Warnf("User %s exists in target %s with externalId %q but was not provisioned by the sync (%s)", conflict.Email, conflict.Target, conflict.ExternalID, conflict.Action)
+	}
 	if len(result.Errors) > 0 {
 		log.Warnf("Sync completed with %d errors", len(result.Errors))
-		for _, syncErr := range result.Errors {
-			log.Errorf("Sync error: %v", syncErr)
-		}
+		logErrorSummary(log, result)
 	} else {
 		log.Info("Sync process completed successfully")
 	}
 
+	return runExitError(result, nil, runFailOnError)
+}
+
+// applyRunFlags checks the flags of a one-shot run and applies them to the configuration;
+// --dry-run switches on test mode before the engine is created
+func applyRunFlags() error {
+	if runReportPath != "" && !runDryRun {
+		return fmt.Errorf("--report requires --dry-run")
+	}
+	if runDryRun {
+		cfg.App.TestMode = true
+	}
+	return nil
+}
+
+// syncRunner is the part of the sync engine that starts one-shot runs
+type syncRunner interface {
+	Sync(ctx context.Context) (*sync.SyncResult, error)
+	SyncFull(ctx context.Context) (*sync.SyncResult, error)
+	SyncEnrollment(ctx context.Context) (*sync.SyncResult, error)
+	SyncGroups(ctx context.Context, groupEmails []string) (*sync.SyncResult, error)
+	SyncUser(ctx context.Context, email string) (*sync.SyncResult, error)
+}
+
+// selectRun returns the run the command's flags ask for: a full, enrollment, group or user run,
+// or by default a regular sync
+func selectRun(engine syncRunner) func(context.Context) (*sync.SyncResult, error) {
+	switch {
+	case runFull:
+		return engine.SyncFull
+	case runEnrollment:
+		return engine.SyncEnrollment
+	case runGroup != "":
+		return func(ctx context.Context) (*sync.SyncResult, error) {
+			return engine.SyncGroups(ctx, []string{runGroup})
+		}
+	case runUser != "":
+		return func(ctx context.Context) (*sync.SyncResult, error) {
+			return engine.SyncUser(ctx, runUser)
+		}
+	}
+	return engine.Sync
+}
+
+// runStoppable runs a one-shot sync that a first Ctrl-C or SIGTERM stops once its current group is
+// done, so no group is left half synced; a second one cancels it at once
+func runStoppable(ctx context.Context, log *logrus.Logger, engine *sync.Engine, run func(context.Context) (*sync.SyncResult, error)) (*sync.SyncResult, error) {
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-runCtx.Done():
+			return
+		}
+		if err := engine.StopRun(""); err != nil {
+			cancel()
+			return
+		}
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(interrupts)
+		log.Warn("Interrupted: finishing the current group before exiting; interrupt again to cancel at once")
+		select {
+		case <-interrupts:
+			log.Warn("Interrupted again: cancelling the run")
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	return run(runCtx)
+}
+
+// logErrorSummary logs sync errors grouped by root cause, with each individual error at debug level
+func logErrorSummary(log *logrus.Logger, result *sync.SyncResult) {
+	for _, group := range result.ErrorSummary() {
+		log.Errorf("Sync error: %s", group)
+		if len(group.Subjects) > 0 {
+			log.Errorf("  e.g. %s", strings.Join(group.Subjects, ", "))
+		}
+	}
+	for _, syncErr := range result.Errors {
+		log.Debugf("Sync error: %v", syncErr)
+	}
+}
+
+// writePrometheusTextfile writes the metrics of a one-shot run for the node_exporter textfile collector
+func writePrometheusTextfile(log *logrus.Logger, prometheus *telemetry.Prometheus, path string) {
+	if err := prometheus.WriteTextfile(path); err != nil {
+		log.Errorf("Failed to write Prometheus metrics: %v", err)
+		return
+	}
+	log.Infof("Wrote Prometheus metrics to %s", path)
+}
+
+// writeDryRunReport prints the changes a dry run found as a table and, with --report, writes them
+// as JSON, stamped with the configuration hash scim-sync apply checks
+func writeDryRunReport(log *logrus.Logger, result *sync.SyncResult, configHash string) error {
+	dryRun := result.DryRunReport(time.Now().UTC())
+	dryRun.ConfigHash = configHash
+	if err := report.WriteDryRunTable(os.Stdout, dryRun); err != nil {
+		return err
+	}
+	if runReportPath == "" {
+		return nil
+	}
+
+	file, err := os.Create(runReportPath)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	if err := report.WriteDryRunJSON(file, dryRun); err != nil {
+		return err
+	}
+	log.Infof("Wrote dry run report to %s", runReportPath)
+	return nil
+}
+
+// checkSecretPermissions warns about, or with app.strict_permissions refuses to start with, a config
+// file or service account key that group or other users can read
+func checkSecretPermissions(log *logrus.Logger) error {
+	issues, err := cfg.EnforcePermissions()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	for _, issue := range issues {
+		log.Warnf("Insecure file permissions: %s", issue)
+	}
 	return nil
 }
 
@@ -225,13 +611,13 @@ func validateConfig() error {
 	if cfg == nil {
 		var err error
 		if cfgFile != "" {
-			cfg, err = config.Load(cfgFile)
+			cfg, err = config.LoadProfile(cfgFile, cfgProfile)
 		} else {
 			cfgFile, err = config.FindConfigFile()
 			if err != nil {
 				return fmt.Errorf("no config file found: %w", err)
 			}
-			cfg, err = config.Load(cfgFile)
+			cfg, err = config.LoadProfile(cfgFile, cfgProfile)
 		}
 
 		if err != nil {
@@ -250,8 +636,12 @@ func validateConfig() error {
 	fmt.Printf("✅ Configuration file '%s' is valid\n", cfgFile)
 	fmt.Printf("   - Google Workspace domain: %s\n", cfg.GoogleWorkspace.Domain)
 	fmt.Printf("   - Groups to sync: %d\n", len(cfg.Sync.Groups))
+	if len(cfg.Sync.OrgUnits) > 0 {
+		fmt.Printf("   - Org units to sync: %d\n", len(cfg.Sync.OrgUnits))
+	}
 	fmt.Printf("   - Test mode: %t\n", cfg.App.TestMode)
 	fmt.Printf("   - Log level: %s\n", cfg.App.LogLevel)
+	fmt.Printf("   - Deployment mode: %s\n", cfg.App.Mode)
 
 	return nil
 }
@@ -267,10 +657,20 @@ func runServer() error {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	if !cfg.RunsServer() {
+		return fmt.Errorf("app.mode is %s: run syncs with 'scim-sync run', or set app.mode: %s", cfg.App.Mode, config.DeploymentModeHybrid)
+	}
+
 	// Setup logger
 	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
 
+	// Secrets on disk must not be readable by other users
+	if err := checkSecretPermissions(log); err != nil {
+		return err
+	}
+
 	// Log server start info
+	logger.LogStartup(log, logger.NewStartup(cfg, logger.ModeServer, version, commit, cfgFile))
 	log.Infof("Starting SCIM sync server on port %d", cfg.Server.Port)
 	if cfg.Server.ScheduleEnabled {
 		log.Infof("Scheduling enabled with cron: %s", cfg.Server.Schedule)
@@ -295,18 +695,18 @@ func runSetupWizard() error {
 }
 
 // runSetupValidation executes setup validation
-func runSetupValidation() error {
+func runSetupValidation(ctx context.Context) error {
 	// Load existing configuration if available
 	if cfg == nil {
 		var err error
 		if cfgFile != "" {
-			cfg, err = config.Load(cfgFile)
+			cfg, err = config.LoadProfile(cfgFile, cfgProfile)
 		} else {
 			cfgFile, err = config.FindConfigFile()
 			if err != nil {
 				return fmt.Errorf("no config file found - run 'setup wizard' first: %w", err)
 			}
-			cfg, err = config.Load(cfgFile)
+			cfg, err = config.LoadProfile(cfgFile, cfgProfile)
 		}
 
 		if err != nil {
@@ -317,7 +717,7 @@ func runSetupValidation() error {
 	}
 
 	validator := setup.NewValidator(cfg)
-	summary, err := validator.ValidateSetup()
+	summary, err := validator.ValidateSetup(ctx)
 	if err != nil {
 		return err
 	}
@@ -331,19 +731,47 @@ func runSetupValidation() error {
 }
 
 // runDocsGeneration generates documentation
-func runDocsGeneration() error {
+func runDocsGeneration(args []string) error {
 	outputDir := "./docs"
-	if len(os.Args) > 3 {
-		outputDir = os.Args[3]
+	if len(args) > 0 {
+		outputDir = args[0]
 	}
 
 	fmt.Printf("Generating documentation in %s...\n", outputDir)
-	return setup.GenerateDocumentation(outputDir)
+	if err := setup.GenerateDocumentation(outputDir, setup.NewDocsData(cfg, cfgFile), docsFormat); err != nil {
+		return err
+	}
+	if !docsDeploy {
+		return nil
+	}
+
+	// Populate the deployment files from the loaded config, or the defaults if there is none
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "./config.yaml"
+	}
+	opts, err := setup.NewDeploymentOptions(cfg, configPath)
+	if err != nil {
+		return err
+	}
+	paths, err := setup.WriteDeploymentArtifacts(filepath.Join(outputDir, "deploy"), opts)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Printf("Wrote %s\n", path)
+	}
+	return nil
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C or SIGTERM cancels a command in progress, including API requests in flight; scim-sync
+	// run instead finishes the group it is syncing first (see runStoppable)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
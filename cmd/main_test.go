@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+func TestRunExitCode(t *testing.T) {
+	failed := &sync.SyncResult{Errors: []error{&sync.SyncError{Kind: "user", Subject: "alice@example.com", Err: errors.New("HTTP 400: invalid userName")}}}
+
+	tests := []struct {
+		name        string
+		result      *sync.SyncResult
+		err         error
+		failOnError bool
+		want        int
+	}{
+		{"success", &sync.SyncResult{}, nil, false, 0},
+		{"success with --fail-on-error", &sync.SyncResult{}, nil, true, 0},
+		{"completed with errors", failed, nil, false, 0},
+		{"completed with errors and --fail-on-error", failed, nil, true, exitCompletedWithErrors},
+		{"failed", failed, errors.New("failed to acquire the sync lock"), false, exitFatal},
+		{"failed before a result", nil, errors.New("failed to load configuration"), true, exitFatal},
+		{"stopped by interrupt", &sync.SyncResult{Stopped: true}, sync.ErrSyncAborted, false, exitInterrupted},
+		{"stopped by interrupt with --fail-on-error", &sync.SyncResult{Stopped: true, Errors: failed.Errors}, sync.ErrSyncAborted, true, exitInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runExitError(tt.result, tt.err, tt.failOnError)
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("Expected exit code %d, got %d (%v)", tt.want, got, err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Expected the run's error to be kept, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"no error", nil, 0},
+		{"command failed", errors.New("invalid configuration"), exitFatal},
+		{"exit error", &exitError{code: exitInterrupted, err: errors.New("interrupted")}, exitInterrupted},
+		{"wrapped exit error", fmt.Errorf("run: %w", &exitError{code: exitCompletedWithErrors, err: errors.New("2 errors")}), exitCompletedWithErrors},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("Expected exit code %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
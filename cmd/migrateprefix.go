@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migratePrefixFrom   string
+	migratePrefixTo     string
+	migratePrefixDryRun bool
+)
+
+// migratePrefixCmd represents the migrate-prefix command
+var migratePrefixCmd = &cobra.Command{
+	Use:   "migrate-prefix",
+	Short: "Rename managed Beyond Identity groups to a new group prefix",
+	Long: `Renames the Beyond Identity group of every configured group from the old prefix to the new one,
+keeping its ID and members, and updates the group mappings in the state file (sync.state_path).
+Run it before changing beyond_identity.group_prefix, so the next sync finds the renamed groups
+instead of creating new ones and orphaning the old ones.
+
+Example:
+  scim-sync migrate-prefix --from GoogleSCIM_ --to GWS_ --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigratePrefix(cmd.Context())
+	},
+}
+
+func init() {
+	migratePrefixCmd.Flags().StringVar(&migratePrefixFrom, "from", "", "current group prefix")
+	migratePrefixCmd.Flags().StringVar(&migratePrefixTo, "to", "", "new group prefix")
+	migratePrefixCmd.Flags().BoolVar(&migratePrefixDryRun, "dry-run", false, "show the renames without making them")
+	_ = migratePrefixCmd.MarkFlagRequired("from")
+	_ = migratePrefixCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(migratePrefixCmd)
+}
+
+// runMigratePrefix renames the managed groups and prints the outcome for each
+func runMigratePrefix(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	// Google Workspace is only read, to find groups synced before the state file recorded them
+	gwsClient, err := sync.NewGWSClient(cfg, httpClient, log)
+	if err != nil {
+		return fmt.Errorf("failed to create Google Workspace client: %w", err)
+	}
+	biClient := sync.NewPrimaryClient(cfg, httpClient)
+
+	engine := sync.NewEngine(gwsClient, biClient, cfg, log)
+	if err := engine.RegisterTargets(httpClient); err != nil {
+		return fmt.Errorf("failed to create target clients: %w", err)
+	}
+	if err := engine.ConfigureSource(httpClient); err != nil {
+		return fmt.Errorf("failed to configure membership source: %w", err)
+	}
+	if err := engine.ConfigureState(); err != nil {
+		return fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	migrations, migrateErr := engine.MigratePrefix(ctx, migratePrefixFrom, migratePrefixTo, migratePrefixDryRun)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tTARGET\tOLD NAME\tNEW NAME\tMEMBERS\tSTATUS")
+	for _, m := range migrations {
+		status := m.Status
+		if m.Err != nil {
+			status += ": " + m.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", m.GroupEmail, m.Target, m.OldName, m.NewName, m.Members, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if migrateErr != nil {
+		return migrateErr
+	}
+
+	if !migratePrefixDryRun && cfg.BeyondIdentity.GroupPrefix != migratePrefixTo {
+		fmt.Printf("\nSet beyond_identity.group_prefix to %q before the next sync.\n", migratePrefixTo)
+	}
+	return nil
+}
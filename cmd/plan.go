@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planOut   string
+	applyPlan string
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Compute the changes a sync would make and save them for review",
+	Long: `Runs a dry run, prints the changes it would make as a table and writes them to a plan file.
+Nothing is changed; once the plan is reviewed, scim-sync apply --plan makes exactly the changes
+it lists.
+
+Example:
+  scim-sync plan --out plan.json
+  scim-sync apply --plan plan.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runDryRun = true
+		runReportPath = planOut
+		return runSync(cmd.Context())
+	},
+}
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Make the changes listed in a plan file",
+	Long: `Makes exactly the changes listed in a plan written by scim-sync plan, without reading Google
+Workspace again. Changes already made since the plan are skipped. Plans made with a different
+sync configuration, or that recorded errors, are refused; run scim-sync plan again.
+
+Example:
+  scim-sync apply --plan plan.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApply(cmd.Context())
+	},
+}
+
+func init() {
+	planCmd.Flags().StringVar(&planOut, "out", "plan.json", "write the plan to this file")
+	planCmd.Flags().BoolVar(&runFull, "full", false, "plan every configured group even when sync.incremental is enabled")
+	applyCmd.Flags().StringVar(&applyPlan, "plan", "", "plan file written by scim-sync plan")
+	_ = applyCmd.MarkFlagRequired("plan")
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(applyCmd)
+}
+
+// runApply reads a plan file and makes the changes it lists
+func runApply(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	file, err := os.Open(applyPlan)
+	if err != nil {
+		return fmt.Errorf("failed to open plan: %w", err)
+	}
+	plan, err := report.ReadDryRunJSON(file)
+	_ = file.Close()
+	if err != nil {
+		return err
+	}
+
+	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	// Google Workspace is only written to when the plan changes the enrollment group
+	gwsClient, err := sync.NewGWSClient(cfg, httpClient, log)
+	if err != nil {
+		return fmt.Errorf("failed to create Google Workspace client: %w", err)
+	}
+	biClient := sync.NewPrimaryClient(cfg, httpClient)
+
+	engine := sync.NewEngine(gwsClient, biClient, cfg, log)
+	if err := engine.RegisterTargets(httpClient); err != nil {
+		return fmt.Errorf("failed to create target clients: %w", err)
+	}
+	if err := engine.ConfigureState(); err != nil {
+		return fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	log.Infof("Applying plan %s generated at %s", applyPlan, plan.GeneratedAt.Format("2006-01-02 15:04:05 MST"))
+	result, err := engine.ApplyPlan(ctx, plan)
+	if err != nil {
+		if result != nil {
+			logErrorSummary(log, result)
+		}
+		return err
+	}
+
+	log.Infof("Applied plan: %d users created, %d groups created, %d memberships added, %d removed",
+		result.UsersCreated, result.GroupsCreated, result.MembershipsAdded, result.MembershipsRemoved)
+	if len(result.Errors) > 0 {
+		logErrorSummary(log, result)
+		return fmt.Errorf("%d changes in the plan failed", len(result.Errors))
+	}
+	return nil
+}
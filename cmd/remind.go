@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/remind"
+	"github.com/spf13/cobra"
+)
+
+// remindCmd represents the remind command
+var remindCmd = &cobra.Command{
+	Use:   "remind",
+	Short: "Email enrollment reminders to users without a passkey",
+	Long: `Send the configured enrollment reminder to users who were provisioned more than
+reminders.grace_period_days ago and have not registered a passkey. Users are reminded at most
+every reminders.interval_days, up to reminders.max_reminders times.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReminders(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(remindCmd)
+}
+
+// runReminders sends one round of enrollment reminders
+func runReminders(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if !cfg.Reminders.Enabled {
+		return fmt.Errorf("reminders are not enabled; set reminders.enabled in the configuration")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	sender, err := remind.NewSender(cfg, httpClient)
+	if err != nil {
+		return err
+	}
+
+	biClient := bi.NewClientWithHTTPClient(cfg.BeyondIdentity.APIToken, cfg.BeyondIdentity.SCIMBaseURL, cfg.BeyondIdentity.NativeAPIURL, httpClient)
+	reminder, err := remind.NewReminder(cfg.Reminders, biClient, sender, cfg.App.TestMode, log)
+	if err != nil {
+		return err
+	}
+
+	result, err := reminder.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("enrollment reminders failed: %w", err)
+	}
+
+	log.Infof("Enrollment reminders: %d pending, %d sent, %d skipped, %d failed", result.Pending, result.Sent, result.Skipped, result.Failed)
+	if result.Failed > 0 {
+		return fmt.Errorf("%d reminders could not be sent", result.Failed)
+	}
+	return nil
+}
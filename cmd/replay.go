@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/snapshot"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replaySnapshot   string
+	replayRecord     string
+	replayLiveGoogle bool
+	replayFormat     string
+)
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Simulate a sync against a recorded snapshot",
+	Long: `Run the sync engine against a recorded snapshot of the provisioning targets and print the
+changes it would make, without calling any write API. Google data comes from the snapshot, or
+is read live with --live-google (the enrollment group is never modified). Use it to rehearse
+configuration changes such as new groups, prefixes or targets.
+
+Record a snapshot of what the current configuration reads with --record:
+
+  scim-sync replay --record backup.json
+  scim-sync replay --snapshot backup.json --config new-config.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if replayRecord != "" {
+			return runRecordSnapshot(cmd.Context())
+		}
+		return runReplay(cmd.Context())
+	},
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replaySnapshot, "snapshot", "", "snapshot file to replay against")
+	replayCmd.Flags().StringVar(&replayRecord, "record", "", "record a snapshot of the live tenants to this file instead of replaying")
+	replayCmd.Flags().BoolVar(&replayLiveGoogle, "live-google", false, "read Google groups live instead of from the snapshot")
+	replayCmd.Flags().StringVar(&replayFormat, "format", "text", "output format: text or json")
+	replayCmd.MarkFlagsMutuallyExclusive("snapshot", "record")
+	replayCmd.MarkFlagsOneRequired("snapshot", "record")
+
+	rootCmd.AddCommand(replayCmd)
+}
+
+// replayOutput is the JSON form of a replay
+type replayOutput struct {
+	Changes []snapshot.Change `json:"changes"`
+	Errors  []string          `json:"errors,omitempty"`
+}
+
+// runRecordSnapshot reads the live tenants and Google groups into a snapshot file
+func runRecordSnapshot(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	gwsClient, err := sync.NewGWSClient(cfg, httpClient, log)
+	if err != nil {
+		return fmt.Errorf("failed to create Google Workspace client: %w", err)
+	}
+
+	// The source may be a CSV export or Azure AD groups rather than Google groups
+	source, err := sync.NewSourceClient(cfg, gwsClient, httpClient)
+	if err != nil {
+		return fmt.Errorf("failed to configure membership source: %w", err)
+	}
+
+	targets := map[string]snapshot.TenantReader{
+		config.DefaultTargetName: sync.NewPrimaryClient(cfg, httpClient),
+	}
+	for _, target := range cfg.Targets {
+		client, err := sync.NewTargetClient(target, httpClient)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		targets[target.Name] = client
+	}
+
+	snap, err := snapshot.Record(ctx, cfg, source, gwsClient, targets)
+	if err != nil {
+		return fmt.Errorf("failed to record snapshot: %w", err)
+	}
+	if err := snap.Save(replayRecord); err != nil {
+		return err
+	}
+
+	users, groups := 0, 0
+	for _, tenant := range snap.Targets {
+		users += len(tenant.Users)
+		groups += len(tenant.Groups)
+	}
+	fmt.Printf("Recorded %d users and %d groups from %d targets, and %d Google groups, to %s\n",
+		users, groups, len(snap.Targets), len(snap.GoogleWorkspace.Groups), replayRecord)
+	return nil
+}
+
+// runReplay runs the engine against the snapshot and prints the captured changes
+func runReplay(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if replayFormat != "text" && replayFormat != "json" {
+		return fmt.Errorf("--format must be text or json")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	snap, err := snapshot.Load(replaySnapshot)
+	if err != nil {
+		return err
+	}
+
+	// Engine logs go to stderr so the change set on stdout stays machine-readable
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+	log.SetFormatter(&logger.PythonCompatibleFormatter{})
+	if level, err := logrus.ParseLevel(cfg.App.LogLevel); err == nil {
+		log.SetLevel(level)
+	}
+
+	recorder := &snapshot.Recorder{}
+	workspaceData := snap.GoogleWorkspace
+	var live snapshot.GroupReader
+	if replayLiveGoogle || workspaceData == nil {
+		httpClient, err := httpclient.NewFromConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		if live, err = sync.NewGWSClient(cfg, httpClient, log); err != nil {
+			return fmt.Errorf("failed to create Google Workspace client: %w", err)
+		}
+		workspaceData = nil
+	}
+
+	// Every write lands in the in-memory clients, so run as a real sync rather than in test mode
+	replayCfg := *cfg
+	replayCfg.App.TestMode = false
+
+	engine := sync.NewEngine(
+		snapshot.NewWorkspaceClient(workspaceData, live, recorder),
+		snapshot.NewTenantClient(config.DefaultTargetName, snap.Targets[config.DefaultTargetName], recorder),
+		&replayCfg, log,
+	)
+	for _, target := range cfg.Targets {
+		engine.AddTarget(target.Name, snapshot.NewTenantClient(target.Name, snap.Targets[target.Name], recorder))
+	}
+	// A CSV export is read again; Azure AD membership was recorded in the snapshot like Google groups
+	if cfg.Source.Type == config.SourceTypeCSV {
+		if err := engine.ConfigureSource(nil); err != nil {
+			return fmt.Errorf("failed to configure membership source: %w", err)
+		}
+	}
+
+	result, syncErr := engine.Sync(ctx)
+
+	output := replayOutput{Changes: recorder.Changes()}
+	if result != nil {
+		for _, err := range result.Errors {
+			output.Errors = append(output.Errors, err.Error())
+		}
+	}
+	if syncErr != nil && result == nil {
+		output.Errors = append(output.Errors, syncErr.Error())
+	}
+
+	if replayFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	}
+
+	fmt.Printf("Replayed against snapshot recorded %s\n", snap.RecordedAt.Format("2006-01-02 15:04:05 MST"))
+	if len(output.Changes) == 0 {
+		fmt.Println("No changes")
+	}
+	for _, change := range output.Changes {
+		fmt.Printf("  %s\n", change)
+	}
+	if len(output.Errors) > 0 {
+		fmt.Printf("\n%d errors:\n", len(output.Errors))
+		for _, err := range output.Errors {
+			fmt.Printf("  %s\n", err)
+		}
+	}
+	return nil
+}
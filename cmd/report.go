@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportDays   int
+	reportOutput string
+	reportFormat string
+	reportHTML   string
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate reports from Beyond Identity data",
+	Long:  `Generate reports about provisioned users and sync status.`,
+}
+
+// reportPendingEnrollmentCmd represents the report pending-enrollment subcommand
+var reportPendingEnrollmentCmd = &cobra.Command{
+	Use:   "pending-enrollment",
+	Short: "List users who have not enrolled a passkey",
+	Long: `List users provisioned more than --days days ago who still have no active passkey,
+to drive enrollment follow-up campaigns.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPendingEnrollmentReport(cmd.Context())
+	},
+}
+
+// reportAccessReviewCmd represents the report access-review subcommand
+var reportAccessReviewCmd = &cobra.Command{
+	Use:   "access-review",
+	Short: "Export who is in which Beyond Identity group",
+	Long: `Export the current members of every provisioned group with the Google group it is sourced
+from and when it was last synced, as CSV or as a SCIM ListResponse for GRC tools.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAccessReviewReport(cmd.Context())
+	},
+}
+
+// reportStatusCmd represents the report status subcommand
+var reportStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Render sync status as a static HTML page",
+	Long: `Write a self-contained HTML page with the recent sync runs, a daily success rate chart, current
+errors and passkey enrollment stats, read from the state file (sync.state_path) and Beyond Identity.
+The page has no external resources, so it can be published to an internal static site for
+stakeholders without API access.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStatusReport(cmd.Context())
+	},
+}
+
+func init() {
+	reportPendingEnrollmentCmd.Flags().IntVar(&reportDays, "days", report.DefaultPendingDays, "minimum days since the user was provisioned")
+	reportPendingEnrollmentCmd.Flags().StringVar(&reportOutput, "output", "", "write the CSV to this file instead of stdout")
+
+	reportAccessReviewCmd.Flags().StringVar(&reportFormat, "format", config.AccessReviewFormatCSV, "output format: csv or scim")
+	reportAccessReviewCmd.Flags().StringVar(&reportOutput, "output", "", "write the export to this file instead of stdout")
+
+	reportStatusCmd.Flags().StringVar(&reportHTML, "html", "", "write the status page to this file")
+	_ = reportStatusCmd.MarkFlagRequired("html")
+
+	reportCmd.AddCommand(reportPendingEnrollmentCmd)
+	reportCmd.AddCommand(reportAccessReviewCmd)
+	reportCmd.AddCommand(reportStatusCmd)
+	rootCmd.AddCommand(reportCmd)
+}
+
+// runPendingEnrollmentReport writes the pending enrollment report as CSV
+func runPendingEnrollmentReport(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if reportDays < 0 {
+		return fmt.Errorf("--days must be non-negative")
+	}
+	if !cfg.PrimaryIsBeyondIdentity() {
+		return fmt.Errorf("enrollment status needs a Beyond Identity primary target, not %s", cfg.PrimaryTargetType())
+	}
+
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	client := bi.NewClientWithHTTPClient(cfg.BeyondIdentity.APIToken, cfg.BeyondIdentity.SCIMBaseURL, cfg.BeyondIdentity.NativeAPIURL, httpClient)
+
+	users, err := report.PendingEnrollment(ctx, client, reportDays, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if reportOutput != "" {
+		file, err := os.Create(reportOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+
+	if err := report.WritePendingEnrollmentCSV(out, users); err != nil {
+		return err
+	}
+
+	if reportOutput != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d pending users to %s\n", len(users), reportOutput)
+	}
+	return nil
+}
+
+// runAccessReviewReport writes the access review export for the groups recorded in the state file
+func runAccessReviewReport(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+
+	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	// Only the provisioned side is read, so no Google Workspace client is needed
+	biClient := sync.NewPrimaryClient(cfg, httpClient)
+	engine := sync.NewEngine(nil, biClient, cfg, log)
+	if err := engine.RegisterTargets(httpClient); err != nil {
+		return fmt.Errorf("failed to create target clients: %w", err)
+	}
+	if err := engine.ConfigureState(); err != nil {
+		return fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	groups, err := engine.AccessReview(ctx)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if reportOutput != "" {
+		file, err := os.Create(reportOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+
+	if err := report.WriteAccessReview(out, groups, reportFormat); err != nil {
+		return err
+	}
+
+	if reportOutput != "" {
+		fmt.Fprintf(os.Stderr, "Wrote access review of %d groups to %s\n", len(groups), reportOutput)
+	}
+	return nil
+}
+
+// runStatusReport writes the status page for the state file and Beyond Identity tenant
+func runStatusReport(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+
+	store, err := state.Open(cfg.Sync.StatePath)
+	if err != nil {
+		return fmt.Errorf("failed to load sync state: %w", err)
+	}
+	status := report.NewStatus(store, version, time.Now())
+
+	// The page is still useful without enrollment stats, so a Beyond Identity outage, or a primary
+	// target other than Beyond Identity, only leaves them out
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	if cfg.PrimaryIsBeyondIdentity() {
+		client := bi.NewClientWithHTTPClient(cfg.BeyondIdentity.APIToken, cfg.BeyondIdentity.SCIMBaseURL, cfg.BeyondIdentity.NativeAPIURL, httpClient)
+		if status.Enrollment, err = report.Enrollment(ctx, client); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: enrollment stats left out: %v\n", err)
+		}
+	}
+
+	file, err := os.Create(reportHTML)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := report.WriteStatusHTML(file, status); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote status page with %d runs to %s\n", len(status.Runs), reportHTML)
+	return nil
+}
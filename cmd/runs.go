@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runsServer string
+	runsJSON   bool
+)
+
+// runsCmd represents the runs command
+var runsCmd = &cobra.Command{
+	Use:   "runs [run-id]",
+	Short: "Show the recent runs of a running server",
+	Long: `List the last sync.recent_runs runs of scim-sync server with their durations and counts, read
+from its GET /runs endpoint; with a run ID, also print the changes that run made, planned or
+failed. Runs are kept in the server's memory, so they start over when it restarts.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			return runShowRun(args[0])
+		}
+		return runListRuns()
+	},
+}
+
+func init() {
+	runsCmd.Flags().StringVar(&runsServer, "server", "", "base URL of the server (default http://localhost:<server.port>)")
+	runsCmd.Flags().BoolVar(&runsJSON, "json", false, "print the server's JSON response")
+	rootCmd.AddCommand(runsCmd)
+}
+
+// runListRuns prints the recent runs, newest first
+func runListRuns() error {
+	var runs server.RunsResponse
+	body, err := getFromServer("/runs", &runs)
+	if err != nil || runsJSON {
+		return printServerJSON(body, err)
+	}
+
+	if runs.Count == 0 {
+		fmt.Println("No runs recorded since the server started")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKIND\tSUBJECT\tSTARTED\tDURATION\tSTATUS\tCHANGES")
+	for _, run := range runs.Runs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", run.ID, run.Kind, run.Subject, run.StartedAt.Local().Format(time.RFC3339),
+			run.Duration.Round(time.Millisecond), runStatus(run), runCounts(run.Result))
+	}
+	return w.Flush()
+}
+
+// runShowRun prints one run with its change records
+func runShowRun(id string) error {
+	var run server.RunResponse
+	body, err := getFromServer("/runs/"+url.PathEscape(id), &run)
+	if err != nil || runsJSON {
+		return printServerJSON(body, err)
+	}
+
+	fmt.Printf("Run %s (%s", run.ID, run.Kind)
+	if run.Subject != "" {
+		fmt.Printf(" %s", run.Subject)
+	}
+	fmt.Printf(")\nStarted:  %s\nDuration: %s\nStatus:   %s\n", run.StartedAt.Local().Format(time.RFC3339), run.Duration.Round(time.Millisecond), runStatus(run))
+	if run.Error != "" {
+		fmt.Printf("Error:    %s\n", run.Error)
+	}
+	if run.Result == nil {
+		return nil
+	}
+	fmt.Printf("Counts:   %s\n", runCounts(run.Result))
+	for _, message := range run.Result.Errors {
+		fmt.Printf("  ✗ %s\n", message)
+	}
+	if len(run.Result.Changes) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTION\tUSER\tGROUP\tTARGET\tOUTCOME")
+	for _, change := range run.Result.Changes {
+		outcome := "done"
+		switch {
+		case change.Error != "":
+			outcome = "failed: " + change.Error
+		case change.Planned:
+			outcome = "planned"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", change.Time.Local().Format(time.TimeOnly), change.Action, change.User, change.Group, change.Target, outcome)
+	}
+	return w.Flush()
+}
+
+// runStatus describes how a run ended
+func runStatus(run server.RunResponse) string {
+	status := run.Status
+	if run.DryRun {
+		status += " (dry run)"
+	}
+	if run.Result != nil && len(run.Result.Errors) > 0 {
+		status += fmt.Sprintf(", %d errors", len(run.Result.Errors))
+	}
+	return status
+}
+
+// runCounts summarizes the changes of a run
+func runCounts(stats *server.SyncStats) string {
+	if stats == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d groups, +%d users, +%d/-%d members", stats.GroupsProcessed, stats.UsersCreated, stats.MembershipsAdded, stats.MembershipsRemoved)
+}
+
+// getFromServer reads a JSON endpoint of the running server into out, returning the raw body
+func getFromServer(path string, out interface{}) ([]byte, error) {
+	base := runsServer
+	if base == "" {
+		if cfg == nil {
+			return nil, fmt.Errorf("configuration not loaded; pass --server")
+		}
+		base = fmt.Sprintf("http://localhost:%d", cfg.Server.Port)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(strings.TrimSuffix(base, "/") + path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the server: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the server response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil, fmt.Errorf("failed to decode the server response: %w", err)
+	}
+	return body, nil
+}
+
+// printServerJSON prints a response body as --json asks, or returns the error reading it
+func printServerJSON(body []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(body)
+	return err
+}
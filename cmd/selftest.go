@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/setup"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+// selftestCmd represents the selftest command
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Provision and remove a canary user and group in Beyond Identity",
+	Long: `Create a clearly named canary user and group (scim-sync-selftest-<timestamp>) in Beyond Identity,
+verify that search, membership PATCH and delete behave as the sync expects, then delete them.
+Unlike setup validate, this writes to the tenant and runs even when app.test_mode is enabled.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSelfTest(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+}
+
+// runSelfTest executes the Beyond Identity self-test
+func runSelfTest(ctx context.Context) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	httpClient, err := httpclient.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	biClient := sync.NewPrimaryClient(cfg, httpClient)
+
+	summary := setup.NewSelfTest(biClient, cfg).Run(ctx)
+
+	// Exit with error code if any step failed
+	if summary.OverallStatus != "PASS" {
+		os.Exit(1)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/selfupdate"
+	"github.com/spf13/cobra"
+)
+
+// selfUpdateTimeout bounds the whole update, including downloading the binary
+const selfUpdateTimeout = 5 * time.Minute
+
+var (
+	selfUpdateChannel       string
+	selfUpdateCheck         bool
+	selfUpdateRepo          string
+	selfUpdateSkipSignature bool
+
+	// Base64 Ed25519 key that verifies release checksums (set via ldflags)
+	releasePublicKey = ""
+)
+
+// selfUpdateCmd represents the self-update command
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Replace this binary with the latest release",
+	Long: `Check GitHub releases for a newer version of scim-sync and, if there is one, download the
+binary for this platform, verify it against the release's signed checksums and replace the
+running executable in place. --channel beta also considers prereleases.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSelfUpdate()
+	},
+}
+
+func init() {
+	selfUpdateCmd.Flags().StringVar(&selfUpdateChannel, "channel", selfupdate.ChannelStable, "release channel: stable or beta")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false, "only report whether a newer version is available")
+	selfUpdateCmd.Flags().StringVar(&selfUpdateRepo, "repo", selfupdate.DefaultRepository, "GitHub repository (owner/name) to update from")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateSkipSignature, "insecure-skip-signature", false, "install without verifying the checksum signature (checksums are still verified)")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+// runSelfUpdate installs the latest release on the channel if it is newer than this build
+func runSelfUpdate() error {
+	publicKey, err := selfupdate.ParsePublicKey(releasePublicKey)
+	if err != nil {
+		return err
+	}
+
+	// Honor the configured CA bundle and headers when a configuration is present, e.g. behind a proxy
+	opts := httpclient.Options{}
+	if cfg != nil {
+		if opts, err = httpclient.OptionsFromConfig(cfg); err != nil {
+			return fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+	}
+	opts.Timeout = selfUpdateTimeout
+	opts.MaxResponseBytes = selfupdate.MaxAssetBytes
+
+	updater := &selfupdate.Updater{
+		HTTPClient:    httpclient.New(opts),
+		APIURL:        selfupdate.DefaultAPIURL,
+		Repository:    selfUpdateRepo,
+		PublicKey:     publicKey,
+		SkipSignature: selfUpdateSkipSignature,
+	}
+
+	release, err := updater.Latest(selfUpdateChannel)
+	if err != nil {
+		return err
+	}
+	if !selfupdate.Newer(release.Tag, version) {
+		fmt.Printf("scim-sync %s is up to date (latest %s release: %s)\n", version, selfUpdateChannel, release.Tag)
+		return nil
+	}
+	if selfUpdateCheck {
+		fmt.Printf("scim-sync %s is available (current: %s); run scim-sync self-update to install it\n", release.Tag, version)
+		return nil
+	}
+
+	if selfUpdateSkipSignature {
+		fmt.Fprintln(os.Stderr, "WARNING: --insecure-skip-signature is set. The release is NOT verified to come from its publisher.")
+	}
+	binary, err := updater.Download(release)
+	if errors.Is(err, selfupdate.ErrNoSigningKey) {
+		return fmt.Errorf("%w; download the release manually or pass --insecure-skip-signature", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	path, err := executablePath()
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.Install(path, binary); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated scim-sync %s to %s (%s)\n", version, release.Tag, path)
+	return nil
+}
+
+// executablePath returns the file behind the running executable, resolving symlinks so a linked
+// install is updated rather than replaced by a copy
+func executablePath() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return resolved, nil
+}
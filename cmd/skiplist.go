@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	skiplistReason  string
+	skiplistDays    int
+	skiplistAddedBy string
+)
+
+// skiplistCmd represents the skiplist command
+var skiplistCmd = &cobra.Command{
+	Use:   "skiplist",
+	Short: "Manage users that syncs do not try to provision",
+	Long: `Users on the skip list are left out of every sync, for users that fail permanently such as
+invalid addresses or blocked domains. Entries are kept in the state file (sync.state_path).`,
+}
+
+// skiplistAddCmd represents the skiplist add subcommand
+var skiplistAddCmd = &cobra.Command{
+	Use:   "add <email>",
+	Short: "Add a user to the skip list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSkiplistAdd(args[0])
+	},
+}
+
+// skiplistRemoveCmd represents the skiplist remove subcommand
+var skiplistRemoveCmd = &cobra.Command{
+	Use:   "remove <email>",
+	Short: "Remove a user from the skip list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSkiplistRemove(args[0])
+	},
+}
+
+// skiplistListCmd represents the skiplist list subcommand
+var skiplistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the users on the skip list",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSkiplistList()
+	},
+}
+
+func init() {
+	skiplistAddCmd.Flags().StringVar(&skiplistReason, "reason", "", "why the user is skipped")
+	skiplistAddCmd.Flags().IntVar(&skiplistDays, "days", 0, "expire the entry after this many days (0 never expires)")
+	skiplistAddCmd.Flags().StringVar(&skiplistAddedBy, "added-by", os.Getenv("USER"), "who added the entry")
+	_ = skiplistAddCmd.MarkFlagRequired("reason")
+
+	skiplistCmd.AddCommand(skiplistAddCmd)
+	skiplistCmd.AddCommand(skiplistRemoveCmd)
+	skiplistCmd.AddCommand(skiplistListCmd)
+	rootCmd.AddCommand(skiplistCmd)
+}
+
+// openSkiplistState loads the state file holding the skip list
+func openSkiplistState() (*state.Store, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+	store, err := state.Open(cfg.Sync.StatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync state: %w", err)
+	}
+	return store, nil
+}
+
+// runSkiplistAdd adds a user to the skip list
+func runSkiplistAdd(email string) error {
+	if !strings.Contains(email, "@") {
+		return fmt.Errorf("%q is not an email address", email)
+	}
+	if skiplistDays < 0 {
+		return fmt.Errorf("--days must not be negative")
+	}
+
+	store, err := openSkiplistState()
+	if err != nil {
+		return err
+	}
+
+	entry := state.SkippedUser{
+		Email:   strings.ToLower(email),
+		Reason:  skiplistReason,
+		AddedBy: skiplistAddedBy,
+		AddedAt: time.Now().UTC(),
+	}
+	if skiplistDays > 0 {
+		entry.ExpiresAt = entry.AddedAt.AddDate(0, 0, skiplistDays)
+	}
+	store.SkipUser(entry)
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Added %s to the skip list\n", entry.Email)
+	return nil
+}
+
+// runSkiplistRemove removes a user from the skip list
+func runSkiplistRemove(email string) error {
+	store, err := openSkiplistState()
+	if err != nil {
+		return err
+	}
+
+	if !store.UnskipUser(email) {
+		return fmt.Errorf("%s is not on the skip list", email)
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %s from the skip list\n", email)
+	return nil
+}
+
+// runSkiplistList prints the skip-list entries in effect
+func runSkiplistList() error {
+	store, err := openSkiplistState()
+	if err != nil {
+		return err
+	}
+
+	users := store.SkippedUsers(time.Now())
+	if len(users) == 0 {
+		fmt.Println("The skip list is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EMAIL\tREASON\tADDED BY\tADDED\tEXPIRES")
+	for _, user := range users {
+		expires := "never"
+		if !user.ExpiresAt.IsZero() {
+			expires = user.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", user.Email, user.Reason, user.AddedBy, user.AddedAt.Format(time.RFC3339), expires)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/logger"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// telemetryCmd represents the telemetry command
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Inspect the anonymous usage report",
+	Long: `When telemetry.enabled is set, an anonymous usage report (version, bucketed counts of groups
+and users synced, feature flags enabled and error categories) is sent at most once a day to help
+maintainers prioritize. Telemetry is off unless you opt in.`,
+}
+
+// telemetryShowCmd represents the telemetry show subcommand
+var telemetryShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print exactly what the usage report contains",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTelemetryShow()
+	},
+}
+
+func init() {
+	telemetryCmd.AddCommand(telemetryShowCmd)
+	rootCmd.AddCommand(telemetryCmd)
+}
+
+// runTelemetryShow prints the usage report recorded after the last run
+func runTelemetryShow() error {
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+
+	// Previewing never sends, so no HTTP client is needed
+	log := logger.Setup(cfg.App.LogLevel, cfg.App.TestMode)
+	report, err := telemetry.NewUsage(cfg, nil, version, telemetry.UsageModeRun, log).Preview()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode usage report: %w", err)
+	}
+
+	if cfg.Telemetry.Enabled {
+		fmt.Fprintf(os.Stderr, "Telemetry is enabled: a report like this is sent to %s at most once a day\n", cfg.Telemetry.Endpoint)
+	} else {
+		fmt.Fprintln(os.Stderr, "Telemetry is disabled: nothing is sent. Set telemetry.enabled: true to opt in")
+	}
+	return nil
+}
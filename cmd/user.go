@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// userCmd represents the user command
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Sync a single user",
+}
+
+// userSyncCmd represents the user sync subcommand
+var userSyncCmd = &cobra.Command{
+	Use:   "sync <email>",
+	Short: "Provision or reconcile one user now, without a full run",
+	Long: `Reads every configured group and brings the user's memberships in line with it: the user
+is created in Beyond Identity if missing and added to the groups they belong to, and removed
+from the synced groups they have left. Other members are untouched, so onboarding escalations
+can be handled without waiting for the next scheduled sync. The run is recorded in the run
+journal as user.
+
+Example:
+  scim-sync user sync alice@example.com
+  scim-sync user sync alice@example.com --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.Contains(args[0], "@") {
+			return fmt.Errorf("%q is not an email address", args[0])
+		}
+		runUser = args[0]
+		return runUserSync(cmd.Context())
+	},
+}
+
+// runUserSync runs the user sync; replaced in tests
+var runUserSync = runSync
+
+func init() {
+	userSyncCmd.Flags().BoolVar(&runDryRun, "dry-run", false, "plan the changes without making them (enables test mode) and print them as a table")
+	userSyncCmd.Flags().BoolVar(&runFailOnError, "fail-on-error", false, "exit with code 2 when syncing the user failed in any group")
+	userCmd.AddCommand(userSyncCmd)
+	rootCmd.AddCommand(userCmd)
+}
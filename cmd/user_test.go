@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// fakeRunner records which run was started, and whether test mode was on at the time
+type fakeRunner struct {
+	result   *sync.SyncResult
+	calls    []string
+	user     string
+	testMode bool
+}
+
+func (f *fakeRunner) record(call string) (*sync.SyncResult, error) {
+	f.calls = append(f.calls, call)
+	f.testMode = cfg.App.TestMode
+	return f.result, nil
+}
+
+func (f *fakeRunner) Sync(ctx context.Context) (*sync.SyncResult, error) { return f.record("Sync") }
+
+func (f *fakeRunner) SyncFull(ctx context.Context) (*sync.SyncResult, error) {
+	return f.record("SyncFull")
+}
+
+func (f *fakeRunner) SyncEnrollment(ctx context.Context) (*sync.SyncResult, error) {
+	return f.record("SyncEnrollment")
+}
+
+func (f *fakeRunner) SyncGroups(ctx context.Context, groupEmails []string) (*sync.SyncResult, error) {
+	return f.record("SyncGroups")
+}
+
+func (f *fakeRunner) SyncUser(ctx context.Context, email string) (*sync.SyncResult, error) {
+	f.user = email
+	return f.record("SyncUser")
+}
+
+// executeUserSync runs scim-sync user sync with args against runner, as runSync would, and
+// returns the exit code
+func executeUserSync(t *testing.T, runner *fakeRunner, args ...string) (int, error) {
+	t.Helper()
+	cfg = &config.Config{}
+	t.Cleanup(func() {
+		cfg, runUser, runDryRun, runFailOnError = nil, "", false, false
+		runUserSync = runSync
+	})
+	runUserSync = func(ctx context.Context) error {
+		if err := applyRunFlags(); err != nil {
+			return err
+		}
+		result, err := selectRun(runner)(ctx)
+		return runExitError(result, err, runFailOnError)
+	}
+
+	cmd := userSyncCmd
+	cmd.SetContext(context.Background())
+	if err := cmd.ParseFlags(args); err != nil {
+		return exitCode(err), err
+	}
+	positional := cmd.Flags().Args()
+	if err := cmd.Args(cmd, positional); err != nil {
+		return exitCode(err), err
+	}
+	err := cmd.RunE(cmd, positional)
+	return exitCode(err), err
+}
+
+func TestUserSync_Argument(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectError string
+	}{
+		{"missing", nil, "accepts 1 arg(s), received 0"},
+		{"more than one", []string{"alice@example.com", "bob@example.com"}, "accepts 1 arg(s), received 2"},
+		{"not an email address", []string{"alice"}, `"alice" is not an email address`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{result: &sync.SyncResult{}}
+			_, err := executeUserSync(t, runner, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error %q, got %v", tt.expectError, err)
+			}
+			if len(runner.calls) != 0 {
+				t.Errorf("Expected no run to start, got %v", runner.calls)
+			}
+		})
+	}
+}
+
+func TestUserSync_Flags(t *testing.T) {
+	failed := &sync.SyncResult{Errors: []error{&sync.SyncError{Kind: "user", Subject: "alice@example.com", Err: errors.New("HTTP 400: invalid userName")}}}
+
+	tests := []struct {
+		name           string
+		args           []string
+		result         *sync.SyncResult
+		expectTestMode bool
+		expectCode     int
+	}{
+		{"default", []string{"alice@example.com"}, &sync.SyncResult{}, false, 0},
+		{"dry run", []string{"alice@example.com", "--dry-run"}, &sync.SyncResult{}, true, 0},
+		{"errors without --fail-on-error", []string{"alice@example.com"}, failed, false, 0},
+		{"errors with --fail-on-error", []string{"--fail-on-error", "alice@example.com"}, failed, false, exitCompletedWithErrors},
+		{"dry run with --fail-on-error", []string{"alice@example.com", "--dry-run", "--fail-on-error"}, failed, true, exitCompletedWithErrors},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{result: tt.result}
+			code, _ := executeUserSync(t, runner, tt.args...)
+
+			if len(runner.calls) != 1 || runner.calls[0] != "SyncUser" || runner.user != "alice@example.com" {
+				t.Fatalf("Expected SyncUser for alice@example.com, got %v for %q", runner.calls, runner.user)
+			}
+			if runner.testMode != tt.expectTestMode {
+				t.Errorf("Expected test mode %v when SyncUser ran, got %v", tt.expectTestMode, runner.testMode)
+			}
+			if code != tt.expectCode {
+				t.Errorf("Expected exit code %d, got %d", tt.expectCode, code)
+			}
+		})
+	}
+}
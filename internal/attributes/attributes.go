@@ -0,0 +1,127 @@
+// Package attributes maps Google Workspace user fields to the SCIM attributes of provisioned users
+package attributes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// User holds the Google Workspace fields mappings can refer to, as {{.GivenName}} in templates
+type User struct {
+	Email      string
+	GivenName  string
+	FamilyName string
+	FullName   string
+	OrgUnit    string // Path of the user's organizational unit, e.g. /Engineering/Platform
+	Department string // Department of the user's primary organization
+	Title      string // Job title in the user's primary organization
+	EmployeeID string // External ID of type organization
+}
+
+// SCIM attributes mappings can set
+const (
+	DisplayName    = "displayName"
+	GivenName      = "name.givenName"
+	FamilyName     = "name.familyName"
+	FormattedName  = "name.formatted"
+	Title          = "title"
+	EmployeeNumber = "employeeNumber" // Enterprise User extension
+	Department     = "department"     // Enterprise User extension
+	Organization   = "organization"   // Enterprise User extension
+)
+
+// Attributes lists the SCIM attributes mappings can set
+var Attributes = []string{DisplayName, GivenName, FamilyName, FormattedName, Title, EmployeeNumber, Department, Organization}
+
+// fields are the Google Workspace field names a mapping can use instead of a template
+var fields = map[string]string{
+	"email":      "{{.Email}}",
+	"givenName":  "{{.GivenName}}",
+	"familyName": "{{.FamilyName}}",
+	"fullName":   "{{.FullName}}",
+	"orgUnit":    "{{.OrgUnit}}",
+	"department": "{{.Department}}",
+	"title":      "{{.Title}}",
+	"employeeId": "{{.EmployeeID}}",
+}
+
+// Fields lists the Google Workspace field names a mapping can use instead of a template
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Mapper renders the mapped attributes of provisioned users
+type Mapper struct {
+	attributes []string // Sorted, so attributes are always rendered in the same order
+	templates  map[string]*template.Template
+}
+
+// New compiles mappings from SCIM attribute to a Google Workspace field name, e.g. givenName, or a
+// template, e.g. "{{.GivenName}} {{.FamilyName}}"
+func New(mappings map[string]string) (*Mapper, error) {
+	m := &Mapper{templates: make(map[string]*template.Template)}
+	for attribute := range mappings {
+		m.attributes = append(m.attributes, attribute)
+	}
+	sort.Strings(m.attributes)
+
+	for _, attribute := range m.attributes {
+		if !isAttribute(attribute) {
+			return nil, fmt.Errorf("unknown attribute %q, must be one of: %v", attribute, Attributes)
+		}
+
+		expression := mappings[attribute]
+		text := expression
+		if !strings.Contains(expression, "{{") {
+			field, ok := fields[expression]
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q for %s, must be a template or one of: %v", expression, attribute, Fields())
+			}
+			text = field
+		}
+
+		tmpl, err := template.New(attribute).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %w", attribute, err)
+		}
+		// Templates refer to fields by name, so a misspelt one only fails when executed
+		if err := tmpl.Execute(&strings.Builder{}, User{}); err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %w", attribute, err)
+		}
+
+		m.templates[attribute] = tmpl
+	}
+	return m, nil
+}
+
+// Render returns the mapped attributes of a user; attributes that render empty, e.g. because the
+// field is not set in Google Workspace, are left out
+func (m *Mapper) Render(source User) (map[string]string, error) {
+	values := make(map[string]string, len(m.attributes))
+	for _, attribute := range m.attributes {
+		var value strings.Builder
+		if err := m.templates[attribute].Execute(&value, source); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", attribute, err)
+		}
+		if rendered := strings.TrimSpace(value.String()); rendered != "" {
+			values[attribute] = rendered
+		}
+	}
+	return values, nil
+}
+
+func isAttribute(attribute string) bool {
+	for _, known := range Attributes {
+		if known == attribute {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package attributes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings map[string]string
+		wantErr  string
+	}{
+		{name: "none", mappings: nil},
+		{name: "field name", mappings: map[string]string{DisplayName: "fullName"}},
+		{name: "template", mappings: map[string]string{DisplayName: "{{.GivenName}} {{.FamilyName}}"}},
+		{name: "unknown attribute", mappings: map[string]string{"nickName": "givenName"}, wantErr: "unknown attribute"},
+		{name: "unknown field", mappings: map[string]string{Title: "jobTitle"}, wantErr: "unknown field"},
+		{name: "unparsable template", mappings: map[string]string{Title: "{{.Title"}, wantErr: "invalid template"},
+		{name: "unknown template field", mappings: map[string]string{Title: "{{.JobTitle}}"}, wantErr: "invalid template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.mappings)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("New() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMapper_Render(t *testing.T) {
+	source := User{
+		Email:      "alice@example.com",
+		GivenName:  "Alice",
+		FamilyName: "Liddell",
+		OrgUnit:    "/Engineering/Platform",
+		EmployeeID: "E1001",
+	}
+
+	tests := []struct {
+		name     string
+		mappings map[string]string
+		want     map[string]string
+	}{
+		{
+			name:     "field names",
+			mappings: map[string]string{GivenName: "givenName", EmployeeNumber: "employeeId"},
+			want:     map[string]string{GivenName: "Alice", EmployeeNumber: "E1001"},
+		},
+		{
+			name:     "template",
+			mappings: map[string]string{DisplayName: "{{.FamilyName}}, {{.GivenName}}", Organization: "{{.OrgUnit}}"},
+			want:     map[string]string{DisplayName: "Liddell, Alice", Organization: "/Engineering/Platform"},
+		},
+		{
+			name:     "empty values are left out",
+			mappings: map[string]string{Title: "title", Department: "{{.Department}} "},
+			want:     map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper, err := New(tt.mappings)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got, err := mapper.Render(source)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Render() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Package audit appends records of sensitive administrative actions to a JSON lines file
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	gosync "sync"
+	"time"
+)
+
+// Record is one audited action
+type Record struct {
+	Time        time.Time   `json:"time"`
+	Action      string      `json:"action"`
+	Subject     string      `json:"subject"`
+	RequestedBy string      `json:"requested_by,omitempty"`
+	RemoteAddr  string      `json:"remote_addr,omitempty"`
+	Outcome     string      `json:"outcome"`
+	Details     interface{} `json:"details,omitempty"`
+	Error       string      `json:"error,omitempty"`
+}
+
+// Log appends audit records to a file; a Log with an empty path discards them
+type Log struct {
+	path string
+	mu   gosync.Mutex
+}
+
+// New creates an audit log writing to path
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the file the log writes to
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Write appends a record as a single JSON line, stamping the time if unset
+func (l *Log) Write(record Record) error {
+	if l.path == "" {
+		return nil
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLog_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log := New(path)
+
+	records := []Record{
+		{Action: "user_deprovision", Subject: "alice@example.com", RequestedBy: "hr", Outcome: "success"},
+		{Action: "user_deprovision", Subject: "bob@example.com", Outcome: "error", Error: "boom"},
+	}
+	for _, record := range records {
+		if err := log.Write(record); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var got []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid audit line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, record)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(got))
+	}
+	if got[0].Subject != "alice@example.com" || got[1].Error != "boom" {
+		t.Errorf("Unexpected records: %+v", got)
+	}
+	if got[0].Time.IsZero() {
+		t.Error("Expected record time to be set")
+	}
+}
+
+func TestLog_Disabled(t *testing.T) {
+	if err := New("").Write(Record{Action: "user_deprovision"}); err != nil {
+		t.Errorf("Expected no error for a disabled log, got %v", err)
+	}
+}
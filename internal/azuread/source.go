@@ -0,0 +1,200 @@
+package azuread
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// ErrGroupNotFound is returned when a configured group does not exist in the tenant
+var ErrGroupNotFound = errors.New("group not found in Azure AD")
+
+// membersPageSize is the largest page Microsoft Graph returns for group members
+const membersPageSize = 999
+
+// Source serves group membership from Microsoft Entra ID (Azure AD) groups through Microsoft
+// Graph. Groups are identified by their object IDs, and their members include the users of
+// nested groups.
+type Source struct {
+	graphURL   string
+	httpClient *http.Client
+}
+
+// Group is a Microsoft Graph group
+type Group struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	Mail        string `json:"mail"`
+}
+
+// User is a Microsoft Graph user
+type User struct {
+	ID                string `json:"id"`
+	Mail              string `json:"mail"`
+	UserPrincipalName string `json:"userPrincipalName"`
+	AccountEnabled    *bool  `json:"accountEnabled"`
+}
+
+// APIError is an error response from Microsoft Graph
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Microsoft Graph API error (status %d, %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// NewSource creates an Azure AD source authenticating as the configured app registration with the
+// client credentials flow. The app needs the GroupMember.Read.All and User.Read.All application
+// permissions. API and token requests are sent through httpClient, or a default client when nil.
+func NewSource(cfg config.AzureADSourceConfig, httpClient *http.Client) *Source {
+	if httpClient == nil {
+		httpClient = httpclient.New(httpclient.Options{})
+	}
+	graphURL := strings.TrimSuffix(cfg.GraphURL, "/")
+	if graphURL == "" {
+		graphURL = config.DefaultAzureADGraphURL
+	}
+	loginURL := strings.TrimSuffix(cfg.LoginURL, "/")
+	if loginURL == "" {
+		loginURL = config.DefaultAzureADLoginURL
+	}
+
+	cc := &clientcredentials.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", loginURL, url.PathEscape(cfg.TenantID)),
+		Scopes:       []string{graphURL + "/.default"},
+	}
+
+	ctx := httpclient.OAuthContext(httpClient)
+	oauthClient := cc.Client(ctx)
+	oauthClient.Timeout = httpClient.Timeout
+
+	return &Source{
+		graphURL:   graphURL,
+		httpClient: oauthClient,
+	}
+}
+
+// GetGroup returns the group with the given object ID. The group is keyed by its object ID as
+// Google groups are by email, and named after its display name
+func (s *Source) GetGroup(ctx context.Context, objectID string) (*gws.Group, error) {
+	var group Group
+	requestURL := fmt.Sprintf("%s/v1.0/groups/%s?$select=id,displayName,description,mail", s.graphURL, url.PathEscape(objectID))
+	if err := s.get(ctx, requestURL, &group); err != nil {
+		return nil, s.groupError(objectID, err)
+	}
+
+	name := group.DisplayName
+	if name == "" {
+		name = objectID
+	}
+	return &gws.Group{
+		ID:          group.ID,
+		Email:       objectID,
+		Name:        name,
+		Description: group.Description,
+	}, nil
+}
+
+// GetGroupMembers returns the users in the group with the given object ID, directly or through
+// nested groups. Members are identified by their mail address, or their user principal name when
+// they have none, and disabled accounts are reported as suspended
+func (s *Source) GetGroupMembers(ctx context.Context, objectID string) ([]*gws.GroupMember, error) {
+	requestURL := fmt.Sprintf("%s/v1.0/groups/%s/transitiveMembers/microsoft.graph.user?$select=id,mail,userPrincipalName,accountEnabled&$top=%d",
+		s.graphURL, url.PathEscape(objectID), membersPageSize)
+
+	var members []*gws.GroupMember
+	seen := make(map[string]bool)
+	for requestURL != "" {
+		var page struct {
+			Value    []User `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := s.get(ctx, requestURL, &page); err != nil {
+			return nil, s.groupError(objectID, err)
+		}
+
+		for _, user := range page.Value {
+			email := strings.ToLower(strings.TrimSpace(user.Mail))
+			if email == "" {
+				email = strings.ToLower(user.UserPrincipalName)
+			}
+			if email == "" || seen[email] {
+				continue
+			}
+			seen[email] = true
+
+			status := "ACTIVE"
+			if user.AccountEnabled != nil && !*user.AccountEnabled {
+				status = "SUSPENDED"
+			}
+			members = append(members, &gws.GroupMember{
+				ID:     user.ID,
+				Email:  email,
+				Role:   "MEMBER",
+				Type:   "USER",
+				Status: status,
+			})
+		}
+		requestURL = page.NextLink
+	}
+
+	return members, nil
+}
+
+// get reads a Microsoft Graph resource into out
+func (s *Source) get(ctx context.Context, requestURL string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to perform request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+
+		var body struct {
+			Error *APIError `json:"error"`
+		}
+		if err := json.Unmarshal(bodyBytes, &body); err == nil && body.Error != nil {
+			body.Error.StatusCode = resp.StatusCode
+			return body.Error
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(bodyBytes))}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// groupError reports a deleted group as ErrGroupNotFound, so the engine treats it as orphaned
+func (s *Source) groupError(objectID string, err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, objectID)
+	}
+	return fmt.Errorf("failed to read group %s: %w", objectID, err)
+}
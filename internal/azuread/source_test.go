@@ -0,0 +1,95 @@
+package azuread
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+const engineeringID = "6f1c2a0e-8d4b-4c3a-9f1e-2b7d5a9c0e41"
+
+// newTestSource serves a token endpoint and the Graph endpoints of one group with two pages of members
+func newTestSource(t *testing.T) *Source {
+	t.Helper()
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/contoso.onmicrosoft.com/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != server.URL+"/.default" {
+			http.Error(w, "bad token request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"graph-token","token_type":"Bearer","expires_in":3600}`)
+	})
+	mux.HandleFunc("/v1.0/groups/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer graph-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1.0/groups/"+engineeringID:
+			fmt.Fprintf(w, `{"id":%q,"displayName":"Engineering","description":"All engineers"}`, engineeringID)
+		case r.URL.Path == "/v1.0/groups/"+engineeringID+"/transitiveMembers/microsoft.graph.user" && r.URL.Query().Get("page") == "":
+			fmt.Fprintf(w, `{"value":[{"id":"u1","mail":"Alice@Contoso.com","accountEnabled":true},{"id":"u2","userPrincipalName":"bob@contoso.onmicrosoft.com","accountEnabled":false}],"@odata.nextLink":%q}`,
+				server.URL+"/v1.0/groups/"+engineeringID+"/transitiveMembers/microsoft.graph.user?page=2")
+		case r.URL.Path == "/v1.0/groups/"+engineeringID+"/transitiveMembers/microsoft.graph.user":
+			fmt.Fprint(w, `{"value":[{"id":"u1","mail":"alice@contoso.com"},{"id":"u3","mail":"carol@contoso.com"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":"Request_ResourceNotFound","message":"Resource does not exist"}}`)
+		}
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return NewSource(config.AzureADSourceConfig{
+		TenantID:     "contoso.onmicrosoft.com",
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		GraphURL:     server.URL,
+		LoginURL:     server.URL,
+	}, server.Client())
+}
+
+func TestGetGroup(t *testing.T) {
+	source := newTestSource(t)
+
+	group, err := source.GetGroup(context.Background(), engineeringID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if group.Email != engineeringID || group.Name != "Engineering" || group.Description != "All engineers" {
+		t.Errorf("Expected the group keyed by its object ID, got %+v", group)
+	}
+
+	_, err = source.GetGroup(context.Background(), "0b9d7e55-3c1a-4f2e-8a6b-91d0c4e7f312")
+	if !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound for a deleted group, got %v", err)
+	}
+}
+
+func TestGetGroupMembers(t *testing.T) {
+	source := newTestSource(t)
+
+	members, err := source.GetGroupMembers(context.Background(), engineeringID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, member := range members {
+		got = append(got, member.Email+":"+member.Status)
+	}
+	want := "alice@contoso.com:ACTIVE,bob@contoso.onmicrosoft.com:SUSPENDED,carol@contoso.com:ACTIVE"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(got, ","))
+	}
+}
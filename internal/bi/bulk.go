@@ -0,0 +1,148 @@
+package bi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxBulkOperations is how many operations are sent in one SCIM Bulk request
+const MaxBulkOperations = 100
+
+// BulkRequest is a SCIM Bulk request (RFC 7644 section 3.7)
+type BulkRequest struct {
+	Schemas    []string        `json:"schemas"`
+	Operations []BulkOperation `json:"Operations"`
+}
+
+// BulkOperation is one operation of a SCIM Bulk request or response
+type BulkOperation struct {
+	Method   string          `json:"method"`
+	BulkID   string          `json:"bulkId,omitempty"`
+	Path     string          `json:"path,omitempty"`
+	Data     interface{}     `json:"data,omitempty"`
+	Location string          `json:"location,omitempty"`
+	Status   json.RawMessage `json:"status,omitempty"` // "201" per the RFC; some servers send a number or an object
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// BulkResponse is the response to a SCIM Bulk request
+type BulkResponse struct {
+	Schemas    []string        `json:"schemas"`
+	Operations []BulkOperation `json:"Operations"`
+}
+
+// BulkUserResult is the outcome of creating one user in a bulk request
+type BulkUserResult struct {
+	User *User // Created user; nil when Err is set
+	Err  error
+}
+
+// CreateUsersBulk creates users with SCIM Bulk requests of up to MaxBulkOperations each.
+// Results are in the order of users; an error is returned only when a request fails as a whole
+func (c *Client) CreateUsersBulk(ctx context.Context, users []*User) ([]BulkUserResult, error) {
+	results := make([]BulkUserResult, 0, len(users))
+	for start := 0; start < len(users); start += MaxBulkOperations {
+		end := start + MaxBulkOperations
+		if end > len(users) {
+			end = len(users)
+		}
+		batch, err := c.createUsersBatch(ctx, users[start:end])
+		if err != nil {
+			return results, err
+		}
+		results = append(results, batch...)
+	}
+	return results, nil
+}
+
+// createUsersBatch sends one bulk request creating users
+func (c *Client) createUsersBatch(ctx context.Context, users []*User) ([]BulkUserResult, error) {
+	request := BulkRequest{Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:BulkRequest"}}
+	for i, user := range users {
+		user.Schemas = userSchemas(user)
+		user.Active = true
+		request.Operations = append(request.Operations, BulkOperation{
+			Method: "POST",
+			BulkID: strconv.Itoa(i),
+			Path:   "/Users",
+			Data:   user,
+		})
+	}
+
+	resp, err := c.makeRequest(ctx, "POST", c.scimBaseURL+"/Bulk", request)
+	for _, user := range users {
+		c.forgetUser(user.UserName, "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create users in bulk: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var response BulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+
+	results := make([]BulkUserResult, len(users))
+	answered := make([]bool, len(users))
+	for _, op := range response.Operations {
+		i, err := strconv.Atoi(op.BulkID)
+		if err != nil || i < 0 || i >= len(users) {
+			continue
+		}
+		answered[i] = true
+		results[i] = bulkUserResult(op)
+	}
+	for i := range results {
+		if !answered[i] {
+			results[i].Err = fmt.Errorf("bulk response has no result for %s", users[i].UserName)
+		}
+	}
+	return results, nil
+}
+
+// bulkUserResult converts the response to one bulk POST into the created user or its error
+func bulkUserResult(op BulkOperation) BulkUserResult {
+	status := bulkStatus(op.Status)
+	if status >= 400 {
+		scimErr := &SCIMError{StatusCode: status, Status: strconv.Itoa(status)}
+		_ = json.Unmarshal(op.Response, scimErr)
+		return BulkUserResult{Err: scimErr}
+	}
+
+	var user User
+	if len(op.Response) > 0 {
+		if err := json.Unmarshal(op.Response, &user); err != nil {
+			return BulkUserResult{Err: fmt.Errorf("failed to decode created user: %w", err)}
+		}
+	}
+	// The RFC only requires the location of the created resource
+	if user.ID == "" && op.Location != "" {
+		user.ID = op.Location[strings.LastIndex(op.Location, "/")+1:]
+	}
+	if user.ID == "" {
+		return BulkUserResult{Err: fmt.Errorf("bulk response has no ID for the created user")}
+	}
+	return BulkUserResult{User: &user}
+}
+
+// bulkStatus reads an operation status sent as "201", 201 or {"code": 201}
+func bulkStatus(raw json.RawMessage) int {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		status, _ := strconv.Atoi(text)
+		return status
+	}
+	var number int
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number
+	}
+	var object struct {
+		Code int `json:"code"`
+	}
+	_ = json.Unmarshal(raw, &object)
+	return object.Code
+}
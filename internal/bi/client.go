@@ -2,21 +2,40 @@ package bi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/cache"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
 )
 
 // Client handles Beyond Identity SCIM API operations
 type Client struct {
-	apiToken     string
-	scimBaseURL  string
-	nativeAPIURL string
-	httpClient   *http.Client
+	apiToken      string
+	authorization string // Authorization header of SCIM requests, see SetAuthorization
+	scimBaseURL   string
+	nativeAPIURL  string // Empty for SCIM services other than Beyond Identity
+	httpClient    *http.Client
+
+	quotaMu  sync.Mutex
+	quota    Quota // Rate limit from the latest response headers
+	requests int64 // Requests made, for the share of the quota used by a run
+
+	patchFallback    bool        // Rewrite groups with PUT when the tenant rejects PATCH
+	patchUnsupported atomic.Bool // The tenant rejected a group PATCH, so groups are always rewritten
+	removeByValue    bool        // Remove members by value rather than with a path filter
+
+	userLookups  *cache.Cache[string, *User]  // Lower-cased email -> user, nil when lookups are not cached
+	groupLookups *cache.Cache[string, *Group] // Display name -> group
 }
 
 // User represents a Beyond Identity SCIM user
@@ -25,6 +44,8 @@ type User struct {
 	ExternalID       string      `json:"externalId"`
 	UserName         string      `json:"userName"`
 	DisplayName      string      `json:"displayName"`
+	Name             *Name       `json:"name,omitempty"`
+	Title            string      `json:"title,omitempty"`
 	Emails           []Email     `json:"emails"`
 	Active           bool        `json:"active"`
 	HasActivePasskey bool        `json:"hasActivePasskey,omitempty"`
@@ -35,6 +56,35 @@ type User struct {
 	// Try Beyond Identity extension schema patterns
 	BeyondIdentityExt map[string]interface{} `json:"urn:ietf:params:scim:schemas:extension:beyondidentity:2.0:User,omitempty"`
 	ByndIDExt         map[string]interface{} `json:"urn:ietf:params:scim:schemas:extension:byndid:2.0:User,omitempty"`
+	Enterprise        *EnterpriseUser        `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+}
+
+// SCIM schemas of user resources
+const (
+	UserSchema           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	EnterpriseUserSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+)
+
+// Name represents the components of a user's name
+type Name struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	Formatted  string `json:"formatted,omitempty"`
+}
+
+// EnterpriseUser represents the SCIM Enterprise User extension attributes
+type EnterpriseUser struct {
+	EmployeeNumber string `json:"employeeNumber,omitempty"`
+	Department     string `json:"department,omitempty"`
+	Organization   string `json:"organization,omitempty"`
+}
+
+// userSchemas returns the schemas a user resource uses
+func userSchemas(user *User) []string {
+	if user.Enterprise != nil {
+		return []string{UserSchema, EnterpriseUserSchema}
+	}
+	return []string{UserSchema}
 }
 
 // Email represents a user's email address
@@ -53,10 +103,32 @@ type UserGroup struct {
 
 // Group represents a Beyond Identity SCIM group
 type Group struct {
-	ID          string        `json:"id,omitempty"`
-	DisplayName string        `json:"displayName"`
-	Members     []GroupMember `json:"members,omitempty"`
-	Schemas     []string      `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []GroupMember    `json:"members,omitempty"`
+	Schemas     []string         `json:"schemas"`
+	Annotation  *GroupAnnotation `json:"urn:ietf:params:scim:schemas:extension:scim-sync:2.0:Group,omitempty"`
+}
+
+// GroupAnnotationSchema is the SCIM extension marking groups managed by this integration
+const GroupAnnotationSchema = "urn:ietf:params:scim:schemas:extension:scim-sync:2.0:Group"
+
+// GroupAnnotation tells Beyond Identity administrators that a group is managed by the sync, and
+// from which source group
+type GroupAnnotation struct {
+	ManagedBy   string    `json:"managedBy"`
+	Version     string    `json:"version,omitempty"`
+	SourceGroup string    `json:"sourceGroup"`
+	LastSynced  time.Time `json:"lastSynced"`
+}
+
+// groupSchemas returns the schemas a group resource uses
+func groupSchemas(group *Group) []string {
+	schemas := []string{"urn:ietf:params:scim:schemas:core:2.0:Group"}
+	if group.Annotation != nil {
+		schemas = append(schemas, GroupAnnotationSchema)
+	}
+	return schemas
 }
 
 // GroupMember represents a member of a group
@@ -66,6 +138,16 @@ type GroupMember struct {
 	Display string `json:"display,omitempty"`
 }
 
+// NativeUser represents a user returned by the Native API, including enrollment state
+type NativeUser struct {
+	ID               string    `json:"id"`
+	EmailAddress     string    `json:"email_address"`
+	DisplayName      string    `json:"display_name"`
+	State            string    `json:"state"`
+	HasActivePasskey bool      `json:"has_active_passkey"`
+	CreateTime       time.Time `json:"create_time"`
+}
+
 // PatchOperation represents a SCIM PATCH operation
 type PatchOperation struct {
 	Op    string      `json:"op"`
@@ -84,26 +166,77 @@ type SCIMError struct {
 	Schemas []string `json:"schemas"`
 	Detail  string   `json:"detail"`
 	Status  string   `json:"status"`
+
+	StatusCode int `json:"-"` // HTTP status of the response
 }
 
 func (e *SCIMError) Error() string {
 	return fmt.Sprintf("SCIM API error (status %s): %s", e.Status, e.Detail)
 }
 
+// ErrNativeAPIUnavailable is returned by GetUserStatus when passkey status cannot be read from the Native API
+var ErrNativeAPIUnavailable = errors.New("native API unavailable")
+
+// HTTPError represents an error response that is not a SCIM error document
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
 // NewClient creates a new Beyond Identity SCIM client
 func NewClient(apiToken, scimBaseURL, nativeAPIURL string) *Client {
+	return NewClientWithHTTPClient(apiToken, scimBaseURL, nativeAPIURL, httpclient.New(httpclient.Options{}))
+}
+
+// NewClientWithHTTPClient creates a new Beyond Identity SCIM client using the given HTTP client
+func NewClientWithHTTPClient(apiToken, scimBaseURL, nativeAPIURL string, httpClient *http.Client) *Client {
 	return &Client{
-		apiToken:     apiToken,
-		scimBaseURL:  strings.TrimSuffix(scimBaseURL, "/"),
-		nativeAPIURL: strings.TrimSuffix(nativeAPIURL, "/"),
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		apiToken:      apiToken,
+		authorization: "Bearer " + apiToken,
+		scimBaseURL:   strings.TrimSuffix(scimBaseURL, "/"),
+		nativeAPIURL:  strings.TrimSuffix(nativeAPIURL, "/"),
+		httpClient:    httpClient,
 	}
 }
 
+// SetPatchFallback makes group changes fall back to a read-modify-write PUT of the whole group
+// when the tenant does not support PATCH for groups
+func (c *Client) SetPatchFallback(enabled bool) {
+	c.patchFallback = enabled
+}
+
+// SetAuthorization replaces the bearer API token sent with SCIM requests by the given
+// Authorization header value, e.g. Basic credentials of another SCIM service. An empty value sends
+// none, for HTTP clients whose transport authenticates requests
+func (c *Client) SetAuthorization(value string) {
+	c.authorization = value
+}
+
+// SetPutForGroups makes every group change rewrite the whole group with PUT, for SCIM services
+// that do not support PATCH for groups
+func (c *Client) SetPutForGroups() {
+	c.patchFallback = true
+	c.patchUnsupported.Store(true)
+}
+
+// SetRemoveMembersByValue makes PATCH requests remove members by listing them as the operation's
+// value instead of with a members[value eq "..."] path filter, for SCIM services that do not
+// support filters in PATCH paths
+func (c *Client) SetRemoveMembersByValue(enabled bool) {
+	c.removeByValue = enabled
+}
+
 // makeRequest performs an HTTP request with proper authentication and error handling
-func (c *Client) makeRequest(method, url string, body interface{}) (*http.Response, error) {
+func (c *Client) makeRequest(ctx context.Context, method, url string, body interface{}) (*http.Response, error) {
+	return c.makeRequestWithHeaders(ctx, method, url, body, nil)
+}
+
+// makeRequestWithHeaders performs an HTTP request like makeRequest, adding the given headers
+func (c *Client) makeRequestWithHeaders(ctx context.Context, method, url string, body interface{}, headers map[string]string) (*http.Response, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -113,19 +246,25 @@ func (c *Client) makeRequest(method, url string, body interface{}) (*http.Respon
 		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
 	req.Header.Set("Content-Type", "application/scim+json")
 	req.Header.Set("Accept", "application/scim+json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform request: %w", err)
 	}
+	c.recordQuota(resp.Header)
 
 	// Handle SCIM errors
 	if resp.StatusCode >= 400 {
@@ -134,21 +273,23 @@ func (c *Client) makeRequest(method, url string, body interface{}) (*http.Respon
 
 		var scimErr SCIMError
 		if err := json.Unmarshal(bodyBytes, &scimErr); err == nil {
+			scimErr.StatusCode = resp.StatusCode
 			return resp, &scimErr
 		}
 
-		return resp, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
+		return resp, &HTTPError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 	}
 
 	return resp, nil
 }
 
 // CreateUser creates a new user in Beyond Identity
-func (c *Client) CreateUser(user *User) (*User, error) {
-	user.Schemas = []string{"urn:ietf:params:scim:schemas:core:2.0:User"}
+func (c *Client) CreateUser(ctx context.Context, user *User) (*User, error) {
+	user.Schemas = userSchemas(user)
 	user.Active = true
 
-	resp, err := c.makeRequest("POST", c.scimBaseURL+"/Users", user)
+	resp, err := c.makeRequest(ctx, "POST", c.scimBaseURL+"/Users", user)
+	c.forgetUser(user.UserName, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -163,10 +304,11 @@ func (c *Client) CreateUser(user *User) (*User, error) {
 }
 
 // UpdateUser updates an existing user in Beyond Identity
-func (c *Client) UpdateUser(userID string, user *User) (*User, error) {
-	user.Schemas = []string{"urn:ietf:params:scim:schemas:core:2.0:User"}
+func (c *Client) UpdateUser(ctx context.Context, userID string, user *User) (*User, error) {
+	user.Schemas = userSchemas(user)
 
-	resp, err := c.makeRequest("PUT", c.scimBaseURL+"/Users/"+userID, user)
+	resp, err := c.makeRequest(ctx, "PUT", c.scimBaseURL+"/Users/"+userID, user)
+	c.forgetUser(user.UserName, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
@@ -181,8 +323,8 @@ func (c *Client) UpdateUser(userID string, user *User) (*User, error) {
 }
 
 // GetUser retrieves a user by ID
-func (c *Client) GetUser(userID string) (*User, error) {
-	resp, err := c.makeRequest("GET", c.scimBaseURL+"/Users/"+userID, nil)
+func (c *Client) GetUser(ctx context.Context, userID string) (*User, error) {
+	resp, err := c.makeRequest(ctx, "GET", c.scimBaseURL+"/Users/"+userID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -196,10 +338,44 @@ func (c *Client) GetUser(userID string) (*User, error) {
 	return &user, nil
 }
 
+// DeactivateUser sets a user inactive so they can no longer authenticate
+func (c *Client) DeactivateUser(ctx context.Context, userID string) error {
+	patchRequest := PatchRequest{
+		Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		Operations: []PatchOperation{{
+			Op:    "replace",
+			Path:  "active",
+			Value: false,
+		}},
+	}
+
+	resp, err := c.makeRequest(ctx, "PATCH", c.scimBaseURL+"/Users/"+userID, patchRequest)
+	c.forgetUser("", userID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return nil
+}
+
+// DeleteUser permanently deletes a user by ID
+func (c *Client) DeleteUser(ctx context.Context, userID string) error {
+	resp, err := c.makeRequest(ctx, "DELETE", c.scimBaseURL+"/Users/"+userID, nil)
+	c.forgetUser("", userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return nil
+}
+
 // GetUserStatus retrieves the current enrollment status of a user (active AND has active passkey)
-func (c *Client) GetUserStatus(userEmail string) (bool, error) {
-	// First get the user from SCIM to check if they're active
-	user, err := c.FindUserByEmail(userEmail)
+func (c *Client) GetUserStatus(ctx context.Context, userEmail string) (bool, error) {
+	// First get the user from SCIM to check if they're active, bypassing the lookup cache so
+	// users deactivated since it was filled are seen
+	user, err := c.findUser(ctx, fmt.Sprintf(`userName eq "%s"`, userEmail))
 	if err != nil {
 		return false, fmt.Errorf("failed to find user by email: %w", err)
 	}
@@ -213,14 +389,15 @@ func (c *Client) GetUserStatus(userEmail string) (bool, error) {
 		return false, nil
 	}
 
-	// Now check passkey status using Native API
+	// Now check passkey status using Native API, which other SCIM services do not have
+	if c.nativeAPIURL == "" {
+		return false, fmt.Errorf("%w: no Native API URL configured", ErrNativeAPIUnavailable)
+	}
 	fmt.Printf("DEBUG: About to check passkey status for %s via Native API\n", userEmail)
-	hasActivePasskey, err := c.getUserPasskeyStatus(userEmail)
+	hasActivePasskey, err := c.getUserPasskeyStatus(ctx, userEmail)
 	if err != nil {
-		// If we can't get passkey status, log warning but don't fail the sync
-		fmt.Printf("WARNING: Failed to get passkey status for %s: %v\n", userEmail, err)
-		// Fall back to just active status for now
-		return user.Active, nil
+		// Without passkey status enrollment cannot be decided; callers skip enrollment steps
+		return false, fmt.Errorf("%w: %w", ErrNativeAPIUnavailable, err)
 	}
 
 	fmt.Printf("DEBUG: User %s - Active: %t, HasActivePasskey (from Native API): %t\n", userEmail, user.Active, hasActivePasskey)
@@ -230,12 +407,12 @@ func (c *Client) GetUserStatus(userEmail string) (bool, error) {
 }
 
 // getUserPasskeyStatus checks if a user has active passkeys using the Native API
-func (c *Client) getUserPasskeyStatus(userEmail string) (bool, error) {
+func (c *Client) getUserPasskeyStatus(ctx context.Context, userEmail string) (bool, error) {
 	// Query the native API to get ALL users (we'll filter in code since the API works with page_size)
 	requestURL := fmt.Sprintf("%s/users?page_size=50", c.nativeAPIURL)
 	fmt.Printf("DEBUG: Querying Native API: %s\n", requestURL)
 
-	resp, err := c.makeNativeAPIRequest("GET", requestURL, nil)
+	resp, err := c.makeNativeAPIRequest(ctx, "GET", requestURL, nil)
 	if err != nil {
 		fmt.Printf("DEBUG: Native API request failed: %v\n", err)
 		return false, fmt.Errorf("failed to query native API: %w", err)
@@ -273,7 +450,7 @@ func (c *Client) getUserPasskeyStatus(userEmail string) (bool, error) {
 }
 
 // makeNativeAPIRequest performs an HTTP request to the Native API
-func (c *Client) makeNativeAPIRequest(method, url string, body interface{}) (*http.Response, error) {
+func (c *Client) makeNativeAPIRequest(ctx context.Context, method, url string, body interface{}) (*http.Response, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -283,7 +460,7 @@ func (c *Client) makeNativeAPIRequest(method, url string, body interface{}) (*ht
 		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -296,35 +473,111 @@ func (c *Client) makeNativeAPIRequest(method, url string, body interface{}) (*ht
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform request: %w", err)
 	}
+	c.recordQuota(resp.Header)
 
 	// Handle API errors
 	if resp.StatusCode >= 400 {
 		defer func() { _ = resp.Body.Close() }()
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return resp, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
+		return resp, &HTTPError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 	}
 
 	return resp, nil
 }
 
-// FindUserByEmail searches for a user by email address
-func (c *Client) FindUserByEmail(email string) (*User, error) {
-	filter := fmt.Sprintf(`userName eq "%s"`, email)
+// ListNativeUsers retrieves every user from the Native API, following pagination
+func (c *Client) ListNativeUsers(ctx context.Context) ([]NativeUser, error) {
+	var users []NativeUser
+	pageToken := ""
+
+	for {
+		requestURL := fmt.Sprintf("%s/users?page_size=100", c.nativeAPIURL)
+		if pageToken != "" {
+			requestURL += "&page_token=" + url.QueryEscape(pageToken)
+		}
+
+		resp, err := c.makeNativeAPIRequest(ctx, "GET", requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		var page struct {
+			Users         []NativeUser `json:"users"`
+			NextPageToken string       `json:"next_page_token"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode users: %w", err)
+		}
+
+		users = append(users, page.Users...)
+		if page.NextPageToken == "" {
+			return users, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// ListUsers retrieves every user with all available schemas, following pagination
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	startIndex := 1
+
+	for {
+		requestURL := fmt.Sprintf("%s/Users?startIndex=%d&count=100&attributes=*", c.scimBaseURL, startIndex)
+
+		resp, err := c.makeRequest(ctx, "GET", requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		var page struct {
+			TotalResults int    `json:"totalResults"`
+			Resources    []User `json:"Resources"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode users: %w", err)
+		}
+
+		users = append(users, page.Resources...)
+		startIndex += len(page.Resources)
+		if len(page.Resources) == 0 || startIndex > page.TotalResults {
+			return users, nil
+		}
+	}
+}
+
+// FindUserByEmail searches for a user by email address, answering from the lookup cache when it
+// is enabled
+func (c *Client) FindUserByEmail(ctx context.Context, email string) (*User, error) {
+	if user, ok := c.cachedUser(email); ok {
+		return user, nil
+	}
+	user, err := c.findUser(ctx, fmt.Sprintf(`userName eq "%s"`, email))
+	if err != nil {
+		return nil, err
+	}
+	c.cacheUser(email, user)
+	return user, nil
+}
+
+// FindUserByExternalID searches for a user by their externalId
+func (c *Client) FindUserByExternalID(ctx context.Context, externalID string) (*User, error) {
+	return c.findUser(ctx, fmt.Sprintf(`externalId eq "%s"`, externalID))
+}
+
+// findUser returns the first user matching a SCIM filter, or nil when none does
+func (c *Client) findUser(ctx context.Context, filter string) (*User, error) {
 	// Try to request all available schemas by adding attributes parameter
 	requestURL := fmt.Sprintf("%s/Users?filter=%s&attributes=*", c.scimBaseURL, url.QueryEscape(filter))
 
-	resp, err := c.makeRequest("GET", requestURL, nil)
+	// Rate limiting is retried by the HTTP client's retry transport
+	resp, err := c.makeRequest(ctx, "GET", requestURL, nil)
 	if err != nil {
-		// Add a small delay and retry on rate limit
-		if strings.Contains(err.Error(), "429") {
-			time.Sleep(2 * time.Second)
-			resp, err = c.makeRequest("GET", requestURL, nil)
-			if err != nil {
-				return nil, fmt.Errorf("failed to search user after retry: %w", err)
-			}
-		} else {
-			return nil, fmt.Errorf("failed to search user: %w", err)
-		}
+		return nil, fmt.Errorf("failed to search user: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -345,10 +598,11 @@ func (c *Client) FindUserByEmail(email string) (*User, error) {
 }
 
 // CreateGroup creates a new group in Beyond Identity
-func (c *Client) CreateGroup(group *Group) (*Group, error) {
-	group.Schemas = []string{"urn:ietf:params:scim:schemas:core:2.0:Group"}
+func (c *Client) CreateGroup(ctx context.Context, group *Group) (*Group, error) {
+	group.Schemas = groupSchemas(group)
 
-	resp, err := c.makeRequest("POST", c.scimBaseURL+"/Groups", group)
+	resp, err := c.makeRequest(ctx, "POST", c.scimBaseURL+"/Groups", group)
+	c.forgetGroup(group.DisplayName, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create group: %w", err)
 	}
@@ -362,12 +616,26 @@ func (c *Client) CreateGroup(group *Group) (*Group, error) {
 	return &createdGroup, nil
 }
 
-// FindGroupByDisplayName searches for a group by display name
-func (c *Client) FindGroupByDisplayName(displayName string) (*Group, error) {
+// FindGroupByDisplayName searches for a group by display name, answering from the lookup cache
+// when it is enabled
+func (c *Client) FindGroupByDisplayName(ctx context.Context, displayName string) (*Group, error) {
+	if group, ok := c.cachedGroup(displayName); ok {
+		return group, nil
+	}
+	group, err := c.findGroup(ctx, displayName)
+	if err != nil {
+		return nil, err
+	}
+	c.cacheGroup(displayName, group)
+	return group, nil
+}
+
+// findGroup returns the group named displayName, or nil when there is none
+func (c *Client) findGroup(ctx context.Context, displayName string) (*Group, error) {
 	filter := fmt.Sprintf(`displayName eq "%s"`, displayName)
 	requestURL := fmt.Sprintf("%s/Groups?filter=%s", c.scimBaseURL, url.QueryEscape(filter))
 
-	resp, err := c.makeRequest("GET", requestURL, nil)
+	resp, err := c.makeRequest(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search group: %w", err)
 	}
@@ -389,11 +657,102 @@ func (c *Client) FindGroupByDisplayName(displayName string) (*Group, error) {
 	return &searchResult.Resources[0], nil
 }
 
+// RenameGroup changes the display name of a group
+func (c *Client) RenameGroup(ctx context.Context, groupID, displayName string) error {
+	patchRequest := PatchRequest{
+		Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		Operations: []PatchOperation{{
+			Op:    "replace",
+			Path:  "displayName",
+			Value: displayName,
+		}},
+	}
+
+	err := c.patchGroup(ctx, groupID, patchRequest, func(group *Group) {
+		group.DisplayName = displayName
+	})
+	c.forgetGroup(displayName, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to rename group: %w", err)
+	}
+	return nil
+}
+
+// AnnotateGroup writes the marker identifying a group as managed by this integration
+func (c *Client) AnnotateGroup(ctx context.Context, groupID string, annotation GroupAnnotation) error {
+	patchRequest := PatchRequest{
+		Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		Operations: []PatchOperation{{
+			Op:    "replace",
+			Path:  GroupAnnotationSchema,
+			Value: annotation,
+		}},
+	}
+
+	err := c.patchGroup(ctx, groupID, patchRequest, func(group *Group) {
+		group.Annotation = &annotation
+	})
+	c.forgetGroup("", groupID)
+	if err != nil {
+		return fmt.Errorf("failed to annotate group: %w", err)
+	}
+	return nil
+}
+
+// DeleteGroup permanently deletes a group by ID
+func (c *Client) DeleteGroup(ctx context.Context, groupID string) error {
+	resp, err := c.makeRequest(ctx, "DELETE", c.scimBaseURL+"/Groups/"+groupID, nil)
+	c.forgetGroup("", groupID)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return nil
+}
+
+// ListGroups retrieves every group whose display name starts with prefix, following pagination.
+// Members are not included
+func (c *Client) ListGroups(ctx context.Context, prefix string) ([]Group, error) {
+	var groups []Group
+	startIndex := 1
+
+	for {
+		requestURL := fmt.Sprintf("%s/Groups?startIndex=%d&count=100&excludedAttributes=members", c.scimBaseURL, startIndex)
+
+		resp, err := c.makeRequest(ctx, "GET", requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list groups: %w", err)
+		}
+
+		var page struct {
+			TotalResults int     `json:"totalResults"`
+			ItemsPerPage int     `json:"itemsPerPage"`
+			Resources    []Group `json:"Resources"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode groups: %w", err)
+		}
+
+		for _, group := range page.Resources {
+			if strings.HasPrefix(group.DisplayName, prefix) {
+				groups = append(groups, group)
+			}
+		}
+		startIndex += len(page.Resources)
+		if len(page.Resources) == 0 || startIndex > page.TotalResults {
+			return groups, nil
+		}
+	}
+}
+
 // GetGroupWithMembers retrieves a group by ID including its members
-func (c *Client) GetGroupWithMembers(groupID string) (*Group, error) {
+func (c *Client) GetGroupWithMembers(ctx context.Context, groupID string) (*Group, error) {
 	requestURL := fmt.Sprintf("%s/Groups/%s", c.scimBaseURL, groupID)
 
-	resp, err := c.makeRequest("GET", requestURL, nil)
+	resp, err := c.makeRequest(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get group: %w", err)
 	}
@@ -408,15 +767,23 @@ func (c *Client) GetGroupWithMembers(groupID string) (*Group, error) {
 }
 
 // UpdateGroupMembers updates group membership using PATCH operations
-func (c *Client) UpdateGroupMembers(groupID string, addMembers, removeMembers []GroupMember) error {
+func (c *Client) UpdateGroupMembers(ctx context.Context, groupID string, addMembers, removeMembers []GroupMember) error {
 	var operations []PatchOperation
 
 	// Add remove operations first
-	for _, member := range removeMembers {
+	if c.removeByValue && len(removeMembers) > 0 {
 		operations = append(operations, PatchOperation{
-			Op:   "remove",
-			Path: fmt.Sprintf("members[value eq \"%s\"]", member.Value),
+			Op:    "remove",
+			Path:  "members",
+			Value: removeMembers,
 		})
+	} else {
+		for _, member := range removeMembers {
+			operations = append(operations, PatchOperation{
+				Op:   "remove",
+				Path: fmt.Sprintf("members[value eq \"%s\"]", member.Value),
+			})
+		}
 	}
 
 	// Add add operations
@@ -437,11 +804,12 @@ func (c *Client) UpdateGroupMembers(groupID string, addMembers, removeMembers []
 		Operations: operations,
 	}
 
-	resp, err := c.makeRequest("PATCH", c.scimBaseURL+"/Groups/"+groupID, patchRequest)
+	err := c.patchGroup(ctx, groupID, patchRequest, func(group *Group) {
+		group.Members = applyMemberChanges(group.Members, addMembers, removeMembers)
+	})
+	c.forgetGroup("", groupID)
 	if err != nil {
 		return fmt.Errorf("failed to update group members: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
-
 	return nil
 }
@@ -0,0 +1,86 @@
+package bi
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/cache"
+)
+
+// SetLookupCache keeps the results of FindUserByEmail and FindGroupByDisplayName for ttl, including
+// users and groups that were not found, so lookups repeated within a sync and by later scheduled
+// syncs make no request. Writes through the client drop what they change; changes made outside it
+// are seen once the TTL passes. A ttl of 0 disables the cache
+func (c *Client) SetLookupCache(ttl time.Duration) {
+	c.userLookups = cache.New[string, *User](ttl)
+	c.groupLookups = cache.New[string, *Group](ttl)
+}
+
+// cachedUser returns a copy of the user cached for email; ok is false when the lookup was not cached
+func (c *Client) cachedUser(email string) (user *User, ok bool) {
+	cached, ok := c.userLookups.Get(strings.ToLower(email))
+	if !ok || cached == nil {
+		return nil, ok
+	}
+	copied := *cached
+	return &copied, true
+}
+
+// cacheUser records the result of looking up email, nil when there is no such user
+func (c *Client) cacheUser(email string, user *User) {
+	if user != nil {
+		copied := *user
+		user = &copied
+	}
+	c.userLookups.Set(strings.ToLower(email), user)
+}
+
+// forgetUser drops the cached lookups of a user that was written, by email and by ID
+func (c *Client) forgetUser(email, userID string) {
+	if email != "" {
+		c.userLookups.Delete(strings.ToLower(email))
+	}
+	if userID != "" {
+		c.userLookups.DeleteFunc(func(_ string, user *User) bool {
+			return user != nil && user.ID == userID
+		})
+	}
+}
+
+// cachedGroup returns a copy of the group cached for displayName; ok is false when the lookup was
+// not cached
+func (c *Client) cachedGroup(displayName string) (group *Group, ok bool) {
+	cached, ok := c.groupLookups.Get(displayName)
+	if !ok || cached == nil {
+		return nil, ok
+	}
+	return copyGroup(cached), true
+}
+
+// cacheGroup records the result of looking up displayName, nil when there is no such group
+func (c *Client) cacheGroup(displayName string, group *Group) {
+	if group != nil {
+		group = copyGroup(group)
+	}
+	c.groupLookups.Set(displayName, group)
+}
+
+// forgetGroup drops the cached lookups of a group that was written, by display name and by ID
+func (c *Client) forgetGroup(displayName, groupID string) {
+	if displayName != "" {
+		c.groupLookups.Delete(displayName)
+	}
+	if groupID != "" {
+		c.groupLookups.DeleteFunc(func(_ string, group *Group) bool {
+			return group != nil && group.ID == groupID
+		})
+	}
+}
+
+// copyGroup copies a group and its members, so callers cannot change a cached group
+func copyGroup(group *Group) *Group {
+	copied := *group
+	copied.Members = slices.Clone(group.Members)
+	return &copied
+}
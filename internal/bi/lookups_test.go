@@ -0,0 +1,85 @@
+package bi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_LookupCache(t *testing.T) {
+	requests := make(map[string]int)
+	var userExists bool
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method+" "+r.URL.Path]++
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/Users":
+			if !userExists {
+				_, _ = w.Write([]byte(`{"totalResults": 0, "Resources": []}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"totalResults": 1, "Resources": [{"id": "u-1", "userName": "alice@example.com"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/Users":
+			userExists = true
+			_ = json.NewEncoder(w).Encode(User{ID: "u-1", UserName: "alice@example.com"})
+		case r.Method == "GET" && r.URL.Path == "/Groups":
+			_, _ = w.Write([]byte(`{"totalResults": 1, "Resources": [{"id": "g-1", "displayName": "GWS_eng", "members": [{"value": "u-2"}]}]}`))
+		case r.Method == "PATCH" && r.URL.Path == "/Groups/g-1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClientWithHTTPClient("token", ts.URL, ts.URL, http.DefaultClient)
+	client.SetLookupCache(time.Hour)
+	ctx := context.Background()
+
+	// Users that were not found are cached until the client creates them
+	for i := 0; i < 2; i++ {
+		if user, err := client.FindUserByEmail(ctx, "alice@example.com"); err != nil || user != nil {
+			t.Fatalf("Expected no user, got %+v (%v)", user, err)
+		}
+	}
+	if requests["GET /Users"] != 1 {
+		t.Errorf("Expected one user lookup before the write, got %d", requests["GET /Users"])
+	}
+	if _, err := client.CreateUser(ctx, &User{UserName: "alice@example.com"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if user, err := client.FindUserByEmail(ctx, "Alice@example.com"); err != nil || user == nil || user.ID != "u-1" {
+			t.Fatalf("Expected the created user, got %+v (%v)", user, err)
+		}
+	}
+	if requests["GET /Users"] != 2 {
+		t.Errorf("Expected one more user lookup after the write, got %d", requests["GET /Users"])
+	}
+
+	// Groups are cached until the client writes them, and callers get copies
+	group, err := client.FindGroupByDisplayName(ctx, "GWS_eng")
+	if err != nil || group == nil {
+		t.Fatalf("Expected the group, got %+v (%v)", group, err)
+	}
+	group.Members[0].Value = "changed"
+	group, _ = client.FindGroupByDisplayName(ctx, "GWS_eng")
+	if group.Members[0].Value != "u-2" {
+		t.Errorf("Expected the cached group to be unchanged, got %+v", group.Members)
+	}
+	if requests["GET /Groups"] != 1 {
+		t.Errorf("Expected one group lookup before the write, got %d", requests["GET /Groups"])
+	}
+	if err := client.UpdateGroupMembers(ctx, "g-1", []GroupMember{{Value: "u-1"}}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.FindGroupByDisplayName(ctx, "GWS_eng"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests["GET /Groups"] != 2 {
+		t.Errorf("Expected the membership change to drop the cached group, got %d lookups", requests["GET /Groups"])
+	}
+}
@@ -0,0 +1,136 @@
+package bi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// putGroupAttempts is how often a group rewrite is retried when the group changed between reading
+// and writing it
+const putGroupAttempts = 3
+
+// ErrGroupModified is returned when a group kept changing while it was being rewritten with PUT
+var ErrGroupModified = errors.New("group was modified concurrently")
+
+// groupResource is the body of a group PUT; members are always sent, so an empty list clears them
+type groupResource struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id"`
+	DisplayName string           `json:"displayName"`
+	Members     []GroupMember    `json:"members"`
+	Annotation  *GroupAnnotation `json:"urn:ietf:params:scim:schemas:extension:scim-sync:2.0:Group,omitempty"`
+}
+
+// patchGroup sends a PATCH to a group. With the patch fallback enabled, a tenant that rejects
+// PATCH has the group read, changed by modify and written back with PUT instead, and every later
+// change skips the PATCH
+func (c *Client) patchGroup(ctx context.Context, groupID string, patch PatchRequest, modify func(*Group)) error {
+	if !c.patchFallback || !c.patchUnsupported.Load() {
+		resp, err := c.makeRequest(ctx, "PATCH", c.scimBaseURL+"/Groups/"+groupID, patch)
+		if err == nil {
+			_ = resp.Body.Close()
+			return nil
+		}
+		if !c.patchFallback || !isPatchUnsupported(err) {
+			return err
+		}
+		c.patchUnsupported.Store(true)
+	}
+	return c.putGroup(ctx, groupID, modify)
+}
+
+// putGroup rewrites a group with PUT. The write is made conditional on the version read, so
+// changes made in between are not overwritten; the group is read again when that happens
+func (c *Client) putGroup(ctx context.Context, groupID string, modify func(*Group)) error {
+	for attempt := 0; attempt < putGroupAttempts; attempt++ {
+		group, version, err := c.getGroupVersion(ctx, groupID)
+		if err != nil {
+			return err
+		}
+		modify(group)
+
+		body := groupResource{
+			Schemas:     groupSchemas(group),
+			ID:          groupID,
+			DisplayName: group.DisplayName,
+			Members:     append([]GroupMember{}, group.Members...),
+			Annotation:  group.Annotation,
+		}
+		var headers map[string]string
+		if version != "" {
+			headers = map[string]string{"If-Match": version}
+		}
+
+		resp, err := c.makeRequestWithHeaders(ctx, "PUT", c.scimBaseURL+"/Groups/"+groupID, body, headers)
+		if err == nil {
+			_ = resp.Body.Close()
+			return nil
+		}
+		if statusCode(err) != http.StatusPreconditionFailed {
+			return fmt.Errorf("failed to replace group: %w", err)
+		}
+	}
+	return fmt.Errorf("%w after %d attempts", ErrGroupModified, putGroupAttempts)
+}
+
+// getGroupVersion reads a group with its version, from the ETag header or meta.version
+func (c *Client) getGroupVersion(ctx context.Context, groupID string) (*Group, string, error) {
+	resp, err := c.makeRequest(ctx, "GET", c.scimBaseURL+"/Groups/"+groupID, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get group: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var group struct {
+		Group
+		Meta struct {
+			Version string `json:"version"`
+		} `json:"meta"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&group); err != nil {
+		return nil, "", fmt.Errorf("failed to decode group: %w", err)
+	}
+
+	version := resp.Header.Get("ETag")
+	if version == "" {
+		version = group.Meta.Version
+	}
+	return &group.Group, version, nil
+}
+
+// applyMemberChanges returns members without those removed and with those added, once each
+func applyMemberChanges(members, add, remove []GroupMember) []GroupMember {
+	removed := make(map[string]bool, len(remove))
+	for _, member := range remove {
+		removed[member.Value] = true
+	}
+
+	seen := make(map[string]bool, len(members)+len(add))
+	var result []GroupMember
+	for _, member := range append(append([]GroupMember{}, members...), add...) {
+		if removed[member.Value] || seen[member.Value] {
+			continue
+		}
+		seen[member.Value] = true
+		result = append(result, GroupMember{Value: member.Value})
+	}
+	return result
+}
+
+// isPatchUnsupported reports whether a PATCH failed because the tenant does not support it:
+// 501 Not Implemented or 405 Method Not Allowed, or a 400 whose message says so
+func isPatchUnsupported(err error) bool {
+	switch statusCode(err) {
+	case http.StatusNotImplemented, http.StatusMethodNotAllowed:
+		return true
+	case http.StatusBadRequest:
+		message := strings.ToLower(err.Error())
+		return strings.Contains(message, "patch") &&
+			(strings.Contains(message, "not supported") || strings.Contains(message, "unsupported") || strings.Contains(message, "not implemented"))
+	}
+	return false
+}
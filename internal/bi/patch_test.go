@@ -0,0 +1,244 @@
+package bi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsPatchUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"not implemented", &HTTPError{StatusCode: 501, Body: "Not Implemented"}, true},
+		{"method not allowed", &SCIMError{Detail: "Method not allowed", StatusCode: 405}, true},
+		{"bad request saying patch is not supported", &SCIMError{Detail: "PATCH is not supported for Groups", StatusCode: 400}, true},
+		{"bad request saying patch is unsupported", &HTTPError{StatusCode: 400, Body: "Unsupported operation: patch"}, true},
+		{"bad request about the payload", &SCIMError{Detail: "Invalid value for members", StatusCode: 400}, false},
+		{"not found", &HTTPError{StatusCode: 404, Body: "PATCH not implemented"}, false},
+		{"wrapped", fmt.Errorf("failed: %w", &HTTPError{StatusCode: 501}), true},
+		{"network error", errors.New("connection reset"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPatchUnsupported(tt.err); got != tt.want {
+				t.Errorf("isPatchUnsupported(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyMemberChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []string
+		add     []string
+		remove  []string
+		want    []string
+	}{
+		{"add and remove", []string{"u-1", "u-2"}, []string{"u-3"}, []string{"u-1"}, []string{"u-2", "u-3"}},
+		{"add existing member once", []string{"u-1"}, []string{"u-1", "u-2", "u-2"}, nil, []string{"u-1", "u-2"}},
+		{"remove wins over add", []string{"u-1"}, []string{"u-2"}, []string{"u-2"}, []string{"u-1"}},
+		{"remove everyone", []string{"u-1", "u-2"}, nil, []string{"u-1", "u-2"}, nil},
+		{"remove missing member", nil, []string{"u-1"}, []string{"u-9"}, []string{"u-1"}},
+	}
+
+	toMembers := func(values []string) []GroupMember {
+		var members []GroupMember
+		for _, value := range values {
+			members = append(members, GroupMember{Value: value, Display: "display " + value})
+		}
+		return members
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyMemberChanges(toMembers(tt.members), toMembers(tt.add), toMembers(tt.remove))
+			var values []string
+			for _, member := range got {
+				if member.Display != "" {
+					t.Errorf("Expected only member values to be sent, got %+v", member)
+				}
+				values = append(values, member.Value)
+			}
+			if strings.Join(values, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected members %v, got %v", tt.want, values)
+			}
+		})
+	}
+}
+
+// fakeGroupServer serves one group that rejects PATCH with patchStatus, answering PUTs with the
+// statuses in putStatuses in turn and 200 after them
+type fakeGroupServer struct {
+	t           *testing.T
+	patchStatus int
+	putStatuses []int
+	useETag     bool
+
+	members  []string
+	version  int
+	requests map[string]int
+	ifMatch  []string
+	put      groupResource
+}
+
+func (f *fakeGroupServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests[r.Method]++
+	if r.URL.Path != "/Groups/g-1" {
+		f.t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		return
+	}
+
+	switch r.Method {
+	case "PATCH":
+		w.WriteHeader(f.patchStatus)
+		_, _ = w.Write([]byte(`{"detail": "PATCH not supported"}`))
+	case "GET":
+		group := map[string]interface{}{"id": "g-1", "displayName": "GWS_eng"}
+		var members []GroupMember
+		for _, value := range f.members {
+			members = append(members, GroupMember{Value: value})
+		}
+		group["members"] = members
+		version := fmt.Sprintf(`W/"%d"`, f.version)
+		if f.useETag {
+			w.Header().Set("ETag", version)
+		} else {
+			group["meta"] = map[string]string{"version": version}
+		}
+		_ = json.NewEncoder(w).Encode(group)
+	case "PUT":
+		f.ifMatch = append(f.ifMatch, r.Header.Get("If-Match"))
+		if len(f.putStatuses) > 0 {
+			status := f.putStatuses[0]
+			f.putStatuses = f.putStatuses[1:]
+			// Someone else changed the group in the meantime
+			f.version++
+			f.members = append(f.members, fmt.Sprintf("other-%d", f.version))
+			w.WriteHeader(status)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&f.put); err != nil {
+			f.t.Errorf("Failed to decode PUT body: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(f.put)
+	}
+}
+
+func newPatchTestClient(t *testing.T, server *fakeGroupServer, fallback bool) *Client {
+	server.t = t
+	server.requests = make(map[string]int)
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	client := NewClientWithHTTPClient("token", ts.URL, ts.URL, http.DefaultClient)
+	client.SetPatchFallback(fallback)
+	return client
+}
+
+func TestClient_PatchFallback(t *testing.T) {
+	for _, status := range []int{http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server := &fakeGroupServer{patchStatus: status, useETag: true, members: []string{"u-1", "u-2"}}
+			client := newPatchTestClient(t, server, true)
+			ctx := context.Background()
+
+			if err := client.UpdateGroupMembers(ctx, "g-1", []GroupMember{{Value: "u-3"}}, []GroupMember{{Value: "u-1"}}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var members []string
+			for _, member := range server.put.Members {
+				members = append(members, member.Value)
+			}
+			if strings.Join(members, ",") != "u-2,u-3" {
+				t.Errorf("Expected the group rewritten with u-2,u-3, got %v", members)
+			}
+			if len(server.ifMatch) != 1 || server.ifMatch[0] != `W/"0"` {
+				t.Errorf("Expected the PUT to be conditional on the ETag read, got %v", server.ifMatch)
+			}
+
+			// Once rejected, PATCH is not tried again
+			if err := client.RenameGroup(ctx, "g-1", "GWS_engineering"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if server.requests["PATCH"] != 1 || server.requests["PUT"] != 2 {
+				t.Errorf("Expected one PATCH and two PUTs, got %v", server.requests)
+			}
+			if server.put.DisplayName != "GWS_engineering" {
+				t.Errorf("Expected the rename to be written with PUT, got %q", server.put.DisplayName)
+			}
+		})
+	}
+}
+
+func TestClient_PatchFallbackDisabled(t *testing.T) {
+	server := &fakeGroupServer{patchStatus: http.StatusNotImplemented}
+	client := newPatchTestClient(t, server, false)
+
+	err := client.UpdateGroupMembers(context.Background(), "g-1", []GroupMember{{Value: "u-3"}}, nil)
+	if statusCode(err) != http.StatusNotImplemented {
+		t.Errorf("Expected the rejected PATCH to fail, got %v", err)
+	}
+	if server.requests["PUT"] != 0 || server.requests["GET"] != 0 {
+		t.Errorf("Expected no fallback without the setting, got %v", server.requests)
+	}
+}
+
+func TestClient_PutGroupPreconditionFailed(t *testing.T) {
+	t.Run("read again and retried", func(t *testing.T) {
+		server := &fakeGroupServer{putStatuses: []int{http.StatusPreconditionFailed}, members: []string{"u-1"}}
+		client := newPatchTestClient(t, server, true)
+		client.SetPutForGroups()
+
+		if err := client.UpdateGroupMembers(context.Background(), "g-1", []GroupMember{{Value: "u-2"}}, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if server.requests["GET"] != 2 || server.requests["PATCH"] != 0 {
+			t.Errorf("Expected the group to be read again after the 412 and PATCH to be skipped, got %v", server.requests)
+		}
+		if strings.Join(server.ifMatch, " ") != `W/"0" W/"1"` {
+			t.Errorf("Expected meta.version to be sent with If-Match, got %v", server.ifMatch)
+		}
+		var members []string
+		for _, member := range server.put.Members {
+			members = append(members, member.Value)
+		}
+		if strings.Join(members, ",") != "u-1,other-1,u-2" {
+			t.Errorf("Expected the concurrent change to be kept, got %v", members)
+		}
+	})
+
+	t.Run("gives up after repeated conflicts", func(t *testing.T) {
+		server := &fakeGroupServer{putStatuses: []int{http.StatusPreconditionFailed, http.StatusPreconditionFailed, http.StatusPreconditionFailed, http.StatusPreconditionFailed}}
+		client := newPatchTestClient(t, server, true)
+		client.SetPutForGroups()
+
+		err := client.UpdateGroupMembers(context.Background(), "g-1", []GroupMember{{Value: "u-2"}}, nil)
+		if !errors.Is(err, ErrGroupModified) {
+			t.Errorf("Expected ErrGroupModified, got %v", err)
+		}
+		if server.requests["PUT"] != putGroupAttempts {
+			t.Errorf("Expected %d PUTs, got %d", putGroupAttempts, server.requests["PUT"])
+		}
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		server := &fakeGroupServer{putStatuses: []int{http.StatusConflict}}
+		client := newPatchTestClient(t, server, true)
+		client.SetPutForGroups()
+
+		err := client.UpdateGroupMembers(context.Background(), "g-1", []GroupMember{{Value: "u-2"}}, nil)
+		if statusCode(err) != http.StatusConflict || server.requests["PUT"] != 1 {
+			t.Errorf("Expected the 409 to be returned after one PUT, got %v after %d", err, server.requests["PUT"])
+		}
+	})
+}
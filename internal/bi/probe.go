@@ -0,0 +1,83 @@
+package bi
+
+import (
+	"context"
+	"errors"
+)
+
+// CapabilityCheck is the outcome of a read-only request made to find out what the API token may access
+type CapabilityCheck struct {
+	Capability  string `json:"capability"`
+	Endpoint    string `json:"endpoint"`
+	RequiredFor string `json:"required_for"`
+	Allowed     bool   `json:"allowed"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ProbeCapabilities makes one read-only request against each API the sync uses and reports
+// which of them the token may call, so missing scopes show up before the first write fails
+func (c *Client) ProbeCapabilities(ctx context.Context) []CapabilityCheck {
+	scim := func(path string) func() error {
+		return func() error {
+			resp, err := c.makeRequest(ctx, "GET", c.scimBaseURL+path, nil)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			return err
+		}
+	}
+	native := func(path string) func() error {
+		return func() error {
+			resp, err := c.makeNativeAPIRequest(ctx, "GET", c.nativeAPIURL+path, nil)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			return err
+		}
+	}
+
+	type probeCall struct {
+		capability  string
+		endpoint    string
+		requiredFor string
+		call        func() error
+	}
+	probes := []probeCall{
+		{"SCIM Users", "GET /Users", "user provisioning", scim("/Users?count=1")},
+		{"SCIM Groups", "GET /Groups", "group provisioning and membership", scim("/Groups?count=1")},
+	}
+	if c.nativeAPIURL != "" {
+		probes = append(probes, probeCall{"Native API", "GET /users", "enrollment status and reports", native("/users?page_size=1")})
+	}
+
+	checks := make([]CapabilityCheck, 0, len(probes))
+	for _, probe := range probes {
+		check := CapabilityCheck{
+			Capability:  probe.capability,
+			Endpoint:    probe.endpoint,
+			RequiredFor: probe.requiredFor,
+		}
+		if err := probe.call(); err != nil {
+			check.Error = err.Error()
+			check.StatusCode = statusCode(err)
+		} else {
+			check.Allowed = true
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// statusCode returns the HTTP status carried by an API error, or 0
+func statusCode(err error) int {
+	var scimErr *SCIMError
+	if errors.As(err, &scimErr) {
+		return scimErr.StatusCode
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	return 0
+}
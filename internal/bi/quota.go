@@ -0,0 +1,71 @@
+package bi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quota is the API rate limit reported in the headers of the client's latest response
+type Quota struct {
+	Limit     int       // Requests allowed in the current window
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets; zero if not reported
+	Requests  int64     // Requests made by this client since it was created
+	UpdatedAt time.Time // When the headers were last seen
+}
+
+// quotaHeaderPrefixes are the rate limit header families read, the common X-RateLimit-* and the
+// IETF draft RateLimit-*
+var quotaHeaderPrefixes = []string{"X-RateLimit-", "RateLimit-"}
+
+// Quota returns the latest rate limit reported by the API and whether any response has reported one
+func (c *Client) Quota() (Quota, bool) {
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+
+	quota := c.quota
+	quota.Requests = c.requests
+	return quota, !quota.UpdatedAt.IsZero()
+}
+
+// recordQuota counts a request and keeps the rate limit headers of its response
+func (c *Client) recordQuota(header http.Header) {
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+
+	c.requests++
+	for _, prefix := range quotaHeaderPrefixes {
+		limit, limitOK := headerInt(header, prefix+"Limit")
+		remaining, remainingOK := headerInt(header, prefix+"Remaining")
+		if !limitOK || !remainingOK {
+			continue
+		}
+
+		now := time.Now()
+		c.quota = Quota{Limit: limit, Remaining: remaining, UpdatedAt: now}
+		if reset, ok := headerInt(header, prefix+"Reset"); ok {
+			// Small values are seconds until the reset, large ones a Unix timestamp
+			if reset > 1_000_000_000 {
+				c.quota.Reset = time.Unix(int64(reset), 0)
+			} else {
+				c.quota.Reset = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+		return
+	}
+}
+
+// headerInt parses the leading integer of a header, ignoring quota policies such as "100;w=60"
+func headerInt(header http.Header, name string) (int, bool) {
+	value := header.Get(name)
+	if i := strings.IndexAny(value, ",;"); i >= 0 {
+		value = value[:i]
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,125 @@
+// Package cache holds values in memory for a limited time, so lookups repeated within and across
+// syncs are answered without an API request
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache maps keys to values that expire ttl after they were set. A nil Cache holds nothing, so
+// callers can leave caching disabled without checking for it
+type Cache[K comparable, V any] struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[K]entry[V]
+	nextSweep time.Time // When expired entries are next dropped from entries
+}
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// New creates a cache whose values expire after ttl; it returns nil, which caches nothing, when ttl
+// is not positive
+func New[K comparable, V any](ttl time.Duration) *Cache[K, V] {
+	if ttl <= 0 {
+		return nil
+	}
+	return &Cache[K, V]{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[K]entry[V]),
+	}
+}
+
+// Get returns the value set for key, with ok false when there is none or it expired
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	if c == nil {
+		return value, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, found := c.entries[key]
+	if !found {
+		return value, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return value, false
+	}
+	return e.value, true
+}
+
+// Set stores value for key until the TTL passes
+func (c *Cache[K, V]) Set(key K, value V) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if !now.Before(c.nextSweep) {
+		c.sweep(now)
+		c.nextSweep = now.Add(c.ttl)
+	}
+	c.entries[key] = entry[V]{value: value, expires: now.Add(c.ttl)}
+}
+
+// Delete drops the value of key, so the next lookup reads it again
+func (c *Cache[K, V]) Delete(key K) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// DeleteFunc drops every value for which match returns true, for writes that know what changed
+// but not under which key it was cached
+func (c *Cache[K, V]) DeleteFunc(match func(key K, value V) bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if match(key, e.value) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Clear drops every value
+func (c *Cache[K, V]) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// Len returns how many values have not expired
+func (c *Cache[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweep(c.now())
+	return len(c.entries)
+}
+
+// sweep drops expired entries, so keys that are never looked up again do not pile up between syncs
+func (c *Cache[K, V]) sweep(now time.Time) {
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
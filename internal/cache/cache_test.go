@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_Expiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New[string, int](time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	if value, ok := c.Get("a"); !ok || value != 1 {
+		t.Errorf("Expected a=1, got %d (found %v)", value, ok)
+	}
+
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Expected a to be cached before the TTL passed")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected a to expire once the TTL passed")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Expected the expired entry to be dropped, got %d entries", c.Len())
+	}
+}
+
+func TestCache_Sweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New[string, int](time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	now = now.Add(2 * time.Minute)
+	c.Set("b", 2)
+
+	if len(c.entries) != 1 {
+		t.Errorf("Expected setting b to drop the expired a, got %v", c.entries)
+	}
+}
+
+func TestCache_Invalidation(t *testing.T) {
+	c := New[string, string](time.Hour)
+	c.Set("alice@example.com", "u-1")
+	c.Set("bob@example.com", "u-2")
+	c.Set("carol@example.com", "u-3")
+
+	c.Delete("alice@example.com")
+	if _, ok := c.Get("alice@example.com"); ok {
+		t.Error("Expected Delete to drop alice")
+	}
+
+	c.DeleteFunc(func(key, value string) bool { return value == "u-2" })
+	if _, ok := c.Get("bob@example.com"); ok {
+		t.Error("Expected DeleteFunc to drop bob")
+	}
+	if _, ok := c.Get("carol@example.com"); !ok {
+		t.Error("Expected DeleteFunc to keep carol")
+	}
+
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("Expected Clear to drop every entry, got %d", c.Len())
+	}
+}
+
+func TestCache_Disabled(t *testing.T) {
+	c := New[string, int](0)
+	if c != nil {
+		t.Fatal("Expected no cache without a TTL")
+	}
+
+	c.Set("a", 1)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected a nil cache to hold nothing")
+	}
+	c.Delete("a")
+	c.DeleteFunc(func(string, int) bool { return true })
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("Expected a nil cache to be empty, got %d", c.Len())
+	}
+}
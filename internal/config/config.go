@@ -4,30 +4,132 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
-	"gopkg.in/yaml.v3"
+	"golang.org/x/text/language"
 )
 
 // Config represents the application configuration
 type Config struct {
+	ConfigVersion   int                   `yaml:"config_version"`
+	Profile         string                `yaml:"-"` // Profile applied over the base settings, if any
+	Path            string                `yaml:"-"` // File the configuration was loaded from
 	App             AppConfig             `yaml:"app"`
 	GoogleWorkspace GoogleWorkspaceConfig `yaml:"google_workspace"`
 	BeyondIdentity  BeyondIdentityConfig  `yaml:"beyond_identity"`
+	Target          TargetConfig          `yaml:"target"` // Primary target when it is not Beyond Identity; see PrimaryTargetType
 	Sync            SyncConfig            `yaml:"sync"`
 	Server          ServerConfig          `yaml:"server"`
+	Network         NetworkConfig         `yaml:"network"`
+	Targets         []TargetConfig        `yaml:"targets"`
+	Source          SourceConfig          `yaml:"source"`
+	Notifications   NotificationsConfig   `yaml:"notifications"`
+	Metrics         MetricsConfig         `yaml:"metrics"`
+	Reminders       RemindersConfig       `yaml:"reminders"`
+	AccessReview    AccessReviewConfig    `yaml:"access_review"`
+	Telemetry       TelemetryConfig       `yaml:"telemetry"`
+	E2E             E2EConfig             `yaml:"e2e"`
+	Features        map[string]bool       `yaml:"features"` // Feature flag overrides; see FeatureFlags
+}
+
+// DefaultTargetName is the name of the primary target, described by the beyond_identity section,
+// or by the target section when its type is generic_scim
+const DefaultTargetName = "default"
+
+// PrimaryTargetType returns the type of the primary target: beyond_identity unless target.type
+// names another SCIM service
+func (c *Config) PrimaryTargetType() string {
+	if c.Target.Type == "" {
+		return TargetTypeBeyondIdentity
+	}
+	return c.Target.Type
+}
+
+// PrimaryIsBeyondIdentity reports whether the primary target is the Beyond Identity tenant of the
+// beyond_identity section, which enrollment status, reminders and the self-test need
+func (c *Config) PrimaryIsBeyondIdentity() bool {
+	return c.PrimaryTargetType() == TargetTypeBeyondIdentity
 }
 
 // AppConfig contains application-level settings
 type AppConfig struct {
 	LogLevel string `yaml:"log_level"`
 	TestMode bool   `yaml:"test_mode"`
+	Mode     string `yaml:"mode"`      // Deployment mode: oneshot, server or hybrid (default); see DeploymentModes
+	ReadOnly bool   `yaml:"read_only"` // Start the server in read-only mode; toggled at runtime with POST /mode/read-only
+	// StrictPermissions refuses to start when the config file or service account key is
+	// accessible by group or other users, instead of warning
+	StrictPermissions bool `yaml:"strict_permissions"`
+}
+
+// Deployment modes of app.mode, which gate the commands and subsystems that start
+const (
+	DeploymentModeOneShot = "oneshot" // Runs started with `scim-sync run`, e.g. from cron; the server does not start
+	DeploymentModeServer  = "server"  // The server alone, with its API, scheduler, runtime monitor and notifications
+	DeploymentModeHybrid  = "hybrid"  // Both: the server, and one-shot runs alongside it
+)
+
+// DeploymentModes lists the supported values of app.mode
+var DeploymentModes = []string{DeploymentModeOneShot, DeploymentModeServer, DeploymentModeHybrid}
+
+// RunsOneShot reports whether app.mode allows one-shot runs with `scim-sync run`
+func (c *Config) RunsOneShot() bool {
+	return c.App.Mode != DeploymentModeServer
 }
 
+// RunsServer reports whether app.mode allows the server, and with it the HTTP API, the
+// scheduler, the runtime monitor and notifications, to start
+func (c *Config) RunsServer() bool {
+	return c.App.Mode != DeploymentModeOneShot
+}
+
+// Supported Google Workspace group APIs
+const (
+	GWSAPIAdminSDK      = "admin_sdk"
+	GWSAPICloudIdentity = "cloud_identity"
+)
+
 // GoogleWorkspaceConfig contains Google Workspace API settings
 type GoogleWorkspaceConfig struct {
 	Domain                string `yaml:"domain"`
 	SuperAdminEmail       string `yaml:"super_admin_email"`
 	ServiceAccountKeyPath string `yaml:"service_account_key_path"`
+	// NextServiceAccountKeyPath holds the key being rotated in; it is used when the
+	// current key is missing or rejected, so keys can be swapped without a sync gap
+	NextServiceAccountKeyPath string `yaml:"next_service_account_key_path"`
+	API                       string `yaml:"api"`         // admin_sdk (default) or cloud_identity
+	CustomerID                string `yaml:"customer_id"` // Required for cloud_identity to create the enrollment group
+	// FallbackAdminEmails are impersonated in order when super_admin_email is suspended or loses privileges
+	FallbackAdminEmails []string `yaml:"fallback_admin_emails"`
+	// DomainAdmins selects the admins impersonated for groups in each domain of a multi-domain tenant
+	DomainAdmins map[string][]string `yaml:"domain_admins"`
+	// PageRetryAttempts is how many times each page of a group's members is requested; 0 uses the default of 3
+	PageRetryAttempts int `yaml:"page_retry_attempts"`
+	// SlowCallThreshold is how long an Admin SDK request may take before it is logged as slow, e.g. 5s; 0 disables the logging
+	SlowCallThreshold string `yaml:"slow_call_threshold"`
+}
+
+// AdminSubjects returns the admins impersonated for a group email, in failover order
+func (g GoogleWorkspaceConfig) AdminSubjects(groupEmail string) []string {
+	if at := strings.LastIndex(groupEmail, "@"); at >= 0 {
+		domain := strings.ToLower(groupEmail[at+1:])
+		for d, admins := range g.DomainAdmins {
+			if strings.ToLower(d) == domain && len(admins) > 0 {
+				return admins
+			}
+		}
+	}
+
+	return append([]string{g.SuperAdminEmail}, g.FallbackAdminEmails...)
+}
+
+// ServiceAccountKeyPaths returns the configured key paths in the order they are tried
+func (g GoogleWorkspaceConfig) ServiceAccountKeyPaths() []string {
+	paths := []string{g.ServiceAccountKeyPath}
+	if g.NextServiceAccountKeyPath != "" && g.NextServiceAccountKeyPath != g.ServiceAccountKeyPath {
+		paths = append(paths, g.NextServiceAccountKeyPath)
+	}
+	return paths
 }
 
 // BeyondIdentityConfig contains Beyond Identity API settings
@@ -36,42 +138,559 @@ type BeyondIdentityConfig struct {
 	SCIMBaseURL  string `yaml:"scim_base_url"`
 	NativeAPIURL string `yaml:"native_api_url"`
 	GroupPrefix  string `yaml:"group_prefix"`
+	// GroupPrefixes lists earlier group prefixes. Existing groups named with one of them are
+	// synced instead of creating a group with GroupPrefix; the first entry is the default GroupPrefix
+	GroupPrefixes []string `yaml:"group_prefixes"`
+	// QuotaWarningFraction warns when a run uses more than this fraction of the API quota that was
+	// available, as reported by rate limit response headers; 1 disables the warning
+	QuotaWarningFraction float64 `yaml:"quota_warning_fraction"`
+	// PatchFallback rewrites groups with a PUT of the whole group when the tenant rejects PATCH
+	PatchFallback bool `yaml:"patch_fallback"`
+	// LookupCacheTTL keeps users and groups found by email and display name for this long, e.g.
+	// 10m, within and across syncs; empty disables the cache
+	LookupCacheTTL string `yaml:"lookup_cache_ttl"`
+}
+
+// LookupCacheDuration returns how long lookups are cached, or 0 when the cache is disabled or the
+// duration is invalid
+func (b BeyondIdentityConfig) LookupCacheDuration() time.Duration {
+	return nonNegativeDuration(b.LookupCacheTTL)
+}
+
+// Policies for existing users the sync did not provision (sync.conflict_policy)
+const (
+	ConflictAdopt     = "adopt"     // Sync the existing user as is
+	ConflictSkip      = "skip"      // Leave the user out of the synced groups
+	ConflictOverwrite = "overwrite" // Replace the user's attributes with the ones the sync creates users with
+	ConflictError     = "error"     // Fail the user
+)
+
+// DefaultAllUsersGroup names the sync.all_users group after the group prefix
+const DefaultAllUsersGroup = "AllUsers"
+
+// DefaultQuotaWarningFraction is the share of the available API quota a run may use before warning
+const DefaultQuotaWarningFraction = 0.5
+
+// Supported target types
+const (
+	TargetTypeBeyondIdentity = "beyond_identity"
+	TargetTypeOkta           = "okta"
+	TargetTypeGenericSCIM    = "generic_scim"
+)
+
+// Supported Okta authentication methods
+const (
+	OktaAuthAPIToken = "api_token"
+	OktaAuthOAuth    = "oauth"
+)
+
+// Supported authentication schemes of generic SCIM targets
+const (
+	SCIMAuthBearer = "bearer"
+	SCIMAuthBasic  = "basic"
+	SCIMAuthOAuth  = "oauth"
+)
+
+// How generic SCIM targets change group membership
+const (
+	MembershipUpdatesPatch = "patch" // PATCH operations adding and removing members
+	MembershipUpdatesPut   = "put"   // PUT of the whole group, for services without PATCH for groups
+
+	MemberRemovalPathFilter = "path_filter" // One remove operation per member, with a members[value eq "..."] path
+	MemberRemovalValue      = "value"       // One remove operation listing the members, for services without path filters
+)
+
+// TargetConfig describes an additional provisioning target (a Beyond Identity tenant, an Okta org
+// or another SCIM 2.0 service), or the primary one when it is a generic SCIM service
+type TargetConfig struct {
+	Name         string `yaml:"name"` // Additional targets only
+	Type         string `yaml:"type"` // beyond_identity (default), okta or generic_scim
+	APIToken     string `yaml:"api_token"`
+	SCIMBaseURL  string `yaml:"scim_base_url"`
+	NativeAPIURL string `yaml:"native_api_url"`
+	GroupPrefix  string `yaml:"group_prefix"`
+	// GroupPrefixes lists earlier group prefixes, see BeyondIdentityConfig.GroupPrefixes
+	GroupPrefixes []string `yaml:"group_prefixes"`
+
+	// Beyond Identity settings
+	PatchFallback  bool   `yaml:"patch_fallback"`   // See BeyondIdentityConfig.PatchFallback
+	LookupCacheTTL string `yaml:"lookup_cache_ttl"` // See BeyondIdentityConfig.LookupCacheTTL
+
+	// Okta settings
+	OrgURL       string `yaml:"org_url"`
+	Auth         string `yaml:"auth"` // api_token (default) or oauth; bearer (default), basic or oauth for generic SCIM
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+
+	// Generic SCIM settings, besides scim_base_url, api_token, auth, client_id, client_secret,
+	// patch_fallback and lookup_cache_ttl
+	Username          string   `yaml:"username"`           // Basic auth
+	Password          string   `yaml:"password"`           // Basic auth
+	TokenURL          string   `yaml:"token_url"`          // OAuth client credentials token endpoint
+	Scopes            []string `yaml:"scopes"`             // OAuth scopes, if the service needs any
+	MembershipUpdates string   `yaml:"membership_updates"` // patch (default) or put
+	MemberRemoval     string   `yaml:"member_removal"`     // path_filter (default) or value
+}
+
+// LookupCacheDuration returns how long lookups in a Beyond Identity target are cached, or 0 when
+// the cache is disabled or the duration is invalid
+func (t TargetConfig) LookupCacheDuration() time.Duration {
+	return nonNegativeDuration(t.LookupCacheTTL)
 }
 
 // SyncConfig contains synchronization settings
 type SyncConfig struct {
-	Groups               []string `yaml:"groups"`
-	EnrollmentGroupEmail string   `yaml:"enrollment_group_email"`
-	EnrollmentGroupName  string   `yaml:"enrollment_group_name"`
-	RetryAttempts        int      `yaml:"retry_attempts"`
-	RetryDelaySeconds    int      `yaml:"retry_delay_seconds"`
+	Groups               []string             `yaml:"groups"`
+	OrgUnits             []string             `yaml:"org_units"`       // Org units, e.g. /Engineering, whose active users are synced to a group each
+	AllUsers             bool                 `yaml:"all_users"`       // Also sync every active user of the domain to one group
+	AllUsersGroup        string               `yaml:"all_users_group"` // Name of the all_users group after the group prefix
+	AnnotateGroups       bool                 `yaml:"annotate_groups"` // Mark synced groups as managed, with their source group and last sync
+	AdminGroups          bool                 `yaml:"admin_groups"`    // Also sync each group's owners and managers to a group named with AdminGroupSuffix
+	ConflictPolicy       string               `yaml:"conflict_policy"` // What to do with existing users the sync did not provision: adopt (default), skip, overwrite or error
+	GroupTargets         map[string]string    `yaml:"group_targets"`   // group email -> target name
+	EnrollmentGroupEmail string               `yaml:"enrollment_group_email"`
+	EnrollmentGroupName  string               `yaml:"enrollment_group_name"`
+	RetryAttempts        int                  `yaml:"retry_attempts"` // Attempts of writes failing without an HTTP response, or with any transient error when Retry is disabled
+	RetryDelaySeconds    int                  `yaml:"retry_delay_seconds"`
+	AuthErrorThreshold   int                  `yaml:"auth_error_threshold"`  // Abort after this many auth errors; -1 disables
+	FailFast             bool                 `yaml:"fail_fast"`             // Abort on the first error
+	ErrorBudget          int                  `yaml:"error_budget"`          // Abort after this many errors; 0 is unlimited
+	AutoSkipDays         int                  `yaml:"auto_skip_days"`        // Skip users that fail permanently for this many days; 0 disables
+	StatePath            string               `yaml:"state_path"`            // File that remembers group mappings between runs
+	DisableFile          string               `yaml:"disable_file"`          // Emergency stop: no sync runs while this file exists; defaults to state_path + ".disabled"
+	LockLeaseSeconds     int                  `yaml:"lock_lease_seconds"`    // Lease on the sync lock next to the state file; renewed while a run is in progress
+	SpreadOver           string               `yaml:"spread_over"`           // Pace user operations evenly across this duration, e.g. 30m; empty disables
+	MaxExpectedDuration  string               `yaml:"max_expected_duration"` // Flag runs still going after this duration, e.g. 2h; empty disables
+	CancelStuckRuns      bool                 `yaml:"cancel_stuck_runs"`     // Also stop runs that exceed max_expected_duration
+	TimeoutSeconds       int                  `yaml:"timeout_seconds"`       // Cancel runs after this many seconds, including API requests in flight; 0 disables
+	TestModeOperations   []string             `yaml:"test_mode_operations"`  // Operations only logged, as in test mode, while the rest apply, e.g. [remove, deactivate]
+	AttributeMappings    map[string]string    `yaml:"attribute_mappings"`    // SCIM attribute of created users to a Google Workspace field or template, e.g. displayName: "{{.FullName}}"
+	DisplayNameLocale    string               `yaml:"display_name_locale"`   // BCP 47 language whose casing rules display names use, e.g. tr or nl
+	PartialMembership    bool                 `yaml:"partial_membership"`    // Sync the members read before a page failed instead of failing the group
+	RetryQueueHours      int                  `yaml:"retry_queue_hours"`     // Retry failed creates and membership updates on later runs for this long; -1 disables
+	Concurrency          int                  `yaml:"concurrency"`           // Groups synced in parallel; 1 syncs them one at a time
+	MembershipBatchSize  int                  `yaml:"membership_batch_size"` // Membership changes sent per request to a group; larger updates are split
+	RecentRuns           int                  `yaml:"recent_runs"`           // Runs whose results are kept in memory for GET /runs; -1 disables
+	Filters              UserFiltersConfig    `yaml:"filters"`
+	OrphanedGroups       OrphanedGroupsConfig `yaml:"orphaned_groups"`
+	Aliases              GroupAliasesConfig   `yaml:"aliases"`
+	Incremental          IncrementalConfig    `yaml:"incremental"`
+	Retry                RetryConfig          `yaml:"retry"`
+	EnrollmentSchema     UserSchemaConfig     `yaml:"enrollment_schema"`
+}
+
+// UserSchemaConfig stamps the enrollment status of each checked user on fields of a Google
+// Workspace custom user schema, so it can be searched in the directory and used in Workspace rules
+type UserSchemaConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	Name         string `yaml:"name"`          // Custom schema, created in the Admin Console; defaults to BeyondIdentity
+	StatusField  string `yaml:"status_field"`  // Set to ENROLLED or NOT_ENROLLED; defaults to enrollmentStatus
+	CheckedField string `yaml:"checked_field"` // Set to when the status was checked, in RFC 3339; defaults to lastCheckedAt
+}
+
+// Default custom user schema and fields of sync.enrollment_schema
+const (
+	DefaultEnrollmentSchemaName         = "BeyondIdentity"
+	DefaultEnrollmentSchemaStatusField  = "enrollmentStatus"
+	DefaultEnrollmentSchemaCheckedField = "lastCheckedAt"
+)
+
+// Enrollment statuses stamped on the status field of sync.enrollment_schema
+const (
+	EnrollmentStatusEnrolled    = "ENROLLED"
+	EnrollmentStatusNotEnrolled = "NOT_ENROLLED"
+)
+
+// AdminGroupSuffix is appended to the name of a synced group for the group of its owners and
+// managers, with sync.admin_groups
+const AdminGroupSuffix = "_Admins"
+
+// Operations that sync.test_mode_operations can simulate
+const (
+	OperationCreate     = "create"     // Creating users and groups
+	OperationAdd        = "add"        // Adding group members, including to the enrollment group
+	OperationRemove     = "remove"     // Removing group members, including when deprovisioning
+	OperationRename     = "rename"     // Renaming groups when archiving them or migrating the prefix
+	OperationDeactivate = "deactivate" // Deactivating users when deprovisioning
+	OperationUpdate     = "update"     // Updating users whose attributes drifted, with the user_updates feature
+)
+
+// Operations lists the operations that can be simulated
+var Operations = []string{OperationCreate, OperationAdd, OperationRemove, OperationRename, OperationDeactivate, OperationUpdate}
+
+// IncrementalConfig controls delta runs, which sync only the groups changed in Google Workspace
+// since the last successful run
+type IncrementalConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	FullSyncInterval string `yaml:"full_sync_interval"` // Run a full sync when the last one is older than this, e.g. 24h
+	Lookback         string `yaml:"lookback"`           // Overlap with the previous run, since audit events can arrive late, e.g. 1h
+}
+
+// RetryConfig controls how Google Workspace and Beyond Identity API requests answered with rate
+// limiting or a transient error are retried. While it is enabled, the engine's own retry_attempts
+// only apply to failures without an HTTP response
+type RetryConfig struct {
+	MaxAttempts int    `yaml:"max_attempts"` // Attempts per request, including the first; 1 disables retries
+	BaseDelay   string `yaml:"base_delay"`   // Backoff before the second attempt, doubled after each, e.g. 500ms
+	MaxDelay    string `yaml:"max_delay"`    // Longest backoff or Retry-After honored between attempts, e.g. 30s
+	Budget      string `yaml:"budget"`       // Total time a request may spend waiting between attempts, e.g. 2m
+}
+
+// Default retry settings for API requests
+const (
+	DefaultRetryMaxAttempts = 4
+	DefaultRetryBaseDelay   = "500ms"
+	DefaultRetryMaxDelay    = "30s"
+	DefaultRetryBudget      = "2m"
+)
+
+// BaseDelayDuration returns the backoff before the second attempt, or 0 when the duration is invalid
+func (r RetryConfig) BaseDelayDuration() time.Duration {
+	return nonNegativeDuration(r.BaseDelay)
+}
+
+// MaxDelayDuration returns the longest wait between attempts, or 0 when the duration is invalid
+func (r RetryConfig) MaxDelayDuration() time.Duration {
+	return nonNegativeDuration(r.MaxDelay)
+}
+
+// BudgetDuration returns how long a request may wait between attempts in total, or 0 when the
+// duration is invalid
+func (r RetryConfig) BudgetDuration() time.Duration {
+	return nonNegativeDuration(r.Budget)
+}
+
+// nonNegativeDuration parses a configured duration, returning 0 when it is invalid or negative
+func nonNegativeDuration(value string) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0
+	}
+	return duration
+}
+
+// UserFiltersConfig decides which group members are provisioned, so service accounts, external
+// collaborators and test accounts never reach Beyond Identity. Members filtered out are synced as
+// if they were not in the group
+type UserFiltersConfig struct {
+	ExcludeDomains  []string `yaml:"exclude_domains"`   // Email domains never provisioned, e.g. gmail.com
+	ExcludeEmails   []string `yaml:"exclude_emails"`    // Addresses never provisioned
+	ExcludePatterns []string `yaml:"exclude_patterns"`  // Regular expressions; emails matching any are not provisioned, e.g. ^svc-
+	IncludePatterns []string `yaml:"include_patterns"`  // Regular expressions; when set, only emails matching one are provisioned
+	IncludeOrgUnits []string `yaml:"include_org_units"` // When set, only users in these organizational units or below them are provisioned
+}
+
+// GroupAliasesConfig controls how Google group alias addresses are handled
+type GroupAliasesConfig struct {
+	Resolve      bool `yaml:"resolve"`       // Sync a configured alias as its canonical group, once even if both are listed
+	CreateGroups bool `yaml:"create_groups"` // Also provision a Beyond Identity group per alias with the same members
+}
+
+// OrphanedGroupsConfig controls what happens to the Beyond Identity group when its source group is deleted
+type OrphanedGroupsConfig struct {
+	Archive       bool   `yaml:"archive"`        // Rename the Beyond Identity group with archive_suffix
+	ArchiveSuffix string `yaml:"archive_suffix"` // Defaults to " (archived)"
+	Cleanup       string `yaml:"cleanup"`        // After each full sync, delete or empty prefixed groups no longer synced; empty disables
+}
+
+// Ways orphaned Beyond Identity groups are cleaned up by scim-sync cleanup or sync.orphaned_groups.cleanup
+const (
+	OrphanCleanupDelete = "delete" // Delete the group
+	OrphanCleanupEmpty  = "empty"  // Remove every member but keep the group
+)
+
+// MetricsConfig selects where sync and API call metrics are published, besides GET /metrics in server mode
+type MetricsConfig struct {
+	Prometheus PrometheusConfig `yaml:"prometheus"`
+	Statsd     StatsdConfig     `yaml:"statsd"`
+}
+
+// PrometheusConfig exposes metrics in the Prometheus text format
+type PrometheusConfig struct {
+	Enabled      bool   `yaml:"enabled"`       // Serve GET /metrics/prometheus in server mode
+	TextfilePath string `yaml:"textfile_path"` // Written after one-shot runs, for node_exporter's textfile collector
+}
+
+// StatsdConfig sends metrics to a statsd agent over UDP
+type StatsdConfig struct {
+	Address string `yaml:"address"` // host:port of the agent; empty disables
+	Prefix  string `yaml:"prefix"`  // Defaults to scim_sync
+}
+
+// DefaultStatsdPrefix is prepended to statsd metric names when no prefix is configured
+const DefaultStatsdPrefix = "scim_sync"
+
+// DefaultAuthErrorThreshold is how many authentication errors abort a sync run by default
+const DefaultAuthErrorThreshold = 10
+
+// DefaultSlowCallThreshold is how long an Admin SDK request may take by default before it is logged as slow
+const DefaultSlowCallThreshold = "5s"
+
+// DefaultFullSyncInterval is how often incremental sync falls back to a full sync by default
+const DefaultFullSyncInterval = "24h"
+
+// DefaultIncrementalLookback is how far before the last successful run changes are read by default
+const DefaultIncrementalLookback = "1h"
+
+// DefaultRetryQueueHours is how long failed writes are retried on later runs by default
+const DefaultRetryQueueHours = 24
+
+// DefaultConcurrency is how many groups are synced in parallel by default
+const DefaultConcurrency = 1
+
+// DefaultMembershipBatchSize is how many membership changes are sent per request by default
+const DefaultMembershipBatchSize = 100
+
+// DefaultRecentRuns is how many run results are kept in memory by default
+const DefaultRecentRuns = 20
+
+// Supported membership source types
+const (
+	SourceTypeGoogleWorkspace = "google_workspace"
+	SourceTypeCSV             = "csv"
+	SourceTypeAzureAD         = "azure_ad"
+	SourceTypeLDAP            = "ldap"
+)
+
+// Default Microsoft endpoints of an Azure AD source, for the global cloud
+const (
+	DefaultAzureADGraphURL = "https://graph.microsoft.com"
+	DefaultAzureADLoginURL = "https://login.microsoftonline.com"
+)
+
+// SourceConfig selects where authoritative group membership is read from
+type SourceConfig struct {
+	Type    string              `yaml:"type"` // google_workspace (default), csv, azure_ad or ldap
+	CSV     CSVSourceConfig     `yaml:"csv"`
+	AzureAD AzureADSourceConfig `yaml:"azure_ad"`
+	LDAP    LDAPSourceConfig    `yaml:"ldap"`
+}
+
+// ReadsGoogleGroups reports whether membership is read from Google groups rather than another source
+func (s SourceConfig) ReadsGoogleGroups() bool {
+	return s.Type == "" || s.Type == SourceTypeGoogleWorkspace
+}
+
+// AzureADSourceConfig describes the Microsoft Entra ID (Azure AD) tenant whose groups are read
+// through Microsoft Graph, authenticating as an app registration with the client credentials flow
+type AzureADSourceConfig struct {
+	TenantID     string   `yaml:"tenant_id"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Groups       []string `yaml:"groups"`    // Object IDs of the groups to sync, added to sync.groups
+	GraphURL     string   `yaml:"graph_url"` // Defaults to https://graph.microsoft.com; set for national clouds
+	LoginURL     string   `yaml:"login_url"` // Defaults to https://login.microsoftonline.com
+}
+
+// CSVSourceConfig describes a membership export delivered as a CSV file, locally or over SFTP
+type CSVSourceConfig struct {
+	Path      string      `yaml:"path"`      // Local file path, used when sftp is not configured
+	Delimiter string      `yaml:"delimiter"` // Field delimiter, defaults to ","
+	Columns   CSVColumns  `yaml:"columns"`
+	SFTP      *SFTPConfig `yaml:"sftp"`
+}
+
+// CSVColumns maps the header names in the export to the fields the source needs
+type CSVColumns struct {
+	Group     string `yaml:"group"`      // Group email, defaults to "group"
+	Email     string `yaml:"email"`      // Member email, defaults to "email"
+	GroupName string `yaml:"group_name"` // Optional group display name
+	Status    string `yaml:"status"`     // Optional member status; "suspended" or "inactive" rows are skipped
+}
+
+// LDAPSourceConfig describes the LDAP directory, such as Active Directory, whose groups are read
+// over LDAPS with a bind account
+type LDAPSourceConfig struct {
+	URL                string   `yaml:"url"` // e.g. ldaps://dc1.corp.example.com:636
+	BindDN             string   `yaml:"bind_dn"`
+	BindPassword       string   `yaml:"bind_password"`
+	CABundle           string   `yaml:"ca_bundle"` // PEM roots to verify the server with, instead of the system roots
+	BaseDN             string   `yaml:"base_dn"`   // Where group members are searched for
+	Groups             []string `yaml:"groups"`    // DNs of the groups to sync, added to sync.groups
+	UserFilter         string   `yaml:"user_filter"`
+	EmailAttribute     string   `yaml:"email_attribute"`      // Defaults to mail
+	UsernameAttribute  string   `yaml:"username_attribute"`   // Defaults to sAMAccountName
+	UsernameDomain     string   `yaml:"username_domain"`      // Members without an email are synced as username@username_domain
+	GroupNameAttribute string   `yaml:"group_name_attribute"` // Defaults to cn
+	Nested             bool     `yaml:"nested"`               // Include members of nested groups; Active Directory only
+}
+
+// Default attributes and filter of an LDAP source, for Active Directory
+const (
+	DefaultLDAPUserFilter         = "(objectClass=person)"
+	DefaultLDAPEmailAttribute     = "mail"
+	DefaultLDAPUsernameAttribute  = "sAMAccountName"
+	DefaultLDAPGroupNameAttribute = "cn"
+)
+
+// SFTPConfig contains the settings used to download the CSV export over SFTP
+type SFTPConfig struct {
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port"`
+	Username       string `yaml:"username"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+	Password       string `yaml:"password"`
+	KnownHostsPath string `yaml:"known_hosts_path"`
+	RemotePath     string `yaml:"remote_path"`
 }
 
 // ServerConfig contains server mode settings
 type ServerConfig struct {
-	Port            int    `yaml:"port"`
-	ScheduleEnabled bool   `yaml:"schedule_enabled"`
-	Schedule        string `yaml:"schedule"`
+	Port            int           `yaml:"port"`
+	ScheduleEnabled bool          `yaml:"schedule_enabled"`
+	Schedule        string        `yaml:"schedule"`
+	WebhookSecret   string        `yaml:"webhook_secret"` // Shared secret for HMAC-signed POST /hooks/trigger requests
+	AuditLogPath    string        `yaml:"audit_log_path"` // JSON lines record of deprovisioning requests
+	Monitor         MonitorConfig `yaml:"monitor"`
+
+	// WaitForInitialSync runs a sync at startup and reports /readyz ready only once a sync succeeds
+	WaitForInitialSync bool `yaml:"wait_for_initial_sync"`
 }
 
-// Load loads configuration from a YAML file
-func Load(configPath string) (*Config, error) {
-	// Read the configuration file
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
-	}
+// Runtime monitor defaults
+const (
+	DefaultMonitorIntervalSeconds = 60
+	DefaultMonitorMaxGoroutines   = 1000
+	DefaultMonitorMaxHeapMB       = 512
+	DefaultMonitorMaxOpenFDs      = 800
+)
 
-	// Substitute environment variables
-	configData := os.ExpandEnv(string(data))
+// MonitorConfig controls the runtime self-monitor of server mode; -1 disables the monitor or a single check
+type MonitorConfig struct {
+	IntervalSeconds int    `yaml:"interval_seconds"` // How often goroutines, heap and open files are sampled
+	MaxGoroutines   int    `yaml:"max_goroutines"`   // Warn with a goroutine dump above this many goroutines
+	MaxHeapMB       int    `yaml:"max_heap_mb"`      // Warn with a goroutine dump above this much heap in use
+	MaxOpenFDs      int    `yaml:"max_open_fds"`     // Warn, and close idle API connections, above this many open files
+	DumpDir         string `yaml:"dump_dir"`         // Write goroutine dumps here instead of to the log
+}
 
-	// Parse YAML
-	var config Config
-	if err := yaml.Unmarshal([]byte(configData), &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
-	}
+// Notification delivery modes
+const (
+	NotificationModeImmediate = "immediate"
+	NotificationModeDigest    = "digest"
+)
+
+// NotificationsConfig configures alerts about scheduled sync runs
+type NotificationsConfig struct {
+	Mode       string          `yaml:"mode"` // immediate (default) alerts on each failed run; digest sends periodic summaries
+	Digest     DigestConfig    `yaml:"digest"`
+	Slack      WebhookConfig   `yaml:"slack"`
+	Teams      WebhookConfig   `yaml:"teams"`
+	GoogleChat WebhookConfig   `yaml:"google_chat"`
+	PagerDuty  PagerDutyConfig `yaml:"pagerduty"`
+	Opsgenie   OpsgenieConfig  `yaml:"opsgenie"`
+
+	MembershipDiffs MembershipDiffsConfig `yaml:"membership_diffs"`
+}
+
+// DefaultDiffMaxEntries is how many users a membership diff lists per group by default
+const DefaultDiffMaxEntries = 10
+
+// MembershipDiffsConfig controls the membership changes listed in chat notifications
+type MembershipDiffsConfig struct {
+	Enabled    bool `yaml:"enabled"`     // Also notify after successful runs that changed membership
+	MaxEntries int  `yaml:"max_entries"` // Users listed per group before the rest are elided
+}
+
+// DigestConfig configures when digest summaries are sent
+type DigestConfig struct {
+	Schedule string `yaml:"schedule"` // Cron expression, defaults to daily at 8 AM
+}
 
-	return &config, nil
+// WebhookConfig contains the incoming webhook of a chat notification channel
+type WebhookConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+// DefaultIncidentFailureThreshold is how many consecutive failed syncs open an incident by default
+const DefaultIncidentFailureThreshold = 3
+
+// PagerDutyConfig contains the PagerDuty Events API v2 integration used for sync-failure incidents
+type PagerDutyConfig struct {
+	RoutingKey       string `yaml:"routing_key"`
+	FailureThreshold int    `yaml:"failure_threshold"` // Consecutive failed syncs before an incident is opened
+}
+
+// OpsgenieConfig contains the Opsgenie API integration used for sync-failure alerts
+type OpsgenieConfig struct {
+	APIKey           string `yaml:"api_key"`
+	APIURL           string `yaml:"api_url"`           // Defaults to https://api.opsgenie.com
+	FailureThreshold int    `yaml:"failure_threshold"` // Consecutive failed syncs before an alert is opened
+}
+
+// Reminder email transports
+const (
+	ReminderTransportGmail = "gmail"
+	ReminderTransportSMTP  = "smtp"
+)
+
+// RemindersConfig configures enrollment reminder emails to users without an active passkey
+type RemindersConfig struct {
+	Enabled         bool       `yaml:"enabled"`
+	Schedule        string     `yaml:"schedule"`          // Cron expression used in server mode
+	GracePeriodDays int        `yaml:"grace_period_days"` // Days after provisioning before the first reminder
+	IntervalDays    int        `yaml:"interval_days"`     // Minimum days between reminders to the same user
+	MaxReminders    int        `yaml:"max_reminders"`     // Reminders sent to a user before giving up
+	Transport       string     `yaml:"transport"`         // gmail (default, uses domain-wide delegation) or smtp
+	From            string     `yaml:"from"`
+	Subject         string     `yaml:"subject"`
+	TemplatePath    string     `yaml:"template_path"` // Optional text/template file for the message body
+	EnrollmentURL   string     `yaml:"enrollment_url"`
+	StatePath       string     `yaml:"state_path"` // Records who was reminded and when
+	SMTP            SMTPConfig `yaml:"smtp"`
+}
+
+// SMTPConfig contains the mail server used when the reminder transport is smtp
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Access review export formats
+const (
+	AccessReviewFormatCSV  = "csv"
+	AccessReviewFormatSCIM = "scim"
+)
+
+// AccessReviewConfig configures access review exports written on a schedule in server mode
+type AccessReviewConfig struct {
+	Schedule  string `yaml:"schedule"`   // Cron expression; empty disables scheduled exports
+	Format    string `yaml:"format"`     // csv (default) or scim
+	OutputDir string `yaml:"output_dir"` // Directory the timestamped exports are written to
+}
+
+// E2EConfig configures scim-sync e2e, which provisions a canary group against sandbox tenants
+type E2EConfig struct {
+	Members []string `yaml:"members"` // Existing sandbox users put in the canary group; at least two
+}
+
+// TelemetryConfig controls the anonymous usage report sent after each run; see scim-sync telemetry show
+type TelemetryConfig struct {
+	Enabled  bool   `yaml:"enabled"`  // Opt in to sending the report; off by default
+	Endpoint string `yaml:"endpoint"` // Where the report is posted; defaults to DefaultTelemetryEndpoint
+}
+
+// DefaultTelemetryEndpoint receives usage reports when telemetry is enabled
+const DefaultTelemetryEndpoint = "https://telemetry.byndid.com/v1/scim-sync/usage"
+
+// DefaultMaxResponseBytes is the largest decoded response body accepted when
+// network.max_response_bytes is not set
+const DefaultMaxResponseBytes int64 = 32 << 20 // 32 MiB
+
+// NetworkConfig contains settings shared by the outbound HTTP clients
+type NetworkConfig struct {
+	MaxResponseBytes   int64             `yaml:"max_response_bytes"`
+	CABundle           string            `yaml:"ca_bundle"`            // PEM root certificates trusted instead of the system roots
+	InsecureSkipVerify bool              `yaml:"insecure_skip_verify"` // Disables certificate verification; for troubleshooting only
+	Headers            map[string]string `yaml:"headers"`              // Extra headers sent on every API request, e.g. correlation IDs
+	MaxConnsPerHost    int               `yaml:"max_conns_per_host"`   // Requests in flight to each API host across all workers; 0 is unlimited
+}
+
+// Load loads configuration from a YAML file, applying the profile named by SCIM_SYNC_PROFILE if set
+func Load(configPath string) (*Config, error) {
+	return LoadProfile(configPath, "")
 }
 
 // FindConfigFile searches for configuration file in common locations
@@ -103,10 +722,22 @@ func FindConfigFile() (string, error) {
 
 // SetDefaults sets default values for configuration
 func (c *Config) SetDefaults() {
+	if c.ConfigVersion == 0 {
+		c.ConfigVersion = CurrentConfigVersion
+	}
+
 	if c.App.LogLevel == "" {
 		c.App.LogLevel = "info"
 	}
 
+	if c.App.Mode == "" {
+		c.App.Mode = DeploymentModeHybrid
+	}
+
+	if c.GoogleWorkspace.API == "" {
+		c.GoogleWorkspace.API = GWSAPIAdminSDK
+	}
+
 	if c.BeyondIdentity.SCIMBaseURL == "" {
 		c.BeyondIdentity.SCIMBaseURL = "https://api.byndid.com/scim/v2"
 	}
@@ -115,8 +746,39 @@ func (c *Config) SetDefaults() {
 		c.BeyondIdentity.NativeAPIURL = "https://api.byndid.com/v2"
 	}
 
+	if c.Sync.ConflictPolicy == "" {
+		c.Sync.ConflictPolicy = ConflictAdopt
+	}
+
+	if c.Sync.AllUsersGroup == "" {
+		c.Sync.AllUsersGroup = DefaultAllUsersGroup
+	}
+
+	// The group prefixes of a generic SCIM primary target name the primary groups like
+	// beyond_identity's would
+	if !c.PrimaryIsBeyondIdentity() {
+		if c.Target.GroupPrefix != "" {
+			c.BeyondIdentity.GroupPrefix = c.Target.GroupPrefix
+		}
+		if c.Target.GroupPrefixes != nil {
+			c.BeyondIdentity.GroupPrefixes = c.Target.GroupPrefixes
+		}
+		c.Target.setSCIMDefaults()
+	}
+
 	if c.BeyondIdentity.GroupPrefix == "" {
 		c.BeyondIdentity.GroupPrefix = "GoogleSCIM_"
+		if len(c.BeyondIdentity.GroupPrefixes) > 0 {
+			c.BeyondIdentity.GroupPrefix = c.BeyondIdentity.GroupPrefixes[0]
+		}
+	}
+
+	if c.Metrics.Statsd.Prefix == "" {
+		c.Metrics.Statsd.Prefix = DefaultStatsdPrefix
+	}
+
+	if c.BeyondIdentity.QuotaWarningFraction == 0 {
+		c.BeyondIdentity.QuotaWarningFraction = DefaultQuotaWarningFraction
 	}
 
 	if c.Sync.RetryAttempts == 0 {
@@ -127,18 +789,134 @@ func (c *Config) SetDefaults() {
 		c.Sync.RetryDelaySeconds = 30
 	}
 
+	if c.Sync.StatePath == "" {
+		c.Sync.StatePath = "./sync-state.json"
+	}
+
+	if c.GoogleWorkspace.SlowCallThreshold == "" {
+		c.GoogleWorkspace.SlowCallThreshold = DefaultSlowCallThreshold
+	}
+
+	if c.Sync.Incremental.FullSyncInterval == "" {
+		c.Sync.Incremental.FullSyncInterval = DefaultFullSyncInterval
+	}
+	if c.Sync.Retry.MaxAttempts == 0 {
+		c.Sync.Retry.MaxAttempts = DefaultRetryMaxAttempts
+	}
+
+	if c.Sync.Retry.BaseDelay == "" {
+		c.Sync.Retry.BaseDelay = DefaultRetryBaseDelay
+	}
+
+	if c.Sync.Retry.MaxDelay == "" {
+		c.Sync.Retry.MaxDelay = DefaultRetryMaxDelay
+	}
+
+	if c.Sync.Retry.Budget == "" {
+		c.Sync.Retry.Budget = DefaultRetryBudget
+	}
+
+	if c.Sync.Incremental.Lookback == "" {
+		c.Sync.Incremental.Lookback = DefaultIncrementalLookback
+	}
+	if c.Sync.DisableFile == "" && c.Sync.StatePath != "" {
+		c.Sync.DisableFile = c.Sync.StatePath + ".disabled"
+	}
+
+	if c.Sync.LockLeaseSeconds == 0 {
+		c.Sync.LockLeaseSeconds = 600
+	}
+
+	if c.Sync.OrphanedGroups.ArchiveSuffix == "" {
+		c.Sync.OrphanedGroups.ArchiveSuffix = " (archived)"
+	}
+
+	if c.Sync.AuthErrorThreshold == 0 {
+		c.Sync.AuthErrorThreshold = DefaultAuthErrorThreshold
+	}
+
+	if c.Sync.RetryQueueHours == 0 {
+		c.Sync.RetryQueueHours = DefaultRetryQueueHours
+	}
+
+	if c.Sync.Concurrency == 0 {
+		c.Sync.Concurrency = DefaultConcurrency
+	}
+
+	if c.Sync.MembershipBatchSize == 0 {
+		c.Sync.MembershipBatchSize = DefaultMembershipBatchSize
+	}
+
+	if c.Sync.RecentRuns == 0 {
+		c.Sync.RecentRuns = DefaultRecentRuns
+	}
+
 	if c.Server.Port == 0 {
 		c.Server.Port = 8080
 	}
 
+	if c.Server.AuditLogPath == "" {
+		c.Server.AuditLogPath = "./audit.log"
+	}
+
 	if c.Server.Schedule == "" {
 		c.Server.Schedule = "0 */6 * * *" // Every 6 hours by default
 	}
 
+	if c.Server.Monitor.IntervalSeconds == 0 {
+		c.Server.Monitor.IntervalSeconds = DefaultMonitorIntervalSeconds
+	}
+	if c.Server.Monitor.MaxGoroutines == 0 {
+		c.Server.Monitor.MaxGoroutines = DefaultMonitorMaxGoroutines
+	}
+	if c.Server.Monitor.MaxHeapMB == 0 {
+		c.Server.Monitor.MaxHeapMB = DefaultMonitorMaxHeapMB
+	}
+	if c.Server.Monitor.MaxOpenFDs == 0 {
+		c.Server.Monitor.MaxOpenFDs = DefaultMonitorMaxOpenFDs
+	}
+
 	if c.Sync.RetryDelaySeconds == 0 {
 		c.Sync.RetryDelaySeconds = 30
 	}
 
+	if c.Notifications.Mode == "" {
+		c.Notifications.Mode = NotificationModeImmediate
+	}
+
+	if c.Notifications.Mode == NotificationModeDigest && c.Notifications.Digest.Schedule == "" {
+		c.Notifications.Digest.Schedule = "0 8 * * *" // Daily at 8 AM
+	}
+
+	if c.Notifications.MembershipDiffs.MaxEntries == 0 {
+		c.Notifications.MembershipDiffs.MaxEntries = DefaultDiffMaxEntries
+	}
+
+	if c.Notifications.PagerDuty.FailureThreshold == 0 {
+		c.Notifications.PagerDuty.FailureThreshold = DefaultIncidentFailureThreshold
+	}
+
+	if c.Notifications.Opsgenie.FailureThreshold == 0 {
+		c.Notifications.Opsgenie.FailureThreshold = DefaultIncidentFailureThreshold
+	}
+
+	c.Reminders.setDefaults()
+
+	if c.AccessReview.Format == "" {
+		c.AccessReview.Format = AccessReviewFormatCSV
+	}
+	if c.AccessReview.OutputDir == "" {
+		c.AccessReview.OutputDir = "./access-reviews"
+	}
+
+	if c.Telemetry.Endpoint == "" {
+		c.Telemetry.Endpoint = DefaultTelemetryEndpoint
+	}
+
+	if c.Network.MaxResponseBytes == 0 {
+		c.Network.MaxResponseBytes = DefaultMaxResponseBytes
+	}
+
 	if c.Sync.EnrollmentGroupEmail == "" {
 		c.Sync.EnrollmentGroupEmail = "byid-enrolled@" + c.GoogleWorkspace.Domain
 	}
@@ -146,4 +924,267 @@ func (c *Config) SetDefaults() {
 	if c.Sync.EnrollmentGroupName == "" {
 		c.Sync.EnrollmentGroupName = "BYID Enrolled"
 	}
+	if c.Sync.EnrollmentSchema.Name == "" {
+		c.Sync.EnrollmentSchema.Name = DefaultEnrollmentSchemaName
+	}
+	if c.Sync.EnrollmentSchema.StatusField == "" {
+		c.Sync.EnrollmentSchema.StatusField = DefaultEnrollmentSchemaStatusField
+	}
+	if c.Sync.EnrollmentSchema.CheckedField == "" {
+		c.Sync.EnrollmentSchema.CheckedField = DefaultEnrollmentSchemaCheckedField
+	}
+
+	if c.Source.Type == "" {
+		c.Source.Type = SourceTypeGoogleWorkspace
+	}
+
+	if c.Source.Type == SourceTypeCSV {
+		if c.Source.CSV.Delimiter == "" {
+			c.Source.CSV.Delimiter = ","
+		}
+		if c.Source.CSV.Columns.Group == "" {
+			c.Source.CSV.Columns.Group = "group"
+		}
+		if c.Source.CSV.Columns.Email == "" {
+			c.Source.CSV.Columns.Email = "email"
+		}
+		if c.Source.CSV.SFTP != nil && c.Source.CSV.SFTP.Port == 0 {
+			c.Source.CSV.SFTP.Port = 22
+		}
+	}
+
+	if c.Source.Type == SourceTypeAzureAD {
+		if c.Source.AzureAD.GraphURL == "" {
+			c.Source.AzureAD.GraphURL = DefaultAzureADGraphURL
+		}
+		if c.Source.AzureAD.LoginURL == "" {
+			c.Source.AzureAD.LoginURL = DefaultAzureADLoginURL
+		}
+		// Azure AD groups are synced under their object IDs
+		for _, group := range c.Source.AzureAD.Groups {
+			if !contains(c.Sync.Groups, group) {
+				c.Sync.Groups = append(c.Sync.Groups, group)
+			}
+		}
+	}
+
+	if c.Source.Type == SourceTypeLDAP {
+		ldap := &c.Source.LDAP
+		if ldap.UserFilter == "" {
+			ldap.UserFilter = DefaultLDAPUserFilter
+		}
+		if ldap.EmailAttribute == "" {
+			ldap.EmailAttribute = DefaultLDAPEmailAttribute
+		}
+		if ldap.UsernameAttribute == "" {
+			ldap.UsernameAttribute = DefaultLDAPUsernameAttribute
+		}
+		if ldap.GroupNameAttribute == "" {
+			ldap.GroupNameAttribute = DefaultLDAPGroupNameAttribute
+		}
+		// LDAP groups are synced under their DNs
+		for _, group := range ldap.Groups {
+			if !contains(c.Sync.Groups, group) {
+				c.Sync.Groups = append(c.Sync.Groups, group)
+			}
+		}
+	}
+
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		if target.Type == "" {
+			target.Type = TargetTypeBeyondIdentity
+		}
+		if target.Type == TargetTypeBeyondIdentity {
+			if target.SCIMBaseURL == "" {
+				target.SCIMBaseURL = "https://api.byndid.com/scim/v2"
+			}
+			if target.NativeAPIURL == "" {
+				target.NativeAPIURL = "https://api.byndid.com/v2"
+			}
+		}
+		if target.Type == TargetTypeOkta && target.Auth == "" {
+			target.Auth = OktaAuthAPIToken
+		}
+		if target.Type == TargetTypeGenericSCIM {
+			target.setSCIMDefaults()
+		}
+		if target.GroupPrefix == "" && len(target.GroupPrefixes) > 0 {
+			target.GroupPrefix = target.GroupPrefixes[0]
+		}
+		if target.GroupPrefix == "" {
+			target.GroupPrefix = c.BeyondIdentity.GroupPrefix
+			if target.GroupPrefixes == nil {
+				target.GroupPrefixes = c.BeyondIdentity.GroupPrefixes
+			}
+		}
+	}
+}
+
+// setSCIMDefaults fills in the auth scheme and membership updates of a generic SCIM target
+func (t *TargetConfig) setSCIMDefaults() {
+	if t.Auth == "" {
+		t.Auth = SCIMAuthBearer
+	}
+	if t.MembershipUpdates == "" {
+		t.MembershipUpdates = MembershipUpdatesPatch
+	}
+	if t.MemberRemoval == "" {
+		t.MemberRemoval = MemberRemovalPathFilter
+	}
+}
+
+// TargetForGroup returns the name of the target a group is provisioned into
+func (c *Config) TargetForGroup(groupEmail string) string {
+	if name, ok := c.Sync.GroupTargets[groupEmail]; ok && name != "" {
+		return name
+	}
+	return DefaultTargetName
+}
+
+// FindTarget returns the additional target with the given name, or nil if none is configured
+func (c *Config) FindTarget(name string) *TargetConfig {
+	for i := range c.Targets {
+		if c.Targets[i].Name == name {
+			return &c.Targets[i]
+		}
+	}
+	return nil
+}
+
+// GroupPrefixForTarget returns the group prefix used when creating groups in the named target
+func (c *Config) GroupPrefixForTarget(name string) string {
+	if target := c.FindTarget(name); target != nil {
+		return target.GroupPrefix
+	}
+	return c.BeyondIdentity.GroupPrefix
+}
+
+// LegacyGroupPrefixesForTarget returns the earlier group prefixes of the named target, other than
+// the one used when creating groups
+func (c *Config) LegacyGroupPrefixesForTarget(name string) []string {
+	prefixes := c.BeyondIdentity.GroupPrefixes
+	if target := c.FindTarget(name); target != nil {
+		prefixes = target.GroupPrefixes
+	}
+
+	primary := c.GroupPrefixForTarget(name)
+	seen := map[string]bool{primary: true}
+	var legacy []string
+	for _, prefix := range prefixes {
+		if !seen[prefix] {
+			seen[prefix] = true
+			legacy = append(legacy, prefix)
+		}
+	}
+	return legacy
+}
+
+// SpreadOverDuration returns how long user operations are spread over, or 0 when pacing is disabled or invalid
+func (s *SyncConfig) SpreadOverDuration() time.Duration {
+	if s.SpreadOver == "" {
+		return 0
+	}
+	window, err := time.ParseDuration(s.SpreadOver)
+	if err != nil || window < 0 {
+		return 0
+	}
+	return window
+}
+
+// DisplayNameLanguage returns the language whose casing rules are used for display names derived
+// from email addresses, or language.Und for language-neutral rules when none or an invalid one is set
+func (s *SyncConfig) DisplayNameLanguage() language.Tag {
+	if s.DisplayNameLocale == "" {
+		return language.Und
+	}
+	tag, err := language.Parse(s.DisplayNameLocale)
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
+
+// WatchdogTimeout returns how long a run may take before it is flagged as stuck, or 0 when the
+// watchdog is disabled or the duration is invalid
+func (s *SyncConfig) WatchdogTimeout() time.Duration {
+	if s.MaxExpectedDuration == "" {
+		return 0
+	}
+	limit, err := time.ParseDuration(s.MaxExpectedDuration)
+	if err != nil || limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// SlowCallDuration returns how long an Admin SDK request may take before it is logged as slow, or 0
+// when the logging is disabled or the duration is invalid
+func (g GoogleWorkspaceConfig) SlowCallDuration() time.Duration {
+	threshold, err := time.ParseDuration(g.SlowCallThreshold)
+	if err != nil || threshold < 0 {
+		return 0
+	}
+	return threshold
+}
+
+// FullSyncEvery returns how old the last full sync may get before incremental sync runs a full
+// one, or 0 when the duration is invalid
+func (i *IncrementalConfig) FullSyncEvery() time.Duration {
+	interval, err := time.ParseDuration(i.FullSyncInterval)
+	if err != nil || interval < 0 {
+		return 0
+	}
+	return interval
+}
+
+// LookbackDuration returns how far before the last successful run changes are read, or 0 when
+// the duration is invalid
+func (i *IncrementalConfig) LookbackDuration() time.Duration {
+	lookback, err := time.ParseDuration(i.Lookback)
+	if err != nil || lookback < 0 {
+		return 0
+	}
+	return lookback
+}
+
+// Timeout returns how long a run may take before it is cancelled, or 0 when there is no limit
+func (s *SyncConfig) Timeout() time.Duration {
+	if s.TimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(s.TimeoutSeconds) * time.Second
+}
+
+// setDefaults fills in the reminder settings that were not configured
+func (r *RemindersConfig) setDefaults() {
+	if r.Schedule == "" {
+		r.Schedule = "0 9 * * 1-5" // Weekdays at 9 AM
+	}
+	if r.GracePeriodDays == 0 {
+		r.GracePeriodDays = 7
+	}
+	if r.IntervalDays == 0 {
+		r.IntervalDays = 7
+	}
+	if r.MaxReminders == 0 {
+		r.MaxReminders = 3
+	}
+	if r.Transport == "" {
+		r.Transport = ReminderTransportGmail
+	}
+	if r.Subject == "" {
+		r.Subject = "Reminder: set up your passkey"
+	}
+	if r.StatePath == "" {
+		r.StatePath = "./reminders-state.json"
+	}
+	if r.SMTP.Port == 0 {
+		r.SMTP.Port = 587
+	}
+}
+
+// SimulatesOperation reports whether op is listed in test_mode_operations, so it is only logged
+func (s *SyncConfig) SimulatesOperation(op string) bool {
+	return contains(s.TestModeOperations, op)
 }
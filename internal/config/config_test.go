@@ -3,7 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/text/language"
 )
 
 func TestLoad(t *testing.T) {
@@ -183,13 +188,22 @@ func TestSetDefaults(t *testing.T) {
 		actual   interface{}
 	}{
 		{"default log level", "info", config.App.LogLevel},
+		{"default deployment mode", DeploymentModeHybrid, config.App.Mode},
 		{"default SCIM base URL", "https://api.byndid.com/scim/v2", config.BeyondIdentity.SCIMBaseURL},
 		{"default native API URL", "https://api.byndid.com/v2", config.BeyondIdentity.NativeAPIURL},
 		{"default group prefix", "GoogleSCIM_", config.BeyondIdentity.GroupPrefix},
 		{"default retry attempts", 3, config.Sync.RetryAttempts},
 		{"default retry delay", 30, config.Sync.RetryDelaySeconds},
+		{"default auth error threshold", DefaultAuthErrorThreshold, config.Sync.AuthErrorThreshold},
+		{"default retry queue hours", DefaultRetryQueueHours, config.Sync.RetryQueueHours},
+		{"default concurrency", DefaultConcurrency, config.Sync.Concurrency},
 		{"default server port", 8080, config.Server.Port},
 		{"default schedule", "0 */6 * * *", config.Server.Schedule},
+		{"default max response bytes", int64(32 << 20), config.Network.MaxResponseBytes},
+		{"default group API", GWSAPIAdminSDK, config.GoogleWorkspace.API},
+		{"default source type", SourceTypeGoogleWorkspace, config.Source.Type},
+		{"default notification mode", NotificationModeImmediate, config.Notifications.Mode},
+		{"default PagerDuty failure threshold", DefaultIncidentFailureThreshold, config.Notifications.PagerDuty.FailureThreshold},
 	}
 
 	for _, tt := range tests {
@@ -232,3 +246,260 @@ func TestSetDefaults_PreservesExistingValues(t *testing.T) {
 		t.Errorf("Expected default native API URL, got %s", config.BeyondIdentity.NativeAPIURL)
 	}
 }
+
+func TestSetDefaults_AzureADSource(t *testing.T) {
+	engineering := "6f1c2a0e-8d4b-4c3a-9f1e-2b7d5a9c0e41"
+	sales := "0b9d7e55-3c1a-4f2e-8a6b-91d0c4e7f312"
+	config := &Config{
+		Sync: SyncConfig{Groups: []string{engineering}},
+		Source: SourceConfig{
+			Type:    SourceTypeAzureAD,
+			AzureAD: AzureADSourceConfig{Groups: []string{engineering, sales}},
+		},
+	}
+
+	config.SetDefaults()
+
+	if len(config.Sync.Groups) != 2 || config.Sync.Groups[1] != sales {
+		t.Errorf("Expected the Azure AD groups to be added to sync.groups once, got %v", config.Sync.Groups)
+	}
+	if config.Source.AzureAD.GraphURL != DefaultAzureADGraphURL || config.Source.AzureAD.LoginURL != DefaultAzureADLoginURL {
+		t.Errorf("Expected the global cloud endpoints, got %s and %s", config.Source.AzureAD.GraphURL, config.Source.AzureAD.LoginURL)
+	}
+}
+
+func TestSetDefaults_LDAPSource(t *testing.T) {
+	engineering := "CN=Engineering,OU=Groups,DC=corp,DC=example,DC=com"
+	config := &Config{
+		Source: SourceConfig{
+			Type: SourceTypeLDAP,
+			LDAP: LDAPSourceConfig{Groups: []string{engineering}, EmailAttribute: "userPrincipalName"},
+		},
+	}
+
+	config.SetDefaults()
+
+	if len(config.Sync.Groups) != 1 || config.Sync.Groups[0] != engineering {
+		t.Errorf("Expected the LDAP group to be added to sync.groups, got %v", config.Sync.Groups)
+	}
+	ldap := config.Source.LDAP
+	if ldap.UserFilter != DefaultLDAPUserFilter || ldap.UsernameAttribute != DefaultLDAPUsernameAttribute || ldap.GroupNameAttribute != DefaultLDAPGroupNameAttribute {
+		t.Errorf("Expected the Active Directory defaults, got %+v", ldap)
+	}
+	if ldap.EmailAttribute != "userPrincipalName" {
+		t.Errorf("Expected the configured email attribute to be kept, got %s", ldap.EmailAttribute)
+	}
+}
+
+func TestTargetForGroup(t *testing.T) {
+	config := &Config{
+		BeyondIdentity: BeyondIdentityConfig{
+			GroupPrefix: "GoogleSCIM_",
+		},
+		Targets: []TargetConfig{
+			{Name: "eu", GroupPrefix: "EU_"},
+			{Name: "apac"},
+		},
+		Sync: SyncConfig{
+			GroupTargets: map[string]string{
+				"eng@test.com": "eu",
+			},
+		},
+	}
+	config.SetDefaults()
+
+	if got := config.TargetForGroup("eng@test.com"); got != "eu" {
+		t.Errorf("Expected target 'eu', got '%s'", got)
+	}
+	if got := config.TargetForGroup("sales@test.com"); got != DefaultTargetName {
+		t.Errorf("Expected default target, got '%s'", got)
+	}
+	if got := config.GroupPrefixForTarget("eu"); got != "EU_" {
+		t.Errorf("Expected prefix 'EU_', got '%s'", got)
+	}
+	if got := config.GroupPrefixForTarget("apac"); got != "GoogleSCIM_" {
+		t.Errorf("Expected inherited prefix 'GoogleSCIM_', got '%s'", got)
+	}
+	if got := config.GroupPrefixForTarget(DefaultTargetName); got != "GoogleSCIM_" {
+		t.Errorf("Expected default prefix 'GoogleSCIM_', got '%s'", got)
+	}
+	if config.Targets[1].SCIMBaseURL != "https://api.byndid.com/scim/v2" {
+		t.Errorf("Expected default SCIM URL for target, got '%s'", config.Targets[1].SCIMBaseURL)
+	}
+}
+
+func TestDeploymentMode(t *testing.T) {
+	tests := []struct {
+		mode        string
+		wantOneShot bool
+		wantServer  bool
+	}{
+		{DeploymentModeOneShot, true, false},
+		{DeploymentModeServer, false, true},
+		{DeploymentModeHybrid, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			config := &Config{App: AppConfig{Mode: tt.mode}}
+			if config.RunsOneShot() != tt.wantOneShot || config.RunsServer() != tt.wantServer {
+				t.Errorf("Expected one-shot %v and server %v, got %v and %v", tt.wantOneShot, tt.wantServer, config.RunsOneShot(), config.RunsServer())
+			}
+		})
+	}
+}
+
+func TestSave_DeploymentMode(t *testing.T) {
+	tests := []struct {
+		mode       string
+		wantServer bool
+		wantRun    bool
+	}{
+		{DeploymentModeOneShot, false, true},
+		{DeploymentModeServer, true, false},
+		{DeploymentModeHybrid, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			config := &Config{App: AppConfig{Mode: tt.mode}}
+			config.SetDefaults()
+			if err := Save(config, path); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content := string(data)
+			if strings.Contains(content, "\nserver:") != tt.wantServer {
+				t.Errorf("Expected server section: %v, got:\n%s", tt.wantServer, content)
+			}
+			if strings.Contains(content, "./scim-sync run") != tt.wantRun {
+				t.Errorf("Expected one-shot usage: %v, got:\n%s", tt.wantRun, content)
+			}
+
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if loaded.App.Mode != tt.mode {
+				t.Errorf("Expected mode %s to be saved, got %s", tt.mode, loaded.App.Mode)
+			}
+		})
+	}
+}
+
+func TestLegacyGroupPrefixesForTarget(t *testing.T) {
+	config := &Config{
+		BeyondIdentity: BeyondIdentityConfig{
+			GroupPrefixes: []string{"GWS_", "GoogleSCIM_", "GWS_"},
+		},
+		Targets: []TargetConfig{
+			{Name: "eu", GroupPrefix: "EU_"},
+			{Name: "apac"},
+		},
+	}
+	config.SetDefaults()
+
+	if got := config.GroupPrefixForTarget(DefaultTargetName); got != "GWS_" {
+		t.Errorf("Expected the first group prefix to be used, got '%s'", got)
+	}
+	if got := config.LegacyGroupPrefixesForTarget(DefaultTargetName); !reflect.DeepEqual(got, []string{"GoogleSCIM_"}) {
+		t.Errorf("Expected legacy prefixes [GoogleSCIM_], got %v", got)
+	}
+	if got := config.LegacyGroupPrefixesForTarget("apac"); !reflect.DeepEqual(got, []string{"GoogleSCIM_"}) {
+		t.Errorf("Expected inherited legacy prefixes, got %v", got)
+	}
+	if got := config.LegacyGroupPrefixesForTarget("eu"); len(got) != 0 {
+		t.Errorf("Expected no legacy prefixes for a target with its own prefix, got %v", got)
+	}
+}
+
+func TestAdminSubjects(t *testing.T) {
+	gwsConfig := GoogleWorkspaceConfig{
+		SuperAdminEmail:     "admin@example.com",
+		FallbackAdminEmails: []string{"backup@example.com"},
+		DomainAdmins: map[string][]string{
+			"Subsidiary.com": {"admin@subsidiary.com", "backup@subsidiary.com"},
+		},
+	}
+
+	tests := []struct {
+		groupEmail string
+		expected   []string
+	}{
+		{"eng@example.com", []string{"admin@example.com", "backup@example.com"}},
+		{"eng@subsidiary.com", []string{"admin@subsidiary.com", "backup@subsidiary.com"}},
+		{"eng@other.com", []string{"admin@example.com", "backup@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupEmail, func(t *testing.T) {
+			subjects := gwsConfig.AdminSubjects(tt.groupEmail)
+			if strings.Join(subjects, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, subjects)
+			}
+		})
+	}
+}
+
+func TestDisplayNameLanguage(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected language.Tag
+	}{
+		{"", language.Und},
+		{"tr", language.Turkish},
+		{"nl-BE", language.MustParse("nl-BE")},
+		{"not a locale", language.Und},
+	}
+
+	for _, tt := range tests {
+		sync := SyncConfig{DisplayNameLocale: tt.locale}
+		if got := sync.DisplayNameLanguage(); got != tt.expected {
+			t.Errorf("DisplayNameLanguage(%q) = %s, expected %s", tt.locale, got, tt.expected)
+		}
+	}
+}
+
+func TestWatchdogTimeout(t *testing.T) {
+	tests := []struct {
+		maxExpected string
+		expected    time.Duration
+	}{
+		{"", 0},
+		{"2h", 2 * time.Hour},
+		{"-1h", 0},
+		{"forever", 0},
+	}
+
+	for _, tt := range tests {
+		sync := SyncConfig{MaxExpectedDuration: tt.maxExpected}
+		if got := sync.WatchdogTimeout(); got != tt.expected {
+			t.Errorf("WatchdogTimeout(%q) = %s, expected %s", tt.maxExpected, got, tt.expected)
+		}
+	}
+}
+
+func TestSpreadOverDuration(t *testing.T) {
+	tests := []struct {
+		spreadOver string
+		expected   time.Duration
+	}{
+		{"", 0},
+		{"30m", 30 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"-5m", 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		sync := SyncConfig{SpreadOver: tt.spreadOver}
+		if got := sync.SpreadOverDuration(); got != tt.expected {
+			t.Errorf("SpreadOverDuration(%q) = %s, expected %s", tt.spreadOver, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import "sort"
+
+// Feature flags gating provisioning behaviors that are rolled out per deployment
+const (
+	FeatureDeprovisioning = "deprovisioning"
+	FeatureBulkAPI        = "bulk_api"
+	FeatureShadowMode     = "shadow_mode"
+	FeatureUserUpdates    = "user_updates"
+	FeatureUserPrefetch   = "user_prefetch"
+)
+
+// FeatureFlag describes a registered feature flag
+type FeatureFlag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// featureRegistry lists every flag the features block accepts; add new risky behaviors here
+// with a default of false, and flip the default once they have proven themselves
+var featureRegistry = []FeatureFlag{
+	{
+		Name:        FeatureDeprovisioning,
+		Description: "Allow removing users from every managed group, and deactivating them, via POST /users/deprovision",
+		Default:     true,
+	},
+	{
+		Name:        FeatureBulkAPI,
+		Description: "Create missing users with SCIM Bulk requests instead of one request per user",
+		Default:     false,
+	},
+	{
+		Name:        FeatureShadowMode,
+		Description: "Also plan each run's membership changes with the shadow planner, without applying them, and report where it disagrees",
+		Default:     false,
+	},
+	{
+		Name:        FeatureUserUpdates,
+		Description: "Update existing users whose display name, emails or mapped attributes drifted from Google Workspace",
+		Default:     false,
+	},
+	{
+		Name:        FeatureUserPrefetch,
+		Description: "List each target's users once per run and look members up in memory instead of with one filtered request each",
+		Default:     false,
+	},
+}
+
+// FeatureFlags returns the registered flags sorted by name
+func FeatureFlags() []FeatureFlag {
+	flags := append([]FeatureFlag(nil), featureRegistry...)
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// LookupFeature returns the registered flag with the given name
+func LookupFeature(name string) (FeatureFlag, bool) {
+	for _, flag := range featureRegistry {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+	return FeatureFlag{}, false
+}
+
+// FeatureEnabled reports whether a flag is on, falling back to its default when the features
+// block does not set it; unknown flags are off
+func (c *Config) FeatureEnabled(name string) bool {
+	flag, ok := LookupFeature(name)
+	if !ok {
+		return false
+	}
+	if enabled, set := c.Features[name]; set {
+		return enabled
+	}
+	return flag.Default
+}
+
+// FeatureState is a registered flag with its effective value in this configuration
+type FeatureState struct {
+	FeatureFlag
+	Enabled    bool `json:"enabled"`
+	Configured bool `json:"configured"` // Set in the features block rather than defaulted
+}
+
+// FeatureStates returns every registered flag with its effective value, sorted by name
+func (c *Config) FeatureStates() []FeatureState {
+	var states []FeatureState
+	for _, flag := range FeatureFlags() {
+		_, configured := c.Features[flag.Name]
+		states = append(states, FeatureState{
+			FeatureFlag: flag,
+			Enabled:     c.FeatureEnabled(flag.Name),
+			Configured:  configured,
+		})
+	}
+	return states
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFeatureEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		features map[string]bool
+		flag     string
+		expected bool
+	}{
+		{"default on", nil, FeatureDeprovisioning, true},
+		{"default off", nil, FeatureBulkAPI, false},
+		{"override on", map[string]bool{FeatureBulkAPI: true}, FeatureBulkAPI, true},
+		{"override off", map[string]bool{FeatureDeprovisioning: false}, FeatureDeprovisioning, false},
+		{"unknown flag", map[string]bool{"teleport": true}, "teleport", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Features: tt.features}
+			if got := cfg.FeatureEnabled(tt.flag); got != tt.expected {
+				t.Errorf("Expected %s to be %t, got %t", tt.flag, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFeatureStates(t *testing.T) {
+	cfg := &Config{Features: map[string]bool{FeatureBulkAPI: true}}
+
+	states := cfg.FeatureStates()
+	if len(states) != len(featureRegistry) {
+		t.Fatalf("Expected every registered flag, got %+v", states)
+	}
+	for i := 1; i < len(states); i++ {
+		if states[i-1].Name > states[i].Name {
+			t.Errorf("Expected flags sorted by name, got %s before %s", states[i-1].Name, states[i].Name)
+		}
+	}
+	for _, state := range states {
+		if state.Description == "" {
+			t.Errorf("Expected %s to be described", state.Name)
+		}
+		if state.Name == FeatureBulkAPI && (!state.Enabled || !state.Configured) {
+			t.Errorf("Expected bulk_api to be enabled by configuration, got %+v", state)
+		}
+		if state.Name == FeatureDeprovisioning && (!state.Enabled || state.Configured) {
+			t.Errorf("Expected deprovisioning to be enabled by default, got %+v", state)
+		}
+	}
+}
+
+func TestValidate_UnknownFeature(t *testing.T) {
+	cfg := &Config{Features: map[string]bool{"bulk-api": true}}
+	cfg.SetDefaults()
+
+	err := cfg.ValidateWithOptions(ValidateOptions{SkipAPIToken: true})
+	if err == nil || !strings.Contains(err.Error(), "features.bulk-api") {
+		t.Errorf("Expected unknown feature flag error, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentConfigVersion is the schema version written by this build
+const CurrentConfigVersion = 2
+
+// migration upgrades a configuration document from one schema version to the next
+type migration struct {
+	from        int
+	description string
+	apply       func(root *yaml.Node) error
+}
+
+// migrations are applied in order to configs older than CurrentConfigVersion; files without
+// config_version predate versioning and are version 1
+var migrations = []migration{
+	{from: 1, description: "move per-group target objects in sync.groups to sync.group_targets", apply: migrateGroupObjects},
+}
+
+// MigrationResult describes an upgrade of a configuration document
+type MigrationResult struct {
+	From    int
+	To      int
+	Applied []string // Descriptions of the steps that ran
+	Data    []byte   // The upgraded document
+	Backup  string   // Copy of the original file, when the file was rewritten
+}
+
+// Changed reports whether the document was upgraded
+func (m *MigrationResult) Changed() bool {
+	return m.From != m.To
+}
+
+// MigrateYAML upgrades a configuration document to CurrentConfigVersion, keeping comments
+func MigrateYAML(data []byte) (*MigrationResult, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		// Empty documents load with defaults; nothing to upgrade
+		return &MigrationResult{From: CurrentConfigVersion, To: CurrentConfigVersion, Data: data}, nil
+	}
+	root := doc.Content[0]
+
+	version := 1
+	if node := mappingValue(root, "config_version"); node != nil {
+		v, err := strconv.Atoi(node.Value)
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("invalid config_version %q", node.Value)
+		}
+		version = v
+	}
+	if version > CurrentConfigVersion {
+		return nil, fmt.Errorf("config_version %d is newer than this build supports (%d); upgrade scim-sync", version, CurrentConfigVersion)
+	}
+
+	result := &MigrationResult{From: version, To: CurrentConfigVersion, Data: data}
+	if version == CurrentConfigVersion {
+		return result, nil
+	}
+
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+		if err := m.apply(root); err != nil {
+			return nil, fmt.Errorf("failed to migrate config from version %d: %w", m.from, err)
+		}
+		result.Applied = append(result.Applied, m.description)
+	}
+	setConfigVersion(root, CurrentConfigVersion)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	result.Data = buf.Bytes()
+	return result, nil
+}
+
+// MigrateFile upgrades a configuration file in place, first copying the original next to it;
+// with dryRun the file is left untouched
+func MigrateFile(path string, dryRun bool) (*MigrationResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	// Migrate the raw file so ${VAR} references are kept rather than expanded
+	result, err := MigrateYAML(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate config file %s: %w", path, err)
+	}
+	if !result.Changed() || dryRun {
+		return result, nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d-%s.bak", path, result.From, time.Now().Format("20060102150405"))
+	if err := os.WriteFile(backup, original, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write config backup: %w", err)
+	}
+	if err := os.WriteFile(path, result.Data, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write migrated config: %w", err)
+	}
+	result.Backup = backup
+	return result, nil
+}
+
+// migrateGroupObjects rewrites sync.groups entries of the form {email, target} to plain
+// emails, moving each target to sync.group_targets
+func migrateGroupObjects(root *yaml.Node) error {
+	syncNode := mappingValue(root, "sync")
+	if syncNode == nil || syncNode.Kind != yaml.MappingNode {
+		return nil
+	}
+	groups := mappingValue(syncNode, "groups")
+	if groups == nil || groups.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	for i, entry := range groups.Content {
+		if entry.Kind != yaml.MappingNode {
+			continue
+		}
+		email := mappingValue(entry, "email")
+		if email == nil || email.Value == "" {
+			return fmt.Errorf("sync.groups[%d] has no email", i)
+		}
+
+		if target := mappingValue(entry, "target"); target != nil && target.Value != "" {
+			targets := mappingValue(syncNode, "group_targets")
+			if targets == nil {
+				targets = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				setMappingValue(syncNode, "group_targets", targets)
+			}
+			key, value := *email, *target
+			if mappingValue(targets, email.Value) != nil {
+				setMappingValue(targets, email.Value, &value)
+			} else {
+				targets.Content = append(targets.Content, &key, &value)
+			}
+		}
+
+		replacement := *email
+		replacement.HeadComment = entry.HeadComment
+		replacement.LineComment = entry.LineComment
+		groups.Content[i] = &replacement
+	}
+	return nil
+}
+
+// setConfigVersion sets config_version, adding it as the first key of the document
+func setConfigVersion(root *yaml.Node, version int) {
+	value := scalarNode(strconv.Itoa(version))
+	value.Tag = "!!int"
+	if node := mappingValue(root, "config_version"); node != nil {
+		*node = *value
+		return
+	}
+
+	key := scalarNode("config_version")
+	value.LineComment = "# Schema version; upgrade older files with: scim-sync config migrate"
+	if len(root.Content) > 0 {
+		// Keep the file's header comment above the new key
+		key.HeadComment = root.Content[0].HeadComment
+		root.Content[0].HeadComment = ""
+	}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value of key in a mapping node, appending the key if missing
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, scalarNode(key), value)
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const legacyConfig = `# Production sync
+beyond_identity:
+  api_token: "${BI_API_TOKEN}"
+sync:
+  groups:
+    - "eng@test.com" # Engineering
+    - email: "sales@test.com"
+      target: "emea"
+    - email: "ops@test.com"
+targets:
+  - name: "emea"
+    type: "beyond_identity"
+`
+
+func TestMigrateYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectError bool
+		expectFrom  int
+		validate    func(t *testing.T, cfg *Config, data string)
+	}{
+		{
+			name:       "unversioned config with per-group objects",
+			input:      legacyConfig,
+			expectFrom: 1,
+			validate: func(t *testing.T, cfg *Config, data string) {
+				if cfg.ConfigVersion != CurrentConfigVersion {
+					t.Errorf("Expected config_version %d, got %d", CurrentConfigVersion, cfg.ConfigVersion)
+				}
+				expected := []string{"eng@test.com", "sales@test.com", "ops@test.com"}
+				if strings.Join(cfg.Sync.Groups, ",") != strings.Join(expected, ",") {
+					t.Errorf("Expected groups %v, got %v", expected, cfg.Sync.Groups)
+				}
+				if cfg.Sync.GroupTargets["sales@test.com"] != "emea" || len(cfg.Sync.GroupTargets) != 1 {
+					t.Errorf("Expected sales@test.com to move to group_targets, got %v", cfg.Sync.GroupTargets)
+				}
+				for _, kept := range []string{"# Production sync", "# Engineering", "${BI_API_TOKEN}"} {
+					if !strings.Contains(data, kept) {
+						t.Errorf("Expected %q to be kept, got:\n%s", kept, data)
+					}
+				}
+			},
+		},
+		{
+			name:       "current version is left untouched",
+			input:      "config_version: 2\nsync:\n  groups: [\"eng@test.com\"]\n",
+			expectFrom: 2,
+			validate: func(t *testing.T, cfg *Config, data string) {
+				if data != "config_version: 2\nsync:\n  groups: [\"eng@test.com\"]\n" {
+					t.Errorf("Expected the document to be unchanged, got:\n%s", data)
+				}
+			},
+		},
+		{
+			name:        "group object without email",
+			input:       "sync:\n  groups:\n    - target: emea\n",
+			expectError: true,
+		},
+		{
+			name:        "newer version",
+			input:       "config_version: 99\n",
+			expectError: true,
+		},
+		{
+			name:       "empty document",
+			input:      "",
+			expectFrom: CurrentConfigVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := MigrateYAML([]byte(tt.input))
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.From != tt.expectFrom || result.To != CurrentConfigVersion {
+				t.Errorf("Expected migration from %d to %d, got %d to %d", tt.expectFrom, CurrentConfigVersion, result.From, result.To)
+			}
+			if tt.validate == nil {
+				return
+			}
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, result.Data, 0644); err != nil {
+				t.Fatalf("Failed to write migrated config: %v", err)
+			}
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Failed to load migrated config: %v", err)
+			}
+			tt.validate(t, cfg, string(result.Data))
+		})
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(legacyConfig), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	// Load upgrades in memory without touching the file
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Sync.GroupTargets["sales@test.com"] != "emea" {
+		t.Errorf("Expected Load to migrate in memory, got %v", cfg.Sync.GroupTargets)
+	}
+
+	if _, err := MigrateFile(path, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != legacyConfig {
+		t.Error("Expected dry run to leave the file unchanged")
+	}
+
+	result, err := MigrateFile(path, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	backup, err := os.ReadFile(result.Backup)
+	if err != nil || string(backup) != legacyConfig {
+		t.Errorf("Expected the original to be backed up, got %q (%v)", backup, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file mode to be kept, got %v (%v)", info.Mode(), err)
+	}
+
+	again, err := MigrateFile(path, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if again.Changed() || again.Backup != "" {
+		t.Errorf("Expected a migrated file to be left alone, got %+v", again)
+	}
+}
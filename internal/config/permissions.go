@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// SecretFileMode is the mode written for files holding secrets: readable by the owner only
+const SecretFileMode os.FileMode = 0600
+
+// PermissionIssue is a file holding secrets that group or other users can access
+type PermissionIssue struct {
+	Path string
+	Mode os.FileMode
+}
+
+func (p PermissionIssue) String() string {
+	return fmt.Sprintf("%s is accessible by group or other users (mode %04o); restrict it with: chmod 600 %s", p.Path, p.Mode.Perm(), p.Path)
+}
+
+// CheckFilePermissions reports whether the file at path is accessible beyond its owner; missing
+// files are not reported, and Windows is skipped since its ACLs are not reflected in the mode
+func CheckFilePermissions(path string) (*PermissionIssue, error) {
+	if path == "" || runtime.GOOS == "windows" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permissions of %s: %w", path, err)
+	}
+	if info.Mode().Perm()&0077 == 0 {
+		return nil, nil
+	}
+	return &PermissionIssue{Path: path, Mode: info.Mode()}, nil
+}
+
+// SecretFiles are the files holding credentials: the config file with the API tokens and the
+// service account keys
+func (c *Config) SecretFiles() []string {
+	var paths []string
+	for _, path := range []string{c.Path, c.GoogleWorkspace.ServiceAccountKeyPath, c.GoogleWorkspace.NextServiceAccountKeyPath} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// CheckPermissions returns the secret files that group or other users can access
+func (c *Config) CheckPermissions() ([]PermissionIssue, error) {
+	var issues []PermissionIssue
+	for _, path := range c.SecretFiles() {
+		issue, err := CheckFilePermissions(path)
+		if err != nil {
+			return issues, err
+		}
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues, nil
+}
+
+// EnforcePermissions checks the secret files and, with app.strict_permissions, returns an error
+// when any of them is too open; otherwise the issues are returned to be reported as warnings
+func (c *Config) EnforcePermissions() ([]PermissionIssue, error) {
+	issues, err := c.CheckPermissions()
+	if err != nil {
+		return issues, err
+	}
+	if len(issues) > 0 && c.App.StrictPermissions {
+		return issues, fmt.Errorf("refusing to start with app.strict_permissions enabled: %s", issues[0])
+	}
+	return issues, nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFileMode writes a file with exactly mode, regardless of the umask
+func writeFileMode(t *testing.T, path string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("secret"), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckFilePermissions(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		mode        os.FileMode
+		expectIssue bool
+	}{
+		{"owner only", 0600, false},
+		{"owner read only", 0400, false},
+		{"group readable", 0640, true},
+		{"world readable", 0644, true},
+		{"world writable", 0602, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_"))
+			writeFileMode(t, path, tt.mode)
+
+			issue, err := CheckFilePermissions(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (issue != nil) != tt.expectIssue {
+				t.Errorf("Expected issue: %v, got %v", tt.expectIssue, issue)
+			}
+		})
+	}
+
+	// Missing files are reported by the checks that need them
+	if issue, err := CheckFilePermissions(filepath.Join(dir, "missing")); issue != nil || err != nil {
+		t.Errorf("Expected no issue for a missing file, got %v, %v", issue, err)
+	}
+}
+
+func TestEnforcePermissions(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	keyPath := filepath.Join(dir, "key.json")
+	writeFileMode(t, configPath, 0600)
+	writeFileMode(t, keyPath, 0644)
+
+	cfg := &Config{Path: configPath, GoogleWorkspace: GoogleWorkspaceConfig{ServiceAccountKeyPath: keyPath}}
+
+	issues, err := cfg.EnforcePermissions()
+	if err != nil {
+		t.Fatalf("Expected only a warning without strict_permissions, got %v", err)
+	}
+	if len(issues) != 1 || issues[0].Path != keyPath {
+		t.Fatalf("Expected the key to be reported, got %v", issues)
+	}
+	if !strings.Contains(issues[0].String(), "chmod 600 "+keyPath) {
+		t.Errorf("Expected the remediation in %q", issues[0])
+	}
+
+	cfg.App.StrictPermissions = true
+	if _, err := cfg.EnforcePermissions(); err == nil {
+		t.Error("Expected an error with strict_permissions")
+	}
+
+	writeFileMode(t, keyPath, 0600)
+	if issues, err := cfg.EnforcePermissions(); err != nil || len(issues) != 0 {
+		t.Errorf("Expected no issues once the key is restricted, got %v, %v", issues, err)
+	}
+}
+
+func TestSaveRestrictsPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFileMode(t, path, 0644)
+
+	cfg := &Config{}
+	cfg.SetDefaults()
+	if err := Save(cfg, path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != SecretFileMode {
+		t.Errorf("Expected an existing file to be restricted to %04o, got %04o", SecretFileMode, info.Mode().Perm())
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if loaded.Path != path {
+		t.Errorf("Expected the loaded path to be recorded, got %q", loaded.Path)
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnvVar selects a profile when --profile is not given
+const ProfileEnvVar = "SCIM_SYNC_PROFILE"
+
+// LoadProfile loads a configuration file and applies the named profile from its profiles section
+// over the base settings; an empty name falls back to SCIM_SYNC_PROFILE, and to the base settings
+// alone when that is unset too
+func LoadProfile(configPath, profile string) (*Config, error) {
+	if profile == "" {
+		profile = os.Getenv(ProfileEnvVar)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+
+	// Substitute environment variables
+	configData := os.ExpandEnv(string(data))
+
+	// Upgrade older layouts in memory; MigrateFile rewrites the file itself
+	migrated, err := MigrateYAML([]byte(configData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(migrated.Data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+
+	var config Config
+	if len(doc.Content) == 0 {
+		if profile != "" {
+			return nil, fmt.Errorf("profile %q not found: config file %s is empty", profile, configPath)
+		}
+		config.Path = configPath
+		return &config, nil
+	}
+
+	root := doc.Content[0]
+	if err := applyProfile(root, profile); err != nil {
+		return nil, fmt.Errorf("failed to apply profile in config file %s: %w", configPath, err)
+	}
+
+	if err := root.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	config.Profile = profile
+	config.Path = configPath
+
+	return &config, nil
+}
+
+// applyProfile merges the named profile over the base settings and drops the profiles section
+func applyProfile(root *yaml.Node, profile string) error {
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	profiles := mappingValue(root, "profiles")
+	removeMappingKey(root, "profiles")
+	if profile == "" {
+		return nil
+	}
+
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		return fmt.Errorf("profile %q not found: the file has no profiles section", profile)
+	}
+
+	override := mappingValue(profiles, profile)
+	if override == nil {
+		var names []string
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			names = append(names, profiles.Content[i].Value)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("profile %q not found (available: %s)", profile, strings.Join(names, ", "))
+	}
+	if override.Kind != yaml.MappingNode {
+		return fmt.Errorf("profile %q must be a mapping of settings", profile)
+	}
+
+	// Profiles may not nest or change the schema version they are read with
+	for _, key := range []string{"profiles", "config_version"} {
+		if mappingValue(override, key) != nil {
+			return fmt.Errorf("profile %q may not set %s", profile, key)
+		}
+	}
+
+	mergeNodes(root, override)
+	return nil
+}
+
+// mergeNodes overlays override onto base: mappings are merged key by key, while scalars and
+// sequences such as sync.groups replace the base value
+func mergeNodes(base, override *yaml.Node) {
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i].Value, override.Content[i+1]
+
+		existing := mappingValue(base, key)
+		if existing != nil && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeNodes(existing, value)
+			continue
+		}
+		setMappingValue(base, key, value)
+	}
+}
+
+// removeMappingKey deletes key and its value from a mapping node
+func removeMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
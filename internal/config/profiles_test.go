@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profilesConfig = `
+app:
+  log_level: info
+  test_mode: false
+google_workspace:
+  domain: example.com
+  super_admin_email: admin@example.com
+beyond_identity:
+  scim_base_url: https://api.byndid.com/scim/v2
+  group_prefix: GWS_
+sync:
+  groups:
+    - eng@example.com
+    - sales@example.com
+  retry_attempts: 5
+profiles:
+  dev:
+    app:
+      test_mode: true
+      log_level: debug
+    beyond_identity:
+      scim_base_url: https://api.dev.byndid.com/scim/v2
+    sync:
+      groups:
+        - sandbox@example.com
+  prod: {}
+`
+
+func writeProfilesConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(profilesConfig), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := writeProfilesConfig(t)
+
+	cfg, err := LoadProfile(path, "dev")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Profile != "dev" || !cfg.App.TestMode || cfg.App.LogLevel != "debug" {
+		t.Errorf("Expected the dev app settings, got %+v (profile %q)", cfg.App, cfg.Profile)
+	}
+	if cfg.BeyondIdentity.SCIMBaseURL != "https://api.dev.byndid.com/scim/v2" || cfg.BeyondIdentity.GroupPrefix != "GWS_" {
+		t.Errorf("Expected the endpoint overridden and the prefix inherited, got %+v", cfg.BeyondIdentity)
+	}
+	if len(cfg.Sync.Groups) != 1 || cfg.Sync.Groups[0] != "sandbox@example.com" || cfg.Sync.RetryAttempts != 5 {
+		t.Errorf("Expected groups replaced and retries inherited, got %+v", cfg.Sync)
+	}
+
+	// Without a profile only the base settings apply
+	cfg, err = LoadProfile(path, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Profile != "" || cfg.App.TestMode || len(cfg.Sync.Groups) != 2 {
+		t.Errorf("Expected the base settings, got %+v", cfg)
+	}
+
+	if _, err := LoadProfile(path, "staging"); err == nil || !strings.Contains(err.Error(), "available: dev, prod") {
+		t.Errorf("Expected an unknown profile to be rejected, got %v", err)
+	}
+}
+
+func TestLoadProfile_EnvVar(t *testing.T) {
+	path := writeProfilesConfig(t)
+	t.Setenv(ProfileEnvVar, "dev")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Profile != "dev" || !cfg.App.TestMode {
+		t.Errorf("Expected the profile from %s, got %q", ProfileEnvVar, cfg.Profile)
+	}
+
+	// The flag takes precedence over the environment
+	cfg, err = LoadProfile(path, "prod")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Profile != "prod" || cfg.App.TestMode {
+		t.Errorf("Expected the prod profile, got %q", cfg.Profile)
+	}
+}
@@ -7,17 +7,21 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Save saves configuration to a YAML file with helpful comments
+// Save saves configuration to a YAML file with helpful comments; the server section and its
+// usage notes are left out of one-shot deployments (app.mode: oneshot)
 func Save(cfg *Config, path string) error {
 	// Create YAML content with comments
 	yamlContent := fmt.Sprintf(`# Go SCIM Sync Configuration File
 # This configuration was generated by the setup wizard
 # For more information, see: https://github.com/gobeyondidentity/google-workspace-provisioner
 
+config_version: %d          # Schema version; upgrade older files with: scim-sync config migrate
+
 # Application settings
 app:
   log_level: "%s"          # Options: debug, info, warn, error
   test_mode: %t            # Set to false to perform actual changes
+  mode: "%s"               # Deployment mode: oneshot, server or hybrid
 
 # Google Workspace configuration
 google_workspace:
@@ -37,26 +41,11 @@ sync:
   groups:                                      # List of Google Workspace groups to sync%s
   retry_attempts: %d                            # Number of retry attempts for failed operations
   retry_delay_seconds: %d                      # Delay between retry attempts
-
-# Server mode settings (for 'server' command)
-server:
-  port: %d                                   # HTTP server port
-  schedule_enabled: %t                      # Enable automatic sync scheduling
-  schedule: "%s"                     # Cron schedule
-
-# Usage:
-# 1. Validate config: ./scim-sync validate-config
-# 2. Run one-time sync: ./scim-sync run
-# 3. Start server mode: ./scim-sync server
-#
-# Server endpoints (when running in server mode):
-# - Health check: GET http://localhost:%d/health
-# - Manual sync: POST http://localhost:%d/sync
-# - Metrics: GET http://localhost:%d/metrics
-# - Version: GET http://localhost:%d/version
 `,
+		CurrentConfigVersion,
 		cfg.App.LogLevel,
 		cfg.App.TestMode,
+		cfg.App.Mode,
 		cfg.GoogleWorkspace.Domain,
 		cfg.GoogleWorkspace.SuperAdminEmail,
 		cfg.GoogleWorkspace.ServiceAccountKeyPath,
@@ -67,9 +56,46 @@ server:
 		formatGroups(cfg.Sync.Groups),
 		cfg.Sync.RetryAttempts,
 		cfg.Sync.RetryDelaySeconds,
+	)
+
+	if !cfg.RunsServer() {
+		yamlContent += `
+# Usage:
+# 1. Validate config: ./scim-sync validate-config
+# 2. Run one-time sync: ./scim-sync run
+# 3. Schedule runs with cron, e.g. the crontab written by the setup wizard
+`
+		return writeSecretFile(path, []byte(yamlContent))
+	}
+
+	yamlContent += fmt.Sprintf(`
+# Server mode settings (for 'server' command)
+server:
+  port: %d                                   # HTTP server port
+  schedule_enabled: %t                      # Enable automatic sync scheduling
+  schedule: "%s"                     # Cron schedule
+
+# Usage:
+# 1. Validate config: ./scim-sync validate-config
+`,
 		cfg.Server.Port,
 		cfg.Server.ScheduleEnabled,
 		cfg.Server.Schedule,
+	)
+	step := 2
+	if cfg.RunsOneShot() {
+		yamlContent += "# 2. Run one-time sync: ./scim-sync run\n"
+		step++
+	}
+	yamlContent += fmt.Sprintf(`# %d. Start server mode: ./scim-sync server
+#
+# Server endpoints (when running in server mode):
+# - Health check: GET http://localhost:%d/health
+# - Manual sync: POST http://localhost:%d/sync
+# - Metrics: GET http://localhost:%d/metrics
+# - Version: GET http://localhost:%d/version
+`,
+		step,
 		cfg.Server.Port,
 		cfg.Server.Port,
 		cfg.Server.Port,
@@ -77,7 +103,7 @@ server:
 	)
 
 	// Write to file
-	return os.WriteFile(path, []byte(yamlContent), 0644)
+	return writeSecretFile(path, []byte(yamlContent))
 }
 
 // formatGroups formats the groups list for YAML output
@@ -107,5 +133,14 @@ func SaveAsYAML(cfg *Config, path string) error {
 `
 	content := header + string(data)
 
-	return os.WriteFile(path, []byte(content), 0644)
+	return writeSecretFile(path, []byte(content))
+}
+
+// writeSecretFile writes a file holding credentials readable by the owner only, tightening the
+// mode of an existing file, which os.WriteFile leaves unchanged
+func writeSecretFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, SecretFileMode); err != nil {
+		return err
+	}
+	return os.Chmod(path, SecretFileMode)
 }
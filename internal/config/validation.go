@@ -2,10 +2,35 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/attributes"
+	"golang.org/x/text/language"
 )
 
+// objectIDPattern matches the object IDs that identify Azure AD groups
+var objectIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// schemaNamePattern matches the names Google Workspace allows for custom user schemas and their fields
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// reservedHeaders are set by the HTTP clients and may not be replaced through network.headers
+var reservedHeaders = map[string]bool{
+	"Authorization":   true,
+	"Accept":          true,
+	"Accept-Encoding": true,
+	"Content-Type":    true,
+	"Content-Length":  true,
+	"Host":            true,
+	"User-Agent":      true,
+}
+
 // ValidationError represents a configuration validation error
 type ValidationError struct {
 	Field   string
@@ -52,6 +77,13 @@ func (c *Config) ValidateWithOptions(opts ValidateOptions) error {
 		}
 	}
 
+	if c.App.Mode != "" && !contains(DeploymentModes, c.App.Mode) {
+		errors = append(errors, ValidationError{
+			Field:   "app.mode",
+			Message: fmt.Sprintf("must be one of: %v", DeploymentModes),
+		})
+	}
+
 	// Validate Google Workspace config
 	if c.GoogleWorkspace.Domain == "" {
 		errors = append(errors, ValidationError{
@@ -82,24 +114,160 @@ func (c *Config) ValidateWithOptions(opts ValidateOptions) error {
 		}
 	}
 
-	// Validate Beyond Identity config
-	if !opts.SkipAPIToken && c.BeyondIdentity.APIToken == "" {
+	for i, admin := range c.GoogleWorkspace.FallbackAdminEmails {
+		if !strings.Contains(admin, "@") {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("google_workspace.fallback_admin_emails[%d]", i),
+				Message: fmt.Sprintf("invalid email format: %s", admin),
+			})
+		}
+	}
+
+	adminDomains := make([]string, 0, len(c.GoogleWorkspace.DomainAdmins))
+	for domain := range c.GoogleWorkspace.DomainAdmins {
+		adminDomains = append(adminDomains, domain)
+	}
+	sort.Strings(adminDomains)
+
+	for _, domain := range adminDomains {
+		admins := c.GoogleWorkspace.DomainAdmins[domain]
+		field := fmt.Sprintf("google_workspace.domain_admins[%s]", domain)
+		if len(admins) == 0 {
+			errors = append(errors, ValidationError{
+				Field:   field,
+				Message: "at least one admin email must be specified",
+			})
+		}
+		for _, admin := range admins {
+			if !strings.Contains(admin, "@") {
+				errors = append(errors, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("invalid email format: %s", admin),
+				})
+			}
+		}
+	}
+
+	switch c.GoogleWorkspace.API {
+	case "", GWSAPIAdminSDK:
+	case GWSAPICloudIdentity:
+		if c.GoogleWorkspace.CustomerID == "" {
+			errors = append(errors, ValidationError{
+				Field:   "google_workspace.customer_id",
+				Message: "customer ID is required when api is cloud_identity",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "google_workspace.api",
+			Message: fmt.Sprintf("must be one of: %v", []string{GWSAPIAdminSDK, GWSAPICloudIdentity}),
+		})
+	}
+
+	// Validate the primary target: Beyond Identity, or another SCIM service in its place
+	switch c.Target.Type {
+	case "", TargetTypeBeyondIdentity:
+		if !opts.SkipAPIToken && c.BeyondIdentity.APIToken == "" {
+			errors = append(errors, ValidationError{
+				Field:   "beyond_identity.api_token",
+				Message: "API token is required",
+			})
+		}
+	case TargetTypeGenericSCIM:
+		errors = append(errors, validateGenericSCIMTarget("target", c.Target, opts)...)
+		if c.Reminders.Enabled {
+			errors = append(errors, ValidationError{
+				Field:   "reminders.enabled",
+				Message: "enrollment reminders need a Beyond Identity primary target",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "target.type",
+			Message: fmt.Sprintf("must be one of: %v", []string{TargetTypeBeyondIdentity, TargetTypeGenericSCIM}),
+		})
+	}
+
+	errors = append(errors, validateGroupPrefixes("beyond_identity.group_prefixes", c.BeyondIdentity.GroupPrefixes)...)
+
+	if c.BeyondIdentity.QuotaWarningFraction < 0 || c.BeyondIdentity.QuotaWarningFraction > 1 {
 		errors = append(errors, ValidationError{
-			Field:   "beyond_identity.api_token",
-			Message: "API token is required",
+			Field:   "beyond_identity.quota_warning_fraction",
+			Message: "quota warning fraction must be between 0 and 1",
 		})
 	}
 
 	// Validate Sync config
-	if len(c.Sync.Groups) == 0 {
+	if len(c.Sync.Groups) == 0 && len(c.Sync.OrgUnits) == 0 && !c.Sync.AllUsers {
 		errors = append(errors, ValidationError{
 			Field:   "sync.groups",
-			Message: "at least one group must be specified",
+			Message: "at least one group or org unit must be specified, or sync.all_users set",
+		})
+	}
+
+	for i, orgUnit := range c.Sync.OrgUnits {
+		if !strings.HasPrefix(orgUnit, "/") {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("sync.org_units[%d]", i),
+				Message: fmt.Sprintf("org unit %q must be a path starting with /, e.g. /Engineering", orgUnit),
+			})
+		}
+	}
+	readsDirectory := c.GoogleWorkspace.API != GWSAPICloudIdentity && c.Source.ReadsGoogleGroups()
+	if len(c.Sync.OrgUnits) > 0 && !readsDirectory {
+		errors = append(errors, ValidationError{
+			Field:   "sync.org_units",
+			Message: "org units are read with the Admin SDK; they need google_workspace.api: admin_sdk and the google_workspace source",
+		})
+	}
+	if c.Sync.AllUsers && !readsDirectory {
+		errors = append(errors, ValidationError{
+			Field:   "sync.all_users",
+			Message: "the domain's users are read with the Admin SDK; this needs google_workspace.api: admin_sdk and the google_workspace source",
 		})
 	}
+	if c.Sync.EnrollmentSchema.Enabled {
+		if c.GoogleWorkspace.API == GWSAPICloudIdentity {
+			errors = append(errors, ValidationError{
+				Field:   "sync.enrollment_schema.enabled",
+				Message: "custom user schema fields are written with the Admin SDK; this needs google_workspace.api: admin_sdk",
+			})
+		}
+		schema := c.Sync.EnrollmentSchema
+		for _, name := range []struct{ field, value string }{
+			{"name", schema.Name},
+			{"status_field", schema.StatusField},
+			{"checked_field", schema.CheckedField},
+		} {
+			if !schemaNamePattern.MatchString(name.value) {
+				errors = append(errors, ValidationError{
+					Field:   "sync.enrollment_schema." + name.field,
+					Message: fmt.Sprintf("%q must start with a letter and contain only letters, digits and underscores", name.value),
+				})
+			}
+		}
+	}
 
-	// Validate email formats
+	// Validate email formats; Azure AD and LDAP groups are synced under their object IDs and DNs instead
 	for i, group := range c.Sync.Groups {
+		if c.Source.Type == SourceTypeLDAP {
+			if !strings.Contains(group, "=") {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("sync.groups[%d]", i),
+					Message: fmt.Sprintf("invalid group DN: %s", group),
+				})
+			}
+			continue
+		}
+		if c.Source.Type == SourceTypeAzureAD {
+			if !objectIDPattern.MatchString(group) {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("sync.groups[%d]", i),
+					Message: fmt.Sprintf("invalid group object ID: %s", group),
+				})
+			}
+			continue
+		}
 		if !strings.Contains(group, "@") {
 			errors = append(errors, ValidationError{
 				Field:   fmt.Sprintf("sync.groups[%d]", i),
@@ -122,6 +290,229 @@ func (c *Config) ValidateWithOptions(opts ValidateOptions) error {
 		})
 	}
 
+	if c.Sync.AuthErrorThreshold < -1 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.auth_error_threshold",
+			Message: "auth error threshold must be positive, or -1 to disable",
+		})
+	}
+
+	switch c.Sync.ConflictPolicy {
+	case "", ConflictAdopt, ConflictSkip, ConflictOverwrite, ConflictError:
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "sync.conflict_policy",
+			Message: fmt.Sprintf("must be one of: %v", []string{ConflictAdopt, ConflictSkip, ConflictOverwrite, ConflictError}),
+		})
+	}
+
+	errors = append(errors, c.Sync.Filters.validate()...)
+
+	if c.Sync.Concurrency < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.concurrency",
+			Message: "concurrency must be at least 1",
+		})
+	}
+
+	if c.Sync.MembershipBatchSize < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.membership_batch_size",
+			Message: "membership batch size must be at least 1",
+		})
+	}
+
+	if c.Sync.RetryQueueHours < -1 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.retry_queue_hours",
+			Message: "retry queue hours must be positive, or -1 to disable",
+		})
+	}
+
+	if c.Sync.RecentRuns < -1 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.recent_runs",
+			Message: "recent runs must be positive, or -1 to disable",
+		})
+	}
+
+	if c.Sync.ErrorBudget < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.error_budget",
+			Message: "error budget must be non-negative",
+		})
+	}
+
+	if c.Sync.AutoSkipDays < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.auto_skip_days",
+			Message: "auto skip days must be non-negative",
+		})
+	}
+
+	if c.Sync.TimeoutSeconds < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.timeout_seconds",
+			Message: "timeout must be non-negative",
+		})
+	}
+
+	for _, op := range c.Sync.TestModeOperations {
+		if !contains(Operations, op) {
+			errors = append(errors, ValidationError{
+				Field:   "sync.test_mode_operations",
+				Message: fmt.Sprintf("unknown operation %q, must be one of: %v", op, Operations),
+			})
+		}
+	}
+
+	if _, err := attributes.New(c.Sync.AttributeMappings); err != nil {
+		errors = append(errors, ValidationError{
+			Field:   "sync.attribute_mappings",
+			Message: err.Error(),
+		})
+	}
+
+	if c.Sync.LockLeaseSeconds < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.lock_lease_seconds",
+			Message: "lock lease must be non-negative",
+		})
+	}
+
+	if c.Sync.SpreadOver != "" {
+		if window, err := time.ParseDuration(c.Sync.SpreadOver); err != nil || window < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "sync.spread_over",
+				Message: "spread over must be a non-negative duration, e.g. 30m",
+			})
+		}
+	}
+
+	if c.Sync.Incremental.Enabled {
+		if interval, err := time.ParseDuration(c.Sync.Incremental.FullSyncInterval); err != nil || interval <= 0 {
+			errors = append(errors, ValidationError{
+				Field:   "sync.incremental.full_sync_interval",
+				Message: "full sync interval must be a positive duration, e.g. 24h",
+			})
+		}
+		if lookback, err := time.ParseDuration(c.Sync.Incremental.Lookback); err != nil || lookback < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "sync.incremental.lookback",
+				Message: "lookback must be a non-negative duration, e.g. 1h",
+			})
+		}
+		if !c.Source.ReadsGoogleGroups() {
+			errors = append(errors, ValidationError{
+				Field:   "sync.incremental.enabled",
+				Message: fmt.Sprintf("incremental sync reads the Google Workspace audit log and cannot be used with a %s source", c.Source.Type),
+			})
+		}
+	}
+
+	if c.Sync.AdminGroups && !c.Source.ReadsGoogleGroups() {
+		errors = append(errors, ValidationError{
+			Field:   "sync.admin_groups",
+			Message: fmt.Sprintf("group owners and managers are only known for Google Workspace groups and cannot be synced from a %s source", c.Source.Type),
+		})
+	}
+
+	if c.GoogleWorkspace.PageRetryAttempts < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "google_workspace.page_retry_attempts",
+			Message: "page retry attempts cannot be negative",
+		})
+	}
+
+	if c.Sync.Retry.MaxAttempts < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "sync.retry.max_attempts",
+			Message: "retry attempts cannot be negative",
+		})
+	}
+	for _, setting := range []struct{ field, value string }{
+		{"sync.retry.base_delay", c.Sync.Retry.BaseDelay},
+		{"sync.retry.max_delay", c.Sync.Retry.MaxDelay},
+		{"sync.retry.budget", c.Sync.Retry.Budget},
+	} {
+		if setting.value == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(setting.value); err != nil || duration < 0 {
+			errors = append(errors, ValidationError{
+				Field:   setting.field,
+				Message: "must be a non-negative duration, e.g. 30s",
+			})
+		}
+	}
+
+	if c.BeyondIdentity.LookupCacheTTL != "" {
+		if ttl, err := time.ParseDuration(c.BeyondIdentity.LookupCacheTTL); err != nil || ttl < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "beyond_identity.lookup_cache_ttl",
+				Message: "lookup cache TTL must be a non-negative duration, e.g. 10m",
+			})
+		}
+	}
+
+	if c.GoogleWorkspace.SlowCallThreshold != "" {
+		if threshold, err := time.ParseDuration(c.GoogleWorkspace.SlowCallThreshold); err != nil || threshold < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "google_workspace.slow_call_threshold",
+				Message: "slow call threshold must be a non-negative duration, e.g. 5s",
+			})
+		}
+	}
+
+	if c.Sync.DisplayNameLocale != "" {
+		if _, err := language.Parse(c.Sync.DisplayNameLocale); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "sync.display_name_locale",
+				Message: fmt.Sprintf("display name locale must be a BCP 47 language tag such as tr or nl-BE: %v", err),
+			})
+		}
+	}
+
+	if c.Sync.MaxExpectedDuration != "" {
+		if limit, err := time.ParseDuration(c.Sync.MaxExpectedDuration); err != nil || limit <= 0 {
+			errors = append(errors, ValidationError{
+				Field:   "sync.max_expected_duration",
+				Message: "max expected duration must be a positive duration, e.g. 2h",
+			})
+		}
+	}
+	if c.Sync.CancelStuckRuns && c.Sync.MaxExpectedDuration == "" {
+		errors = append(errors, ValidationError{
+			Field:   "sync.cancel_stuck_runs",
+			Message: "cancel_stuck_runs requires max_expected_duration",
+		})
+	}
+
+	if c.Metrics.Statsd.Address != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Statsd.Address); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "metrics.statsd.address",
+				Message: "statsd address must be host:port, e.g. localhost:8125",
+			})
+		}
+	}
+
+	// Reject misspelled feature flags rather than silently using their defaults
+	featureNames := make([]string, 0, len(c.Features))
+	for name := range c.Features {
+		featureNames = append(featureNames, name)
+	}
+	sort.Strings(featureNames)
+
+	for _, name := range featureNames {
+		if _, ok := LookupFeature(name); !ok {
+			errors = append(errors, ValidationError{
+				Field:   "features." + name,
+				Message: "unknown feature flag",
+			})
+		}
+	}
+
 	// Validate server configuration
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		errors = append(errors, ValidationError{
@@ -137,6 +528,176 @@ func (c *Config) ValidateWithOptions(opts ValidateOptions) error {
 			Message: "schedule must be provided when schedule_enabled is true",
 		})
 	}
+	if c.Server.ScheduleEnabled && c.App.Mode == DeploymentModeOneShot {
+		errors = append(errors, ValidationError{
+			Field:   "server.schedule_enabled",
+			Message: "the server does not start with app.mode oneshot; schedule 'scim-sync run' with cron instead",
+		})
+	}
+
+	monitorSettings := []struct {
+		field string
+		value int
+	}{
+		{"server.monitor.interval_seconds", c.Server.Monitor.IntervalSeconds},
+		{"server.monitor.max_goroutines", c.Server.Monitor.MaxGoroutines},
+		{"server.monitor.max_heap_mb", c.Server.Monitor.MaxHeapMB},
+		{"server.monitor.max_open_fds", c.Server.Monitor.MaxOpenFDs},
+	}
+	for _, setting := range monitorSettings {
+		if setting.value < -1 {
+			errors = append(errors, ValidationError{
+				Field:   setting.field,
+				Message: "must be positive, or -1 to disable",
+			})
+		}
+	}
+
+	// Validate additional targets
+	targetNames := map[string]bool{DefaultTargetName: true}
+	for i, target := range c.Targets {
+		field := fmt.Sprintf("targets[%d]", i)
+		if target.Name == "" {
+			errors = append(errors, ValidationError{
+				Field:   field + ".name",
+				Message: "target name is required",
+			})
+		} else if targetNames[target.Name] {
+			errors = append(errors, ValidationError{
+				Field:   field + ".name",
+				Message: fmt.Sprintf("duplicate or reserved target name: %s", target.Name),
+			})
+		}
+		targetNames[target.Name] = true
+		errors = append(errors, validateGroupPrefixes(field+".group_prefixes", target.GroupPrefixes)...)
+
+		switch target.Type {
+		case "", TargetTypeBeyondIdentity:
+			if !opts.SkipAPIToken && target.APIToken == "" {
+				errors = append(errors, ValidationError{
+					Field:   field + ".api_token",
+					Message: "API token is required",
+				})
+			}
+			if target.LookupCacheTTL != "" {
+				if ttl, err := time.ParseDuration(target.LookupCacheTTL); err != nil || ttl < 0 {
+					errors = append(errors, ValidationError{
+						Field:   field + ".lookup_cache_ttl",
+						Message: "lookup cache TTL must be a non-negative duration, e.g. 10m",
+					})
+				}
+			}
+		case TargetTypeOkta:
+			errors = append(errors, validateOktaTarget(field, target, opts)...)
+		case TargetTypeGenericSCIM:
+			errors = append(errors, validateGenericSCIMTarget(field, target, opts)...)
+		default:
+			errors = append(errors, ValidationError{
+				Field:   field + ".type",
+				Message: fmt.Sprintf("must be one of: %v", []string{TargetTypeBeyondIdentity, TargetTypeOkta, TargetTypeGenericSCIM}),
+			})
+		}
+	}
+
+	mappedGroups := make([]string, 0, len(c.Sync.GroupTargets))
+	for group := range c.Sync.GroupTargets {
+		mappedGroups = append(mappedGroups, group)
+	}
+	sort.Strings(mappedGroups)
+
+	for _, group := range mappedGroups {
+		target := c.Sync.GroupTargets[group]
+		if !contains(c.Sync.Groups, group) {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("sync.group_targets[%s]", group),
+				Message: "group is not listed in sync.groups",
+			})
+		}
+		if !targetNames[target] {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("sync.group_targets[%s]", group),
+				Message: fmt.Sprintf("unknown target: %s", target),
+			})
+		}
+	}
+
+	if c.Network.MaxResponseBytes < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "network.max_response_bytes",
+			Message: "max response bytes must be non-negative",
+		})
+	}
+
+	if c.Network.MaxConnsPerHost < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "network.max_conns_per_host",
+			Message: "max connections per host must be non-negative",
+		})
+	}
+
+	for name := range c.Network.Headers {
+		if reservedHeaders[http.CanonicalHeaderKey(name)] {
+			errors = append(errors, ValidationError{
+				Field:   "network.headers",
+				Message: fmt.Sprintf("header %s is set by scim-sync and cannot be overridden", name),
+			})
+		}
+	}
+
+	if c.Network.CABundle != "" {
+		if _, err := os.Stat(c.Network.CABundle); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "network.ca_bundle",
+				Message: fmt.Sprintf("CA bundle not readable: %s", c.Network.CABundle),
+			})
+		}
+	}
+
+	switch c.Source.Type {
+	case "", SourceTypeGoogleWorkspace:
+	case SourceTypeCSV:
+		errors = append(errors, validateCSVSource(c.Source.CSV)...)
+	case SourceTypeAzureAD:
+		errors = append(errors, validateAzureADSource(c.Source.AzureAD)...)
+	case SourceTypeLDAP:
+		errors = append(errors, validateLDAPSource(c.Source.LDAP)...)
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "source.type",
+			Message: fmt.Sprintf("must be one of: %v", []string{SourceTypeGoogleWorkspace, SourceTypeCSV, SourceTypeAzureAD, SourceTypeLDAP}),
+		})
+	}
+
+	errors = append(errors, validateNotifications(c.Notifications)...)
+
+	if c.Reminders.Enabled {
+		errors = append(errors, validateReminders(c.Reminders)...)
+	}
+
+	switch c.Sync.OrphanedGroups.Cleanup {
+	case "", OrphanCleanupDelete, OrphanCleanupEmpty:
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "sync.orphaned_groups.cleanup",
+			Message: fmt.Sprintf("must be one of: %v", []string{OrphanCleanupDelete, OrphanCleanupEmpty}),
+		})
+	}
+
+	switch c.AccessReview.Format {
+	case "", AccessReviewFormatCSV, AccessReviewFormatSCIM:
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "access_review.format",
+			Message: fmt.Sprintf("must be one of: %v", []string{AccessReviewFormatCSV, AccessReviewFormatSCIM}),
+		})
+	}
+
+	if c.Telemetry.Enabled && c.Telemetry.Endpoint != "" && !strings.HasPrefix(c.Telemetry.Endpoint, "https://") {
+		errors = append(errors, ValidationError{
+			Field:   "telemetry.endpoint",
+			Message: "endpoint must use https",
+		})
+	}
 
 	if len(errors) > 0 {
 		return errors
@@ -145,6 +706,387 @@ func (c *Config) ValidateWithOptions(opts ValidateOptions) error {
 	return nil
 }
 
+// validateNotifications validates the notification mode and channels
+func validateNotifications(n NotificationsConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	switch n.Mode {
+	case "", NotificationModeImmediate:
+	case NotificationModeDigest:
+		if n.Digest.Schedule == "" {
+			errors = append(errors, ValidationError{
+				Field:   "notifications.digest.schedule",
+				Message: "digest schedule is required when mode is digest",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "notifications.mode",
+			Message: fmt.Sprintf("must be one of: %v", []string{NotificationModeImmediate, NotificationModeDigest}),
+		})
+	}
+
+	webhooks := []struct {
+		field string
+		url   string
+	}{
+		{"notifications.slack.webhook_url", n.Slack.WebhookURL},
+		{"notifications.teams.webhook_url", n.Teams.WebhookURL},
+		{"notifications.google_chat.webhook_url", n.GoogleChat.WebhookURL},
+	}
+	for _, webhook := range webhooks {
+		if webhook.url != "" && !strings.HasPrefix(webhook.url, "https://") {
+			errors = append(errors, ValidationError{
+				Field:   webhook.field,
+				Message: "webhook URL must use https",
+			})
+		}
+	}
+
+	if n.MembershipDiffs.MaxEntries < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "notifications.membership_diffs.max_entries",
+			Message: "max entries must be non-negative",
+		})
+	}
+
+	if n.PagerDuty.FailureThreshold < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "notifications.pagerduty.failure_threshold",
+			Message: "failure threshold must be non-negative",
+		})
+	}
+
+	if n.Opsgenie.FailureThreshold < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "notifications.opsgenie.failure_threshold",
+			Message: "failure threshold must be non-negative",
+		})
+	}
+
+	if n.Opsgenie.APIURL != "" && !strings.HasPrefix(n.Opsgenie.APIURL, "https://") {
+		errors = append(errors, ValidationError{
+			Field:   "notifications.opsgenie.api_url",
+			Message: "API URL must use https",
+		})
+	}
+
+	return errors
+}
+
+// validateReminders validates the reminder email settings
+func validateReminders(r RemindersConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if !strings.Contains(r.From, "@") {
+		errors = append(errors, ValidationError{
+			Field:   "reminders.from",
+			Message: "a sender email address is required",
+		})
+	}
+
+	switch r.Transport {
+	case "", ReminderTransportGmail:
+	case ReminderTransportSMTP:
+		if r.SMTP.Host == "" {
+			errors = append(errors, ValidationError{
+				Field:   "reminders.smtp.host",
+				Message: "SMTP host is required for the smtp transport",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "reminders.transport",
+			Message: fmt.Sprintf("must be one of: %v", []string{ReminderTransportGmail, ReminderTransportSMTP}),
+		})
+	}
+
+	if r.GracePeriodDays < 0 || r.IntervalDays < 0 || r.MaxReminders < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "reminders",
+			Message: "grace_period_days, interval_days and max_reminders must be non-negative",
+		})
+	}
+
+	if r.TemplatePath != "" {
+		if _, err := os.Stat(r.TemplatePath); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "reminders.template_path",
+				Message: fmt.Sprintf("template file not found: %s", r.TemplatePath),
+			})
+		}
+	}
+
+	return errors
+}
+
+// validateCSVSource validates the settings of a CSV membership source
+func validateCSVSource(csv CSVSourceConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if csv.Delimiter != "" && len([]rune(csv.Delimiter)) != 1 {
+		errors = append(errors, ValidationError{
+			Field:   "source.csv.delimiter",
+			Message: "delimiter must be a single character",
+		})
+	}
+
+	if csv.SFTP == nil {
+		if csv.Path == "" {
+			errors = append(errors, ValidationError{
+				Field:   "source.csv.path",
+				Message: "path is required when sftp is not configured",
+			})
+		}
+		return errors
+	}
+
+	sftp := csv.SFTP
+	required := []struct {
+		field string
+		value string
+	}{
+		{"host", sftp.Host},
+		{"username", sftp.Username},
+		{"remote_path", sftp.RemotePath},
+		{"known_hosts_path", sftp.KnownHostsPath},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			errors = append(errors, ValidationError{
+				Field:   "source.csv.sftp." + r.field,
+				Message: r.field + " is required",
+			})
+		}
+	}
+
+	if sftp.PrivateKeyPath == "" && sftp.Password == "" {
+		errors = append(errors, ValidationError{
+			Field:   "source.csv.sftp.private_key_path",
+			Message: "private_key_path or password is required",
+		})
+	}
+
+	if sftp.Port < 0 || sftp.Port > 65535 {
+		errors = append(errors, ValidationError{
+			Field:   "source.csv.sftp.port",
+			Message: "port must be between 1 and 65535",
+		})
+	}
+
+	return errors
+}
+
+// validateAzureADSource validates the settings of an Azure AD membership source
+func validateAzureADSource(azure AzureADSourceConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	required := []struct {
+		field string
+		value string
+	}{
+		{"tenant_id", azure.TenantID},
+		{"client_id", azure.ClientID},
+		{"client_secret", azure.ClientSecret},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			errors = append(errors, ValidationError{
+				Field:   "source.azure_ad." + r.field,
+				Message: r.field + " is required",
+			})
+		}
+	}
+
+	for i, group := range azure.Groups {
+		if !objectIDPattern.MatchString(group) {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("source.azure_ad.groups[%d]", i),
+				Message: fmt.Sprintf("invalid group object ID: %s", group),
+			})
+		}
+	}
+
+	for _, endpoint := range []struct {
+		field string
+		url   string
+	}{
+		{"graph_url", azure.GraphURL},
+		{"login_url", azure.LoginURL},
+	} {
+		if endpoint.url != "" && !strings.HasPrefix(endpoint.url, "https://") {
+			errors = append(errors, ValidationError{
+				Field:   "source.azure_ad." + endpoint.field,
+				Message: endpoint.field + " must use https",
+			})
+		}
+	}
+
+	return errors
+}
+
+// validateLDAPSource validates the settings of an LDAP membership source
+func validateLDAPSource(ldap LDAPSourceConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if !strings.HasPrefix(strings.ToLower(ldap.URL), "ldaps://") {
+		errors = append(errors, ValidationError{
+			Field:   "source.ldap.url",
+			Message: "url is required and must use ldaps://",
+		})
+	}
+
+	required := []struct {
+		field string
+		value string
+	}{
+		{"bind_dn", ldap.BindDN},
+		{"bind_password", ldap.BindPassword},
+		{"base_dn", ldap.BaseDN},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			errors = append(errors, ValidationError{
+				Field:   "source.ldap." + r.field,
+				Message: r.field + " is required",
+			})
+		}
+	}
+
+	for i, group := range ldap.Groups {
+		if !strings.Contains(group, "=") {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("source.ldap.groups[%d]", i),
+				Message: fmt.Sprintf("invalid group DN: %s", group),
+			})
+		}
+	}
+
+	if ldap.UserFilter != "" && !(strings.HasPrefix(ldap.UserFilter, "(") && strings.HasSuffix(ldap.UserFilter, ")")) {
+		errors = append(errors, ValidationError{
+			Field:   "source.ldap.user_filter",
+			Message: "user filter must be enclosed in parentheses, e.g. (objectClass=person)",
+		})
+	}
+
+	if ldap.CABundle != "" {
+		if _, err := os.Stat(ldap.CABundle); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "source.ldap.ca_bundle",
+				Message: fmt.Sprintf("CA bundle not readable: %s", ldap.CABundle),
+			})
+		}
+	}
+
+	return errors
+}
+
+// validateOktaTarget validates the settings of an Okta target
+func validateOktaTarget(field string, target TargetConfig, opts ValidateOptions) ValidationErrors {
+	var errors ValidationErrors
+
+	if target.OrgURL == "" {
+		errors = append(errors, ValidationError{
+			Field:   field + ".org_url",
+			Message: "Okta org URL is required",
+		})
+	}
+
+	switch target.Auth {
+	case "", OktaAuthAPIToken:
+		if !opts.SkipAPIToken && target.APIToken == "" {
+			errors = append(errors, ValidationError{
+				Field:   field + ".api_token",
+				Message: "API token is required",
+			})
+		}
+	case OktaAuthOAuth:
+		if target.ClientID == "" || target.ClientSecret == "" {
+			errors = append(errors, ValidationError{
+				Field:   field + ".client_id",
+				Message: "client_id and client_secret are required for oauth",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   field + ".auth",
+			Message: fmt.Sprintf("must be one of: %v", []string{OktaAuthAPIToken, OktaAuthOAuth}),
+		})
+	}
+
+	return errors
+}
+
+// validateGenericSCIMTarget validates the settings of a generic SCIM 2.0 target
+func validateGenericSCIMTarget(field string, target TargetConfig, opts ValidateOptions) ValidationErrors {
+	var errors ValidationErrors
+
+	if !strings.HasPrefix(target.SCIMBaseURL, "https://") {
+		errors = append(errors, ValidationError{
+			Field:   field + ".scim_base_url",
+			Message: "SCIM base URL is required and must use https",
+		})
+	}
+
+	switch target.Auth {
+	case "", SCIMAuthBearer:
+		if !opts.SkipAPIToken && target.APIToken == "" {
+			errors = append(errors, ValidationError{
+				Field:   field + ".api_token",
+				Message: "API token is required",
+			})
+		}
+	case SCIMAuthBasic:
+		if target.Username == "" || target.Password == "" {
+			errors = append(errors, ValidationError{
+				Field:   field + ".username",
+				Message: "username and password are required for basic",
+			})
+		}
+	case SCIMAuthOAuth:
+		if target.ClientID == "" || target.ClientSecret == "" || target.TokenURL == "" {
+			errors = append(errors, ValidationError{
+				Field:   field + ".client_id",
+				Message: "client_id, client_secret and token_url are required for oauth",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   field + ".auth",
+			Message: fmt.Sprintf("must be one of: %v", []string{SCIMAuthBearer, SCIMAuthBasic, SCIMAuthOAuth}),
+		})
+	}
+
+	switch target.MembershipUpdates {
+	case "", MembershipUpdatesPatch, MembershipUpdatesPut:
+	default:
+		errors = append(errors, ValidationError{
+			Field:   field + ".membership_updates",
+			Message: fmt.Sprintf("must be one of: %v", []string{MembershipUpdatesPatch, MembershipUpdatesPut}),
+		})
+	}
+
+	switch target.MemberRemoval {
+	case "", MemberRemovalPathFilter, MemberRemovalValue:
+	default:
+		errors = append(errors, ValidationError{
+			Field:   field + ".member_removal",
+			Message: fmt.Sprintf("must be one of: %v", []string{MemberRemovalPathFilter, MemberRemovalValue}),
+		})
+	}
+
+	if target.LookupCacheTTL != "" {
+		if ttl, err := time.ParseDuration(target.LookupCacheTTL); err != nil || ttl < 0 {
+			errors = append(errors, ValidationError{
+				Field:   field + ".lookup_cache_ttl",
+				Message: "lookup cache TTL must be a non-negative duration, e.g. 10m",
+			})
+		}
+	}
+
+	return errors
+}
+
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -154,3 +1096,54 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// validate checks that the filter patterns compile and org units are paths
+func (f UserFiltersConfig) validate() []ValidationError {
+	var errors []ValidationError
+	for _, list := range []struct {
+		field    string
+		patterns []string
+	}{
+		{"sync.filters.exclude_patterns", f.ExcludePatterns},
+		{"sync.filters.include_patterns", f.IncludePatterns},
+	} {
+		for _, pattern := range list.patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errors = append(errors, ValidationError{
+					Field:   list.field,
+					Message: fmt.Sprintf("invalid regular expression %q: %v", pattern, err),
+				})
+			}
+		}
+	}
+	for _, orgUnit := range f.IncludeOrgUnits {
+		if !strings.HasPrefix(orgUnit, "/") {
+			errors = append(errors, ValidationError{
+				Field:   "sync.filters.include_org_units",
+				Message: fmt.Sprintf("org unit %q must be a path starting with /, e.g. /Employees", orgUnit),
+			})
+		}
+	}
+	for _, domain := range f.ExcludeDomains {
+		if domain == "" || strings.Contains(domain, "@") {
+			errors = append(errors, ValidationError{
+				Field:   "sync.filters.exclude_domains",
+				Message: fmt.Sprintf("%q is not a domain, e.g. contractors.example.com", domain),
+			})
+		}
+	}
+	return errors
+}
+
+// validateGroupPrefixes rejects empty group prefixes, which would match groups the sync did not create
+func validateGroupPrefixes(field string, prefixes []string) []ValidationError {
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			return []ValidationError{{
+				Field:   field,
+				Message: "group prefixes must not be empty",
+			}}
+		}
+	}
+	return nil
+}
@@ -74,6 +74,58 @@ func TestValidate(t *testing.T) {
 			expectError: true,
 			errorFields: []string{"app.log_level"},
 		},
+		{
+			name: "invalid deployment mode",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+					Mode:     "daemon",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			expectError: true,
+			errorFields: []string{"app.mode"},
+		},
+		{
+			name: "scheduling with one-shot mode",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+					Mode:     DeploymentModeOneShot,
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Server: ServerConfig{
+					Port:            8080,
+					ScheduleEnabled: true,
+					Schedule:        "0 */6 * * *",
+				},
+			},
+			expectError: true,
+			errorFields: []string{"server.schedule_enabled"},
+		},
 		{
 			name: "invalid email format in groups",
 			config: &Config{
@@ -95,6 +147,908 @@ func TestValidate(t *testing.T) {
 			expectError: true,
 			errorFields: []string{"sync.groups[0]"},
 		},
+		{
+			name: "invalid targets",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Targets: []TargetConfig{
+					{Name: "default", APIToken: "token"},
+					{Name: "eu"},
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+					GroupTargets: map[string]string{
+						"group1@test.com": "apac",
+						"other@test.com":  "eu",
+					},
+				},
+			},
+			expectError: true,
+			errorFields: []string{
+				"targets[0].name",
+				"targets[1].api_token",
+				"sync.group_targets[group1@test.com]",
+				"sync.group_targets[other@test.com]",
+			},
+		},
+		{
+			name: "invalid generic scim targets",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Targets: []TargetConfig{
+					{Name: "hr", Type: TargetTypeGenericSCIM, SCIMBaseURL: "http://scim.example.com", Auth: SCIMAuthBasic, Username: "sync"},
+					{Name: "apps", Type: TargetTypeGenericSCIM, SCIMBaseURL: "https://scim.example.com", APIToken: "token", MembershipUpdates: "post", MemberRemoval: "filter"},
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{
+				"targets[0].scim_base_url",
+				"targets[0].username",
+				"targets[1].membership_updates",
+				"targets[1].member_removal",
+			},
+		},
+		{
+			name: "generic scim primary target without beyond identity",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				Target: TargetConfig{Type: TargetTypeGenericSCIM, SCIMBaseURL: "https://scim.example.com", APIToken: "token"},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid primary targets",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				Target:    TargetConfig{Type: TargetTypeGenericSCIM, Auth: SCIMAuthOAuth, ClientID: "id"},
+				Reminders: RemindersConfig{Enabled: true},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{
+				"target.scim_base_url",
+				"target.client_id",
+				"reminders.enabled",
+			},
+		},
+		{
+			name: "unsupported primary target type",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				Target: TargetConfig{Type: TargetTypeOkta},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"target.type"},
+		},
+		{
+			name: "invalid csv source",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:      []string{"group1@test.com"},
+					AdminGroups: true,
+				},
+				Source: SourceConfig{
+					Type: SourceTypeCSV,
+					CSV: CSVSourceConfig{
+						Delimiter: "||",
+						SFTP: &SFTPConfig{
+							Host:     "sftp.example.com",
+							Username: "hr-export",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorFields: []string{
+				"source.csv.delimiter",
+				"source.csv.sftp.remote_path",
+				"source.csv.sftp.known_hosts_path",
+				"source.csv.sftp.private_key_path",
+				"sync.admin_groups",
+			},
+		},
+		{
+			name: "invalid azure ad source",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:      []string{"group1@test.com"},
+					Incremental: IncrementalConfig{Enabled: true, FullSyncInterval: "24h", Lookback: "1h"},
+				},
+				Source: SourceConfig{
+					Type: SourceTypeAzureAD,
+					AzureAD: AzureADSourceConfig{
+						TenantID: "contoso.onmicrosoft.com",
+						ClientID: "6f1c2a0e-8d4b-4c3a-9f1e-2b7d5a9c0e41",
+						Groups:   []string{"engineering"},
+						GraphURL: "http://graph.microsoft.com",
+					},
+				},
+			},
+			expectError: true,
+			errorFields: []string{
+				"sync.groups[0]",
+				"sync.incremental.enabled",
+				"source.azure_ad.client_secret",
+				"source.azure_ad.groups[0]",
+				"source.azure_ad.graph_url",
+			},
+		},
+		{
+			name: "invalid ldap source",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:      []string{"group1@test.com"},
+					AdminGroups: true,
+				},
+				Source: SourceConfig{
+					Type: SourceTypeLDAP,
+					LDAP: LDAPSourceConfig{
+						URL:        "ldap://dc1.corp.example.com",
+						BindDN:     "CN=svc-sync,OU=Service Accounts,DC=corp,DC=example,DC=com",
+						Groups:     []string{"engineering"},
+						UserFilter: "objectClass=person",
+						CABundle:   "/nonexistent/ca.pem",
+					},
+				},
+			},
+			expectError: true,
+			errorFields: []string{
+				"sync.groups[0]",
+				"sync.admin_groups",
+				"source.ldap.url",
+				"source.ldap.bind_password",
+				"source.ldap.base_dn",
+				"source.ldap.groups[0]",
+				"source.ldap.user_filter",
+				"source.ldap.ca_bundle",
+			},
+		},
+		{
+			name: "cloud identity without customer ID",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+					API:                   GWSAPICloudIdentity,
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"google_workspace.customer_id"},
+		},
+		{
+			name: "invalid enrollment schema",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+					API:                   GWSAPICloudIdentity,
+					CustomerID:            "C01abcd23",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+					EnrollmentSchema: UserSchemaConfig{
+						Enabled:     true,
+						Name:        "Beyond-Identity",
+						StatusField: "enrollmentStatus",
+					},
+				},
+			},
+			expectError: true,
+			errorFields: []string{
+				"sync.enrollment_schema.enabled",
+				"sync.enrollment_schema.name",
+				"sync.enrollment_schema.checked_field",
+			},
+		},
+		{
+			name: "invalid error limits",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:             []string{"group1@test.com"},
+					AuthErrorThreshold: -5,
+					ErrorBudget:        -1,
+					RetryQueueHours:    -2,
+					Concurrency:        -1,
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.auth_error_threshold", "sync.concurrency", "sync.retry_queue_hours", "sync.error_budget"},
+		},
+		{
+			name: "invalid spread over",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:     []string{"group1@test.com"},
+					SpreadOver: "30 minutes",
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.spread_over"},
+		},
+		{
+			name: "invalid incremental full sync interval",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+					Incremental: IncrementalConfig{
+						Enabled:          true,
+						FullSyncInterval: "daily",
+						Lookback:         "1h",
+					},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.incremental.full_sync_interval"},
+		},
+		{
+			name: "quota warning fraction out of range",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken:             "test-token",
+					QuotaWarningFraction: 80,
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"beyond_identity.quota_warning_fraction"},
+		},
+		{
+			name: "negative page retry attempts",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+					PageRetryAttempts:     -1,
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"google_workspace.page_retry_attempts"},
+		},
+		{
+			name: "invalid slow call threshold",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+					SlowCallThreshold:     "slow",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"google_workspace.slow_call_threshold"},
+		},
+		{
+			name: "invalid lookup cache TTL",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken:       "test-token",
+					LookupCacheTTL: "-5m",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Targets: []TargetConfig{{Name: "eu", APIToken: "eu-token", LookupCacheTTL: "often"}},
+			},
+			expectError: true,
+			errorFields: []string{"beyond_identity.lookup_cache_ttl", "targets[0].lookup_cache_ttl"},
+		},
+		{
+			name: "invalid retry settings",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+					Retry:  RetryConfig{MaxAttempts: -1, BaseDelay: "soon", Budget: "-1m"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.retry.max_attempts", "sync.retry.base_delay", "sync.retry.budget"},
+		},
+		{
+			name: "invalid display name locale",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:            []string{"group1@test.com"},
+					DisplayNameLocale: "turkish_",
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.display_name_locale"},
+		},
+		{
+			name: "invalid watchdog",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:              []string{"group1@test.com"},
+					MaxExpectedDuration: "0s",
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.max_expected_duration"},
+		},
+		{
+			name: "negative sync timeout",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:         []string{"group1@test.com"},
+					TimeoutSeconds: -1,
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.timeout_seconds"},
+		},
+		{
+			name: "unknown test mode operation",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:             []string{"group1@test.com"},
+					TestModeOperations: []string{OperationDeactivate, "delete"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.test_mode_operations"},
+		},
+		{
+			name: "unknown attribute mapping field",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:            []string{"group1@test.com"},
+					AttributeMappings: map[string]string{"displayName": "nickname"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.attribute_mappings"},
+		},
+		{
+			name: "cancel stuck runs without watchdog",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:          []string{"group1@test.com"},
+					CancelStuckRuns: true,
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.cancel_stuck_runs"},
+		},
+		{
+			name: "invalid statsd address",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Metrics: MetricsConfig{
+					Statsd: StatsdConfig{Address: "localhost"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"metrics.statsd.address"},
+		},
+		{
+			name: "invalid notifications",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Notifications: NotificationsConfig{
+					Mode:      "hourly",
+					Slack:     WebhookConfig{WebhookURL: "http://hooks.slack.com/services/x"},
+					PagerDuty: PagerDutyConfig{RoutingKey: "key", FailureThreshold: -1},
+					Opsgenie:  OpsgenieConfig{APIKey: "key", APIURL: "http://api.opsgenie.com"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{
+				"notifications.mode",
+				"notifications.slack.webhook_url",
+				"notifications.pagerduty.failure_threshold",
+				"notifications.opsgenie.api_url",
+			},
+		},
+		{
+			name: "telemetry endpoint without https",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Telemetry: TelemetryConfig{Enabled: true, Endpoint: "http://telemetry.example.com"},
+			},
+			expectError: true,
+			errorFields: []string{"telemetry.endpoint"},
+		},
+		{
+			name: "invalid reminders",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Reminders: RemindersConfig{
+					Enabled:      true,
+					Transport:    ReminderTransportSMTP,
+					TemplatePath: "/nonexistent/reminder.tmpl",
+				},
+			},
+			expectError: true,
+			errorFields: []string{"reminders.from", "reminders.smtp.host", "reminders.template_path"},
+		},
+		{
+			name: "invalid access review format",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				AccessReview: AccessReviewConfig{
+					Format: "xlsx",
+				},
+			},
+			expectError: true,
+			errorFields: []string{"access_review.format"},
+		},
+		{
+			name: "reserved network header",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Network: NetworkConfig{
+					Headers: map[string]string{"authorization": "Bearer other", "X-Correlation-ID": "acme-scim"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"network.headers"},
+		},
+		{
+			name: "negative max connections per host",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+				},
+				Network: NetworkConfig{
+					MaxConnsPerHost: -1,
+				},
+			},
+			expectError: true,
+			errorFields: []string{"network.max_conns_per_host"},
+		},
+		{
+			name: "negative membership batch size",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:              []string{"group1@test.com"},
+					MembershipBatchSize: -1,
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.membership_batch_size"},
+		},
+		{
+			name: "invalid recent runs",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:     []string{"group1@test.com"},
+					RecentRuns: -2,
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.recent_runs"},
+		},
+		{
+			name: "invalid user filters",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups: []string{"group1@test.com"},
+					Filters: UserFiltersConfig{
+						ExcludePatterns: []string{"("},
+						IncludeOrgUnits: []string{"Employees"},
+					},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.filters.exclude_patterns", "sync.filters.include_org_units"},
+		},
+		{
+			name: "org units instead of groups",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					OrgUnits: []string{"/Engineering", "Sales"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.org_units[1]"},
+		},
+		{
+			name: "unknown orphaned group cleanup",
+			config: &Config{
+				App: AppConfig{
+					LogLevel: "info",
+				},
+				GoogleWorkspace: GoogleWorkspaceConfig{
+					Domain:                "test.com",
+					SuperAdminEmail:       "admin@test.com",
+					ServiceAccountKeyPath: "/tmp/test.json",
+				},
+				BeyondIdentity: BeyondIdentityConfig{
+					APIToken: "test-token",
+				},
+				Sync: SyncConfig{
+					Groups:         []string{"group1@test.com"},
+					OrphanedGroups: OrphanedGroupsConfig{Cleanup: "archive"},
+				},
+			},
+			expectError: true,
+			errorFields: []string{"sync.orphaned_groups.cleanup"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,89 @@
+package csvsource
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sftpDialTimeout bounds how long connecting to the SFTP server may take
+const sftpDialTimeout = 30 * time.Second
+
+// FetchSFTP downloads the export at cfg.RemotePath, verifying the server against known_hosts
+func FetchSFTP(cfg config.SFTPConfig, maxBytes int64) ([]byte, error) {
+	hostKeyCallback, err := knownhosts.New(cfg.KnownHostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known hosts: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if cfg.PrivateKeyPath != "" {
+		key, err := os.ReadFile(cfg.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SFTP private key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SFTP private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password))
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 22
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            cfg.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sftpDialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SFTP server %s: %w", addr, err)
+	}
+	defer func() { _ = client.Close() }()
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start SFTP subsystem: %w", err)
+	}
+	defer func() { _ = sftpClient.Close() }()
+
+	data, err := sftpReadFile(sftpClient, cfg.RemotePath, maxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", cfg.RemotePath, err)
+	}
+	return data, nil
+}
+
+// sftpReadFile reads path, failing once it exceeds maxBytes
+func sftpReadFile(client *sftp.Client, path string, maxBytes int64) ([]byte, error) {
+	file, err := client.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("remote file exceeds maximum size of %d bytes", maxBytes)
+	}
+	return data, nil
+}
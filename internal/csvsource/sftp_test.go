@@ -0,0 +1,184 @@
+package csvsource
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// startSFTPServer runs an SSH server on localhost that accepts password "secret" and serves
+// the local file system over the sftp subsystem; it returns the server's address and host key
+func startSFTPServer(t *testing.T) (string, ssh.PublicKey) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("Failed to create host key signer: %v", err)
+	}
+
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "secret" {
+				return nil, os.ErrPermission
+			}
+			return nil, nil
+		},
+	}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, serverConfig)
+		}
+	}()
+
+	return listener.Addr().String(), signer.PublicKey()
+}
+
+func serveSFTP(conn net.Conn, serverConfig *ssh.ServerConfig) {
+	defer func() { _ = conn.Close() }()
+
+	_, channels, requests, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range channelRequests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				server, err := sftp.NewServer(channel, sftp.ReadOnly())
+				if err != nil {
+					return
+				}
+				_ = server.Serve()
+				_ = channel.Close()
+			}
+		}()
+	}
+}
+
+// sftpTestConfig writes a known_hosts file trusting hostKey and returns a configuration
+// that downloads remotePath from the server at addr
+func sftpTestConfig(t *testing.T, addr string, hostKey ssh.PublicKey, remotePath string) config.SFTPConfig {
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey)
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write known_hosts: %v", err)
+	}
+
+	return config.SFTPConfig{
+		Host:           host,
+		Port:           port,
+		Username:       "sync",
+		Password:       "secret",
+		KnownHostsPath: knownHosts,
+		RemotePath:     remotePath,
+	}
+}
+
+func TestFetchSFTP(t *testing.T) {
+	addr, hostKey := startSFTPServer(t)
+
+	// Larger than one SFTP read so multiple reads are exercised
+	content := "group,email\n" + strings.Repeat("eng@example.com,alice@example.com\n", 2000)
+	remotePath := filepath.Join(t.TempDir(), "members.csv")
+	if err := os.WriteFile(remotePath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+
+	data, err := FetchSFTP(sftpTestConfig(t, addr, hostKey, remotePath), DefaultMaxFileBytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("Expected %d bytes, got %d", len(content), len(data))
+	}
+}
+
+func TestFetchSFTP_Errors(t *testing.T) {
+	addr, hostKey := startSFTPServer(t)
+
+	remotePath := filepath.Join(t.TempDir(), "members.csv")
+	if err := os.WriteFile(remotePath, []byte(strings.Repeat("x", 1000)), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	unknownHostKey, err := ssh.NewPublicKey(otherKey)
+	if err != nil {
+		t.Fatalf("Failed to create public key: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.SFTPConfig
+		maxBytes int64
+		want     string
+	}{
+		{
+			name:     "missing file",
+			cfg:      sftpTestConfig(t, addr, hostKey, remotePath+".missing"),
+			maxBytes: DefaultMaxFileBytes,
+			want:     "not exist",
+		},
+		{
+			name:     "file too large",
+			cfg:      sftpTestConfig(t, addr, hostKey, remotePath),
+			maxBytes: 100,
+			want:     "maximum size",
+		},
+		{
+			name:     "unknown host key",
+			cfg:      sftpTestConfig(t, addr, unknownHostKey, remotePath),
+			maxBytes: DefaultMaxFileBytes,
+			want:     "key mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FetchSFTP(tt.cfg, tt.maxBytes)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package csvsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// DefaultMaxFileBytes is the largest export accepted when no limit is configured
+const DefaultMaxFileBytes int64 = 32 << 20 // 32 MiB
+
+// ErrGroupNotFound is returned when a configured group does not appear in the export
+var ErrGroupNotFound = errors.New("group not found in CSV export")
+
+// Source serves group membership from a CSV export delivered locally or over SFTP
+type Source struct {
+	cfg      config.CSVSourceConfig
+	maxBytes int64
+	fetch    func() ([]byte, error)
+
+	mu     sync.RWMutex
+	groups map[string]*groupData
+}
+
+// groupData holds the parsed membership of a single group
+type groupData struct {
+	group   *gws.Group
+	members []*gws.GroupMember
+}
+
+// NewSource creates a CSV source from configuration; the export is read on the first Refresh
+func NewSource(cfg config.CSVSourceConfig, maxBytes int64) *Source {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxFileBytes
+	}
+
+	s := &Source{
+		cfg:      cfg,
+		maxBytes: maxBytes,
+	}
+
+	if cfg.SFTP != nil {
+		s.fetch = func() ([]byte, error) {
+			return FetchSFTP(*cfg.SFTP, maxBytes)
+		}
+	} else {
+		s.fetch = s.readLocal
+	}
+
+	return s
+}
+
+// Refresh re-reads the export; the previous membership is kept if the new file fails validation
+func (s *Source) Refresh(ctx context.Context) error {
+	data, err := s.fetch()
+	if err != nil {
+		return fmt.Errorf("failed to fetch CSV export: %w", err)
+	}
+
+	groups, err := parse(bytes.NewReader(data), s.cfg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.groups = groups
+	s.mu.Unlock()
+
+	return nil
+}
+
+// GetGroup returns the group with the given email from the export
+func (s *Source) GetGroup(ctx context.Context, email string) (*gws.Group, error) {
+	data, err := s.lookup(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return data.group, nil
+}
+
+// GetGroupMembers returns the members of the group with the given email from the export
+func (s *Source) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	data, err := s.lookup(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return data.members, nil
+}
+
+// lookup finds a group, loading the export if it has not been read yet
+func (s *Source) lookup(ctx context.Context, email string) (*groupData, error) {
+	s.mu.RLock()
+	loaded := s.groups != nil
+	s.mu.RUnlock()
+
+	if !loaded {
+		if err := s.Refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.groups[strings.ToLower(email)]
+	if !ok {
+		// A missing group is an error rather than an empty group so a truncated
+		// export never removes everyone from the target
+		return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, email)
+	}
+	return data, nil
+}
+
+// readLocal reads the export from the configured local path
+func (s *Source) readLocal() ([]byte, error) {
+	file, err := os.Open(s.cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(file, s.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, fmt.Errorf("file %s exceeds maximum size of %d bytes", s.cfg.Path, s.maxBytes)
+	}
+	return data, nil
+}
+
+// parse reads an export, mapping header names to fields and validating every row.
+// Any invalid row fails the whole file so a malformed export is never partially applied.
+func parse(r io.Reader, cfg config.CSVSourceConfig) (map[string]*groupData, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	if cfg.Delimiter != "" {
+		reader.Comma = []rune(cfg.Delimiter)[0]
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV export is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := cfg.Columns
+	if columns.Group == "" {
+		columns.Group = "group"
+	}
+	if columns.Email == "" {
+		columns.Email = "email"
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[normalizeHeader(name)] = i
+	}
+
+	// Optional columns are left unmapped (-1) when not configured
+	column := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, ok := index[normalizeHeader(name)]
+		if !ok {
+			return -1, fmt.Errorf("CSV header is missing column %q", name)
+		}
+		return i, nil
+	}
+
+	groupCol, err := column(columns.Group)
+	if err != nil {
+		return nil, err
+	}
+	emailCol, err := column(columns.Email)
+	if err != nil {
+		return nil, err
+	}
+	groupNameCol, err := column(columns.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	statusCol, err := column(columns.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*groupData)
+	seen := make(map[string]bool)
+	var rowErrors []string
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		groupEmail := strings.ToLower(strings.TrimSpace(record[groupCol]))
+		memberEmail := strings.ToLower(strings.TrimSpace(record[emailCol]))
+
+		if !isEmail(groupEmail) {
+			rowErrors = append(rowErrors, fmt.Sprintf("line %d: invalid group email %q", line, record[groupCol]))
+			continue
+		}
+		if !isEmail(memberEmail) {
+			rowErrors = append(rowErrors, fmt.Sprintf("line %d: invalid member email %q", line, record[emailCol]))
+			continue
+		}
+
+		data, ok := groups[groupEmail]
+		if !ok {
+			data = &groupData{group: &gws.Group{
+				ID:    groupEmail,
+				Email: groupEmail,
+				Name:  groupEmail,
+			}}
+			groups[groupEmail] = data
+		}
+		if groupNameCol >= 0 {
+			if name := strings.TrimSpace(record[groupNameCol]); name != "" {
+				data.group.Name = name
+			}
+		}
+
+		status := "ACTIVE"
+		if statusCol >= 0 {
+			switch strings.ToLower(strings.TrimSpace(record[statusCol])) {
+			case "suspended", "inactive", "terminated":
+				status = "SUSPENDED"
+			}
+		}
+
+		key := groupEmail + "\x00" + memberEmail
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		data.members = append(data.members, &gws.GroupMember{
+			ID:     memberEmail,
+			Email:  memberEmail,
+			Role:   "MEMBER",
+			Type:   "USER",
+			Status: status,
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, fmt.Errorf("CSV export failed validation: %s", strings.Join(rowErrors, "; "))
+	}
+
+	return groups, nil
+}
+
+// normalizeHeader makes header matching insensitive to case, surrounding space and a UTF-8 BOM
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+// isEmail performs the same loose email check used for configured groups
+func isEmail(value string) bool {
+	at := strings.Index(value, "@")
+	return at > 0 && at < len(value)-1 && !strings.ContainsAny(value, " \t")
+}
@@ -0,0 +1,161 @@
+package csvsource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		cfg           config.CSVSourceConfig
+		expectError   string
+		expectGroups  map[string]int
+		expectName    string
+		expectSkipped string
+	}{
+		{
+			name:         "default columns",
+			input:        "group,email\neng@example.com,alice@example.com\neng@example.com,bob@example.com\nsales@example.com,carol@example.com\n",
+			expectGroups: map[string]int{"eng@example.com": 2, "sales@example.com": 1},
+		},
+		{
+			name:  "header mapping is case insensitive",
+			input: "\ufeffTeam Email;Team Name;Work Email;Employment Status\nENG@example.com;Engineering;Alice@Example.com;Active\neng@example.com;Engineering;bob@example.com;Terminated\n",
+			cfg: config.CSVSourceConfig{
+				Delimiter: ";",
+				Columns: config.CSVColumns{
+					Group:     "team email",
+					Email:     "WORK EMAIL",
+					GroupName: "Team Name",
+					Status:    "Employment Status",
+				},
+			},
+			expectGroups:  map[string]int{"eng@example.com": 2},
+			expectName:    "Engineering",
+			expectSkipped: "bob@example.com",
+		},
+		{
+			name:         "duplicate rows are collapsed",
+			input:        "group,email\neng@example.com,alice@example.com\neng@example.com,ALICE@example.com\n",
+			expectGroups: map[string]int{"eng@example.com": 1},
+		},
+		{
+			name:        "missing column",
+			input:       "group,mail\neng@example.com,alice@example.com\n",
+			expectError: `missing column "email"`,
+		},
+		{
+			name:        "invalid rows fail the whole file",
+			input:       "group,email\neng@example.com,alice@example.com\neng@example.com,\nnot-a-group,bob@example.com\n",
+			expectError: "line 3: invalid member email",
+		},
+		{
+			name:        "empty file",
+			input:       "",
+			expectError: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := parse(strings.NewReader(tt.input), tt.cfg)
+
+			if tt.expectError != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing %q, got nil", tt.expectError)
+				}
+				if !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(groups) != len(tt.expectGroups) {
+				t.Errorf("Expected %d groups, got %d", len(tt.expectGroups), len(groups))
+			}
+			for email, count := range tt.expectGroups {
+				data, ok := groups[email]
+				if !ok {
+					t.Errorf("Expected group %s to be present", email)
+					continue
+				}
+				if len(data.members) != count {
+					t.Errorf("Expected %d members in %s, got %d", count, email, len(data.members))
+				}
+				if tt.expectName != "" && data.group.Name != tt.expectName {
+					t.Errorf("Expected group name %q, got %q", tt.expectName, data.group.Name)
+				}
+				for _, member := range data.members {
+					expectStatus := "ACTIVE"
+					if member.Email == tt.expectSkipped {
+						expectStatus = "SUSPENDED"
+					}
+					if member.Status != expectStatus {
+						t.Errorf("Expected %s to have status %s, got %s", member.Email, expectStatus, member.Status)
+					}
+					if member.Type != "USER" {
+						t.Errorf("Expected member type USER, got %s", member.Type)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSource_LocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, []byte("group,email\neng@example.com,alice@example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+
+	source := NewSource(config.CSVSourceConfig{Path: path}, 0)
+
+	group, err := source.GetGroup(context.Background(), "Eng@Example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if group.Email != "eng@example.com" {
+		t.Errorf("Expected group email eng@example.com, got %s", group.Email)
+	}
+
+	if _, err := source.GetGroupMembers(context.Background(), "missing@example.com"); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound, got %v", err)
+	}
+
+	// A refresh with an invalid export keeps the previously loaded membership
+	if err := os.WriteFile(path, []byte("group,email\n,alice@example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+	if err := source.Refresh(context.Background()); err == nil {
+		t.Error("Expected refresh to fail for invalid export")
+	}
+	members, err := source.GetGroupMembers(context.Background(), "eng@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(members) != 1 {
+		t.Errorf("Expected previous membership to be kept, got %d members", len(members))
+	}
+}
+
+func TestSource_FileTooLarge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, []byte("group,email\neng@example.com,alice@example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+
+	if err := NewSource(config.CSVSourceConfig{Path: path}, 10).Refresh(context.Background()); err == nil {
+		t.Error("Expected error for oversized export, got nil")
+	}
+}
@@ -0,0 +1,98 @@
+package gws
+
+import (
+	gosync "sync"
+	"time"
+)
+
+// CallStats summarizes the requests made to one Admin SDK method
+type CallStats struct {
+	Calls           int           `json:"calls"`
+	Errors          int           `json:"errors"`
+	TotalDuration   time.Duration `json:"-"`
+	AverageDuration time.Duration `json:"average_duration"`
+	MaxDuration     time.Duration `json:"max_duration"`
+}
+
+// SlowCall is a request that took longer than the slow call threshold
+type SlowCall struct {
+	Method   string // e.g. members.list
+	Group    string // The group the request was about, if any
+	Page     int    // The page of a listing, from 1; 0 for requests that are not paged
+	Duration time.Duration
+	Err      error
+}
+
+// CallMetrics times the Admin SDK requests of one or more clients by method and reports those
+// slower than a threshold. It is safe for concurrent use; a nil CallMetrics records nothing
+type CallMetrics struct {
+	mu        gosync.Mutex
+	stats     map[string]*CallStats
+	threshold time.Duration
+	onSlow    func(SlowCall)
+}
+
+// NewCallMetrics creates a recorder that passes requests slower than threshold to onSlow; a
+// threshold of 0 reports none
+func NewCallMetrics(threshold time.Duration, onSlow func(SlowCall)) *CallMetrics {
+	return &CallMetrics{
+		stats:     make(map[string]*CallStats),
+		threshold: threshold,
+		onSlow:    onSlow,
+	}
+}
+
+// Stats returns the requests recorded so far by method
+func (m *CallMetrics) Stats() map[string]CallStats {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]CallStats, len(m.stats))
+	for method, calls := range m.stats {
+		copied := *calls
+		copied.AverageDuration = calls.TotalDuration / time.Duration(calls.Calls)
+		stats[method] = copied
+	}
+	return stats
+}
+
+// start times a request and returns the function recording its outcome
+func (m *CallMetrics) start(method, group string, page int) func(error) {
+	if m == nil {
+		return func(error) {}
+	}
+	started := time.Now()
+	return func(err error) {
+		m.record(SlowCall{Method: method, Group: group, Page: page, Duration: time.Since(started), Err: err})
+	}
+}
+
+func (m *CallMetrics) record(call SlowCall) {
+	m.mu.Lock()
+	stats, ok := m.stats[call.Method]
+	if !ok {
+		stats = &CallStats{}
+		m.stats[call.Method] = stats
+	}
+	stats.Calls++
+	stats.TotalDuration += call.Duration
+	if call.Duration > stats.MaxDuration {
+		stats.MaxDuration = call.Duration
+	}
+	if call.Err != nil {
+		stats.Errors++
+	}
+	m.mu.Unlock()
+
+	if m.threshold > 0 && call.Duration >= m.threshold && m.onSlow != nil {
+		m.onSlow(call)
+	}
+}
+
+// SetCallMetrics sets where the client's requests are timed; without it they are not
+func (c *Client) SetCallMetrics(metrics *CallMetrics) {
+	c.calls = metrics
+}
@@ -0,0 +1,45 @@
+package gws
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCallMetrics(t *testing.T) {
+	var slow []SlowCall
+	metrics := NewCallMetrics(time.Second, func(call SlowCall) { slow = append(slow, call) })
+
+	metrics.record(SlowCall{Method: "members.list", Group: "eng@example.com", Page: 1, Duration: 200 * time.Millisecond})
+	metrics.record(SlowCall{Method: "members.list", Group: "eng@example.com", Page: 2, Duration: 3 * time.Second})
+	metrics.record(SlowCall{Method: "groups.get", Group: "eng@example.com", Duration: 100 * time.Millisecond, Err: errors.New("backend error")})
+
+	stats := metrics.Stats()
+	members := stats["members.list"]
+	if members.Calls != 2 || members.Errors != 0 || members.MaxDuration != 3*time.Second || members.AverageDuration != 1600*time.Millisecond {
+		t.Errorf("Unexpected members.list stats: %+v", members)
+	}
+	if groups := stats["groups.get"]; groups.Calls != 1 || groups.Errors != 1 {
+		t.Errorf("Unexpected groups.get stats: %+v", groups)
+	}
+
+	if len(slow) != 1 || slow[0].Method != "members.list" || slow[0].Page != 2 || slow[0].Group != "eng@example.com" {
+		t.Errorf("Expected only the second page to be reported as slow, got %+v", slow)
+	}
+}
+
+func TestCallMetrics_Disabled(t *testing.T) {
+	reported := false
+	metrics := NewCallMetrics(0, func(SlowCall) { reported = true })
+	metrics.record(SlowCall{Method: "users.get", Duration: time.Minute})
+	if reported {
+		t.Error("Expected no slow calls to be reported with a threshold of 0")
+	}
+
+	// A client without metrics times nothing
+	var none *CallMetrics
+	none.start("users.get", "", 0)(nil)
+	if stats := none.Stats(); stats != nil {
+		t.Errorf("Expected no stats without metrics, got %+v", stats)
+	}
+}
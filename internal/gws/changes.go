@@ -0,0 +1,130 @@
+package gws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	reports "google.golang.org/api/admin/reports/v1"
+	"google.golang.org/api/option"
+)
+
+// Changes lists what changed in the directory according to the Admin audit log
+type Changes struct {
+	Groups []string // Lower-cased addresses of groups whose settings or membership changed, sorted
+	Users  []string // Lower-cased addresses of users suspended, restored, renamed or deleted, sorted
+}
+
+// Audit log applications read for changes
+const (
+	reportsAppAdmin  = "admin"             // Changes made in the Admin Console or Directory API
+	reportsAppGroups = "groups_enterprise" // Changes made in Google Groups
+)
+
+// userChangeEvents are the admin events that change whether a user is synced in every group they belong to
+var userChangeEvents = map[string]bool{
+	"SUSPEND_USER":   true,
+	"UNSUSPEND_USER": true,
+	"DELETE_USER":    true,
+	"UNDELETE_USER":  true,
+	"RENAME_USER":    true,
+}
+
+// ChangeReader reads recent group and user changes from the Admin SDK Reports API, so runs can
+// skip groups that have not changed
+type ChangeReader struct {
+	service      *reports.Service
+	scopes       scopeChecker
+	pageAttempts int
+}
+
+// NewChangeReader creates a reader that impersonates adminEmail; the service account needs the
+// admin.reports.audit.readonly scope in its domain-wide delegation
+func NewChangeReader(serviceAccountKeyPath, adminEmail string, baseClient *http.Client) (*ChangeReader, error) {
+	ctx := httpclient.OAuthContext(baseClient)
+
+	httpClient, clientID, err := newDelegatedHTTPClient(ctx, serviceAccountKeyPath, adminEmail, reports.AdminReportsAuditReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := reports.NewService(ctx, option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Reports service: %w", err)
+	}
+
+	return &ChangeReader{
+		service: service,
+		scopes:  scopeChecker{clientID: clientID, subject: adminEmail, requested: []string{reports.AdminReportsAuditReadonlyScope}},
+	}, nil
+}
+
+// SetPageRetryAttempts sets how many times each page of the audit log is requested before giving up;
+// DefaultPageRetryAttempts is used when it is not set
+func (r *ChangeReader) SetPageRetryAttempts(attempts int) {
+	r.pageAttempts = attempts
+}
+
+// Changes returns the groups and users changed at or after since
+func (r *ChangeReader) Changes(ctx context.Context, since time.Time) (*Changes, error) {
+	groups := make(map[string]bool)
+	users := make(map[string]bool)
+
+	for _, app := range []string{reportsAppAdmin, reportsAppGroups} {
+		pageToken := ""
+		for {
+			call := r.service.Activities.List("all", app).StartTime(since.UTC().Format(time.RFC3339)).MaxResults(1000)
+			if pageToken != "" {
+				call = call.PageToken(pageToken)
+			}
+
+			resp, err := fetchPage(ctx, r.pageAttempts, func() (*reports.Activities, error) { return call.Context(ctx).Do() })
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s audit events: %w", app, r.scopes.check(err, "read the "+app+" audit log", reports.AdminReportsAuditReadonlyScope))
+			}
+
+			for _, activity := range resp.Items {
+				for _, event := range activity.Events {
+					collectChange(event, groups, users)
+				}
+			}
+
+			if resp.NextPageToken == "" {
+				break
+			}
+			pageToken = resp.NextPageToken
+		}
+	}
+
+	return &Changes{Groups: sortedKeys(groups), Users: sortedKeys(users)}, nil
+}
+
+// collectChange records the group or user an audit event changed, if any
+func collectChange(event *reports.ActivityEvents, groups, users map[string]bool) {
+	for _, param := range event.Parameters {
+		switch {
+		// Admin events name the group in GROUP_EMAIL, Google Groups events in group_id
+		case param.Name == "GROUP_EMAIL" || param.Name == "group_id":
+			if param.Value != "" {
+				groups[strings.ToLower(param.Value)] = true
+			}
+		case param.Name == "USER_EMAIL" && userChangeEvents[event.Name]:
+			if param.Value != "" {
+				users[strings.ToLower(param.Value)] = true
+			}
+		}
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/googleapi"
@@ -19,6 +20,9 @@ type Client struct {
 	service         *admin.Service
 	domain          string
 	superAdminEmail string
+	scopes          scopeChecker
+	pageAttempts    int          // See SetPageRetryAttempts
+	calls           *CallMetrics // See SetCallMetrics
 }
 
 // User represents a Google Workspace user
@@ -28,6 +32,10 @@ type User struct {
 	Name         UserName `json:"name"`
 	Suspended    bool     `json:"suspended"`
 	Archived     bool     `json:"archived"`
+	OrgUnitPath  string   `json:"orgUnitPath,omitempty"`
+	Department   string   `json:"department,omitempty"` // Of the primary organization
+	Title        string   `json:"title,omitempty"`      // Of the primary organization
+	EmployeeID   string   `json:"employeeId,omitempty"` // External ID of type organization
 }
 
 // UserName represents a user's name components
@@ -56,38 +64,24 @@ type GroupMember struct {
 
 // NewClient creates a new Google Workspace client
 func NewClient(serviceAccountKeyPath, domain, superAdminEmail string) (*Client, error) {
-	ctx := context.Background()
-
-	// Read service account credentials
-	credentialsJSON, err := os.ReadFile(serviceAccountKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read service account file: %w", err)
-	}
+	return NewClientWithHTTPClient(serviceAccountKeyPath, domain, superAdminEmail, httpclient.New(httpclient.Options{}))
+}
 
-	// Parse credentials to get client email
-	var creds struct {
-		ClientEmail string `json:"client_email"`
-	}
-	if err := json.Unmarshal(credentialsJSON, &creds); err != nil {
-		return nil, fmt.Errorf("failed to parse service account credentials: %w", err)
-	}
+// NewClientWithHTTPClient creates a new Google Workspace client whose API and token
+// requests are sent through the given base HTTP client
+func NewClientWithHTTPClient(serviceAccountKeyPath, domain, superAdminEmail string, baseClient *http.Client) (*Client, error) {
+	ctx := httpclient.OAuthContext(baseClient)
 
-	// Create JWT config for domain-wide delegation
-	config, err := google.JWTConfigFromJSON(
-		credentialsJSON,
+	scopes := []string{
 		admin.AdminDirectoryUserScope,
 		admin.AdminDirectoryGroupScope,
 		admin.AdminDirectoryGroupMemberScope,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create JWT config: %w", err)
 	}
 
-	// Set the subject for domain-wide delegation
-	config.Subject = superAdminEmail
-
-	// Create HTTP client
-	httpClient := config.Client(ctx)
+	httpClient, clientID, err := newDelegatedHTTPClient(ctx, serviceAccountKeyPath, superAdminEmail, scopes...)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create Admin SDK service
 	service, err := admin.NewService(ctx, option.WithHTTPClient(httpClient))
@@ -99,37 +93,61 @@ func NewClient(serviceAccountKeyPath, domain, superAdminEmail string) (*Client,
 		service:         service,
 		domain:          domain,
 		superAdminEmail: superAdminEmail,
+		scopes:          scopeChecker{clientID: clientID, subject: superAdminEmail, requested: scopes},
 	}, nil
 }
 
+// newDelegatedHTTPClient creates an HTTP client that acts as subject using the
+// service account's domain-wide delegation, and returns the service account's client ID
+func newDelegatedHTTPClient(ctx context.Context, serviceAccountKeyPath, subject string, scopes ...string) (*http.Client, string, error) {
+	// Read service account credentials
+	credentialsJSON, err := os.ReadFile(serviceAccountKeyPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read service account file: %w", err)
+	}
+
+	// Parse credentials to get client email and ID
+	var creds struct {
+		ClientEmail string `json:"client_email"`
+		ClientID    string `json:"client_id"`
+	}
+	if err := json.Unmarshal(credentialsJSON, &creds); err != nil {
+		return nil, "", fmt.Errorf("failed to parse service account credentials: %w", err)
+	}
+
+	// Create JWT config for domain-wide delegation
+	config, err := google.JWTConfigFromJSON(credentialsJSON, scopes...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create JWT config: %w", err)
+	}
+
+	// Set the subject for domain-wide delegation
+	config.Subject = subject
+
+	// Create HTTP client
+	return config.Client(ctx), creds.ClientID, nil
+}
+
 // GetUsers retrieves all users in the domain
-func (c *Client) GetUsers() ([]*User, error) {
+func (c *Client) GetUsers(ctx context.Context) ([]*User, error) {
 	var allUsers []*User
 	pageToken := ""
 
-	for {
+	for page := 1; ; page++ {
 		call := c.service.Users.List().Domain(c.domain).MaxResults(500)
 		if pageToken != "" {
 			call = call.PageToken(pageToken)
 		}
 
-		resp, err := call.Do()
+		done := c.calls.start("users.list", "", page)
+		resp, err := call.Context(ctx).Do()
+		done(err)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list users: %w", err)
+			return nil, fmt.Errorf("failed to list users: %w", c.scopes.check(err, "list users", admin.AdminDirectoryUserScope))
 		}
 
 		for _, user := range resp.Users {
-			allUsers = append(allUsers, &User{
-				ID:           user.Id,
-				PrimaryEmail: user.PrimaryEmail,
-				Name: UserName{
-					GivenName:  user.Name.GivenName,
-					FamilyName: user.Name.FamilyName,
-					FullName:   user.Name.FullName,
-				},
-				Suspended: user.Suspended,
-				Archived:  user.Archived,
-			})
+			allUsers = append(allUsers, newUser(user))
 		}
 
 		if resp.NextPageToken == "" {
@@ -141,11 +159,111 @@ func (c *Client) GetUsers() ([]*User, error) {
 	return allUsers, nil
 }
 
+// GetOrgUnitUsers retrieves the users in an organizational unit, such as /Engineering, and in the
+// units below it
+func (c *Client) GetOrgUnitUsers(ctx context.Context, orgUnitPath string) ([]*User, error) {
+	query := fmt.Sprintf("orgUnitPath='%s'", strings.ReplaceAll(orgUnitPath, "'", "\\'"))
+	var users []*User
+	pageToken := ""
+
+	for page := 1; ; page++ {
+		call := c.service.Users.List().Domain(c.domain).Query(query).MaxResults(500)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		done := c.calls.start("users.list", "", page)
+		resp, err := call.Context(ctx).Do()
+		done(err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users of org unit %s: %w", orgUnitPath, c.scopes.check(err, "list users of org unit "+orgUnitPath, admin.AdminDirectoryUserScope))
+		}
+
+		for _, user := range resp.Users {
+			users = append(users, newUser(user))
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	return users, nil
+}
+
+// GetUser retrieves a user by email, or nil when the address is not a user in the directory,
+// e.g. an external member of a group
+func (c *Client) GetUser(ctx context.Context, email string) (*User, error) {
+	done := c.calls.start("users.get", "", 0)
+	user, err := c.service.Users.Get(email).Context(ctx).Do()
+	done(err)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user %s: %w", email, c.scopes.check(err, "read user "+email, admin.AdminDirectoryUserScope))
+	}
+	return newUser(user), nil
+}
+
+// newUser converts a Directory API user
+func newUser(user *admin.User) *User {
+	converted := &User{
+		ID:           user.Id,
+		PrimaryEmail: user.PrimaryEmail,
+		Suspended:    user.Suspended,
+		Archived:     user.Archived,
+		OrgUnitPath:  user.OrgUnitPath,
+	}
+	if user.Name != nil {
+		converted.Name = UserName{
+			GivenName:  user.Name.GivenName,
+			FamilyName: user.Name.FamilyName,
+			FullName:   user.Name.FullName,
+		}
+	}
+
+	// Organizations and external IDs are untyped in the API client, so they are decoded here
+	var organizations []admin.UserOrganization
+	if decodeField(user.Organizations, &organizations) {
+		for i, org := range organizations {
+			if org.Primary || i == 0 {
+				converted.Department, converted.Title = org.Department, org.Title
+			}
+		}
+	}
+	var externalIDs []admin.UserExternalId
+	if decodeField(user.ExternalIds, &externalIDs) {
+		for _, id := range externalIDs {
+			if id.Type == "organization" {
+				converted.EmployeeID = id.Value
+				break
+			}
+		}
+	}
+	return converted
+}
+
+// decodeField decodes an untyped API field into target, reporting whether it was set and valid
+func decodeField(field interface{}, target interface{}) bool {
+	if field == nil {
+		return false
+	}
+	raw, err := json.Marshal(field)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, target) == nil
+}
+
 // GetGroup retrieves a specific group by email
-func (c *Client) GetGroup(groupEmail string) (*Group, error) {
-	group, err := c.service.Groups.Get(groupEmail).Do()
+func (c *Client) GetGroup(ctx context.Context, groupEmail string) (*Group, error) {
+	done := c.calls.start("groups.get", groupEmail, 0)
+	group, err := c.service.Groups.Get(groupEmail).Context(ctx).Do()
+	done(err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get group %s: %w", groupEmail, err)
+		return nil, fmt.Errorf("failed to get group %s: %w", groupEmail, c.scopes.check(err, "read group "+groupEmail, admin.AdminDirectoryGroupScope))
 	}
 
 	return &Group{
@@ -156,24 +274,55 @@ func (c *Client) GetGroup(groupEmail string) (*Group, error) {
 	}, nil
 }
 
+// GetGroupAliases retrieves the alias addresses of a group
+func (c *Client) GetGroupAliases(ctx context.Context, groupEmail string) ([]string, error) {
+	done := c.calls.start("groups.aliases.list", groupEmail, 0)
+	resp, err := c.service.Groups.Aliases.List(groupEmail).Context(ctx).Do()
+	done(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aliases of group %s: %w", groupEmail, c.scopes.check(err, "list aliases of "+groupEmail, admin.AdminDirectoryGroupScope))
+	}
+
+	// The generated client leaves each alias as a decoded JSON object
+	aliases := make([]string, 0, len(resp.Aliases))
+	for _, raw := range resp.Aliases {
+		if alias, ok := raw.(map[string]interface{}); ok {
+			if address, ok := alias["alias"].(string); ok && address != "" {
+				aliases = append(aliases, address)
+			}
+		}
+	}
+	return aliases, nil
+}
+
 // GetGroupMembers retrieves all members of a group
-func (c *Client) GetGroupMembers(groupEmail string) ([]*GroupMember, error) {
+func (c *Client) GetGroupMembers(ctx context.Context, groupEmail string) ([]*GroupMember, error) {
 	var allMembers []*GroupMember
 	pageToken := ""
 
-	for {
+	for pages := 0; ; pages++ {
 		call := c.service.Members.List(groupEmail).MaxResults(200)
 		if pageToken != "" {
 			call = call.PageToken(pageToken)
 		}
 
-		resp, err := call.Do()
+		// Each attempt at a page is timed on its own, so a slow page is told apart from a retried one
+		resp, err := fetchPage(ctx, c.pageAttempts, func() (*admin.Members, error) {
+			done := c.calls.start("members.list", groupEmail, pages+1)
+			resp, err := call.Context(ctx).Do()
+			done(err)
+			return resp, err
+		})
 		if err != nil {
 			// Handle case where group has no members
 			if isNotFoundError(err) {
 				return allMembers, nil
 			}
-			return nil, fmt.Errorf("failed to list members for group %s: %w", groupEmail, err)
+			err = fmt.Errorf("failed to list members for group %s: %w", groupEmail, c.scopes.check(err, "list members of "+groupEmail, admin.AdminDirectoryGroupMemberScope))
+			if pages > 0 {
+				return allMembers, &PartialMembersError{Group: groupEmail, Pages: pages, Err: err}
+			}
+			return nil, err
 		}
 
 		for _, member := range resp.Members {
@@ -195,56 +344,90 @@ func (c *Client) GetGroupMembers(groupEmail string) ([]*GroupMember, error) {
 	return allMembers, nil
 }
 
+// SetPageRetryAttempts sets how many times each page of a listing is requested before giving up;
+// DefaultPageRetryAttempts is used when it is not set
+func (c *Client) SetPageRetryAttempts(attempts int) {
+	c.pageAttempts = attempts
+}
+
 // AddMemberToGroup adds a user to a Google Workspace group
-func (c *Client) AddMemberToGroup(groupEmail, userEmail string) error {
+func (c *Client) AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error {
 	member := &admin.Member{
 		Email: userEmail,
 		Role:  "MEMBER",
 		Type:  "USER",
 	}
 
-	_, err := c.service.Members.Insert(groupEmail, member).Do()
+	done := c.calls.start("members.insert", groupEmail, 0)
+	_, err := c.service.Members.Insert(groupEmail, member).Context(ctx).Do()
+	done(err)
 	if err != nil {
 		// Check if user is already a member
 		if googleErr, ok := err.(*googleapi.Error); ok && googleErr.Code == http.StatusConflict {
 			return nil // User already in group, no error
 		}
-		return fmt.Errorf("failed to add member %s to group %s: %w", userEmail, groupEmail, err)
+		return fmt.Errorf("failed to add member %s to group %s: %w", userEmail, groupEmail, c.scopes.check(err, "add members to "+groupEmail, admin.AdminDirectoryGroupMemberScope))
 	}
 
 	return nil
 }
 
 // RemoveMemberFromGroup removes a user from a Google Workspace group
-func (c *Client) RemoveMemberFromGroup(groupEmail, userEmail string) error {
-	err := c.service.Members.Delete(groupEmail, userEmail).Do()
+func (c *Client) RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error {
+	done := c.calls.start("members.delete", groupEmail, 0)
+	err := c.service.Members.Delete(groupEmail, userEmail).Context(ctx).Do()
+	done(err)
 	if err != nil {
 		// Check if user is not a member (404 error)
 		if isNotFoundError(err) {
 			return nil // User not in group, no error
 		}
-		return fmt.Errorf("failed to remove member %s from group %s: %w", userEmail, groupEmail, err)
+		return fmt.Errorf("failed to remove member %s from group %s: %w", userEmail, groupEmail, c.scopes.check(err, "remove members from "+groupEmail, admin.AdminDirectoryGroupMemberScope))
 	}
 
 	return nil
 }
 
+// SetUserSchemaFields sets fields of a custom user schema on a user, leaving the user's other
+// fields in the schema unchanged. The schema must already exist in the domain. Addresses that are
+// not users in the directory, e.g. external group members, are left alone
+func (c *Client) SetUserSchemaFields(ctx context.Context, email, schemaName string, fields map[string]interface{}) error {
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s fields: %w", schemaName, err)
+	}
+	user := &admin.User{CustomSchemas: map[string]googleapi.RawMessage{schemaName: raw}}
+
+	done := c.calls.start("users.patch", "", 0)
+	_, err = c.service.Users.Patch(email, user).Context(ctx).Do()
+	done(err)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to set %s fields of user %s: %w", schemaName, email, c.scopes.check(err, "update user "+email, admin.AdminDirectoryUserScope))
+	}
+	return nil
+}
+
 // CreateGroup creates a new Google Workspace group
-func (c *Client) CreateGroup(groupEmail, groupName, description string) (*Group, error) {
+func (c *Client) CreateGroup(ctx context.Context, groupEmail, groupName, description string) (*Group, error) {
 	group := &admin.Group{
 		Email:       groupEmail,
 		Name:        groupName,
 		Description: description,
 	}
 
-	createdGroup, err := c.service.Groups.Insert(group).Do()
+	done := c.calls.start("groups.insert", groupEmail, 0)
+	createdGroup, err := c.service.Groups.Insert(group).Context(ctx).Do()
+	done(err)
 	if err != nil {
 		// Check if group already exists
 		if googleErr, ok := err.(*googleapi.Error); ok && googleErr.Code == http.StatusConflict {
 			// Group exists, fetch and return it
-			return c.GetGroup(groupEmail)
+			return c.GetGroup(ctx, groupEmail)
 		}
-		return nil, fmt.Errorf("failed to create group %s: %w", groupEmail, err)
+		return nil, fmt.Errorf("failed to create group %s: %w", groupEmail, c.scopes.check(err, "create group "+groupEmail, admin.AdminDirectoryGroupScope))
 	}
 
 	return &Group{
@@ -255,14 +438,28 @@ func (c *Client) CreateGroup(groupEmail, groupName, description string) (*Group,
 	}, nil
 }
 
+// DeleteGroup deletes a Google Workspace group; a group that does not exist is not an error
+func (c *Client) DeleteGroup(ctx context.Context, groupEmail string) error {
+	done := c.calls.start("groups.delete", groupEmail, 0)
+	err := c.service.Groups.Delete(groupEmail).Context(ctx).Do()
+	done(err)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete group %s: %w", groupEmail, c.scopes.check(err, "delete group "+groupEmail, admin.AdminDirectoryGroupScope))
+	}
+	return nil
+}
+
 // EnsureGroup ensures a group exists, creating it if necessary
-func (c *Client) EnsureGroup(groupEmail, groupName, description string) (*Group, error) {
+func (c *Client) EnsureGroup(ctx context.Context, groupEmail, groupName, description string) (*Group, error) {
 	// Try to get existing group
-	group, err := c.GetGroup(groupEmail)
+	group, err := c.GetGroup(ctx, groupEmail)
 	if err != nil {
 		// If not found, create it
 		if isNotFoundError(err) {
-			return c.CreateGroup(groupEmail, groupName, description)
+			return c.CreateGroup(ctx, groupEmail, groupName, description)
 		}
 		return nil, fmt.Errorf("failed to check for existing group: %w", err)
 	}
@@ -0,0 +1,434 @@
+package gws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"google.golang.org/api/admin/directory/v1"
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// maxNestingDepth bounds how deep nested group memberships are expanded
+const maxNestingDepth = 10
+
+// CloudIdentityClient handles group operations through the Cloud Identity Groups API,
+// which also exposes dynamic and security groups that the Admin SDK cannot see
+type CloudIdentityClient struct {
+	service      *cloudidentity.Service
+	users        *admin.Service // Reads which members are suspended, which memberships do not tell
+	customerID   string
+	scopes       scopeChecker
+	userScopes   scopeChecker
+	pageAttempts int // See SetPageRetryAttempts
+
+	mu         sync.Mutex
+	groupNames map[string]string // group email -> resource name (groups/{id})
+}
+
+// NewCloudIdentityClient creates a Cloud Identity client whose API and token requests
+// are sent through the given base HTTP client
+func NewCloudIdentityClient(serviceAccountKeyPath, superAdminEmail, customerID string, baseClient *http.Client) (*CloudIdentityClient, error) {
+	ctx := httpclient.OAuthContext(baseClient)
+
+	httpClient, clientID, err := newDelegatedHTTPClient(ctx, serviceAccountKeyPath, superAdminEmail, cloudidentity.CloudIdentityGroupsScope)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := cloudidentity.NewService(ctx, option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Identity service: %w", err)
+	}
+
+	// Users are read with their own token, so a missing user scope only fails member listings
+	usersHTTPClient, _, err := newDelegatedHTTPClient(ctx, serviceAccountKeyPath, superAdminEmail, admin.AdminDirectoryUserReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+	users, err := admin.NewService(ctx, option.WithHTTPClient(usersHTTPClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Admin SDK service: %w", err)
+	}
+
+	if !strings.HasPrefix(customerID, "customers/") {
+		customerID = "customers/" + customerID
+	}
+
+	return &CloudIdentityClient{
+		service:    service,
+		users:      users,
+		customerID: customerID,
+		scopes:     scopeChecker{clientID: clientID, subject: superAdminEmail, requested: []string{cloudidentity.CloudIdentityGroupsScope}},
+		userScopes: scopeChecker{clientID: clientID, subject: superAdminEmail, requested: []string{admin.AdminDirectoryUserReadonlyScope}},
+		groupNames: make(map[string]string),
+	}, nil
+}
+
+// GetGroup retrieves a group by email address
+func (c *CloudIdentityClient) GetGroup(ctx context.Context, groupEmail string) (*Group, error) {
+	name, err := c.lookupGroupName(ctx, groupEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := c.service.Groups.Get(name).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group %s: %w", groupEmail, err)
+	}
+
+	return toGroup(group), nil
+}
+
+// GetGroupAliases retrieves the alias addresses of a group from its additional group keys
+func (c *CloudIdentityClient) GetGroupAliases(ctx context.Context, groupEmail string) ([]string, error) {
+	name, err := c.lookupGroupName(ctx, groupEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := c.service.Groups.Get(name).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group %s: %w", groupEmail, err)
+	}
+
+	var aliases []string
+	for _, key := range group.AdditionalGroupKeys {
+		if key.Id != "" {
+			aliases = append(aliases, key.Id)
+		}
+	}
+	return aliases, nil
+}
+
+// GetGroupMembers retrieves all members of a group, expanding nested groups so the
+// result contains the users who are effectively members. When a later page or a nested group
+// cannot be read, the members read so far are returned with a PartialMembersError
+func (c *CloudIdentityClient) GetGroupMembers(ctx context.Context, groupEmail string) ([]*GroupMember, error) {
+	name, err := c.lookupGroupName(ctx, groupEmail)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	collector := &memberCollector{
+		visitedGroups: map[string]bool{name: true},
+		seenMembers:   make(map[string]bool),
+	}
+	err = c.collectMembers(ctx, collector, name, 0)
+	if err != nil {
+		err = fmt.Errorf("failed to list members for group %s: %w", groupEmail, err)
+		if collector.pages == 0 {
+			return nil, err
+		}
+		err = &PartialMembersError{Group: groupEmail, Pages: collector.pages, Err: err}
+	} else if collector.unexpanded != nil {
+		err = &PartialMembersError{Group: groupEmail, Pages: collector.pages, Nested: collector.unexpandedGroup, Err: collector.unexpanded}
+	}
+
+	if statusErr := c.setMemberStatus(ctx, collector.members); statusErr != nil {
+		return nil, fmt.Errorf("failed to read the status of members of group %s: %w", groupEmail, statusErr)
+	}
+	return collector.members, err
+}
+
+// SetPageRetryAttempts sets how many times each page of a listing is requested before giving up;
+// DefaultPageRetryAttempts is used when it is not set
+func (c *CloudIdentityClient) SetPageRetryAttempts(attempts int) {
+	c.pageAttempts = attempts
+}
+
+// memberCollector accumulates the users of a group and the groups nested in it
+type memberCollector struct {
+	visitedGroups map[string]bool // Resource names of the groups expanded so far
+	seenMembers   map[string]bool
+	members       []*GroupMember
+	pages         int
+
+	unexpanded      error  // Why the first nested group that could not be expanded was skipped
+	unexpandedGroup string // Email of that group
+}
+
+// skip records a nested group whose members are missing from the result
+func (m *memberCollector) skip(groupEmail string, err error) {
+	if m.unexpanded == nil {
+		m.unexpanded, m.unexpandedGroup = err, groupEmail
+	}
+}
+
+// collectMembers appends the users in a group, recursing into nested groups. Nested groups that
+// cannot be read, e.g. because they belong to another customer, or that are nested deeper than
+// maxNestingDepth are skipped and recorded on the collector
+func (c *CloudIdentityClient) collectMembers(ctx context.Context, collector *memberCollector, groupName string, depth int) error {
+	var nested []string
+
+	pageToken := ""
+	for {
+		call := c.service.Groups.Memberships.List(groupName).View("FULL").PageSize(200)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		resp, err := fetchPage(ctx, c.pageAttempts, func() (*cloudidentity.ListMembershipsResponse, error) { return call.Context(ctx).Do() })
+		if err != nil {
+			return err
+		}
+		collector.pages++
+
+		for _, membership := range resp.Memberships {
+			if membership.PreferredMemberKey == nil {
+				continue
+			}
+			email := membership.PreferredMemberKey.Id
+
+			if membership.Type == "GROUP" {
+				nested = append(nested, email)
+				continue
+			}
+			if collector.seenMembers[email] {
+				continue
+			}
+			collector.seenMembers[email] = true
+
+			collector.members = append(collector.members, &GroupMember{
+				ID:     membership.Name,
+				Email:  email,
+				Role:   membershipRole(membership.Roles),
+				Type:   membershipType(membership.Type),
+				Status: memberStatusActive,
+			})
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	for _, email := range nested {
+		if depth >= maxNestingDepth {
+			collector.skip(email, fmt.Errorf("nested more than %d groups deep", maxNestingDepth))
+			continue
+		}
+
+		name, err := c.lookupGroupName(ctx, email)
+		if err != nil {
+			// Groups outside the customer cannot be expanded
+			if isNotFoundError(err) || isForbiddenError(err) {
+				collector.skip(email, err)
+				continue
+			}
+			return err
+		}
+		if collector.visitedGroups[name] {
+			continue
+		}
+		collector.visitedGroups[name] = true
+
+		if err := c.collectMembers(ctx, collector, name, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Member statuses, as the Admin SDK reports them
+const (
+	memberStatusActive    = "ACTIVE"
+	memberStatusSuspended = "SUSPENDED"
+)
+
+// setMemberStatus marks the members whose accounts are suspended, which Cloud Identity
+// memberships do not show. Members outside the directory are left active
+func (c *CloudIdentityClient) setMemberStatus(ctx context.Context, members []*GroupMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	suspended := make(map[string]bool)
+	pageToken := ""
+	for {
+		call := c.users.Users.List().Customer("my_customer").Query("isSuspended=true").Fields("users(primaryEmail,aliases)", "nextPageToken").MaxResults(500)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		resp, err := fetchPage(ctx, c.pageAttempts, func() (*admin.Users, error) { return call.Context(ctx).Do() })
+		if err != nil {
+			return c.userScopes.check(err, "list suspended users", admin.AdminDirectoryUserReadonlyScope)
+		}
+		for _, user := range resp.Users {
+			suspended[strings.ToLower(user.PrimaryEmail)] = true
+			for _, alias := range user.Aliases {
+				suspended[strings.ToLower(alias)] = true
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	for _, member := range members {
+		if suspended[strings.ToLower(member.Email)] {
+			member.Status = memberStatusSuspended
+		}
+	}
+	return nil
+}
+
+// AddMemberToGroup adds a user to a group
+func (c *CloudIdentityClient) AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error {
+	name, err := c.lookupGroupName(ctx, groupEmail)
+	if err != nil {
+		return err
+	}
+
+	membership := &cloudidentity.Membership{
+		PreferredMemberKey: &cloudidentity.EntityKey{Id: userEmail},
+		Roles:              []*cloudidentity.MembershipRole{{Name: "MEMBER"}},
+	}
+
+	if _, err := c.service.Groups.Memberships.Create(name, membership).Context(ctx).Do(); err != nil {
+		// Check if user is already a member
+		if googleErr, ok := err.(*googleapi.Error); ok && googleErr.Code == http.StatusConflict {
+			return nil
+		}
+		return fmt.Errorf("failed to add member %s to group %s: %w", userEmail, groupEmail, err)
+	}
+
+	return nil
+}
+
+// RemoveMemberFromGroup removes a user from a group
+func (c *CloudIdentityClient) RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error {
+	name, err := c.lookupGroupName(ctx, groupEmail)
+	if err != nil {
+		return err
+	}
+
+	lookup, err := c.service.Groups.Memberships.Lookup(name).MemberKeyId(userEmail).Context(ctx).Do()
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil // User not in group, no error
+		}
+		return fmt.Errorf("failed to look up membership of %s in group %s: %w", userEmail, groupEmail, err)
+	}
+
+	if _, err := c.service.Groups.Memberships.Delete(lookup.Name).Context(ctx).Do(); err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove member %s from group %s: %w", userEmail, groupEmail, err)
+	}
+
+	return nil
+}
+
+// EnsureGroup ensures a group exists, creating it if necessary
+func (c *CloudIdentityClient) EnsureGroup(ctx context.Context, groupEmail, groupName, description string) (*Group, error) {
+	group, err := c.GetGroup(ctx, groupEmail)
+	if err == nil {
+		return group, nil
+	}
+	if !isNotFoundError(err) {
+		return nil, fmt.Errorf("failed to check for existing group: %w", err)
+	}
+
+	newGroup := &cloudidentity.Group{
+		GroupKey:    &cloudidentity.EntityKey{Id: groupEmail},
+		Parent:      c.customerID,
+		DisplayName: groupName,
+		Description: description,
+		Labels:      map[string]string{"cloudidentity.googleapis.com/groups.discussion_forum": ""},
+	}
+
+	if _, err := c.service.Groups.Create(newGroup).InitialGroupConfig("WITH_INITIAL_OWNER").Context(ctx).Do(); err != nil {
+		if googleErr, ok := err.(*googleapi.Error); !ok || googleErr.Code != http.StatusConflict {
+			return nil, fmt.Errorf("failed to create group %s: %w", groupEmail, err)
+		}
+	}
+
+	return c.GetGroup(ctx, groupEmail)
+}
+
+// lookupGroupName resolves a group email to its Cloud Identity resource name
+func (c *CloudIdentityClient) lookupGroupName(ctx context.Context, groupEmail string) (string, error) {
+	key := strings.ToLower(groupEmail)
+
+	c.mu.Lock()
+	name, ok := c.groupNames[key]
+	c.mu.Unlock()
+	if ok {
+		return name, nil
+	}
+
+	resp, err := c.service.Groups.Lookup().GroupKeyId(groupEmail).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to look up group %s: %w", groupEmail, c.scopes.check(err, "look up group "+groupEmail, cloudidentity.CloudIdentityGroupsScope))
+	}
+
+	c.mu.Lock()
+	c.groupNames[key] = resp.Name
+	c.mu.Unlock()
+
+	return resp.Name, nil
+}
+
+// toGroup converts a Cloud Identity group to the shared Group type
+func toGroup(group *cloudidentity.Group) *Group {
+	result := &Group{
+		ID:          group.Name,
+		Name:        group.DisplayName,
+		Description: group.Description,
+	}
+	if group.GroupKey != nil {
+		result.Email = group.GroupKey.Id
+	}
+	if result.Name == "" {
+		result.Name = result.Email
+	}
+	return result
+}
+
+// membershipType maps Cloud Identity membership types to Admin SDK member types;
+// the names match apart from the type being omitted for plain users in some views
+func membershipType(t string) string {
+	if t == "" || t == "TYPE_UNSPECIFIED" {
+		return "USER"
+	}
+	return t
+}
+
+// membershipRole returns the highest role held, using Admin SDK role names
+func membershipRole(roles []*cloudidentity.MembershipRole) string {
+	role := "MEMBER"
+	for _, r := range roles {
+		switch r.Name {
+		case "OWNER":
+			return "OWNER"
+		case "MANAGER":
+			role = "MANAGER"
+		}
+	}
+	return role
+}
+
+// isForbiddenError checks if the error is a 403 permission denied error
+func isForbiddenError(err error) bool {
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code == http.StatusForbidden
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package gws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/admin/directory/v1"
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+// fakeDirectory serves group lookups, membership pages and suspended users for a Cloud Identity client
+type fakeDirectory struct {
+	groups    map[string][][]*cloudidentity.Membership // group email -> pages of memberships
+	forbidden map[string]bool                          // Groups whose lookup is denied
+	failPage  map[string]int                           // Group email -> page answered with 500
+	suspended []string
+}
+
+func (f *fakeDirectory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/v1/groups:lookup":
+		email := r.URL.Query().Get("groupKey.id")
+		if f.forbidden[email] {
+			writeGoogleError(w, http.StatusForbidden)
+			return
+		}
+		if _, ok := f.groups[email]; !ok {
+			writeGoogleError(w, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&cloudidentity.LookupGroupNameResponse{Name: "groups/" + email})
+
+	case strings.HasPrefix(r.URL.Path, "/v1/groups/") && strings.HasSuffix(r.URL.Path, "/memberships"):
+		email := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/groups/"), "/memberships")
+		page := 0
+		if token := r.URL.Query().Get("pageToken"); token != "" {
+			_, _ = fmt.Sscanf(token, "page-%d", &page)
+		}
+		if failPage, ok := f.failPage[email]; ok && failPage == page {
+			writeGoogleError(w, http.StatusInternalServerError)
+			return
+		}
+		resp := &cloudidentity.ListMembershipsResponse{Memberships: f.groups[email][page]}
+		if page+1 < len(f.groups[email]) {
+			resp.NextPageToken = fmt.Sprintf("page-%d", page+1)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+
+	case r.URL.Path == "/admin/directory/v1/users":
+		resp := &admin.Users{}
+		for _, email := range f.suspended {
+			resp.Users = append(resp.Users, &admin.User{PrimaryEmail: email, Suspended: true})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+
+	default:
+		writeGoogleError(w, http.StatusNotFound)
+	}
+}
+
+func writeGoogleError(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintf(w, `{"error":{"code":%d,"message":"%s"}}`, code, http.StatusText(code))
+}
+
+func newTestCloudIdentityClient(t *testing.T, directory *fakeDirectory) *CloudIdentityClient {
+	server := httptest.NewServer(directory)
+	t.Cleanup(server.Close)
+
+	opts := []option.ClientOption{option.WithEndpoint(server.URL + "/"), option.WithHTTPClient(server.Client())}
+	service, err := cloudidentity.NewService(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("Failed to create Cloud Identity service: %v", err)
+	}
+	users, err := admin.NewService(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("Failed to create Admin SDK service: %v", err)
+	}
+	return &CloudIdentityClient{service: service, users: users, pageAttempts: 1, groupNames: make(map[string]string)}
+}
+
+func userMembership(email string, roles ...string) *cloudidentity.Membership {
+	membership := &cloudidentity.Membership{Name: "memberships/" + email, PreferredMemberKey: &cloudidentity.EntityKey{Id: email}, Type: "USER"}
+	for _, role := range roles {
+		membership.Roles = append(membership.Roles, &cloudidentity.MembershipRole{Name: role})
+	}
+	return membership
+}
+
+func groupMembership(email string) *cloudidentity.Membership {
+	return &cloudidentity.Membership{PreferredMemberKey: &cloudidentity.EntityKey{Id: email}, Type: "GROUP"}
+}
+
+// describeMembers lists members as email:role:status for comparison
+func describeMembers(members []*GroupMember) string {
+	var described []string
+	for _, member := range members {
+		described = append(described, member.Email+":"+member.Role+":"+member.Status)
+	}
+	return strings.Join(described, ",")
+}
+
+func TestCloudIdentityGetGroupMembers_Nested(t *testing.T) {
+	client := newTestCloudIdentityClient(t, &fakeDirectory{
+		groups: map[string][][]*cloudidentity.Membership{
+			"eng@example.com": {
+				{userMembership("alice@example.com", "MEMBER", "OWNER"), groupMembership("team@example.com")},
+				{userMembership("bob@example.com", "MEMBER", "MANAGER")},
+			},
+			"team@example.com": {
+				{userMembership("carol@example.com", "MEMBER"), userMembership("alice@example.com", "MEMBER"), groupMembership("eng@example.com")},
+			},
+		},
+		suspended: []string{"Carol@example.com"},
+	})
+
+	members, err := client.GetGroupMembers(context.Background(), "eng@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "alice@example.com:OWNER:ACTIVE,bob@example.com:MANAGER:ACTIVE,carol@example.com:MEMBER:SUSPENDED"
+	if got := describeMembers(members); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestCloudIdentityGetGroupMembers_DepthLimit(t *testing.T) {
+	groups := make(map[string][][]*cloudidentity.Membership)
+	for i := 0; i <= maxNestingDepth+1; i++ {
+		groups[fmt.Sprintf("level%d@example.com", i)] = [][]*cloudidentity.Membership{{
+			userMembership(fmt.Sprintf("user%d@example.com", i)),
+			groupMembership(fmt.Sprintf("level%d@example.com", i+1)),
+		}}
+	}
+	client := newTestCloudIdentityClient(t, &fakeDirectory{groups: groups})
+
+	members, err := client.GetGroupMembers(context.Background(), "level0@example.com")
+	var partial *PartialMembersError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected a PartialMembersError for groups nested too deep, got %v", err)
+	}
+	if partial.Nested != fmt.Sprintf("level%d@example.com", maxNestingDepth+1) {
+		t.Errorf("Expected the first group past the depth limit to be reported, got %s", partial.Nested)
+	}
+	if len(members) != maxNestingDepth+1 {
+		t.Errorf("Expected the users of %d levels, got %s", maxNestingDepth+1, describeMembers(members))
+	}
+}
+
+func TestCloudIdentityGetGroupMembers_Partial(t *testing.T) {
+	tests := []struct {
+		name         string
+		directory    *fakeDirectory
+		expectNested string
+		expectPages  int
+		expectEmails string
+	}{
+		{
+			name: "nested group of another customer",
+			directory: &fakeDirectory{
+				groups: map[string][][]*cloudidentity.Membership{
+					"eng@example.com": {{userMembership("alice@example.com"), groupMembership("partners@other.com")}},
+				},
+				forbidden: map[string]bool{"partners@other.com": true},
+			},
+			expectNested: "partners@other.com",
+			expectPages:  1,
+			expectEmails: "alice@example.com:MEMBER:ACTIVE",
+		},
+		{
+			name: "deleted nested group",
+			directory: &fakeDirectory{
+				groups: map[string][][]*cloudidentity.Membership{
+					"eng@example.com": {{userMembership("alice@example.com"), groupMembership("gone@example.com")}},
+				},
+			},
+			expectNested: "gone@example.com",
+			expectPages:  1,
+			expectEmails: "alice@example.com:MEMBER:ACTIVE",
+		},
+		{
+			name: "later page fails",
+			directory: &fakeDirectory{
+				groups: map[string][][]*cloudidentity.Membership{
+					"eng@example.com": {{userMembership("alice@example.com")}, {userMembership("bob@example.com")}},
+				},
+				failPage: map[string]int{"eng@example.com": 1},
+			},
+			expectPages:  1,
+			expectEmails: "alice@example.com:MEMBER:ACTIVE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestCloudIdentityClient(t, tt.directory)
+
+			members, err := client.GetGroupMembers(context.Background(), "eng@example.com")
+			var partial *PartialMembersError
+			if !errors.As(err, &partial) {
+				t.Fatalf("Expected a PartialMembersError, got %v", err)
+			}
+			if partial.Nested != tt.expectNested || partial.Pages != tt.expectPages {
+				t.Errorf("Expected nested group %q after %d pages, got %q after %d", tt.expectNested, tt.expectPages, partial.Nested, partial.Pages)
+			}
+			if got := describeMembers(members); got != tt.expectEmails {
+				t.Errorf("Expected %s, got %s", tt.expectEmails, got)
+			}
+		})
+	}
+}
+
+func TestCloudIdentityGetGroupMembers_FirstPageFails(t *testing.T) {
+	client := newTestCloudIdentityClient(t, &fakeDirectory{
+		groups:   map[string][][]*cloudidentity.Membership{"eng@example.com": {{userMembership("alice@example.com")}}},
+		failPage: map[string]int{"eng@example.com": 0},
+	})
+
+	members, err := client.GetGroupMembers(context.Background(), "eng@example.com")
+	var partial *PartialMembersError
+	if err == nil || errors.As(err, &partial) || members != nil {
+		t.Errorf("Expected the listing to fail outright, got %v and %v", members, err)
+	}
+}
+
+func TestMembershipRole(t *testing.T) {
+	tests := []struct {
+		roles []string
+		want  string
+	}{
+		{nil, "MEMBER"},
+		{[]string{"MEMBER"}, "MEMBER"},
+		{[]string{"MEMBER", "MANAGER"}, "MANAGER"},
+		{[]string{"MANAGER", "OWNER", "MEMBER"}, "OWNER"},
+	}
+
+	for _, tt := range tests {
+		var roles []*cloudidentity.MembershipRole
+		for _, name := range tt.roles {
+			roles = append(roles, &cloudidentity.MembershipRole{Name: name})
+		}
+		if got := membershipRole(roles); got != tt.want {
+			t.Errorf("membershipRole(%v) = %s, want %s", tt.roles, got, tt.want)
+		}
+	}
+}
+
+func TestMembershipType(t *testing.T) {
+	tests := map[string]string{
+		"":                 "USER",
+		"TYPE_UNSPECIFIED": "USER",
+		"USER":             "USER",
+		"SERVICE_ACCOUNT":  "SERVICE_ACCOUNT",
+		"SHARED_DRIVE":     "SHARED_DRIVE",
+	}
+
+	for membershipTypeName, want := range tests {
+		if got := membershipType(membershipTypeName); got != want {
+			t.Errorf("membershipType(%q) = %s, want %s", membershipTypeName, got, want)
+		}
+	}
+}
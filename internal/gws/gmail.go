@@ -0,0 +1,60 @@
+package gws
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+// GmailSender sends plain-text email as a Workspace user through the Gmail API,
+// using the same domain-wide delegation as the directory clients
+type GmailSender struct {
+	service *gmail.Service
+	from    string
+	scopes  scopeChecker
+}
+
+// NewGmailSender creates a sender that impersonates fromEmail; the service account needs
+// the gmail.send scope in its domain-wide delegation
+func NewGmailSender(serviceAccountKeyPath, fromEmail string, baseClient *http.Client) (*GmailSender, error) {
+	ctx := httpclient.OAuthContext(baseClient)
+
+	httpClient, clientID, err := newDelegatedHTTPClient(ctx, serviceAccountKeyPath, fromEmail, gmail.GmailSendScope)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := gmail.NewService(ctx, option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gmail service: %w", err)
+	}
+
+	return &GmailSender{
+		service: service,
+		from:    fromEmail,
+		scopes:  scopeChecker{clientID: clientID, subject: fromEmail, requested: []string{gmail.GmailSendScope}},
+	}, nil
+}
+
+// Send sends a plain-text message to a single recipient
+func (g *GmailSender) Send(to, subject, body string) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", g.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	msg.WriteString(body)
+
+	message := &gmail.Message{Raw: base64.URLEncoding.EncodeToString([]byte(msg.String()))}
+	if _, err := g.service.Users.Messages.Send("me", message).Do(); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, g.scopes.check(err, "send email as "+g.from, gmail.GmailSendScope))
+	}
+	return nil
+}
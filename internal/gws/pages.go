@@ -0,0 +1,72 @@
+package gws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// DefaultPageRetryAttempts is how many times a page of results is requested before giving up
+const DefaultPageRetryAttempts = 3
+
+// pageRetryDelay is the wait before the second attempt at a page, doubled after each attempt
+var pageRetryDelay = time.Second
+
+// PartialMembersError is returned along with the members read before a later page of a group's
+// membership failed, even after retries, or when a nested group could not be expanded, so
+// callers may choose to sync the members they have
+type PartialMembersError struct {
+	Group  string
+	Pages  int    // Pages read before the failure
+	Nested string // Nested group that could not be expanded, when that is what is missing
+	Err    error
+}
+
+func (e *PartialMembersError) Error() string {
+	if e.Nested != "" {
+		return fmt.Sprintf("membership of %s is incomplete, nested group %s could not be expanded: %v", e.Group, e.Nested, e.Err)
+	}
+	return fmt.Sprintf("membership of %s is incomplete, page %d could not be read: %v", e.Group, e.Pages+1, e.Err)
+}
+
+func (e *PartialMembersError) Unwrap() error {
+	return e.Err
+}
+
+// fetchPage requests one page of results, retrying rate limiting, server errors and network
+// failures up to attempts times
+func fetchPage[T any](ctx context.Context, attempts int, fetch func() (T, error)) (T, error) {
+	if attempts < 1 {
+		attempts = DefaultPageRetryAttempts
+	}
+
+	delay := pageRetryDelay
+	for attempt := 1; ; attempt++ {
+		page, err := fetch()
+		if err == nil || attempt >= attempts || !isRetryablePageError(err) {
+			return page, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return page, err
+		}
+		delay *= 2
+	}
+}
+
+// isRetryablePageError reports whether a failed page request may succeed if repeated
+func isRetryablePageError(err error) bool {
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code == http.StatusTooManyRequests || googleErr.Code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,137 @@
+package gws
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+// ScopeError reports that domain-wide delegation for the service account does not
+// authorize a scope an operation needs, with the exact values to fix it
+type ScopeError struct {
+	ClientID  string   // Service account OAuth client ID shown in the Admin Console
+	Operation string   // What was being attempted, e.g. "list members of eng@example.com"
+	Scope     string   // Scope the operation requires
+	Requested []string // Every scope the client requests; all must be delegated
+	Err       error
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("insufficient delegated scopes to %s: client ID %s needs scope %s: %v", e.Operation, e.ClientID, e.Scope, e.Err)
+}
+
+func (e *ScopeError) Unwrap() error {
+	return e.Err
+}
+
+// Remediation returns the steps to authorize the missing scopes in the Admin Console
+func (e *ScopeError) Remediation() string {
+	return fmt.Sprintf(`Domain-wide delegation is missing the scope required to %s.
+  1. Open the Google Admin Console: Security > Access and data control > API controls > Manage Domain Wide Delegation
+  2. Edit (or add) the entry for client ID: %s
+  3. Set OAuth scopes to (comma-separated, paste exactly):
+     %s
+  Required for this operation: %s
+  Changes can take a few minutes to take effect.`,
+		e.Operation, e.ClientID, strings.Join(e.Requested, ","), e.Scope)
+}
+
+// PrivilegeError reports that the admin the service account acts as lacks the role or privilege
+// an operation needs, although delegation authorized the scope
+type PrivilegeError struct {
+	Admin     string // Admin the service account impersonates
+	Operation string
+	Err       error
+}
+
+func (e *PrivilegeError) Error() string {
+	return fmt.Sprintf("admin %s lacks the privilege to %s: %v", e.Admin, e.Operation, e.Err)
+}
+
+func (e *PrivilegeError) Unwrap() error {
+	return e.Err
+}
+
+// Remediation returns the steps to give the impersonated admin the privilege
+func (e *PrivilegeError) Remediation() string {
+	return fmt.Sprintf(`The admin %s, which the service account acts as, is not allowed to %s.
+  1. Open the Google Admin Console: Account > Admin roles
+  2. Assign %s a role with the privilege for this operation (e.g. Groups Admin for groups, User Management Admin for users),
+     or act as another admin that has one (google_workspace.super_admin_email)
+  Changes can take a few minutes to take effect.`,
+		e.Admin, e.Operation, e.Admin)
+}
+
+// isScopeError checks if err was caused by a scope missing from domain-wide delegation
+func isScopeError(err error) bool {
+	// The token endpoint refuses the whole request when any requested scope is not delegated
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		body := string(retrieveErr.Body)
+		if retrieveErr.ErrorCode == "unauthorized_client" || strings.Contains(body, "unauthorized_client") {
+			return true
+		}
+		if retrieveErr.ErrorCode == "access_denied" || strings.Contains(body, "access_denied") {
+			description := strings.ToLower(retrieveErr.ErrorDescription + " " + body)
+			return strings.Contains(description, "scope") || strings.Contains(description, "not authorized")
+		}
+		return false
+	}
+
+	// The token was issued but does not carry the scope the API needs
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) && googleErr.Code == http.StatusForbidden {
+		message := strings.ToLower(googleErr.Message)
+		return strings.Contains(message, "insufficient authentication scopes") ||
+			strings.Contains(googleErr.Body, "ACCESS_TOKEN_SCOPE_INSUFFICIENT")
+	}
+
+	return false
+}
+
+// isPrivilegeError checks if err means the impersonated admin lacks a role or privilege;
+// insufficientPermissions is also reported for missing scopes, so those are checked first
+func isPrivilegeError(err error) bool {
+	var googleErr *googleapi.Error
+	if !errors.As(err, &googleErr) || googleErr.Code != http.StatusForbidden || isScopeError(err) {
+		return false
+	}
+	for _, item := range googleErr.Errors {
+		if item.Reason == "insufficientPermissions" {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeChecker turns missing-delegation and missing-privilege errors into ScopeErrors and
+// PrivilegeErrors for one client
+type scopeChecker struct {
+	clientID  string
+	subject   string // Admin the client acts as
+	requested []string
+}
+
+// check returns a ScopeError or PrivilegeError wrapping err if it was caused by missing delegation
+// or admin privileges, otherwise err
+func (s scopeChecker) check(err error, operation, scope string) error {
+	switch {
+	case err == nil:
+		return nil
+	case isScopeError(err):
+		return &ScopeError{
+			ClientID:  s.clientID,
+			Operation: operation,
+			Scope:     scope,
+			Requested: s.requested,
+			Err:       err,
+		}
+	case isPrivilegeError(err):
+		return &PrivilegeError{Admin: s.subject, Operation: operation, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package gws
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+func TestScopeCheckerCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect string // scope, privilege or unchanged
+	}{
+		{
+			name:   "scope not delegated",
+			err:    &oauth2.RetrieveError{ErrorCode: "unauthorized_client", ErrorDescription: "Client is unauthorized to retrieve access tokens using this method, or client not authorized for any of the scopes requested."},
+			expect: "scope",
+		},
+		{
+			name:   "scope not delegated in the body only",
+			err:    &oauth2.RetrieveError{Body: []byte(`{"error":"unauthorized_client"}`)},
+			expect: "scope",
+		},
+		{
+			name:   "access denied for the scopes requested",
+			err:    &oauth2.RetrieveError{ErrorCode: "access_denied", ErrorDescription: "Requested client not authorized."},
+			expect: "scope",
+		},
+		{
+			name:   "access denied for another reason",
+			err:    &oauth2.RetrieveError{ErrorCode: "access_denied", ErrorDescription: "Account restricted"},
+			expect: "unchanged",
+		},
+		{
+			name:   "admin suspended",
+			err:    &oauth2.RetrieveError{ErrorCode: "invalid_grant", ErrorDescription: "Invalid email or User ID"},
+			expect: "unchanged",
+		},
+		{
+			name:   "token without the scope",
+			err:    &googleapi.Error{Code: http.StatusForbidden, Message: "Request had insufficient authentication scopes.", Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}}},
+			expect: "scope",
+		},
+		{
+			name:   "token scope insufficient in the body",
+			err:    &googleapi.Error{Code: http.StatusForbidden, Body: `{"error":{"status":"PERMISSION_DENIED","details":[{"reason":"ACCESS_TOKEN_SCOPE_INSUFFICIENT"}]}}`},
+			expect: "scope",
+		},
+		{
+			name:   "admin lacks a privilege",
+			err:    fmt.Errorf("failed: %w", &googleapi.Error{Code: http.StatusForbidden, Message: "Not Authorized to access this resource/api", Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}}}),
+			expect: "privilege",
+		},
+		{
+			name:   "forbidden for another reason",
+			err:    &googleapi.Error{Code: http.StatusForbidden, Message: "Domain cannot use apis.", Errors: []googleapi.ErrorItem{{Reason: "domainCannotUseApis"}}},
+			expect: "unchanged",
+		},
+		{
+			name:   "not found",
+			err:    &googleapi.Error{Code: http.StatusNotFound, Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}}},
+			expect: "unchanged",
+		},
+		{
+			name:   "network error",
+			err:    errors.New("connection reset by peer"),
+			expect: "unchanged",
+		},
+	}
+
+	checker := scopeChecker{clientID: "1234567890", subject: "admin@example.com", requested: []string{"scope-a", "scope-b"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checker.check(tt.err, "read group eng@example.com", "scope-a")
+
+			var scopeErr *ScopeError
+			var privilegeErr *PrivilegeError
+			switch tt.expect {
+			case "scope":
+				if !errors.As(err, &scopeErr) {
+					t.Fatalf("Expected a ScopeError, got %v", err)
+				}
+				if scopeErr.ClientID != "1234567890" || scopeErr.Scope != "scope-a" {
+					t.Errorf("Expected the client ID and scope to be reported, got %+v", scopeErr)
+				}
+			case "privilege":
+				if !errors.As(err, &privilegeErr) {
+					t.Fatalf("Expected a PrivilegeError, got %v", err)
+				}
+				if privilegeErr.Admin != "admin@example.com" {
+					t.Errorf("Expected the impersonated admin to be reported, got %+v", privilegeErr)
+				}
+			default:
+				if err != tt.err {
+					t.Errorf("Expected the error to be returned unchanged, got %v", err)
+				}
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected the original error to be wrapped, got %v", err)
+			}
+		})
+	}
+
+	if err := checker.check(nil, "read group eng@example.com", "scope-a"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestRemediation(t *testing.T) {
+	scopeErr := &ScopeError{ClientID: "1234567890", Operation: "list members of eng@example.com", Scope: "scope-b", Requested: []string{"scope-a", "scope-b"}}
+	remediation := scopeErr.Remediation()
+	for _, want := range []string{"Manage Domain Wide Delegation", "client ID: 1234567890", "scope-a,scope-b", "Required for this operation: scope-b"} {
+		if !strings.Contains(remediation, want) {
+			t.Errorf("Expected the scope remediation to contain %q, got:\n%s", want, remediation)
+		}
+	}
+
+	privilegeErr := &PrivilegeError{Admin: "admin@example.com", Operation: "list members of eng@example.com"}
+	remediation = privilegeErr.Remediation()
+	for _, want := range []string{"Admin roles", "Assign admin@example.com a role", "list members of eng@example.com"} {
+		if !strings.Contains(remediation, want) {
+			t.Errorf("Expected the privilege remediation to contain %q, got:\n%s", want, remediation)
+		}
+	}
+	if strings.Contains(remediation, "Domain Wide Delegation") {
+		t.Errorf("Expected no delegation steps for a missing privilege, got:\n%s", remediation)
+	}
+}
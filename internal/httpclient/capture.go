@@ -0,0 +1,222 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are replaced with a placeholder before a capture is written
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+}
+
+// sensitiveFields are JSON or form fields whose values are replaced before a capture is written
+var sensitiveFields = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"assertion":     true,
+	"client_secret": true,
+	"private_key":   true,
+	"password":      true,
+	"token":         true,
+}
+
+// CaptureTransport writes a sanitized copy of every request/response pair to a directory
+type CaptureTransport struct {
+	Base http.RoundTripper
+	Dir  string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewCaptureTransport wraps base so each exchange is recorded as a JSON file in dir
+func NewCaptureTransport(base http.RoundTripper, dir string) *CaptureTransport {
+	return &CaptureTransport{
+		Base: base,
+		Dir:  dir,
+	}
+}
+
+// capturedExchange is the on-disk format of a single request/response pair
+type capturedExchange struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Duration  string            `json:"duration"`
+	Request   capturedRequest   `json:"request"`
+	Response  *capturedResponse `json:"response,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type capturedRequest struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers"`
+	Body    interface{}         `json:"body,omitempty"`
+}
+
+type capturedResponse struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers"`
+	Body    interface{}         `json:"body,omitempty"`
+}
+
+// CloseIdleConnections closes idle connections of the base transport
+func (t *CaptureTransport) CloseIdleConnections() {
+	if base, ok := t.Base.(closeIdler); ok {
+		base.CloseIdleConnections()
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *CaptureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body for capture: %w", err)
+		}
+		reqBody = data
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	exchange := capturedExchange{
+		Timestamp: time.Now(),
+		Request: capturedRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: sanitizeHeaders(req.Header),
+			Body:    sanitizeBody(reqBody, req.Header.Get("Content-Type")),
+		},
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	exchange.Duration = time.Since(exchange.Timestamp).String()
+
+	if err != nil {
+		exchange.Error = err.Error()
+		t.write(req, exchange)
+		return nil, err
+	}
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	exchange.Response = &capturedResponse{
+		Status:  resp.StatusCode,
+		Headers: sanitizeHeaders(resp.Header),
+		Body:    sanitizeBody(respBody, resp.Header.Get("Content-Type")),
+	}
+	if readErr != nil {
+		exchange.Error = readErr.Error()
+	}
+	t.write(req, exchange)
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	return resp, nil
+}
+
+// write stores an exchange as NNNN-METHOD-host.json; capture failures never fail the request
+func (t *CaptureTransport) write(req *http.Request, exchange capturedExchange) {
+	t.mu.Lock()
+	t.seq++
+	seq := t.seq
+	t.mu.Unlock()
+
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(t.Dir, 0700); err != nil {
+		return
+	}
+
+	name := fmt.Sprintf("%04d-%s-%s.json", seq, req.Method, strings.ReplaceAll(req.URL.Host, ":", "_"))
+	_ = os.WriteFile(filepath.Join(t.Dir, name), data, 0600)
+}
+
+// sanitizeHeaders copies headers with credentials redacted
+func sanitizeHeaders(headers http.Header) map[string][]string {
+	result := make(map[string][]string, len(headers))
+	for key, values := range headers {
+		if sensitiveHeaders[strings.ToLower(key)] {
+			result[key] = []string{redacted}
+			continue
+		}
+		result[key] = append([]string(nil), values...)
+	}
+	return result
+}
+
+// sanitizeBody decodes JSON and form bodies so they are pretty-printed, redacting secrets
+func sanitizeBody(body []byte, contentType string) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		return redactValue(decoded)
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			form := make(map[string]interface{}, len(values))
+			for key, vals := range values {
+				if sensitiveFields[strings.ToLower(key)] {
+					form[key] = redacted
+				} else if len(vals) == 1 {
+					form[key] = vals[0]
+				} else {
+					form[key] = vals
+				}
+			}
+			return form
+		}
+	}
+
+	return string(body)
+}
+
+// redactValue walks decoded JSON replacing sensitive fields
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(inner)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(inner)
+		}
+		return v
+	default:
+		return v
+	}
+}
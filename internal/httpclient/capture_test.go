@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaptureTransport_WritesSanitizedExchange(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(`{"id":"user-1","access_token":"abc123"}`))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	client := New(Options{CaptureDir: dir})
+
+	req, _ := http.NewRequest("POST", ts.URL+"/Users", strings.NewReader(`{"userName":"alice@test.com"}`))
+	req.Header.Set("Authorization", "Bearer super-secret")
+	req.Header.Set("Content-Type", "application/scim+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if !strings.Contains(string(body), "user-1") {
+		t.Errorf("Expected caller to still receive the response body, got %q", string(body))
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 capture file, got %d", len(files))
+	}
+	if !strings.Contains(filepath.Base(files[0]), "0001-POST-") {
+		t.Errorf("Unexpected capture file name: %s", filepath.Base(files[0]))
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read capture file: %v", err)
+	}
+
+	content := string(data)
+	for _, secret := range []string{"super-secret", "session=secret", "abc123"} {
+		if strings.Contains(content, secret) {
+			t.Errorf("Capture file leaked secret %q", secret)
+		}
+	}
+
+	var exchange capturedExchange
+	if err := json.Unmarshal(data, &exchange); err != nil {
+		t.Fatalf("Capture file is not valid JSON: %v", err)
+	}
+	if exchange.Request.Method != "POST" {
+		t.Errorf("Expected method POST, got %s", exchange.Request.Method)
+	}
+	if exchange.Response == nil || exchange.Response.Status != http.StatusOK {
+		t.Errorf("Expected captured response with status 200, got %+v", exchange.Response)
+	}
+	reqBody, ok := exchange.Request.Body.(map[string]interface{})
+	if !ok || reqBody["userName"] != "alice@test.com" {
+		t.Errorf("Expected decoded request body, got %v", exchange.Request.Body)
+	}
+}
+
+func TestSanitizeBody_Form(t *testing.T) {
+	body := sanitizeBody([]byte("grant_type=jwt&assertion=eyJhbGciOi"), "application/x-www-form-urlencoded")
+
+	form, ok := body.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected form map, got %T", body)
+	}
+	if form["assertion"] != redacted {
+		t.Errorf("Expected assertion to be redacted, got %v", form["assertion"])
+	}
+	if form["grant_type"] != "jwt" {
+		t.Errorf("Expected grant_type to be preserved, got %v", form["grant_type"])
+	}
+}
@@ -0,0 +1,210 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"time"
+)
+
+// APIs faults can be limited to
+const (
+	FaultAPIGoogle         = "google"
+	FaultAPIBeyondIdentity = "bi"
+)
+
+// Faults describes failures injected into outbound requests, so integration tests and operators
+// can check retries, partial membership handling and alerting before trusting production syncs
+type Faults struct {
+	API          string        // FaultAPIGoogle or FaultAPIBeyondIdentity; empty affects both
+	RateLimited  float64       // Share of requests answered with 429 Too Many Requests
+	ServerError  float64       // Share of requests answered with 503 Service Unavailable
+	Latency      time.Duration // Delay added before every affected request
+	PartialPages float64       // Share of requests for a second or later page answered with 503
+	Seed         int64         // Seeds the random choices so a run can be repeated; 0 picks one
+}
+
+// faults are injected by every client built after SetFaults; nil injects none
+var faults *Faults
+
+// SetFaults injects f into the clients built from now on; nil stops injecting faults
+func SetFaults(f *Faults) {
+	faults = f
+}
+
+// ParseFaults parses a comma-separated fault spec such as "api=google,429=0.2,5xx=0.05,
+// latency=500ms,partial=0.5,seed=42"; shares are between 0 and 1
+func ParseFaults(spec string) (*Faults, error) {
+	f := &Faults{}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fault %q: expected key=value", field)
+		}
+
+		var err error
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "api":
+			f.API = strings.ToLower(strings.TrimSpace(value))
+			if f.API != FaultAPIGoogle && f.API != FaultAPIBeyondIdentity {
+				return nil, fmt.Errorf("invalid fault api %q: expected %s or %s", value, FaultAPIGoogle, FaultAPIBeyondIdentity)
+			}
+		case "429":
+			f.RateLimited, err = parseShare(value)
+		case "5xx":
+			f.ServerError, err = parseShare(value)
+		case "partial":
+			f.PartialPages, err = parseShare(value)
+		case "latency":
+			f.Latency, err = time.ParseDuration(strings.TrimSpace(value))
+			if err == nil && f.Latency < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		case "seed":
+			f.Seed, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		default:
+			return nil, fmt.Errorf("unknown fault %q: expected api, 429, 5xx, latency, partial or seed", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid fault %s: %w", field, err)
+		}
+	}
+	return f, nil
+}
+
+func parseShare(value string) (float64, error) {
+	share, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, err
+	}
+	if share < 0 || share > 1 {
+		return 0, fmt.Errorf("must be between 0 and 1")
+	}
+	return share, nil
+}
+
+// String describes the faults for log messages
+func (f *Faults) String() string {
+	api := "all APIs"
+	switch f.API {
+	case FaultAPIGoogle:
+		api = "Google APIs"
+	case FaultAPIBeyondIdentity:
+		api = "Beyond Identity APIs"
+	}
+	return fmt.Sprintf("%s: %g%% 429, %g%% 5xx, %g%% of later pages failed, %s latency",
+		api, f.RateLimited*100, f.ServerError*100, f.PartialPages*100, f.Latency)
+}
+
+// FaultTransport answers some requests made through Base with injected failures instead of
+// sending them
+type FaultTransport struct {
+	Base   http.RoundTripper
+	Faults Faults
+
+	mu   gosync.Mutex
+	rand *rand.Rand
+}
+
+// NewFaultTransport wraps base so it injects f
+func NewFaultTransport(base http.RoundTripper, f Faults) *FaultTransport {
+	seed := f.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &FaultTransport{Base: base, Faults: f, rand: rand.New(rand.NewSource(seed))}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.affects(req) {
+		return t.Base.RoundTrip(req)
+	}
+
+	if t.Faults.Latency > 0 {
+		timer := time.NewTimer(t.Faults.Latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case t.roll(t.Faults.RateLimited):
+		return injectedResponse(req, http.StatusTooManyRequests), nil
+	case t.roll(t.Faults.ServerError):
+		return injectedResponse(req, http.StatusServiceUnavailable), nil
+	case isLaterPage(req) && t.roll(t.Faults.PartialPages):
+		return injectedResponse(req, http.StatusServiceUnavailable), nil
+	}
+	return t.Base.RoundTrip(req)
+}
+
+// CloseIdleConnections closes idle connections of the base transport
+func (t *FaultTransport) CloseIdleConnections() {
+	if base, ok := t.Base.(closeIdler); ok {
+		base.CloseIdleConnections()
+	}
+}
+
+// affects reports whether faults apply to the API req is sent to; Google APIs, including their
+// token endpoint, are served from googleapis.com and everything else is Beyond Identity
+func (t *FaultTransport) affects(req *http.Request) bool {
+	google := strings.HasSuffix(req.URL.Hostname(), ".googleapis.com")
+	switch t.Faults.API {
+	case FaultAPIGoogle:
+		return google
+	case FaultAPIBeyondIdentity:
+		return !google
+	}
+	return true
+}
+
+func (t *FaultTransport) roll(share float64) bool {
+	if share <= 0 {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rand.Float64() < share
+}
+
+// isLaterPage reports whether req continues a paginated listing
+func isLaterPage(req *http.Request) bool {
+	query := req.URL.Query()
+	if query.Get("pageToken") != "" || query.Get("page_token") != "" {
+		return true
+	}
+	start, err := strconv.Atoi(query.Get("startIndex"))
+	return err == nil && start > 1
+}
+
+// injectedResponse builds the response of an injected failure; 429s ask to retry after a second
+func injectedResponse(req *http.Request, status int) *http.Response {
+	body := fmt.Sprintf(`{"error":{"code":%d,"message":"fault injected by scim-sync"}}`, status)
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	if status == http.StatusTooManyRequests {
+		header.Set("Retry-After", "1")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
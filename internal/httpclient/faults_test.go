@@ -0,0 +1,111 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseFaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expected    Faults
+		expectError bool
+	}{
+		{name: "all", spec: "api=google, 429=0.2,5xx=0.05,latency=500ms,partial=1,seed=42", expected: Faults{
+			API: FaultAPIGoogle, RateLimited: 0.2, ServerError: 0.05, Latency: 500 * time.Millisecond, PartialPages: 1, Seed: 42,
+		}},
+		{name: "empty", spec: "", expected: Faults{}},
+		{name: "unknown api", spec: "api=okta", expectError: true},
+		{name: "share above 1", spec: "429=1.5", expectError: true},
+		{name: "negative latency", spec: "latency=-1s", expectError: true},
+		{name: "unknown fault", spec: "timeout=0.1", expectError: true},
+		{name: "missing value", spec: "429", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFaults(tt.spec)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if *got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *got)
+			}
+		})
+	}
+}
+
+func TestFaultTransport(t *testing.T) {
+	var served int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	get := func(client *http.Client, url string) int {
+		t.Helper()
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	client := New(Options{Faults: &Faults{RateLimited: 1}})
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" {
+		t.Errorf("Expected an injected 429 with Retry-After, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if served != 0 {
+		t.Errorf("Expected the injected request not to be sent, got %d", served)
+	}
+
+	// Only pages after the first fail, so listings come back partial
+	client = New(Options{Faults: &Faults{PartialPages: 1}})
+	if status := get(client, server.URL+"/users"); status != http.StatusOK {
+		t.Errorf("Expected the first page to succeed, got %d", status)
+	}
+	if status := get(client, server.URL+"/users?pageToken=abc"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected a later page to fail, got %d", status)
+	}
+
+	// The test server is not a Google API, so Google-only faults leave it alone
+	client = New(Options{Faults: &Faults{API: FaultAPIGoogle, ServerError: 1}})
+	if status := get(client, server.URL); status != http.StatusOK {
+		t.Errorf("Expected Beyond Identity requests to be unaffected, got %d", status)
+	}
+}
+
+func TestFaultTransport_LatencyHonorsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := New(Options{Faults: &Faults{Latency: time.Minute}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	start := time.Now()
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("Expected the request to be cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to cut the injected latency short, took %v", elapsed)
+	}
+}
@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	gosync "sync"
+)
+
+// HostLimiter caps the requests in flight to each API host. Clients built from the same options
+// share it, so the engine's parallel workers together never hold more than the limit of
+// connections to api.byndid.com or googleapis.com
+type HostLimiter struct {
+	limit int
+
+	mu    gosync.Mutex
+	slots map[string]chan struct{} // Host -> semaphore holding one token per request in flight
+}
+
+// NewHostLimiter creates a limiter allowing limit requests in flight per host; it returns nil,
+// which limits nothing, when limit is not positive
+func NewHostLimiter(limit int) *HostLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &HostLimiter{limit: limit, slots: make(map[string]chan struct{})}
+}
+
+func (l *HostLimiter) semaphore(host string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slots, ok := l.slots[host]
+	if !ok {
+		slots = make(chan struct{}, l.limit)
+		l.slots[host] = slots
+	}
+	return slots
+}
+
+// HostLimitTransport waits for a free slot of the request's host before sending it through Base.
+// The slot is held until the response body is read to the end or closed, as the connection stays
+// busy until then
+type HostLimitTransport struct {
+	Base    http.RoundTripper
+	Limiter *HostLimiter
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *HostLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	slots := t.Limiter.semaphore(req.URL.Host)
+	select {
+	case slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	release := gosync.OnceFunc(func() { <-slots })
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{body: resp.Body, release: release}
+	return resp, nil
+}
+
+// CloseIdleConnections closes idle connections of the base transport
+func (t *HostLimitTransport) CloseIdleConnections() {
+	if base, ok := t.Base.(closeIdler); ok {
+		base.CloseIdleConnections()
+	}
+}
+
+// releasingBody frees the request's slot once the body is drained or closed
+type releasingBody struct {
+	body    io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if err == io.EOF {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *releasingBody) Close() error {
+	err := b.body.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	gosync "sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostLimitTransport(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// Two clients sharing the options share the limit, like the Google and Beyond Identity clients
+	opts := Options{HostLimiter: NewHostLimiter(2)}
+	clients := []*http.Client{New(opts), New(opts)}
+
+	var wg gosync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(client *http.Client) {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			_, _ = io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+		}(clients[i%2])
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", got)
+	}
+}
+
+func TestHostLimitTransport_WaitHonorsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := New(Options{HostLimiter: NewHostLimiter(1)})
+	held, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The first body is still open, so the second request waits for its slot until cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("Expected the request to wait for a free slot")
+	}
+
+	_ = held.Body.Close()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected closing the body to free the slot, got %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestNewHostLimiter_Unlimited(t *testing.T) {
+	if NewHostLimiter(0) != nil {
+		t.Error("Expected no limiter for a limit of 0")
+	}
+}
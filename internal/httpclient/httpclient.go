@@ -0,0 +1,268 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// DefaultTimeout is the request timeout used when none is configured
+const DefaultTimeout = 30 * time.Second
+
+// DefaultMaxResponseBytes is the largest decoded response body accepted when none is configured
+const DefaultMaxResponseBytes = config.DefaultMaxResponseBytes
+
+// userAgent identifies this integration to the Google and Beyond Identity APIs; see SetVersion
+var userAgent = buildUserAgent("dev", "unknown")
+
+// SetVersion sets the build reported in the User-Agent header of outbound requests
+func SetVersion(version, commit string) {
+	userAgent = buildUserAgent(version, commit)
+}
+
+// UserAgent returns the User-Agent sent on outbound requests
+func UserAgent() string {
+	return userAgent
+}
+
+func buildUserAgent(version, commit string) string {
+	return fmt.Sprintf("scim-sync/%s (commit %s; %s; %s/%s)", version, commit, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// ErrResponseTooLarge is returned when reading a response body that exceeds the configured limit
+var ErrResponseTooLarge = errors.New("response body exceeds maximum allowed size")
+
+// Options controls how outbound HTTP clients are built
+type Options struct {
+	Timeout          time.Duration
+	MaxResponseBytes int64
+	CaptureDir       string            // When set, sanitized request/response pairs are written here
+	TLSConfig        *tls.Config       // When set, replaces the default TLS settings
+	Headers          map[string]string // Extra headers added to every request, e.g. correlation IDs
+	Observer         CallObserver      // When set, told about every request, e.g. to publish API call metrics
+	Faults           *Faults           // When set, injected into requests; defaults to those set with SetFaults
+	HostLimiter      *HostLimiter      // When set, caps the requests in flight per host across every client sharing it
+	Retry            *RetryPolicy      // When set, rate limited and transiently failed requests are retried
+}
+
+// OptionsFromConfig builds client options from the network section of the configuration
+func OptionsFromConfig(cfg *config.Config) (Options, error) {
+	tlsConfig, err := TLSConfig(cfg.Network.CABundle, cfg.Network.InsecureSkipVerify)
+	if err != nil {
+		return Options{}, err
+	}
+
+	return Options{
+		Timeout:          DefaultTimeout,
+		MaxResponseBytes: cfg.Network.MaxResponseBytes,
+		TLSConfig:        tlsConfig,
+		Headers:          cfg.Network.Headers,
+		HostLimiter:      NewHostLimiter(cfg.Network.MaxConnsPerHost),
+		Retry:            RetryPolicyFromConfig(cfg.Sync.Retry),
+	}, nil
+}
+
+// New creates an HTTP client that negotiates gzip and guards response sizes
+func New(opts Options) *http.Client {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	base := http.DefaultTransport
+	if opts.TLSConfig != nil {
+		custom := http.DefaultTransport.(*http.Transport).Clone()
+		custom.TLSClientConfig = opts.TLSConfig
+		base = custom
+	}
+	if opts.HostLimiter != nil {
+		base = &HostLimitTransport{Base: base, Limiter: opts.HostLimiter}
+	}
+
+	// Injected failures replace real responses, so everything above sees them like API errors
+	injected := opts.Faults
+	if injected == nil {
+		injected = faults
+	}
+	if injected != nil {
+		base = NewFaultTransport(base, *injected)
+	}
+
+	gzipTransport := NewTransport(base, opts.MaxResponseBytes)
+	gzipTransport.Headers = opts.Headers
+
+	var transport http.RoundTripper = gzipTransport
+	if opts.CaptureDir != "" {
+		// Capture sits outside the gzip transport so recorded bodies are already decoded
+		transport = NewCaptureTransport(transport, opts.CaptureDir)
+	}
+	if opts.Observer != nil {
+		transport = &ObserveTransport{Base: transport, Observer: opts.Observer}
+	}
+
+	// Retries sit outside everything else, so each attempt is observed, captured and limited.
+	// The timeout then applies per attempt rather than across the waits between them
+	if opts.Retry != nil && opts.Retry.MaxAttempts > 1 {
+		transport = &RetryTransport{Base: transport, Policy: *opts.Retry, Timeout: timeout}
+		timeout = 0
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// NewFromConfig creates an HTTP client using the network settings from the configuration
+func NewFromConfig(cfg *config.Config) (*http.Client, error) {
+	opts, err := OptionsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return New(opts), nil
+}
+
+// OAuthContext returns a context from which the oauth2 package picks up base, so token
+// requests go through the same client as API requests
+func OAuthContext(base *http.Client) context.Context {
+	return context.WithValue(context.Background(), oauth2.HTTPClient, base)
+}
+
+// Transport requests gzip-compressed responses, transparently decompresses them
+// and limits how many decoded bytes may be read from a response body. It also
+// identifies the integration in the User-Agent header and adds any extra headers
+type Transport struct {
+	Base             http.RoundTripper
+	MaxResponseBytes int64
+	Headers          map[string]string
+}
+
+// NewTransport wraps base with gzip negotiation and a response size guard
+func NewTransport(base http.RoundTripper, maxResponseBytes int64) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if maxResponseBytes <= 0 {
+		maxResponseBytes = DefaultMaxResponseBytes
+	}
+
+	return &Transport{
+		Base:             base,
+		MaxResponseBytes: maxResponseBytes,
+	}
+}
+
+// closeIdler is implemented by transports that pool connections
+type closeIdler interface {
+	CloseIdleConnections()
+}
+
+// CloseIdleConnections closes idle connections of the base transport, so
+// http.Client.CloseIdleConnections reaches the pooled connections
+func (t *Transport) CloseIdleConnections() {
+	if base, ok := t.Base.(closeIdler); ok {
+		base.CloseIdleConnections()
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Setting Accept-Encoding explicitly disables the standard library's own
+	// transparent decompression, so we handle gzip ourselves below
+	req = req.Clone(req.Context())
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	// Keep the identifier of any client library, e.g. google-api-go-client, after ours
+	if existing := req.Header.Get("User-Agent"); existing != "" {
+		req.Header.Set("User-Agent", userAgent+" "+existing)
+	} else {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for name, value := range t.Headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") && req.Method != http.MethodHead {
+		resp.Body = &gzipBody{body: resp.Body}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+
+	if resp.ContentLength > t.MaxResponseBytes {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: %d bytes from %s (limit %d)", ErrResponseTooLarge, resp.ContentLength, req.URL.Host, t.MaxResponseBytes)
+	}
+
+	resp.Body = &limitedBody{body: resp.Body, remaining: t.MaxResponseBytes}
+	return resp, nil
+}
+
+// gzipBody lazily decompresses a gzip-encoded response body
+type gzipBody struct {
+	body   io.ReadCloser
+	reader *gzip.Reader
+	err    error
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.reader == nil && g.err == nil {
+		g.reader, g.err = gzip.NewReader(g.body)
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+	return g.reader.Read(p)
+}
+
+func (g *gzipBody) Close() error {
+	return g.body.Close()
+}
+
+// limitedBody fails reads once more than the allowed number of bytes has been consumed
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+
+	// Read one byte past the limit so oversized bodies are detected rather than truncated
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.body.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrResponseTooLarge
+	}
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
@@ -0,0 +1,250 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestTransport_DecompressesGzip(t *testing.T) {
+	payload := `{"totalResults": 1}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Expected Accept-Encoding gzip, got '%s'", r.Header.Get("Accept-Encoding"))
+		}
+
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write([]byte(payload))
+		_ = gz.Close()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer ts.Close()
+
+	client := New(Options{})
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+
+	if string(body) != payload {
+		t.Errorf("Expected body %q, got %q", payload, string(body))
+	}
+	if resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("Expected Content-Encoding to be removed, got '%s'", resp.Header.Get("Content-Encoding"))
+	}
+}
+
+func TestTransport_PlainResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("plain"))
+	}))
+	defer ts.Close()
+
+	resp, err := New(Options{}).Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "plain" {
+		t.Errorf("Expected body 'plain', got %q", string(body))
+	}
+}
+
+func TestTransport_RejectsLargeContentLength(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer ts.Close()
+
+	_, err := New(Options{MaxResponseBytes: 10}).Get(ts.URL)
+	if err == nil {
+		t.Fatal("Expected error for oversized response, got nil")
+	}
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestTransport_LimitsDecompressedBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write([]byte(strings.Repeat("a", 10000)))
+		_ = gz.Close()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer ts.Close()
+
+	resp, err := New(Options{MaxResponseBytes: 1000}).Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	_, err = io.ReadAll(resp.Body)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestTransport_RespectsExistingAcceptEncoding(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "identity" {
+			t.Errorf("Expected Accept-Encoding identity, got '%s'", r.Header.Get("Accept-Encoding"))
+		}
+	}))
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept-Encoding", "identity")
+
+	resp, err := New(Options{}).Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestNew_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0600); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		caBundle  string
+		insecure  bool
+		expectErr bool
+	}{
+		{"system roots reject test CA", "", false, true},
+		{"bundle trusts test CA", bundle, false, false},
+		{"verification disabled", "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := TLSConfig(tt.caBundle, tt.insecure)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, err := New(Options{TLSConfig: tlsConfig}).Get(server.URL)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestTLSConfig_InvalidBundle(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(bundle, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	if _, err := TLSConfig(bundle, false); err == nil {
+		t.Error("Expected an error for a bundle without certificates")
+	}
+	if _, err := TLSConfig(filepath.Join(t.TempDir(), "missing.pem"), false); err == nil {
+		t.Error("Expected an error for a missing bundle")
+	}
+	if tlsConfig, err := TLSConfig("", false); tlsConfig != nil || err != nil {
+		t.Errorf("Expected default TLS settings, got %v, %v", tlsConfig, err)
+	}
+}
+
+func TestTransport_IdentifiesIntegration(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	SetVersion("1.2.3", "abc123")
+	defer SetVersion("dev", "unknown")
+
+	client := New(Options{Headers: map[string]string{"X-Correlation-ID": "acme-scim", "X-Team": "iam"}})
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("User-Agent", "google-api-go-client/0.5")
+	req.Header.Set("X-Team", "caller")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if ua := got.Get("User-Agent"); !strings.HasPrefix(ua, "scim-sync/1.2.3 (commit abc123;") || !strings.HasSuffix(ua, " google-api-go-client/0.5") {
+		t.Errorf("Unexpected User-Agent: %q", ua)
+	}
+	if got.Get("X-Correlation-ID") != "acme-scim" {
+		t.Errorf("Expected the correlation header, got %q", got.Get("X-Correlation-ID"))
+	}
+	if got.Get("X-Team") != "caller" {
+		t.Errorf("Expected headers set by the caller to win, got %q", got.Get("X-Team"))
+	}
+	if req.Header.Get("X-Correlation-ID") != "" {
+		t.Error("Expected the caller's request not to be modified")
+	}
+}
+
+// idleTransport records CloseIdleConnections calls
+type idleTransport struct {
+	http.RoundTripper
+	closed int
+}
+
+func (t *idleTransport) CloseIdleConnections() {
+	t.closed++
+}
+
+func TestTransport_CloseIdleConnections(t *testing.T) {
+	base := &idleTransport{RoundTripper: http.DefaultTransport}
+	client := &http.Client{Transport: NewCaptureTransport(NewTransport(base, 0), t.TempDir())}
+
+	client.CloseIdleConnections()
+	if base.closed != 1 {
+		t.Errorf("Expected idle connections of the base transport to be closed, got %d calls", base.closed)
+	}
+}
+
+func TestOAuthContext(t *testing.T) {
+	base := &http.Client{}
+	if got := OAuthContext(base).Value(oauth2.HTTPClient); got != base {
+		t.Errorf("Expected the oauth2 package to pick up the base client, got %v", got)
+	}
+}
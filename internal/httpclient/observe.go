@@ -0,0 +1,31 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// CallObserver is told about every outbound request once its response headers arrive or it
+// fails; resp is nil when err is set
+type CallObserver func(req *http.Request, resp *http.Response, duration time.Duration, err error)
+
+// ObserveTransport reports each request made through Base to Observer
+type ObserveTransport struct {
+	Base     http.RoundTripper
+	Observer CallObserver
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ObserveTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	t.Observer(req, resp, time.Since(start), err)
+	return resp, err
+}
+
+// CloseIdleConnections closes idle connections of the base transport
+func (t *ObserveTransport) CloseIdleConnections() {
+	if base, ok := t.Base.(closeIdler); ok {
+		base.CloseIdleConnections()
+	}
+}
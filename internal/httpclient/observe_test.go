@@ -0,0 +1,39 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew_Observer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var calls []int
+	client := New(Options{Observer: func(req *http.Request, resp *http.Response, duration time.Duration, err error) {
+		if err != nil {
+			calls = append(calls, -1)
+			return
+		}
+		calls = append(calls, resp.StatusCode)
+	}})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	server.Close()
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("Expected an error from a closed server")
+	}
+
+	if len(calls) != 2 || calls[0] != http.StatusTooManyRequests || calls[1] != -1 {
+		t.Errorf("Expected a 429 and a failed call to be observed, got %v", calls)
+	}
+}
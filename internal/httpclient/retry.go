@@ -0,0 +1,204 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// RetryPolicy decides how requests answered with rate limiting or a transient error are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts per request, including the first; 1 or less disables retries
+	BaseDelay   time.Duration // Backoff before the second attempt, doubled after each attempt
+	MaxDelay    time.Duration // Longest backoff, and longest Retry-After honored; 0 is unlimited
+	Budget      time.Duration // Total wait between the attempts of one request; 0 is unlimited
+}
+
+// RetryPolicyFromConfig builds the retry policy of sync.retry
+func RetryPolicyFromConfig(cfg config.RetryConfig) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   cfg.BaseDelayDuration(),
+		MaxDelay:    cfg.MaxDelayDuration(),
+		Budget:      cfg.BudgetDuration(),
+	}
+}
+
+// backoff returns the jittered wait before attempt+1: between half and all of the exponential delay
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// RetryTransport repeats requests that were rate limited (429), failed with 502, 503 or 504, or
+// failed on the network, honoring Retry-After and otherwise backing off exponentially with jitter.
+// Server errors and network failures are only retried for idempotent methods, as the first attempt
+// may have been applied. A response that is not retried, including the last one, is returned as is.
+// Timeout applies to each attempt, including reading its response body, in place of the client's
+// timeout, which would otherwise cover the waits between attempts too
+type RetryTransport struct {
+	Base    http.RoundTripper
+	Policy  RetryPolicy
+	Timeout time.Duration
+	sleep   func(req *http.Request, d time.Duration) error // Waits, or fails when the request is cancelled; replaced in tests
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		resp, cancel, err := t.attempt(req)
+		if attempt >= t.Policy.MaxAttempts || !retryable(req, resp, err) {
+			return finishAttempt(resp, err, cancel)
+		}
+
+		delay := t.Policy.backoff(attempt)
+		if after, ok := retryAfter(resp); ok {
+			if t.Policy.MaxDelay > 0 && after > t.Policy.MaxDelay {
+				return finishAttempt(resp, err, cancel) // The API asked for a longer wait than allowed
+			}
+			delay = after
+		}
+		if t.Policy.Budget > 0 && waited+delay > t.Policy.Budget {
+			return finishAttempt(resp, err, cancel)
+		}
+
+		// The request is sent again with a fresh body
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return finishAttempt(resp, err, cancel)
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return finishAttempt(resp, err, cancel)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		cancel()
+
+		if sleepErr := t.wait(req, delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+		waited += delay
+	}
+}
+
+// attempt sends req once under the per-attempt timeout, returning the function releasing it
+func (t *RetryTransport) attempt(req *http.Request) (*http.Response, context.CancelFunc, error) {
+	if t.Timeout <= 0 {
+		resp, err := t.Base.RoundTrip(req)
+		return resp, func() {}, err
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.Timeout)
+	resp, err := t.Base.RoundTrip(req.WithContext(ctx))
+	return resp, cancel, err
+}
+
+// finishAttempt returns the outcome of the last attempt, keeping its timeout running until the
+// response body is closed
+func finishAttempt(resp *http.Response, err error, cancel context.CancelFunc) (*http.Response, error) {
+	if resp == nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, err
+}
+
+// cancelBody releases the timeout of an attempt once its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (t *RetryTransport) wait(req *http.Request, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(req, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// CloseIdleConnections closes idle connections of the base transport
+func (t *RetryTransport) CloseIdleConnections() {
+	if base, ok := t.Base.(closeIdler); ok {
+		base.CloseIdleConnections()
+	}
+}
+
+// retryable reports whether a request may succeed if sent again
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		var netErr net.Error
+		return idempotent(req.Method) && (errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF))
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true // Rejected before it was processed
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+// idempotent reports whether repeating a request with method has the same effect as sending it once
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait a response asks for in its Retry-After header, as seconds or a date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,141 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingSleep records the waits of a retry transport instead of sleeping
+func recordingSleep(waits *[]time.Duration) func(*http.Request, time.Duration) error {
+	return func(_ *http.Request, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+}
+
+func TestRetryTransport_HonorsRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("Expected the body to be sent with every attempt, got %q", body)
+		}
+		if requests.Add(1) < 3 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	transport := &RetryTransport{
+		Base:   http.DefaultTransport,
+		Policy: RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Second},
+		sleep:  recordingSleep(&waits),
+	}
+	client := &http.Client{Transport: transport}
+
+	// Rate limited requests are retried whatever their method
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK || requests.Load() != 3 {
+		t.Errorf("Expected success on the third attempt, got %d after %d", resp.StatusCode, requests.Load())
+	}
+	if len(waits) != 2 || waits[0] != 2*time.Second || waits[1] != 2*time.Second {
+		t.Errorf("Expected to wait as Retry-After asked, got %v", waits)
+	}
+}
+
+func TestRetryTransport_Limits(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		status     int
+		retryAfter string
+		policy     RetryPolicy
+		wantCalls  int32
+	}{
+		{"gives up after max attempts", http.MethodGet, http.StatusServiceUnavailable, "", RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second}, 3},
+		{"server errors on POST are not retried", http.MethodPost, http.StatusServiceUnavailable, "", RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second}, 1},
+		{"client errors are not retried", http.MethodGet, http.StatusBadRequest, "", RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second}, 1},
+		{"Retry-After beyond max delay", http.MethodGet, http.StatusTooManyRequests, "120", RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}, 1},
+		{"budget exhausted", http.MethodGet, http.StatusTooManyRequests, "20", RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, Budget: 30 * time.Second}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			client := &http.Client{Transport: &RetryTransport{Base: http.DefaultTransport, Policy: tt.policy, sleep: recordingSleep(&waits)}}
+			req, _ := http.NewRequest(tt.method, server.URL, nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected the last response to be returned, got %d", resp.StatusCode)
+			}
+			if got := requests.Load(); got != tt.wantCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if got := policy.backoff(attempt); got < want/2 || got > want {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", attempt, got, want/2, want)
+			}
+		}
+	}
+}
+
+func TestRetryTransport_TimeoutPerAttempt(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// The first attempt times out and is retried; the client itself has no timeout
+	client := New(Options{Timeout: 50 * time.Millisecond, Retry: &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}})
+	if client.Timeout != 0 {
+		t.Errorf("Expected the timeout to move to the retry transport, got %s", client.Timeout)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "ok" || requests.Load() != 2 {
+		t.Errorf("Expected the second attempt to succeed, got %q after %d attempts", body, requests.Load())
+	}
+}
@@ -0,0 +1,194 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// ErrGroupNotFound is returned when a configured group does not exist in the directory
+var ErrGroupNotFound = errors.New("group not found in LDAP directory")
+
+const (
+	// requestTimeout bounds connecting to the directory and each request made to it
+	requestTimeout = 30 * time.Second
+
+	// searchPageSize is the number of members read per page, within Active Directory's default MaxPageSize
+	searchPageSize = 500
+
+	// matchingRuleInChain makes an Active Directory memberOf filter match members of nested groups
+	matchingRuleInChain = "1.2.840.113556.1.4.1941"
+
+	// accountDisabled is the ACCOUNTDISABLE flag of Active Directory's userAccountControl
+	accountDisabled = 0x2
+)
+
+// conn is the part of an LDAP connection the source uses
+type conn interface {
+	Search(request *goldap.SearchRequest) (*goldap.SearchResult, error)
+	SearchWithPaging(request *goldap.SearchRequest, pagingSize uint32) (*goldap.SearchResult, error)
+	Close() error
+}
+
+// Source serves group membership from LDAP groups, such as Active Directory groups, read over
+// LDAPS with a bind account. Groups are identified by their DNs, and members are found by
+// searching base_dn for users whose memberOf holds the group.
+type Source struct {
+	cfg  config.LDAPSourceConfig
+	dial func(ctx context.Context) (conn, error)
+}
+
+// NewSource creates an LDAP source; the directory is connected to for each request
+func NewSource(cfg config.LDAPSourceConfig) *Source {
+	s := &Source{cfg: cfg}
+	s.dial = s.connect
+	return s
+}
+
+// GetGroup returns the group with the given DN, named after its group_name_attribute
+func (s *Source) GetGroup(ctx context.Context, dn string) (*gws.Group, error) {
+	c, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = c.Close() }()
+
+	nameAttribute := attributeOrDefault(s.cfg.GroupNameAttribute, config.DefaultLDAPGroupNameAttribute)
+	result, err := c.Search(goldap.NewSearchRequest(dn, goldap.ScopeBaseObject, goldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)", []string{nameAttribute, "description"}, nil))
+	if err != nil {
+		return nil, groupError(dn, err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, dn)
+	}
+
+	entry := result.Entries[0]
+	name := entry.GetAttributeValue(nameAttribute)
+	if name == "" {
+		name = dn
+	}
+	return &gws.Group{
+		ID:          dn,
+		Email:       dn,
+		Name:        name,
+		Description: entry.GetAttributeValue("description"),
+	}, nil
+}
+
+// GetGroupMembers returns the users in the group with the given DN, and in its nested groups when
+// nested is set. Members are identified by their email_attribute, or by username_attribute at
+// username_domain when they have no email; members with neither are left out. Accounts disabled
+// in Active Directory are reported as suspended
+func (s *Source) GetGroupMembers(ctx context.Context, dn string) ([]*gws.GroupMember, error) {
+	c, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = c.Close() }()
+
+	memberOf := "memberOf"
+	if s.cfg.Nested {
+		memberOf += ":" + matchingRuleInChain + ":"
+	}
+	userFilter := attributeOrDefault(s.cfg.UserFilter, config.DefaultLDAPUserFilter)
+	filter := fmt.Sprintf("(&%s(%s=%s))", userFilter, memberOf, goldap.EscapeFilter(dn))
+
+	emailAttribute := attributeOrDefault(s.cfg.EmailAttribute, config.DefaultLDAPEmailAttribute)
+	usernameAttribute := attributeOrDefault(s.cfg.UsernameAttribute, config.DefaultLDAPUsernameAttribute)
+	result, err := c.SearchWithPaging(goldap.NewSearchRequest(s.cfg.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		filter, []string{emailAttribute, usernameAttribute, "userAccountControl"}, nil), searchPageSize)
+	if err != nil {
+		return nil, groupError(dn, err)
+	}
+
+	var members []*gws.GroupMember
+	seen := make(map[string]bool)
+	for _, entry := range result.Entries {
+		email := strings.ToLower(strings.TrimSpace(entry.GetAttributeValue(emailAttribute)))
+		if email == "" && s.cfg.UsernameDomain != "" {
+			if username := strings.TrimSpace(entry.GetAttributeValue(usernameAttribute)); username != "" {
+				email = strings.ToLower(username + "@" + s.cfg.UsernameDomain)
+			}
+		}
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		status := "ACTIVE"
+		if control, err := strconv.ParseInt(entry.GetAttributeValue("userAccountControl"), 10, 64); err == nil && control&accountDisabled != 0 {
+			status = "SUSPENDED"
+		}
+		members = append(members, &gws.GroupMember{
+			ID:     email,
+			Email:  email,
+			Role:   "MEMBER",
+			Type:   "USER",
+			Status: status,
+		})
+	}
+
+	return members, nil
+}
+
+// connect dials the directory over LDAPS and binds as the configured account
+func (s *Source) connect(ctx context.Context) (conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if s.cfg.CABundle != "" {
+		pem, err := os.ReadFile(s.cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LDAP CA bundle: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in LDAP CA bundle %s", s.cfg.CABundle)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	c, err := goldap.DialURL(s.cfg.URL,
+		goldap.DialWithTLSConfig(tlsConfig),
+		goldap.DialWithDialer(&net.Dialer{Timeout: requestTimeout}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	c.SetTimeout(requestTimeout)
+
+	if err := c.Bind(s.cfg.BindDN, s.cfg.BindPassword); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("failed to bind as %s: %w", s.cfg.BindDN, err)
+	}
+	return c, nil
+}
+
+// groupError reports a deleted group as ErrGroupNotFound, so the engine treats it as orphaned
+func groupError(dn string, err error) error {
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, dn)
+	}
+	return fmt.Errorf("failed to read group %s: %w", dn, err)
+}
+
+// attributeOrDefault returns value, or fallback when it is not configured
+func attributeOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,113 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+const engineeringDN = "CN=Engineering (US),OU=Groups,DC=corp,DC=example,DC=com"
+
+// fakeConn serves the Engineering group and its members, recording the member search filter
+type fakeConn struct {
+	filter string
+	closed bool
+}
+
+func (f *fakeConn) Search(request *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	if request.BaseDN != engineeringDN {
+		return nil, goldap.NewError(goldap.LDAPResultNoSuchObject, errors.New("no such object"))
+	}
+	return &goldap.SearchResult{Entries: []*goldap.Entry{
+		goldap.NewEntry(engineeringDN, map[string][]string{"cn": {"Engineering (US)"}, "description": {"All engineers"}}),
+	}}, nil
+}
+
+func (f *fakeConn) SearchWithPaging(request *goldap.SearchRequest, pagingSize uint32) (*goldap.SearchResult, error) {
+	f.filter = request.Filter
+	return &goldap.SearchResult{Entries: []*goldap.Entry{
+		goldap.NewEntry("CN=Alice,OU=Users,DC=corp,DC=example,DC=com", map[string][]string{"mail": {"Alice@Example.com"}, "userAccountControl": {"512"}}),
+		goldap.NewEntry("CN=Bob,OU=Users,DC=corp,DC=example,DC=com", map[string][]string{"sAMAccountName": {"bob"}, "userAccountControl": {"514"}}),
+		goldap.NewEntry("CN=Alice Admin,OU=Users,DC=corp,DC=example,DC=com", map[string][]string{"mail": {"alice@example.com"}}),
+	}}, nil
+}
+
+func (f *fakeConn) Close() error {
+	f.closed = true
+	return nil
+}
+
+func newTestSource(cfg config.LDAPSourceConfig) (*Source, *fakeConn) {
+	fake := &fakeConn{}
+	source := NewSource(cfg)
+	source.dial = func(ctx context.Context) (conn, error) { return fake, nil }
+	return source, fake
+}
+
+func TestGetGroup(t *testing.T) {
+	source, fake := newTestSource(config.LDAPSourceConfig{})
+
+	group, err := source.GetGroup(context.Background(), engineeringDN)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if group.Email != engineeringDN || group.Name != "Engineering (US)" || group.Description != "All engineers" {
+		t.Errorf("Expected the group keyed by its DN, got %+v", group)
+	}
+	if !fake.closed {
+		t.Error("Expected the connection to be closed")
+	}
+
+	_, err = source.GetGroup(context.Background(), "CN=Deleted,OU=Groups,DC=corp,DC=example,DC=com")
+	if !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound for a deleted group, got %v", err)
+	}
+}
+
+func TestGetGroupMembers(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.LDAPSourceConfig
+		filter string
+		want   string
+	}{
+		{
+			name:   "direct members with email only",
+			cfg:    config.LDAPSourceConfig{BaseDN: "DC=corp,DC=example,DC=com"},
+			filter: `(&(objectClass=person)(memberOf=CN=Engineering \28US\29,OU=Groups,DC=corp,DC=example,DC=com))`,
+			want:   "alice@example.com:ACTIVE",
+		},
+		{
+			name:   "nested members with usernames at a domain",
+			cfg:    config.LDAPSourceConfig{BaseDN: "DC=corp,DC=example,DC=com", Nested: true, UsernameDomain: "example.com"},
+			filter: `(&(objectClass=person)(memberOf:1.2.840.113556.1.4.1941:=CN=Engineering \28US\29,OU=Groups,DC=corp,DC=example,DC=com))`,
+			want:   "alice@example.com:ACTIVE,bob@example.com:SUSPENDED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, fake := newTestSource(tt.cfg)
+
+			members, err := source.GetGroupMembers(context.Background(), engineeringDN)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fake.filter != tt.filter {
+				t.Errorf("Expected filter %s, got %s", tt.filter, fake.filter)
+			}
+
+			var got []string
+			for _, member := range members {
+				got = append(got, member.Email+":"+member.Status)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, strings.Join(got, ","))
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Process modes reported in the startup summary
+const (
+	ModeRun    = "run"
+	ModeServer = "server"
+)
+
+// Environments the process can be detected to run in
+const (
+	EnvironmentKubernetes = "kubernetes"
+	EnvironmentContainer  = "container"
+	EnvironmentHost       = "host"
+)
+
+// Startup summarizes the build, configuration and environment of the process, logged once when
+// run or server starts so postmortems and support tickets show what was running where
+type Startup struct {
+	Mode        string
+	Version     string
+	Commit      string
+	ConfigPath  string
+	ConfigHash  string // First 12 hex digits of the SHA-256 of the configuration file
+	Profile     string
+	Domain      string
+	Tenant      string // Host of the Beyond Identity SCIM API
+	Groups      int
+	Targets     int // Provisioning targets, including the default tenant
+	Features    []string
+	Schedule    string
+	Environment string
+	Platform    string
+}
+
+// NewStartup builds the startup summary of a process running in mode with cfg, loaded from configPath
+func NewStartup(cfg *config.Config, mode, version, commit, configPath string) Startup {
+	startup := Startup{
+		Mode:        mode,
+		Version:     version,
+		Commit:      commit,
+		ConfigPath:  configPath,
+		ConfigHash:  fileHash(configPath),
+		Profile:     cfg.Profile,
+		Domain:      cfg.GoogleWorkspace.Domain,
+		Tenant:      cfg.BeyondIdentity.SCIMBaseURL,
+		Groups:      len(cfg.Sync.Groups),
+		Targets:     1 + len(cfg.Targets),
+		Features:    []string{},
+		Schedule:    "once",
+		Environment: DetectEnvironment(),
+		Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if u, err := url.Parse(cfg.BeyondIdentity.SCIMBaseURL); err == nil && u.Host != "" {
+		startup.Tenant = u.Host
+	}
+	for _, feature := range cfg.FeatureStates() {
+		if feature.Enabled {
+			startup.Features = append(startup.Features, feature.Name)
+		}
+	}
+	if mode == ModeServer {
+		startup.Schedule = "manual"
+		if cfg.Server.ScheduleEnabled {
+			startup.Schedule = cfg.Server.Schedule
+		}
+	}
+	return startup
+}
+
+// Fields returns the summary as log fields
+func (s Startup) Fields() logrus.Fields {
+	features := strings.Join(s.Features, ",")
+	if features == "" {
+		features = "none"
+	}
+	return logrus.Fields{
+		"mode":        s.Mode,
+		"version":     s.Version,
+		"commit":      s.Commit,
+		"config":      s.ConfigPath,
+		"config_hash": s.ConfigHash,
+		"profile":     s.Profile,
+		"domain":      s.Domain,
+		"tenant":      s.Tenant,
+		"groups":      s.Groups,
+		"targets":     s.Targets,
+		"features":    features,
+		"schedule":    s.Schedule,
+		"environment": s.Environment,
+		"platform":    s.Platform,
+	}
+}
+
+// startupFieldOrder keeps the summary line in a stable, readable order
+var startupFieldOrder = []string{"mode", "version", "commit", "config", "config_hash", "profile", "domain",
+	"tenant", "groups", "targets", "features", "schedule", "environment", "platform"}
+
+// String formats the summary as key=value pairs, quoting values that contain spaces
+func (s Startup) String() string {
+	fields := s.Fields()
+	pairs := make([]string, 0, len(startupFieldOrder))
+	for _, key := range startupFieldOrder {
+		value := fmt.Sprint(fields[key])
+		if value == "" {
+			continue
+		}
+		if strings.ContainsAny(value, " \"") {
+			value = fmt.Sprintf("%q", value)
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// LogStartup logs the summary on one line; the fields are attached as well, for formatters that
+// output them
+func LogStartup(logger *logrus.Logger, startup Startup) {
+	logger.WithFields(startup.Fields()).Infof("Startup summary: %s", startup)
+}
+
+// fileHash returns the first 12 hex digits of the SHA-256 of a file, or "unknown" if it cannot be read
+func fileHash(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "unknown"
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Files that reveal the process runs in a container; variables so tests can point them elsewhere
+var (
+	containerMarkers = []string{"/.dockerenv", "/run/.containerenv"}
+	cgroupPath       = "/proc/1/cgroup"
+)
+
+// DetectEnvironment reports whether the process runs in a Kubernetes pod, another container or
+// directly on a host
+func DetectEnvironment() string {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return EnvironmentKubernetes
+	}
+	for _, marker := range containerMarkers {
+		if _, err := os.Stat(marker); err == nil {
+			return EnvironmentContainer
+		}
+	}
+	if data, err := os.ReadFile(cgroupPath); err == nil {
+		cgroup := string(data)
+		for _, name := range []string{"docker", "containerd", "kubepods", "libpod"} {
+			if strings.Contains(cgroup, name) {
+				return EnvironmentContainer
+			}
+		}
+	}
+	return EnvironmentHost
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func TestNewStartup(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("sync:\n  groups: [eng@example.com]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		GoogleWorkspace: config.GoogleWorkspaceConfig{Domain: "example.com"},
+		BeyondIdentity:  config.BeyondIdentityConfig{SCIMBaseURL: "https://api.byndid.com/scim/v2"},
+		Sync:            config.SyncConfig{Groups: []string{"eng@example.com", "sales@example.com"}},
+		Server:          config.ServerConfig{ScheduleEnabled: true, Schedule: "0 */6 * * *"},
+		Features:        map[string]bool{config.FeatureBulkAPI: true, config.FeatureDeprovisioning: false},
+	}
+
+	startup := NewStartup(cfg, ModeServer, "1.2.3", "abc123", configPath)
+	line := startup.String()
+	for _, want := range []string{
+		"mode=server version=1.2.3 commit=abc123 config=" + configPath,
+		"domain=example.com tenant=api.byndid.com groups=2 targets=1 features=bulk_api",
+		`schedule="0 */6 * * *"`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in %q", want, line)
+		}
+	}
+	if len(startup.ConfigHash) != 12 {
+		t.Errorf("Expected a 12 digit config hash, got %q", startup.ConfigHash)
+	}
+
+	if run := NewStartup(cfg, ModeRun, "1.2.3", "abc123", filepath.Join(t.TempDir(), "missing.yaml")); run.Schedule != "once" || run.ConfigHash != "unknown" {
+		t.Errorf("Expected a one-shot run with an unknown hash, got %+v", run)
+	}
+}
+
+func TestDetectEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	markers, cgroup := containerMarkers, cgroupPath
+	t.Cleanup(func() { containerMarkers, cgroupPath = markers, cgroup })
+	containerMarkers = []string{filepath.Join(dir, ".dockerenv")}
+	cgroupPath = filepath.Join(dir, "cgroup")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+	if env := DetectEnvironment(); env != EnvironmentHost {
+		t.Errorf("Expected %s, got %s", EnvironmentHost, env)
+	}
+
+	if err := os.WriteFile(cgroupPath, []byte("0::/system.slice/docker-0123.scope\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if env := DetectEnvironment(); env != EnvironmentContainer {
+		t.Errorf("Expected %s, got %s", EnvironmentContainer, env)
+	}
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	if env := DetectEnvironment(); env != EnvironmentKubernetes {
+		t.Errorf("Expected %s, got %s", EnvironmentKubernetes, env)
+	}
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// EventMembershipChanged is sent after a run that added or removed group members
+const EventMembershipChanged = "membership_changed"
+
+// MembershipChangedEvent describes the membership changes of a successful run
+func MembershipChangedEvent(result *syncengine.SyncResult, maxEntries int) Event {
+	return Event{
+		Kind:     EventMembershipChanged,
+		Severity: SeverityInfo,
+		Title:    "Group membership changed",
+		Text:     MembershipDiffText(result.MembershipDiffs, maxEntries),
+	}
+}
+
+// MembershipDiffText renders one line per group, e.g. "+2 users, -1 user in GWS_Engineering: +alice, +bob, -carol",
+// listing at most maxEntries users per group
+func MembershipDiffText(diffs []syncengine.GroupDiff, maxEntries int) string {
+	lines := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		var counts []string
+		if len(diff.Added) > 0 {
+			counts = append(counts, "+"+pluralUsers(len(diff.Added)))
+		}
+		if len(diff.Removed) > 0 {
+			counts = append(counts, "-"+pluralUsers(len(diff.Removed)))
+		}
+		if len(counts) == 0 {
+			continue
+		}
+
+		var entries []string
+		for _, user := range diff.Added {
+			entries = append(entries, "+"+shortUser(user))
+		}
+		for _, user := range diff.Removed {
+			entries = append(entries, "-"+shortUser(user))
+		}
+		if len(entries) > maxEntries {
+			entries = append(entries[:maxEntries], fmt.Sprintf("… %d more", len(entries)-maxEntries))
+		}
+
+		line := fmt.Sprintf("%s in %s", strings.Join(counts, ", "), diff.Group)
+		if maxEntries > 0 {
+			line += ": " + strings.Join(entries, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pluralUsers formats a user count
+func pluralUsers(n int) string {
+	if n == 1 {
+		return "1 user"
+	}
+	return fmt.Sprintf("%d users", n)
+}
+
+// shortUser drops the domain from an email to keep diffs compact; user IDs are kept as is
+func shortUser(user string) string {
+	if at := strings.Index(user, "@"); at > 0 {
+		return user[:at]
+	}
+	return user
+}
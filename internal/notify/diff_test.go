@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"testing"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+func TestMembershipDiffText(t *testing.T) {
+	diffs := []syncengine.GroupDiff{
+		{Group: "GWS_Engineering", Added: []string{"alice@example.com", "bob@example.com", "carol@example.com"}, Removed: []string{"dave@example.com"}},
+		{Group: "GWS_Sales", Removed: []string{"a1b2c3"}},
+		{Group: "GWS_Empty"},
+	}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		expected   string
+	}{
+		{
+			name:       "all entries listed",
+			maxEntries: 10,
+			expected:   "+3 users, -1 user in GWS_Engineering: +alice, +bob, +carol, -dave\n-1 user in GWS_Sales: -a1b2c3",
+		},
+		{
+			name:       "capped",
+			maxEntries: 2,
+			expected:   "+3 users, -1 user in GWS_Engineering: +alice, +bob, … 2 more\n-1 user in GWS_Sales: -a1b2c3",
+		},
+		{
+			name:       "counts only",
+			maxEntries: 0,
+			expected:   "+3 users, -1 user in GWS_Engineering\n-1 user in GWS_Sales",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MembershipDiffText(diffs, tt.maxEntries); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMembershipChangedEvent(t *testing.T) {
+	result := &syncengine.SyncResult{
+		MembershipDiffs: []syncengine.GroupDiff{{Group: "GWS_Engineering", Added: []string{"alice@example.com"}}},
+	}
+
+	event := MembershipChangedEvent(result, 10)
+	if event.Kind != EventMembershipChanged || event.Severity != SeverityInfo {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.Text != "+1 user in GWS_Engineering: +alice" {
+		t.Errorf("Unexpected diff text: %q", event.Text)
+	}
+}
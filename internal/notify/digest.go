@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// maxDigestErrors is how many distinct errors are listed in a digest
+const maxDigestErrors = 5
+
+// Digest aggregates sync results between summaries so failures are reported once per
+// window instead of on every run
+type Digest struct {
+	mu                 sync.Mutex
+	now                func() time.Time
+	since              time.Time
+	runs               int
+	successfulRuns     int
+	usersCreated       int
+	groupsCreated      int
+	membershipsAdded   int
+	membershipsRemoved int
+	errors             map[string]int // error description -> occurrences
+}
+
+// NewDigest creates an empty digest starting now
+func NewDigest() *Digest {
+	d := &Digest{now: time.Now}
+	d.reset()
+	return d
+}
+
+// reset starts a new window; must hold mu
+func (d *Digest) reset() {
+	d.since = d.now()
+	d.runs = 0
+	d.successfulRuns = 0
+	d.usersCreated = 0
+	d.groupsCreated = 0
+	d.membershipsAdded = 0
+	d.membershipsRemoved = 0
+	d.errors = make(map[string]int)
+}
+
+// Record adds the outcome of a sync run to the current window
+func (d *Digest) Record(result *syncengine.SyncResult, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.runs++
+	if err != nil {
+		d.errors[fmt.Sprintf("run failed: %v", err)]++
+	}
+	if result == nil {
+		return
+	}
+
+	if err == nil && len(result.Errors) == 0 {
+		d.successfulRuns++
+	}
+	d.usersCreated += result.UsersCreated
+	d.groupsCreated += result.GroupsCreated
+	d.membershipsAdded += result.MembershipsAdded
+	d.membershipsRemoved += result.MembershipsRemoved
+
+	for _, group := range result.ErrorSummary() {
+		kind := group.Kind
+		if kind == "" {
+			kind = "operation"
+		}
+		d.errors[fmt.Sprintf("%s failed with: %s", kind, group.Cause)] += group.Count
+	}
+}
+
+// Flush returns a summary event for the current window and starts a new one
+func (d *Digest) Flush() Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	event := Event{
+		Kind:     EventDigest,
+		Severity: SeverityInfo,
+		Title:    fmt.Sprintf("Sync digest since %s", d.since.Format(time.RFC1123)),
+		Fields: []Field{
+			{Name: "Runs", Value: fmt.Sprintf("%d", d.runs)},
+			{Name: "Success rate", Value: successRate(d.successfulRuns, d.runs)},
+			{Name: "New users", Value: fmt.Sprintf("%d", d.usersCreated)},
+			{Name: "New groups", Value: fmt.Sprintf("%d", d.groupsCreated)},
+			{Name: "Memberships added", Value: fmt.Sprintf("%d", d.membershipsAdded)},
+			{Name: "Memberships removed", Value: fmt.Sprintf("%d", d.membershipsRemoved)},
+		},
+	}
+
+	switch {
+	case d.runs == 0:
+		event.Severity = SeverityWarning
+		event.Text = "No sync runs completed in this window."
+	case d.successfulRuns < d.runs:
+		event.Severity = SeverityWarning
+		event.Text = topErrors(d.errors)
+	}
+
+	d.reset()
+	return event
+}
+
+// successRate formats the share of successful runs as a percentage
+func successRate(successful, total int) string {
+	if total == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", float64(successful)/float64(total)*100)
+}
+
+// topErrors lists the most frequent errors, one per line
+func topErrors(errors map[string]int) string {
+	descriptions := make([]string, 0, len(errors))
+	for description := range errors {
+		descriptions = append(descriptions, description)
+	}
+	sort.Slice(descriptions, func(i, j int) bool {
+		if errors[descriptions[i]] != errors[descriptions[j]] {
+			return errors[descriptions[i]] > errors[descriptions[j]]
+		}
+		return descriptions[i] < descriptions[j]
+	})
+
+	var lines []string
+	for i, description := range descriptions {
+		if i == maxDigestErrors {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(descriptions)-maxDigestErrors))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%dx %s", errors[description], description))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+func fieldValue(event Event, name string) string {
+	for _, field := range event.Fields {
+		if field.Name == name {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+func TestDigest(t *testing.T) {
+	digest := NewDigest()
+	unauthorized := errors.New("HTTP 401: Unauthorized")
+
+	digest.Record(&syncengine.SyncResult{UsersCreated: 3, MembershipsAdded: 4}, nil)
+	digest.Record(&syncengine.SyncResult{
+		UsersCreated: 1,
+		Errors: []error{
+			&syncengine.SyncError{Kind: "user", Subject: "a@example.com", Err: unauthorized},
+			&syncengine.SyncError{Kind: "user", Subject: "b@example.com", Err: unauthorized},
+		},
+	}, nil)
+	digest.Record(nil, errors.New("failed to refresh membership source"))
+	digest.Record(&syncengine.SyncResult{}, nil)
+
+	event := digest.Flush()
+	if event.Kind != EventDigest || event.Severity != SeverityWarning {
+		t.Errorf("Expected warning digest, got %s/%s", event.Kind, event.Severity)
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Runs", "4"},
+		{"Success rate", "50.0%"},
+		{"New users", "4"},
+		{"Memberships added", "4"},
+	}
+	for _, tt := range tests {
+		if got := fieldValue(event, tt.field); got != tt.want {
+			t.Errorf("Expected %s %q, got %q", tt.field, tt.want, got)
+		}
+	}
+
+	lines := strings.Split(event.Text, "\n")
+	if len(lines) != 2 || lines[0] != "2x user failed with: HTTP 401: Unauthorized" {
+		t.Errorf("Expected errors ordered by frequency, got %q", event.Text)
+	}
+
+	// Flushing starts a new, empty window
+	digest.now = func() time.Time { return time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC) }
+	event = digest.Flush()
+	if fieldValue(event, "Runs") != "0" || event.Text != "No sync runs completed in this window." {
+		t.Errorf("Expected empty window after flush, got %+v", event)
+	}
+}
+
+func TestDigest_AllSuccessful(t *testing.T) {
+	digest := NewDigest()
+	digest.Record(&syncengine.SyncResult{UsersCreated: 1}, nil)
+
+	event := digest.Flush()
+	if event.Severity != SeverityInfo || event.Text != "" {
+		t.Errorf("Expected informational digest without errors, got %+v", event)
+	}
+	if got := fieldValue(event, "Success rate"); got != "100.0%" {
+		t.Errorf("Expected 100%% success rate, got %s", got)
+	}
+}
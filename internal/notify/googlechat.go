@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GoogleChatNotifier posts events to a Google Chat space webhook
+type GoogleChatNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewGoogleChatNotifier creates a notifier for a Google Chat incoming webhook
+func NewGoogleChatNotifier(webhookURL string, httpClient *http.Client) *GoogleChatNotifier {
+	return &GoogleChatNotifier{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+// Notify implements Notifier
+func (g *GoogleChatNotifier) Notify(event Event) error {
+	body, err := json.Marshal(map[string]string{"text": googleChatText(event)})
+	if err != nil {
+		return fmt.Errorf("failed to encode Google Chat message: %w", err)
+	}
+
+	if err := postJSON(g.httpClient, g.webhookURL, body, nil); err != nil {
+		return fmt.Errorf("google chat: %w", err)
+	}
+	return nil
+}
+
+// googleChatText renders an event using Google Chat's text formatting
+func googleChatText(event Event) string {
+	var b strings.Builder
+
+	prefix := ""
+	switch event.Severity {
+	case SeverityWarning:
+		prefix = "⚠️ "
+	case SeverityCritical:
+		prefix = "🚨 "
+	}
+
+	fmt.Fprintf(&b, "%s*%s*", prefix, event.Title)
+	if event.Text != "" {
+		fmt.Fprintf(&b, "\n%s", event.Text)
+	}
+	for _, field := range event.Fields {
+		fmt.Fprintf(&b, "\n• *%s:* %s", field.Name, field.Value)
+	}
+	return b.String()
+}
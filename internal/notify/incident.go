@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	gosync "sync"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// incidentDedupKey identifies the sync-failure incident so repeated triggers update one alert
+const incidentDedupKey = "google-workspace-provisioner-sync-failure"
+
+// IncidentClient opens and resolves the sync-failure alert in an incident management tool
+type IncidentClient interface {
+	Name() string
+	Trigger(summary, details string) error
+	Resolve() error
+}
+
+// incidentTracker counts consecutive failures for one incident tool
+type incidentTracker struct {
+	client    IncidentClient
+	threshold int
+	failures  int
+	open      bool
+}
+
+// IncidentManager opens an incident after consecutive failed syncs and resolves it when
+// a sync succeeds again
+type IncidentManager struct {
+	mu       gosync.Mutex
+	trackers []*incidentTracker
+	logger   *logrus.Logger
+}
+
+// NewIncidentManager creates an empty incident manager
+func NewIncidentManager(logger *logrus.Logger) *IncidentManager {
+	return &IncidentManager{logger: logger}
+}
+
+// NewIncidentManagerFromConfig creates an incident manager for the tools configured under notifications
+func NewIncidentManagerFromConfig(cfg config.NotificationsConfig, httpClient *http.Client, logger *logrus.Logger) *IncidentManager {
+	m := NewIncidentManager(logger)
+	if cfg.PagerDuty.RoutingKey != "" {
+		m.Add(NewPagerDutyClient(cfg.PagerDuty.RoutingKey, httpClient), cfg.PagerDuty.FailureThreshold)
+	}
+	if cfg.Opsgenie.APIKey != "" {
+		m.Add(NewOpsgenieClient(cfg.Opsgenie.APIURL, cfg.Opsgenie.APIKey, httpClient), cfg.Opsgenie.FailureThreshold)
+	}
+	return m
+}
+
+// Add registers a tool that is alerted after threshold consecutive failures
+func (m *IncidentManager) Add(client IncidentClient, threshold int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trackers = append(m.trackers, &incidentTracker{client: client, threshold: threshold})
+}
+
+// RecordSuccess resolves any open incidents
+func (m *IncidentManager) RecordSuccess() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tracker := range m.trackers {
+		tracker.failures = 0
+		if !tracker.open {
+			continue
+		}
+
+		// Keep the incident marked open so resolving is retried after the next run
+		if err := tracker.client.Resolve(); err != nil {
+			m.logger.Errorf("Failed to resolve %s incident: %v", tracker.client.Name(), err)
+			continue
+		}
+		tracker.open = false
+		m.logger.Infof("Resolved %s incident after successful sync", tracker.client.Name())
+	}
+}
+
+// RecordFailure counts a failed sync and triggers incidents whose threshold is reached
+func (m *IncidentManager) RecordFailure(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tracker := range m.trackers {
+		tracker.failures++
+		if tracker.open || tracker.failures < tracker.threshold {
+			continue
+		}
+
+		summary := fmt.Sprintf("Google Workspace sync failed %d times in a row", tracker.failures)
+		if triggerErr := tracker.client.Trigger(summary, err.Error()); triggerErr != nil {
+			m.logger.Errorf("Failed to trigger %s incident: %v", tracker.client.Name(), triggerErr)
+			continue
+		}
+		tracker.open = true
+		m.logger.Warnf("Triggered %s incident: %s", tracker.client.Name(), summary)
+	}
+}
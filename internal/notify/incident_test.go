@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeIncidentClient struct {
+	triggers   int
+	resolves   int
+	triggerErr error
+}
+
+func (f *fakeIncidentClient) Name() string { return "fake" }
+
+func (f *fakeIncidentClient) Trigger(summary, details string) error {
+	f.triggers++
+	return f.triggerErr
+}
+
+func (f *fakeIncidentClient) Resolve() error {
+	f.resolves++
+	return nil
+}
+
+func TestIncidentManager(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client := &fakeIncidentClient{}
+	manager := NewIncidentManager(logger)
+	manager.Add(client, 3)
+
+	failure := errors.New("HTTP 401: Unauthorized")
+
+	// A success between failures resets the count
+	manager.RecordFailure(failure)
+	manager.RecordFailure(failure)
+	manager.RecordSuccess()
+	manager.RecordFailure(failure)
+	manager.RecordFailure(failure)
+	if client.triggers != 0 {
+		t.Fatalf("Expected no incident before 3 consecutive failures, got %d triggers", client.triggers)
+	}
+	if client.resolves != 0 {
+		t.Errorf("Expected nothing to resolve without an open incident, got %d resolves", client.resolves)
+	}
+
+	manager.RecordFailure(failure)
+	manager.RecordFailure(failure)
+	if client.triggers != 1 {
+		t.Errorf("Expected a single incident while failures continue, got %d triggers", client.triggers)
+	}
+
+	manager.RecordSuccess()
+	manager.RecordSuccess()
+	if client.resolves != 1 {
+		t.Errorf("Expected the incident to be resolved once, got %d resolves", client.resolves)
+	}
+}
+
+func TestIncidentManager_RetriesFailedTrigger(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client := &fakeIncidentClient{triggerErr: errors.New("unreachable")}
+	manager := NewIncidentManager(logger)
+	manager.Add(client, 1)
+
+	manager.RecordFailure(errors.New("boom"))
+	client.triggerErr = nil
+	manager.RecordFailure(errors.New("boom"))
+	if client.triggers != 2 {
+		t.Errorf("Expected trigger to be retried, got %d triggers", client.triggers)
+	}
+}
+
+func TestPagerDutyClient(t *testing.T) {
+	var events []pagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("Failed to decode event: %v", err)
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewPagerDutyClient("routing-key", server.Client())
+	client.eventsURL = server.URL
+
+	if err := client.Trigger("sync failed", "HTTP 401"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.Resolve(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].EventAction != "trigger" || events[0].RoutingKey != "routing-key" || events[0].Payload.Summary != "sync failed" {
+		t.Errorf("Unexpected trigger event: %+v", events[0])
+	}
+	if events[1].EventAction != "resolve" || events[1].DedupKey != events[0].DedupKey {
+		t.Errorf("Expected resolve with the trigger's dedup key, got %+v", events[1])
+	}
+}
+
+func TestOpsgenieClient(t *testing.T) {
+	var paths, auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		auths = append(auths, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewOpsgenieClient(server.URL+"/", "api-key", server.Client())
+	if err := client.Trigger("sync failed", "HTTP 401"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.Resolve(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantPaths := []string{
+		"/v2/alerts",
+		"/v2/alerts/google-workspace-provisioner-sync-failure/close?identifierType=alias",
+	}
+	for i, want := range wantPaths {
+		if paths[i] != want {
+			t.Errorf("Expected request to %s, got %s", want, paths[i])
+		}
+		if auths[i] != "GenieKey api-key" {
+			t.Errorf("Expected GenieKey authorization, got %q", auths[i])
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/sirupsen/logrus"
+)
+
+// Event kinds sent to notification channels
+const (
+	EventSyncFailed = "sync_failed"
+	EventDigest     = "digest"
+	EventOrphaned   = "group_orphaned"
+	EventQuota      = "quota_warning"
+	EventStuck      = "sync_stuck"
+)
+
+// Event severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Field is a labelled value shown alongside an event's text
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Event is a channel-independent notification
+type Event struct {
+	Kind     string
+	Severity string
+	Title    string
+	Text     string
+	Fields   []Field
+}
+
+// Notifier delivers events to a single channel
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// Dispatcher fans events out to every configured channel
+type Dispatcher struct {
+	notifiers []Notifier
+	logger    *logrus.Logger
+}
+
+// NewDispatcher creates a dispatcher for the given channels
+func NewDispatcher(logger *logrus.Logger, notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{
+		notifiers: notifiers,
+		logger:    logger,
+	}
+}
+
+// NewFromConfig creates a dispatcher for the channels configured under notifications
+func NewFromConfig(cfg config.NotificationsConfig, httpClient *http.Client, logger *logrus.Logger) *Dispatcher {
+	var notifiers []Notifier
+	if cfg.Slack.WebhookURL != "" {
+		notifiers = append(notifiers, NewSlackNotifier(cfg.Slack.WebhookURL, httpClient))
+	}
+	if cfg.Teams.WebhookURL != "" {
+		notifiers = append(notifiers, NewTeamsNotifier(cfg.Teams.WebhookURL, httpClient))
+	}
+	if cfg.GoogleChat.WebhookURL != "" {
+		notifiers = append(notifiers, NewGoogleChatNotifier(cfg.GoogleChat.WebhookURL, httpClient))
+	}
+	return NewDispatcher(logger, notifiers...)
+}
+
+// Enabled reports whether any channel is configured
+func (d *Dispatcher) Enabled() bool {
+	return len(d.notifiers) > 0
+}
+
+// Notify sends event to every channel, returning the combined delivery errors
+func (d *Dispatcher) Notify(event Event) error {
+	var errs []error
+	for _, notifier := range d.notifiers {
+		if err := notifier.Notify(event); err != nil {
+			d.logger.Errorf("Failed to send %s notification: %v", event.Kind, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// postJSON sends a webhook payload with optional extra headers and checks the response status
+func postJSON(httpClient *http.Client, url string, body []byte, header http.Header) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GroupsOrphanedEvent describes source groups that were deleted and will no longer be synced
+func GroupsOrphanedEvent(groups []string) Event {
+	return Event{
+		Kind:     EventOrphaned,
+		Severity: SeverityWarning,
+		Title:    "Groups deleted from the source",
+		Text:     "These groups no longer exist and will not be synced until the sync configuration changes.",
+		Fields:   []Field{{Name: "Groups", Value: strings.Join(groups, ", ")}},
+	}
+}
+
+// SyncFailedEvent describes a sync run that failed or finished with errors
+func SyncFailedEvent(result *syncengine.SyncResult, err error) Event {
+	event := Event{
+		Kind:     EventSyncFailed,
+		Severity: SeverityWarning,
+		Title:    "Sync completed with errors",
+	}
+
+	if err != nil {
+		event.Severity = SeverityCritical
+		event.Title = "Sync failed"
+		event.Text = err.Error()
+	}
+
+	if result != nil {
+		var lines []string
+		for _, group := range result.ErrorSummary() {
+			lines = append(lines, group.String())
+		}
+		if len(lines) > 0 {
+			event.Fields = append(event.Fields, Field{Name: "Errors", Value: strings.Join(lines, "; ")})
+		}
+	}
+	return event
+}
+
+// QuotaWarningEvent describes targets where a run used more than the warning fraction of the
+// available API quota
+func QuotaWarningEvent(usages []syncengine.QuotaUsage) Event {
+	event := Event{
+		Kind:     EventQuota,
+		Severity: SeverityWarning,
+		Title:    "Sync is nearing the Beyond Identity API quota",
+		Text:     "The last run used a large share of the API requests available. Later runs may be rate limited; consider sync.spread_over or a less frequent schedule.",
+	}
+	for _, usage := range usages {
+		value := fmt.Sprintf("%.0f%% of the available quota used (%d requests), %d of %d remaining",
+			usage.Fraction*100, usage.Used, usage.Remaining, usage.Limit)
+		if !usage.Reset.IsZero() {
+			value += fmt.Sprintf(", resets %s", usage.Reset.UTC().Format(time.RFC3339))
+		}
+		event.Fields = append(event.Fields, Field{Name: usage.Target, Value: value})
+	}
+	return event
+}
+
+// SyncStuckEvent describes a run still in progress after sync.max_expected_duration
+func SyncStuckEvent(run syncengine.StuckRun) Event {
+	event := Event{
+		Kind:     EventStuck,
+		Severity: SeverityCritical,
+		Title:    "Sync is taking longer than expected",
+		Text:     fmt.Sprintf("A %s run has been in progress for more than %s and may be stuck on a hung API call. A goroutine dump was logged.", run.Kind, run.Limit),
+		Fields: []Field{
+			{Name: "Run", Value: run.ID},
+			{Name: "Started", Value: run.StartedAt.UTC().Format(time.RFC3339)},
+		},
+	}
+	if run.Subject != "" {
+		event.Fields = append(event.Fields, Field{Name: "Subject", Value: run.Subject})
+	}
+	if run.Cancelled {
+		event.Text += " The run is being cancelled."
+	}
+	return event
+}
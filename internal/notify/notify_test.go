@@ -0,0 +1,204 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/sirupsen/logrus"
+)
+
+type recordingNotifier struct {
+	events []Event
+	err    error
+}
+
+func (r *recordingNotifier) Notify(event Event) error {
+	r.events = append(r.events, event)
+	return r.err
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	ok := &recordingNotifier{}
+	failing := &recordingNotifier{err: errors.New("channel down")}
+	dispatcher := NewDispatcher(logger, failing, ok)
+
+	err := dispatcher.Notify(Event{Kind: EventSyncFailed, Title: "Sync failed"})
+	if err == nil || !strings.Contains(err.Error(), "channel down") {
+		t.Errorf("Expected delivery error to be returned, got %v", err)
+	}
+	if len(ok.events) != 1 {
+		t.Error("Expected a failing channel not to prevent delivery to the others")
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	logger := logrus.New()
+
+	if NewFromConfig(config.NotificationsConfig{}, http.DefaultClient, logger).Enabled() {
+		t.Error("Expected no channels without configuration")
+	}
+
+	cfg := config.NotificationsConfig{
+		Slack:      config.WebhookConfig{WebhookURL: "https://hooks.slack.com/services/x"},
+		Teams:      config.WebhookConfig{WebhookURL: "https://example.webhook.office.com/x"},
+		GoogleChat: config.WebhookConfig{WebhookURL: "https://chat.googleapis.com/v1/spaces/x/messages"},
+	}
+	if got := len(NewFromConfig(cfg, http.DefaultClient, logger).notifiers); got != 3 {
+		t.Errorf("Expected 3 channels, got %d", got)
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	notifier := NewSlackNotifier(server.URL, server.Client())
+	err := notifier.Notify(Event{
+		Severity: SeverityCritical,
+		Title:    "Sync failed",
+		Text:     "token expired",
+		Fields:   []Field{{Name: "Errors", Value: "3 users failed"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := ":rotating_light: *Sync failed*\ntoken expired\n• *Errors:* 3 users failed"
+	if payload["text"] != want {
+		t.Errorf("Expected text %q, got %q", want, payload["text"])
+	}
+}
+
+func TestSlackNotifier_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if err := NewSlackNotifier(server.URL, server.Client()).Notify(Event{Title: "x"}); err == nil {
+		t.Error("Expected error for non-2xx webhook response")
+	}
+}
+
+func TestSyncFailedEvent(t *testing.T) {
+	result := &syncengine.SyncResult{
+		Errors: []error{&syncengine.SyncError{Kind: "user", Subject: "a@example.com", Err: errors.New("boom")}},
+	}
+
+	event := SyncFailedEvent(result, nil)
+	if event.Severity != SeverityWarning || event.Title != "Sync completed with errors" {
+		t.Errorf("Unexpected event for partial failure: %+v", event)
+	}
+	if len(event.Fields) != 1 || event.Fields[0].Value != "1 user failed with: boom" {
+		t.Errorf("Expected error summary field, got %+v", event.Fields)
+	}
+
+	event = SyncFailedEvent(nil, errors.New("refresh failed"))
+	if event.Severity != SeverityCritical || event.Text != "refresh failed" {
+		t.Errorf("Unexpected event for failed run: %+v", event)
+	}
+}
+
+func TestQuotaWarningEvent(t *testing.T) {
+	event := QuotaWarningEvent([]syncengine.QuotaUsage{
+		{Target: "default", Limit: 1000, Remaining: 100, Used: 300, Fraction: 0.75},
+	})
+
+	if event.Kind != EventQuota || event.Severity != SeverityWarning {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if len(event.Fields) != 1 || event.Fields[0].Name != "default" ||
+		event.Fields[0].Value != "75% of the available quota used (300 requests), 100 of 1000 remaining" {
+		t.Errorf("Expected a field per target, got %+v", event.Fields)
+	}
+}
+
+func TestSyncStuckEvent(t *testing.T) {
+	run := syncengine.StuckRun{
+		ID:        "run-1",
+		Kind:      "group",
+		Subject:   "eng@example.com",
+		StartedAt: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+		Limit:     2 * time.Hour,
+	}
+
+	event := SyncStuckEvent(run)
+	if event.Kind != EventStuck || event.Severity != SeverityCritical {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if len(event.Fields) != 3 || event.Fields[1].Value != "2024-06-01T09:00:00Z" || event.Fields[2].Value != "eng@example.com" {
+		t.Errorf("Unexpected fields %+v", event.Fields)
+	}
+	if strings.Contains(event.Text, "cancelled") {
+		t.Errorf("Expected no cancellation, got %q", event.Text)
+	}
+
+	run.Cancelled = true
+	if event := SyncStuckEvent(run); !strings.HasSuffix(event.Text, "The run is being cancelled.") {
+		t.Errorf("Expected the cancellation to be reported, got %q", event.Text)
+	}
+}
+
+func TestTeamsNotifier(t *testing.T) {
+	var card teamsCard
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&card); err != nil {
+			t.Errorf("Failed to decode card: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	err := NewTeamsNotifier(server.URL, server.Client()).Notify(Event{
+		Severity: SeverityWarning,
+		Title:    "Sync completed with errors",
+		Fields:   []Field{{Name: "Errors", Value: "3 users failed"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if card.Type != "MessageCard" || card.Title != "Sync completed with errors" || card.ThemeColor != "FFB900" {
+		t.Errorf("Unexpected card: %+v", card)
+	}
+	if len(card.Sections) != 1 || card.Sections[0].Facts[0] != (teamsFact{Name: "Errors", Value: "3 users failed"}) {
+		t.Errorf("Expected fields as facts, got %+v", card.Sections)
+	}
+}
+
+func TestGoogleChatNotifier(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	err := NewGoogleChatNotifier(server.URL, server.Client()).Notify(Event{
+		Severity: SeverityInfo,
+		Title:    "Sync digest",
+		Fields:   []Field{{Name: "Runs", Value: "4"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := "*Sync digest*\n• *Runs:* 4"; payload["text"] != want {
+		t.Errorf("Expected text %q, got %q", want, payload["text"])
+	}
+}
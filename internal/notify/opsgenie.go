@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultOpsgenieURL is the Opsgenie API for US accounts; EU accounts use https://api.eu.opsgenie.com
+const DefaultOpsgenieURL = "https://api.opsgenie.com"
+
+// OpsgenieClient raises sync-failure alerts through the Opsgenie Alert API
+type OpsgenieClient struct {
+	apiURL     string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewOpsgenieClient creates a client using an API integration key
+func NewOpsgenieClient(apiURL, apiKey string, httpClient *http.Client) *OpsgenieClient {
+	if apiURL == "" {
+		apiURL = DefaultOpsgenieURL
+	}
+	return &OpsgenieClient{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		apiKey:     apiKey,
+		httpClient: httpClient,
+	}
+}
+
+// Name implements IncidentClient
+func (o *OpsgenieClient) Name() string {
+	return "Opsgenie"
+}
+
+// Trigger implements IncidentClient
+func (o *OpsgenieClient) Trigger(summary, details string) error {
+	return o.post("/v2/alerts", map[string]string{
+		"message":     summary,
+		"alias":       incidentDedupKey,
+		"description": details,
+		"priority":    "P1",
+		"source":      "google-workspace-provisioner",
+	})
+}
+
+// Resolve implements IncidentClient
+func (o *OpsgenieClient) Resolve() error {
+	path := "/v2/alerts/" + url.PathEscape(incidentDedupKey) + "/close?identifierType=alias"
+	return o.post(path, map[string]string{"source": "google-workspace-provisioner"})
+}
+
+// post sends an authenticated request to the Alert API
+func (o *OpsgenieClient) post(path string, payload map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode Opsgenie request: %w", err)
+	}
+
+	header := http.Header{"Authorization": []string{"GenieKey " + o.apiKey}}
+	if err := postJSON(o.httpClient, o.apiURL+path, body, header); err != nil {
+		return fmt.Errorf("opsgenie: %w", err)
+	}
+	return nil
+}
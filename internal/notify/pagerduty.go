@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyClient raises sync-failure incidents through the PagerDuty Events API v2
+type PagerDutyClient struct {
+	routingKey string
+	eventsURL  string
+	httpClient *http.Client
+}
+
+// NewPagerDutyClient creates a client for the service with the given integration routing key
+func NewPagerDutyClient(routingKey string, httpClient *http.Client) *PagerDutyClient {
+	return &PagerDutyClient{
+		routingKey: routingKey,
+		eventsURL:  pagerDutyEventsURL,
+		httpClient: httpClient,
+	}
+}
+
+// pagerDutyEvent is an Events API v2 request
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Name implements IncidentClient
+func (p *PagerDutyClient) Name() string {
+	return "PagerDuty"
+}
+
+// Trigger implements IncidentClient
+func (p *PagerDutyClient) Trigger(summary, details string) error {
+	return p.send(pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "trigger",
+		DedupKey:    incidentDedupKey,
+		Payload: &pagerDutyPayload{
+			Summary:       summary,
+			Source:        "google-workspace-provisioner",
+			Severity:      "critical",
+			CustomDetails: map[string]string{"last_error": details},
+		},
+	})
+}
+
+// Resolve implements IncidentClient
+func (p *PagerDutyClient) Resolve() error {
+	return p.send(pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "resolve",
+		DedupKey:    incidentDedupKey,
+	})
+}
+
+// send posts an event to the Events API
+func (p *PagerDutyClient) send(event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode PagerDuty event: %w", err)
+	}
+
+	if err := postJSON(p.httpClient, p.eventsURL, body, nil); err != nil {
+		return fmt.Errorf("pagerduty: %w", err)
+	}
+	return nil
+}
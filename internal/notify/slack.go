@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SlackNotifier posts events to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewSlackNotifier creates a notifier for a Slack incoming webhook
+func NewSlackNotifier(webhookURL string, httpClient *http.Client) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+// Notify implements Notifier
+func (s *SlackNotifier) Notify(event Event) error {
+	body, err := json.Marshal(map[string]string{"text": slackText(event)})
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	if err := postJSON(s.httpClient, s.webhookURL, body, nil); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return nil
+}
+
+// slackText renders an event using Slack's mrkdwn formatting
+func slackText(event Event) string {
+	var b strings.Builder
+
+	icon := ":information_source:"
+	switch event.Severity {
+	case SeverityWarning:
+		icon = ":warning:"
+	case SeverityCritical:
+		icon = ":rotating_light:"
+	}
+
+	fmt.Fprintf(&b, "%s *%s*", icon, event.Title)
+	if event.Text != "" {
+		fmt.Fprintf(&b, "\n%s", event.Text)
+	}
+	for _, field := range event.Fields {
+		fmt.Fprintf(&b, "\n• *%s:* %s", field.Name, field.Value)
+	}
+	return b.String()
+}
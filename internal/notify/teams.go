@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// TeamsNotifier posts events to a Microsoft Teams incoming webhook as a message card
+type TeamsNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewTeamsNotifier creates a notifier for a Microsoft Teams incoming webhook
+func NewTeamsNotifier(webhookURL string, httpClient *http.Client) *TeamsNotifier {
+	return &TeamsNotifier{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+// teamsCard is the legacy actionable message card accepted by incoming webhooks
+type teamsCard struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	ThemeColor string         `json:"themeColor"`
+	Summary    string         `json:"summary"`
+	Title      string         `json:"title"`
+	Text       string         `json:"text,omitempty"`
+	Sections   []teamsSection `json:"sections,omitempty"`
+}
+
+type teamsSection struct {
+	Facts []teamsFact `json:"facts"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Notify implements Notifier
+func (t *TeamsNotifier) Notify(event Event) error {
+	card := teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: teamsColor(event.Severity),
+		Summary:    event.Title,
+		Title:      event.Title,
+		Text:       event.Text,
+	}
+
+	if len(event.Fields) > 0 {
+		section := teamsSection{}
+		for _, field := range event.Fields {
+			section.Facts = append(section.Facts, teamsFact{Name: field.Name, Value: field.Value})
+		}
+		card.Sections = []teamsSection{section}
+	}
+
+	body, err := json.Marshal(card)
+	if err != nil {
+		return fmt.Errorf("failed to encode Teams card: %w", err)
+	}
+
+	if err := postJSON(t.httpClient, t.webhookURL, body, nil); err != nil {
+		return fmt.Errorf("teams: %w", err)
+	}
+	return nil
+}
+
+// teamsColor returns the card accent color for a severity
+func teamsColor(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "D13438"
+	case SeverityWarning:
+		return "FFB900"
+	default:
+		return "0078D7"
+	}
+}
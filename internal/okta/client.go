@@ -0,0 +1,420 @@
+package okta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// Scopes requested when authenticating with OAuth client credentials
+var Scopes = []string{
+	"okta.users.read",
+	"okta.users.manage",
+	"okta.groups.read",
+	"okta.groups.manage",
+}
+
+// Client provisions users and groups into Okta using the Users and Groups APIs.
+// It exposes the same operations as the Beyond Identity client so the sync engine
+// can target either system.
+type Client struct {
+	orgURL     string
+	apiToken   string
+	httpClient *http.Client
+}
+
+// User represents an Okta user
+type User struct {
+	ID      string      `json:"id,omitempty"`
+	Status  string      `json:"status,omitempty"`
+	Profile UserProfile `json:"profile"`
+}
+
+// UserProfile contains the Okta user profile attributes
+type UserProfile struct {
+	Login     string `json:"login"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	NickName  string `json:"nickName,omitempty"`
+}
+
+// Group represents an Okta group
+type Group struct {
+	ID      string       `json:"id,omitempty"`
+	Profile GroupProfile `json:"profile"`
+}
+
+// GroupProfile contains the Okta group profile attributes
+type GroupProfile struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Factor represents an enrolled Okta authenticator factor
+type Factor struct {
+	ID         string `json:"id"`
+	FactorType string `json:"factorType"`
+	Status     string `json:"status"`
+}
+
+// APIError represents an Okta API error response
+type APIError struct {
+	StatusCode   int    `json:"-"`
+	ErrorCode    string `json:"errorCode"`
+	ErrorSummary string `json:"errorSummary"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Okta API error (status %d, %s): %s", e.StatusCode, e.ErrorCode, e.ErrorSummary)
+}
+
+// NewClient creates an Okta client authenticating with an SSWS API token
+func NewClient(orgURL, apiToken string, httpClient *http.Client) *Client {
+	return &Client{
+		orgURL:     strings.TrimSuffix(orgURL, "/"),
+		apiToken:   apiToken,
+		httpClient: httpClient,
+	}
+}
+
+// NewOAuthClient creates an Okta client authenticating with OAuth 2.0 client credentials
+func NewOAuthClient(orgURL, clientID, clientSecret string, httpClient *http.Client) *Client {
+	orgURL = strings.TrimSuffix(orgURL, "/")
+
+	cc := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     orgURL + "/oauth2/v1/token",
+		Scopes:       Scopes,
+	}
+
+	ctx := httpclient.OAuthContext(httpClient)
+	oauthClient := cc.Client(ctx)
+	oauthClient.Timeout = httpClient.Timeout
+
+	return &Client{
+		orgURL:     orgURL,
+		httpClient: oauthClient,
+	}
+}
+
+// makeRequest performs an HTTP request with proper authentication and error handling
+func (c *Client) makeRequest(ctx context.Context, method, requestURL string, body interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// OAuth clients have the bearer token added by their transport
+	if c.apiToken != "" {
+		req.Header.Set("Authorization", "SSWS "+c.apiToken)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform request: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer func() { _ = resp.Body.Close() }()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(bodyBytes, apiErr); err == nil && apiErr.ErrorCode != "" {
+			return resp, apiErr
+		}
+
+		return resp, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return resp, nil
+}
+
+// FindGroupByDisplayName searches for a group by name
+func (c *Client) FindGroupByDisplayName(ctx context.Context, displayName string) (*bi.Group, error) {
+	search := fmt.Sprintf(`profile.name eq "%s"`, displayName)
+	requestURL := fmt.Sprintf("%s/api/v1/groups?search=%s", c.orgURL, url.QueryEscape(search))
+
+	resp, err := c.makeRequest(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search group: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var groups []Group
+	if err := json.NewDecoder(resp.Body).Decode(&groups); err != nil {
+		return nil, fmt.Errorf("failed to decode search result: %w", err)
+	}
+
+	if len(groups) == 0 {
+		return nil, nil // Group not found
+	}
+
+	return toBIGroup(&groups[0]), nil
+}
+
+// CreateGroup creates a new group in Okta
+func (c *Client) CreateGroup(ctx context.Context, group *bi.Group) (*bi.Group, error) {
+	oktaGroup := &Group{
+		Profile: GroupProfile{
+			Name: group.DisplayName,
+		},
+	}
+
+	resp, err := c.makeRequest(ctx, "POST", c.orgURL+"/api/v1/groups", oktaGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var createdGroup Group
+	if err := json.NewDecoder(resp.Body).Decode(&createdGroup); err != nil {
+		return nil, fmt.Errorf("failed to decode created group: %w", err)
+	}
+
+	return toBIGroup(&createdGroup), nil
+}
+
+// GetGroupWithMembers retrieves a group by ID including its members
+func (c *Client) GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error) {
+	resp, err := c.makeRequest(ctx, "GET", fmt.Sprintf("%s/api/v1/groups/%s", c.orgURL, groupID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var group Group
+	if err := json.NewDecoder(resp.Body).Decode(&group); err != nil {
+		return nil, fmt.Errorf("failed to decode group: %w", err)
+	}
+
+	result := toBIGroup(&group)
+
+	// Members are paginated using Link headers
+	nextURL := fmt.Sprintf("%s/api/v1/groups/%s/users?limit=200", c.orgURL, groupID)
+	for nextURL != "" {
+		resp, err := c.makeRequest(ctx, "GET", nextURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list group members: %w", err)
+		}
+
+		var users []User
+		err = json.NewDecoder(resp.Body).Decode(&users)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode group members: %w", err)
+		}
+
+		for _, user := range users {
+			result.Members = append(result.Members, bi.GroupMember{
+				Value:   user.ID,
+				Display: user.Profile.Login,
+			})
+		}
+
+		nextURL = nextLink(resp.Header)
+	}
+
+	return result, nil
+}
+
+// UpdateGroupMembers adds and removes group members one user at a time
+func (c *Client) UpdateGroupMembers(ctx context.Context, groupID string, addMembers, removeMembers []bi.GroupMember) error {
+	for _, member := range removeMembers {
+		requestURL := fmt.Sprintf("%s/api/v1/groups/%s/users/%s", c.orgURL, groupID, member.Value)
+		resp, err := c.makeRequest(ctx, "DELETE", requestURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to remove member %s: %w", member.Value, err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	for _, member := range addMembers {
+		requestURL := fmt.Sprintf("%s/api/v1/groups/%s/users/%s", c.orgURL, groupID, member.Value)
+		resp, err := c.makeRequest(ctx, "PUT", requestURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to add member %s: %w", member.Value, err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	return nil
+}
+
+// FindUserByEmail searches for a user by login
+func (c *Client) FindUserByEmail(ctx context.Context, email string) (*bi.User, error) {
+	user, err := c.findOktaUser(ctx, email)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return toBIUser(user), nil
+}
+
+// findOktaUser searches for an Okta user by login
+func (c *Client) findOktaUser(ctx context.Context, email string) (*User, error) {
+	search := fmt.Sprintf(`profile.login eq "%s"`, email)
+	requestURL := fmt.Sprintf("%s/api/v1/users?search=%s", c.orgURL, url.QueryEscape(search))
+
+	resp, err := c.makeRequest(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search user: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var users []User
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return nil, fmt.Errorf("failed to decode search result: %w", err)
+	}
+
+	if len(users) == 0 {
+		return nil, nil // User not found
+	}
+
+	return &users[0], nil
+}
+
+// CreateUser creates and activates a new user in Okta
+func (c *Client) CreateUser(ctx context.Context, user *bi.User) (*bi.User, error) {
+	firstName, lastName := splitDisplayName(user.DisplayName)
+	if user.Name != nil && user.Name.GivenName != "" && user.Name.FamilyName != "" {
+		firstName, lastName = user.Name.GivenName, user.Name.FamilyName
+	}
+
+	email := user.UserName
+	for _, e := range user.Emails {
+		if e.Primary {
+			email = e.Value
+		}
+	}
+
+	oktaUser := &User{
+		Profile: UserProfile{
+			Login:     user.UserName,
+			Email:     email,
+			FirstName: firstName,
+			LastName:  lastName,
+		},
+	}
+
+	resp, err := c.makeRequest(ctx, "POST", c.orgURL+"/api/v1/users?activate=true", oktaUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var createdUser User
+	if err := json.NewDecoder(resp.Body).Decode(&createdUser); err != nil {
+		return nil, fmt.Errorf("failed to decode created user: %w", err)
+	}
+
+	return toBIUser(&createdUser), nil
+}
+
+// GetUserStatus reports a user as enrolled when they are active and have an active WebAuthn factor
+func (c *Client) GetUserStatus(ctx context.Context, userEmail string) (bool, error) {
+	user, err := c.findOktaUser(ctx, userEmail)
+	if err != nil {
+		return false, fmt.Errorf("failed to find user by email: %w", err)
+	}
+
+	if user == nil || user.Status != "ACTIVE" {
+		return false, nil
+	}
+
+	resp, err := c.makeRequest(ctx, "GET", fmt.Sprintf("%s/api/v1/users/%s/factors", c.orgURL, user.ID), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to list factors: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var factors []Factor
+	if err := json.NewDecoder(resp.Body).Decode(&factors); err != nil {
+		return false, fmt.Errorf("failed to decode factors: %w", err)
+	}
+
+	for _, factor := range factors {
+		if factor.FactorType == "webauthn" && factor.Status == "ACTIVE" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// toBIGroup converts an Okta group to the engine's group representation
+func toBIGroup(group *Group) *bi.Group {
+	return &bi.Group{
+		ID:          group.ID,
+		DisplayName: group.Profile.Name,
+	}
+}
+
+// toBIUser converts an Okta user to the engine's user representation
+func toBIUser(user *User) *bi.User {
+	return &bi.User{
+		ID:          user.ID,
+		ExternalID:  user.ID,
+		UserName:    user.Profile.Login,
+		DisplayName: strings.TrimSpace(user.Profile.FirstName + " " + user.Profile.LastName),
+		Emails: []bi.Email{
+			{
+				Value:   user.Profile.Email,
+				Type:    "work",
+				Primary: true,
+			},
+		},
+		Active: user.Status == "ACTIVE" || user.Status == "PROVISIONED",
+	}
+}
+
+// splitDisplayName splits a display name into the first and last names Okta requires
+func splitDisplayName(displayName string) (string, string) {
+	parts := strings.Fields(displayName)
+	switch len(parts) {
+	case 0:
+		return "Unknown", "Unknown"
+	case 1:
+		return parts[0], parts[0]
+	default:
+		return parts[0], strings.Join(parts[1:], " ")
+	}
+}
+
+// nextLink extracts the rel="next" URL from Okta's Link headers
+func nextLink(header http.Header) string {
+	for _, link := range header.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			segments := strings.Split(part, ";")
+			if len(segments) < 2 {
+				continue
+			}
+			for _, param := range segments[1:] {
+				if strings.TrimSpace(param) == `rel="next"` {
+					return strings.Trim(strings.TrimSpace(segments[0]), "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
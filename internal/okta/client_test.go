@@ -0,0 +1,147 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+func TestClient_FindAndCreateUser(t *testing.T) {
+	var created User
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "SSWS test-token" {
+			t.Errorf("Expected SSWS authorization, got '%s'", r.Header.Get("Authorization"))
+		}
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v1/users":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == "POST" && r.URL.Path == "/api/v1/users":
+			if r.URL.Query().Get("activate") != "true" {
+				t.Errorf("Expected user to be activated on creation")
+			}
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			created.ID = "00u1"
+			created.Status = "ACTIVE"
+			_ = json.NewEncoder(w).Encode(created)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, "test-token", http.DefaultClient)
+
+	user, err := client.FindUserByEmail(context.Background(), "jane.doe@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user != nil {
+		t.Fatalf("Expected no user, got %+v", user)
+	}
+
+	newUser, err := client.CreateUser(context.Background(), &bi.User{
+		UserName:    "jane.doe@example.com",
+		DisplayName: "Jane Doe",
+		Emails:      []bi.Email{{Value: "jane.doe@example.com", Primary: true}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if newUser.ID != "00u1" {
+		t.Errorf("Expected ID '00u1', got '%s'", newUser.ID)
+	}
+	if created.Profile.FirstName != "Jane" || created.Profile.LastName != "Doe" {
+		t.Errorf("Expected first/last name Jane Doe, got %s %s", created.Profile.FirstName, created.Profile.LastName)
+	}
+}
+
+func TestClient_GetGroupWithMembersPaginates(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/groups/00g1":
+			_, _ = w.Write([]byte(`{"id":"00g1","profile":{"name":"GoogleSCIM_Eng"}}`))
+		case r.URL.Path == "/api/v1/groups/00g1/users" && r.URL.Query().Get("after") == "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/groups/00g1/users?after=00u1>; rel="next"`, ts.URL))
+			_, _ = w.Write([]byte(`[{"id":"00u1","profile":{"login":"a@example.com"}}]`))
+		case r.URL.Path == "/api/v1/groups/00g1/users":
+			_, _ = w.Write([]byte(`[{"id":"00u2","profile":{"login":"b@example.com"}}]`))
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.String())
+		}
+	}))
+	defer ts.Close()
+
+	group, err := NewClient(ts.URL, "test-token", http.DefaultClient).GetGroupWithMembers(context.Background(), "00g1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if group.DisplayName != "GoogleSCIM_Eng" {
+		t.Errorf("Expected display name 'GoogleSCIM_Eng', got '%s'", group.DisplayName)
+	}
+	if len(group.Members) != 2 {
+		t.Errorf("Expected 2 members across pages, got %d", len(group.Members))
+	}
+}
+
+func TestClient_GetUserStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/users":
+			_, _ = w.Write([]byte(`[{"id":"00u1","status":"ACTIVE","profile":{"login":"a@example.com"}}]`))
+		case "/api/v1/users/00u1/factors":
+			_, _ = w.Write([]byte(`[{"id":"f1","factorType":"sms","status":"ACTIVE"},{"id":"f2","factorType":"webauthn","status":"ACTIVE"}]`))
+		}
+	}))
+	defer ts.Close()
+
+	enrolled, err := NewClient(ts.URL, "test-token", http.DefaultClient).GetUserStatus(context.Background(), "a@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !enrolled {
+		t.Error("Expected user with active webauthn factor to be enrolled")
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errorCode":"E0000006","errorSummary":"You do not have permission"}`))
+	}))
+	defer ts.Close()
+
+	_, err := NewClient(ts.URL, "test-token", http.DefaultClient).FindGroupByDisplayName(context.Background(), "x")
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
+
+func TestSplitDisplayName(t *testing.T) {
+	tests := []struct {
+		input string
+		first string
+		last  string
+	}{
+		{"Jane Doe", "Jane", "Doe"},
+		{"Mary Ann Smith", "Mary", "Ann Smith"},
+		{"Prince", "Prince", "Prince"},
+		{"", "Unknown", "Unknown"},
+	}
+
+	for _, tt := range tests {
+		first, last := splitDisplayName(tt.input)
+		if first != tt.first || last != tt.last {
+			t.Errorf("splitDisplayName(%q) = %q, %q; expected %q, %q", tt.input, first, last, tt.first, tt.last)
+		}
+	}
+}
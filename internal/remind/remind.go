@@ -0,0 +1,168 @@
+package remind
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultTemplate is the message body used when no template_path is configured
+const defaultTemplate = `Hello {{.DisplayName}},
+
+Your account ({{.Email}}) was set up {{.DaysPending}} days ago, but you have not yet registered a passkey.
+{{if .EnrollmentURL}}
+Set up your passkey here: {{.EnrollmentURL}}
+{{end}}
+If you need help, please contact your IT team.
+`
+
+// TemplateData is available to the reminder template
+type TemplateData struct {
+	Email         string
+	DisplayName   string
+	DaysPending   int
+	EnrollmentURL string
+}
+
+// Result summarizes a reminder run
+type Result struct {
+	Pending int // Users past the grace period without a passkey
+	Sent    int
+	Skipped int // Reminded recently or already sent the maximum
+	Failed  int
+}
+
+// Reminder emails users who have not registered a passkey after the grace period
+type Reminder struct {
+	cfg      config.RemindersConfig
+	users    report.UserLister
+	sender   Sender
+	template *template.Template
+	testMode bool
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+// NewReminder creates a reminder using the configured or default template
+func NewReminder(cfg config.RemindersConfig, users report.UserLister, sender Sender, testMode bool, logger *logrus.Logger) (*Reminder, error) {
+	text := defaultTemplate
+	if cfg.TemplatePath != "" {
+		data, err := os.ReadFile(cfg.TemplatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read reminder template: %w", err)
+		}
+		text = string(data)
+	}
+
+	tmpl, err := template.New("reminder").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reminder template: %w", err)
+	}
+
+	return &Reminder{
+		cfg:      cfg,
+		users:    users,
+		sender:   sender,
+		template: tmpl,
+		testMode: testMode,
+		logger:   logger,
+		now:      time.Now,
+	}, nil
+}
+
+// Run sends reminders to pending users who are due one and records them in the state file
+func (r *Reminder) Run(ctx context.Context) (*Result, error) {
+	now := r.now()
+
+	pending, err := report.PendingEnrollment(ctx, r.users, r.cfg.GracePeriodDays, now)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := LoadState(r.cfg.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Pending: len(pending)}
+	stillPending := make(map[string]bool)
+
+	for _, user := range pending {
+		stillPending[strings.ToLower(user.Email)] = true
+
+		if !r.due(state.Get(user.Email), now) {
+			result.Skipped++
+			continue
+		}
+
+		body, err := r.render(user)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.testMode {
+			r.logger.Infof("TEST MODE: Would send enrollment reminder to %s (pending %d days)", user.Email, user.DaysPending)
+			result.Sent++
+			continue
+		}
+
+		if err := r.sender.Send(user.Email, r.cfg.Subject, body); err != nil {
+			r.logger.Errorf("Failed to send enrollment reminder to %s: %v", user.Email, err)
+			result.Failed++
+			continue
+		}
+
+		r.logger.Infof("Sent enrollment reminder to %s (pending %d days)", user.Email, user.DaysPending)
+		state.MarkSent(user.Email, now)
+		result.Sent++
+	}
+
+	if r.testMode {
+		return result, nil
+	}
+
+	state.Forget(stillPending)
+	if err := state.Save(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// due reports whether a user with the given reminder history should be reminded now
+func (r *Reminder) due(record *Record, now time.Time) bool {
+	if record == nil {
+		return true
+	}
+	if record.Count >= r.cfg.MaxReminders {
+		return false
+	}
+	return !now.Before(record.LastSent.AddDate(0, 0, r.cfg.IntervalDays))
+}
+
+// render executes the template for a user
+func (r *Reminder) render(user report.PendingUser) (string, error) {
+	name := user.DisplayName
+	if name == "" {
+		name = user.Email
+	}
+
+	var buf bytes.Buffer
+	err := r.template.Execute(&buf, TemplateData{
+		Email:         user.Email,
+		DisplayName:   name,
+		DaysPending:   user.DaysPending,
+		EnrollmentURL: r.cfg.EnrollmentURL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render reminder for %s: %w", user.Email, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,173 @@
+package remind
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+type mockUserLister struct {
+	users []bi.NativeUser
+}
+
+func (m *mockUserLister) ListNativeUsers(ctx context.Context) ([]bi.NativeUser, error) {
+	return m.users, nil
+}
+
+type sentEmail struct {
+	to, subject, body string
+}
+
+type mockSender struct {
+	sent   []sentEmail
+	failTo string
+}
+
+func (m *mockSender) Send(to, subject, body string) error {
+	if to == m.failTo {
+		return errors.New("mailbox unavailable")
+	}
+	m.sent = append(m.sent, sentEmail{to, subject, body})
+	return nil
+}
+
+func newTestReminder(t *testing.T, users []bi.NativeUser, sender Sender, now time.Time) *Reminder {
+	t.Helper()
+
+	cfg := config.RemindersConfig{
+		GracePeriodDays: 7,
+		IntervalDays:    7,
+		MaxReminders:    2,
+		Subject:         "Set up your passkey",
+		EnrollmentURL:   "https://enroll.example.com",
+		StatePath:       filepath.Join(t.TempDir(), "state.json"),
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	reminder, err := NewReminder(cfg, &mockUserLister{users: users}, sender, false, logger)
+	if err != nil {
+		t.Fatalf("Failed to create reminder: %v", err)
+	}
+	reminder.now = func() time.Time { return now }
+	return reminder
+}
+
+func TestReminder_Run(t *testing.T) {
+	now := time.Date(2024, 6, 30, 9, 0, 0, 0, time.UTC)
+	users := []bi.NativeUser{
+		{EmailAddress: "alice@example.com", DisplayName: "Alice", State: "ACTIVE", CreateTime: now.AddDate(0, 0, -10)},
+		{EmailAddress: "bob@example.com", State: "ACTIVE", CreateTime: now.AddDate(0, 0, -2)},
+		{EmailAddress: "carol@example.com", State: "ACTIVE", HasActivePasskey: true, CreateTime: now.AddDate(0, 0, -30)},
+	}
+	sender := &mockSender{}
+	reminder := newTestReminder(t, users, sender, now)
+
+	result, err := reminder.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Pending != 1 || result.Sent != 1 {
+		t.Errorf("Expected 1 pending user reminded, got %+v", result)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].to != "alice@example.com" {
+		t.Fatalf("Expected reminder to alice, got %+v", sender.sent)
+	}
+	body := sender.sent[0].body
+	if !strings.Contains(body, "Hello Alice") || !strings.Contains(body, "https://enroll.example.com") || !strings.Contains(body, "10 days") {
+		t.Errorf("Unexpected reminder body: %q", body)
+	}
+
+	// Only alice remains pending for the rest of the test
+	reminder.users = &mockUserLister{users: users[:1]}
+
+	// Too soon for another reminder
+	reminder.now = func() time.Time { return now.AddDate(0, 0, 3) }
+	if result, _ := reminder.Run(context.Background()); result.Sent != 0 || result.Skipped != 1 {
+		t.Errorf("Expected reminder within interval to be skipped, got %+v", result)
+	}
+
+	// Due again after the interval, then capped at max_reminders
+	reminder.now = func() time.Time { return now.AddDate(0, 0, 7) }
+	if result, _ := reminder.Run(context.Background()); result.Sent != 1 {
+		t.Errorf("Expected second reminder after interval, got %+v", result)
+	}
+	reminder.now = func() time.Time { return now.AddDate(0, 0, 14) }
+	if result, _ := reminder.Run(context.Background()); result.Sent != 0 {
+		t.Errorf("Expected no reminders beyond max_reminders, got %+v", result)
+	}
+	if len(sender.sent) != 2 {
+		t.Errorf("Expected 2 reminders in total, got %d", len(sender.sent))
+	}
+}
+
+func TestReminder_FailedSendIsRetried(t *testing.T) {
+	now := time.Date(2024, 6, 30, 9, 0, 0, 0, time.UTC)
+	users := []bi.NativeUser{{EmailAddress: "alice@example.com", State: "ACTIVE", CreateTime: now.AddDate(0, 0, -10)}}
+	sender := &mockSender{failTo: "alice@example.com"}
+	reminder := newTestReminder(t, users, sender, now)
+
+	if result, _ := reminder.Run(context.Background()); result.Failed != 1 {
+		t.Errorf("Expected failed send to be reported, got %+v", result)
+	}
+
+	sender.failTo = ""
+	if result, _ := reminder.Run(context.Background()); result.Sent != 1 {
+		t.Errorf("Expected failed reminder to be retried on the next run, got %+v", result)
+	}
+}
+
+func TestReminder_TestMode(t *testing.T) {
+	now := time.Date(2024, 6, 30, 9, 0, 0, 0, time.UTC)
+	users := []bi.NativeUser{{EmailAddress: "alice@example.com", State: "ACTIVE", CreateTime: now.AddDate(0, 0, -10)}}
+	sender := &mockSender{}
+	reminder := newTestReminder(t, users, sender, now)
+	reminder.testMode = true
+
+	if result, _ := reminder.Run(context.Background()); result.Sent != 1 {
+		t.Errorf("Expected test mode to report the reminder, got %+v", result)
+	}
+	if len(sender.sent) != 0 {
+		t.Error("Expected no email in test mode")
+	}
+	if _, err := os.Stat(reminder.cfg.StatePath); !os.IsNotExist(err) {
+		t.Error("Expected state not to be written in test mode")
+	}
+}
+
+func TestState_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	sentAt := time.Date(2024, 6, 30, 9, 0, 0, 0, time.UTC)
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	state.MarkSent("Alice@Example.com", sentAt)
+	state.MarkSent("bob@example.com", sentAt)
+	state.Forget(map[string]bool{"alice@example.com": true})
+	if err := state.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	record := loaded.Get("alice@example.com")
+	if record == nil || record.Count != 1 || !record.LastSent.Equal(sentAt) {
+		t.Errorf("Expected alice's record to round-trip, got %+v", record)
+	}
+	if loaded.Get("bob@example.com") != nil {
+		t.Error("Expected users no longer pending to be forgotten")
+	}
+}
@@ -0,0 +1,68 @@
+package remind
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// Sender delivers a plain-text email to one recipient
+type Sender interface {
+	Send(to, subject, body string) error
+}
+
+// SMTPSender sends email through an SMTP relay, upgrading to TLS when the server offers STARTTLS
+type SMTPSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPSender creates a sender for the configured relay
+func NewSMTPSender(cfg config.SMTPConfig, from string) *SMTPSender {
+	sender := &SMTPSender{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		from: from,
+	}
+	if cfg.Username != "" {
+		sender.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return sender
+}
+
+// Send implements Sender
+func (s *SMTPSender) Send(to, subject, body string) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+	return nil
+}
+
+// NewSender creates the sender for the configured transport
+func NewSender(cfg *config.Config, httpClient *http.Client) (Sender, error) {
+	reminders := cfg.Reminders
+	if reminders.Transport == config.ReminderTransportSMTP {
+		return NewSMTPSender(reminders.SMTP, reminders.From), nil
+	}
+
+	sender, err := gws.NewGmailSender(cfg.GoogleWorkspace.ServiceAccountKeyPath, reminders.From, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gmail sender: %w", err)
+	}
+	return sender, nil
+}
@@ -0,0 +1,97 @@
+package remind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Record tracks the reminders sent to one user
+type Record struct {
+	Count    int       `json:"count"`
+	LastSent time.Time `json:"last_sent"`
+}
+
+// State records who has been reminded, persisted as JSON so reminders survive restarts
+type State struct {
+	path    string
+	Records map[string]*Record `json:"records"` // lower-cased email -> record
+}
+
+// LoadState reads the state file, starting empty if it does not exist yet
+func LoadState(path string) (*State, error) {
+	state := &State{path: path, Records: make(map[string]*Record)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reminder state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse reminder state %s: %w", path, err)
+	}
+	if state.Records == nil {
+		state.Records = make(map[string]*Record)
+	}
+	return state, nil
+}
+
+// Get returns the record for email, or nil if the user was never reminded
+func (s *State) Get(email string) *Record {
+	return s.Records[strings.ToLower(email)]
+}
+
+// MarkSent records a reminder sent to email at the given time
+func (s *State) MarkSent(email string, at time.Time) {
+	key := strings.ToLower(email)
+	record, ok := s.Records[key]
+	if !ok {
+		record = &Record{}
+		s.Records[key] = record
+	}
+	record.Count++
+	record.LastSent = at
+}
+
+// Forget drops users who are no longer pending so they start over if they fall out of enrollment again
+func (s *State) Forget(keep map[string]bool) {
+	for email := range s.Records {
+		if !keep[email] {
+			delete(s.Records, email)
+		}
+	}
+}
+
+// Save writes the state file atomically
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode reminder state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".reminders-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write reminder state: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write reminder state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write reminder state: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write reminder state: %w", err)
+	}
+	return nil
+}
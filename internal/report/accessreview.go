@@ -0,0 +1,170 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// accessReviewSchema is the SCIM extension carrying provenance of each group
+const accessReviewSchema = "urn:gobeyondidentity:params:scim:schemas:extension:accessreview:2.0:Group"
+
+// AccessReviewGroup is a provisioned group with its current members and where it was sourced from
+type AccessReviewGroup struct {
+	SourceGroup  string               `json:"source_group"`
+	Target       string               `json:"target"`
+	GroupID      string               `json:"group_id"`
+	GroupName    string               `json:"group_name"`
+	LastVerified time.Time            `json:"last_verified"`
+	Members      []AccessReviewMember `json:"members"`
+}
+
+// AccessReviewMember is a member of a provisioned group
+type AccessReviewMember struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// WriteAccessReviewCSV writes one row per group membership with a header row
+func WriteAccessReviewCSV(w io.Writer, groups []AccessReviewGroup) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"user_email", "user_id", "group_name", "group_id", "target", "source_group", "last_verified"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, group := range groups {
+		for _, member := range group.Members {
+			record := []string{
+				member.Email, member.UserID, group.GroupName, group.GroupID, group.Target,
+				group.SourceGroup, group.LastVerified.UTC().Format(time.RFC3339),
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// scimListResponse is a SCIM ListResponse of groups
+type scimListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	Resources    []scimGroup `json:"Resources"`
+}
+
+type scimGroup struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id"`
+	ExternalID  string           `json:"externalId"`
+	DisplayName string           `json:"displayName"`
+	Members     []scimMember     `json:"members"`
+	Meta        scimMeta         `json:"meta"`
+	Provenance  scimAccessReview `json:"urn:gobeyondidentity:params:scim:schemas:extension:accessreview:2.0:Group"`
+}
+
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type"`
+}
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+type scimAccessReview struct {
+	SourceGroup  string `json:"sourceGroup"`
+	Target       string `json:"target"`
+	LastVerified string `json:"lastVerified,omitempty"`
+}
+
+// WriteAccessReviewSCIM writes the groups as a SCIM ListResponse, with the source group and
+// last verified time in an extension schema
+func WriteAccessReviewSCIM(w io.Writer, groups []AccessReviewGroup) error {
+	response := scimListResponse{
+		Schemas:      []string{"urn:ietf:params:scim:api:messages:2.0:ListResponse"},
+		TotalResults: len(groups),
+		Resources:    make([]scimGroup, 0, len(groups)),
+	}
+
+	for _, group := range groups {
+		var lastVerified string
+		if !group.LastVerified.IsZero() {
+			lastVerified = group.LastVerified.UTC().Format(time.RFC3339)
+		}
+
+		resource := scimGroup{
+			Schemas:     []string{"urn:ietf:params:scim:schemas:core:2.0:Group", accessReviewSchema},
+			ID:          group.GroupID,
+			ExternalID:  group.SourceGroup,
+			DisplayName: group.GroupName,
+			Members:     make([]scimMember, 0, len(group.Members)),
+			Meta:        scimMeta{ResourceType: "Group", LastModified: lastVerified},
+			Provenance:  scimAccessReview{SourceGroup: group.SourceGroup, Target: group.Target, LastVerified: lastVerified},
+		}
+		for _, member := range group.Members {
+			resource.Members = append(resource.Members, scimMember{Value: member.UserID, Display: member.Email, Type: "User"})
+		}
+		response.Resources = append(response.Resources, resource)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(response); err != nil {
+		return fmt.Errorf("failed to write SCIM export: %w", err)
+	}
+	return nil
+}
+
+// WriteAccessReview writes the export in the given format
+func WriteAccessReview(w io.Writer, groups []AccessReviewGroup, format string) error {
+	switch format {
+	case config.AccessReviewFormatCSV:
+		return WriteAccessReviewCSV(w, groups)
+	case config.AccessReviewFormatSCIM:
+		return WriteAccessReviewSCIM(w, groups)
+	default:
+		return fmt.Errorf("unsupported access review format %q: use csv or scim", format)
+	}
+}
+
+// SaveAccessReview writes the export to a timestamped file in dir and returns its path
+func SaveAccessReview(dir, format string, groups []AccessReviewGroup, now time.Time) (string, error) {
+	var ext string
+	switch format {
+	case config.AccessReviewFormatCSV:
+		ext = "csv"
+	case config.AccessReviewFormatSCIM:
+		ext = "json"
+	default:
+		return "", fmt.Errorf("unsupported access review format %q: use csv or scim", format)
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create access review directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("access-review-%s.%s", now.UTC().Format("20060102-150405"), ext))
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create access review file: %w", err)
+	}
+	if err := WriteAccessReview(file, groups, format); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write access review file: %w", err)
+	}
+	return path, nil
+}
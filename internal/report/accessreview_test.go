@@ -0,0 +1,97 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func testAccessReview() []AccessReviewGroup {
+	return []AccessReviewGroup{{
+		SourceGroup:  "eng@example.com",
+		Target:       "default",
+		GroupID:      "group-1",
+		GroupName:    "GWS_Engineering",
+		LastVerified: time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC),
+		Members: []AccessReviewMember{
+			{UserID: "user-1", Email: "alice@example.com"},
+			{UserID: "user-2", Email: "bob@example.com"},
+		},
+	}}
+}
+
+func TestWriteAccessReviewCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAccessReviewCSV(&buf, testAccessReview()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "user_email,user_id,group_name,group_id,target,source_group,last_verified\n" +
+		"alice@example.com,user-1,GWS_Engineering,group-1,default,eng@example.com,2024-06-02T09:00:00Z\n" +
+		"bob@example.com,user-2,GWS_Engineering,group-1,default,eng@example.com,2024-06-02T09:00:00Z\n"
+	if buf.String() != expected {
+		t.Errorf("Expected CSV:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteAccessReviewSCIM(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAccessReviewSCIM(&buf, testAccessReview()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var response struct {
+		TotalResults int `json:"totalResults"`
+		Resources    []struct {
+			ID         string `json:"id"`
+			ExternalID string `json:"externalId"`
+			Members    []struct {
+				Value   string `json:"value"`
+				Display string `json:"display"`
+			} `json:"members"`
+			Provenance struct {
+				SourceGroup  string `json:"sourceGroup"`
+				LastVerified string `json:"lastVerified"`
+			} `json:"urn:gobeyondidentity:params:scim:schemas:extension:accessreview:2.0:Group"`
+		} `json:"Resources"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+
+	if response.TotalResults != 1 || len(response.Resources) != 1 {
+		t.Fatalf("Expected 1 group, got %+v", response)
+	}
+	group := response.Resources[0]
+	if group.ID != "group-1" || group.ExternalID != "eng@example.com" || len(group.Members) != 2 {
+		t.Errorf("Unexpected group: %+v", group)
+	}
+	if group.Provenance.SourceGroup != "eng@example.com" || group.Provenance.LastVerified != "2024-06-02T09:00:00Z" {
+		t.Errorf("Unexpected provenance: %+v", group.Provenance)
+	}
+}
+
+func TestSaveAccessReview(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reviews")
+	now := time.Date(2024, 6, 30, 8, 0, 0, 0, time.UTC)
+
+	path, err := SaveAccessReview(dir, config.AccessReviewFormatSCIM, testAccessReview(), now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filepath.Base(path) != "access-review-20240630-080000.json" {
+		t.Errorf("Unexpected file name %s", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected export file to exist: %v", err)
+	}
+
+	if _, err := SaveAccessReview(dir, "xlsx", testAccessReview(), now); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// ParseTime parses a date (2006-01-02, midnight UTC) or an RFC 3339 timestamp
+func ParseTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use YYYY-MM-DD or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// Entities a change record is about
+const (
+	EntityUser       = "user"
+	EntityGroup      = "group"
+	EntityMembership = "membership"
+)
+
+// ChangeRecord is one change a run made, would have made, or failed to make
+type ChangeRecord struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`          // A history action such as user_created, or group_created
+	Entity  string    `json:"entity"`          // user, group or membership
+	User    string    `json:"user,omitempty"`  // User email, or the user ID when the email is unknown
+	ID      string    `json:"id,omitempty"`    // ID of the user or group in the target, when known
+	Group   string    `json:"group,omitempty"` // Source group email, or the name of a group created
+	Target  string    `json:"target,omitempty"`
+	Planned bool      `json:"planned,omitempty"` // Only reported: a dry run, read-only mode or sync.test_mode_operations
+	Error   string    `json:"error,omitempty"`   // Why the change failed; failed changes were not made
+}
+
+// WriteChangesCSV writes sync changes as CSV with a header row
+func WriteChangesCSV(w io.Writer, changes []state.Change) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"time", "action", "user", "group", "target"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, change := range changes {
+		record := []string{change.Time.UTC().Format(time.RFC3339), change.Action, change.User, change.Group, change.Target}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Time
+		expectError bool
+	}{
+		{name: "date", value: "2024-06-01", expected: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "timestamp", value: "2024-06-01T12:30:00Z", expected: time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)},
+		{name: "invalid", value: "June 1st", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteChangesCSV(t *testing.T) {
+	changes := []state.Change{{
+		Time:   time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC),
+		Action: state.ChangeMembershipAdded,
+		User:   "alice@example.com",
+		Group:  "eng@example.com",
+		Target: "default",
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteChangesCSV(&buf, changes); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "time,action,user,group,target\n2024-06-02T09:00:00Z,membership_added,alice@example.com,eng@example.com,default\n"
+	if buf.String() != expected {
+		t.Errorf("Expected CSV:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
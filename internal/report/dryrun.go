@@ -0,0 +1,98 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DryRun lists the changes a dry run would make, as written by scim-sync run --dry-run --report and
+// scim-sync plan, and executed by scim-sync apply --plan
+type DryRun struct {
+	GeneratedAt    time.Time           `json:"generated_at"`
+	ConfigHash     string              `json:"config_hash,omitempty"` // Fingerprint of the sync configuration the plan was made with
+	UsersToCreate  []PlannedUser       `json:"users_to_create"`
+	GroupsToCreate []PlannedGroup      `json:"groups_to_create"`
+	Memberships    []PlannedMembership `json:"memberships"`
+	Changes        []ChangeRecord      `json:"changes,omitempty"` // Each planned change, in the order it was found
+	Errors         []string            `json:"errors,omitempty"`  // Failures that left parts of the plan out
+}
+
+// PlannedUser is a user a dry run would create
+type PlannedUser struct {
+	Email  string `json:"email"`
+	Target string `json:"target"`
+}
+
+// PlannedGroup is a group a dry run would create
+type PlannedGroup struct {
+	Name       string `json:"name"`
+	Target     string `json:"target"`
+	GroupEmail string `json:"group_email,omitempty"` // Source group it is synced from, recorded in the state file when applied
+}
+
+// PlannedMembership lists the users a dry run would add to and remove from one group
+type PlannedMembership struct {
+	Group  string   `json:"group"`
+	Target string   `json:"target,omitempty"`
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// WriteDryRunJSON writes the dry run as indented JSON
+func WriteDryRunJSON(w io.Writer, dryRun *DryRun) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(dryRun); err != nil {
+		return fmt.Errorf("failed to write dry run report: %w", err)
+	}
+	return nil
+}
+
+// ReadDryRunJSON reads a dry run written by WriteDryRunJSON
+func ReadDryRunJSON(r io.Reader) (*DryRun, error) {
+	var dryRun DryRun
+	if err := json.NewDecoder(r).Decode(&dryRun); err != nil {
+		return nil, fmt.Errorf("failed to read dry run report: %w", err)
+	}
+	return &dryRun, nil
+}
+
+// WriteDryRunTable writes the dry run as a table with one row per change, followed by totals
+func WriteDryRunTable(w io.Writer, dryRun *DryRun) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANGE\tSUBJECT\tGROUP / TARGET")
+	for _, user := range dryRun.UsersToCreate {
+		fmt.Fprintf(tw, "create user\t%s\t%s\n", user.Email, user.Target)
+	}
+	for _, group := range dryRun.GroupsToCreate {
+		fmt.Fprintf(tw, "create group\t%s\t%s\n", group.Name, group.Target)
+	}
+	var added, removed int
+	for _, membership := range dryRun.Memberships {
+		for _, user := range membership.Add {
+			fmt.Fprintf(tw, "+ member\t%s\t%s\n", user, membership.Group)
+		}
+		for _, user := range membership.Remove {
+			fmt.Fprintf(tw, "- member\t%s\t%s\n", user, membership.Group)
+		}
+		added += len(membership.Add)
+		removed += len(membership.Remove)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write dry run report: %w", err)
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d users to create, %d groups to create, %d memberships to add, %d to remove\n",
+		len(dryRun.UsersToCreate), len(dryRun.GroupsToCreate), added, removed)
+	if err == nil && len(dryRun.Errors) > 0 {
+		_, err = fmt.Fprintf(w, "Incomplete plan, %d errors:\n  %s\n", len(dryRun.Errors), strings.Join(dryRun.Errors, "\n  "))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write dry run report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func testDryRun() *DryRun {
+	return &DryRun{
+		GeneratedAt:    time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+		UsersToCreate:  []PlannedUser{{Email: "alice@example.com", Target: "default"}},
+		GroupsToCreate: []PlannedGroup{{Name: "GWS_Engineering", Target: "default"}},
+		Memberships: []PlannedMembership{
+			{Group: "GWS_Engineering", Add: []string{"alice@example.com", "bob@example.com"}, Remove: []string{"mallory@example.com"}},
+		},
+	}
+}
+
+func TestWriteDryRunJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDryRunJSON(&buf, testDryRun()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var decoded DryRun
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if len(decoded.UsersToCreate) != 1 || len(decoded.Memberships[0].Add) != 2 || decoded.Memberships[0].Remove[0] != "mallory@example.com" {
+		t.Errorf("Unexpected report %+v", decoded)
+	}
+}
+
+func TestReadDryRunJSON(t *testing.T) {
+	dryRun := testDryRun()
+	dryRun.ConfigHash = "0123456789abcdef"
+	dryRun.Memberships[0].Target = "default"
+
+	var buf bytes.Buffer
+	if err := WriteDryRunJSON(&buf, dryRun); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	read, err := ReadDryRunJSON(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if read.ConfigHash != dryRun.ConfigHash || read.Memberships[0].Target != "default" || !read.GeneratedAt.Equal(dryRun.GeneratedAt) {
+		t.Errorf("Expected the plan to round-trip, got %+v", read)
+	}
+
+	if _, err := ReadDryRunJSON(bytes.NewBufferString("not json")); err == nil {
+		t.Error("Expected an error for a malformed plan")
+	}
+}
+
+func TestWriteDryRunTable(t *testing.T) {
+	dryRun := testDryRun()
+	dryRun.Errors = []string{"user bob@example.com: HTTP 503"}
+
+	var buf bytes.Buffer
+	if err := WriteDryRunTable(&buf, dryRun); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `CHANGE        SUBJECT              GROUP / TARGET
+create user   alice@example.com    default
+create group  GWS_Engineering      default
++ member      alice@example.com    GWS_Engineering
++ member      bob@example.com      GWS_Engineering
+- member      mallory@example.com  GWS_Engineering
+
+1 users to create, 1 groups to create, 2 memberships to add, 1 to remove
+Incomplete plan, 1 errors:
+  user bob@example.com: HTTP 503
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected table:\n%s\nwant:\n%s", got, want)
+	}
+}
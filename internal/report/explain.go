@@ -0,0 +1,57 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Stages of the decisions a run explains for one user, as printed by scim-sync run --explain
+const (
+	ExplainStageGroup      = "group"      // Whether the group was synced and includes the user
+	ExplainStageFilter     = "filter"     // Member type, status, skip list and sync.filters filters
+	ExplainStageLookup     = "lookup"     // Match of the user in Beyond Identity
+	ExplainStageAttributes = "attributes" // Attributes the user is created with, or why none are compared
+	ExplainStageAction     = "action"     // What the run did to the user's account
+	ExplainStageMembership = "membership" // Change to the user's membership of the Beyond Identity group
+	ExplainStageEnrollment = "enrollment" // Change to the user's membership of the enrollment group
+)
+
+// ExplainStep is one decision a run made about the explained user
+type ExplainStep struct {
+	Group    string `json:"group,omitempty"` // Configured source group the decision was made for
+	Target   string `json:"target,omitempty"`
+	Stage    string `json:"stage"`
+	Decision string `json:"decision"`
+}
+
+// WriteExplanation writes the decision trace of one user as a table with one row per decision
+func WriteExplanation(w io.Writer, email string, steps []ExplainStep) error {
+	if _, err := fmt.Fprintf(w, "Decision trace for %s:\n", email); err != nil {
+		return fmt.Errorf("failed to write decision trace: %w", err)
+	}
+	if len(steps) == 0 {
+		_, err := fmt.Fprintln(w, "  no synced group was read, so no decisions were made")
+		if err != nil {
+			return fmt.Errorf("failed to write decision trace: %w", err)
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tTARGET\tSTAGE\tDECISION")
+	for _, step := range steps {
+		group, target := step.Group, step.Target
+		if group == "" {
+			group = "-"
+		}
+		if target == "" {
+			target = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", group, target, step.Stage, step.Decision)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write decision trace: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteExplanation(t *testing.T) {
+	steps := []ExplainStep{
+		{Group: "eng@example.com", Target: "default", Stage: ExplainStageGroup, Decision: "includes the user (type USER, status ACTIVE)"},
+		{Stage: ExplainStageAttributes, Decision: "name from the directory profile: displayName=Alice Liddell"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteExplanation(&buf, "alice@example.com", steps); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || lines[0] != "Decision trace for alice@example.com:" {
+		t.Fatalf("Unexpected trace:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "GROUP") || !strings.Contains(lines[2], "eng@example.com") || !strings.HasPrefix(lines[3], "-") {
+		t.Errorf("Unexpected rows:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteExplanation(&buf, "alice@example.com", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "no decisions") {
+		t.Errorf("Expected an empty trace to say so, got:\n%s", buf.String())
+	}
+}
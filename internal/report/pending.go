@@ -0,0 +1,86 @@
+package report
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+// DefaultPendingDays is the default age after which an unenrolled user is reported
+const DefaultPendingDays = 14
+
+// UserLister lists Beyond Identity users with their enrollment state
+type UserLister interface {
+	ListNativeUsers(ctx context.Context) ([]bi.NativeUser, error)
+}
+
+// PendingUser is a provisioned user who has not registered an active passkey
+type PendingUser struct {
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	DaysPending int       `json:"days_pending"`
+}
+
+// PendingEnrollment returns active users created at least minDays before now who still
+// lack an active passkey, longest pending first
+func PendingEnrollment(ctx context.Context, lister UserLister, minDays int, now time.Time) ([]PendingUser, error) {
+	users, err := lister.ListNativeUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Beyond Identity users: %w", err)
+	}
+
+	cutoff := now.AddDate(0, 0, -minDays)
+
+	var pending []PendingUser
+	for _, user := range users {
+		if user.HasActivePasskey || user.CreateTime.IsZero() || user.CreateTime.After(cutoff) {
+			continue
+		}
+		// Suspended users are not expected to enroll
+		if user.State != "" && !strings.EqualFold(user.State, "ACTIVE") {
+			continue
+		}
+
+		pending = append(pending, PendingUser{
+			Email:       user.EmailAddress,
+			DisplayName: user.DisplayName,
+			CreatedAt:   user.CreateTime,
+			DaysPending: int(now.Sub(user.CreateTime).Hours() / 24),
+		})
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].CreatedAt.Equal(pending[j].CreatedAt) {
+			return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+		}
+		return pending[i].Email < pending[j].Email
+	})
+
+	return pending, nil
+}
+
+// WritePendingEnrollmentCSV writes the report as CSV with a header row
+func WritePendingEnrollmentCSV(w io.Writer, users []PendingUser) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"email", "display_name", "created_at", "days_pending"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, user := range users {
+		record := []string{user.Email, user.DisplayName, user.CreatedAt.UTC().Format(time.RFC3339), strconv.Itoa(user.DaysPending)}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
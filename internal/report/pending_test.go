@@ -0,0 +1,74 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+type mockUserLister struct {
+	users []bi.NativeUser
+	err   error
+}
+
+func (m *mockUserLister) ListNativeUsers(ctx context.Context) ([]bi.NativeUser, error) {
+	return m.users, m.err
+}
+
+func TestPendingEnrollment(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	lister := &mockUserLister{users: []bi.NativeUser{
+		{EmailAddress: "enrolled@example.com", State: "ACTIVE", HasActivePasskey: true, CreateTime: daysAgo(30)},
+		{EmailAddress: "recent@example.com", State: "ACTIVE", CreateTime: daysAgo(3)},
+		{EmailAddress: "old@example.com", DisplayName: "Old", State: "ACTIVE", CreateTime: daysAgo(40)},
+		{EmailAddress: "suspended@example.com", State: "SUSPENDED", CreateTime: daysAgo(40)},
+		{EmailAddress: "boundary@example.com", State: "ACTIVE", CreateTime: daysAgo(14)},
+		{EmailAddress: "unknown@example.com", State: "ACTIVE"},
+	}}
+
+	pending, err := PendingEnrollment(context.Background(), lister, 14, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(pending) != 2 {
+		t.Fatalf("Expected 2 pending users, got %d: %+v", len(pending), pending)
+	}
+	if pending[0].Email != "old@example.com" || pending[0].DaysPending != 40 {
+		t.Errorf("Expected longest pending user first, got %+v", pending[0])
+	}
+	if pending[1].Email != "boundary@example.com" || pending[1].DaysPending != 14 {
+		t.Errorf("Expected user created exactly 14 days ago, got %+v", pending[1])
+	}
+}
+
+func TestPendingEnrollment_Error(t *testing.T) {
+	if _, err := PendingEnrollment(context.Background(), &mockUserLister{err: errors.New("HTTP 401")}, 14, time.Now()); err == nil {
+		t.Error("Expected error when users cannot be listed")
+	}
+}
+
+func TestWritePendingEnrollmentCSV(t *testing.T) {
+	var buf bytes.Buffer
+	users := []PendingUser{{
+		Email:       "old@example.com",
+		DisplayName: "Old, User",
+		CreatedAt:   time.Date(2024, 5, 21, 12, 0, 0, 0, time.UTC),
+		DaysPending: 40,
+	}}
+
+	if err := WritePendingEnrollmentCSV(&buf, users); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "email,display_name,created_at,days_pending\nold@example.com,\"Old, User\",2024-05-21T12:00:00Z,40\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,266 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// statusChartDays is how many days the success rate chart covers
+const statusChartDays = 14
+
+// Status is what the static status page shows, read from the state file and Beyond Identity
+type Status struct {
+	GeneratedAt    time.Time
+	Version        string
+	Runs           []state.Run      // Newest first
+	LastSuccess    *time.Time       // Start of the last successful run
+	Days           []DayRate        // Oldest first, one per day of the chart
+	Errors         []StatusError    // Problems a stakeholder may need to follow up
+	OrphanedGroups []string         // Source groups deleted from Google Workspace
+	SkippedUsers   int              // Users on the skip list
+	Enrollment     *EnrollmentStats // Nil when Beyond Identity could not be read
+}
+
+// DayRate counts the finished runs started on a day
+type DayRate struct {
+	Day       time.Time
+	Succeeded int
+	Failed    int
+}
+
+// Rate is the share of the day's runs that succeeded, from 0 to 100; -1 when none finished
+func (d DayRate) Rate() int {
+	total := d.Succeeded + d.Failed
+	if total == 0 {
+		return -1
+	}
+	return d.Succeeded * 100 / total
+}
+
+// StatusError is a current problem: a failed latest run, or a write waiting in the retry queue
+type StatusError struct {
+	Time    time.Time
+	Source  string
+	Message string
+}
+
+// EnrollmentStats counts Beyond Identity users by enrollment state
+type EnrollmentStats struct {
+	Users     int // Active users
+	Enrolled  int // Active users with an active passkey
+	Suspended int
+}
+
+// Pending is how many active users have not enrolled
+func (e EnrollmentStats) Pending() int {
+	return e.Users - e.Enrolled
+}
+
+// Percent is the share of active users who have enrolled, from 0 to 100
+func (e EnrollmentStats) Percent() int {
+	if e.Users == 0 {
+		return 0
+	}
+	return e.Enrolled * 100 / e.Users
+}
+
+// NewStatus builds the status page from the state file at now
+func NewStatus(store *state.Store, version string, now time.Time) *Status {
+	status := &Status{
+		GeneratedAt:  now,
+		Version:      version,
+		Runs:         store.Runs(),
+		SkippedUsers: len(store.SkippedUsers(now)),
+	}
+
+	if checkpoint, ok := store.Checkpoint(); ok {
+		status.LastSuccess = &checkpoint.LastSync
+	}
+
+	// One bucket per calendar day (UTC) ending today, so days without runs show as gaps
+	today := now.UTC().Truncate(24 * time.Hour)
+	status.Days = make([]DayRate, statusChartDays)
+	for i := range status.Days {
+		status.Days[i].Day = today.AddDate(0, 0, i-statusChartDays+1)
+	}
+	for _, run := range status.Runs {
+		index := int(run.StartedAt.UTC().Truncate(24*time.Hour).Sub(status.Days[0].Day) / (24 * time.Hour))
+		if index < 0 || index >= statusChartDays {
+			continue
+		}
+		switch run.Status {
+		case state.RunStatusSucceeded:
+			status.Days[index].Succeeded++
+		case state.RunStatusFailed:
+			status.Days[index].Failed++
+		}
+	}
+
+	// The latest finished run tells whether syncs currently work; older failures are history
+	for _, run := range status.Runs {
+		if run.Status == state.RunStatusRunning {
+			continue
+		}
+		if run.Status == state.RunStatusFailed {
+			status.Errors = append(status.Errors, StatusError{Time: run.StartedAt, Source: run.Kind + " sync", Message: run.Error})
+		}
+		break
+	}
+	for _, op := range store.DeferredOps() {
+		status.Errors = append(status.Errors, StatusError{Time: op.EnqueuedAt, Source: "retry queue: " + op.Kind, Message: op.LastError})
+	}
+
+	for email, group := range store.Groups() {
+		if group.Orphaned {
+			status.OrphanedGroups = append(status.OrphanedGroups, email)
+		}
+	}
+	sort.Strings(status.OrphanedGroups)
+
+	return status
+}
+
+// Enrollment counts the Beyond Identity users who have and have not enrolled a passkey
+func Enrollment(ctx context.Context, lister UserLister) (*EnrollmentStats, error) {
+	users, err := lister.ListNativeUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Beyond Identity users: %w", err)
+	}
+
+	stats := &EnrollmentStats{}
+	for _, user := range users {
+		if user.State != "" && !strings.EqualFold(user.State, "ACTIVE") {
+			stats.Suspended++
+			continue
+		}
+		stats.Users++
+		if user.HasActivePasskey {
+			stats.Enrolled++
+		}
+	}
+	return stats, nil
+}
+
+// WriteStatusHTML writes the status page as a single HTML document without external resources,
+// so it can be published to any static site
+func WriteStatusHTML(w io.Writer, status *Status) error {
+	if err := statusTemplate.Execute(w, status); err != nil {
+		return fmt.Errorf("failed to render status page: %w", err)
+	}
+	return nil
+}
+
+var statusTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+	"day":  func(t time.Time) string { return t.Format("Jan 2") },
+	"duration": func(run state.Run) string {
+		if run.FinishedAt == nil {
+			return ""
+		}
+		return run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+	},
+	// Bars are 20px wide with a 4px gap in a 120px tall chart; days where every run failed get a
+	// sliver so they stand apart from days without runs
+	"barX":       func(i int) int { return i * 24 },
+	"barHeight":  barHeight,
+	"barY":       func(rate int) int { return 120 - barHeight(rate) },
+	"chartWidth": func(days []DayRate) int { return len(days) * 24 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>SCIM Sync Status</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 960px; color: #222; }
+h1 { margin-bottom: 0; }
+.meta { color: #666; margin-top: 0.25em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #ddd; }
+.succeeded { color: #1a7f37; }
+.failed { color: #cf222e; }
+.running { color: #9a6700; }
+.tiles { display: flex; gap: 1em; }
+.tile { border: 1px solid #ddd; border-radius: 6px; padding: 0.75em 1em; flex: 1; }
+.tile strong { display: block; font-size: 1.5em; }
+svg text { font-size: 10px; fill: #666; }
+</style>
+</head>
+<body>
+<h1>SCIM Sync Status</h1>
+<p class="meta">Generated {{time .GeneratedAt}}{{if .Version}} by scim-sync {{.Version}}{{end}}</p>
+
+<div class="tiles">
+<div class="tile">Last successful sync<strong>{{if .LastSuccess}}{{time .LastSuccess}}{{else}}never{{end}}</strong></div>
+<div class="tile">Current errors<strong>{{len .Errors}}</strong></div>
+{{- with .Enrollment}}
+<div class="tile">Enrolled users<strong>{{.Enrolled}} / {{.Users}} ({{.Percent}}%)</strong></div>
+{{- end}}
+</div>
+
+<h2>Success Rate</h2>
+<svg width="{{chartWidth .Days}}" height="140" role="img" aria-label="Daily sync success rate">
+{{- range $i, $day := .Days}}
+{{- $rate := $day.Rate}}
+{{- if ge $rate 0}}
+<rect x="{{barX $i}}" y="{{barY $rate}}" width="20" height="{{barHeight $rate}}" fill="{{if eq $rate 100}}#1a7f37{{else}}#cf222e{{end}}"><title>{{day $day.Day}}: {{$rate}}% of {{$day.Succeeded}} succeeded, {{$day.Failed}} failed</title></rect>
+{{- end}}
+<text x="{{barX $i}}" y="135">{{day $day.Day}}</text>
+{{- end}}
+</svg>
+
+<h2>Current Errors</h2>
+{{- if .Errors}}
+<table>
+<tr><th>Since</th><th>Source</th><th>Error</th></tr>
+{{- range .Errors}}
+<tr><td>{{time .Time}}</td><td>{{.Source}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>None.</p>
+{{- end}}
+{{- if .OrphanedGroups}}
+<p>Groups deleted from Google Workspace: {{range $i, $group := .OrphanedGroups}}{{if $i}}, {{end}}{{$group}}{{end}}</p>
+{{- end}}
+{{- if .SkippedUsers}}
+<p>{{.SkippedUsers}} users are on the skip list.</p>
+{{- end}}
+
+<h2>Enrollment</h2>
+{{- with .Enrollment}}
+<table>
+<tr><th>Active users</th><th>Enrolled</th><th>Not enrolled</th><th>Suspended</th></tr>
+<tr><td>{{.Users}}</td><td>{{.Enrolled}}</td><td>{{.Pending}}</td><td>{{.Suspended}}</td></tr>
+</table>
+{{- else}}
+<p>Unavailable: Beyond Identity could not be read when this page was generated.</p>
+{{- end}}
+
+<h2>Recent Syncs</h2>
+{{- if .Runs}}
+<table>
+<tr><th>Started</th><th>Kind</th><th>Status</th><th>Duration</th><th>Error</th></tr>
+{{- range .Runs}}
+<tr><td>{{time .StartedAt}}</td><td>{{.Kind}}{{if .Subject}} ({{.Subject}}){{end}}{{if .DryRun}} [dry run]{{end}}{{range $name, $value := .Labels}} {{$name}}={{$value}}{{end}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{duration .}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No syncs have run yet.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+func barHeight(rate int) int {
+	if height := rate * 120 / 100; height > 2 {
+		return height
+	}
+	return 2
+}
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+func TestNewStatus(t *testing.T) {
+	now := time.Date(2024, 6, 14, 12, 0, 0, 0, time.UTC)
+	store, err := state.Open("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Two runs today, one yesterday and one outside the chart
+	for i, run := range []struct {
+		started time.Time
+		err     error
+	}{
+		{started: now.AddDate(0, 0, -30)},
+		{started: now.AddDate(0, 0, -1), err: errors.New("HTTP 500")},
+		{started: now.Add(-2 * time.Hour)},
+		{started: now.Add(-time.Hour), err: errors.New("HTTP 401")},
+	} {
+		id := string(rune('a' + i))
+		store.StartRun(state.Run{ID: id, Kind: "full", StartedAt: run.started})
+		store.FinishRun(id, run.started.Add(time.Minute), run.err)
+	}
+	store.StartRun(state.Run{ID: "running", Kind: "user", Subject: "alice@example.com", StartedAt: now})
+	store.SetCheckpoint(state.Checkpoint{LastSync: now.Add(-2 * time.Hour)})
+	store.Enqueue(state.DeferredOp{ID: "op", Kind: state.DeferredCreateUser, User: "bob@example.com", LastError: "HTTP 503", EnqueuedAt: now})
+	store.UpdateGroup("old@example.com", func(group *state.GroupState) { group.Orphaned = true })
+
+	status := NewStatus(store, "v1.2.3", now)
+
+	if len(status.Runs) != 5 || status.Runs[0].ID != "running" {
+		t.Fatalf("Expected 5 runs newest first, got %+v", status.Runs)
+	}
+	if status.LastSuccess == nil || !status.LastSuccess.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("Expected last success 2 hours ago, got %v", status.LastSuccess)
+	}
+
+	if len(status.Days) != statusChartDays {
+		t.Fatalf("Expected %d days, got %d", statusChartDays, len(status.Days))
+	}
+	today := status.Days[statusChartDays-1]
+	if today.Succeeded != 1 || today.Failed != 1 || today.Rate() != 50 {
+		t.Errorf("Expected 1 succeeded and 1 failed today, got %+v", today)
+	}
+	if yesterday := status.Days[statusChartDays-2]; yesterday.Rate() != 0 {
+		t.Errorf("Expected 0%% yesterday, got %d", yesterday.Rate())
+	}
+	if status.Days[0].Rate() != -1 {
+		t.Errorf("Expected no runs on the first day, got %+v", status.Days[0])
+	}
+
+	// Only the latest finished run's failure is current, plus the queued write
+	if len(status.Errors) != 2 || status.Errors[0].Message != "HTTP 401" || status.Errors[1].Message != "HTTP 503" {
+		t.Errorf("Expected the latest failure and the queued write, got %+v", status.Errors)
+	}
+	if len(status.OrphanedGroups) != 1 || status.OrphanedGroups[0] != "old@example.com" {
+		t.Errorf("Expected orphaned old@example.com, got %v", status.OrphanedGroups)
+	}
+}
+
+func TestEnrollment(t *testing.T) {
+	lister := &mockUserLister{users: []bi.NativeUser{
+		{ID: "1", State: "ACTIVE", HasActivePasskey: true},
+		{ID: "2", State: "ACTIVE"},
+		{ID: "3"},
+		{ID: "4", State: "SUSPENDED", HasActivePasskey: true},
+	}}
+
+	stats, err := Enrollment(context.Background(), lister)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stats.Users != 3 || stats.Enrolled != 1 || stats.Suspended != 1 || stats.Pending() != 2 || stats.Percent() != 33 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	if _, err := Enrollment(context.Background(), &mockUserLister{err: errors.New("HTTP 401")}); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestWriteStatusHTML(t *testing.T) {
+	now := time.Date(2024, 6, 14, 12, 0, 0, 0, time.UTC)
+	store, err := state.Open("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.StartRun(state.Run{ID: "a", Kind: "full", StartedAt: now.Add(-time.Hour)})
+	store.FinishRun("a", now.Add(-time.Hour), errors.New("<script>alert(1)</script>"))
+
+	status := NewStatus(store, "v1.2.3", now)
+	status.Enrollment = &EnrollmentStats{Users: 4, Enrolled: 3}
+
+	var buf bytes.Buffer
+	if err := WriteStatusHTML(&buf, status); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	page := buf.String()
+
+	for _, expected := range []string{"scim-sync v1.2.3", "3 / 4 (75%)", "&lt;script&gt;", "<svg"} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("Expected page to contain %q", expected)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("Expected errors to be escaped")
+	}
+	if strings.Contains(page, "http://") || strings.Contains(page, "https://") {
+		t.Error("Expected no external resources")
+	}
+}
@@ -0,0 +1,326 @@
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Release channels
+const (
+	ChannelStable = "stable" // Releases not marked as prereleases
+	ChannelBeta   = "beta"   // Prereleases as well
+)
+
+// DefaultRepository is the GitHub repository releases are published to
+const DefaultRepository = "gobeyondidentity/Beyond-Identity-and-Google-Workspace-Python-Integration"
+
+// DefaultAPIURL is the GitHub REST API
+const DefaultAPIURL = "https://api.github.com"
+
+// Files published with every release next to the binaries
+const (
+	ChecksumsAsset = "checksums.txt"     // sha256sum output for every binary
+	SignatureAsset = "checksums.txt.sig" // Base64 Ed25519 signature of checksums.txt
+)
+
+// MaxAssetBytes bounds downloads, well above the size of a release binary
+const MaxAssetBytes = 256 << 20
+
+// ErrNoSigningKey is returned when the binary was built without a release signing key
+var ErrNoSigningKey = errors.New("this build has no release signing key")
+
+// Release is a GitHub release
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+}
+
+// asset returns the release's file with the given name
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Updater finds and installs releases of scim-sync
+type Updater struct {
+	HTTPClient *http.Client
+	APIURL     string
+	Repository string            // owner/name
+	PublicKey  ed25519.PublicKey // Verifies checksums.txt; nil refuses to install unless SkipSignature is set
+
+	SkipSignature bool // Install without verifying the signature, e.g. for builds without a key
+}
+
+// ParsePublicKey decodes a base64 Ed25519 public key, as embedded at build time
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release signing key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Latest returns the newest release on the channel, as listed by GitHub (newest first)
+func (u *Updater) Latest(channel string) (*Release, error) {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return nil, fmt.Errorf("unknown channel %q: use %s or %s", channel, ChannelStable, ChannelBeta)
+	}
+
+	body, err := u.get(fmt.Sprintf("%s/repos/%s/releases", strings.TrimSuffix(u.APIURL, "/"), u.Repository))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	var releases []Release
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+
+	for i := range releases {
+		release := &releases[i]
+		if release.Draft || (release.Prerelease && channel == ChannelStable) {
+			continue
+		}
+		return release, nil
+	}
+	return nil, fmt.Errorf("no %s release found in %s", channel, u.Repository)
+}
+
+// AssetName returns the name of the release binary for an OS and architecture
+func AssetName(goos, goarch string) string {
+	name := "scim-sync-" + goos + "-" + goarch
+	switch goos {
+	case "darwin":
+		name = "scim-sync-macos-" + goarch
+	case "windows":
+		name += ".exe"
+	}
+	return name
+}
+
+// Download returns the release binary for this platform after verifying it against the signed
+// checksums published with the release
+func (u *Updater) Download(release *Release) ([]byte, error) {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	binaryAsset, ok := release.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no binary for %s/%s (%s)", release.Tag, runtime.GOOS, runtime.GOARCH, name)
+	}
+	checksumsAsset, ok := release.asset(ChecksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s", release.Tag, ChecksumsAsset)
+	}
+
+	checksums, err := u.get(checksumsAsset.DownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", ChecksumsAsset, err)
+	}
+	if err := u.verifySignature(release, checksums); err != nil {
+		return nil, err
+	}
+
+	want, err := checksumFor(checksums, name)
+	if err != nil {
+		return nil, err
+	}
+	binary, err := u.get(binaryAsset.DownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(binary)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, want, got)
+	}
+	return binary, nil
+}
+
+// verifySignature checks checksums.txt against its detached signature
+func (u *Updater) verifySignature(release *Release, checksums []byte) error {
+	if u.SkipSignature {
+		return nil
+	}
+	if u.PublicKey == nil {
+		return ErrNoSigningKey
+	}
+
+	signatureAsset, ok := release.asset(SignatureAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s", release.Tag, SignatureAsset)
+	}
+	encoded, err := u.get(signatureAsset.DownloadURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", SignatureAsset, err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", SignatureAsset, err)
+	}
+	if !ed25519.Verify(u.PublicKey, checksums, signature) {
+		return fmt.Errorf("signature of %s in release %s is invalid", ChecksumsAsset, release.Tag)
+	}
+	return nil
+}
+
+// checksumFor finds a file's SHA-256 in sha256sum output
+func checksumFor(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", ChecksumsAsset, name)
+}
+
+// Install replaces the executable at path with binary. The new binary is written next to it and
+// renamed over it, so the executable is never partly written; on Windows, where a running
+// executable cannot be replaced, the old one is moved aside to path + ".old" first
+func Install(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".scim-sync-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to write update next to %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(binary); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return fmt.Errorf("failed to make update executable: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// Newer reports whether version a is newer than b. Versions are compared as vMAJOR.MINOR.PATCH
+// with an optional -prerelease, which sorts before the release; other versions, such as dev
+// builds, are older than any release
+func Newer(a, b string) bool {
+	return compareVersions(a, b) > 0
+}
+
+func compareVersions(a, b string) int {
+	coreA, preA, okA := parseVersion(a)
+	coreB, preB, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := range coreA {
+		if coreA[i] != coreB[i] {
+			if coreA[i] > coreB[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	case preA > preB:
+		return 1
+	}
+	return -1
+}
+
+// parseVersion splits v1.2.3-beta.1 into its numbers and prerelease
+func parseVersion(version string) ([3]int, string, bool) {
+	var core [3]int
+	version, ok := strings.CutPrefix(version, "v")
+	if !ok {
+		return core, "", false
+	}
+	version, prerelease, _ := strings.Cut(version, "-")
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return core, "", false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return core, "", false
+		}
+		core[i] = n
+	}
+	return core, prerelease, true
+}
+
+// get fetches a URL, failing on non-2xx responses
+func (u *Updater) get(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxAssetBytes))
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
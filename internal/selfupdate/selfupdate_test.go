@@ -0,0 +1,201 @@
+package selfupdate
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// releaseServer serves a releases list and the assets of one release
+func releaseServer(t *testing.T, releases []Release, files map[string][]byte) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/repo/releases" {
+			for i := range releases {
+				for j := range releases[i].Assets {
+					releases[i].Assets[j].DownloadURL = server.URL + "/download/" + releases[i].Assets[j].Name
+				}
+			}
+			_ = json.NewEncoder(w).Encode(releases)
+			return
+		}
+		content, ok := files[strings.TrimPrefix(r.URL.Path, "/download/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// signedFiles returns a binary for this platform with its checksums signed by key
+func signedFiles(binary []byte, key ed25519.PrivateKey) map[string][]byte {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	sum := sha256.Sum256(binary)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+	return map[string][]byte{
+		name:           binary,
+		ChecksumsAsset: checksums,
+		SignatureAsset: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, checksums)) + "\n"),
+	}
+}
+
+func assets(files map[string][]byte) []Asset {
+	var list []Asset
+	for name := range files {
+		list = append(list, Asset{Name: name})
+	}
+	return list
+}
+
+func TestLatest(t *testing.T) {
+	releases := []Release{
+		{Tag: "v1.3.0", Draft: true},
+		{Tag: "v1.3.0-beta.1", Prerelease: true},
+		{Tag: "v1.2.0"},
+	}
+	server := releaseServer(t, releases, nil)
+	updater := &Updater{HTTPClient: server.Client(), APIURL: server.URL, Repository: "owner/repo"}
+
+	tests := []struct {
+		channel string
+		want    string
+	}{
+		{ChannelStable, "v1.2.0"},
+		{ChannelBeta, "v1.3.0-beta.1"},
+	}
+	for _, tt := range tests {
+		release, err := updater.Latest(tt.channel)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if release.Tag != tt.want {
+			t.Errorf("Expected %s on %s, got %s", tt.want, tt.channel, release.Tag)
+		}
+	}
+
+	if _, err := updater.Latest("nightly"); err == nil {
+		t.Error("Expected an error for an unknown channel")
+	}
+}
+
+func TestDownload(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	binary := []byte("new binary")
+
+	tampered := signedFiles(binary, private)
+	tampered[AssetName(runtime.GOOS, runtime.GOARCH)] = []byte("tampered binary")
+
+	tests := []struct {
+		name      string
+		files     map[string][]byte
+		publicKey ed25519.PublicKey
+		skip      bool
+		wantErr   string
+	}{
+		{name: "signed", files: signedFiles(binary, private), publicKey: public},
+		{name: "signed by another key", files: signedFiles(binary, otherKey), publicKey: public, wantErr: "signature"},
+		{name: "checksum mismatch", files: tampered, publicKey: public, wantErr: "checksum mismatch"},
+		{name: "no signing key", files: signedFiles(binary, private), wantErr: ErrNoSigningKey.Error()},
+		{name: "signature skipped", files: signedFiles(binary, otherKey), skip: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := Release{Tag: "v1.2.0", Assets: assets(tt.files)}
+			server := releaseServer(t, []Release{release}, tt.files)
+			updater := &Updater{HTTPClient: server.Client(), APIURL: server.URL, Repository: "owner/repo", PublicKey: tt.publicKey, SkipSignature: tt.skip}
+
+			latest, err := updater.Latest(ChannelStable)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := updater.Download(latest)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != string(binary) {
+				t.Errorf("Expected the release binary, got %q", got)
+			}
+		})
+	}
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.2.0-beta.1", true},
+		{"v1.2.0-beta.2", "v1.2.0-beta.1", true},
+		{"v1.2.0-beta.1", "v1.2.0", false},
+		{"v1.0.0", "dev", true},
+		{"dev", "v1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := Newer(tt.a, tt.b); got != tt.want {
+			t.Errorf("Newer(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scim-sync")
+	if err := os.WriteFile(path, []byte("old binary"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Install(path, []byte("new binary")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "new binary" {
+		t.Errorf("Expected the binary to be replaced, got %q (%v)", content, err)
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0750|0111 {
+		t.Errorf("Expected the mode to be kept executable, got %v", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files left behind, got %d entries", len(entries))
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	public, _, _ := ed25519.GenerateKey(nil)
+
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(public))
+	if err != nil || !key.Equal(public) {
+		t.Errorf("Expected the key to round-trip, got %v", err)
+	}
+	if key, err := ParsePublicKey(""); key != nil || err != nil {
+		t.Error("Expected no key for an empty value")
+	}
+	if _, err := ParsePublicKey("bm90IGEga2V5"); err == nil {
+		t.Error("Expected an error for a key of the wrong size")
+	}
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// confirmationTTL is how long a deprovisioning confirmation token stays valid
+const confirmationTTL = 5 * time.Minute
+
+// auditActionDeprovision is the audit action of a confirmed deprovisioning
+const auditActionDeprovision = "user_deprovision"
+
+// DeprovisionUserRequest asks to remove a user from every managed group. The first request
+// returns a plan and a confirmation token; repeating it with the token carries it out
+type DeprovisionUserRequest struct {
+	Email             string `json:"email"`
+	Deactivate        bool   `json:"deactivate,omitempty"`
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+	RequestedBy       string `json:"requested_by,omitempty"` // Free-form caller identifier recorded in the audit log
+}
+
+func (r *DeprovisionUserRequest) validate() []FieldError {
+	return requireEmail("email", r.Email)
+}
+
+// DeprovisionUserResponse represents a deprovisioning plan or its result
+type DeprovisionUserResponse struct {
+	Status            string                        `json:"status"`
+	Message           string                        `json:"message"`
+	Timestamp         time.Time                     `json:"timestamp"`
+	Email             string                        `json:"email"`
+	Deactivate        bool                          `json:"deactivate"`
+	ConfirmationToken string                        `json:"confirmation_token,omitempty"`
+	ExpiresAt         *time.Time                    `json:"expires_at,omitempty"`
+	Result            *syncengine.DeprovisionResult `json:"result,omitempty"`
+	Error             string                        `json:"error,omitempty"`
+}
+
+// pendingDeprovision is a deprovisioning awaiting confirmation
+type pendingDeprovision struct {
+	email      string
+	deactivate bool
+	expiresAt  time.Time
+}
+
+// confirmations holds single-use deprovisioning confirmation tokens
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]pendingDeprovision
+}
+
+// newConfirmations creates an empty token store
+func newConfirmations() *confirmations {
+	return &confirmations{pending: make(map[string]pendingDeprovision)}
+}
+
+// issue returns a token confirming a deprovisioning of email with the given deactivate flag
+func (c *confirmations) issue(email string, deactivate bool, now time.Time) (string, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for token, pending := range c.pending {
+		if now.After(pending.expiresAt) {
+			delete(c.pending, token)
+		}
+	}
+
+	token := newJobID() + newJobID()
+	expiresAt := now.Add(confirmationTTL)
+	c.pending[token] = pendingDeprovision{email: email, deactivate: deactivate, expiresAt: expiresAt}
+	return token, expiresAt
+}
+
+// consume reports whether token confirms this exact request, invalidating it either way
+func (c *confirmations) consume(token, email string, deactivate bool, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, ok := c.pending[token]
+	if !ok {
+		return false
+	}
+	delete(c.pending, token)
+	return strings.EqualFold(pending.email, email) && pending.deactivate == deactivate && !now.After(pending.expiresAt)
+}
+
+// handleDeprovisionUser removes a user from all managed groups, and optionally deactivates them,
+// once the caller repeats the request with the confirmation token from the plan
+func (s *Server) handleDeprovisionUser(w http.ResponseWriter, r *http.Request) {
+	if !s.config.FeatureEnabled(config.FeatureDeprovisioning) {
+		http.Error(w, "Deprovisioning is disabled by features.deprovisioning", http.StatusForbidden)
+		return
+	}
+
+	var req DeprovisionUserRequest
+	if !s.decodeRequest(w, r, &req, false) {
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+
+	response := DeprovisionUserResponse{
+		Timestamp:  time.Now(),
+		Email:      req.Email,
+		Deactivate: req.Deactivate,
+	}
+	status := http.StatusOK
+
+	if req.ConfirmationToken == "" {
+		s.logger.Infof("Deprovisioning plan for %s requested via API", req.Email)
+		plan, err := s.syncEngine.PlanDeprovision(r.Context(), req.Email)
+		if err != nil {
+			s.logger.Errorf("Deprovisioning plan for %s failed: %v", req.Email, err)
+			response.Status = "error"
+			response.Message = "Failed to plan deprovisioning"
+			response.Error = err.Error()
+			status = http.StatusInternalServerError
+		} else {
+			token, expiresAt := s.deprovisions.issue(req.Email, req.Deactivate, time.Now())
+			response.Status = "confirmation_required"
+			response.Message = "Repeat the request with confirmation_token to deprovision the user"
+			response.ConfirmationToken = token
+			response.ExpiresAt = &expiresAt
+			response.Result = plan
+		}
+		s.writeDeprovisionResponse(w, status, response)
+		return
+	}
+
+	if !s.deprovisions.consume(req.ConfirmationToken, req.Email, req.Deactivate, time.Now()) {
+		s.logger.Warnf("Rejected deprovisioning of %s from %s: invalid confirmation token", req.Email, r.RemoteAddr)
+		http.Error(w, "Invalid or expired confirmation token", http.StatusForbidden)
+		return
+	}
+
+	s.logger.Infof("Deprovisioning of %s confirmed via API (requested by %q)", req.Email, req.RequestedBy)
+	result, err := s.syncEngine.DeprovisionUser(r.Context(), req.Email, req.Deactivate)
+
+	record := audit.Record{
+		Time:        time.Now().UTC(),
+		Action:      auditActionDeprovision,
+		Subject:     req.Email,
+		RequestedBy: req.RequestedBy,
+		RemoteAddr:  r.RemoteAddr,
+	}
+
+	switch {
+	case err != nil:
+		s.logger.Errorf("Deprovisioning of %s failed: %v", req.Email, err)
+		response.Status = "error"
+		response.Message = "Deprovisioning failed"
+		response.Error = err.Error()
+		status = http.StatusInternalServerError
+		record.Error = err.Error()
+	case len(result.Errors) > 0:
+		response.Status = "partial"
+		response.Message = "User deprovisioned with errors"
+		response.Result = result
+	default:
+		response.Status = "success"
+		response.Message = "User deprovisioned"
+		response.Result = result
+	}
+
+	record.Outcome = response.Status
+	if result != nil {
+		record.Details = result
+	}
+	if err := s.audit.Write(record); err != nil {
+		s.logger.Errorf("Failed to write audit record for deprovisioning of %s: %v", req.Email, err)
+	}
+
+	s.writeDeprovisionResponse(w, status, response)
+}
+
+// writeDeprovisionResponse writes a deprovisioning response as JSON
+func (s *Server) writeDeprovisionResponse(w http.ResponseWriter, status int, response DeprovisionUserResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode deprovision response", "error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// Audit actions of emergency stops
+const (
+	auditActionEmergencyStop   = "emergency_stop"
+	auditActionEmergencyResume = "emergency_resume"
+)
+
+// EmergencyStopRequest stops or resumes sync runs; the body is optional
+type EmergencyStopRequest struct {
+	Reason      string `json:"reason,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"` // Free-form caller identifier recorded in the audit log
+}
+
+func (r *EmergencyStopRequest) validate() []FieldError {
+	return nil
+}
+
+// EmergencyStopResponse reports whether sync runs are disabled
+type EmergencyStopResponse struct {
+	Stopped bool                      `json:"stopped"`
+	Stop    *syncengine.EmergencyStop `json:"stop,omitempty"`
+}
+
+// handleEmergencyStop disables sync runs until resumed: the run in progress stops at its next
+// group or user, and scheduled runs, queued jobs and new requests are refused
+func (s *Server) handleEmergencyStop(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeEmergencyRequest(w, r)
+	if !ok {
+		return
+	}
+
+	stop, err := s.syncEngine.EmergencyStop(req.Reason, req.RequestedBy)
+	s.auditEmergency(r, auditActionEmergencyStop, req, err)
+	if err != nil {
+		s.logger.Errorf("Emergency stop failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeEmergencyStatus(w, stop)
+}
+
+// handleEmergencyResume lifts an emergency stop made through the API or the disable file
+func (s *Server) handleEmergencyResume(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeEmergencyRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.syncEngine.ResumeSyncs()
+	s.auditEmergency(r, auditActionEmergencyResume, req, err)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	s.logger.Warnf("Sync runs resumed via API (requested by %q): %s", req.RequestedBy, req.Reason)
+	s.writeEmergencyStatus(w, s.syncEngine.EmergencyStopped())
+}
+
+// handleEmergencyStatus reports whether an emergency stop is in effect
+func (s *Server) handleEmergencyStatus(w http.ResponseWriter, r *http.Request) {
+	s.writeEmergencyStatus(w, s.syncEngine.EmergencyStopped())
+}
+
+// decodeEmergencyRequest reads the optional request body
+func (s *Server) decodeEmergencyRequest(w http.ResponseWriter, r *http.Request) (EmergencyStopRequest, bool) {
+	var req EmergencyStopRequest
+	ok := s.decodeRequest(w, r, &req, true)
+	return req, ok
+}
+
+// auditEmergency records an emergency stop or resume
+func (s *Server) auditEmergency(r *http.Request, action string, req EmergencyStopRequest, err error) {
+	record := audit.Record{
+		Time:        time.Now().UTC(),
+		Action:      action,
+		Subject:     "server",
+		RequestedBy: req.RequestedBy,
+		RemoteAddr:  r.RemoteAddr,
+		Outcome:     "success",
+		Details:     map[string]interface{}{"reason": req.Reason},
+	}
+	if err != nil {
+		record.Outcome = "failed"
+		record.Error = err.Error()
+	}
+	if err := s.audit.Write(record); err != nil {
+		s.logger.Errorf("Failed to write audit record for %s: %v", action, err)
+	}
+}
+
+func (s *Server) writeEmergencyStatus(w http.ResponseWriter, stop *syncengine.EmergencyStop) {
+	response := EmergencyStopResponse{Stopped: stop != nil, Stop: stop}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode emergency stop response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
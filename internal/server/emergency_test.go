@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
+	"github.com/gorilla/mux"
+)
+
+func TestHandleEmergencyStop(t *testing.T) {
+	server := createTestServer(t)
+	server.audit = audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	post := func(path, body string) (*httptest.ResponseRecorder, EmergencyStopResponse) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		var response EmergencyStopResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	rr, response := post("/emergency-stop", `{"reason":"bad upstream change","requested_by":"oncall"}`)
+	if rr.Code != http.StatusOK || !response.Stopped || response.Stop.Reason != "bad upstream change" {
+		t.Fatalf("Expected syncs to be stopped, got %d %+v", rr.Code, response)
+	}
+	if !server.features()["emergency_stop"] {
+		t.Error("Expected /info to report the emergency stop")
+	}
+
+	// New syncs are refused while stopped
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/sync", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected sync to be refused with 503, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/emergency-stop", nil))
+	if !strings.Contains(rr.Body.String(), `"stopped":true`) {
+		t.Errorf("Expected the status to report the stop, got %s", rr.Body.String())
+	}
+
+	// The body is optional
+	rr, response = post("/emergency-stop/resume", "")
+	if rr.Code != http.StatusOK || response.Stopped {
+		t.Fatalf("Expected syncs to be resumed, got %d %+v", rr.Code, response)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/sync", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected sync to run after resuming, got %d", rr.Code)
+	}
+
+	raw, err := os.ReadFile(server.audit.Path())
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], auditActionEmergencyStop) || !strings.Contains(lines[1], auditActionEmergencyResume) {
+		t.Errorf("Expected the stop and resume to be audited, got %q", raw)
+	}
+}
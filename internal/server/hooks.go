@@ -0,0 +1,136 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gorilla/mux"
+)
+
+// signatureHeader carries the hex HMAC-SHA256 of the request body, prefixed with "sha256="
+const signatureHeader = "X-Signature-256"
+
+// maxHookBodyBytes bounds the size of webhook requests
+const maxHookBodyBytes = 64 << 10
+
+// jobQueueSize is how many targeted syncs may wait behind the one running
+const jobQueueSize = 100
+
+// TriggerRequest asks for an immediate sync of one user or one configured group
+type TriggerRequest struct {
+	User        string `json:"user,omitempty"`
+	Group       string `json:"group,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"` // Free-form caller identifier recorded on the job
+}
+
+func (r *TriggerRequest) validate() []FieldError {
+	switch {
+	case r.User != "" && r.Group != "":
+		return []FieldError{{Field: "group", Message: "specify either user or group, not both"}}
+	case r.User == "" && r.Group == "":
+		return []FieldError{{Field: "user", Message: "user or group is required"}}
+	case r.User != "":
+		return requireEmail("user", r.User)
+	}
+	return nil
+}
+
+// handleTrigger verifies the request signature and enqueues a targeted sync
+func (s *Server) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHookBodyBytes))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	if !validSignature(s.config.Server.WebhookSecret, body, r.Header.Get(signatureHeader)) {
+		s.logger.Warnf("Rejected webhook trigger from %s: invalid signature", r.RemoteAddr)
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var req TriggerRequest
+	if !s.checkRequest(w, body, &req, false) {
+		return
+	}
+
+	kind, subject := JobKindUser, req.User
+	if req.Group != "" {
+		if !s.isSyncedGroup(req.Group) {
+			s.writeValidationErrors(w, []FieldError{{Field: "group", Message: "is not configured for sync"}})
+			return
+		}
+		kind, subject = JobKindGroup, req.Group
+	}
+
+	if stop := s.syncEngine.EmergencyStopped(); stop != nil {
+		http.Error(w, fmt.Sprintf("%v: %s", syncengine.ErrSyncDisabled, stop), http.StatusServiceUnavailable)
+		return
+	}
+
+	job, err := s.jobs.Enqueue(kind, subject, req.RequestedBy)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrJobQueueFull) || errors.Is(err, ErrJobQueueStopped) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		s.logger.Error("Failed to encode job response", "error", err)
+	}
+}
+
+// handleJobStatus reports the status of a queued targeted sync
+func (s *Server) handleJobStatus(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		s.logger.Error("Failed to encode job response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// isSyncedGroup reports whether a group is listed under sync.groups
+func (s *Server) isSyncedGroup(groupEmail string) bool {
+	for _, group := range s.config.Sync.Groups {
+		if strings.EqualFold(group, groupEmail) {
+			return true
+		}
+	}
+	return false
+}
+
+// validSignature checks a "sha256=<hex>" HMAC of body in constant time
+func validSignature(secret string, body []byte, header string) bool {
+	if secret == "" {
+		return false
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(header, "sha256="))
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidSignature(t *testing.T) {
+	body := []byte(`{"user":"alice@example.com"}`)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		want   bool
+	}{
+		{"valid", "secret", sign("secret", body), true},
+		{"wrong secret", "secret", sign("other", body), false},
+		{"missing header", "secret", "", false},
+		{"not hex", "secret", "sha256=zz", false},
+		{"no secret configured", "", sign("", body), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validSignature(tt.secret, body, tt.header); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleTrigger(t *testing.T) {
+	server := createTestServer(t)
+	server.config.Server.WebhookSecret = "secret"
+	server.config.Sync.Groups = []string{"eng@example.com"}
+	server.jobs = NewJobQueue(server.syncEngine, server.logger, 10)
+	server.jobs.Start()
+	defer server.jobs.Stop()
+
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	tests := []struct {
+		name       string
+		body       string
+		signature  string
+		wantStatus int
+	}{
+		{"user", `{"user":"alice@example.com"}`, "", http.StatusAccepted},
+		{"group", `{"group":"ENG@example.com"}`, "", http.StatusAccepted},
+		{"unconfigured group", `{"group":"other@example.com"}`, "", http.StatusUnprocessableEntity},
+		{"both", `{"user":"alice@example.com","group":"eng@example.com"}`, "", http.StatusUnprocessableEntity},
+		{"empty", `{}`, "", http.StatusUnprocessableEntity},
+		{"unknown field", `{"user":"alice@example.com","priority":"high"}`, "", http.StatusUnprocessableEntity},
+		{"invalid json", `{"user":`, "", http.StatusBadRequest},
+		{"bad signature", `{"user":"alice@example.com"}`, "sha256=00", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(tt.body)
+			signature := tt.signature
+			if signature == "" {
+				signature = sign("secret", body)
+			}
+
+			req := httptest.NewRequest("POST", "/hooks/trigger", bytes.NewReader(body))
+			req.Header.Set(signatureHeader, signature)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+
+			var job Job
+			if err := json.Unmarshal(rr.Body.Bytes(), &job); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+
+			// Poll the job until the worker finishes it
+			deadline := time.Now().Add(2 * time.Second)
+			for {
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, httptest.NewRequest("GET", "/jobs/"+job.ID, nil))
+				if rr.Code != http.StatusOK {
+					t.Fatalf("Expected status code 200 for job status, got %d", rr.Code)
+				}
+				if err := json.Unmarshal(rr.Body.Bytes(), &job); err != nil {
+					t.Fatalf("Failed to parse job: %v", err)
+				}
+				if job.Status == JobStatusSucceeded {
+					break
+				}
+				if job.Status == JobStatusFailed || time.Now().After(deadline) {
+					t.Fatalf("Expected job to succeed, got %+v", job)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestJobQueue_Full(t *testing.T) {
+	server := createTestServer(t)
+	queue := NewJobQueue(server.syncEngine, server.logger, 1)
+
+	if _, err := queue.Enqueue(JobKindUser, "alice@example.com", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := queue.Enqueue(JobKindUser, "bob@example.com", ""); err != ErrJobQueueFull {
+		t.Errorf("Expected ErrJobQueueFull, got %v", err)
+	}
+
+	queue.Start()
+	queue.Stop()
+	if _, err := queue.Enqueue(JobKindUser, "carol@example.com", ""); err != ErrJobQueueStopped {
+		t.Errorf("Expected ErrJobQueueStopped, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// BuildInfo identifies the running build
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+}
+
+// buildInfo is reported by /info, /version and /health; see SetBuildInfo
+var buildInfo = BuildInfo{Version: "dev", Commit: "unknown", Date: "unknown", GoVersion: runtime.Version()}
+
+// SetBuildInfo sets the build reported by the server endpoints
+func SetBuildInfo(version, commit, date string) {
+	buildInfo = BuildInfo{Version: version, Commit: commit, Date: date, GoVersion: runtime.Version()}
+}
+
+// InfoResponse describes a deployment for inventory tooling
+type InfoResponse struct {
+	Build         BuildInfo       `json:"build"`
+	StartedAt     time.Time       `json:"started_at"`
+	UptimeSeconds int64           `json:"uptime_seconds"`
+	Profile       string          `json:"profile,omitempty"` // Config profile applied over the base settings
+	Features      map[string]bool `json:"features"`
+	Domain        string          `json:"domain"` // Redacted Google Workspace domain
+	Tenants       []TenantInfo    `json:"tenants"`
+	GroupCount    int             `json:"group_count"`
+}
+
+// TenantInfo describes a provisioning target without identifying the tenant
+type TenantInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Host string `json:"host"` // Redacted API host
+}
+
+// handleInfo handles deployment information requests
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	response := InfoResponse{
+		Build:      buildInfo,
+		StartedAt:  s.startedAt,
+		Profile:    s.config.Profile,
+		Features:   s.features(),
+		Domain:     redactHost(s.config.GoogleWorkspace.Domain),
+		Tenants:    tenantInfo(s.config),
+		GroupCount: len(s.config.Sync.Groups),
+	}
+	if !s.startedAt.IsZero() {
+		response.UptimeSeconds = int64(time.Since(s.startedAt).Seconds())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode info response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// features reports which optional behaviors this deployment has enabled
+func (s *Server) features() map[string]bool {
+	cfg := s.config
+	notifications := cfg.Notifications.Slack.WebhookURL != "" || cfg.Notifications.Teams.WebhookURL != "" ||
+		cfg.Notifications.GoogleChat.WebhookURL != ""
+	incidents := cfg.Notifications.PagerDuty.RoutingKey != "" || cfg.Notifications.Opsgenie.APIKey != ""
+
+	// Notifications, reminders and scheduled exports are sent by the scheduler
+	scheduled := s.scheduler != nil
+	features := map[string]bool{
+		"scheduler":              scheduled,
+		"test_mode":              cfg.App.TestMode,
+		"test_mode_operations":   len(cfg.Sync.TestModeOperations) > 0,
+		"read_only":              s.syncEngine.ReadOnly(),
+		"emergency_stop":         s.syncEngine.EmergencyStopped() != nil,
+		"webhooks":               s.jobs != nil,
+		"deprovision_audit_log":  cfg.Server.AuditLogPath != "",
+		"wait_for_initial_sync":  cfg.Server.WaitForInitialSync,
+		"notifications":          scheduled && notifications,
+		"notification_digest":    scheduled && notifications && cfg.Notifications.Mode == config.NotificationModeDigest,
+		"membership_diffs":       scheduled && notifications && cfg.Notifications.MembershipDiffs.Enabled,
+		"incidents":              scheduled && incidents,
+		"reminders":              scheduled && cfg.Reminders.Enabled,
+		"access_review_exports":  scheduled && cfg.AccessReview.Schedule != "",
+		"enrollment_group":       cfg.Sync.EnrollmentGroupEmail != "",
+		"enrollment_schema":      cfg.Sync.EnrollmentSchema.Enabled,
+		"orphaned_group_archive": cfg.Sync.OrphanedGroups.Archive,
+		"orphaned_group_cleanup": cfg.Sync.OrphanedGroups.Cleanup != "",
+		"csv_source":             cfg.Source.Type == config.SourceTypeCSV,
+		"azure_ad_source":        cfg.Source.Type == config.SourceTypeAzureAD,
+		"ldap_source":            cfg.Source.Type == config.SourceTypeLDAP,
+		"cloud_identity":         cfg.GoogleWorkspace.API == config.GWSAPICloudIdentity,
+	}
+
+	// Feature flags are reported with their effective values
+	for _, flag := range cfg.FeatureStates() {
+		features[flag.Name] = flag.Enabled
+	}
+	return features
+}
+
+// FeaturesResponse lists the registered feature flags
+type FeaturesResponse struct {
+	Features []config.FeatureState `json:"features"`
+}
+
+// handleFeatures handles feature flag inspection requests
+func (s *Server) handleFeatures(w http.ResponseWriter, r *http.Request) {
+	response := FeaturesResponse{Features: s.config.FeatureStates()}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode features response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// tenantInfo lists the default target followed by the additional targets
+func tenantInfo(cfg *config.Config) []TenantInfo {
+	tenants := []TenantInfo{{
+		Name: config.DefaultTargetName,
+		Type: cfg.PrimaryTargetType(),
+		Host: redactURLHost(cfg.BeyondIdentity.SCIMBaseURL),
+	}}
+	if !cfg.PrimaryIsBeyondIdentity() {
+		tenants[0].Host = redactURLHost(cfg.Target.SCIMBaseURL)
+	}
+	for _, target := range cfg.Targets {
+		info := TenantInfo{Name: target.Name, Type: target.Type, Host: redactURLHost(target.SCIMBaseURL)}
+		if target.Type == config.TargetTypeOkta {
+			info.Host = redactURLHost(target.OrgURL)
+		}
+		if info.Type == "" {
+			info.Type = config.TargetTypeBeyondIdentity
+		}
+		tenants = append(tenants, info)
+	}
+	return tenants
+}
+
+// redactURLHost returns the redacted host of an API URL
+func redactURLHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	return redactHost(parsed.Hostname())
+}
+
+// redactHost masks every label of a host name but the top-level domain, keeping the first
+// character, e.g. "acme-corp.okta.com" becomes "a***.o***.com"
+func redactHost(host string) string {
+	if host == "" {
+		return ""
+	}
+	labels := strings.Split(host, ".")
+	masked := len(labels) - 1
+	if masked == 0 {
+		masked = 1
+	}
+	for i := 0; i < masked; i++ {
+		if labels[i] != "" {
+			labels[i] = labels[i][:1] + "***"
+		}
+	}
+	return strings.Join(labels, ".")
+}
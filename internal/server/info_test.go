@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func TestHandleInfo(t *testing.T) {
+	SetBuildInfo("1.2.3", "abc123", "2024-06-01T00:00:00Z")
+	defer SetBuildInfo("dev", "unknown", "unknown")
+
+	server := createTestServer(t)
+	server.startedAt = time.Now().Add(-time.Minute)
+	server.config.GoogleWorkspace.Domain = "acme-corp.com"
+	server.config.BeyondIdentity.SCIMBaseURL = "https://api.acme.byndid.com/scim/v2"
+	server.config.Sync.Groups = []string{"eng@acme-corp.com", "sales@acme-corp.com"}
+	server.config.Server.AuditLogPath = "./audit.log"
+	server.config.Profile = "staging"
+	server.config.Targets = []config.TargetConfig{
+		{Name: "emea", Type: config.TargetTypeOkta, OrgURL: "https://acme-emea.okta.com"},
+	}
+
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	req := httptest.NewRequest("GET", "/info", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", rr.Code)
+	}
+
+	var response InfoResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response.Build.Version != "1.2.3" || response.Build.Commit != "abc123" || response.Build.GoVersion == "" {
+		t.Errorf("Expected build info to be reported, got %+v", response.Build)
+	}
+	if response.UptimeSeconds < 60 {
+		t.Errorf("Expected uptime of at least 60s, got %d", response.UptimeSeconds)
+	}
+	if response.Profile != "staging" {
+		t.Errorf("Expected the staging profile, got %q", response.Profile)
+	}
+	if response.GroupCount != 2 {
+		t.Errorf("Expected 2 groups, got %d", response.GroupCount)
+	}
+	if response.Features["scheduler"] || !response.Features["deprovision_audit_log"] {
+		t.Errorf("Expected features to reflect the configuration, got %v", response.Features)
+	}
+	if response.Domain != "a***.com" {
+		t.Errorf("Expected redacted domain, got %q", response.Domain)
+	}
+	if len(response.Tenants) != 2 || response.Tenants[1].Name != "emea" || response.Tenants[1].Host != "a***.o***.com" {
+		t.Errorf("Expected redacted tenants, got %+v", response.Tenants)
+	}
+	if strings.Contains(rr.Body.String(), "acme") {
+		t.Errorf("Expected tenant and domain names to be redacted, got %s", rr.Body.String())
+	}
+}
+
+func TestRedactHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"", ""},
+		{"localhost", "l***"},
+		{"example.com", "e***.com"},
+		{"api.byndid.com", "a***.b***.com"},
+	}
+
+	for _, tt := range tests {
+		if got := redactHost(tt.host); got != tt.expected {
+			t.Errorf("redactHost(%q) = %q, expected %q", tt.host, got, tt.expected)
+		}
+	}
+}
+
+func TestHandleFeatures(t *testing.T) {
+	server := createTestServer(t)
+	server.config.Features = map[string]bool{config.FeatureBulkAPI: true}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/features", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", rr.Code)
+	}
+
+	var response FeaturesResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	enabled := make(map[string]bool)
+	for _, flag := range response.Features {
+		enabled[flag.Name] = flag.Enabled
+	}
+	if !enabled[config.FeatureBulkAPI] || !enabled[config.FeatureDeprovisioning] {
+		t.Errorf("Expected bulk_api and deprovisioning to be enabled, got %+v", response.Features)
+	}
+}
@@ -1,8 +1,39 @@
 package server
 
-import "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+import (
+	"context"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
 
 // SyncEngine interface for sync operations
 type SyncEngine interface {
-	Sync() (*sync.SyncResult, error)
+	Sync(ctx context.Context) (*sync.SyncResult, error)
+	SyncFull(ctx context.Context) (*sync.SyncResult, error)
+	SyncGroups(ctx context.Context, groupEmails []string) (*sync.SyncResult, error)
+	SyncUser(ctx context.Context, email string) (*sync.SyncResult, error)
+	ProvisionUser(ctx context.Context, email string) (*sync.UserProvisionResult, error)
+	PlanDeprovision(ctx context.Context, email string) (*sync.DeprovisionResult, error)
+	DeprovisionUser(ctx context.Context, email string, deactivate bool) (*sync.DeprovisionResult, error)
+	UserAccess(ctx context.Context, email string) (*sync.UserAccess, error)
+	SkipUser(email, reason, addedBy string, ttl time.Duration) (state.SkippedUser, error)
+	UnskipUser(email string) (bool, error)
+	SkippedUsers() []state.SkippedUser
+	Changes(since, until time.Time) []state.Change
+	RecentRuns() []sync.RecentRun
+	RecentRun(id string) (sync.RecentRun, bool)
+	RunInProgress() (sync.ActiveRun, bool)
+	CancelRun(id string) error
+	StopRun(id string) error
+	PauseRun(id string) error
+	ResumeRun(id string) error
+	AccessReview(ctx context.Context) ([]report.AccessReviewGroup, error)
+	SetReadOnly(enabled bool)
+	ReadOnly() bool
+	EmergencyStop(reason, requestedBy string) (*sync.EmergencyStop, error)
+	ResumeSyncs() error
+	EmergencyStopped() *sync.EmergencyStop
 }
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/sirupsen/logrus"
+)
+
+// Job kinds
+const (
+	JobKindUser  = "user"
+	JobKindGroup = "group"
+)
+
+// Job statuses
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// jobHistoryLimit is how many finished jobs are kept for status lookups
+const jobHistoryLimit = 100
+
+// Errors returned by Enqueue
+var (
+	ErrJobQueueFull    = errors.New("job queue is full")
+	ErrJobQueueStopped = errors.New("job queue is stopped")
+)
+
+// Job is a targeted sync requested through the API
+type Job struct {
+	ID          string                 `json:"id"`
+	Kind        string                 `json:"kind"`
+	Subject     string                 `json:"subject"`
+	RequestedBy string                 `json:"requested_by,omitempty"`
+	Status      string                 `json:"status"`
+	EnqueuedAt  time.Time              `json:"enqueued_at"`
+	StartedAt   *time.Time             `json:"started_at,omitempty"`
+	FinishedAt  *time.Time             `json:"finished_at,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	Result      *syncengine.SyncResult `json:"result,omitempty"`
+}
+
+// JobQueue runs targeted syncs one at a time in the background
+type JobQueue struct {
+	engine SyncEngine
+	logger *logrus.Logger
+	queue  chan *Job
+	done   chan struct{}
+	ctx    context.Context // Cancelled by Stop to abort the running job
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	jobs    map[string]*Job
+	order   []string
+	stopped bool
+}
+
+// NewJobQueue creates a queue holding up to size pending jobs
+func NewJobQueue(engine SyncEngine, logger *logrus.Logger, size int) *JobQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobQueue{
+		ctx:    ctx,
+		cancel: cancel,
+		engine: engine,
+		logger: logger,
+		queue:  make(chan *Job, size),
+		done:   make(chan struct{}),
+		jobs:   make(map[string]*Job),
+	}
+}
+
+// Start runs queued jobs until Stop is called
+func (q *JobQueue) Start() {
+	go func() {
+		defer close(q.done)
+		for job := range q.queue {
+			q.run(job)
+		}
+	}()
+}
+
+// Stop stops accepting jobs, cancels the running one and waits for the queue to drain;
+// jobs still queued fail as cancelled
+func (q *JobQueue) Stop() {
+	q.mu.Lock()
+	q.stopped = true
+	close(q.queue)
+	q.mu.Unlock()
+
+	q.cancel()
+	<-q.done
+}
+
+// Enqueue adds a targeted sync of a user or group to the queue
+func (q *JobQueue) Enqueue(kind, subject, requestedBy string) (*Job, error) {
+	job := &Job{
+		ID:          newJobID(),
+		Kind:        kind,
+		Subject:     subject,
+		RequestedBy: requestedBy,
+		Status:      JobStatusQueued,
+		EnqueuedAt:  time.Now(),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopped {
+		return nil, ErrJobQueueStopped
+	}
+
+	select {
+	case q.queue <- job:
+	default:
+		return nil, ErrJobQueueFull
+	}
+
+	q.jobs[job.ID] = job
+	q.order = append(q.order, job.ID)
+	if len(q.order) > jobHistoryLimit {
+		delete(q.jobs, q.order[0])
+		q.order = q.order[1:]
+	}
+
+	q.logger.Infof("Queued %s sync of %s (job %s)", kind, subject, job.ID)
+	copied := *job
+	return &copied, nil
+}
+
+// Get returns a snapshot of a job
+func (q *JobQueue) Get(id string) (Job, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// run executes a job and records its outcome
+func (q *JobQueue) run(job *Job) {
+	started := time.Now()
+	q.update(func() {
+		job.Status = JobStatusRunning
+		job.StartedAt = &started
+	})
+
+	var result *syncengine.SyncResult
+	var err error
+	switch job.Kind {
+	case JobKindUser:
+		result, err = q.engine.SyncUser(q.ctx, job.Subject)
+	case JobKindGroup:
+		result, err = q.engine.SyncGroups(q.ctx, []string{job.Subject})
+	default:
+		err = fmt.Errorf("unknown job kind %q", job.Kind)
+	}
+
+	finished := time.Now()
+	q.update(func() {
+		job.FinishedAt = &finished
+		job.Result = result
+		if err != nil {
+			job.Status = JobStatusFailed
+			job.Error = err.Error()
+		} else {
+			job.Status = JobStatusSucceeded
+		}
+	})
+
+	if err != nil {
+		q.logger.Errorf("Job %s (%s sync of %s) failed: %v", job.ID, job.Kind, job.Subject, err)
+		return
+	}
+	q.logger.Infof("Job %s (%s sync of %s) completed in %v", job.ID, job.Kind, job.Subject, finished.Sub(started))
+}
+
+// update changes a job under the lock so Get never sees a partial update
+func (q *JobQueue) update(change func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	change()
+}
+
+// newJobID returns a random job identifier
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
 	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
 )
 
@@ -24,25 +25,54 @@ type Metrics struct {
 	lastSyncTime            *time.Time
 	lastError               error
 	uptime                  time.Time
+	runtime                 *RuntimeStats // Latest runtime monitor sample
+	quota                   map[string]QuotaStats
+	runsInProgress          int
+	apiCalls                map[string]*APICallStats // By host
+	retryQueueDepth         int                      // Writes queued for retry after the last sync
+	gwsCalls                *gws.CallMetrics         // Admin SDK requests by method
+}
+
+// APICallStats counts the outbound API calls to one host
+type APICallStats struct {
+	Calls           int           `json:"calls"`
+	Errors          int           `json:"errors"` // Failed requests and responses with status 400 or above
+	TotalDuration   time.Duration `json:"-"`
+	AverageDuration time.Duration `json:"average_duration"`
+}
+
+// QuotaStats is a target's API quota as of its last sync
+type QuotaStats struct {
+	Limit           int        `json:"limit"`
+	Remaining       int        `json:"remaining"`
+	Reset           *time.Time `json:"reset,omitempty"`
+	LastRunRequests int64      `json:"last_run_requests"`
+	LastRunFraction float64    `json:"last_run_fraction"` // Share of the available quota used by the last run
 }
 
 // MetricsStats represents the current metrics statistics
 type MetricsStats struct {
-	TotalSyncs              int           `json:"total_syncs"`
-	SuccessfulSyncs         int           `json:"successful_syncs"`
-	FailedSyncs             int           `json:"failed_syncs"`
-	SuccessRate             float64       `json:"success_rate"`
-	TotalUsersCreated       int           `json:"total_users_created"`
-	TotalUsersUpdated       int           `json:"total_users_updated"`
-	TotalGroupsCreated      int           `json:"total_groups_created"`
-	TotalGroupsProcessed    int           `json:"total_groups_processed"`
-	TotalMembershipsAdded   int           `json:"total_memberships_added"`
-	TotalMembershipsRemoved int           `json:"total_memberships_removed"`
-	LastSyncDuration        time.Duration `json:"last_sync_duration"`
-	AverageSyncDuration     time.Duration `json:"average_sync_duration"`
-	LastSyncTime            *time.Time    `json:"last_sync_time"`
-	LastError               string        `json:"last_error,omitempty"`
-	Uptime                  time.Duration `json:"uptime"`
+	TotalSyncs              int                      `json:"total_syncs"`
+	SuccessfulSyncs         int                      `json:"successful_syncs"`
+	FailedSyncs             int                      `json:"failed_syncs"`
+	SuccessRate             float64                  `json:"success_rate"`
+	TotalUsersCreated       int                      `json:"total_users_created"`
+	TotalUsersUpdated       int                      `json:"total_users_updated"`
+	TotalGroupsCreated      int                      `json:"total_groups_created"`
+	TotalGroupsProcessed    int                      `json:"total_groups_processed"`
+	TotalMembershipsAdded   int                      `json:"total_memberships_added"`
+	TotalMembershipsRemoved int                      `json:"total_memberships_removed"`
+	LastSyncDuration        time.Duration            `json:"last_sync_duration"`
+	AverageSyncDuration     time.Duration            `json:"average_sync_duration"`
+	LastSyncTime            *time.Time               `json:"last_sync_time"`
+	LastError               string                   `json:"last_error,omitempty"`
+	Uptime                  time.Duration            `json:"uptime"`
+	Runtime                 *RuntimeStats            `json:"runtime,omitempty"`
+	Quota                   map[string]QuotaStats    `json:"quota,omitempty"` // Remaining API quota by target
+	RunsInProgress          int                      `json:"runs_in_progress"`
+	APICalls                map[string]APICallStats  `json:"api_calls,omitempty"` // Outbound calls by host
+	RetryQueueDepth         int                      `json:"retry_queue_depth"`   // Failed writes waiting to be retried
+	GWSCalls                map[string]gws.CallStats `json:"gws_calls,omitempty"` // Admin SDK requests by method
 }
 
 // NewMetrics creates a new metrics collector
@@ -72,6 +102,8 @@ func (m *Metrics) RecordSync(result *syncengine.SyncResult, duration time.Durati
 	m.totalMembershipsRemoved += result.MembershipsRemoved
 
 	m.lastSyncDuration = duration
+	m.retryQueueDepth = result.QueueDepth
+	m.recordQuota(result.QuotaUsage)
 
 	// Calculate average duration
 	if m.totalSyncs > 0 {
@@ -92,6 +124,33 @@ func (m *Metrics) RecordSync(result *syncengine.SyncResult, duration time.Durati
 	}
 }
 
+// recordQuota keeps the latest API quota of each target that reported one
+func (m *Metrics) recordQuota(usages []syncengine.QuotaUsage) {
+	if len(usages) > 0 && m.quota == nil {
+		m.quota = make(map[string]QuotaStats)
+	}
+	for _, usage := range usages {
+		stats := QuotaStats{
+			Limit:           usage.Limit,
+			Remaining:       usage.Remaining,
+			LastRunRequests: usage.Used,
+			LastRunFraction: usage.Fraction,
+		}
+		if !usage.Reset.IsZero() {
+			reset := usage.Reset
+			stats.Reset = &reset
+		}
+		m.quota[usage.Target] = stats
+	}
+}
+
+// SuccessfulSyncs returns the number of syncs that completed without errors
+func (m *Metrics) SuccessfulSyncs() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.successfulSyncs
+}
+
 // RecordFailedSync records a failed sync operation
 func (m *Metrics) RecordFailedSync(err error, duration time.Duration) {
 	m.mu.Lock()
@@ -145,7 +204,45 @@ func (m *Metrics) GetStats() *MetricsStats {
 		LastSyncTime:            m.lastSyncTime,
 		LastError:               lastErrorStr,
 		Uptime:                  time.Since(m.uptime),
+		Runtime:                 m.runtimeStats(),
+		Quota:                   m.quotaStats(),
+		RunsInProgress:          m.runsInProgress,
+		APICalls:                m.apiCallStats(),
+		RetryQueueDepth:         m.retryQueueDepth,
+		GWSCalls:                m.gwsCalls.Stats(),
+	}
+}
+
+// SetGWSCallMetrics sets where the Google Workspace client times its requests, reported by method
+func (m *Metrics) SetGWSCallMetrics(calls *gws.CallMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gwsCalls = calls
+}
+
+// RecordRuntime records a runtime monitor sample, carrying peaks over from earlier samples
+func (m *Metrics) RecordRuntime(stats RuntimeStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats.PeakGoroutines = stats.Goroutines
+	stats.PeakHeapAllocBytes = stats.HeapAllocBytes
+	stats.PeakOpenFDs = stats.OpenFDs
+	if previous := m.runtime; previous != nil {
+		stats.PeakGoroutines = max(stats.PeakGoroutines, previous.PeakGoroutines)
+		stats.PeakHeapAllocBytes = max(stats.PeakHeapAllocBytes, previous.PeakHeapAllocBytes)
+		stats.PeakOpenFDs = max(stats.PeakOpenFDs, previous.PeakOpenFDs)
+	}
+	m.runtime = &stats
+}
+
+// runtimeStats copies the latest runtime sample; the caller holds the lock
+func (m *Metrics) runtimeStats() *RuntimeStats {
+	if m.runtime == nil {
+		return nil
 	}
+	stats := *m.runtime
+	return &stats
 }
 
 // Reset resets all metrics
@@ -168,3 +265,69 @@ func (m *Metrics) Reset() {
 	m.lastError = nil
 	m.uptime = time.Now()
 }
+
+// quotaStats returns a copy of the quota gauges; the caller holds the lock
+func (m *Metrics) quotaStats() map[string]QuotaStats {
+	if len(m.quota) == 0 {
+		return nil
+	}
+	stats := make(map[string]QuotaStats, len(m.quota))
+	for target, quota := range m.quota {
+		stats[target] = quota
+	}
+	return stats
+}
+
+// OpStarted counts runs in progress; Metrics is a syncengine.MetricsSink
+func (m *Metrics) OpStarted(op syncengine.Op) {
+	if op.Kind != syncengine.OpRun {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runsInProgress++
+}
+
+// OpFinished counts runs in progress; run results are recorded by RecordSync
+func (m *Metrics) OpFinished(op syncengine.Op, duration time.Duration, err error) {
+	if op.Kind != syncengine.OpRun {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runsInProgress--
+}
+
+// APICall counts an outbound API call
+func (m *Metrics) APICall(call syncengine.APICall) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.apiCalls == nil {
+		m.apiCalls = make(map[string]*APICallStats)
+	}
+	stats, ok := m.apiCalls[call.Host]
+	if !ok {
+		stats = &APICallStats{}
+		m.apiCalls[call.Host] = stats
+	}
+	stats.Calls++
+	stats.TotalDuration += call.Duration
+	if call.Err != nil || call.StatusCode >= 400 {
+		stats.Errors++
+	}
+}
+
+// apiCallStats returns a copy of the API call counts; the caller holds the lock
+func (m *Metrics) apiCallStats() map[string]APICallStats {
+	if len(m.apiCalls) == 0 {
+		return nil
+	}
+	stats := make(map[string]APICallStats, len(m.apiCalls))
+	for host, calls := range m.apiCalls {
+		copied := *calls
+		copied.AverageDuration = calls.TotalDuration / time.Duration(calls.Calls)
+		stats[host] = copied
+	}
+	return stats
+}
@@ -87,6 +87,72 @@ func TestRecordSync(t *testing.T) {
 	}
 }
 
+func TestRecordSyncRetryQueue(t *testing.T) {
+	metrics := NewMetrics()
+
+	metrics.RecordSync(&sync.SyncResult{QueueDepth: 3}, time.Second)
+	if depth := metrics.GetStats().RetryQueueDepth; depth != 3 {
+		t.Errorf("Expected retry queue depth 3, got %d", depth)
+	}
+
+	metrics.RecordSync(&sync.SyncResult{}, time.Second)
+	if depth := metrics.GetStats().RetryQueueDepth; depth != 0 {
+		t.Errorf("Expected the latest depth to replace the previous one, got %d", depth)
+	}
+}
+
+func TestRecordSyncQuota(t *testing.T) {
+	metrics := NewMetrics()
+	if stats := metrics.GetStats(); stats.Quota != nil {
+		t.Errorf("Expected no quota before a target reports one, got %+v", stats.Quota)
+	}
+
+	reset := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	metrics.RecordSync(&sync.SyncResult{QuotaUsage: []sync.QuotaUsage{
+		{Target: "default", Limit: 1000, Remaining: 600, Reset: reset, Used: 400, Fraction: 0.4},
+	}}, time.Second)
+	metrics.RecordSync(&sync.SyncResult{}, time.Second)
+
+	quota, ok := metrics.GetStats().Quota["default"]
+	if !ok {
+		t.Fatal("Expected the quota to be kept across runs without rate limit headers")
+	}
+	if quota.Limit != 1000 || quota.Remaining != 600 || quota.LastRunRequests != 400 || quota.LastRunFraction != 0.4 {
+		t.Errorf("Unexpected quota %+v", quota)
+	}
+	if quota.Reset == nil || !quota.Reset.Equal(reset) {
+		t.Errorf("Expected reset %v, got %v", reset, quota.Reset)
+	}
+}
+
+func TestMetricsSink(t *testing.T) {
+	metrics := NewMetrics()
+	run := sync.Op{Kind: sync.OpRun, Name: "full"}
+
+	metrics.OpStarted(run)
+	metrics.OpStarted(sync.Op{Kind: sync.OpGroup, Name: "eng@example.com"})
+	metrics.APICall(sync.APICall{Host: "api.byndid.com", StatusCode: 200, Duration: 100 * time.Millisecond})
+	metrics.APICall(sync.APICall{Host: "api.byndid.com", StatusCode: 429, Duration: 300 * time.Millisecond})
+	metrics.APICall(sync.APICall{Host: "admin.googleapis.com", Err: fmt.Errorf("timeout")})
+
+	stats := metrics.GetStats()
+	if stats.RunsInProgress != 1 {
+		t.Errorf("Expected 1 run in progress, got %d", stats.RunsInProgress)
+	}
+	bi := stats.APICalls["api.byndid.com"]
+	if bi.Calls != 2 || bi.Errors != 1 || bi.AverageDuration != 200*time.Millisecond {
+		t.Errorf("Unexpected Beyond Identity calls %+v", bi)
+	}
+	if google := stats.APICalls["admin.googleapis.com"]; google.Calls != 1 || google.Errors != 1 {
+		t.Errorf("Unexpected Google calls %+v", google)
+	}
+
+	metrics.OpFinished(run, time.Second, nil)
+	if stats := metrics.GetStats(); stats.RunsInProgress != 0 {
+		t.Errorf("Expected no runs in progress, got %d", stats.RunsInProgress)
+	}
+}
+
 func TestRecordSyncWithErrors(t *testing.T) {
 	metrics := NewMetrics()
 
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
+)
+
+// auditActionReadOnly is the audit action of a read-only mode change
+const auditActionReadOnly = "read_only_mode"
+
+// ReadOnlyRequest switches read-only mode on or off
+type ReadOnlyRequest struct {
+	Enabled     *bool  `json:"enabled"`
+	Reason      string `json:"reason,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"` // Free-form caller identifier recorded in the audit log
+}
+
+func (r *ReadOnlyRequest) validate() []FieldError {
+	if r.Enabled == nil {
+		return []FieldError{{Field: "enabled", Message: "is required"}}
+	}
+	return nil
+}
+
+// ModeResponse reports whether syncs may write
+type ModeResponse struct {
+	ReadOnly           bool     `json:"read_only"`
+	TestMode           bool     `json:"test_mode"`                      // Configured test mode also suppresses writes and cannot be changed at runtime
+	TestModeOperations []string `json:"test_mode_operations,omitempty"` // Operations configured to only be logged
+}
+
+// handleReadOnly switches read-only mode without a restart; a sync in progress is affected from its next operation
+func (s *Server) handleReadOnly(w http.ResponseWriter, r *http.Request) {
+	var req ReadOnlyRequest
+	if !s.decodeRequest(w, r, &req, false) {
+		return
+	}
+
+	previous := s.syncEngine.ReadOnly()
+	s.syncEngine.SetReadOnly(*req.Enabled)
+
+	if *req.Enabled != previous {
+		if *req.Enabled {
+			s.logger.Warnf("Read-only mode enabled via API (requested by %q): %s", req.RequestedBy, req.Reason)
+		} else {
+			s.logger.Warnf("Read-only mode disabled via API (requested by %q): %s", req.RequestedBy, req.Reason)
+		}
+
+		record := audit.Record{
+			Time:        time.Now().UTC(),
+			Action:      auditActionReadOnly,
+			Subject:     "server",
+			RequestedBy: req.RequestedBy,
+			RemoteAddr:  r.RemoteAddr,
+			Outcome:     "success",
+			Details:     map[string]interface{}{"read_only": *req.Enabled, "reason": req.Reason},
+		}
+		if err := s.audit.Write(record); err != nil {
+			s.logger.Errorf("Failed to write audit record for read-only mode change: %v", err)
+		}
+	}
+
+	response := ModeResponse{ReadOnly: s.syncEngine.ReadOnly(), TestMode: s.config.App.TestMode, TestModeOperations: s.config.Sync.TestModeOperations}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode mode response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
+	"github.com/gorilla/mux"
+)
+
+func TestHandleReadOnly(t *testing.T) {
+	server := createTestServer(t)
+	server.audit = audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	toggle := func(body string) (*httptest.ResponseRecorder, ModeResponse) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", "/mode/read-only", strings.NewReader(body)))
+		var response ModeResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	if rr, _ := toggle(`{"reason":"incident"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected enabled to be required, got %d", rr.Code)
+	}
+
+	rr, response := toggle(`{"enabled":true,"reason":"incident","requested_by":"oncall"}`)
+	if rr.Code != http.StatusOK || !response.ReadOnly {
+		t.Fatalf("Expected read-only mode to be enabled, got %d %+v", rr.Code, response)
+	}
+	if !server.syncEngine.ReadOnly() || !server.features()["read_only"] {
+		t.Error("Expected the engine and /info to report read-only mode")
+	}
+
+	// Repeating the current mode is not audited again
+	toggle(`{"enabled":true}`)
+	_, response = toggle(`{"enabled":false,"requested_by":"oncall"}`)
+	if response.ReadOnly {
+		t.Error("Expected read-only mode to be disabled")
+	}
+
+	raw, err := os.ReadFile(server.audit.Path())
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected two audit records, got %q", raw)
+	}
+	var record audit.Record
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Failed to parse audit record: %v", err)
+	}
+	if record.Action != auditActionReadOnly || record.RequestedBy != "oncall" {
+		t.Errorf("Unexpected audit record: %+v", record)
+	}
+}
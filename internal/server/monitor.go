@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// RuntimeStats is a sample of the server's own resource usage
+type RuntimeStats struct {
+	SampledAt          time.Time `json:"sampled_at"`
+	Goroutines         int       `json:"goroutines"`
+	HeapAllocBytes     uint64    `json:"heap_alloc_bytes"`
+	HeapSysBytes       uint64    `json:"heap_sys_bytes"`
+	OpenFDs            int       `json:"open_fds"` // -1 where the platform does not report open files
+	PeakGoroutines     int       `json:"peak_goroutines"`
+	PeakHeapAllocBytes uint64    `json:"peak_heap_alloc_bytes"`
+	PeakOpenFDs        int       `json:"peak_open_fds"`
+	Exceeded           []string  `json:"exceeded,omitempty"` // Thresholds exceeded by this sample
+}
+
+// idleCloser closes pooled connections that are not in use
+type idleCloser interface {
+	CloseIdleConnections()
+}
+
+// Monitor samples goroutines, heap and open files on an interval, records them in the metrics
+// and warns with a goroutine dump when a threshold is first exceeded, so leaks in long-running
+// deployments are visible before they exhaust the host
+type Monitor struct {
+	cfg     config.MonitorConfig
+	idle    idleCloser
+	metrics *Metrics
+	logger  *logrus.Logger
+	sample  func() RuntimeStats
+
+	exceeded map[string]bool // Thresholds exceeded by the previous sample
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Monitored thresholds
+const (
+	thresholdGoroutines = "goroutines"
+	thresholdHeap       = "heap"
+	thresholdOpenFDs    = "open_fds"
+)
+
+// NewMonitor creates a monitor; idle connections of idle are closed while open files exceed the threshold
+func NewMonitor(cfg config.MonitorConfig, idle idleCloser, metrics *Metrics, logger *logrus.Logger) *Monitor {
+	return &Monitor{
+		cfg:      cfg,
+		idle:     idle,
+		metrics:  metrics,
+		logger:   logger,
+		sample:   sampleRuntime,
+		exceeded: make(map[string]bool),
+	}
+}
+
+// Start samples immediately and then every interval until Stop
+func (m *Monitor) Start() {
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(time.Duration(m.cfg.IntervalSeconds) * time.Second)
+		defer ticker.Stop()
+
+		m.check()
+		for {
+			select {
+			case <-ticker.C:
+				m.check()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops sampling and waits for an in-progress sample to finish
+func (m *Monitor) Stop() {
+	if m.stop == nil {
+		return
+	}
+	close(m.stop)
+	<-m.done
+	m.stop = nil
+}
+
+// check takes one sample, records it and reacts to exceeded thresholds
+func (m *Monitor) check() {
+	stats := m.sample()
+
+	current := make(map[string]bool)
+	var newlyExceeded []string
+	over := func(name string, value, limit int64, unit string) {
+		if limit <= 0 || value <= limit {
+			return
+		}
+		breach := fmt.Sprintf("%s %d%s > %d%s", name, value, unit, limit, unit)
+		stats.Exceeded = append(stats.Exceeded, breach)
+		current[name] = true
+		if !m.exceeded[name] {
+			newlyExceeded = append(newlyExceeded, breach)
+		}
+	}
+	over(thresholdGoroutines, int64(stats.Goroutines), int64(m.cfg.MaxGoroutines), "")
+	over(thresholdHeap, int64(stats.HeapAllocBytes>>20), int64(m.cfg.MaxHeapMB), "MB")
+	over(thresholdOpenFDs, int64(stats.OpenFDs), int64(m.cfg.MaxOpenFDs), "")
+
+	m.metrics.RecordRuntime(stats)
+
+	for name := range m.exceeded {
+		if !current[name] {
+			m.logger.Infof("Runtime monitor: %s back below threshold", name)
+		}
+	}
+	m.exceeded = current
+
+	// Pooled API connections are the most likely file descriptors to reclaim
+	if current[thresholdOpenFDs] && m.idle != nil {
+		m.idle.CloseIdleConnections()
+	}
+
+	// Dump once per breach rather than every interval
+	if len(newlyExceeded) > 0 {
+		m.warn(newlyExceeded)
+	}
+}
+
+// warn logs the exceeded thresholds with a goroutine dump, written to dump_dir when configured
+func (m *Monitor) warn(exceeded []string) {
+	summary := strings.Join(exceeded, ", ")
+
+	var dump bytes.Buffer
+	if profile := pprof.Lookup("goroutine"); profile != nil {
+		_ = profile.WriteTo(&dump, 1)
+	}
+
+	if m.cfg.DumpDir != "" {
+		path := filepath.Join(m.cfg.DumpDir, fmt.Sprintf("goroutines-%s.txt", time.Now().UTC().Format("20060102T150405Z")))
+		err := os.WriteFile(path, dump.Bytes(), 0600)
+		if err == nil {
+			m.logger.Warnf("Runtime monitor: possible leak (%s); goroutine dump written to %s", summary, path)
+			return
+		}
+		m.logger.Errorf("Runtime monitor: failed to write goroutine dump: %v", err)
+	}
+	m.logger.Warnf("Runtime monitor: possible leak (%s); goroutine dump:\n%s", summary, dump.String())
+}
+
+// sampleRuntime reads the current goroutine count, heap usage and open files
+func sampleRuntime() RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return RuntimeStats{
+		SampledAt:      time.Now(),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapSysBytes:   mem.HeapSys,
+		OpenFDs:        countOpenFDs(),
+	}
+}
+
+// countOpenFDs counts the process's open file descriptors where /proc is available
+func countOpenFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+type mockIdleCloser struct {
+	calls int
+}
+
+func (m *mockIdleCloser) CloseIdleConnections() {
+	m.calls++
+}
+
+func newTestMonitor(cfg config.MonitorConfig, samples ...RuntimeStats) (*Monitor, *mockIdleCloser, *bytes.Buffer) {
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&logs)
+
+	idle := &mockIdleCloser{}
+	monitor := NewMonitor(cfg, idle, NewMetrics(), logger)
+	monitor.sample = func() RuntimeStats {
+		sample := samples[0]
+		if len(samples) > 1 {
+			samples = samples[1:]
+		}
+		return sample
+	}
+	return monitor, idle, &logs
+}
+
+func TestMonitor_Check(t *testing.T) {
+	cfg := config.MonitorConfig{IntervalSeconds: 60, MaxGoroutines: 100, MaxHeapMB: 64, MaxOpenFDs: -1}
+	monitor, idle, logs := newTestMonitor(cfg,
+		RuntimeStats{Goroutines: 50, HeapAllocBytes: 10 << 20, OpenFDs: 5000},
+		RuntimeStats{Goroutines: 150, HeapAllocBytes: 10 << 20, OpenFDs: 5000},
+		RuntimeStats{Goroutines: 160, HeapAllocBytes: 10 << 20, OpenFDs: 5000},
+		RuntimeStats{Goroutines: 40, HeapAllocBytes: 80 << 20, OpenFDs: 5000},
+	)
+
+	// Within thresholds; the disabled open file check is ignored
+	monitor.check()
+	if logs.Len() != 0 || idle.calls != 0 {
+		t.Fatalf("Expected no warning, got %q", logs.String())
+	}
+
+	// A new breach warns with a goroutine dump
+	monitor.check()
+	if !strings.Contains(logs.String(), "goroutines 150 > 100") || !strings.Contains(logs.String(), "goroutine profile") {
+		t.Errorf("Expected a warning with a goroutine dump, got %q", logs.String())
+	}
+	stats := monitor.metrics.GetStats().Runtime
+	if stats == nil || stats.Goroutines != 150 || len(stats.Exceeded) != 1 {
+		t.Errorf("Expected the sample in the metrics, got %+v", stats)
+	}
+
+	// A continuing breach is not dumped again
+	logs.Reset()
+	monitor.check()
+	if logs.Len() != 0 {
+		t.Errorf("Expected one warning per breach, got %q", logs.String())
+	}
+
+	// Recovery is logged and a different breach warns
+	monitor.check()
+	if !strings.Contains(logs.String(), "goroutines back below threshold") || !strings.Contains(logs.String(), "heap 80MB > 64MB") {
+		t.Errorf("Expected recovery and heap warning, got %q", logs.String())
+	}
+	stats = monitor.metrics.GetStats().Runtime
+	if stats.Goroutines != 40 || stats.PeakGoroutines != 160 || stats.PeakHeapAllocBytes != 80<<20 {
+		t.Errorf("Expected current values with peaks, got %+v", stats)
+	}
+}
+
+func TestMonitor_OpenFDs(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.MonitorConfig{IntervalSeconds: 60, MaxGoroutines: -1, MaxHeapMB: -1, MaxOpenFDs: 100, DumpDir: dir}
+	monitor, idle, logs := newTestMonitor(cfg, RuntimeStats{OpenFDs: 250})
+
+	monitor.check()
+	monitor.check()
+	if idle.calls != 2 {
+		t.Errorf("Expected idle connections to be closed on every sample over the threshold, got %d", idle.calls)
+	}
+
+	dumps, _ := os.ReadDir(dir)
+	if len(dumps) != 1 || !strings.Contains(logs.String(), "goroutine dump written to") {
+		t.Errorf("Expected one dump file, got %d files and %q", len(dumps), logs.String())
+	}
+}
+
+func TestMonitor_StartStop(t *testing.T) {
+	monitor, _, _ := newTestMonitor(config.MonitorConfig{IntervalSeconds: 60}, RuntimeStats{Goroutines: 1, SampledAt: time.Now()})
+
+	monitor.Start()
+	monitor.Stop()
+	monitor.Stop()
+
+	if monitor.metrics.GetStats().Runtime == nil {
+		t.Error("Expected a sample to be taken on start")
+	}
+}
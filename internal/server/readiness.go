@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Readiness statuses of GET /readyz
+const (
+	readinessReady   = "ready"
+	readinessWaiting = "waiting_for_initial_sync"
+)
+
+// ReadinessResponse represents the readiness check response
+type ReadinessResponse struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ready reports whether the service should receive traffic: always, unless
+// server.wait_for_initial_sync holds it back until a sync completes without errors
+func (s *Server) ready() bool {
+	return !s.config.Server.WaitForInitialSync || s.metrics.SuccessfulSyncs() > 0
+}
+
+// handleReady handles readiness check requests, answering 503 Service Unavailable until ready
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	response := ReadinessResponse{Status: readinessReady, Timestamp: time.Now()}
+	status := http.StatusOK
+	if !s.ready() {
+		response.Status = readinessWaiting
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode readiness response", "error", err)
+	}
+}
+
+// runInitialSync performs the sync /readyz waits for with server.wait_for_initial_sync. When it
+// fails, the service becomes ready after the first later sync that succeeds
+func (s *Server) runInitialSync(ctx context.Context) {
+	s.logger.Info("Running initial sync before reporting ready")
+
+	startTime := time.Now()
+	result, err := s.syncEngine.Sync(ctx)
+	duration := time.Since(startTime)
+
+	switch {
+	case err != nil:
+		s.logger.Errorf("Initial sync failed: %v; not ready until a sync succeeds", err)
+		s.metrics.RecordFailedSync(err, duration)
+	case len(result.Errors) > 0:
+		s.logger.Warnf("Initial sync completed with %d errors; not ready until a sync succeeds", len(result.Errors))
+		s.metrics.RecordSync(result, duration)
+	default:
+		s.logger.Infof("Initial sync completed successfully in %v; ready", duration)
+		s.metrics.RecordSync(result, duration)
+	}
+}
+
+// startScheduler starts the scheduler, if enabled
+func (s *Server) startScheduler() error {
+	if s.scheduler == nil {
+		return nil
+	}
+	if err := s.scheduler.Start(); err != nil {
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
+	s.logger.Info("Scheduler started successfully")
+	return nil
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandleReady(t *testing.T) {
+	server := createTestServer(t)
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	check := func() (int, ReadinessResponse) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/readyz", nil))
+		var response ReadinessResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr.Code, response
+	}
+
+	if code, response := check(); code != http.StatusOK || response.Status != readinessReady {
+		t.Errorf("Expected the server to be ready without wait_for_initial_sync, got %d %+v", code, response)
+	}
+
+	server.config.Server.WaitForInitialSync = true
+	if code, response := check(); code != http.StatusServiceUnavailable || response.Status != readinessWaiting {
+		t.Errorf("Expected the server to wait for the initial sync, got %d %+v", code, response)
+	}
+
+	// A failed initial sync keeps the server unready until a sync succeeds
+	engine := server.syncEngine.(*mockSyncEngine)
+	engine.shouldError = true
+	server.runInitialSync(context.Background())
+	if code, _ := check(); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected a failed initial sync to keep the server unready, got %d", code)
+	}
+
+	engine.shouldError = false
+	server.runInitialSync(context.Background())
+	if code, response := check(); code != http.StatusOK || response.Status != readinessReady {
+		t.Errorf("Expected the server to be ready after the initial sync, got %d %+v", code, response)
+	}
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// PendingEnrollmentResponse represents the pending enrollment report
+type PendingEnrollmentResponse struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Days        int                  `json:"days"`
+	Count       int                  `json:"count"`
+	Users       []report.PendingUser `json:"users"`
+}
+
+// handlePendingEnrollmentReport lists users provisioned more than ?days=N days ago who
+// have no active passkey, as JSON or as CSV with ?format=csv
+func (s *Server) handlePendingEnrollmentReport(w http.ResponseWriter, r *http.Request) {
+	if s.users == nil {
+		http.Error(w, "Enrollment status needs a Beyond Identity primary target", http.StatusNotImplemented)
+		return
+	}
+
+	days := report.DefaultPendingDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			http.Error(w, "days must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	now := time.Now()
+	users, err := report.PendingEnrollment(r.Context(), s.users, days, now)
+	if err != nil {
+		s.logger.Errorf("Failed to build pending enrollment report: %v", err)
+		http.Error(w, "Failed to build report", http.StatusBadGateway)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="pending-enrollment.csv"`)
+		if err := report.WritePendingEnrollmentCSV(w, users); err != nil {
+			s.logger.Errorf("Failed to write pending enrollment report: %v", err)
+		}
+		return
+	}
+
+	if users == nil {
+		users = []report.PendingUser{}
+	}
+	response := PendingEnrollmentResponse{
+		GeneratedAt: now,
+		Days:        days,
+		Count:       len(users),
+		Users:       users,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode pending enrollment response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// ChangesResponse represents the user and membership changes made in a time window
+type ChangesResponse struct {
+	Since   time.Time      `json:"since"`
+	Until   *time.Time     `json:"until,omitempty"`
+	Count   int            `json:"count"`
+	Changes []state.Change `json:"changes"`
+}
+
+// handleChanges lists users and memberships added or removed between ?since= and the
+// optional ?until=, as JSON or as CSV with ?format=csv
+func (s *Server) handleChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("since") == "" {
+		http.Error(w, "since is required", http.StatusBadRequest)
+		return
+	}
+	since, err := report.ParseTime(query.Get("since"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var until time.Time
+	if value := query.Get("until"); value != "" {
+		if until, err = report.ParseTime(value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	changes := s.syncEngine.Changes(since, until)
+
+	if query.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="changes.csv"`)
+		if err := report.WriteChangesCSV(w, changes); err != nil {
+			s.logger.Errorf("Failed to write changes report: %v", err)
+		}
+		return
+	}
+
+	if changes == nil {
+		changes = []state.Change{}
+	}
+	response := ChangesResponse{
+		Since:   since,
+		Count:   len(changes),
+		Changes: changes,
+	}
+	if !until.IsZero() {
+		response.Until = &until
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode changes response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleAccessReviewReport exports who is in which provisioned group and the source group it
+// mirrors, as a SCIM ListResponse or as CSV with ?format=csv
+func (s *Server) handleAccessReviewReport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = config.AccessReviewFormatSCIM
+	}
+	if format != config.AccessReviewFormatSCIM && format != config.AccessReviewFormatCSV {
+		http.Error(w, "format must be scim or csv", http.StatusBadRequest)
+		return
+	}
+
+	groups, err := s.syncEngine.AccessReview(r.Context())
+	if err != nil {
+		s.logger.Errorf("Failed to build access review: %v", err)
+		http.Error(w, "Failed to build report", http.StatusBadGateway)
+		return
+	}
+
+	if format == config.AccessReviewFormatCSV {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="access-review.csv"`)
+	} else {
+		w.Header().Set("Content-Type", "application/scim+json")
+	}
+	if err := report.WriteAccessReview(w, groups, format); err != nil {
+		s.logger.Errorf("Failed to write access review: %v", err)
+	}
+}
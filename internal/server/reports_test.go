@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	"github.com/gorilla/mux"
+)
+
+type mockUserLister struct {
+	users []bi.NativeUser
+	err   error
+}
+
+func (m *mockUserLister) ListNativeUsers(ctx context.Context) ([]bi.NativeUser, error) {
+	return m.users, m.err
+}
+
+func TestHandlePendingEnrollmentReport(t *testing.T) {
+	server := createTestServer(t)
+	server.users = &mockUserLister{users: []bi.NativeUser{
+		{EmailAddress: "old@example.com", State: "ACTIVE", CreateTime: time.Now().AddDate(0, 0, -20)},
+		{EmailAddress: "new@example.com", State: "ACTIVE", CreateTime: time.Now().AddDate(0, 0, -2)},
+	}}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCount  int
+	}{
+		{"default days", "", http.StatusOK, 1},
+		{"custom days", "?days=1", http.StatusOK, 2},
+		{"invalid days", "?days=abc", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/report/pending-enrollment"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response PendingEnrollmentResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Count != tt.wantCount || len(response.Users) != tt.wantCount {
+				t.Errorf("Expected %d users, got %d", tt.wantCount, response.Count)
+			}
+		})
+	}
+}
+
+func TestHandlePendingEnrollmentReport_CSV(t *testing.T) {
+	server := createTestServer(t)
+	server.users = &mockUserLister{users: []bi.NativeUser{
+		{EmailAddress: "old@example.com", State: "ACTIVE", CreateTime: time.Now().AddDate(0, 0, -20)},
+	}}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/report/pending-enrollment?format=csv", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("Expected text/csv, got %s", ct)
+	}
+	if !strings.Contains(rr.Body.String(), "old@example.com") {
+		t.Errorf("Expected pending user in CSV, got %q", rr.Body.String())
+	}
+}
+
+func TestHandlePendingEnrollmentReport_Error(t *testing.T) {
+	server := createTestServer(t)
+	server.users = &mockUserLister{err: errors.New("HTTP 401")}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/report/pending-enrollment", nil))
+
+	if rr.Code != http.StatusBadGateway {
+		t.Errorf("Expected status code 502, got %d", rr.Code)
+	}
+}
+
+func TestHandleChanges(t *testing.T) {
+	server := createTestServer(t)
+	server.syncEngine = &mockSyncEngine{changes: []state.Change{
+		{Time: time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC), Action: state.ChangeUserCreated, User: "old@example.com"},
+		{Time: time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC), Action: state.ChangeMembershipAdded, User: "alice@example.com", Group: "eng@example.com"},
+		{Time: time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC), Action: state.ChangeMembershipRemoved, User: "bob@example.com", Group: "eng@example.com"},
+	}}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCount  int
+	}{
+		{"since date", "?since=2024-06-01", http.StatusOK, 2},
+		{"window", "?since=2024-06-01&until=2024-06-05", http.StatusOK, 1},
+		{"missing since", "", http.StatusBadRequest, 0},
+		{"invalid since", "?since=yesterday", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest("GET", "/changes"+tt.query, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response ChangesResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Count != tt.wantCount || len(response.Changes) != tt.wantCount {
+				t.Errorf("Expected %d changes, got %d", tt.wantCount, response.Count)
+			}
+		})
+	}
+}
+
+func TestHandleAccessReviewReport(t *testing.T) {
+	server := createTestServer(t)
+	server.syncEngine = &mockSyncEngine{review: []report.AccessReviewGroup{{
+		SourceGroup: "eng@example.com",
+		Target:      "default",
+		GroupID:     "group-1",
+		GroupName:   "GWS_Engineering",
+		Members:     []report.AccessReviewMember{{UserID: "user-1", Email: "alice@example.com"}},
+	}}}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	tests := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantContent string
+	}{
+		{"scim by default", "", http.StatusOK, `"externalId": "eng@example.com"`},
+		{"csv", "?format=csv", http.StatusOK, "alice@example.com,user-1,GWS_Engineering,group-1,default,eng@example.com"},
+		{"invalid format", "?format=xml", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest("GET", "/report/access-review"+tt.query, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantContent) {
+				t.Errorf("Expected body to contain %q, got %s", tt.wantContent, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxRequestBodyBytes bounds the JSON bodies of API requests
+const maxRequestBodyBytes = 1 << 20
+
+// FieldError names a request body field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is returned with 422 Unprocessable Entity for request bodies that are
+// well-formed JSON but have unknown fields, values of the wrong type or invalid values
+type ValidationErrorResponse struct {
+	Status  string       `json:"status"` // Always "invalid_request"
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
+
+// requestBody is implemented by API request payloads
+type requestBody interface {
+	validate() []FieldError // The fields with invalid values; none when the request is valid
+}
+
+// errMalformedBody is returned for request bodies that are not a single JSON object
+var errMalformedBody = errors.New("request body is not a JSON object")
+
+// decodeStrict decodes a JSON object into req, rejecting unknown fields, and validates it.
+// It returns errMalformedBody for a body that is not JSON, and the offending fields for one that
+// does not match req. An empty body decodes as {} when optional is set
+func decodeStrict(data []byte, req requestBody, optional bool) ([]FieldError, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		if !optional {
+			return nil, fmt.Errorf("%w: the body is empty", errMalformedBody)
+		}
+		return req.validate(), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			return []FieldError{{Field: typeErr.Field, Message: fmt.Sprintf("must be a JSON %s", jsonType(typeErr.Type.Kind().String()))}}, nil
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+			return []FieldError{{Field: field, Message: "unknown field"}}, nil
+		default:
+			return nil, fmt.Errorf("%w: %v", errMalformedBody, err)
+		}
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: unexpected data after the object", errMalformedBody)
+	}
+	return req.validate(), nil
+}
+
+// jsonType names the JSON type a Go kind decodes from
+func jsonType(kind string) string {
+	switch kind {
+	case "bool":
+		return "boolean"
+	case "string":
+		return "string"
+	case "slice", "array":
+		return "array"
+	case "map", "struct":
+		return "object"
+	default:
+		return "number"
+	}
+}
+
+// decodeRequest reads and strictly decodes the JSON body of r into req, answering 400 Bad
+// Request for a malformed body and 422 Unprocessable Entity listing the offending fields for an
+// invalid one; it reports whether the handler should go on
+func (s *Server) decodeRequest(w http.ResponseWriter, r *http.Request, req requestBody, optional bool) bool {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return false
+	}
+	return s.checkRequest(w, data, req, optional)
+}
+
+// checkRequest strictly decodes a request body already read, as decodeRequest
+func (s *Server) checkRequest(w http.ResponseWriter, data []byte, req requestBody, optional bool) bool {
+	fields, err := decodeStrict(data, req, optional)
+	if err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return false
+	}
+	if len(fields) > 0 {
+		s.writeValidationErrors(w, fields)
+		return false
+	}
+	return true
+}
+
+// writeValidationErrors answers 422 Unprocessable Entity listing the offending fields
+func (s *Server) writeValidationErrors(w http.ResponseWriter, fields []FieldError) {
+	response := ValidationErrorResponse{
+		Status:  "invalid_request",
+		Message: "Request body failed validation",
+		Errors:  fields,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode validation error response", "error", err)
+	}
+}
+
+// requireEmail returns the error of a required email field that is missing or malformed
+func requireEmail(field, email string) []FieldError {
+	if !strings.Contains(strings.TrimSpace(email), "@") {
+		return []FieldError{{Field: field, Message: "a valid email address is required"}}
+	}
+	return nil
+}
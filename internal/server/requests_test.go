@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+func TestDecodeStrict(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		optional   bool
+		wantFields []FieldError
+		wantErr    bool
+	}{
+		{"valid", `{"full":true,"labels":{"ticket":"OPS-1"}}`, false, nil, false},
+		{"empty optional body", ``, true, nil, false},
+		{"empty required body", ``, false, nil, true},
+		{"malformed", `{"full":`, false, nil, true},
+		{"trailing data", `{} {}`, false, nil, true},
+		{"unknown field", `{"fulll":true}`, false, []FieldError{{Field: "fulll", Message: "unknown field"}}, false},
+		{"wrong type", `{"dry_run":"yes"}`, false, []FieldError{{Field: "dry_run", Message: "must be a JSON boolean"}}, false},
+		{"invalid values", `{"full":true,"groups":["eng"]}`, false, []FieldError{
+			{Field: "full", Message: "cannot be combined with groups"},
+			{Field: "groups[0]", Message: "must be a group email address"},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req SyncRequest
+			fields, err := decodeStrict([]byte(tt.body), &req, tt.optional)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error: %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("Expected fields %+v, got %+v", tt.wantFields, fields)
+			}
+		})
+	}
+}
+
+func TestHandleSync_Request(t *testing.T) {
+	server := createTestServer(t)
+	server.config.Sync.Groups = []string{"eng@example.com"}
+	engine := &mockSyncEngine{result: &sync.SyncResult{GroupsProcessed: 1, DryRun: true}}
+	server.syncEngine = engine
+
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+	post := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", "/sync", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := post(`{"groups":["ENG@example.com"],"dry_run":true,"labels":{"ticket":"OPS-1"}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(engine.groupSyncs) != 1 || engine.groupSyncs[0][0] != "ENG@example.com" {
+		t.Errorf("Expected the requested group to be synced, got %v", engine.groupSyncs)
+	}
+	var response SyncResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Result == nil || !response.Result.DryRun {
+		t.Errorf("Expected the dry run to be reported, got %+v", response.Result)
+	}
+
+	rr = post(`{"groups":["other@example.com"]}`)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422 for an unconfigured group, got %d", rr.Code)
+	}
+	var invalid ValidationErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &invalid); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(invalid.Errors) != 1 || invalid.Errors[0].Field != "groups[0]" {
+		t.Errorf("Expected groups[0] to be reported, got %+v", invalid.Errors)
+	}
+
+	if rr := post(`{"group":"eng@example.com"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 for an unknown field, got %d", rr.Code)
+	}
+	if rr := post(`not json`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a malformed body, got %d", rr.Code)
+	}
+}
+
+func TestHandleSyncGroup(t *testing.T) {
+	server := createTestServer(t)
+	server.config.Sync.Groups = []string{"eng@example.com", "sales@example.com"}
+	engine := &mockSyncEngine{result: &sync.SyncResult{GroupsProcessed: 1}}
+	server.syncEngine = engine
+
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		return rr
+	}
+
+	rr := post("/sync/group/Eng@example.com", ``)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(engine.groupSyncs) != 1 || !reflect.DeepEqual(engine.groupSyncs[0], []string{"Eng@example.com"}) {
+		t.Errorf("Expected only the group in the path to be synced, got %v", engine.groupSyncs)
+	}
+	var response SyncResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Status != "success" || response.Result == nil || response.Result.GroupsProcessed != 1 {
+		t.Errorf("Expected the group's own result, got %+v", response)
+	}
+
+	if rr := post("/sync/group/other@example.com", ``); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unconfigured group, got %d", rr.Code)
+	}
+	if rr := post("/sync/group/eng@example.com", `{"groups":["sales@example.com"]}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 for groups in the body, got %d", rr.Code)
+	}
+	if len(engine.groupSyncs) != 1 {
+		t.Errorf("Expected rejected requests not to sync, got %v", engine.groupSyncs)
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gorilla/mux"
+)
+
+// Run control actions of POST /sync/{id}/{action}, also recorded as audit actions prefixed with run_
+const (
+	runActionCancel = "cancel"
+	runActionStop   = "stop"
+	runActionPause  = "pause"
+	runActionResume = "resume"
+)
+
+// RunControlRequest cancels, stops, pauses or resumes the run in progress; the body is optional
+type RunControlRequest struct {
+	Reason      string `json:"reason,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"` // Free-form caller identifier recorded in the audit log
+}
+
+func (r *RunControlRequest) validate() []FieldError {
+	return nil
+}
+
+// ActiveRunResponse describes the run in progress, as returned by GET /sync/current and the run
+// control endpoints
+type ActiveRunResponse struct {
+	Running   bool      `json:"running"`
+	ID        string    `json:"id,omitempty"`
+	Kind      string    `json:"kind,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	Paused    bool      `json:"paused,omitempty"`
+	Stopping  bool      `json:"stopping,omitempty"`
+}
+
+// handleCurrentRun reports the run in progress, if any, so its ID can be passed to the control endpoints
+func (s *Server) handleCurrentRun(w http.ResponseWriter, r *http.Request) {
+	s.writeActiveRun(w)
+}
+
+// handleRunControl cancels the run with the given ID at once, stops it after its current group, or
+// pauses or resumes it between groups
+func (s *Server) handleRunControl(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, action := vars["id"], vars["action"]
+
+	var req RunControlRequest
+	if !s.decodeRequest(w, r, &req, true) {
+		return
+	}
+
+	var err error
+	switch action {
+	case runActionCancel:
+		err = s.syncEngine.CancelRun(id)
+	case runActionStop:
+		err = s.syncEngine.StopRun(id)
+	case runActionPause:
+		err = s.syncEngine.PauseRun(id)
+	case runActionResume:
+		err = s.syncEngine.ResumeRun(id)
+	}
+	s.auditRunControl(r, id, action, req, err)
+	if errors.Is(err, syncengine.ErrRunNotFound) {
+		http.Error(w, "Run not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Warnf("Run %s: %s requested via API (requested by %q): %s", id, action, req.RequestedBy, req.Reason)
+	s.writeActiveRun(w)
+}
+
+// auditRunControl records a request to control a run
+func (s *Server) auditRunControl(r *http.Request, id, action string, req RunControlRequest, err error) {
+	record := audit.Record{
+		Time:        time.Now().UTC(),
+		Action:      "run_" + action,
+		Subject:     id,
+		RequestedBy: req.RequestedBy,
+		RemoteAddr:  r.RemoteAddr,
+		Outcome:     "success",
+		Details:     map[string]interface{}{"reason": req.Reason},
+	}
+	if err != nil {
+		record.Outcome = "failed"
+		record.Error = err.Error()
+	}
+	if err := s.audit.Write(record); err != nil {
+		s.logger.Errorf("Failed to write audit record for run %s: %v", action, err)
+	}
+}
+
+func (s *Server) writeActiveRun(w http.ResponseWriter) {
+	var response ActiveRunResponse
+	if run, ok := s.syncEngine.RunInProgress(); ok {
+		response = ActiveRunResponse{
+			Running:   true,
+			ID:        run.ID,
+			Kind:      run.Kind,
+			Subject:   run.Subject,
+			StartedAt: run.StartedAt,
+			Paused:    run.Paused,
+			Stopping:  run.Stopping,
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode run response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gorilla/mux"
+)
+
+func TestHandleRunControl(t *testing.T) {
+	server := createTestServer(t)
+	server.audit = audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	engine := &mockSyncEngine{}
+	server.syncEngine = engine
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	request := func(method, path, body string) (*httptest.ResponseRecorder, ActiveRunResponse) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var response ActiveRunResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	rr, response := request("GET", "/sync/current", "")
+	if rr.Code != http.StatusOK || response.Running {
+		t.Fatalf("Expected no run in progress, got %d %+v", rr.Code, response)
+	}
+
+	engine.active = &sync.ActiveRun{ID: "run-1", Kind: sync.RunKindFull}
+	rr, response = request("POST", "/sync/run-1/pause", `{"reason":"checking a change","requested_by":"oncall"}`)
+	if rr.Code != http.StatusOK || !response.Running || !response.Paused {
+		t.Fatalf("Expected the run to be paused, got %d %+v", rr.Code, response)
+	}
+	rr, response = request("POST", "/sync/run-1/resume", "")
+	if rr.Code != http.StatusOK || response.Paused {
+		t.Fatalf("Expected the run to be resumed, got %d %+v", rr.Code, response)
+	}
+	rr, response = request("POST", "/sync/run-1/stop", "")
+	if rr.Code != http.StatusOK || !response.Stopping {
+		t.Fatalf("Expected the run to be stopping, got %d %+v", rr.Code, response)
+	}
+
+	// Another run's ID is not found
+	rr, _ = request("POST", "/sync/run-2/cancel", "")
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code 404, got %d", rr.Code)
+	}
+	rr, response = request("POST", "/sync/run-1/cancel", "")
+	if rr.Code != http.StatusOK || response.Running {
+		t.Fatalf("Expected the run to be cancelled, got %d %+v", rr.Code, response)
+	}
+
+	rr, _ = request("POST", "/sync/run-1/restart", "")
+	if rr.Code != http.StatusNotFound && rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected an unknown action to be rejected, got %d", rr.Code)
+	}
+
+	raw, err := os.ReadFile(server.audit.Path())
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[0], `"run_pause"`) || !strings.Contains(lines[3], `"failed"`) {
+		t.Errorf("Expected each request to be audited, got %q", raw)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gorilla/mux"
+)
+
+// RunResponse is a recent run as returned by GET /runs and GET /runs/{id}
+type RunResponse struct {
+	ID         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Subject    string            `json:"subject,omitempty"`
+	Status     string            `json:"status"` // success or error
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Duration   time.Duration     `json:"duration"`
+	DryRun     bool              `json:"dry_run,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Result     *SyncStats        `json:"result,omitempty"` // Change records are only included by GET /runs/{id}
+}
+
+// RunsResponse lists the recent runs kept by the engine, newest first
+type RunsResponse struct {
+	Count int           `json:"count"`
+	Runs  []RunResponse `json:"runs"`
+}
+
+// newRunResponse converts a recent run for the API, with its change records when withChanges is set
+func newRunResponse(run syncengine.RecentRun, withChanges bool) RunResponse {
+	response := RunResponse{
+		ID:         run.ID,
+		Kind:       run.Kind,
+		Subject:    run.Subject,
+		Status:     "success",
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Duration:   run.Duration(),
+		DryRun:     run.DryRun,
+		Labels:     run.Labels,
+		Error:      run.Error,
+	}
+	if run.Error != "" {
+		response.Status = "error"
+	}
+	if run.Result != nil {
+		response.Result = newSyncStats(run.Result, run.Duration())
+		if !withChanges {
+			response.Result.Changes = nil
+		}
+	}
+	return response
+}
+
+// handleRuns lists the runs of this process kept under sync.recent_runs, without their change records
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	runs := s.syncEngine.RecentRuns()
+	response := RunsResponse{Count: len(runs), Runs: make([]RunResponse, 0, len(runs))}
+	for _, run := range runs {
+		response.Runs = append(response.Runs, newRunResponse(run, false))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode runs response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleRun returns one recent run with its change records
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.syncEngine.RecentRun(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "Run not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newRunResponse(run, true)); err != nil {
+		s.logger.Error("Failed to encode run response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
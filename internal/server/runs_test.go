@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gorilla/mux"
+)
+
+func TestHandleRuns(t *testing.T) {
+	started := time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC)
+	server := createTestServer(t)
+	server.syncEngine = &mockSyncEngine{recent: []sync.RecentRun{
+		{
+			ID:         "run-2",
+			Kind:       sync.RunKindFull,
+			StartedAt:  started.Add(time.Hour),
+			FinishedAt: started.Add(time.Hour + time.Second),
+			Error:      "sync aborted: too many errors",
+		},
+		{
+			ID:         "run-1",
+			Kind:       sync.RunKindGroups,
+			Subject:    "eng@example.com",
+			StartedAt:  started,
+			FinishedAt: started.Add(2 * time.Second),
+			Result: &sync.SyncResult{
+				GroupsProcessed:  1,
+				MembershipsAdded: 1,
+				Changes:          []report.ChangeRecord{{Action: state.ChangeMembershipAdded, User: "alice@example.com", Group: "eng@example.com"}},
+			},
+		},
+	}}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/runs", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", rr.Code)
+	}
+	var list RunsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if list.Count != 2 || list.Runs[0].ID != "run-2" || list.Runs[0].Status != "error" || list.Runs[1].Status != "success" {
+		t.Errorf("Expected both runs newest first, got %+v", list.Runs)
+	}
+	if list.Runs[1].Duration != 2*time.Second || list.Runs[1].Result == nil || list.Runs[1].Result.GroupsProcessed != 1 {
+		t.Errorf("Expected the counts and duration of run-1, got %+v", list.Runs[1])
+	}
+	if len(list.Runs[1].Result.Changes) != 0 {
+		t.Errorf("Expected the list to leave out change records, got %+v", list.Runs[1].Result.Changes)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/runs/run-1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", rr.Code)
+	}
+	var run RunResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &run); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if run.Subject != "eng@example.com" || run.Result == nil || len(run.Result.Changes) != 1 {
+		t.Errorf("Expected run-1 with its change records, got %+v", run)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/runs/unknown", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code 404, got %d", rr.Code)
+	}
+}
@@ -1,10 +1,15 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/notify"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/remind"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
 	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
 	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
@@ -17,6 +22,17 @@ type Scheduler struct {
 	syncEngine *syncengine.Engine
 	logger     *logrus.Logger
 	metrics    *Metrics
+	notifier   *notify.Dispatcher
+	digest     *notify.Digest // Set in digest mode; failures are summarized instead of alerted per run
+	digestCron string
+	incidents  *notify.IncidentManager
+	diffs      config.MembershipDiffsConfig
+	reminder   *remind.Reminder
+	remindCron string
+	review     *config.AccessReviewConfig
+	syncEntry  cron.EntryID
+	ctx        context.Context // Cancelled by Stop to abort runs in progress
+	cancel     context.CancelFunc
 	mu         sync.RWMutex
 	running    bool
 	lastSync   *time.Time
@@ -24,7 +40,7 @@ type Scheduler struct {
 }
 
 // NewScheduler creates a new scheduler
-func NewScheduler(schedule string, syncEngine *syncengine.Engine, logger *logrus.Logger, metrics *Metrics) *Scheduler {
+func NewScheduler(schedule string, syncEngine *syncengine.Engine, logger *logrus.Logger, metrics *Metrics, notifier *notify.Dispatcher) *Scheduler {
 	// Create cron with logging
 	c := cron.New(cron.WithLogger(cron.VerbosePrintfLogger(logger)))
 
@@ -34,9 +50,37 @@ func NewScheduler(schedule string, syncEngine *syncengine.Engine, logger *logrus
 		syncEngine: syncEngine,
 		logger:     logger,
 		metrics:    metrics,
+		notifier:   notifier,
 	}
 }
 
+// SetIncidents opens incidents after consecutive failed scheduled syncs and resolves them on recovery
+func (s *Scheduler) SetIncidents(incidents *notify.IncidentManager) {
+	s.incidents = incidents
+}
+
+// SetMembershipDiffs controls how membership changes are listed in notifications
+func (s *Scheduler) SetMembershipDiffs(diffs config.MembershipDiffsConfig) {
+	s.diffs = diffs
+}
+
+// EnableReminders sends enrollment reminder emails on the given cron schedule
+func (s *Scheduler) EnableReminders(reminder *remind.Reminder, schedule string) {
+	s.reminder = reminder
+	s.remindCron = schedule
+}
+
+// EnableAccessReview writes access review exports on the configured schedule
+func (s *Scheduler) EnableAccessReview(review config.AccessReviewConfig) {
+	s.review = &review
+}
+
+// EnableDigest summarizes scheduled runs on the given cron schedule instead of alerting on each failure
+func (s *Scheduler) EnableDigest(schedule string) {
+	s.digest = notify.NewDigest()
+	s.digestCron = schedule
+}
+
 // Start starts the scheduler
 func (s *Scheduler) Start() error {
 	s.mu.Lock()
@@ -51,15 +95,34 @@ func (s *Scheduler) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to add cron job: %w", err)
 	}
+	s.syncEntry = entryID
+
+	if s.digest != nil {
+		if _, err := s.cron.AddFunc(s.digestCron, s.sendDigest); err != nil {
+			return fmt.Errorf("failed to add digest cron job: %w", err)
+		}
+	}
+
+	if s.reminder != nil {
+		if _, err := s.cron.AddFunc(s.remindCron, s.sendReminders); err != nil {
+			return fmt.Errorf("failed to add reminder cron job: %w", err)
+		}
+	}
+
+	if s.review != nil {
+		if _, err := s.cron.AddFunc(s.review.Schedule, s.exportAccessReview); err != nil {
+			return fmt.Errorf("failed to add access review cron job: %w", err)
+		}
+	}
 
 	// Start the cron scheduler
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 	s.cron.Start()
 	s.running = true
 
 	// Calculate next sync time
-	entries := s.cron.Entries()
-	if len(entries) > 0 {
-		nextTime := entries[0].Next
+	if entry := s.cron.Entry(s.syncEntry); entry.Valid() {
+		nextTime := entry.Next
 		s.nextSync = &nextTime
 	}
 
@@ -71,7 +134,7 @@ func (s *Scheduler) Start() error {
 	return nil
 }
 
-// Stop stops the scheduler
+// Stop stops the scheduler, cancelling any run in progress
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -80,8 +143,9 @@ func (s *Scheduler) Stop() {
 		return
 	}
 
-	// Stop the cron scheduler and wait for running jobs to complete
+	// Stop the cron scheduler, cancel running jobs and wait for them to return
 	ctx := s.cron.Stop()
+	s.cancel()
 	<-ctx.Done()
 
 	s.running = false
@@ -113,10 +177,9 @@ func (s *Scheduler) GetNextSync() *time.Time {
 		return nil
 	}
 
-	// Get the latest next time from cron entries
-	entries := s.cron.Entries()
-	if len(entries) > 0 {
-		nextTime := entries[0].Next
+	// Get the latest next time from the sync cron entry
+	if entry := s.cron.Entry(s.syncEntry); entry.Valid() {
+		nextTime := entry.Next
 		return &nextTime
 	}
 
@@ -125,10 +188,15 @@ func (s *Scheduler) GetNextSync() *time.Time {
 
 // runSync executes a sync operation (called by cron)
 func (s *Scheduler) runSync() {
+	if stop := s.syncEngine.EmergencyStopped(); stop != nil {
+		s.logger.Warnf("Skipping scheduled sync: emergency stop in effect: %s", stop)
+		return
+	}
+
 	s.logger.Info("Starting scheduled sync operation")
 
 	startTime := time.Now()
-	result, err := s.syncEngine.Sync()
+	result, err := s.syncEngine.Sync(s.ctx)
 	duration := time.Since(startTime)
 
 	// Update last sync time
@@ -136,9 +204,8 @@ func (s *Scheduler) runSync() {
 	s.lastSync = &startTime
 
 	// Update next sync time
-	entries := s.cron.Entries()
-	if len(entries) > 0 {
-		nextTime := entries[0].Next
+	if entry := s.cron.Entry(s.syncEntry); entry.Valid() {
+		nextTime := entry.Next
 		s.nextSync = &nextTime
 	}
 	s.mu.Unlock()
@@ -153,6 +220,95 @@ func (s *Scheduler) runSync() {
 		// Log summary
 		if len(result.Errors) > 0 {
 			s.logger.Warnf("Scheduled sync completed with %d errors", len(result.Errors))
+			for _, group := range result.ErrorSummary() {
+				s.logger.Warnf("Sync error: %s", group)
+			}
+		}
+	}
+
+	s.notifyResult(result, err)
+}
+
+// notifyResult alerts on a failed run, or adds it to the digest in digest mode
+func (s *Scheduler) notifyResult(result *syncengine.SyncResult, err error) {
+	if s.incidents != nil {
+		if err != nil {
+			s.incidents.RecordFailure(err)
+		} else {
+			s.incidents.RecordSuccess()
+		}
+	}
+
+	// Deleted groups are reported once, when first detected, so alert even in digest mode
+	if s.notifier != nil && result != nil && len(result.OrphanedGroups) > 0 {
+		_ = s.notifier.Notify(notify.GroupsOrphanedEvent(result.OrphanedGroups))
+	}
+
+	// A run nearing the API quota affects the next scheduled run, so alert even in digest mode
+	if s.notifier != nil && result != nil {
+		if exceeded := result.QuotaExceeded(); len(exceeded) > 0 {
+			_ = s.notifier.Notify(notify.QuotaWarningEvent(exceeded))
+		}
+	}
+
+	if s.digest != nil {
+		s.digest.Record(result, err)
+		return
+	}
+
+	if s.notifier == nil {
+		return
+	}
+	if err == nil && len(result.Errors) == 0 {
+		if s.diffs.Enabled && len(result.MembershipDiffs) > 0 {
+			_ = s.notifier.Notify(notify.MembershipChangedEvent(result, s.diffs.MaxEntries))
 		}
+		return
+	}
+
+	event := notify.SyncFailedEvent(result, err)
+	if result != nil && len(result.MembershipDiffs) > 0 {
+		event.Fields = append(event.Fields, notify.Field{
+			Name:  "Membership changes",
+			Value: notify.MembershipDiffText(result.MembershipDiffs, s.diffs.MaxEntries),
+		})
+	}
+	_ = s.notifier.Notify(event)
+}
+
+// sendDigest delivers the summary of runs since the last digest (called by cron)
+func (s *Scheduler) sendDigest() {
+	event := s.digest.Flush()
+	if s.notifier == nil {
+		return
+	}
+
+	s.logger.Info("Sending sync digest")
+	_ = s.notifier.Notify(event)
+}
+
+// sendReminders emails users who have not enrolled a passkey (called by cron)
+func (s *Scheduler) sendReminders() {
+	result, err := s.reminder.Run(s.ctx)
+	if err != nil {
+		s.logger.Errorf("Enrollment reminders failed: %v", err)
+		return
+	}
+	s.logger.Infof("Enrollment reminders: %d pending, %d sent, %d skipped, %d failed", result.Pending, result.Sent, result.Skipped, result.Failed)
+}
+
+// exportAccessReview writes the current group memberships to the access review directory (called by cron)
+func (s *Scheduler) exportAccessReview() {
+	groups, err := s.syncEngine.AccessReview(s.ctx)
+	if err != nil {
+		s.logger.Errorf("Access review export failed: %v", err)
+		return
+	}
+
+	path, err := report.SaveAccessReview(s.review.OutputDir, s.review.Format, groups, time.Now())
+	if err != nil {
+		s.logger.Errorf("Access review export failed: %v", err)
+		return
 	}
+	s.logger.Infof("Wrote access review of %d groups to %s", len(groups), path)
 }
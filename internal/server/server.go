@@ -3,17 +3,25 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
-	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/notify"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/remind"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
 	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/telemetry"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
@@ -24,8 +32,17 @@ type Server struct {
 	logger     *logrus.Logger
 	config     *config.Config
 	syncEngine SyncEngine
+	users      report.UserLister
 	scheduler  *Scheduler
+	jobs       *JobQueue
 	metrics    *Metrics
+	prometheus *telemetry.Prometheus // Served at /metrics/prometheus when metrics.prometheus.enabled
+	monitor    *Monitor
+
+	audit           *audit.Log
+	deprovisions    *confirmations
+	startedAt       time.Time
+	stopInitialSync context.CancelFunc // Cancels the initial sync of server.wait_for_initial_sync
 }
 
 // HealthResponse represents the health check response
@@ -40,6 +57,50 @@ type HealthResponse struct {
 }
 
 // SyncResponse represents the manual sync response
+// Bounds of POST /sync labels
+const (
+	maxSyncLabels         = 20
+	maxSyncLabelKeyLength = 64
+	maxSyncLabelLength    = 256
+)
+
+// SyncRequest is the optional body of POST /sync
+type SyncRequest struct {
+	Full   bool              `json:"full,omitempty"`    // Sync every group even when incremental sync is enabled; also ?full=true
+	DryRun bool              `json:"dry_run,omitempty"` // Report changes as in test mode without writing them
+	Groups []string          `json:"groups,omitempty"`  // Sync only these configured groups
+	Labels map[string]string `json:"labels,omitempty"`  // Free-form labels recorded with the run in the journal, e.g. a ticket
+}
+
+func (r *SyncRequest) validate() []FieldError {
+	var fields []FieldError
+	if r.Full && len(r.Groups) > 0 {
+		fields = append(fields, FieldError{Field: "full", Message: "cannot be combined with groups"})
+	}
+	for i, group := range r.Groups {
+		if !strings.Contains(group, "@") {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("groups[%d]", i), Message: "must be a group email address"})
+		}
+	}
+	if len(r.Labels) > maxSyncLabels {
+		fields = append(fields, FieldError{Field: "labels", Message: fmt.Sprintf("at most %d labels are allowed", maxSyncLabels)})
+	}
+	keys := make([]string, 0, len(r.Labels))
+	for key := range r.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch {
+		case key == "" || len(key) > maxSyncLabelKeyLength:
+			fields = append(fields, FieldError{Field: "labels." + key, Message: fmt.Sprintf("label names must be 1 to %d characters", maxSyncLabelKeyLength)})
+		case len(r.Labels[key]) > maxSyncLabelLength:
+			fields = append(fields, FieldError{Field: "labels." + key, Message: fmt.Sprintf("label values must be at most %d characters", maxSyncLabelLength)})
+		}
+	}
+	return fields
+}
+
 type SyncResponse struct {
 	Status    string     `json:"status"`
 	Message   string     `json:"message"`
@@ -50,41 +111,138 @@ type SyncResponse struct {
 
 // SyncStats represents synchronization statistics
 type SyncStats struct {
-	GroupsProcessed    int           `json:"groups_processed"`
-	UsersCreated       int           `json:"users_created"`
-	UsersUpdated       int           `json:"users_updated"`
-	GroupsCreated      int           `json:"groups_created"`
-	MembershipsAdded   int           `json:"memberships_added"`
-	MembershipsRemoved int           `json:"memberships_removed"`
-	Duration           time.Duration `json:"duration"`
-	Errors             []string      `json:"errors"`
+	GroupsProcessed     int                            `json:"groups_processed"`
+	UsersCreated        int                            `json:"users_created"`
+	UsersUpdated        int                            `json:"users_updated"`
+	GroupsCreated       int                            `json:"groups_created"`
+	MembershipsAdded    int                            `json:"memberships_added"`
+	MembershipsRemoved  int                            `json:"memberships_removed"`
+	UsersSkipped        int                            `json:"users_skipped,omitempty"`
+	UsersFiltered       int                            `json:"users_filtered,omitempty"`       // Members sync.filters kept from being provisioned
+	UserConflicts       []syncengine.UserConflict      `json:"user_conflicts,omitempty"`       // Existing users the sync did not provision
+	SkippedSteps        []string                       `json:"skipped_steps,omitempty"`        // e.g. enrollment status while the Native API is down
+	PartialGroups       []string                       `json:"partial_groups,omitempty"`       // Synced from incomplete membership without removals
+	ReadOnly            bool                           `json:"read_only,omitempty"`            // Changes were reported but not written
+	DryRun              bool                           `json:"dry_run,omitempty"`              // Requested as a dry run; changes were reported but not written
+	DeferredQueued      int                            `json:"deferred_queued,omitempty"`      // Failed writes queued for retry
+	DeferredRetried     int                            `json:"deferred_retried,omitempty"`     // Queued writes that succeeded
+	QueueDepth          int                            `json:"queue_depth"`                    // Writes still queued
+	ShadowDiscrepancies []syncengine.ShadowDiscrepancy `json:"shadow_discrepancies,omitempty"` // With the shadow_mode flag
+	Incremental         bool                           `json:"incremental,omitempty"`          // Only groups changed since the last successful run were synced
+	GroupsUnchanged     int                            `json:"groups_unchanged,omitempty"`     // Groups an incremental run skipped
+	SimulatedDiffs      []syncengine.GroupDiff         `json:"simulated_diffs,omitempty"`      // Membership changes only logged because of sync.test_mode_operations
+	GroupsCleanedUp     []string                       `json:"groups_cleaned_up,omitempty"`    // Orphaned groups deleted or emptied by sync.orphaned_groups.cleanup
+	Changes             []report.ChangeRecord          `json:"changes,omitempty"`              // Each change made, planned or failed
+	Duration            time.Duration                  `json:"duration"`
+	Errors              []string                       `json:"errors"`
+	ErrorSummary        []string                       `json:"error_summary,omitempty"`
 }
 
 // NewServer creates a new HTTP server instance
 func NewServer(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
-	// Create Google Workspace client
-	gwsClient, err := gws.NewClient(
-		cfg.GoogleWorkspace.ServiceAccountKeyPath,
-		cfg.GoogleWorkspace.Domain,
-		cfg.GoogleWorkspace.SuperAdminEmail,
-	)
+	// Measurements are published to the in-process metrics and any configured sinks
+	metrics := NewMetrics()
+	sinks := &syncengine.MetricsSinks{}
+	sinks.Add(metrics)
+	prometheus, err := telemetry.FromConfig(cfg.Metrics, sinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics sinks: %w", err)
+	}
+
+	httpOpts, err := httpclient.OptionsFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	httpOpts.Observer = sinks.ObserveHTTP
+	httpClient := httpclient.New(httpOpts)
+	if cfg.Network.InsecureSkipVerify {
+		logger.Warn("network.insecure_skip_verify is enabled: TLS certificates are NOT verified and API tokens can be intercepted")
+	}
+
+	// Create Google Workspace client for the configured group API
+	gwsCalls := syncengine.NewGWSCallMetrics(cfg, logger)
+	metrics.SetGWSCallMetrics(gwsCalls)
+	gwsClient, err := syncengine.NewGWSClientWithCallMetrics(cfg, httpClient, gwsCalls, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Google Workspace client: %w", err)
 	}
 
 	// Create Beyond Identity client
-	biClient := bi.NewClient(cfg.BeyondIdentity.APIToken, cfg.BeyondIdentity.SCIMBaseURL, cfg.BeyondIdentity.NativeAPIURL)
+	biClient := syncengine.NewPrimaryClient(cfg, httpClient)
+
+	// Enrollment reports and reminders read the Native API of a Beyond Identity primary target
+	var users report.UserLister
+	if cfg.PrimaryIsBeyondIdentity() {
+		users = biClient
+	}
 
 	// Create sync engine
 	syncEngine := syncengine.NewEngine(gwsClient, biClient, cfg, logger)
+	syncEngine.SetMetricsSinks(sinks)
+	syncEngine.SetVersion(buildInfo.Version)
 
-	// Create metrics collector
-	metrics := NewMetrics()
+	// Record the anonymous usage report, sent only when telemetry.enabled is set
+	syncEngine.OnSyncFinished(telemetry.NewUsage(cfg, httpClient, buildInfo.Version, telemetry.UsageModeServer, logger).RunFinished)
+
+	// Register clients for any additional provisioning targets
+	if err := syncEngine.RegisterTargets(httpClient); err != nil {
+		return nil, fmt.Errorf("failed to create target clients: %w", err)
+	}
+
+	// Read membership from a CSV export instead of Google groups when configured
+	if err := syncEngine.ConfigureSource(httpClient); err != nil {
+		return nil, fmt.Errorf("failed to configure membership source: %w", err)
+	}
+
+	// Load group mappings and orphaned groups from previous runs
+	if err := syncEngine.ConfigureState(); err != nil {
+		return nil, fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	// Read the audit log so runs can skip unchanged groups when sync.incremental is enabled
+	if err := syncEngine.ConfigureIncremental(httpClient); err != nil {
+		return nil, fmt.Errorf("failed to configure incremental sync: %w", err)
+	}
+
+	// Clean up after a previous run that crashed holding the sync lock
+	if _, err := syncEngine.Recover(); err != nil {
+		return nil, fmt.Errorf("failed to recover from a previous crash: %w", err)
+	}
+
+	// Report missing API permissions before the first write fails
+	syncEngine.LogCapabilities(context.Background())
+
+	// Alert when any run, scheduled or requested, outlives sync.max_expected_duration
+	notifier := notify.NewFromConfig(cfg.Notifications, httpClient, logger)
+	syncEngine.OnStuckRun(func(run syncengine.StuckRun) {
+		_ = notifier.Notify(notify.SyncStuckEvent(run))
+	})
 
 	// Create scheduler if scheduling is enabled
 	var scheduler *Scheduler
 	if cfg.Server.ScheduleEnabled {
-		scheduler = NewScheduler(cfg.Server.Schedule, syncEngine, logger, metrics)
+		scheduler = NewScheduler(cfg.Server.Schedule, syncEngine, logger, metrics, notifier)
+		scheduler.SetIncidents(notify.NewIncidentManagerFromConfig(cfg.Notifications, httpClient, logger))
+		scheduler.SetMembershipDiffs(cfg.Notifications.MembershipDiffs)
+		if cfg.Notifications.Mode == config.NotificationModeDigest {
+			scheduler.EnableDigest(cfg.Notifications.Digest.Schedule)
+		}
+
+		if cfg.Reminders.Enabled && users != nil {
+			sender, err := remind.NewSender(cfg, httpClient)
+			if err != nil {
+				return nil, err
+			}
+			reminder, err := remind.NewReminder(cfg.Reminders, users, sender, cfg.App.TestMode, logger)
+			if err != nil {
+				return nil, err
+			}
+			scheduler.EnableReminders(reminder, cfg.Reminders.Schedule)
+		}
+
+		if cfg.AccessReview.Schedule != "" {
+			scheduler.EnableAccessReview(cfg.AccessReview)
+		}
 	}
 
 	// Create router
@@ -94,8 +252,24 @@ func NewServer(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
 		logger:     logger,
 		config:     cfg,
 		syncEngine: syncEngine,
+		users:      users,
 		scheduler:  scheduler,
 		metrics:    metrics,
+		prometheus: prometheus,
+
+		audit:        audit.New(cfg.Server.AuditLogPath),
+		deprovisions: newConfirmations(),
+		startedAt:    time.Now(),
+	}
+
+	// Watch for goroutine, heap and file descriptor leaks in long-running deployments
+	if cfg.Server.Monitor.IntervalSeconds > 0 {
+		server.monitor = NewMonitor(cfg.Server.Monitor, httpClient, metrics, logger)
+	}
+
+	// Targeted syncs requested by external systems run one at a time in the background
+	if cfg.Server.WebhookSecret != "" {
+		server.jobs = NewJobQueue(syncEngine, logger, jobQueueSize)
 	}
 
 	// Register routes
@@ -117,14 +291,27 @@ func NewServer(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
 
 // registerRoutes sets up HTTP endpoints
 func (s *Server) registerRoutes(router *mux.Router) {
-	// Health check endpoint
+	// Health check endpoints
 	router.HandleFunc("/health", s.handleHealth).Methods("GET")
+	router.HandleFunc("/readyz", s.handleReady).Methods("GET")
 
 	// Manual sync endpoint
 	router.HandleFunc("/sync", s.handleSync).Methods("POST")
+	router.HandleFunc("/sync/current", s.handleCurrentRun).Methods("GET")
+	router.HandleFunc("/sync/group/{email}", s.handleSyncGroup).Methods("POST")
+	router.HandleFunc("/sync/{id}/{action:cancel|stop|pause|resume}", s.handleRunControl).Methods("POST")
+	router.HandleFunc("/users/provision", s.handleProvisionUser).Methods("POST")
+	router.HandleFunc("/users/deprovision", s.handleDeprovisionUser).Methods("POST")
+	router.HandleFunc("/users/{email}/memberships", s.handleUserMemberships).Methods("GET")
+	router.HandleFunc("/skiplist", s.handleListSkipped).Methods("GET")
+	router.HandleFunc("/skiplist", s.handleSkipUser).Methods("POST")
+	router.HandleFunc("/skiplist/{email}", s.handleUnskipUser).Methods("DELETE")
 
 	// Metrics endpoint
 	router.HandleFunc("/metrics", s.handleMetrics).Methods("GET")
+	if s.prometheus != nil && s.config.Metrics.Prometheus.Enabled {
+		router.Handle("/metrics/prometheus", s.prometheus).Methods("GET")
+	}
 
 	// Scheduler control endpoints
 	if s.scheduler != nil {
@@ -133,20 +320,48 @@ func (s *Server) registerRoutes(router *mux.Router) {
 		router.HandleFunc("/scheduler/status", s.handleSchedulerStatus).Methods("GET")
 	}
 
-	// Version endpoint
+	// Report endpoints
+	router.HandleFunc("/report/pending-enrollment", s.handlePendingEnrollmentReport).Methods("GET")
+	router.HandleFunc("/report/access-review", s.handleAccessReviewReport).Methods("GET")
+	router.HandleFunc("/changes", s.handleChanges).Methods("GET")
+	router.HandleFunc("/runs", s.handleRuns).Methods("GET")
+	router.HandleFunc("/runs/{id}", s.handleRun).Methods("GET")
+
+	// Webhook and job endpoints
+	if s.jobs != nil {
+		router.HandleFunc("/hooks/trigger", s.handleTrigger).Methods("POST")
+		router.HandleFunc("/jobs/{id}", s.handleJobStatus).Methods("GET")
+	}
+
+	// Version and deployment information endpoints
 	router.HandleFunc("/version", s.handleVersion).Methods("GET")
+	router.HandleFunc("/info", s.handleInfo).Methods("GET")
+	router.HandleFunc("/features", s.handleFeatures).Methods("GET")
+	router.HandleFunc("/mode/read-only", s.handleReadOnly).Methods("POST")
+
+	// Emergency stop endpoints
+	router.HandleFunc("/emergency-stop", s.handleEmergencyStatus).Methods("GET")
+	router.HandleFunc("/emergency-stop", s.handleEmergencyStop).Methods("POST")
+	router.HandleFunc("/emergency-stop/resume", s.handleEmergencyResume).Methods("POST")
 }
 
 // Start starts the HTTP server and scheduler
 func (s *Server) Start() error {
 	s.logger.Infof("Starting SCIM sync server on port %d", s.config.Server.Port)
 
-	// Start scheduler if enabled
-	if s.scheduler != nil {
-		if err := s.scheduler.Start(); err != nil {
-			return fmt.Errorf("failed to start scheduler: %w", err)
+	// Start scheduler if enabled; with server.wait_for_initial_sync it starts after the initial sync
+	if !s.config.Server.WaitForInitialSync {
+		if err := s.startScheduler(); err != nil {
+			return err
 		}
-		s.logger.Info("Scheduler started successfully")
+	}
+
+	if s.jobs != nil {
+		s.jobs.Start()
+	}
+
+	if s.monitor != nil {
+		s.monitor.Start()
 	}
 
 	// Start HTTP server in a goroutine
@@ -156,6 +371,20 @@ func (s *Server) Start() error {
 		}
 	}()
 
+	if s.config.Server.WaitForInitialSync {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopInitialSync = cancel
+		go func() {
+			s.runInitialSync(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err := s.startScheduler(); err != nil {
+				s.logger.Errorf("%v", err)
+			}
+		}()
+	}
+
 	s.logger.Info("SCIM sync server started successfully")
 
 	// Wait for shutdown signal
@@ -172,12 +401,27 @@ func (s *Server) waitForShutdown() {
 	sig := <-sigChan
 	s.logger.Infof("Received signal %s, starting graceful shutdown...", sig)
 
+	// Abort the initial sync, which also keeps the scheduler from starting
+	if s.stopInitialSync != nil {
+		s.stopInitialSync()
+	}
+
 	// Stop scheduler
 	if s.scheduler != nil {
 		s.scheduler.Stop()
 		s.logger.Info("Scheduler stopped")
 	}
 
+	if s.monitor != nil {
+		s.monitor.Stop()
+	}
+
+	// Finish queued targeted syncs
+	if s.jobs != nil {
+		s.jobs.Stop()
+		s.logger.Info("Job queue stopped")
+	}
+
 	// Stop HTTP server
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -201,7 +445,7 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 	response := HealthResponse{
 		Status:      "healthy",
-		Version:     "0.1.0",
+		Version:     buildInfo.Version,
 		Timestamp:   time.Now(),
 		Services:    services,
 		SyncEnabled: s.scheduler != nil,
@@ -224,12 +468,97 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleSync handles manual sync requests
+// newSyncStats converts a sync result into its API representation
+func newSyncStats(result *syncengine.SyncResult, duration time.Duration) *SyncStats {
+	return &SyncStats{
+		GroupsProcessed:     result.GroupsProcessed,
+		UsersCreated:        result.UsersCreated,
+		UsersUpdated:        result.UsersUpdated,
+		GroupsCreated:       result.GroupsCreated,
+		MembershipsAdded:    result.MembershipsAdded,
+		MembershipsRemoved:  result.MembershipsRemoved,
+		UsersSkipped:        result.UsersSkipped,
+		UsersFiltered:       result.UsersFiltered,
+		UserConflicts:       result.UserConflicts,
+		SkippedSteps:        result.SkippedSteps,
+		PartialGroups:       result.PartialGroups,
+		ReadOnly:            result.ReadOnly,
+		DryRun:              result.DryRun,
+		DeferredQueued:      result.DeferredQueued,
+		DeferredRetried:     result.DeferredRetried,
+		QueueDepth:          result.QueueDepth,
+		ShadowDiscrepancies: result.ShadowDiscrepancies,
+		Incremental:         result.Incremental,
+		GroupsUnchanged:     result.GroupsUnchanged,
+		SimulatedDiffs:      result.SimulatedDiffs,
+		GroupsCleanedUp:     result.GroupsCleanedUp,
+		Changes:             result.Changes,
+		Duration:            duration,
+		Errors:              errorStrings(result.Errors),
+		ErrorSummary:        errorSummary(result),
+	}
+}
+
+// handleSync handles manual sync requests, with the options of an optional SyncRequest body;
+// ?full=true syncs every group even when incremental sync is enabled
 func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
-	s.logger.Info("Manual sync requested via API")
+	var req SyncRequest
+	if !s.decodeRequest(w, r, &req, true) {
+		return
+	}
+	if full, _ := strconv.ParseBool(r.URL.Query().Get("full")); full {
+		req.Full = true
+	}
+	var unconfigured []FieldError
+	for i, group := range req.Groups {
+		if !s.isSyncedGroup(group) {
+			unconfigured = append(unconfigured, FieldError{Field: fmt.Sprintf("groups[%d]", i), Message: "is not configured for sync"})
+		}
+	}
+	if len(unconfigured) > 0 {
+		s.writeValidationErrors(w, unconfigured)
+		return
+	}
+	s.logger.Infof("Manual sync requested via API (full: %t, dry run: %t, groups: %d, labels: %v)", req.Full, req.DryRun, len(req.Groups), req.Labels)
+	s.runManualSync(w, r, req)
+}
+
+// handleSyncGroup handles requests to sync the one configured group named in the path, with the
+// dry run and labels of an optional SyncRequest body
+func (s *Server) handleSyncGroup(w http.ResponseWriter, r *http.Request) {
+	var req SyncRequest
+	if !s.decodeRequest(w, r, &req, true) {
+		return
+	}
+	if len(req.Groups) > 0 {
+		s.writeValidationErrors(w, []FieldError{{Field: "groups", Message: "cannot be set when the group is named in the path"}})
+		return
+	}
+	group := mux.Vars(r)["email"]
+	if !s.isSyncedGroup(group) {
+		http.Error(w, "Group is not configured for sync", http.StatusNotFound)
+		return
+	}
+	req.Groups = []string{group}
+	s.logger.Infof("Group sync requested via API (group: %s, dry run: %t, labels: %v)", group, req.DryRun, req.Labels)
+	s.runManualSync(w, r, req)
+}
+
+// runManualSync runs the sync a SyncRequest asks for and writes its SyncResponse
+func (s *Server) runManualSync(w http.ResponseWriter, r *http.Request, req SyncRequest) {
+	run := s.syncEngine.Sync
+	switch {
+	case len(req.Groups) > 0:
+		run = func(ctx context.Context) (*syncengine.SyncResult, error) {
+			return s.syncEngine.SyncGroups(ctx, req.Groups)
+		}
+	case req.Full:
+		run = s.syncEngine.SyncFull
+	}
+	ctx := syncengine.WithRunOptions(r.Context(), syncengine.RunOptions{DryRun: req.DryRun, Labels: req.Labels})
 
 	startTime := time.Now()
-	result, err := s.syncEngine.Sync()
+	result, err := run(ctx)
 	duration := time.Since(startTime)
 
 	response := SyncResponse{
@@ -241,21 +570,16 @@ func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
 		response.Status = "error"
 		response.Message = "Sync operation failed"
 		response.Error = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, syncengine.ErrSyncDisabled) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 	} else {
 		s.logger.Info("Manual sync completed successfully")
 		response.Status = "success"
 		response.Message = "Sync operation completed"
-		response.Result = &SyncStats{
-			GroupsProcessed:    result.GroupsProcessed,
-			UsersCreated:       result.UsersCreated,
-			UsersUpdated:       result.UsersUpdated,
-			GroupsCreated:      result.GroupsCreated,
-			MembershipsAdded:   result.MembershipsAdded,
-			MembershipsRemoved: result.MembershipsRemoved,
-			Duration:           duration,
-			Errors:             errorStrings(result.Errors),
-		}
+		response.Result = newSyncStats(result, duration)
 
 		// Update metrics
 		s.metrics.RecordSync(result, duration)
@@ -336,8 +660,9 @@ func (s *Server) handleSchedulerStatus(w http.ResponseWriter, r *http.Request) {
 // handleVersion handles version requests
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
 	version := map[string]string{
-		"version":    "0.1.0",
-		"build_time": time.Now().Format(time.RFC3339),
+		"version":    buildInfo.Version,
+		"commit":     buildInfo.Commit,
+		"build_time": buildInfo.Date,
 		"mode":       "server",
 	}
 
@@ -348,6 +673,15 @@ func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// errorSummary describes the sync errors grouped by root cause
+func errorSummary(result *syncengine.SyncResult) []string {
+	var summary []string
+	for _, group := range result.ErrorSummary() {
+		summary = append(summary, group.String())
+	}
+	return summary
+}
+
 // errorStrings converts a slice of errors to a slice of strings
 func errorStrings(errors []error) []string {
 	if len(errors) == 0 {
@@ -2,33 +2,222 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/telemetry"
 )
 
 // Mock sync engine for testing
 type mockSyncEngine struct {
-	shouldError bool
-	result      *sync.SyncResult
+	shouldError     bool
+	result          *sync.SyncResult
+	changes         []state.Change
+	review          []report.AccessReviewGroup
+	provisionGroups []string
+	deprovisions    []string
+	skipped         map[string]state.SkippedUser
+	readOnly        bool
+	stop            *sync.EmergencyStop
+	fullSyncs       int
+	groupSyncs      [][]string
+	recent          []sync.RecentRun
+	active          *sync.ActiveRun
 }
 
-func (m *mockSyncEngine) Sync() (*sync.SyncResult, error) {
+func (m *mockSyncEngine) Sync(ctx context.Context) (*sync.SyncResult, error) {
+	if m.stop != nil {
+		return nil, fmt.Errorf("%w: %s", sync.ErrSyncDisabled, m.stop)
+	}
 	if m.shouldError {
 		return nil, fmt.Errorf("mock sync error")
 	}
 	return m.result, nil
 }
 
+func (m *mockSyncEngine) SyncFull(ctx context.Context) (*sync.SyncResult, error) {
+	m.fullSyncs++
+	return m.Sync(ctx)
+}
+
+func (m *mockSyncEngine) SyncGroups(ctx context.Context, groupEmails []string) (*sync.SyncResult, error) {
+	m.groupSyncs = append(m.groupSyncs, groupEmails)
+	return m.Sync(ctx)
+}
+
+func (m *mockSyncEngine) SyncUser(ctx context.Context, email string) (*sync.SyncResult, error) {
+	return m.Sync(ctx)
+}
+
+func (m *mockSyncEngine) ProvisionUser(ctx context.Context, email string) (*sync.UserProvisionResult, error) {
+	result, err := m.Sync(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sync.UserProvisionResult{SyncResult: result, Groups: m.provisionGroups}, nil
+}
+
+func (m *mockSyncEngine) PlanDeprovision(ctx context.Context, email string) (*sync.DeprovisionResult, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf("mock plan error")
+	}
+	return &sync.DeprovisionResult{
+		Email:       email,
+		Memberships: []sync.DeprovisionMembership{{SourceGroup: "eng@example.com", GroupName: "GWS_Engineering"}},
+	}, nil
+}
+
+func (m *mockSyncEngine) DeprovisionUser(ctx context.Context, email string, deactivate bool) (*sync.DeprovisionResult, error) {
+	result, err := m.PlanDeprovision(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	m.deprovisions = append(m.deprovisions, email)
+	return result, nil
+}
+
+func (m *mockSyncEngine) UserAccess(ctx context.Context, email string) (*sync.UserAccess, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf("mock lookup error")
+	}
+	access := &sync.UserAccess{Email: email, Targets: []sync.UserTargetStatus{}, Groups: []sync.UserGroupAccess{}}
+	if email == "alice@example.com" {
+		access.Targets = append(access.Targets, sync.UserTargetStatus{Target: "default", UserID: "u1", Active: true})
+		access.Groups = append(access.Groups, sync.UserGroupAccess{SourceGroup: "eng@example.com", InSource: true, InBIGroup: true})
+	}
+	return access, nil
+}
+
+func (m *mockSyncEngine) SkipUser(email, reason, addedBy string, ttl time.Duration) (state.SkippedUser, error) {
+	if m.shouldError {
+		return state.SkippedUser{}, fmt.Errorf("mock skip error")
+	}
+	if m.skipped == nil {
+		m.skipped = make(map[string]state.SkippedUser)
+	}
+	entry := state.SkippedUser{Email: email, Reason: reason, AddedBy: addedBy, AddedAt: time.Now()}
+	if ttl > 0 {
+		entry.ExpiresAt = entry.AddedAt.Add(ttl)
+	}
+	m.skipped[email] = entry
+	return entry, nil
+}
+
+func (m *mockSyncEngine) UnskipUser(email string) (bool, error) {
+	if _, ok := m.skipped[email]; !ok {
+		return false, nil
+	}
+	delete(m.skipped, email)
+	return true, nil
+}
+
+func (m *mockSyncEngine) SkippedUsers() []state.SkippedUser {
+	users := []state.SkippedUser{}
+	for _, entry := range m.skipped {
+		users = append(users, entry)
+	}
+	return users
+}
+
+func (m *mockSyncEngine) AccessReview(ctx context.Context) ([]report.AccessReviewGroup, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf("mock access review error")
+	}
+	return m.review, nil
+}
+
+func (m *mockSyncEngine) SetReadOnly(enabled bool) {
+	m.readOnly = enabled
+}
+
+func (m *mockSyncEngine) ReadOnly() bool {
+	return m.readOnly
+}
+
+func (m *mockSyncEngine) EmergencyStop(reason, requestedBy string) (*sync.EmergencyStop, error) {
+	m.stop = &sync.EmergencyStop{Source: sync.StopSourceAPI, Reason: reason, RequestedBy: requestedBy}
+	return m.stop, nil
+}
+
+func (m *mockSyncEngine) ResumeSyncs() error {
+	m.stop = nil
+	return nil
+}
+
+func (m *mockSyncEngine) EmergencyStopped() *sync.EmergencyStop {
+	return m.stop
+}
+
+func (m *mockSyncEngine) RecentRuns() []sync.RecentRun {
+	return m.recent
+}
+
+func (m *mockSyncEngine) RecentRun(id string) (sync.RecentRun, bool) {
+	for _, run := range m.recent {
+		if run.ID == id {
+			return run, true
+		}
+	}
+	return sync.RecentRun{}, false
+}
+
+func (m *mockSyncEngine) RunInProgress() (sync.ActiveRun, bool) {
+	if m.active == nil {
+		return sync.ActiveRun{}, false
+	}
+	return *m.active, true
+}
+
+func (m *mockSyncEngine) CancelRun(id string) error {
+	return m.controlRun(id, func(run *sync.ActiveRun) { m.active = nil })
+}
+
+func (m *mockSyncEngine) StopRun(id string) error {
+	return m.controlRun(id, func(run *sync.ActiveRun) { run.Stopping = true })
+}
+
+func (m *mockSyncEngine) PauseRun(id string) error {
+	return m.controlRun(id, func(run *sync.ActiveRun) { run.Paused = true })
+}
+
+func (m *mockSyncEngine) ResumeRun(id string) error {
+	return m.controlRun(id, func(run *sync.ActiveRun) { run.Paused = false })
+}
+
+func (m *mockSyncEngine) controlRun(id string, apply func(run *sync.ActiveRun)) error {
+	if m.active == nil || (id != "" && m.active.ID != id) {
+		return sync.ErrRunNotFound
+	}
+	apply(m.active)
+	return nil
+}
+
+func (m *mockSyncEngine) Changes(since, until time.Time) []state.Change {
+	var changes []state.Change
+	for _, change := range m.changes {
+		if !change.Time.Before(since) && (until.IsZero() || change.Time.Before(until)) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
 // Helper to create a test server without external dependencies
 func createTestServer(t *testing.T) *Server {
 	cfg := &config.Config{
@@ -44,9 +233,11 @@ func createTestServer(t *testing.T) *Server {
 	logger.SetLevel(logrus.FatalLevel) // Reduce log noise during tests
 
 	server := &Server{
-		config:  cfg,
-		logger:  logger,
-		metrics: NewMetrics(),
+		config:       cfg,
+		logger:       logger,
+		metrics:      NewMetrics(),
+		audit:        audit.New(""),
+		deprovisions: newConfirmations(),
 		syncEngine: &mockSyncEngine{
 			result: &sync.SyncResult{
 				GroupsProcessed:    2,
@@ -192,6 +383,32 @@ func TestHandleSync_Error(t *testing.T) {
 	}
 }
 
+func TestHandleSync_Full(t *testing.T) {
+	server := createTestServer(t)
+	engine := &mockSyncEngine{result: &sync.SyncResult{GroupsProcessed: 2}}
+	server.syncEngine = engine
+
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	for _, tt := range []struct {
+		url       string
+		fullSyncs int
+	}{
+		{"/sync", 0},
+		{"/sync?full=true", 1},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", tt.url, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("POST %s: expected status 200, got %d", tt.url, rr.Code)
+		}
+		if engine.fullSyncs != tt.fullSyncs {
+			t.Errorf("POST %s: expected %d full syncs, got %d", tt.url, tt.fullSyncs, engine.fullSyncs)
+		}
+	}
+}
+
 func TestHandleMetrics(t *testing.T) {
 	server := createTestServer(t)
 
@@ -471,3 +688,55 @@ func TestHealthResponse(t *testing.T) {
 		t.Error("Expected next sync to be set")
 	}
 }
+
+func TestAPIDocsListEveryRoute(t *testing.T) {
+	server := createTestServer(t)
+	server.config.Metrics.Prometheus.Enabled = true
+	server.prometheus = &telemetry.Prometheus{}
+	server.scheduler = &Scheduler{}
+	server.jobs = &JobQueue{}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	// Variables restricted to alternatives, e.g. {action:cancel|stop}, are documented one by one
+	alternatives := regexp.MustCompile(`\{\w+:([^}]+)\}`)
+	var endpoints []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		paths := []string{path}
+		if match := alternatives.FindStringSubmatchIndex(path); match != nil {
+			paths = nil
+			for _, alternative := range strings.Split(path[match[2]:match[3]], "|") {
+				paths = append(paths, path[:match[0]]+alternative+path[match[1]:])
+			}
+		}
+		for _, method := range methods {
+			for _, path := range paths {
+				endpoints = append(endpoints, "`"+method+" "+path+"`")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk routes: %v", err)
+	}
+
+	for _, doc := range []string{"../../docs/API.md", "../setup/templates/docs/api.md.tmpl"} {
+		content, err := os.ReadFile(doc)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", doc, err)
+		}
+		for _, endpoint := range endpoints {
+			if !strings.Contains(string(content), endpoint) {
+				t.Errorf("%s does not list %s", doc, endpoint)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	"github.com/gorilla/mux"
+)
+
+// SkipUserRequest adds a user to the skip list
+type SkipUserRequest struct {
+	Email         string `json:"email"`
+	Reason        string `json:"reason"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty"` // 0 keeps the entry until it is removed
+	AddedBy       string `json:"added_by,omitempty"`
+}
+
+func (r *SkipUserRequest) validate() []FieldError {
+	fields := requireEmail("email", r.Email)
+	if strings.TrimSpace(r.Reason) == "" {
+		fields = append(fields, FieldError{Field: "reason", Message: "is required"})
+	}
+	if r.ExpiresInDays < 0 {
+		fields = append(fields, FieldError{Field: "expires_in_days", Message: "must be a non-negative integer"})
+	}
+	return fields
+}
+
+// SkipListResponse lists the users syncs do not try to provision
+type SkipListResponse struct {
+	Count int                 `json:"count"`
+	Users []state.SkippedUser `json:"users"`
+}
+
+// handleListSkipped lists the skip-list entries in effect
+func (s *Server) handleListSkipped(w http.ResponseWriter, r *http.Request) {
+	users := s.syncEngine.SkippedUsers()
+	response := SkipListResponse{Count: len(users), Users: users}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode skip list response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleSkipUser adds a user to the skip list
+func (s *Server) handleSkipUser(w http.ResponseWriter, r *http.Request) {
+	var req SkipUserRequest
+	if !s.decodeRequest(w, r, &req, false) {
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	entry, err := s.syncEngine.SkipUser(req.Email, req.Reason, req.AddedBy, ttl)
+	if err != nil {
+		s.logger.Errorf("Failed to add %s to the skip list: %v", req.Email, err)
+		http.Error(w, "Failed to update skip list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		s.logger.Error("Failed to encode skip list response", "error", err)
+	}
+}
+
+// handleUnskipUser removes a user from the skip list
+func (s *Server) handleUnskipUser(w http.ResponseWriter, r *http.Request) {
+	email := mux.Vars(r)["email"]
+	removed, err := s.syncEngine.UnskipUser(email)
+	if err != nil {
+		s.logger.Errorf("Failed to remove %s from the skip list: %v", email, err)
+		http.Error(w, "Failed to update skip list", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "User is not on the skip list", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandleSkipList(t *testing.T) {
+	server := createTestServer(t)
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	if rr := serve("POST", "/skiplist", `{`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected malformed JSON to be rejected, got %d", rr.Code)
+	}
+	invalidRequests := []string{
+		`{"reason":"invalid email"}`,
+		`{"email":"bad@example.com"}`,
+		`{"email":"bad@example.com","reason":"x","expires_in_days":-1}`,
+		`{"email":"bad@example.com","reason":"x","expires_in_days":"30"}`,
+	}
+	for _, body := range invalidRequests {
+		if rr := serve("POST", "/skiplist", body); rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected %s to be rejected, got %d", body, rr.Code)
+		}
+	}
+
+	if rr := serve("POST", "/skiplist", `{"email":"bad@example.com","reason":"invalid email","expires_in_days":30}`); rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	rr := serve("GET", "/skiplist", "")
+	var list SkipListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if list.Count != 1 || list.Users[0].Reason != "invalid email" || list.Users[0].ExpiresAt.IsZero() {
+		t.Errorf("Unexpected skip list: %+v", list)
+	}
+
+	if rr := serve("DELETE", "/skiplist/bad@example.com", ""); rr.Code != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, rr.Code)
+	}
+	if rr := serve("DELETE", "/skiplist/bad@example.com", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
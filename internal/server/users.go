@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gorilla/mux"
+)
+
+// ProvisionUserRequest names the user to provision
+type ProvisionUserRequest struct {
+	Email string `json:"email"`
+}
+
+func (r *ProvisionUserRequest) validate() []FieldError {
+	return requireEmail("email", r.Email)
+}
+
+// ProvisionUserResponse represents the result of provisioning a single user
+type ProvisionUserResponse struct {
+	Status    string     `json:"status"`
+	Message   string     `json:"message"`
+	Timestamp time.Time  `json:"timestamp"`
+	Email     string     `json:"email"`
+	Groups    []string   `json:"groups,omitempty"` // Synced source groups the user belongs to
+	Result    *SyncStats `json:"result,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// handleProvisionUser provisions one user and their memberships in the synced groups immediately
+func (s *Server) handleProvisionUser(w http.ResponseWriter, r *http.Request) {
+	var req ProvisionUserRequest
+	if !s.decodeRequest(w, r, &req, false) {
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+
+	s.logger.Infof("Provisioning of %s requested via API", req.Email)
+
+	startTime := time.Now()
+	provisioned, err := s.syncEngine.ProvisionUser(r.Context(), req.Email)
+	duration := time.Since(startTime)
+
+	response := ProvisionUserResponse{
+		Timestamp: time.Now(),
+		Email:     req.Email,
+	}
+	status := http.StatusOK
+
+	switch {
+	case err != nil:
+		s.logger.Errorf("Provisioning of %s failed: %v", req.Email, err)
+		response.Status = "error"
+		response.Message = "Provisioning failed"
+		response.Error = err.Error()
+		status = http.StatusInternalServerError
+		if errors.Is(err, syncengine.ErrSyncDisabled) {
+			status = http.StatusServiceUnavailable
+		}
+		if provisioned != nil {
+			response.Groups = provisioned.Groups
+			response.Result = newSyncStats(provisioned.SyncResult, duration)
+		}
+	case len(provisioned.Groups) == 0 && provisioned.UsersSkipped > 0:
+		response.Status = "skipped"
+		response.Message = "User is on the skip list"
+		status = http.StatusConflict
+	case len(provisioned.Groups) == 0 && len(provisioned.Errors) == 0:
+		response.Status = "not_found"
+		response.Message = "User is not a member of any synced group"
+		status = http.StatusNotFound
+	default:
+		response.Status = "success"
+		response.Message = "User provisioned"
+		if len(provisioned.Errors) > 0 {
+			response.Status = "partial"
+			response.Message = "User provisioned with errors"
+		}
+		response.Groups = provisioned.Groups
+		response.Result = newSyncStats(provisioned.SyncResult, duration)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode provision response", "error", err)
+	}
+}
+
+// handleUserMemberships explains which synced groups give a user access in Beyond Identity,
+// for answering why someone does or does not have access
+func (s *Server) handleUserMemberships(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(mux.Vars(r)["email"])
+	if !strings.Contains(email, "@") {
+		http.Error(w, "A user email is required", http.StatusBadRequest)
+		return
+	}
+
+	access, err := s.syncEngine.UserAccess(r.Context(), email)
+	if err != nil {
+		s.logger.Errorf("Failed to look up access for %s: %v", email, err)
+		http.Error(w, "Failed to look up user", http.StatusBadGateway)
+		return
+	}
+	if !access.Found() {
+		http.Error(w, "User not found in any synced group or target", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(access); err != nil {
+		s.logger.Error("Failed to encode memberships response", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
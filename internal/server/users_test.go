@@ -0,0 +1,189 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/audit"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/gorilla/mux"
+)
+
+func TestHandleProvisionUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		groups      []string
+		shouldError bool
+		wantStatus  int
+		wantResult  string
+	}{
+		{"provisioned", `{"email":"alice@example.com"}`, []string{"eng@example.com"}, false, http.StatusOK, "success"},
+		{"not in synced groups", `{"email":"alice@example.com"}`, nil, false, http.StatusNotFound, "not_found"},
+		{"sync error", `{"email":"alice@example.com"}`, nil, true, http.StatusInternalServerError, "error"},
+		{"missing email", `{}`, nil, false, http.StatusUnprocessableEntity, ""},
+		{"unknown field", `{"email":"alice@example.com","groups":["eng@example.com"]}`, nil, false, http.StatusUnprocessableEntity, ""},
+		{"invalid json", `{`, nil, false, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(t)
+			engine := server.syncEngine.(*mockSyncEngine)
+			engine.provisionGroups = tt.groups
+			engine.shouldError = tt.shouldError
+			router := mux.NewRouter()
+			server.registerRoutes(router)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest("POST", "/users/provision", strings.NewReader(tt.body)))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantResult == "" {
+				return
+			}
+
+			var response ProvisionUserResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Status != tt.wantResult {
+				t.Errorf("Expected status %s, got %s", tt.wantResult, response.Status)
+			}
+		})
+	}
+}
+
+func TestHandleDeprovisionUser(t *testing.T) {
+	server := createTestServer(t)
+	engine := server.syncEngine.(*mockSyncEngine)
+	server.audit = audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	deprovision := func(body string) (*httptest.ResponseRecorder, DeprovisionUserResponse) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", "/users/deprovision", strings.NewReader(body)))
+		var response DeprovisionUserResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	// The first request only returns the plan
+	rr, plan := deprovision(`{"email":"alice@example.com","deactivate":true}`)
+	if rr.Code != http.StatusOK || plan.Status != "confirmation_required" || plan.ConfirmationToken == "" {
+		t.Fatalf("Expected a confirmation token, got %d %+v", rr.Code, plan)
+	}
+	if len(engine.deprovisions) != 0 {
+		t.Fatal("Expected nothing to be deprovisioned before confirmation")
+	}
+
+	// The token is bound to the planned request
+	rr, _ = deprovision(`{"email":"alice@example.com","confirmation_token":"` + plan.ConfirmationToken + `"}`)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected a token for a different request to be rejected, got %d", rr.Code)
+	}
+
+	_, plan = deprovision(`{"email":"alice@example.com","deactivate":true}`)
+	body := `{"email":"alice@example.com","deactivate":true,"requested_by":"hr","confirmation_token":"` + plan.ConfirmationToken + `"}`
+	rr, response := deprovision(body)
+	if rr.Code != http.StatusOK || response.Status != "success" {
+		t.Fatalf("Expected success, got %d %+v", rr.Code, response)
+	}
+	if len(engine.deprovisions) != 1 {
+		t.Errorf("Expected one deprovisioning, got %v", engine.deprovisions)
+	}
+
+	// Tokens are single use
+	rr, _ = deprovision(body)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected a reused token to be rejected, got %d", rr.Code)
+	}
+
+	raw, err := os.ReadFile(server.audit.Path())
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	var record audit.Record
+	if err := json.Unmarshal(raw, &record); err != nil {
+		t.Fatalf("Expected a single audit record, got %q: %v", raw, err)
+	}
+	if record.Subject != "alice@example.com" || record.RequestedBy != "hr" || record.Outcome != "success" {
+		t.Errorf("Unexpected audit record: %+v", record)
+	}
+}
+
+func TestConfirmations_Expiry(t *testing.T) {
+	c := newConfirmations()
+	now := time.Now()
+	token, _ := c.issue("alice@example.com", false, now)
+	if c.consume(token, "alice@example.com", false, now.Add(confirmationTTL+time.Second)) {
+		t.Error("Expected an expired token to be rejected")
+	}
+}
+
+func TestHandleUserMemberships(t *testing.T) {
+	tests := []struct {
+		name        string
+		email       string
+		shouldError bool
+		wantStatus  int
+	}{
+		{"found", "alice@example.com", false, http.StatusOK},
+		{"unknown user", "nobody@example.com", false, http.StatusNotFound},
+		{"lookup error", "alice@example.com", true, http.StatusBadGateway},
+		{"not an email", "alice", false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(t)
+			server.syncEngine.(*mockSyncEngine).shouldError = tt.shouldError
+			router := mux.NewRouter()
+			server.registerRoutes(router)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest("GET", "/users/"+tt.email+"/memberships", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var access syncengine.UserAccess
+			if err := json.Unmarshal(rr.Body.Bytes(), &access); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if len(access.Groups) != 1 || !access.Groups[0].InBIGroup {
+				t.Errorf("Unexpected memberships: %+v", access.Groups)
+			}
+		})
+	}
+}
+
+func TestHandleDeprovisionUser_FeatureDisabled(t *testing.T) {
+	server := createTestServer(t)
+	server.config.Features = map[string]bool{config.FeatureDeprovisioning: false}
+	router := mux.NewRouter()
+	server.registerRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/users/deprovision", strings.NewReader(`{"email":"alice@example.com"}`)))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status code 403, got %d", rr.Code)
+	}
+	if engine := server.syncEngine.(*mockSyncEngine); len(engine.deprovisions) != 0 {
+		t.Error("Expected nothing to be deprovisioned")
+	}
+}
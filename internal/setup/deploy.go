@@ -0,0 +1,121 @@
+package setup
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+//go:embed templates/*.tmpl
+var deploymentTemplates embed.FS
+
+// DeploymentArtifact is a deployment file rendered from a template in templates/
+type DeploymentArtifact struct {
+	Name     string // File written to the output directory
+	Template string
+	Mode     string // The app.mode deployment the file runs: server or oneshot; hybrid deployments use both
+}
+
+// usedIn reports whether the file belongs to a deployment with app.mode mode
+func (a DeploymentArtifact) usedIn(mode string) bool {
+	return mode == config.DeploymentModeHybrid || a.Mode == mode
+}
+
+// DeploymentArtifacts are the deployment files written by the wizard and setup docs
+var DeploymentArtifacts = []DeploymentArtifact{
+	{Name: "scim-sync.service", Template: "templates/scim-sync.service.tmpl", Mode: config.DeploymentModeServer},
+	{Name: "docker-compose.yml", Template: "templates/docker-compose.yml.tmpl", Mode: config.DeploymentModeServer},
+	{Name: "crontab", Template: "templates/crontab.tmpl", Mode: config.DeploymentModeOneShot},
+}
+
+// Default deployment settings
+const (
+	DefaultBinaryPath  = "/usr/local/bin/scim-sync"
+	DefaultServiceUser = "scim-sync"
+)
+
+// DeploymentOptions are the values the deployment templates are populated with
+type DeploymentOptions struct {
+	ConfigPath string // Absolute path of the config file
+	ConfigDir  string // Directory of the config file, mounted into the container
+	ConfigName string // Base name of the config file
+	WorkDir    string // Relative paths in the config resolve from here; the config file's directory
+	BinaryPath string
+	User       string // Account the systemd unit runs as
+	Port       int
+	Schedule   string // Cron expression for one-shot runs
+	Mode       string // app.mode; only the files of this deployment mode are written
+}
+
+// NewDeploymentOptions derives the template values from the config file path and its settings;
+// cfg may be nil, in which case the defaults are used
+func NewDeploymentOptions(cfg *config.Config, configPath string) (DeploymentOptions, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return DeploymentOptions{}, fmt.Errorf("failed to resolve config path %s: %w", configPath, err)
+	}
+
+	defaults := &config.Config{}
+	if cfg != nil {
+		defaults.Server = cfg.Server
+		defaults.App.Mode = cfg.App.Mode
+	}
+	defaults.SetDefaults()
+
+	return DeploymentOptions{
+		ConfigPath: absPath,
+		ConfigDir:  filepath.Dir(absPath),
+		ConfigName: filepath.Base(absPath),
+		WorkDir:    filepath.Dir(absPath),
+		BinaryPath: DefaultBinaryPath,
+		User:       DefaultServiceUser,
+		Port:       defaults.Server.Port,
+		Schedule:   defaults.Server.Schedule,
+		Mode:       defaults.App.Mode,
+	}, nil
+}
+
+// RenderDeploymentArtifact renders one deployment file
+func RenderDeploymentArtifact(artifact DeploymentArtifact, opts DeploymentOptions) ([]byte, error) {
+	tmpl, err := template.ParseFS(deploymentTemplates, artifact.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template for %s: %w", artifact.Name, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, opts); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", artifact.Name, err)
+	}
+	return out.Bytes(), nil
+}
+
+// WriteDeploymentArtifacts writes the deployment files of opts.Mode to outputDir and returns their
+// paths; an empty mode writes every file
+func WriteDeploymentArtifacts(outputDir string, opts DeploymentOptions) ([]string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(DeploymentArtifacts))
+	for _, artifact := range DeploymentArtifacts {
+		if opts.Mode != "" && !artifact.usedIn(opts.Mode) {
+			continue
+		}
+		content, err := RenderDeploymentArtifact(artifact, opts)
+		if err != nil {
+			return paths, err
+		}
+
+		path := filepath.Join(outputDir, artifact.Name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,95 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func TestNewDeploymentOptions(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{Port: 9090, Schedule: "0 2 * * *"}}
+
+	opts, err := NewDeploymentOptions(cfg, "/etc/scim-sync/prod.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Port != 9090 || opts.Schedule != "0 2 * * *" {
+		t.Errorf("Expected the configured port and schedule, got %+v", opts)
+	}
+	if opts.ConfigDir != "/etc/scim-sync" || opts.ConfigName != "prod.yaml" || opts.WorkDir != "/etc/scim-sync" {
+		t.Errorf("Expected paths derived from the config file, got %+v", opts)
+	}
+
+	// Without a config the defaults are used and relative paths are made absolute
+	opts, err = NewDeploymentOptions(nil, "config.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Port != 8080 || opts.Schedule == "" || !filepath.IsAbs(opts.ConfigPath) {
+		t.Errorf("Expected defaults with an absolute config path, got %+v", opts)
+	}
+}
+
+func TestWriteDeploymentArtifacts(t *testing.T) {
+	opts, _ := NewDeploymentOptions(&config.Config{Server: config.ServerConfig{Port: 9090}}, "/etc/scim-sync/config.yaml")
+	dir := t.TempDir()
+
+	paths, err := WriteDeploymentArtifacts(dir, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(paths) != len(DeploymentArtifacts) {
+		t.Fatalf("Expected %d files, got %v", len(DeploymentArtifacts), paths)
+	}
+
+	expected := map[string][]string{
+		"scim-sync.service":  {`ExecStart=/usr/local/bin/scim-sync server --config "/etc/scim-sync/config.yaml"`, "WorkingDirectory=/etc/scim-sync"},
+		"docker-compose.yml": {`"9090:9090"`, `"/etc/scim-sync:/etc/scim-sync"`, "/etc/scim-sync/config.yaml", "localhost:9090/health"},
+		"crontab":            {`0 */6 * * * cd "/etc/scim-sync" && /usr/local/bin/scim-sync run --config "/etc/scim-sync/config.yaml"`},
+	}
+	for name, snippets := range expected {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		for _, snippet := range snippets {
+			if !strings.Contains(string(content), snippet) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", name, snippet, content)
+			}
+		}
+	}
+}
+
+func TestWriteDeploymentArtifacts_Mode(t *testing.T) {
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{config.DeploymentModeOneShot, []string{"crontab"}},
+		{config.DeploymentModeServer, []string{"scim-sync.service", "docker-compose.yml"}},
+		{config.DeploymentModeHybrid, []string{"scim-sync.service", "docker-compose.yml", "crontab"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			opts, _ := NewDeploymentOptions(&config.Config{App: config.AppConfig{Mode: tt.mode}}, "/etc/scim-sync/config.yaml")
+			dir := t.TempDir()
+
+			paths, err := WriteDeploymentArtifacts(dir, opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(paths) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, paths)
+			}
+			for i, name := range tt.want {
+				if paths[i] != filepath.Join(dir, name) {
+					t.Errorf("Expected %s, got %s", name, paths[i])
+				}
+			}
+		})
+	}
+}
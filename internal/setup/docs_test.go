@@ -0,0 +1,141 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func TestNewDocsData(t *testing.T) {
+	cfg := &config.Config{
+		Profile:         "prod",
+		GoogleWorkspace: config.GoogleWorkspaceConfig{Domain: "acme.com"},
+		BeyondIdentity:  config.BeyondIdentityConfig{GroupPrefix: "Acme_"},
+		Sync:            config.SyncConfig{Groups: []string{"eng@acme.com"}},
+		Server:          config.ServerConfig{Port: 9090},
+	}
+	cfg.SetDefaults()
+
+	data := NewDocsData(cfg, "/etc/scim-sync/config.yaml")
+	if !data.Configured || data.Domain != "acme.com" || data.GroupPrefix != "Acme_" {
+		t.Errorf("Expected the configured values, got %+v", data)
+	}
+	if data.Port != 9090 || data.AlternatePort != 9091 {
+		t.Errorf("Expected port 9090 and alternate 9091, got %d and %d", data.Port, data.AlternatePort)
+	}
+	if data.ConfigFlag != " --config /etc/scim-sync/config.yaml --profile prod" {
+		t.Errorf("Unexpected config flag %q", data.ConfigFlag)
+	}
+
+	// The default config file needs no flag, and without a config examples are used
+	if data := NewDocsData(cfg, "./config.yaml"); data.ConfigFlag != " --profile prod" {
+		t.Errorf("Unexpected config flag %q", data.ConfigFlag)
+	}
+	data = NewDocsData(nil, "./config.yaml")
+	if data.Configured || data.Domain != "yourcompany.com" || data.Port != 8080 || data.ConfigFlag != "" {
+		t.Errorf("Expected example values, got %+v", data)
+	}
+}
+
+func TestGenerateDocumentation(t *testing.T) {
+	cfg := &config.Config{
+		GoogleWorkspace: config.GoogleWorkspaceConfig{Domain: "acme.com"},
+		BeyondIdentity:  config.BeyondIdentityConfig{GroupPrefix: "Acme_"},
+		Sync:            config.SyncConfig{Groups: []string{"eng@acme.com"}},
+		Server:          config.ServerConfig{Port: 9090},
+	}
+	cfg.SetDefaults()
+	data := NewDocsData(cfg, "prod.yaml")
+
+	tests := []struct {
+		format    string
+		extension string
+		contains  []string
+	}{
+		{DocsFormatMarkdown, ".md", []string{"## Your Deployment", "`eng@acme.com`", "Acme_", "--config prod.yaml"}},
+		{DocsFormatHTML, ".html", []string{"<!DOCTYPE html>", "<h2>Your Deployment</h2>", "<code>eng@acme.com</code>", "localhost:9090"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := GenerateDocumentation(dir, data, tt.format); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var all strings.Builder
+			for _, guide := range docsGuides {
+				content, err := os.ReadFile(filepath.Join(dir, guide.name+tt.extension))
+				if err != nil {
+					t.Fatalf("Expected %s to be written: %v", guide.name, err)
+				}
+				all.Write(content)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(all.String(), want) {
+					t.Errorf("Expected the guides to contain %q", want)
+				}
+			}
+		})
+	}
+
+	if err := GenerateDocumentation(t.TempDir(), data, "pdf"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	page := markdownToHTML("# Title\n\nSome **bold** `<code>` and [a link](https://example.com).\n\n1. First\n   - Nested\n2. Second\n\n```bash\necho <hi>\n```\n")
+
+	for _, want := range []string{
+		"<title>Title</title>",
+		"<h1>Title</h1>",
+		"<strong>bold</strong> <code>&lt;code&gt;</code>",
+		`<a href="https://example.com">a link</a>`,
+		"<ol>\n<li>First<ul>\n<li>Nested</li></ul>\n</li>\n<li>Second</li></ol>",
+		`<pre><code class="language-bash">echo &lt;hi&gt;</code></pre>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected %q in:\n%s", want, page)
+		}
+	}
+}
+
+func TestMarkdownToHTML_Tables(t *testing.T) {
+	page := markdownToHTML("Intro\n| Endpoint | Count |\n|:---|---:|\n| `GET /a|b` | 1 |\n| x \\| y |\nafter\n")
+
+	for _, want := range []string{
+		"<p>Intro</p>",
+		"<thead>\n<tr><th>Endpoint</th><th style=\"text-align: right\">Count</th></tr>\n</thead>",
+		"<tr><td><code>GET /a|b</code></td><td style=\"text-align: right\">1</td></tr>",
+		"<tr><td>x | y</td><td style=\"text-align: right\"></td></tr>\n</tbody>\n</table>",
+		"<p>after</p>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected %q in:\n%s", want, page)
+		}
+	}
+}
+
+func TestAPIGuideHTMLRendersEndpointTable(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.SetDefaults()
+	guide, err := renderGuide("templates/docs/api.md.tmpl", NewDocsData(cfg, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	page := markdownToHTML(guide)
+	if !strings.Contains(page, "<table>") || !strings.Contains(page, "<th>Endpoint</th>") {
+		t.Fatalf("Expected the endpoint table to be rendered as a table:\n%s", page)
+	}
+	if !strings.Contains(page, "<td><code>POST /sync/group/{email}</code></td>") {
+		t.Errorf("Expected every endpoint in its own cell:\n%s", page)
+	}
+	if strings.Contains(page, "<p>|") {
+		t.Errorf("Expected no table rows left as paragraphs:\n%s", page)
+	}
+}
@@ -0,0 +1,265 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// e2eName identifies the canary group so leftovers are easy to recognize
+const e2eName = "scim-sync-e2e"
+
+// E2EGoogleClient is the subset of the Google Workspace client the end-to-end test drives
+type E2EGoogleClient interface {
+	CreateGroup(ctx context.Context, groupEmail, groupName, description string) (*gws.Group, error)
+	DeleteGroup(ctx context.Context, groupEmail string) error
+	AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error
+	RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error
+}
+
+// E2ETargetClient is the subset of the Beyond Identity client the end-to-end test checks and
+// cleans up with
+type E2ETargetClient interface {
+	FindUserByEmail(ctx context.Context, email string) (*bi.User, error)
+	GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error)
+	DeleteGroup(ctx context.Context, groupID string) error
+	DeleteUser(ctx context.Context, userID string) error
+}
+
+// E2ESyncer runs the sync under test; it is satisfied by the sync engine
+type E2ESyncer interface {
+	SyncGroups(ctx context.Context, groupEmails []string) (*syncengine.SyncResult, error)
+}
+
+// E2E runs a scripted scenario against sandbox tenants: it creates a canary Google group with
+// the e2e.members, syncs it, checks the Beyond Identity group and users, removes a member,
+// syncs again and checks the removal, then deletes everything it created
+type E2E struct {
+	google     E2EGoogleClient
+	target     E2ETargetClient
+	syncer     E2ESyncer
+	config     *config.Config
+	groupEmail string
+	groupName  string
+}
+
+// NewE2E creates an end-to-end test whose canary group is named after now; the group must be
+// added to sync.groups before the syncer is created, see GroupEmail
+func NewE2E(google E2EGoogleClient, target E2ETargetClient, cfg *config.Config, now time.Time) *E2E {
+	suffix := now.UTC().Format("20060102150405")
+	return &E2E{
+		google:     google,
+		target:     target,
+		config:     cfg,
+		groupEmail: fmt.Sprintf("%s-%s@%s", e2eName, suffix, cfg.GoogleWorkspace.Domain),
+		groupName:  fmt.Sprintf("%s-%s", e2eName, suffix),
+	}
+}
+
+// GroupEmail returns the address of the canary Google group
+func (t *E2E) GroupEmail() string {
+	return t.groupEmail
+}
+
+// SetSyncer sets the sync the scenario runs
+func (t *E2E) SetSyncer(syncer E2ESyncer) {
+	t.syncer = syncer
+}
+
+// Run executes the scenario; cleanup runs even when an earlier step fails
+func (t *E2E) Run(ctx context.Context) *ValidationSummary {
+	startTime := time.Now()
+
+	fmt.Println("🧪 Running End-to-End Test")
+	fmt.Println("══════════════════════════")
+	fmt.Println()
+
+	summary := &ValidationSummary{
+		Results: make([]*ValidationResult, 0),
+	}
+
+	members := t.config.E2E.Members
+	var groupCreated bool
+	var biGroupID string
+	createdUsers := make(map[string]string) // Beyond Identity user ID -> email, of users the runs created
+
+	defer func() {
+		t.cleanup(ctx, summary, groupCreated, biGroupID, createdUsers)
+		finishSummary(summary, startTime, "📊 End-to-End Test Summary")
+	}()
+
+	if !runStep(summary, "Create canary Google group", func() (string, error) {
+		if _, err := t.google.CreateGroup(ctx, t.groupEmail, t.groupName, "Created by scim-sync e2e; safe to delete"); err != nil {
+			return "", err
+		}
+		groupCreated = true
+		return fmt.Sprintf("Created %s", t.groupEmail), nil
+	}) {
+		return summary
+	}
+
+	if !runStep(summary, "Add canary members", func() (string, error) {
+		for _, member := range members {
+			if err := t.google.AddMemberToGroup(ctx, t.groupEmail, member); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("Added %s", strings.Join(members, ", ")), nil
+	}) {
+		return summary
+	}
+
+	if !runStep(summary, "Sync canary group", func() (string, error) {
+		result, err := t.sync(ctx, createdUsers)
+		if err != nil {
+			return "", err
+		}
+		for _, change := range result.Changes {
+			if change.Entity == report.EntityGroup && change.Action == state.ChangeGroupCreated {
+				biGroupID = change.ID
+			}
+		}
+		if biGroupID == "" {
+			return "", errors.New("the sync did not create a Beyond Identity group")
+		}
+		return fmt.Sprintf("Created group %s, %d users and %d memberships", biGroupID, result.UsersCreated, result.MembershipsAdded), nil
+	}) {
+		return summary
+	}
+
+	if !runStep(summary, "Check Beyond Identity members", func() (string, error) {
+		return t.checkMembers(ctx, biGroupID, members, nil)
+	}) {
+		return summary
+	}
+
+	removed := members[len(members)-1]
+	kept := members[:len(members)-1]
+	if !runStep(summary, "Remove canary member", func() (string, error) {
+		if err := t.google.RemoveMemberFromGroup(ctx, t.groupEmail, removed); err != nil {
+			return "", err
+		}
+		result, err := t.sync(ctx, createdUsers)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Removed %s and synced (%d memberships removed)", removed, result.MembershipsRemoved), nil
+	}) {
+		return summary
+	}
+
+	runStep(summary, "Check member removal", func() (string, error) {
+		return t.checkMembers(ctx, biGroupID, kept, []string{removed})
+	})
+
+	return summary
+}
+
+// sync runs the sync under test for the canary group, failing on any sync error, and records the
+// users it created
+func (t *E2E) sync(ctx context.Context, createdUsers map[string]string) (*syncengine.SyncResult, error) {
+	result, err := t.syncer.SyncGroups(ctx, []string{t.groupEmail})
+	if result != nil {
+		for _, change := range result.Changes {
+			if change.Action == state.ChangeUserCreated && !change.Planned && change.Error == "" && change.ID != "" {
+				createdUsers[change.ID] = change.User
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.DryRun || result.ReadOnly {
+		return nil, errors.New("the sync only reported its changes; disable test and read-only mode")
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("the sync failed with %d errors, e.g. %v", len(result.Errors), result.Errors[0])
+	}
+	return result, nil
+}
+
+// checkMembers verifies that the Beyond Identity group has the users of want and none of those of
+// unwanted
+func (t *E2E) checkMembers(ctx context.Context, groupID string, want, unwanted []string) (string, error) {
+	group, err := t.target.GetGroupWithMembers(ctx, groupID)
+	if err != nil {
+		return "", err
+	}
+	memberIDs := make(map[string]bool, len(group.Members))
+	for _, member := range group.Members {
+		memberIDs[member.Value] = true
+	}
+
+	for _, email := range want {
+		user, err := t.target.FindUserByEmail(ctx, email)
+		if err != nil {
+			return "", err
+		}
+		if user == nil {
+			return "", fmt.Errorf("user %s was not provisioned", email)
+		}
+		if !memberIDs[user.ID] {
+			return "", fmt.Errorf("user %s is not a member of %s", email, group.DisplayName)
+		}
+	}
+	for _, email := range unwanted {
+		user, err := t.target.FindUserByEmail(ctx, email)
+		if err != nil {
+			return "", err
+		}
+		if user != nil && memberIDs[user.ID] {
+			return "", fmt.Errorf("user %s is still a member of %s", email, group.DisplayName)
+		}
+	}
+	return fmt.Sprintf("%s has the %d expected members", group.DisplayName, len(want)), nil
+}
+
+// cleanup deletes whatever the scenario created, even when ctx was cancelled mid-test. Users that
+// existed before the test are left as they are
+func (t *E2E) cleanup(ctx context.Context, summary *ValidationSummary, groupCreated bool, biGroupID string, createdUsers map[string]string) {
+	ctx = context.WithoutCancel(ctx)
+
+	if biGroupID != "" {
+		runStep(summary, "Delete canary Beyond Identity group", func() (string, error) {
+			if err := t.target.DeleteGroup(ctx, biGroupID); err != nil {
+				return "", fmt.Errorf("%w (remove group %s manually)", err, biGroupID)
+			}
+			return fmt.Sprintf("Deleted %s", biGroupID), nil
+		})
+	}
+
+	if len(createdUsers) > 0 {
+		runStep(summary, "Delete canary Beyond Identity users", func() (string, error) {
+			var failed []string
+			for id, email := range createdUsers {
+				if err := t.target.DeleteUser(ctx, id); err != nil {
+					failed = append(failed, email)
+				}
+			}
+			if len(failed) > 0 {
+				sort.Strings(failed)
+				return "", fmt.Errorf("failed to delete %s (remove them manually)", strings.Join(failed, ", "))
+			}
+			return fmt.Sprintf("Deleted %d users the sync created", len(createdUsers)), nil
+		})
+	}
+
+	if groupCreated {
+		runStep(summary, "Delete canary Google group", func() (string, error) {
+			if err := t.google.DeleteGroup(ctx, t.groupEmail); err != nil {
+				return "", fmt.Errorf("%w (remove group %s manually)", err, t.groupEmail)
+			}
+			return fmt.Sprintf("Deleted %s", t.groupEmail), nil
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package setup
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// fakeE2EGoogle keeps Google groups and their members in memory
+type fakeE2EGoogle struct {
+	groups map[string][]string
+}
+
+func (f *fakeE2EGoogle) CreateGroup(ctx context.Context, groupEmail, groupName, description string) (*gws.Group, error) {
+	f.groups[groupEmail] = nil
+	return &gws.Group{Email: groupEmail, Name: groupName}, nil
+}
+
+func (f *fakeE2EGoogle) DeleteGroup(ctx context.Context, groupEmail string) error {
+	delete(f.groups, groupEmail)
+	return nil
+}
+
+func (f *fakeE2EGoogle) AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error {
+	f.groups[groupEmail] = append(f.groups[groupEmail], userEmail)
+	return nil
+}
+
+func (f *fakeE2EGoogle) RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error {
+	members := f.groups[groupEmail][:0]
+	for _, member := range f.groups[groupEmail] {
+		if member != userEmail {
+			members = append(members, member)
+		}
+	}
+	f.groups[groupEmail] = members
+	return nil
+}
+
+// fakeE2ESyncer mirrors the Google group into the fake Beyond Identity tenant
+type fakeE2ESyncer struct {
+	google *fakeE2EGoogle
+	target *fakeSelfTestClient
+	err    error
+}
+
+func (f *fakeE2ESyncer) SyncGroups(ctx context.Context, groupEmails []string) (*syncengine.SyncResult, error) {
+	result := &syncengine.SyncResult{}
+	if f.err != nil {
+		result.Errors = []error{f.err}
+		return result, nil
+	}
+
+	group, _ := f.target.FindGroupByDisplayName(ctx, "GWS_canary")
+	if group == nil {
+		group, _ = f.target.CreateGroup(ctx, &bi.Group{DisplayName: "GWS_canary"})
+		result.Changes = append(result.Changes, report.ChangeRecord{Action: state.ChangeGroupCreated, Entity: report.EntityGroup, ID: group.ID})
+	}
+	group.Members = nil
+	for _, email := range f.google.groups[groupEmails[0]] {
+		user, _ := f.target.FindUserByEmail(ctx, email)
+		if user == nil {
+			user, _ = f.target.CreateUser(ctx, &bi.User{UserName: email})
+			result.Changes = append(result.Changes, report.ChangeRecord{Action: state.ChangeUserCreated, Entity: report.EntityUser, User: email, ID: user.ID})
+		}
+		group.Members = append(group.Members, bi.GroupMember{Value: user.ID})
+	}
+	return result, nil
+}
+
+func TestE2ERun(t *testing.T) {
+	tests := []struct {
+		name         string
+		syncErr      error
+		expectStatus string
+		expectFailed int
+	}{
+		{
+			name:         "scenario passes",
+			expectStatus: "PASS",
+		},
+		{
+			name:         "sync errors fail the scenario and still clean up",
+			syncErr:      errors.New("target rejected the group"),
+			expectStatus: "FAIL",
+			expectFailed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			google := &fakeE2EGoogle{groups: map[string][]string{}}
+			target := newFakeSelfTestClient()
+			existing, _ := target.CreateUser(context.Background(), &bi.User{UserName: "existing@test.com"})
+
+			cfg := &config.Config{
+				GoogleWorkspace: config.GoogleWorkspaceConfig{Domain: "test.com"},
+				E2E:             config.E2EConfig{Members: []string{"existing@test.com", "canary@test.com"}},
+			}
+			e2e := NewE2E(google, target, cfg, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+			if e2e.GroupEmail() != "scim-sync-e2e-20240102030405@test.com" {
+				t.Errorf("Unexpected canary group %s", e2e.GroupEmail())
+			}
+			e2e.SetSyncer(&fakeE2ESyncer{google: google, target: target, err: tt.syncErr})
+
+			summary := e2e.Run(context.Background())
+
+			if summary.OverallStatus != tt.expectStatus || summary.Failed != tt.expectFailed {
+				t.Errorf("Expected status %s with %d failed steps, got %s with %d: %+v", tt.expectStatus, tt.expectFailed, summary.OverallStatus, summary.Failed, summary.Results)
+			}
+			if len(google.groups) != 0 || len(target.groups) != 0 {
+				t.Errorf("Expected the canary groups to be deleted, got %v and %v", google.groups, target.groups)
+			}
+			if len(target.users) != 1 || target.users[existing.ID] == nil {
+				t.Errorf("Expected only the users the sync created to be deleted, got %v", target.users)
+			}
+		})
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	summary := &ValidationSummary{
+		Failed:   1,
+		Duration: 1500 * time.Millisecond,
+		Results: []*ValidationResult{
+			{Component: "Sync canary group", Status: "PASS", Message: "Operation succeeded", Details: "Created group g-1", Duration: time.Second},
+			{Component: "Check member removal", Status: "FAIL", Message: "Operation failed", Details: "user x is still a member", Duration: 500 * time.Millisecond},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "scim-sync-e2e", summary, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("Expected an XML header, got %q", buf.String())
+	}
+
+	var suite junitSuite
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatalf("Invalid JUnit XML: %v", err)
+	}
+	if suite.Name != "scim-sync-e2e" || suite.Tests != 2 || suite.Failures != 1 || suite.Time != "1.500" || suite.Timestamp != "2024-01-02T03:04:03" {
+		t.Errorf("Unexpected suite attributes: %+v", suite)
+	}
+	if suite.Cases[0].Failure != nil || suite.Cases[1].Failure == nil || suite.Cases[1].Failure.Details != "user x is still a member" {
+		t.Errorf("Expected only the second case to fail, got %+v", suite.Cases)
+	}
+}
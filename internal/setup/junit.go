@@ -0,0 +1,75 @@
+package setup
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// junitSuite is a JUnit XML test suite, the report format CI systems read
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Output    string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+// WriteJUnit writes the steps of a test against the tenants as a JUnit XML suite named name,
+// each step a test case, so CI systems can show and gate on them
+func WriteJUnit(w io.Writer, name string, summary *ValidationSummary, finished time.Time) error {
+	suite := junitSuite{
+		Name:      name,
+		Tests:     len(summary.Results),
+		Failures:  summary.Failed,
+		Time:      junitSeconds(summary.Duration),
+		Timestamp: finished.Add(-summary.Duration).UTC().Format("2006-01-02T15:04:05"),
+	}
+	for _, result := range summary.Results {
+		testCase := junitCase{
+			Name:      result.Component,
+			ClassName: name,
+			Time:      junitSeconds(result.Duration),
+		}
+		if result.Status == "PASS" {
+			testCase.Output = result.Details
+		} else {
+			testCase.Failure = &junitFailure{Message: result.Message, Details: result.Details}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// junitSeconds formats a duration as JUnit's fractional seconds
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,228 @@
+package setup
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// The markdown subset used by the documentation templates
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	listItemPattern  = regexp.MustCompile(`^(\s*)(-|(\d+)\.)\s+(.*)$`)
+	codeSpanPattern  = regexp.MustCompile("`([^`]+)`")
+	boldPattern      = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	linkPattern      = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	tableRulePattern = regexp.MustCompile(`^\|?(\s*:?-+:?\s*\|)*\s*:?-+:?\s*\|?$`)
+)
+
+// openList is a list being rendered, nested by indentation
+type openList struct {
+	indent int
+	tag    string
+}
+
+// markdownToHTML renders the documentation markdown (headings, fenced code, nested lists,
+// paragraphs, tables, inline code, bold and links) as a standalone HTML page
+func markdownToHTML(markdown string) string {
+	var body strings.Builder
+	var lists []openList
+	var paragraph []string
+	title := ""
+
+	closeLists := func(indent int) {
+		for len(lists) > 0 && lists[len(lists)-1].indent > indent {
+			fmt.Fprintf(&body, "</li></%s>\n", lists[len(lists)-1].tag)
+			lists = lists[:len(lists)-1]
+		}
+	}
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			fmt.Fprintf(&body, "<p>%s</p>\n", renderInline(strings.Join(paragraph, " ")))
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(markdown, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), "```"):
+			flushParagraph()
+			closeLists(-1)
+			language := strings.TrimPrefix(strings.TrimSpace(line), "```")
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			class := ""
+			if language != "" {
+				class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(language))
+			}
+			fmt.Fprintf(&body, "<pre><code%s>%s</code></pre>\n", class, html.EscapeString(strings.Join(code, "\n")))
+
+		case isTableRow(line) && i+1 < len(lines) && tableRulePattern.MatchString(strings.TrimSpace(lines[i+1])):
+			flushParagraph()
+			closeLists(-1)
+			header := splitTableRow(line)
+			aligns := tableAlignments(lines[i+1])
+			body.WriteString("<table>\n<thead>\n")
+			writeTableRow(&body, "th", header, aligns)
+			body.WriteString("</thead>\n<tbody>\n")
+			for i += 2; i < len(lines) && isTableRow(lines[i]); i++ {
+				writeTableRow(&body, "td", splitTableRow(lines[i]), aligns)
+			}
+			i--
+			body.WriteString("</tbody>\n</table>\n")
+
+		case line == "":
+			flushParagraph()
+			closeLists(-1)
+
+		case headingPattern.MatchString(line):
+			flushParagraph()
+			closeLists(-1)
+			match := headingPattern.FindStringSubmatch(line)
+			if title == "" {
+				title = match[2]
+			}
+			fmt.Fprintf(&body, "<h%d>%s</h%d>\n", len(match[1]), renderInline(match[2]), len(match[1]))
+
+		case listItemPattern.MatchString(line):
+			flushParagraph()
+			match := listItemPattern.FindStringSubmatch(line)
+			indent, tag, start := len(match[1]), "ul", ""
+			if match[3] != "" {
+				tag = "ol"
+				if match[3] != "1" {
+					start = fmt.Sprintf(` start="%s"`, match[3])
+				}
+			}
+
+			closeLists(indent)
+			if len(lists) > 0 && lists[len(lists)-1].indent == indent && lists[len(lists)-1].tag != tag {
+				closeLists(indent - 1)
+			}
+			if len(lists) > 0 && lists[len(lists)-1].indent == indent {
+				body.WriteString("</li>\n")
+			} else {
+				fmt.Fprintf(&body, "<%s%s>\n", tag, start)
+				lists = append(lists, openList{indent: indent, tag: tag})
+			}
+			fmt.Fprintf(&body, "<li>%s", renderInline(match[4]))
+
+		default:
+			closeLists(-1)
+			paragraph = append(paragraph, strings.TrimSpace(line))
+		}
+	}
+	flushParagraph()
+	closeLists(-1)
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+pre { background: #f6f8fa; padding: 1rem; overflow-x: auto; }
+code { font-family: SFMono-Regular, Consolas, monospace; }
+table { border-collapse: collapse; margin: 1rem 0; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+%s</body>
+</html>
+`, html.EscapeString(title), body.String())
+}
+
+// isTableRow reports whether line is a row of a pipe table
+func isTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+// splitTableRow returns the cells of a table row, leaving pipes inside code spans or escaped
+// with a backslash in their cell
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '`':
+			inCode = !inCode
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|' && !inCode:
+			cell.WriteByte('|')
+			i++
+			continue
+		case line[i] == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(line[i])
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// tableAlignments returns the text-align of each column from a table's delimiter row
+func tableAlignments(rule string) []string {
+	var aligns []string
+	for _, cell := range splitTableRow(rule) {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns = append(aligns, "center")
+		case strings.HasSuffix(cell, ":"):
+			aligns = append(aligns, "right")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+	return aligns
+}
+
+// writeTableRow writes one row of cells, padding or cutting it to the header's columns
+func writeTableRow(body *strings.Builder, tag string, cells, aligns []string) {
+	body.WriteString("<tr>")
+	for i, align := range aligns {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		style := ""
+		if align != "" {
+			style = fmt.Sprintf(` style="text-align: %s"`, align)
+		}
+		fmt.Fprintf(body, "<%s%s>%s</%s>", tag, style, renderInline(cell), tag)
+	}
+	body.WriteString("</tr>\n")
+}
+
+// renderInline escapes text and renders inline code, bold and links
+func renderInline(text string) string {
+	// Code spans are rendered first and kept out of the other patterns
+	var spans []string
+	text = codeSpanPattern.ReplaceAllStringFunc(text, func(span string) string {
+		spans = append(spans, "<code>"+html.EscapeString(strings.Trim(span, "`"))+"</code>")
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	})
+
+	text = html.EscapeString(text)
+	text = boldPattern.ReplaceAllString(text, "<strong>$1</strong>")
+	text = linkPattern.ReplaceAllString(text, `<a href="$2">$1</a>`)
+
+	for i, span := range spans {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), span, 1)
+	}
+	return text
+}
@@ -0,0 +1,269 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// selfTestName identifies the canary user and group so leftovers are easy to recognize
+const selfTestName = "scim-sync-selftest"
+
+// SelfTestClient is the subset of the Beyond Identity client exercised by the self-test
+type SelfTestClient interface {
+	CreateUser(ctx context.Context, user *bi.User) (*bi.User, error)
+	FindUserByEmail(ctx context.Context, email string) (*bi.User, error)
+	DeleteUser(ctx context.Context, userID string) error
+	CreateGroup(ctx context.Context, group *bi.Group) (*bi.Group, error)
+	FindGroupByDisplayName(ctx context.Context, displayName string) (*bi.Group, error)
+	GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error)
+	UpdateGroupMembers(ctx context.Context, groupID string, addMembers, removeMembers []bi.GroupMember) error
+	DeleteGroup(ctx context.Context, groupID string) error
+}
+
+// SelfTest provisions a canary user and group in Beyond Identity, exercises the SCIM
+// operations the sync relies on, and removes them again
+type SelfTest struct {
+	client SelfTestClient
+	config *config.Config
+	now    func() time.Time
+}
+
+// NewSelfTest creates a self-test against the given Beyond Identity client
+func NewSelfTest(client SelfTestClient, cfg *config.Config) *SelfTest {
+	return &SelfTest{
+		client: client,
+		config: cfg,
+		now:    time.Now,
+	}
+}
+
+// Run executes the self-test; cleanup runs even when an earlier step fails
+func (s *SelfTest) Run(ctx context.Context) *ValidationSummary {
+	startTime := time.Now()
+
+	fmt.Println("🧪 Running Beyond Identity Self-Test")
+	fmt.Println("═══════════════════════════════════")
+	fmt.Println()
+
+	summary := &ValidationSummary{
+		Results: make([]*ValidationResult, 0),
+	}
+
+	suffix := s.now().UTC().Format("20060102150405")
+	domain := s.config.GoogleWorkspace.Domain
+	if domain == "" {
+		domain = "example.com"
+	}
+	email := fmt.Sprintf("%s-%s@%s", selfTestName, suffix, domain)
+	groupName := fmt.Sprintf("%s%s-%s", s.config.BeyondIdentity.GroupPrefix, selfTestName, suffix)
+
+	var user *bi.User
+	var group *bi.Group
+
+	defer func() {
+		s.cleanup(ctx, summary, user, group, email)
+		s.finish(summary, startTime)
+	}()
+
+	if !s.step(summary, "Create canary user", func() (string, error) {
+		var err error
+		user, err = s.client.CreateUser(ctx, &bi.User{
+			ExternalID:  email,
+			UserName:    email,
+			DisplayName: "SCIM Sync Self-Test",
+			Emails:      []bi.Email{{Value: email, Type: "work", Primary: true}},
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Created %s (ID: %s)", email, user.ID), nil
+	}) {
+		return summary
+	}
+
+	if !s.step(summary, "Search canary user", func() (string, error) {
+		found, err := s.client.FindUserByEmail(ctx, email)
+		if err != nil {
+			return "", err
+		}
+		if found == nil || found.ID != user.ID {
+			return "", fmt.Errorf("user %s not returned by search", email)
+		}
+		return "Found user by email filter", nil
+	}) {
+		return summary
+	}
+
+	if !s.step(summary, "Create canary group", func() (string, error) {
+		var err error
+		group, err = s.client.CreateGroup(ctx, &bi.Group{DisplayName: groupName})
+		if err != nil {
+			return "", err
+		}
+		found, err := s.client.FindGroupByDisplayName(ctx, groupName)
+		if err != nil {
+			return "", err
+		}
+		if found == nil || found.ID != group.ID {
+			return "", fmt.Errorf("group %s not returned by search", groupName)
+		}
+		return fmt.Sprintf("Created and found %s (ID: %s)", groupName, group.ID), nil
+	}) {
+		return summary
+	}
+
+	member := bi.GroupMember{Value: user.ID}
+	if !s.step(summary, "Add group member", func() (string, error) {
+		if err := s.client.UpdateGroupMembers(ctx, group.ID, []bi.GroupMember{member}, nil); err != nil {
+			return "", err
+		}
+		return s.checkMembership(ctx, group.ID, user.ID, true)
+	}) {
+		return summary
+	}
+
+	s.step(summary, "Remove group member", func() (string, error) {
+		if err := s.client.UpdateGroupMembers(ctx, group.ID, nil, []bi.GroupMember{member}); err != nil {
+			return "", err
+		}
+		return s.checkMembership(ctx, group.ID, user.ID, false)
+	})
+
+	return summary
+}
+
+// checkMembership verifies that a PATCH took effect
+func (s *SelfTest) checkMembership(ctx context.Context, groupID, userID string, want bool) (string, error) {
+	group, err := s.client.GetGroupWithMembers(ctx, groupID)
+	if err != nil {
+		return "", err
+	}
+
+	found := false
+	for _, member := range group.Members {
+		if member.Value == userID {
+			found = true
+			break
+		}
+	}
+
+	switch {
+	case found && !want:
+		return "", errors.New("user is still a member after the remove operation")
+	case !found && want:
+		return "", errors.New("user is not a member after the add operation")
+	case want:
+		return "PATCH add reflected in group members", nil
+	default:
+		return "PATCH remove reflected in group members", nil
+	}
+}
+
+// cleanup deletes whatever canary objects were created, even when ctx was cancelled mid-test
+func (s *SelfTest) cleanup(ctx context.Context, summary *ValidationSummary, user *bi.User, group *bi.Group, email string) {
+	ctx = context.WithoutCancel(ctx)
+
+	if group != nil {
+		s.step(summary, "Delete canary group", func() (string, error) {
+			if err := s.client.DeleteGroup(ctx, group.ID); err != nil {
+				return "", fmt.Errorf("%w (remove group %s manually)", err, group.DisplayName)
+			}
+			return fmt.Sprintf("Deleted %s", group.DisplayName), nil
+		})
+	}
+
+	if user != nil {
+		s.step(summary, "Delete canary user", func() (string, error) {
+			if err := s.client.DeleteUser(ctx, user.ID); err != nil {
+				return "", fmt.Errorf("%w (remove user %s manually)", err, email)
+			}
+			found, err := s.client.FindUserByEmail(ctx, email)
+			if err != nil {
+				return "", err
+			}
+			if found != nil {
+				return "", fmt.Errorf("user %s still returned by search after delete", email)
+			}
+			return fmt.Sprintf("Deleted %s", email), nil
+		})
+	}
+}
+
+// step runs one self-test operation and records its result
+func (s *SelfTest) step(summary *ValidationSummary, component string, fn func() (string, error)) bool {
+	return runStep(summary, component, fn)
+}
+
+// runStep runs one operation of a test against the tenants, reporting whether it passed
+func runStep(summary *ValidationSummary, component string, fn func() (string, error)) bool {
+	fmt.Printf("🔸 %s... ", component)
+	start := time.Now()
+
+	details, err := fn()
+	if err != nil {
+		fmt.Println("❌ FAIL")
+		summary.Results = append(summary.Results, &ValidationResult{
+			Component: component,
+			Status:    "FAIL",
+			Message:   "Operation failed",
+			Details:   err.Error(),
+			Duration:  time.Since(start),
+		})
+		return false
+	}
+
+	fmt.Println("✅ PASS")
+	summary.Results = append(summary.Results, &ValidationResult{
+		Component: component,
+		Status:    "PASS",
+		Message:   "Operation succeeded",
+		Details:   details,
+		Duration:  time.Since(start),
+	})
+	return true
+}
+
+// finish tallies the results and prints the summary
+func (s *SelfTest) finish(summary *ValidationSummary, startTime time.Time) {
+	finishSummary(summary, startTime, "📊 Self-Test Summary")
+}
+
+// finishSummary tallies the results of a test against the tenants and prints them under title
+func finishSummary(summary *ValidationSummary, startTime time.Time, title string) {
+	summary.Duration = time.Since(startTime)
+	summary.TotalChecks = len(summary.Results)
+
+	for _, result := range summary.Results {
+		if result.Status == "PASS" {
+			summary.Passed++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	if summary.Failed == 0 {
+		summary.OverallStatus = "PASS"
+	} else {
+		summary.OverallStatus = "FAIL"
+	}
+
+	fmt.Println()
+	fmt.Println(title)
+	fmt.Println(strings.Repeat("═", utf8.RuneCountInString(title)))
+	fmt.Printf("📈 Results: %d passed, %d failed (total: %d)\n",
+		summary.Passed, summary.Failed, summary.TotalChecks)
+	fmt.Printf("⏱️  Duration: %v\n", summary.Duration.Round(time.Millisecond))
+
+	for _, result := range summary.Results {
+		if result.Status == "FAIL" {
+			fmt.Printf("   • %s: %s\n", result.Component, result.Details)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// fakeSelfTestClient keeps users and groups in memory
+type fakeSelfTestClient struct {
+	users     map[string]*bi.User
+	groups    map[string]*bi.Group
+	nextID    int
+	patchErr  error
+	deleteErr error
+}
+
+func newFakeSelfTestClient() *fakeSelfTestClient {
+	return &fakeSelfTestClient{users: map[string]*bi.User{}, groups: map[string]*bi.Group{}}
+}
+
+func (f *fakeSelfTestClient) id() string {
+	f.nextID++
+	return fmt.Sprintf("id-%d", f.nextID)
+}
+
+func (f *fakeSelfTestClient) CreateUser(ctx context.Context, user *bi.User) (*bi.User, error) {
+	user.ID = f.id()
+	f.users[user.ID] = user
+	return user, nil
+}
+
+func (f *fakeSelfTestClient) FindUserByEmail(ctx context.Context, email string) (*bi.User, error) {
+	for _, user := range f.users {
+		if user.UserName == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeSelfTestClient) DeleteUser(ctx context.Context, userID string) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	delete(f.users, userID)
+	return nil
+}
+
+func (f *fakeSelfTestClient) CreateGroup(ctx context.Context, group *bi.Group) (*bi.Group, error) {
+	group.ID = f.id()
+	f.groups[group.ID] = group
+	return group, nil
+}
+
+func (f *fakeSelfTestClient) FindGroupByDisplayName(ctx context.Context, displayName string) (*bi.Group, error) {
+	for _, group := range f.groups {
+		if group.DisplayName == displayName {
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeSelfTestClient) GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error) {
+	group, ok := f.groups[groupID]
+	if !ok {
+		return nil, errors.New("group not found")
+	}
+	return group, nil
+}
+
+func (f *fakeSelfTestClient) UpdateGroupMembers(ctx context.Context, groupID string, addMembers, removeMembers []bi.GroupMember) error {
+	if f.patchErr != nil {
+		return f.patchErr
+	}
+	group := f.groups[groupID]
+	for _, remove := range removeMembers {
+		for i, member := range group.Members {
+			if member.Value == remove.Value {
+				group.Members = append(group.Members[:i], group.Members[i+1:]...)
+				break
+			}
+		}
+	}
+	group.Members = append(group.Members, addMembers...)
+	return nil
+}
+
+func (f *fakeSelfTestClient) DeleteGroup(ctx context.Context, groupID string) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	delete(f.groups, groupID)
+	return nil
+}
+
+func TestSelfTestRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		patchErr     error
+		deleteErr    error
+		expectStatus string
+		expectFailed int
+		expectLeft   int
+	}{
+		{
+			name:         "all operations succeed",
+			expectStatus: "PASS",
+		},
+		{
+			name:         "patch failure still cleans up",
+			patchErr:     errors.New("patch rejected"),
+			expectStatus: "FAIL",
+			expectFailed: 1,
+		},
+		{
+			name:         "delete failure is reported",
+			deleteErr:    errors.New("delete rejected"),
+			expectStatus: "FAIL",
+			expectFailed: 2,
+			expectLeft:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeSelfTestClient()
+			client.patchErr = tt.patchErr
+			client.deleteErr = tt.deleteErr
+
+			cfg := &config.Config{
+				GoogleWorkspace: config.GoogleWorkspaceConfig{Domain: "test.com"},
+				BeyondIdentity:  config.BeyondIdentityConfig{GroupPrefix: "GWS_"},
+			}
+			selfTest := NewSelfTest(client, cfg)
+			selfTest.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+			summary := selfTest.Run(context.Background())
+
+			if summary.OverallStatus != tt.expectStatus {
+				t.Errorf("Expected status %s, got %s", tt.expectStatus, summary.OverallStatus)
+			}
+			if summary.Failed != tt.expectFailed {
+				t.Errorf("Expected %d failed steps, got %d", tt.expectFailed, summary.Failed)
+			}
+			if left := len(client.users) + len(client.groups); left != tt.expectLeft {
+				t.Errorf("Expected %d canary objects left, got %d", tt.expectLeft, left)
+			}
+		})
+	}
+}
@@ -1,13 +1,22 @@
 package setup
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
 	"github.com/sirupsen/logrus"
 )
 
@@ -48,7 +57,7 @@ func NewValidator(cfg *config.Config) *Validator {
 }
 
 // ValidateSetup performs comprehensive setup validation
-func (v *Validator) ValidateSetup() (*ValidationSummary, error) {
+func (v *Validator) ValidateSetup(ctx context.Context) (*ValidationSummary, error) {
 	startTime := time.Now()
 
 	fmt.Println("🔍 Validating Go SCIM Sync Setup")
@@ -65,11 +74,21 @@ func (v *Validator) ValidateSetup() (*ValidationSummary, error) {
 	// Environment validation
 	v.addResult(summary, v.validateEnvironment())
 
+	// Secret files readable by other users
+	v.addResult(summary, v.validateFilePermissions())
+
 	// Google Workspace connectivity
-	v.addResult(summary, v.validateGoogleWorkspace())
+	v.addResult(summary, v.validateGoogleWorkspace(ctx))
+
+	// Beyond Identity connectivity and token permissions, unless another SCIM service is the
+	// primary target
+	if v.config.PrimaryIsBeyondIdentity() {
+		v.addResult(summary, v.validateBeyondIdentity())
+		v.addResult(summary, v.validateCapabilities(ctx))
+	}
 
-	// Beyond Identity connectivity
-	v.addResult(summary, v.validateBeyondIdentity())
+	// Endpoint certificates against the configured CA bundle
+	v.addResult(summary, v.validateTLS())
 
 	// Group existence check
 	v.addResult(summary, v.validateGroups())
@@ -160,12 +179,69 @@ func (v *Validator) validateEnvironment() *ValidationResult {
 	}
 }
 
+// validateFilePermissions checks that the config file and service account keys are readable by
+// their owner only; open files fail validation with app.strict_permissions and are warned about otherwise
+func (v *Validator) validateFilePermissions() *ValidationResult {
+	fmt.Print("🔒 File permissions... ")
+	start := time.Now()
+
+	issues, err := v.config.CheckPermissions()
+	if err != nil {
+		fmt.Println("❌ FAIL")
+		return &ValidationResult{
+			Component: "File Permissions",
+			Status:    "FAIL",
+			Message:   "Failed to check file permissions",
+			Details:   err.Error(),
+			Duration:  time.Since(start),
+		}
+	}
+
+	if len(issues) > 0 {
+		details := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			details = append(details, issue.String())
+		}
+
+		if v.config.App.StrictPermissions {
+			fmt.Println("❌ FAIL")
+			return &ValidationResult{
+				Component: "File Permissions",
+				Status:    "FAIL",
+				Message:   "Secret files are accessible by other users and app.strict_permissions is enabled",
+				Details:   strings.Join(details, "; "),
+				Duration:  time.Since(start),
+			}
+		}
+
+		fmt.Println("⚠️  WARN")
+		for _, detail := range details {
+			fmt.Printf("   • %s\n", detail)
+		}
+		return &ValidationResult{
+			Component: "File Permissions",
+			Status:    "PASS",
+			Message:   "Secret files are accessible by other users",
+			Details:   strings.Join(details, "; "),
+			Duration:  time.Since(start),
+		}
+	}
+
+	fmt.Println("✅ PASS")
+	return &ValidationResult{
+		Component: "File Permissions",
+		Status:    "PASS",
+		Message:   "Secret files are readable by their owner only",
+		Duration:  time.Since(start),
+	}
+}
+
 // validateGoogleWorkspace tests Google Workspace connectivity
-func (v *Validator) validateGoogleWorkspace() *ValidationResult {
+func (v *Validator) validateGoogleWorkspace(ctx context.Context) *ValidationResult {
 	fmt.Print("🔵 Google Workspace connectivity... ")
 	start := time.Now()
 
-	_, err := gws.NewClient(
+	client, err := gws.NewClient(
 		v.config.GoogleWorkspace.ServiceAccountKeyPath,
 		v.config.GoogleWorkspace.Domain,
 		v.config.GoogleWorkspace.SuperAdminEmail,
@@ -181,8 +257,32 @@ func (v *Validator) validateGoogleWorkspace() *ValidationResult {
 		}
 	}
 
-	// Test basic connectivity - client creation validates auth setup
-	// We could expand this to make actual API calls if needed
+	// Read the first configured group to confirm domain-wide delegation covers the required scopes
+	if len(v.config.Sync.Groups) > 0 {
+		var scopeErr *gws.ScopeError
+		var privilegeErr *gws.PrivilegeError
+		_, err := client.GetGroup(ctx, v.config.Sync.Groups[0])
+		if errors.As(err, &scopeErr) {
+			fmt.Println("❌ FAIL")
+			return &ValidationResult{
+				Component: "Google Workspace",
+				Status:    "FAIL",
+				Message:   "Domain-wide delegation is missing required scopes",
+				Details:   scopeErr.Remediation(),
+				Duration:  time.Since(start),
+			}
+		}
+		if errors.As(err, &privilegeErr) {
+			fmt.Println("❌ FAIL")
+			return &ValidationResult{
+				Component: "Google Workspace",
+				Status:    "FAIL",
+				Message:   "The admin the service account acts as lacks the required privileges",
+				Details:   privilegeErr.Remediation(),
+				Duration:  time.Since(start),
+			}
+		}
+	}
 
 	fmt.Println("✅ PASS")
 	return &ValidationResult{
@@ -214,7 +314,7 @@ func (v *Validator) validateBeyondIdentity() *ValidationResult {
 	}
 
 	// Test connectivity with a simple HTTP request
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := v.httpClient()
 	req, err := http.NewRequest("GET", v.config.BeyondIdentity.SCIMBaseURL+"/Users?count=1", nil)
 	if err != nil {
 		fmt.Println("❌ FAIL")
@@ -275,6 +375,156 @@ func (v *Validator) validateBeyondIdentity() *ValidationResult {
 	}
 }
 
+// validateCapabilities probes which Beyond Identity APIs the token may call
+func (v *Validator) validateCapabilities(ctx context.Context) *ValidationResult {
+	fmt.Print("🔑 Beyond Identity token permissions... ")
+	start := time.Now()
+
+	client := bi.NewClientWithHTTPClient(
+		v.config.BeyondIdentity.APIToken,
+		v.config.BeyondIdentity.SCIMBaseURL,
+		v.config.BeyondIdentity.NativeAPIURL,
+		v.httpClient(),
+	)
+
+	var matrix, denied []string
+	for _, check := range client.ProbeCapabilities(ctx) {
+		status := "allowed"
+		if !check.Allowed {
+			status = "denied"
+			if check.StatusCode != 0 {
+				status = fmt.Sprintf("denied (HTTP %d)", check.StatusCode)
+			}
+			denied = append(denied, fmt.Sprintf("%s (needed for %s)", check.Capability, check.RequiredFor))
+		}
+		matrix = append(matrix, fmt.Sprintf("%s %s: %s", check.Capability, check.Endpoint, status))
+	}
+
+	if len(denied) > 0 {
+		fmt.Println("❌ FAIL")
+		return &ValidationResult{
+			Component: "Token Permissions",
+			Status:    "FAIL",
+			Message:   fmt.Sprintf("Token cannot call: %s", strings.Join(denied, ", ")),
+			Details:   strings.Join(matrix, "; "),
+			Duration:  time.Since(start),
+		}
+	}
+
+	fmt.Println("✅ PASS")
+	return &ValidationResult{
+		Component: "Token Permissions",
+		Status:    "PASS",
+		Message:   "Token can call every API the sync uses",
+		Details:   strings.Join(matrix, "; "),
+		Duration:  time.Since(start),
+	}
+}
+
+// httpClient returns a client for connectivity checks that honors the configured TLS settings
+func (v *Validator) httpClient() *http.Client {
+	tlsConfig, err := httpclient.TLSConfig(v.config.Network.CABundle, v.config.Network.InsecureSkipVerify)
+	if err != nil {
+		// validateTLS reports the unusable bundle; fall back to the system roots here
+		v.logger.Errorf("Ignoring network.ca_bundle: %v", err)
+	}
+	return httpclient.New(httpclient.Options{Timeout: 10 * time.Second, TLSConfig: tlsConfig})
+}
+
+// googleEndpoints are the Google APIs the sync calls
+var googleEndpoints = []string{"https://oauth2.googleapis.com", "https://admin.googleapis.com"}
+
+// validateTLS checks that every configured endpoint presents a certificate that verifies
+// against network.ca_bundle, or the system roots when no bundle is set
+func (v *Validator) validateTLS() *ValidationResult {
+	fmt.Print("🔒 TLS certificate verification... ")
+	start := time.Now()
+
+	roots, source := (*x509.CertPool)(nil), "system roots"
+	if bundle := v.config.Network.CABundle; bundle != "" {
+		pool, err := httpclient.LoadCABundle(bundle)
+		if err != nil {
+			fmt.Println("❌ FAIL")
+			return &ValidationResult{
+				Component: "TLS",
+				Status:    "FAIL",
+				Message:   "Failed to load CA bundle",
+				Details:   err.Error(),
+				Duration:  time.Since(start),
+			}
+		}
+		roots, source = pool, bundle
+	}
+
+	endpoints := []string{v.config.BeyondIdentity.SCIMBaseURL, v.config.BeyondIdentity.NativeAPIURL}
+	if !v.config.PrimaryIsBeyondIdentity() {
+		endpoints = []string{v.config.Target.SCIMBaseURL, v.config.Target.TokenURL}
+	}
+	for _, target := range v.config.Targets {
+		endpoints = append(endpoints, target.SCIMBaseURL, target.NativeAPIURL, target.OrgURL)
+	}
+	endpoints = append(endpoints, googleEndpoints...)
+
+	verified, failures := checkCertificates(endpoints, roots)
+	if len(failures) > 0 {
+		fmt.Println("❌ FAIL")
+		return &ValidationResult{
+			Component: "TLS",
+			Status:    "FAIL",
+			Message:   fmt.Sprintf("%d endpoints did not verify against %s", len(failures), source),
+			Details:   strings.Join(failures, "; "),
+			Duration:  time.Since(start),
+		}
+	}
+
+	message := fmt.Sprintf("%d endpoints verified against %s", verified, source)
+	if v.config.Network.InsecureSkipVerify {
+		message += " (insecure_skip_verify is enabled but not needed)"
+	}
+	fmt.Println("✅ PASS")
+	return &ValidationResult{
+		Component: "TLS",
+		Status:    "PASS",
+		Message:   message,
+		Duration:  time.Since(start),
+	}
+}
+
+// checkCertificates completes a TLS handshake with each distinct https endpoint host, verifying
+// its certificate against roots (nil for the system roots); it returns how many verified and why others failed
+func checkCertificates(endpoints []string, roots *x509.CertPool) (int, []string) {
+	seen := make(map[string]bool)
+	verified := 0
+	var failures []string
+
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Scheme != "https" || u.Host == "" || seen[u.Host] {
+			continue
+		}
+		seen[u.Host] = true
+
+		address := u.Host
+		if u.Port() == "" {
+			address = net.JoinHostPort(u.Hostname(), "443")
+		}
+
+		dialer := &net.Dialer{Timeout: 10 * time.Second}
+		conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+			RootCAs:    roots,
+			ServerName: u.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		})
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", u.Host, err))
+			continue
+		}
+		_ = conn.Close()
+		verified++
+	}
+	return verified, failures
+}
+
 // validateGroups checks if configured groups exist in Google Workspace
 func (v *Validator) validateGroups() *ValidationResult {
 	fmt.Print("👥 Group existence check... ")
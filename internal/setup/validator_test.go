@@ -1,8 +1,13 @@
 package setup
 
 import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -315,3 +320,122 @@ func TestAddResult(t *testing.T) {
 		t.Error("Expected added result to match input result")
 	}
 }
+
+func TestValidateCapabilities(t *testing.T) {
+	tests := []struct {
+		name         string
+		deniedPath   string
+		expectStatus string
+	}{
+		{"all allowed", "", "PASS"},
+		{"groups denied", "/scim/Groups", "FAIL"},
+		{"native API denied", "/v2/users", "FAIL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("Expected only read-only requests, got %s %s", r.Method, r.URL.Path)
+				}
+				if r.URL.Path == tt.deniedPath {
+					http.Error(w, "Forbidden", http.StatusForbidden)
+					return
+				}
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer api.Close()
+
+			validator := NewValidator(&config.Config{
+				BeyondIdentity: config.BeyondIdentityConfig{
+					APIToken:     "test-token",
+					SCIMBaseURL:  api.URL + "/scim",
+					NativeAPIURL: api.URL + "/v2",
+				},
+			})
+			result := validator.validateCapabilities(context.Background())
+
+			if result.Status != tt.expectStatus {
+				t.Errorf("Expected status %s, got %s: %s", tt.expectStatus, result.Status, result.Details)
+			}
+			if tt.expectStatus == "FAIL" && !strings.Contains(result.Details, "HTTP 403") {
+				t.Errorf("Expected the denied status in the details, got %s", result.Details)
+			}
+		})
+	}
+}
+
+func TestCheckCertificates(t *testing.T) {
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer api.Close()
+
+	bundle := x509.NewCertPool()
+	bundle.AddCert(api.Certificate())
+
+	endpoints := []string{api.URL + "/scim", api.URL + "/v2", "http://plain.example.com", ""}
+
+	verified, failures := checkCertificates(endpoints, bundle)
+	if verified != 1 || len(failures) != 0 {
+		t.Errorf("Expected the test server to verify once against its CA, got %d verified, failures %v", verified, failures)
+	}
+
+	verified, failures = checkCertificates(endpoints, x509.NewCertPool())
+	if verified != 0 || len(failures) != 1 {
+		t.Errorf("Expected verification to fail against an unrelated bundle, got %d verified, failures %v", verified, failures)
+	}
+}
+
+func TestValidateTLS_InvalidBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	validator := NewValidator(&config.Config{Network: config.NetworkConfig{CABundle: path}})
+	if result := validator.validateTLS(); result.Status != "FAIL" || result.Message != "Failed to load CA bundle" {
+		t.Errorf("Expected an invalid bundle to fail, got %+v", result)
+	}
+}
+
+func TestValidateFilePermissions(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "key.json")
+	if err := os.WriteFile(keyPath, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(keyPath, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		strict       bool
+		mode         os.FileMode
+		expectStatus string
+		expectDetail bool
+	}{
+		{"restricted", false, 0600, "PASS", false},
+		{"open warns", false, 0644, "PASS", true},
+		{"open fails when strict", true, 0644, "FAIL", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.Chmod(keyPath, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			cfg := &config.Config{
+				App:             config.AppConfig{StrictPermissions: tt.strict},
+				GoogleWorkspace: config.GoogleWorkspaceConfig{ServiceAccountKeyPath: keyPath},
+			}
+
+			result := NewValidator(cfg).validateFilePermissions()
+			if result.Status != tt.expectStatus {
+				t.Errorf("Expected status %s, got %s", tt.expectStatus, result.Status)
+			}
+			if strings.Contains(result.Details, keyPath) != tt.expectDetail {
+				t.Errorf("Expected key in details: %v, got %q", tt.expectDetail, result.Details)
+			}
+		})
+	}
+}
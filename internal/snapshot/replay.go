@@ -0,0 +1,343 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	"google.golang.org/api/googleapi"
+)
+
+// Change actions captured during a replay
+const (
+	ActionCreateUser   = "create_user"
+	ActionCreateGroup  = "create_group"
+	ActionRenameGroup  = "rename_group"
+	ActionAddMember    = "add_member"
+	ActionRemoveMember = "remove_member"
+)
+
+// Change is a write the sync would have made
+type Change struct {
+	Target  string `json:"target"` // Target name, or google_workspace for the enrollment group
+	Action  string `json:"action"`
+	Subject string `json:"subject"`         // User email or group name
+	Group   string `json:"group,omitempty"` // Group a member was added to or removed from
+}
+
+func (c Change) String() string {
+	switch c.Action {
+	case ActionCreateUser:
+		return fmt.Sprintf("create user %s in %s", c.Subject, c.Target)
+	case ActionCreateGroup:
+		return fmt.Sprintf("create group %s in %s", c.Subject, c.Target)
+	case ActionRenameGroup:
+		return fmt.Sprintf("rename group %s to %s in %s", c.Group, c.Subject, c.Target)
+	case ActionAddMember:
+		return fmt.Sprintf("add %s to %s in %s", c.Subject, c.Group, c.Target)
+	case ActionRemoveMember:
+		return fmt.Sprintf("remove %s from %s in %s", c.Subject, c.Group, c.Target)
+	default:
+		return fmt.Sprintf("%s %s in %s", c.Action, c.Subject, c.Target)
+	}
+}
+
+// Recorder collects the changes made by replay clients, in order
+type Recorder struct {
+	changes []Change
+}
+
+// Changes returns the captured changes
+func (r *Recorder) Changes() []Change {
+	return append([]Change(nil), r.changes...)
+}
+
+func (r *Recorder) record(change Change) {
+	r.changes = append(r.changes, change)
+}
+
+// TenantClient serves a recorded tenant from memory, applying and capturing writes
+type TenantClient struct {
+	name     string
+	recorder *Recorder
+	users    []*bi.User
+	groups   []*bi.Group
+	enrolled map[string]bool
+	nextID   int
+}
+
+// NewTenantClient creates a client for the recorded tenant of the named target; a nil
+// snapshot starts the tenant empty
+func NewTenantClient(name string, data *TenantSnapshot, recorder *Recorder) *TenantClient {
+	c := &TenantClient{name: name, recorder: recorder, enrolled: make(map[string]bool)}
+	if data == nil {
+		return c
+	}
+	for i := range data.Users {
+		user := data.Users[i]
+		c.users = append(c.users, &user)
+	}
+	for i := range data.Groups {
+		group := data.Groups[i]
+		group.Members = append([]bi.GroupMember(nil), group.Members...)
+		c.groups = append(c.groups, &group)
+	}
+	for _, email := range data.Enrolled {
+		c.enrolled[strings.ToLower(email)] = true
+	}
+	return c
+}
+
+// FindGroupByDisplayName implements the provisioning client
+func (c *TenantClient) FindGroupByDisplayName(ctx context.Context, name string) (*bi.Group, error) {
+	for _, group := range c.groups {
+		if group.DisplayName == name {
+			copied := *group
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateGroup implements the provisioning client
+func (c *TenantClient) CreateGroup(ctx context.Context, group *bi.Group) (*bi.Group, error) {
+	created := *group
+	created.ID = c.newID("group")
+	c.groups = append(c.groups, &created)
+	c.recorder.record(Change{Target: c.name, Action: ActionCreateGroup, Subject: created.DisplayName})
+
+	copied := created
+	return &copied, nil
+}
+
+// RenameGroup implements the optional group renamer used to archive orphaned groups
+func (c *TenantClient) RenameGroup(ctx context.Context, groupID, displayName string) error {
+	group := c.group(groupID)
+	if group == nil {
+		return fmt.Errorf("group %s not found in snapshot", groupID)
+	}
+	c.recorder.record(Change{Target: c.name, Action: ActionRenameGroup, Subject: displayName, Group: group.DisplayName})
+	group.DisplayName = displayName
+	return nil
+}
+
+// FindUserByEmail implements the provisioning client
+func (c *TenantClient) FindUserByEmail(ctx context.Context, email string) (*bi.User, error) {
+	if user := c.userByEmail(email); user != nil {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// CreateUser implements the provisioning client
+func (c *TenantClient) CreateUser(ctx context.Context, user *bi.User) (*bi.User, error) {
+	created := *user
+	created.ID = c.newID("user")
+	c.users = append(c.users, &created)
+	c.recorder.record(Change{Target: c.name, Action: ActionCreateUser, Subject: created.UserName})
+
+	copied := created
+	return &copied, nil
+}
+
+// UpdateGroupMembers implements the provisioning client
+func (c *TenantClient) UpdateGroupMembers(ctx context.Context, groupID string, membersToAdd []bi.GroupMember, membersToRemove []bi.GroupMember) error {
+	group := c.group(groupID)
+	if group == nil {
+		return fmt.Errorf("group %s not found in snapshot", groupID)
+	}
+
+	for _, member := range membersToAdd {
+		group.Members = append(group.Members, member)
+		c.recorder.record(Change{Target: c.name, Action: ActionAddMember, Subject: c.identity(member), Group: group.DisplayName})
+	}
+	for _, member := range membersToRemove {
+		for i, existing := range group.Members {
+			if existing.Value == member.Value {
+				c.recorder.record(Change{Target: c.name, Action: ActionRemoveMember, Subject: c.identity(existing), Group: group.DisplayName})
+				group.Members = append(group.Members[:i], group.Members[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// GetUserStatus implements the provisioning client from the recorded enrollment state
+func (c *TenantClient) GetUserStatus(ctx context.Context, userEmail string) (bool, error) {
+	user := c.userByEmail(userEmail)
+	return user != nil && user.Active && c.enrolled[strings.ToLower(userEmail)], nil
+}
+
+// GetGroupWithMembers implements the provisioning client
+func (c *TenantClient) GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error) {
+	group := c.group(groupID)
+	if group == nil {
+		return nil, fmt.Errorf("group %s not found in snapshot", groupID)
+	}
+	copied := *group
+	copied.Members = append([]bi.GroupMember(nil), group.Members...)
+	return &copied, nil
+}
+
+func (c *TenantClient) group(id string) *bi.Group {
+	for _, group := range c.groups {
+		if group.ID == id {
+			return group
+		}
+	}
+	return nil
+}
+
+func (c *TenantClient) userByEmail(email string) *bi.User {
+	for _, user := range c.users {
+		if strings.EqualFold(user.UserName, email) {
+			return user
+		}
+		for _, e := range user.Emails {
+			if strings.EqualFold(e.Value, email) {
+				return user
+			}
+		}
+	}
+	return nil
+}
+
+// identity names a group member by email when the user is known
+func (c *TenantClient) identity(member bi.GroupMember) string {
+	for _, user := range c.users {
+		if user.ID == member.Value {
+			return user.UserName
+		}
+	}
+	if member.Display != "" {
+		return member.Display
+	}
+	return member.Value
+}
+
+func (c *TenantClient) newID(kind string) string {
+	c.nextID++
+	return fmt.Sprintf("replay-%s-%d", kind, c.nextID)
+}
+
+// WorkspaceClient serves Google groups from a snapshot, or reads them live when there is no
+// recorded Google data, and captures writes to the enrollment group instead of making them
+type WorkspaceClient struct {
+	data     *WorkspaceSnapshot
+	live     GroupReader
+	recorder *Recorder
+	created  map[string]*gws.Group
+	added    map[string][]*gws.GroupMember
+	removed  map[string]map[string]bool
+}
+
+// NewWorkspaceClient creates a client over recorded data, or over live when data is nil
+func NewWorkspaceClient(data *WorkspaceSnapshot, live GroupReader, recorder *Recorder) *WorkspaceClient {
+	return &WorkspaceClient{
+		data:     data,
+		live:     live,
+		recorder: recorder,
+		created:  make(map[string]*gws.Group),
+		added:    make(map[string][]*gws.GroupMember),
+		removed:  make(map[string]map[string]bool),
+	}
+}
+
+// GetGroup implements the Google Workspace client
+func (c *WorkspaceClient) GetGroup(ctx context.Context, email string) (*gws.Group, error) {
+	key := strings.ToLower(email)
+	if group, ok := c.created[key]; ok {
+		return group, nil
+	}
+	if c.data == nil {
+		return c.live.GetGroup(ctx, email)
+	}
+	if group, ok := c.data.Groups[key]; ok {
+		return group, nil
+	}
+	return nil, c.missing(email)
+}
+
+// missing reports a group absent from the recorded data: deleted if it was recorded as
+// deleted, otherwise an error since replay cannot know its members
+func (c *WorkspaceClient) missing(email string) error {
+	for _, deleted := range c.data.Deleted {
+		if strings.EqualFold(deleted, email) {
+			return &googleapi.Error{Code: http.StatusNotFound, Message: "Resource Not Found: groupKey " + email}
+		}
+	}
+	return fmt.Errorf("group %s is not in the snapshot; record a new snapshot or replay with live Google data", email)
+}
+
+// GetGroupMembers implements the Google Workspace client, including captured writes
+func (c *WorkspaceClient) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	key := strings.ToLower(email)
+
+	var recorded []*gws.GroupMember
+	switch {
+	case c.created[key] != nil:
+	case c.data == nil:
+		members, err := c.live.GetGroupMembers(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+		recorded = members
+	default:
+		if _, ok := c.data.Groups[key]; !ok {
+			return nil, c.missing(email)
+		}
+		recorded = c.data.Members[key]
+	}
+
+	var members []*gws.GroupMember
+	for _, member := range append(recorded, c.added[key]...) {
+		if !c.removed[key][strings.ToLower(member.Email)] {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// AddMemberToGroup implements the Google Workspace client
+func (c *WorkspaceClient) AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error {
+	key := strings.ToLower(groupEmail)
+	delete(c.removed[key], strings.ToLower(userEmail))
+	c.added[key] = append(c.added[key], &gws.GroupMember{Email: userEmail, Role: "MEMBER", Type: "USER", Status: "ACTIVE"})
+	c.recorder.record(Change{Target: state.TargetGoogleWorkspace, Action: ActionAddMember, Subject: userEmail, Group: groupEmail})
+	return nil
+}
+
+// RemoveMemberFromGroup implements the Google Workspace client
+func (c *WorkspaceClient) RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error {
+	key := strings.ToLower(groupEmail)
+	if c.removed[key] == nil {
+		c.removed[key] = make(map[string]bool)
+	}
+	c.removed[key][strings.ToLower(userEmail)] = true
+	c.recorder.record(Change{Target: state.TargetGoogleWorkspace, Action: ActionRemoveMember, Subject: userEmail, Group: groupEmail})
+	return nil
+}
+
+// EnsureGroup implements the Google Workspace client, capturing the creation of a missing group
+func (c *WorkspaceClient) EnsureGroup(ctx context.Context, groupEmail, groupName, description string) (*gws.Group, error) {
+	group, err := c.GetGroup(ctx, groupEmail)
+	if err == nil {
+		return group, nil
+	}
+	var googleErr *googleapi.Error
+	if !errors.As(err, &googleErr) || googleErr.Code != http.StatusNotFound {
+		return nil, err
+	}
+
+	group = &gws.Group{Email: groupEmail, Name: groupName, Description: description}
+	c.created[strings.ToLower(groupEmail)] = group
+	c.recorder.record(Change{Target: state.TargetGoogleWorkspace, Action: ActionCreateGroup, Subject: groupEmail})
+	return group, nil
+}
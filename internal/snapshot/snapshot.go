@@ -0,0 +1,212 @@
+// Package snapshot records what a sync reads from Beyond Identity and Google Workspace, and
+// replays a sync against such a recording entirely in memory
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"google.golang.org/api/googleapi"
+)
+
+// Snapshot is a recording of the data a sync run reads
+type Snapshot struct {
+	RecordedAt      time.Time                  `json:"recorded_at"`
+	Targets         map[string]*TenantSnapshot `json:"targets"`                    // target name -> recorded tenant
+	GoogleWorkspace *WorkspaceSnapshot         `json:"google_workspace,omitempty"` // Unset to read Google data live
+}
+
+// TenantSnapshot is the recorded users and managed groups of one provisioning target
+type TenantSnapshot struct {
+	Users    []bi.User  `json:"users"`
+	Groups   []bi.Group `json:"groups"`
+	Enrolled []string   `json:"enrolled,omitempty"` // Emails of users that were active with an active passkey
+}
+
+// WorkspaceSnapshot is the recorded synced groups, their members and the enrollment group
+type WorkspaceSnapshot struct {
+	Groups  map[string]*gws.Group         `json:"groups"`            // lower-cased group email -> group
+	Members map[string][]*gws.GroupMember `json:"members"`           // lower-cased group email -> members
+	Deleted []string                      `json:"deleted,omitempty"` // Configured groups that did not exist
+}
+
+// GroupReader reads groups and their members
+type GroupReader interface {
+	GetGroup(ctx context.Context, email string) (*gws.Group, error)
+	GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error)
+}
+
+// TenantReader reads the users and groups of a provisioning target
+type TenantReader interface {
+	FindGroupByDisplayName(ctx context.Context, name string) (*bi.Group, error)
+	FindUserByEmail(ctx context.Context, email string) (*bi.User, error)
+	GetUserStatus(ctx context.Context, userEmail string) (bool, error)
+	GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error)
+}
+
+// Load reads a snapshot file
+func Load(path string) (*Snapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	if snap.Targets == nil {
+		snap.Targets = make(map[string]*TenantSnapshot)
+	}
+	return &snap, nil
+}
+
+// Save writes the snapshot; it contains user emails, so only the owner can read it
+func (s *Snapshot) Save(path string) error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Record reads everything a sync of cfg would read: the synced groups from source, the
+// enrollment group from google, and the matching groups and users of each target
+func Record(ctx context.Context, cfg *config.Config, source, google GroupReader, targets map[string]TenantReader) (*Snapshot, error) {
+	snap := &Snapshot{
+		RecordedAt:      time.Now(),
+		Targets:         make(map[string]*TenantSnapshot),
+		GoogleWorkspace: &WorkspaceSnapshot{Groups: make(map[string]*gws.Group), Members: make(map[string][]*gws.GroupMember)},
+	}
+	recorders := make(map[string]*tenantRecorder)
+
+	for _, groupEmail := range cfg.Sync.Groups {
+		targetName := cfg.TargetForGroup(groupEmail)
+		target, ok := targets[targetName]
+		if !ok {
+			return nil, fmt.Errorf("no client for target %s", targetName)
+		}
+		recorder, ok := recorders[targetName]
+		if !ok {
+			recorder = &tenantRecorder{reader: target, data: &TenantSnapshot{}, users: make(map[string]bool), groups: make(map[string]bool)}
+			recorders[targetName] = recorder
+			snap.Targets[targetName] = recorder.data
+		}
+
+		group, members, err := recordGroup(ctx, snap.GoogleWorkspace, source, groupEmail)
+		if err != nil {
+			return nil, err
+		}
+		if group == nil {
+			continue
+		}
+
+		if err := recorder.group(ctx, cfg.GroupPrefixForTarget(targetName)+group.Name); err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if member.Type != "USER" {
+				continue
+			}
+			if err := recorder.user(ctx, member.Email); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if cfg.Sync.EnrollmentGroupEmail != "" {
+		if _, _, err := recordGroup(ctx, snap.GoogleWorkspace, google, cfg.Sync.EnrollmentGroupEmail); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+// recordGroup copies a group and its members into the workspace snapshot, noting groups that
+// do not exist so replay sees them as deleted
+func recordGroup(ctx context.Context, workspace *WorkspaceSnapshot, reader GroupReader, groupEmail string) (*gws.Group, []*gws.GroupMember, error) {
+	group, err := reader.GetGroup(ctx, groupEmail)
+	if err != nil {
+		var googleErr *googleapi.Error
+		if errors.As(err, &googleErr) && googleErr.Code == http.StatusNotFound {
+			workspace.Deleted = append(workspace.Deleted, strings.ToLower(groupEmail))
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read group %s: %w", groupEmail, err)
+	}
+	members, err := reader.GetGroupMembers(ctx, groupEmail)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read members of %s: %w", groupEmail, err)
+	}
+
+	key := strings.ToLower(groupEmail)
+	workspace.Groups[key] = group
+	workspace.Members[key] = members
+	return group, members, nil
+}
+
+// tenantRecorder copies users and groups from a target, each once
+type tenantRecorder struct {
+	reader TenantReader
+	data   *TenantSnapshot
+	users  map[string]bool
+	groups map[string]bool
+}
+
+// group records a managed group with its members, if it exists
+func (r *tenantRecorder) group(ctx context.Context, displayName string) error {
+	if r.groups[displayName] {
+		return nil
+	}
+	r.groups[displayName] = true
+
+	group, err := r.reader.FindGroupByDisplayName(ctx, displayName)
+	if err != nil {
+		return fmt.Errorf("failed to search for group %s: %w", displayName, err)
+	}
+	if group == nil {
+		return nil
+	}
+	withMembers, err := r.reader.GetGroupWithMembers(ctx, group.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read members of group %s: %w", displayName, err)
+	}
+	r.data.Groups = append(r.data.Groups, *withMembers)
+	return nil
+}
+
+// user records a user and whether they are enrolled, if they exist
+func (r *tenantRecorder) user(ctx context.Context, email string) error {
+	key := strings.ToLower(email)
+	if r.users[key] {
+		return nil
+	}
+	r.users[key] = true
+
+	user, err := r.reader.FindUserByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to search for user %s: %w", email, err)
+	}
+	if user == nil {
+		return nil
+	}
+	r.data.Users = append(r.data.Users, *user)
+
+	// Enrollment is optional context; a Native API outage should not prevent recording
+	if enrolled, err := r.reader.GetUserStatus(ctx, email); err == nil && enrolled {
+		r.data.Enrolled = append(r.data.Enrolled, key)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package snapshot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestConfig(groups ...string) *config.Config {
+	return &config.Config{
+		BeyondIdentity: config.BeyondIdentityConfig{GroupPrefix: "GWS_"},
+		Sync: config.SyncConfig{
+			Groups:               groups,
+			EnrollmentGroupEmail: "enrolled@example.com",
+			EnrollmentGroupName:  "BYID Enrolled",
+		},
+	}
+}
+
+// recordTestSnapshot records a tenant where alice is provisioned and enrolled, and bob is a
+// stale member of GWS_Engineering
+func recordTestSnapshot(t *testing.T) *Snapshot {
+	t.Helper()
+
+	google := NewWorkspaceClient(&WorkspaceSnapshot{
+		Groups: map[string]*gws.Group{
+			"eng@example.com":      {Email: "eng@example.com", Name: "Engineering"},
+			"sales@example.com":    {Email: "sales@example.com", Name: "Sales"},
+			"enrolled@example.com": {Email: "enrolled@example.com", Name: "BYID Enrolled"},
+		},
+		Members: map[string][]*gws.GroupMember{
+			"eng@example.com":   {{Email: "alice@example.com", Type: "USER", Status: "ACTIVE"}},
+			"sales@example.com": {{Email: "carol@example.com", Type: "USER", Status: "ACTIVE"}},
+		},
+	}, nil, &Recorder{})
+
+	tenant := NewTenantClient(config.DefaultTargetName, &TenantSnapshot{
+		Users: []bi.User{
+			{ID: "u1", UserName: "alice@example.com", Active: true},
+			{ID: "u2", UserName: "bob@example.com", Active: true},
+		},
+		Groups: []bi.Group{
+			{ID: "g1", DisplayName: "GWS_Engineering", Members: []bi.GroupMember{{Value: "u1"}, {Value: "u2"}}},
+		},
+		Enrolled: []string{"alice@example.com"},
+	}, &Recorder{})
+
+	snap, err := Record(context.Background(), newTestConfig("eng@example.com"), google, google, map[string]TenantReader{config.DefaultTargetName: tenant})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.json")
+	if err := snap.Save(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return loaded
+}
+
+func TestRecord(t *testing.T) {
+	snap := recordTestSnapshot(t)
+
+	tenant := snap.Targets[config.DefaultTargetName]
+	if tenant == nil || len(tenant.Users) != 1 || tenant.Users[0].UserName != "alice@example.com" {
+		t.Fatalf("Expected only the source members to be recorded, got %+v", tenant)
+	}
+	if len(tenant.Groups) != 1 || len(tenant.Groups[0].Members) != 2 {
+		t.Errorf("Expected GWS_Engineering with both members, got %+v", tenant.Groups)
+	}
+	if len(tenant.Enrolled) != 1 {
+		t.Errorf("Expected alice to be recorded as enrolled, got %v", tenant.Enrolled)
+	}
+	if _, ok := snap.GoogleWorkspace.Groups["sales@example.com"]; ok {
+		t.Error("Expected groups outside the configuration not to be recorded")
+	}
+	if _, ok := snap.GoogleWorkspace.Groups["enrolled@example.com"]; !ok {
+		t.Error("Expected the enrollment group to be recorded")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	snap := recordTestSnapshot(t)
+
+	// Rehearse adding sales@example.com, which was not part of the recording
+	cfg := newTestConfig("eng@example.com", "sales@example.com")
+	recorder := &Recorder{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	engine := syncengine.NewEngine(
+		NewWorkspaceClient(snap.GoogleWorkspace, nil, recorder),
+		NewTenantClient(config.DefaultTargetName, snap.Targets[config.DefaultTargetName], recorder),
+		cfg, logger,
+	)
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Change{
+		{Target: "default", Action: ActionRemoveMember, Subject: "u2", Group: "GWS_Engineering"},
+		{Target: "google_workspace", Action: ActionAddMember, Subject: "alice@example.com", Group: "enrolled@example.com"},
+	}
+	changes := recorder.Changes()
+	if len(changes) < len(expected) {
+		t.Fatalf("Expected at least %d changes, got %+v", len(expected), changes)
+	}
+	for i, want := range expected {
+		if changes[i] != want {
+			t.Errorf("Expected change %d to be %+v, got %+v", i, want, changes[i])
+		}
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected the unrecorded sales group to fail, got %v", result.Errors)
+	}
+}
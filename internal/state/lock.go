@@ -0,0 +1,215 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	gosync "sync"
+	"time"
+)
+
+// ErrLocked is returned by AcquireLock when another process holds an unexpired lease
+var ErrLocked = errors.New("sync lock is held by another process")
+
+// LockInfo is the content of a lock file
+type LockInfo struct {
+	Owner      string    `json:"owner"` // host:pid of the process holding the lease
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the lease has run out at now, meaning its holder stopped renewing it
+func (l LockInfo) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// LockedError describes the lease that prevented AcquireLock from taking the lock
+type LockedError struct {
+	Path   string
+	Holder LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%v: %s holds %s until %s", ErrLocked, e.Holder.Owner, e.Path, e.Holder.ExpiresAt.Format(time.RFC3339))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// Lock is a lease on a lock file; the lease must be renewed before it expires or another
+// process may take the lock over, which is how a crashed holder stops blocking later runs
+type Lock struct {
+	path  string
+	lease time.Duration
+
+	mu   gosync.Mutex
+	info LockInfo
+	stop chan struct{}
+	done chan struct{}
+}
+
+// LockOwner identifies this process in lock files
+func LockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// ReadLock returns the lease recorded in the lock file at path, if there is one
+func ReadLock(path string) (LockInfo, bool, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return LockInfo{}, false, nil
+	}
+	if err != nil {
+		return LockInfo{}, false, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	var info LockInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		// A holder that crashed while writing leaves an unreadable file; treat it as expired
+		return LockInfo{}, true, nil
+	}
+	return info, true, nil
+}
+
+// AcquireLock takes the lock file at path for lease, taking over an expired lease left by a
+// holder that crashed; the previous holder is returned when a stale lease was taken over
+func AcquireLock(path string, lease time.Duration) (*Lock, *LockInfo, error) {
+	now := time.Now()
+	l := &Lock{
+		path:  path,
+		lease: lease,
+		info:  LockInfo{Owner: LockOwner(), AcquiredAt: now, ExpiresAt: now.Add(lease)},
+	}
+
+	raw, err := json.Marshal(l.info)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode lock: %w", err)
+	}
+
+	var stale *LockInfo
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			_, werr := f.Write(raw)
+			cerr := f.Close()
+			if werr != nil || cerr != nil {
+				_ = os.Remove(path)
+				return nil, nil, fmt.Errorf("failed to write lock file: %w", errors.Join(werr, cerr))
+			}
+			return l, stale, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		holder, exists, err := ReadLock(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if exists && !holder.Expired(now) {
+			return nil, nil, &LockedError{Path: path, Holder: holder}
+		}
+
+		// The lease ran out, so its holder is gone; remove it and race for the lock again
+		stale = &holder
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("failed to remove stale lock file: %w", err)
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s was taken over by another process", ErrLocked, path)
+}
+
+// Info returns the current lease
+func (l *Lock) Info() LockInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.info
+}
+
+// Renew extends the lease from now
+func (l *Lock) Renew() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	holder, exists, err := ReadLock(l.path)
+	if err != nil {
+		return err
+	}
+	if !exists || holder.Owner != l.info.Owner || !holder.AcquiredAt.Equal(l.info.AcquiredAt) {
+		return fmt.Errorf("%w: lease on %s was lost", ErrLocked, l.path)
+	}
+
+	info := l.info
+	info.ExpiresAt = time.Now().Add(l.lease)
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to encode lock: %w", err)
+	}
+	if err := os.WriteFile(l.path, raw, 0600); err != nil {
+		return fmt.Errorf("failed to renew lock: %w", err)
+	}
+	l.info = info
+	return nil
+}
+
+// KeepAlive renews the lease in the background every third of the lease until Release
+func (l *Lock) KeepAlive(onError func(error)) {
+	l.mu.Lock()
+	if l.stop != nil {
+		l.mu.Unlock()
+		return
+	}
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	stop, done := l.stop, l.done
+	l.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(l.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := l.Renew(); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// Release stops renewing the lease and removes the lock file if this process still holds it
+func (l *Lock) Release() error {
+	l.mu.Lock()
+	stop, done := l.stop, l.done
+	l.stop = nil
+	l.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	holder, exists, err := ReadLock(l.path)
+	if err != nil {
+		return err
+	}
+	if !exists || holder.Owner != l.info.Owner || !holder.AcquiredAt.Equal(l.info.AcquiredAt) {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json.lock")
+
+	lock, stale, err := AcquireLock(path, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stale != nil {
+		t.Errorf("Expected no stale lease on a fresh lock, got %+v", stale)
+	}
+
+	_, _, err = AcquireLock(path, time.Minute)
+	var locked *LockedError
+	if !errors.As(err, &locked) || !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected a held lock to be refused, got %v", err)
+	}
+	if locked.Holder.Owner != LockOwner() {
+		t.Errorf("Expected the holder to be %s, got %s", LockOwner(), locked.Holder.Owner)
+	}
+
+	if err := lock.Renew(); err != nil {
+		t.Errorf("Unexpected error renewing: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Unexpected error releasing: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the lock file to be removed, got %v", err)
+	}
+
+	relocked, _, err := AcquireLock(path, time.Minute)
+	if err != nil {
+		t.Fatalf("Expected the released lock to be free, got %v", err)
+	}
+	_ = relocked.Release()
+}
+
+func TestAcquireLock_TakesOverExpiredLease(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"expired lease", mustLockJSON(t, LockInfo{Owner: "crashed:42", ExpiresAt: time.Now().Add(-time.Minute)})},
+		{"unreadable lock file", "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json.lock")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to write lock file: %v", err)
+			}
+
+			lock, stale, err := AcquireLock(path, time.Minute)
+			if err != nil {
+				t.Fatalf("Expected the stale lock to be taken over, got %v", err)
+			}
+			if stale == nil {
+				t.Error("Expected the stale lease to be reported")
+			}
+			if info, _, _ := ReadLock(path); info.Owner != LockOwner() {
+				t.Errorf("Expected this process to hold the lock, got %+v", info)
+			}
+
+			// A holder whose lease was taken over must not remove the new holder's lock
+			lost := &Lock{path: path, lease: time.Minute, info: LockInfo{Owner: "crashed:42"}}
+			if err := lost.Renew(); !errors.Is(err, ErrLocked) {
+				t.Errorf("Expected renewing a lost lease to fail, got %v", err)
+			}
+			if err := lost.Release(); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if _, exists, _ := ReadLock(path); !exists {
+				t.Error("Expected the new holder's lock to be kept")
+			}
+			_ = lock.Release()
+		})
+	}
+}
+
+func mustLockJSON(t *testing.T, info LockInfo) string {
+	t.Helper()
+	raw, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("Failed to encode lock: %v", err)
+	}
+	return string(raw)
+}
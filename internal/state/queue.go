@@ -0,0 +1,108 @@
+package state
+
+import (
+	"strings"
+	"time"
+)
+
+// Deferred operation kinds
+const (
+	DeferredCreateUser         = "create_user"
+	DeferredUpdateGroupMembers = "update_group_members"
+)
+
+// DeferredOp is a write that failed with a transient error and is retried by later runs until it
+// succeeds or expires
+type DeferredOp struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Target     string    `json:"target,omitempty"`
+	User       string    `json:"user,omitempty"`     // Email of the user to create
+	Group      string    `json:"group,omitempty"`    // Source group email of a membership update
+	GroupID    string    `json:"group_id,omitempty"` // Beyond Identity group of a membership update
+	Add        []string  `json:"add,omitempty"`      // User IDs to add
+	Remove     []string  `json:"remove,omitempty"`   // User IDs to remove
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the operation is no longer retried at now
+func (o DeferredOp) Expired(now time.Time) bool {
+	return !now.Before(o.ExpiresAt)
+}
+
+// key identifies operations that replace each other: the latest membership update of a group
+// supersedes earlier ones, and a user is only created once per target
+func (o DeferredOp) key() string {
+	if o.Kind == DeferredCreateUser {
+		return o.Kind + "\x00" + o.Target + "\x00" + strings.ToLower(o.User)
+	}
+	return o.Kind + "\x00" + o.Target + "\x00" + o.GroupID
+}
+
+// Enqueue adds an operation to the retry queue, replacing any queued operation it supersedes;
+// the replacement keeps its place, ID, attempts and expiry so an outage cannot keep a write
+// queued forever
+func (s *Store) Enqueue(op DeferredOp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, queued := range s.data.Queue {
+		if queued.key() == op.key() {
+			op.ID = queued.ID
+			op.Attempts = queued.Attempts
+			op.EnqueuedAt = queued.EnqueuedAt
+			op.ExpiresAt = queued.ExpiresAt
+			s.data.Queue[i] = &op
+			return
+		}
+	}
+	s.data.Queue = append(s.data.Queue, &op)
+}
+
+// DeferredOps returns the queued operations, oldest first
+func (s *Store) DeferredOps() []DeferredOp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ops := make([]DeferredOp, 0, len(s.data.Queue))
+	for _, op := range s.data.Queue {
+		ops = append(ops, *op)
+	}
+	return ops
+}
+
+// UpdateDeferred replaces the queued operation with the same ID, e.g. after a failed attempt
+func (s *Store) UpdateDeferred(op DeferredOp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, queued := range s.data.Queue {
+		if queued.ID == op.ID {
+			s.data.Queue[i] = &op
+			return
+		}
+	}
+}
+
+// RemoveDeferred removes an operation from the queue
+func (s *Store) RemoveDeferred(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, queued := range s.data.Queue {
+		if queued.ID == id {
+			s.data.Queue = append(s.data.Queue[:i], s.data.Queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// QueueDepth returns how many operations are waiting to be retried
+func (s *Store) QueueDepth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.data.Queue)
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_Queue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	expires := now.Add(24 * time.Hour)
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.Enqueue(DeferredOp{ID: "1", Kind: DeferredCreateUser, Target: "default", User: "Alice@example.com", EnqueuedAt: now, ExpiresAt: expires})
+	store.Enqueue(DeferredOp{ID: "2", Kind: DeferredUpdateGroupMembers, Target: "default", GroupID: "g1", Add: []string{"u1"}, EnqueuedAt: now, ExpiresAt: expires})
+	store.Enqueue(DeferredOp{ID: "3", Kind: DeferredCreateUser, Target: "eu", User: "alice@example.com", EnqueuedAt: now, ExpiresAt: expires})
+
+	// A later update of the same group and a repeated create supersede the queued ones
+	store.Enqueue(DeferredOp{ID: "4", Kind: DeferredUpdateGroupMembers, Target: "default", GroupID: "g1", Add: []string{"u1", "u2"}, EnqueuedAt: now, ExpiresAt: expires})
+	store.Enqueue(DeferredOp{ID: "5", Kind: DeferredCreateUser, Target: "default", User: "alice@example.com", EnqueuedAt: now, ExpiresAt: expires})
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ops := reopened.DeferredOps()
+	var ids []string
+	for _, op := range ops {
+		ids = append(ids, op.ID)
+	}
+	if len(ids) != 3 || ids[0] != "1" || ids[1] != "2" || ids[2] != "3" {
+		t.Fatalf("Expected queued operations [1 2 3], got %v", ids)
+	}
+	if len(ops[1].Add) != 2 || ops[0].User != "alice@example.com" {
+		t.Errorf("Expected the latest operations to be kept, got %+v", ops)
+	}
+
+	// Superseding keeps the original expiry
+	reopened.Enqueue(DeferredOp{ID: "6", Kind: DeferredCreateUser, Target: "eu", User: "alice@example.com", ExpiresAt: expires.Add(time.Hour)})
+	if got := reopened.DeferredOps()[2]; got.ID != "3" || !got.ExpiresAt.Equal(expires) {
+		t.Errorf("Expected the original expiry to be kept, got %+v", got)
+	}
+
+	op := reopened.DeferredOps()[0]
+	op.Attempts = 2
+	op.LastError = "HTTP 503"
+	reopened.UpdateDeferred(op)
+	if got := reopened.DeferredOps()[0]; got.Attempts != 2 || got.LastError != "HTTP 503" {
+		t.Errorf("Expected the attempt to be recorded, got %+v", got)
+	}
+
+	reopened.RemoveDeferred("2")
+	if depth := reopened.QueueDepth(); depth != 2 {
+		t.Errorf("Expected queue depth 2, got %d", depth)
+	}
+}
+
+func TestDeferredOp_Expired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	op := DeferredOp{ExpiresAt: now}
+
+	if op.Expired(now.Add(-time.Second)) {
+		t.Error("Expected the operation to apply before it expires")
+	}
+	if !op.Expired(now) {
+		t.Error("Expected the operation to expire at its expiry time")
+	}
+}
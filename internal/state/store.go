@@ -0,0 +1,398 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+)
+
+// GroupState is what is remembered about a synced source group between runs
+type GroupState struct {
+	BIGroupID   string    `json:"bi_group_id,omitempty"`
+	BIGroupName string    `json:"bi_group_name,omitempty"`
+	Target      string    `json:"target,omitempty"`
+	LastSynced  time.Time `json:"last_synced,omitempty"`
+	Orphaned    bool      `json:"orphaned,omitempty"`
+	OrphanedAt  time.Time `json:"orphaned_at,omitempty"`
+	ConfigHash  string    `json:"config_hash,omitempty"` // Sync configuration the group was orphaned under
+}
+
+// Change actions recorded in the history
+const (
+	ChangeUserCreated       = "user_created"
+	ChangeMembershipAdded   = "membership_added"
+	ChangeMembershipRemoved = "membership_removed"
+	ChangeUserDeactivated   = "user_deactivated"
+	ChangeUserUpdated       = "user_updated"
+	ChangeGroupCreated      = "group_created" // Only in run results; the history holds user and membership changes
+)
+
+// TargetGoogleWorkspace is the Target of changes made to the Google Workspace enrollment group
+const TargetGoogleWorkspace = "google_workspace"
+
+// Change is one user or membership change applied by a sync run
+type Change struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	User   string    `json:"user"`             // User email, or the user ID when the email is unknown
+	Group  string    `json:"group,omitempty"`  // Source group email; empty for user changes
+	Target string    `json:"target,omitempty"` // Tenant the change was applied to
+}
+
+// SkippedUser is a user the engine does not try to provision, typically because they fail permanently
+type SkippedUser struct {
+	Email     string    `json:"email"`
+	Reason    string    `json:"reason"`
+	AddedBy   string    `json:"added_by,omitempty"`
+	AddedAt   time.Time `json:"added_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // Zero means the entry never expires
+}
+
+// Expired reports whether the entry no longer applies at now
+func (u SkippedUser) Expired(now time.Time) bool {
+	return !u.ExpiresAt.IsZero() && !now.Before(u.ExpiresAt)
+}
+
+// Run statuses
+const (
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+// RunErrorCrash is the error recorded for runs that were still running when the process died
+const RunErrorCrash = "crash"
+
+// runHistoryLimit is how many runs are kept in the journal
+const runHistoryLimit = 50
+
+// Run is a sync run recorded when it starts, so a run interrupted by a crash can be detected later
+type Run struct {
+	ID         string            `json:"id"`
+	Kind       string            `json:"kind"`              // full, groups or user
+	Subject    string            `json:"subject,omitempty"` // User email or group emails for targeted runs
+	Owner      string            `json:"owner"`             // host:pid of the process running it
+	Status     string            `json:"status"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	DryRun     bool              `json:"dry_run,omitempty"` // Requested as a dry run
+	Labels     map[string]string `json:"labels,omitempty"`  // Free-form labels given by the caller
+}
+
+// Checkpoint records the last successful runs, so incremental runs know which changes to read
+type Checkpoint struct {
+	LastSync     time.Time `json:"last_sync"`            // Start of the last successful run, full or incremental
+	LastFullSync time.Time `json:"last_full_sync"`       // Start of the last successful full run
+	RetryFull    bool      `json:"retry_full,omitempty"` // The last run had errors; the next one is full so failed groups are retried
+}
+
+// data is the persisted document
+type data struct {
+	Groups     map[string]*GroupState  `json:"groups"` // lower-cased source group email -> state
+	Changes    []Change                `json:"changes,omitempty"`
+	Skipped    map[string]*SkippedUser `json:"skipped_users,omitempty"` // lower-cased user email -> entry
+	Runs       []*Run                  `json:"runs,omitempty"`          // oldest first
+	Queue      []*DeferredOp           `json:"queue,omitempty"`         // oldest first
+	Checkpoint *Checkpoint             `json:"checkpoint,omitempty"`    // Last successful runs; see Checkpoint
+}
+
+// Store persists sync state between runs in a JSON file; an empty path keeps it in memory only
+type Store struct {
+	path string
+
+	mu   gosync.Mutex
+	data data
+}
+
+// Open loads the store at path, starting empty if the file does not exist yet
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	s.data.Groups = make(map[string]*GroupState)
+
+	if path == "" {
+		return s, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if s.data.Groups == nil {
+		s.data.Groups = make(map[string]*GroupState)
+	}
+	return s, nil
+}
+
+// Group returns a copy of the state of a source group
+func (s *Store) Group(email string) (GroupState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.data.Groups[strings.ToLower(email)]
+	if !ok {
+		return GroupState{}, false
+	}
+	return *group, true
+}
+
+// Groups returns a copy of the state of every source group, keyed by lower-cased email
+func (s *Store) Groups() map[string]GroupState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := make(map[string]GroupState, len(s.data.Groups))
+	for email, group := range s.data.Groups {
+		groups[email] = *group
+	}
+	return groups
+}
+
+// UpdateGroup applies update to the state of a source group, creating it if needed
+func (s *Store) UpdateGroup(email string, update func(group *GroupState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(email)
+	group, ok := s.data.Groups[key]
+	if !ok {
+		group = &GroupState{}
+		s.data.Groups[key] = group
+	}
+	update(group)
+}
+
+// RecordChanges appends changes to the history
+func (s *Store) RecordChanges(changes ...Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Changes = append(s.data.Changes, changes...)
+}
+
+// Changes returns the changes made at or after since and before until, oldest first; a
+// zero until means no upper bound
+func (s *Store) Changes(since, until time.Time) []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changes []Change
+	for _, change := range s.data.Changes {
+		if change.Time.Before(since) || (!until.IsZero() && !change.Time.Before(until)) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+	return changes
+}
+
+// SkipUser adds or replaces a skip-list entry, dropping entries that have expired
+func (s *Store) SkipUser(entry SkippedUser) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data.Skipped == nil {
+		s.data.Skipped = make(map[string]*SkippedUser)
+	}
+	for email, skipped := range s.data.Skipped {
+		if skipped.Expired(entry.AddedAt) {
+			delete(s.data.Skipped, email)
+		}
+	}
+	s.data.Skipped[strings.ToLower(entry.Email)] = &entry
+}
+
+// UnskipUser removes a user from the skip list, reporting whether they were on it
+func (s *Store) UnskipUser(email string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(email)
+	if _, ok := s.data.Skipped[key]; !ok {
+		return false
+	}
+	delete(s.data.Skipped, key)
+	return true
+}
+
+// SkippedUser returns the user's skip-list entry if it applies at now
+func (s *Store) SkippedUser(email string, now time.Time) (SkippedUser, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	skipped, ok := s.data.Skipped[strings.ToLower(email)]
+	if !ok || skipped.Expired(now) {
+		return SkippedUser{}, false
+	}
+	return *skipped, true
+}
+
+// SkippedUsers returns the skip-list entries that apply at now, sorted by email
+func (s *Store) SkippedUsers(now time.Time) []SkippedUser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := []SkippedUser{}
+	for _, skipped := range s.data.Skipped {
+		if !skipped.Expired(now) {
+			users = append(users, *skipped)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Email < users[j].Email
+	})
+	return users
+}
+
+// StartRun records a run as running
+func (s *Store) StartRun(run Run) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run.Status = RunStatusRunning
+	s.data.Runs = append(s.data.Runs, &run)
+	if len(s.data.Runs) > runHistoryLimit {
+		s.data.Runs = s.data.Runs[len(s.data.Runs)-runHistoryLimit:]
+	}
+}
+
+// FinishRun records the outcome of a run; a nil err marks it succeeded
+func (s *Store) FinishRun(id string, finished time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, run := range s.data.Runs {
+		if run.ID != id {
+			continue
+		}
+		run.FinishedAt = &finished
+		run.Status = RunStatusSucceeded
+		if err != nil {
+			run.Status = RunStatusFailed
+			run.Error = err.Error()
+		}
+		return
+	}
+}
+
+// FailInterruptedRuns marks every run still recorded as running as failed with RunErrorCrash
+// and returns them; only call it while no run can be in progress
+func (s *Store) FailInterruptedRuns(now time.Time) []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var interrupted []Run
+	for _, run := range s.data.Runs {
+		if run.Status != RunStatusRunning {
+			continue
+		}
+		run.Status = RunStatusFailed
+		run.Error = RunErrorCrash
+		run.FinishedAt = &now
+		interrupted = append(interrupted, *run)
+	}
+	return interrupted
+}
+
+// Runs returns the run journal, newest first
+func (s *Store) Runs() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]Run, 0, len(s.data.Runs))
+	for i := len(s.data.Runs) - 1; i >= 0; i-- {
+		runs = append(runs, *s.data.Runs[i])
+	}
+	return runs
+}
+
+// Checkpoint returns the last successful runs, if a run has succeeded yet
+func (s *Store) Checkpoint() (Checkpoint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data.Checkpoint == nil {
+		return Checkpoint{}, false
+	}
+	return *s.data.Checkpoint, true
+}
+
+// SetCheckpoint records the last successful runs
+func (s *Store) SetCheckpoint(checkpoint Checkpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Checkpoint = &checkpoint
+}
+
+// RemoveTempFiles deletes temporary files left next to the state file by a Save that was
+// interrupted, returning their paths; only call it while no other process can be saving
+func (s *Store) RemoveTempFiles() ([]string, error) {
+	if s.path == "" {
+		return nil, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(s.path), ".state-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list temporary state files: %w", err)
+	}
+
+	var removed []string
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove temporary state file: %w", err)
+		}
+		removed = append(removed, match)
+	}
+	return removed, nil
+}
+
+// Save writes the store atomically
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	raw, err := json.MarshalIndent(&s.data, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".state-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
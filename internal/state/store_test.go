@@ -0,0 +1,221 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	orphanedAt := time.Date(2024, 6, 30, 9, 0, 0, 0, time.UTC)
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := store.Group("eng@example.com"); ok {
+		t.Fatal("Expected empty store")
+	}
+
+	store.UpdateGroup("Eng@Example.com", func(group *GroupState) {
+		group.BIGroupID = "group-1"
+		group.Orphaned = true
+		group.OrphanedAt = orphanedAt
+	})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	group, ok := reopened.Group("eng@example.com")
+	if !ok || group.BIGroupID != "group-1" || !group.Orphaned || !group.OrphanedAt.Equal(orphanedAt) {
+		t.Errorf("Expected group state to round-trip, got %+v", group)
+	}
+}
+
+func TestStore_Checkpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	lastFull := time.Date(2024, 6, 30, 9, 0, 0, 0, time.UTC)
+	last := lastFull.Add(2 * time.Hour)
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := store.Checkpoint(); ok {
+		t.Fatal("Expected no checkpoint before the first run")
+	}
+
+	store.SetCheckpoint(Checkpoint{LastSync: last, LastFullSync: lastFull})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkpoint, ok := reopened.Checkpoint()
+	if !ok || !checkpoint.LastSync.Equal(last) || !checkpoint.LastFullSync.Equal(lastFull) {
+		t.Errorf("Expected checkpoint to round-trip, got %+v", checkpoint)
+	}
+}
+
+func TestStore_InMemory(t *testing.T) {
+	store, err := Open("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.UpdateGroup("eng@example.com", func(group *GroupState) { group.BIGroupID = "group-1" })
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := store.Group("eng@example.com"); !ok {
+		t.Error("Expected in-memory state to be kept")
+	}
+}
+
+func TestOpen_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("Expected error for corrupt state file")
+	}
+}
+
+func TestStore_Changes(t *testing.T) {
+	store, err := Open("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 12, 0, 0, 0, time.UTC) }
+	store.RecordChanges(
+		Change{Time: day(3), Action: ChangeMembershipAdded, User: "bob@example.com", Group: "eng@example.com"},
+		Change{Time: day(1), Action: ChangeUserCreated, User: "alice@example.com"},
+		Change{Time: day(5), Action: ChangeMembershipRemoved, User: "carol@example.com", Group: "eng@example.com"},
+	)
+
+	tests := []struct {
+		name   string
+		since  time.Time
+		until  time.Time
+		expect []string
+	}{
+		{name: "all changes", expect: []string{"alice@example.com", "bob@example.com", "carol@example.com"}},
+		{name: "since only", since: day(2), expect: []string{"bob@example.com", "carol@example.com"}},
+		{name: "window", since: day(2), until: day(5), expect: []string{"bob@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := store.Changes(tt.since, tt.until)
+			if len(changes) != len(tt.expect) {
+				t.Fatalf("Expected %d changes, got %+v", len(tt.expect), changes)
+			}
+			for i, user := range tt.expect {
+				if changes[i].User != user {
+					t.Errorf("Expected change %d for %s, got %s", i, user, changes[i].User)
+				}
+			}
+		})
+	}
+}
+
+func TestStore_SkippedUsers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	store.SkipUser(SkippedUser{Email: "Bad@example.com", Reason: "invalid email", AddedAt: now})
+	store.SkipUser(SkippedUser{Email: "temp@example.com", Reason: "blocked domain", AddedAt: now, ExpiresAt: now.Add(time.Hour)})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if skipped, ok := reopened.SkippedUser("bad@example.com", now); !ok || skipped.Reason != "invalid email" {
+		t.Errorf("Expected bad@example.com to be skipped, got %+v, %v", skipped, ok)
+	}
+	if got := reopened.SkippedUsers(now); len(got) != 2 {
+		t.Errorf("Expected 2 skipped users, got %+v", got)
+	}
+
+	later := now.Add(2 * time.Hour)
+	if _, ok := reopened.SkippedUser("temp@example.com", later); ok {
+		t.Error("Expected the expired entry not to apply")
+	}
+	if got := reopened.SkippedUsers(later); len(got) != 1 {
+		t.Errorf("Expected 1 skipped user after expiry, got %+v", got)
+	}
+
+	if !reopened.UnskipUser("BAD@example.com") {
+		t.Error("Expected bad@example.com to be removed")
+	}
+	if reopened.UnskipUser("bad@example.com") {
+		t.Error("Expected a second removal to report false")
+	}
+}
+
+func TestStore_FailInterruptedRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	started := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	store.StartRun(Run{ID: "done", Kind: "full", StartedAt: started})
+	store.FinishRun("done", started.Add(time.Minute), nil)
+	store.StartRun(Run{ID: "crashed", Kind: "user", Subject: "alice@example.com", StartedAt: started.Add(time.Hour)})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A Save interrupted before the rename leaves its temporary file behind
+	leftover := filepath.Join(filepath.Dir(path), ".state-123.json")
+	if err := os.WriteFile(leftover, []byte("{"), 0600); err != nil {
+		t.Fatalf("Failed to write temporary file: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	interrupted := reopened.FailInterruptedRuns(started.Add(2 * time.Hour))
+	if len(interrupted) != 1 || interrupted[0].ID != "crashed" || interrupted[0].Error != RunErrorCrash {
+		t.Errorf("Expected the unfinished run to be marked crashed, got %+v", interrupted)
+	}
+
+	runs := reopened.Runs()
+	if len(runs) != 2 || runs[0].Status != RunStatusFailed || runs[1].Status != RunStatusSucceeded {
+		t.Errorf("Expected the journal newest first with both runs finished, got %+v", runs)
+	}
+	if again := reopened.FailInterruptedRuns(started.Add(3 * time.Hour)); len(again) != 0 {
+		t.Errorf("Expected no runs left to recover, got %+v", again)
+	}
+
+	removed, err := reopened.RemoveTempFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != leftover {
+		t.Errorf("Expected %s to be removed, got %v", leftover, removed)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the state file to be kept: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+)
+
+// userGetter is implemented by targets that can look a user up by ID, used to resolve member emails
+type userGetter interface {
+	GetUser(ctx context.Context, userID string) (*bi.User, error)
+}
+
+// AccessReview lists the current members of every group the engine has provisioned, with the
+// source group it mirrors and when it was last synced; orphaned groups are left out
+func (e *Engine) AccessReview(ctx context.Context) ([]report.AccessReviewGroup, error) {
+	var sourceGroups []string
+	groups := e.state.Groups()
+	for email, group := range groups {
+		if !group.Orphaned && group.BIGroupID != "" {
+			sourceGroups = append(sourceGroups, email)
+		}
+	}
+	sort.Strings(sourceGroups)
+
+	emails := make(map[string]string) // target + user ID -> email
+	review := make([]report.AccessReviewGroup, 0, len(sourceGroups))
+
+	for _, email := range sourceGroups {
+		group := groups[email]
+		client, err := e.clientForTarget(group.Target)
+		if err != nil {
+			return nil, err
+		}
+
+		biGroup, err := client.GetGroupWithMembers(ctx, group.BIGroupID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get members of %s: %w", group.BIGroupName, err)
+		}
+
+		entry := report.AccessReviewGroup{
+			SourceGroup:  email,
+			Target:       group.Target,
+			GroupID:      group.BIGroupID,
+			GroupName:    group.BIGroupName,
+			LastVerified: group.LastSynced,
+			Members:      make([]report.AccessReviewMember, 0, len(biGroup.Members)),
+		}
+		for _, member := range biGroup.Members {
+			key := group.Target + "\x00" + member.Value
+			userEmail, ok := emails[key]
+			if !ok {
+				userEmail = e.memberEmail(ctx, client, member)
+				emails[key] = userEmail
+			}
+			entry.Members = append(entry.Members, report.AccessReviewMember{UserID: member.Value, Email: userEmail})
+		}
+
+		sort.Slice(entry.Members, func(i, j int) bool {
+			if entry.Members[i].Email != entry.Members[j].Email {
+				return entry.Members[i].Email < entry.Members[j].Email
+			}
+			return entry.Members[i].UserID < entry.Members[j].UserID
+		})
+		review = append(review, entry)
+	}
+
+	return review, nil
+}
+
+// memberEmail resolves the email of a group member, falling back to the name the API returned
+func (e *Engine) memberEmail(ctx context.Context, client BIClient, member bi.GroupMember) string {
+	getter, ok := client.(userGetter)
+	if !ok {
+		return memberIdentity(member)
+	}
+
+	user, err := getter.GetUser(ctx, member.Value)
+	if err != nil {
+		e.logger.Warnf("Failed to look up user %s: %v", member.Value, err)
+		return memberIdentity(member)
+	}
+	for _, email := range user.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if user.UserName != "" {
+		return user.UserName
+	}
+	return memberIdentity(member)
+}
@@ -0,0 +1,54 @@
+package sync
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// Roles of Google group members that sync.admin_groups provisions into the admins group
+const (
+	memberRoleOwner   = "OWNER"
+	memberRoleManager = "MANAGER"
+)
+
+// adminGroupName returns the name of the Beyond Identity group holding the owners and managers of
+// a source group, e.g. GWS_Engineering_Admins
+func adminGroupName(prefix, sourceName string) string {
+	return prefix + sourceName + config.AdminGroupSuffix
+}
+
+// syncAdminGroup provisions the admins group of a synced group with the members the group's
+// owners and managers were synced as, when sync.admin_groups is set. Like the group itself, its
+// members are only added while the source membership is incomplete
+func (e *Engine) syncAdminGroup(ctx context.Context, biClient BIClient, targetName, groupEmail string, gwsGroup *gws.Group, gwsMembers []*gws.GroupMember, users map[string]string, result *SyncResult) {
+	admins := make(map[string]bool)
+	for _, member := range gwsMembers {
+		if member.Role == memberRoleOwner || member.Role == memberRoleManager {
+			admins[strings.ToLower(member.Email)] = true
+		}
+	}
+	adminUsers := make(map[string]string, len(admins))
+	for userID, email := range users {
+		if admins[strings.ToLower(email)] {
+			adminUsers[userID] = email
+		}
+	}
+
+	groupName := adminGroupName(e.config.GroupPrefixForTarget(targetName), gwsGroup.Name)
+	biGroup, err := e.ensureBIGroup(ctx, biClient, targetName, "", groupName, "Owners and managers of "+groupEmail, result)
+	if err == nil {
+		if biGroup.DisplayName != "" {
+			groupName = biGroup.DisplayName
+		}
+		err = e.updateGroupMembership(ctx, biClient, groupEmail, targetName, biGroup.ID, groupName, adminUsers, result)
+	}
+	if err != nil {
+		e.logger.Errorf("Failed to sync admins group %s: %v", groupName, err)
+		e.addError(result, "group", groupEmail, err)
+		return
+	}
+	e.annotateGroup(ctx, biClient, groupEmail, targetName, biGroup.ID, result)
+}
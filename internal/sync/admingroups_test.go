@@ -0,0 +1,60 @@
+package sync
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSync_AdminGroups(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.config.Sync.AdminGroups = true
+	gwsClient.members["eng@example.com"][0].Role = memberRoleOwner
+	gwsClient.members["eng@example.com"][1].Role = "MEMBER"
+
+	if _, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"}); err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	alice, _ := biClient.FindUserByEmail(context.Background(), "alice@example.com")
+	admins, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering_Admins")
+	if admins == nil || len(admins.Members) != 1 || admins.Members[0].Value != alice.ID {
+		t.Fatalf("Expected GWS_Engineering_Admins with only the owner, got %+v", admins)
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering"); len(group.Members) != 2 {
+		t.Errorf("Expected GWS_Engineering to keep every member, got %+v", group.Members)
+	}
+
+	// Alice steps down and Bob becomes a manager
+	gwsClient.members["eng@example.com"][0].Role = "MEMBER"
+	gwsClient.members["eng@example.com"][1].Role = memberRoleManager
+	if _, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"}); err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	bob, _ := biClient.FindUserByEmail(context.Background(), "bob@example.com")
+	admins, _ = biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering_Admins")
+	if len(admins.Members) != 1 || admins.Members[0].Value != bob.ID {
+		t.Errorf("Expected the admins group to follow role changes, got %+v", admins.Members)
+	}
+
+	// The admins group is synced, so it is never cleaned up as an orphan
+	orphaned, err := engine.FindOrphanedGroups(context.Background())
+	if err != nil {
+		t.Fatalf("FindOrphanedGroups() error = %v", err)
+	}
+	for _, group := range orphaned {
+		if group.Name == "GWS_Engineering_Admins" {
+			t.Errorf("Expected the admins group not to be orphaned, got %+v", orphaned)
+		}
+	}
+}
+
+func TestSync_AdminGroupsDisabled(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	gwsClient.members["eng@example.com"][0].Role = memberRoleOwner
+
+	if _, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"}); err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	if admins, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering_Admins"); admins != nil {
+		t.Errorf("Expected no admins group without sync.admin_groups, got %+v", admins)
+	}
+}
@@ -0,0 +1,126 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errAliasesUnsupported is returned when the Google Workspace client cannot list group aliases
+var errAliasesUnsupported = errors.New("the membership source has no group aliases")
+
+// aliasLister is implemented by sources that can list the alias addresses of a group
+type aliasLister interface {
+	GetGroupAliases(ctx context.Context, groupEmail string) ([]string, error)
+}
+
+// GetGroupAliases implements aliasLister, failing when the current client does not
+func (r *rotatingGWSClient) GetGroupAliases(ctx context.Context, groupEmail string) ([]string, error) {
+	var aliases []string
+	err := r.do(func(client GWSClient) error {
+		lister, ok := client.(aliasLister)
+		if !ok {
+			return errAliasesUnsupported
+		}
+		var err error
+		aliases, err = lister.GetGroupAliases(ctx, groupEmail)
+		return err
+	})
+	return aliases, err
+}
+
+// GetGroupAliases implements aliasLister, failing when the admins' clients do not
+func (d *delegatingGWSClient) GetGroupAliases(ctx context.Context, groupEmail string) ([]string, error) {
+	var aliases []string
+	err := d.do(groupEmail, func(client GWSClient) error {
+		lister, ok := client.(aliasLister)
+		if !ok {
+			return errAliasesUnsupported
+		}
+		var err error
+		aliases, err = lister.GetGroupAliases(ctx, groupEmail)
+		return err
+	})
+	return aliases, err
+}
+
+// resolveAliases maps configured alias addresses to their canonical groups when sync.aliases.resolve
+// is set, and drops addresses of a group that is already synced under another configured address.
+// Groups keep their configured address for targets, state and the skip list
+func (e *Engine) resolveAliases(ctx context.Context, groupEmails []string, result *SyncResult) []string {
+	if !e.config.Sync.Aliases.Resolve {
+		return groupEmails
+	}
+
+	result.sourceEmails = make(map[string]string)
+	resolved := make([]string, 0, len(groupEmails))
+	synced := make(map[string]string) // Canonical address -> configured address syncing it
+	for _, groupEmail := range groupEmails {
+		// Groups that cannot be read here fail with the same error when they are synced
+		canonical := groupEmail
+		if group, err := e.sourceGroup(ctx, groupEmail); err == nil && group.Email != "" {
+			canonical = group.Email
+		}
+
+		if first, ok := synced[strings.ToLower(canonical)]; ok {
+			e.logger.Warnf("Group %s is the same group as %s (%s); syncing it once", groupEmail, first, canonical)
+			result.explainGroup(groupEmail, e.config.TargetForGroup(groupEmail), "not synced: same group as "+first)
+			continue
+		}
+		synced[strings.ToLower(canonical)] = groupEmail
+
+		if !strings.EqualFold(canonical, groupEmail) {
+			e.logger.Infof("Group alias %s resolves to %s", groupEmail, canonical)
+			result.sourceEmails[groupEmail] = canonical
+		}
+		resolved = append(resolved, groupEmail)
+	}
+	return resolved
+}
+
+// sourceEmail returns the address a configured group is read from the source with
+func (r *SyncResult) sourceEmail(groupEmail string) string {
+	if canonical, ok := r.sourceEmails[groupEmail]; ok {
+		return canonical
+	}
+	return groupEmail
+}
+
+// syncAliasGroups provisions a Beyond Identity group named after each alias of a group, with the
+// same members as the group, when sync.aliases.create_groups is set
+func (e *Engine) syncAliasGroups(ctx context.Context, biClient BIClient, targetName, groupEmail string, users map[string]string, result *SyncResult) {
+	lister, ok := e.source.(aliasLister)
+	var aliases []string
+	err := errAliasesUnsupported
+	if ok {
+		aliases, err = lister.GetGroupAliases(ctx, groupEmail)
+	}
+	if errors.Is(err, errAliasesUnsupported) {
+		if result.runFlags().setOnce(flagAliasesUnsupported) {
+			e.logger.Warnf("sync.aliases.create_groups is set but %v", err)
+		}
+		return
+	}
+	if err != nil {
+		e.logger.Errorf("Failed to list aliases of group %s: %v", groupEmail, err)
+		e.addError(result, "group", groupEmail, fmt.Errorf("failed to list aliases: %w", err))
+		return
+	}
+
+	prefix := e.config.GroupPrefixForTarget(targetName)
+	for _, alias := range aliases {
+		groupName := prefix + alias
+		biGroup, err := e.ensureBIGroup(ctx, biClient, targetName, "", groupName, "", result)
+		if err == nil {
+			if biGroup.DisplayName != "" {
+				groupName = biGroup.DisplayName
+			}
+			err = e.updateGroupMembership(ctx, biClient, alias, targetName, biGroup.ID, groupName, users, result)
+		}
+		if err != nil {
+			e.logger.Errorf("Failed to sync alias group %s: %v", groupName, err)
+			e.addError(result, "group", alias, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// aliasGWSClient resolves eng-team@example.com to eng@example.com like the Directory API does,
+// but lists members only under the canonical address
+type aliasGWSClient struct {
+	*mockGWSClient
+	aliases    map[string][]string
+	aliasesErr error
+}
+
+func (c *aliasGWSClient) GetGroupAliases(ctx context.Context, groupEmail string) ([]string, error) {
+	if c.aliasesErr != nil {
+		return nil, c.aliasesErr
+	}
+	return c.aliases[groupEmail], nil
+}
+
+func newAliasTestEngine(groups ...string) (*Engine, *aliasGWSClient, *mockBIClient) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	gwsClient.groups["eng@example.com"].Email = "eng@example.com"
+	gwsClient.groups["sales@example.com"].Email = "sales@example.com"
+	gwsClient.groups["eng-team@example.com"] = gwsClient.groups["eng@example.com"]
+
+	source := &aliasGWSClient{
+		mockGWSClient: gwsClient,
+		aliases:       map[string][]string{"eng@example.com": {"eng-team@example.com"}},
+	}
+	engine.SetSource(source)
+	engine.config.Sync.Groups = groups
+	return engine, source, biClient
+}
+
+func TestSync_ResolveAliases(t *testing.T) {
+	tests := []struct {
+		name            string
+		resolve         bool
+		groups          []string
+		expectProcessed int
+		expectMembers   int // Members of GWS_Engineering
+	}{
+		{"alias read through its canonical group", true, []string{"eng-team@example.com"}, 1, 2},
+		{"alias and canonical synced once", true, []string{"eng-team@example.com", "eng@example.com", "sales@example.com"}, 2, 2},
+		{"unresolved alias has no members", false, []string{"eng-team@example.com"}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, biClient := newAliasTestEngine(tt.groups...)
+			engine.config.Sync.Aliases.Resolve = tt.resolve
+
+			result, err := engine.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.GroupsProcessed != tt.expectProcessed || len(result.Errors) != 0 {
+				t.Errorf("Expected %d groups processed without errors, got %d and %v", tt.expectProcessed, result.GroupsProcessed, result.Errors)
+			}
+
+			group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+			if group == nil || len(group.Members) != tt.expectMembers {
+				t.Errorf("Expected GWS_Engineering with %d members, got %+v", tt.expectMembers, group)
+			}
+		})
+	}
+}
+
+func TestSync_AliasGroups(t *testing.T) {
+	engine, source, biClient := newAliasTestEngine("eng@example.com", "sales@example.com")
+	engine.config.Sync.Aliases.CreateGroups = true
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	canonical, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	alias, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_eng-team@example.com")
+	if alias == nil || canonical == nil || !sameMembers(alias.Members, canonical.Members) || len(alias.Members) != 2 {
+		t.Errorf("Expected the alias group to have the members of GWS_Engineering, got %+v and %+v", alias, canonical)
+	}
+	if result.GroupsCreated != 3 {
+		t.Errorf("Expected 3 groups created, got %d", result.GroupsCreated)
+	}
+
+	// Failing to list aliases is reported without failing the group itself
+	engine, source, _ = newAliasTestEngine("eng@example.com")
+	engine.config.Sync.Aliases.CreateGroups = true
+	source.aliasesErr = errors.New("forbidden")
+
+	result, err = engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsProcessed != 1 || len(result.Errors) != 1 {
+		t.Errorf("Expected the group processed with one alias error, got %d and %v", result.GroupsProcessed, result.Errors)
+	}
+}
+
+func TestSync_AliasGroupsThroughWrappers(t *testing.T) {
+	engine, source, biClient := newAliasTestEngine("eng@example.com", "sales@example.com")
+	engine.config.Sync.Aliases.CreateGroups = true
+	engine.config.GoogleWorkspace.SuperAdminEmail = "admin@example.com"
+	engine.config.GoogleWorkspace.FallbackAdminEmails = []string{"backup@example.com"}
+
+	// Production sources are wrapped for key rotation and admin failover
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	engine.SetSource(newDelegatingGWSClient(engine.config.GoogleWorkspace.AdminSubjects, func(subject string) (GWSClient, error) {
+		return newRotatingGWSClient([]string{"key.json"}, func(keyPath string) (GWSClient, error) {
+			return source, nil
+		}, logger)
+	}, logger))
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	if alias, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_eng-team@example.com"); alias == nil {
+		t.Fatal("Expected the alias group to be created through the wrapped source")
+	}
+
+	// Cleanup keeps the alias group
+	orphaned, err := engine.FindOrphanedGroups(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, group := range orphaned {
+		if group.Name == "GWS_eng-team@example.com" {
+			t.Errorf("Expected the alias group not to be orphaned, got %+v", group)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// allUsersKey is the group key standing in for the domain's users with sync.all_users, wherever
+// configured group addresses are used
+const allUsersKey = "all-users"
+
+// errAllUsersUnsupported is returned when the Google Workspace client cannot list the domain's users
+var errAllUsersUnsupported = errors.New("listing the domain's users needs the Admin SDK (google_workspace.api: admin_sdk)")
+
+// GetUsers implements userLister when the admins' clients do
+func (d *delegatingGWSClient) GetUsers(ctx context.Context) ([]*gws.User, error) {
+	var users []*gws.User
+	err := d.do("", func(client GWSClient) error {
+		lister, ok := client.(userLister)
+		if !ok {
+			return nil
+		}
+		var err error
+		users, err = lister.GetUsers(ctx)
+		return err
+	})
+	return users, err
+}
+
+// isDirectoryGroup reports whether a group key stands for users read from the directory, an org
+// unit or the whole domain, rather than for a group
+func isDirectoryGroup(groupEmail string) bool {
+	_, isOrgUnit := orgUnitPath(groupEmail)
+	return isOrgUnit || groupEmail == allUsersKey
+}
+
+// allUsersMembers returns the domain's active, non-archived users as members of the
+// sync.all_users group
+func (e *Engine) allUsersMembers(ctx context.Context) ([]*gws.GroupMember, error) {
+	lister, ok := e.gwsClient.(userLister)
+	if !ok {
+		return nil, errAllUsersUnsupported
+	}
+	users, err := lister.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Every domain has at least its admin, so an empty listing means the client could not list
+	// users; syncing it would empty the group
+	if len(users) == 0 {
+		return nil, errAllUsersUnsupported
+	}
+	return activeMembers(users), nil
+}
+
+// activeMembers converts directory users to group members, leaving out suspended and archived users
+func activeMembers(users []*gws.User) []*gws.GroupMember {
+	members := make([]*gws.GroupMember, 0, len(users))
+	for _, user := range users {
+		if user.Suspended || user.Archived {
+			continue
+		}
+		members = append(members, &gws.GroupMember{
+			ID:     user.ID,
+			Email:  user.PrimaryEmail,
+			Role:   "MEMBER",
+			Type:   "USER",
+			Status: "ACTIVE",
+		})
+	}
+	return members
+}
@@ -0,0 +1,51 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+func TestSync_AllUsers(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.config.Sync.Groups = nil
+	engine.config.Sync.AllUsers = true
+	engine.config.Sync.AllUsersGroup = "Everyone"
+	engine.gwsClient = &listingGWSClient{directoryGWSClient: &directoryGWSClient{
+		mockGWSClient: gwsClient,
+		users: map[string]*gws.User{
+			"alice@example.com": {ID: "1", PrimaryEmail: "alice@example.com"},
+			"bob@example.com":   {ID: "2", PrimaryEmail: "bob@example.com", Suspended: true},
+			"carol@example.com": {ID: "3", PrimaryEmail: "carol@example.com", Archived: true},
+			"dave@example.com":  {ID: "4", PrimaryEmail: "dave@example.com"},
+		},
+	}}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsProcessed != 1 || len(result.Errors) != 0 {
+		t.Fatalf("Expected the all users group to be synced, got %d groups and errors %v", result.GroupsProcessed, result.Errors)
+	}
+	group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Everyone")
+	if group == nil || len(group.Members) != 2 {
+		t.Errorf("Expected a group with the active users, got %+v", group)
+	}
+}
+
+func TestSync_AllUsersEmptyListing(t *testing.T) {
+	engine, gwsClient, _ := newTargetedTestEngine()
+	engine.config.Sync.Groups = nil
+	engine.config.Sync.AllUsers = true
+	engine.gwsClient = &listingGWSClient{directoryGWSClient: &directoryGWSClient{mockGWSClient: gwsClient}}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected an empty listing to fail the group rather than empty it, got %v", result.Errors)
+	}
+}
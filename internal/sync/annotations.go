@@ -0,0 +1,53 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// groupAnnotationManager names this integration in group annotations
+const groupAnnotationManager = "scim-sync"
+
+// groupAnnotator is implemented by Beyond Identity clients that can mark groups as managed
+type groupAnnotator interface {
+	AnnotateGroup(ctx context.Context, groupID string, annotation bi.GroupAnnotation) error
+}
+
+// SetVersion sets the build written to group annotations with sync.annotate_groups
+func (e *Engine) SetVersion(version string) {
+	e.version = version
+}
+
+// annotateGroup marks a synced Beyond Identity group as managed by the sync, with its source group
+// and the time of the sync, when sync.annotate_groups is set. Failures are only logged: once a
+// target rejects an annotation its groups are not annotated for the rest of the run
+func (e *Engine) annotateGroup(ctx context.Context, biClient BIClient, groupEmail, targetName, groupID string, result *SyncResult) {
+	if !e.config.Sync.AnnotateGroups || isPlannedID(groupID) || result.runFlags().isSet(flagAnnotationsFailed+targetName) {
+		return
+	}
+	annotator, ok := biClient.(groupAnnotator)
+	if !ok {
+		if result.runFlags().setOnce(flagAnnotationsFailed + targetName) {
+			e.logger.Warnf("sync.annotate_groups is set but target %s cannot annotate groups", targetName)
+		}
+		return
+	}
+	if e.simulated(config.OperationUpdate) {
+		e.logger.Debugf("TEST MODE: Would annotate group %s as synced from %s", groupID, groupEmail)
+		return
+	}
+
+	annotation := bi.GroupAnnotation{
+		ManagedBy:   groupAnnotationManager,
+		Version:     e.version,
+		SourceGroup: groupEmail,
+		LastSynced:  e.now().UTC(),
+	}
+	if err := annotator.AnnotateGroup(ctx, groupID, annotation); err != nil {
+		if result.runFlags().setOnce(flagAnnotationsFailed + targetName) {
+			e.logger.Warnf("Failed to annotate groups in target %s, skipping annotations for this run: %v", targetName, err)
+		}
+	}
+}
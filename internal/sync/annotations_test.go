@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+// annotatingBIClient records the annotations written to groups
+type annotatingBIClient struct {
+	*mockBIClient
+	annotations map[string]bi.GroupAnnotation
+	calls       int
+	err         error
+}
+
+func (c *annotatingBIClient) AnnotateGroup(ctx context.Context, groupID string, annotation bi.GroupAnnotation) error {
+	c.calls++
+	if c.err != nil {
+		return c.err
+	}
+	c.annotations[groupID] = annotation
+	return nil
+}
+
+func TestSync_AnnotateGroups(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	client := &annotatingBIClient{mockBIClient: biClient, annotations: make(map[string]bi.GroupAnnotation)}
+	engine.biClient = client
+	engine.SetVersion("1.2.3")
+
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.calls != 0 {
+		t.Errorf("Expected no annotations unless sync.annotate_groups is set, got %d", client.calls)
+	}
+
+	engine.config.Sync.AnnotateGroups = true
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	annotation, ok := client.annotations[group.ID]
+	if !ok || annotation.SourceGroup != "eng@example.com" || annotation.Version != "1.2.3" || annotation.ManagedBy != groupAnnotationManager || annotation.LastSynced.IsZero() {
+		t.Errorf("Expected the group to be annotated with its source group, got %+v", annotation)
+	}
+	if len(client.annotations) != 2 {
+		t.Errorf("Expected both groups to be annotated, got %d", len(client.annotations))
+	}
+}
+
+func TestSync_AnnotateGroupsRejected(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	client := &annotatingBIClient{mockBIClient: biClient, err: errors.New("HTTP 400: unknown schema")}
+	engine.biClient = client
+	engine.config.Sync.AnnotateGroups = true
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("Expected annotations to stop after the first rejection, got %d attempts", client.calls)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected a rejected annotation not to fail the sync, got %v", result.Errors)
+	}
+}
@@ -0,0 +1,359 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// ErrStalePlan is returned when a plan was made with a different sync configuration than the
+// engine's, so its group names and targets may no longer mean what they did
+var ErrStalePlan = errors.New("plan was made with a different sync configuration")
+
+// PlanHash fingerprints the parts of the configuration a plan depends on: the synced groups,
+// where they are provisioned and under which names, and the enrollment group
+func (e *Engine) PlanHash() string {
+	prefixes := map[string]string{config.DefaultTargetName: e.config.GroupPrefixForTarget(config.DefaultTargetName)}
+	for _, target := range e.config.Targets {
+		prefixes[target.Name] = e.config.GroupPrefixForTarget(target.Name)
+	}
+
+	allUsersGroup := ""
+	if e.config.Sync.AllUsers {
+		allUsersGroup = e.config.Sync.AllUsersGroup
+	}
+
+	data, _ := json.Marshal(struct {
+		Groups          []string
+		GroupTargets    map[string]string
+		Prefixes        map[string]string
+		EnrollmentGroup string
+		OrgUnits        []string `json:",omitempty"`
+		AllUsersGroup   string   `json:",omitempty"`
+	}{e.config.Sync.Groups, e.config.Sync.GroupTargets, prefixes, e.config.Sync.EnrollmentGroupEmail, e.config.Sync.OrgUnits, allUsersGroup})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ApplyPlan makes exactly the changes listed in a plan written by scim-sync plan, without reading
+// the source again. Changes already made, e.g. by a run since the plan, are skipped, so applying a
+// plan twice is harmless. Plans made with another configuration, or that are incomplete, are refused
+func (e *Engine) ApplyPlan(ctx context.Context, plan *report.DryRun) (*SyncResult, error) {
+	if plan.ConfigHash != e.PlanHash() {
+		return nil, fmt.Errorf("%w (plan %q, current %q); run scim-sync plan again", ErrStalePlan, plan.ConfigHash, e.PlanHash())
+	}
+	if len(plan.Errors) > 0 {
+		return nil, fmt.Errorf("plan is incomplete (%d errors); fix them and run scim-sync plan again", len(plan.Errors))
+	}
+	if op := e.simulatedPlanOperation(plan); op != "" {
+		return nil, fmt.Errorf("plan needs the %s operation, which test mode, read-only mode or sync.test_mode_operations only simulate", op)
+	}
+
+	ctx, finish, err := e.beginRun(ctx, RunKindApply, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	e.applyPlan(ctx, plan, result)
+	if err := e.state.Save(); err != nil {
+		e.logger.Errorf("Failed to save sync state: %v", err)
+	}
+	if result.Aborted {
+		err = fmt.Errorf("apply aborted: %s", result.AbortReason)
+	}
+	finish(result, err)
+	return result, err
+}
+
+// simulatedPlanOperation returns an operation the plan needs that would only be logged, if any
+func (e *Engine) simulatedPlanOperation(plan *report.DryRun) string {
+	if (len(plan.UsersToCreate) > 0 || len(plan.GroupsToCreate) > 0) && e.simulated(config.OperationCreate) {
+		return config.OperationCreate
+	}
+	for _, membership := range plan.Memberships {
+		if len(membership.Add) > 0 && e.simulated(config.OperationAdd) {
+			return config.OperationAdd
+		}
+		if len(membership.Remove) > 0 && e.simulated(config.OperationRemove) {
+			return config.OperationRemove
+		}
+	}
+	return ""
+}
+
+func (e *Engine) applyPlan(ctx context.Context, plan *report.DryRun, result *SyncResult) {
+	userIDs := make(map[string]string) // Target + lowercased email -> ID of the users created
+
+	for _, planned := range plan.UsersToCreate {
+		if e.runCancelled(ctx, result) {
+			return
+		}
+		id, err := e.applyUser(ctx, planned, result)
+		if err != nil {
+			e.addError(result, "user", planned.Email, err)
+		}
+		// Empty when the user failed or was left out by sync.conflict_policy, so they get no memberships
+		userIDs[planned.Target+"\x00"+strings.ToLower(planned.Email)] = id
+	}
+
+	for _, planned := range plan.GroupsToCreate {
+		if e.runCancelled(ctx, result) {
+			return
+		}
+		if err := e.applyGroup(ctx, planned, result); err != nil {
+			e.addError(result, "group", planned.Name, err)
+		}
+	}
+
+	for _, membership := range plan.Memberships {
+		if e.nextGroup(ctx, result) {
+			return
+		}
+		var err error
+		if membership.Target == state.TargetGoogleWorkspace {
+			err = e.applyEnrollmentMembership(ctx, membership, result)
+		} else {
+			err = e.applyMembership(ctx, membership, userIDs, result)
+		}
+		if err != nil {
+			e.addError(result, "group", membership.Group, err)
+		}
+	}
+}
+
+// applyUser creates a planned user unless they already exist, returning their ID, or no ID when
+// an existing user is left out by sync.conflict_policy
+func (e *Engine) applyUser(ctx context.Context, planned report.PlannedUser, result *SyncResult) (string, error) {
+	client, err := e.clientForTarget(planned.Target)
+	if err != nil {
+		return "", err
+	}
+	id, exists, err := e.findAppliedUser(ctx, client, planned.Target, planned.Email, result)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		e.logger.Infof("User %s already exists in target %s, skipping", planned.Email, planned.Target)
+		return id, nil
+	}
+
+	user, err := e.newBIUser(ctx, planned.Email, result)
+	if err != nil {
+		return "", err
+	}
+	var created *bi.User
+	if err := e.retry(ctx, func() error {
+		var err error
+		created, err = client.CreateUser(ctx, user)
+		return err
+	}); err != nil {
+		return "", fmt.Errorf("failed to create user: %w", err)
+	}
+	e.userCreated(planned.Target, planned.Email, created.ID, result)
+	return created.ID, nil
+}
+
+// findAppliedUser looks up a user the way a sync does, by Google user ID first and email second,
+// and applies sync.conflict_policy to them when they exist. The ID is empty when the policy
+// leaves the user out
+func (e *Engine) findAppliedUser(ctx context.Context, client BIClient, targetName, email string, result *SyncResult) (id string, exists bool, err error) {
+	existing, err := e.reconcileBIUser(ctx, client, targetName, email, result)
+	if err != nil || existing == nil {
+		return "", false, err
+	}
+	id, _, err = e.resolveConflict(ctx, client, targetName, email, existing, result)
+	return id, true, err
+}
+
+// applyGroup creates a planned group unless it already exists, and records it in the state file
+// as the group its source group is synced to
+func (e *Engine) applyGroup(ctx context.Context, planned report.PlannedGroup, result *SyncResult) error {
+	client, err := e.clientForTarget(planned.Target)
+	if err != nil {
+		return err
+	}
+	existing, err := client.FindGroupByDisplayName(ctx, planned.Name)
+	if err != nil {
+		return fmt.Errorf("failed to search for group: %w", err)
+	}
+	if existing != nil {
+		e.logger.Infof("Group %s already exists in target %s, skipping", planned.Name, planned.Target)
+		if planned.GroupEmail != "" {
+			e.recordGroup(planned.GroupEmail, planned.Target, existing.ID, planned.Name)
+		}
+		return nil
+	}
+
+	var created *bi.Group
+	if err := e.retry(ctx, func() error {
+		var err error
+		created, err = client.CreateGroup(ctx, &bi.Group{DisplayName: planned.Name})
+		return err
+	}); err != nil {
+		e.recordFailedChange(result, report.ChangeRecord{Action: state.ChangeGroupCreated, Group: planned.Name, Target: planned.Target}, err)
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+	result.GroupsCreated++
+	e.recordChange(result, report.ChangeRecord{Action: state.ChangeGroupCreated, ID: created.ID, Group: planned.Name, Target: planned.Target})
+	e.logger.Infof("Created group: %s (ID: %s)", planned.Name, created.ID)
+	if planned.GroupEmail != "" {
+		e.recordGroup(planned.GroupEmail, planned.Target, created.ID, planned.Name)
+	}
+	return nil
+}
+
+// applyMembership adds and removes the planned members of a Beyond Identity group, leaving out
+// users who already are, or no longer are, members
+func (e *Engine) applyMembership(ctx context.Context, membership report.PlannedMembership, userIDs map[string]string, result *SyncResult) error {
+	targetName := membership.Target
+	if targetName == "" {
+		targetName = config.DefaultTargetName
+	}
+	client, err := e.clientForTarget(targetName)
+	if err != nil {
+		return err
+	}
+	group, err := client.FindGroupByDisplayName(ctx, membership.Group)
+	if err != nil {
+		return fmt.Errorf("failed to search for group: %w", err)
+	}
+	if group == nil {
+		return fmt.Errorf("group %s not found in target %s", membership.Group, targetName)
+	}
+	current, err := client.GetGroupWithMembers(ctx, group.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get current group members: %w", err)
+	}
+	isMember := make(map[string]bool)
+	for _, member := range current.Members {
+		isMember[member.Value] = true
+	}
+
+	var toAdd, toRemove []bi.GroupMember
+	var added, removed []string
+	for _, email := range membership.Add {
+		id, ok := userIDs[targetName+"\x00"+strings.ToLower(email)]
+		if !ok {
+			var exists bool
+			id, exists, err = e.findAppliedUser(ctx, client, targetName, email, result)
+			if err != nil {
+				e.addError(result, "user", email, err)
+				continue
+			}
+			if !exists {
+				e.addError(result, "user", email, fmt.Errorf("user not found in target %s", targetName))
+				continue
+			}
+		}
+		if id == "" || isMember[id] {
+			continue
+		}
+		isMember[id] = true
+		toAdd = append(toAdd, bi.GroupMember{Value: id})
+		added = append(added, email)
+	}
+	for _, identity := range membership.Remove {
+		for _, member := range current.Members {
+			if member.Value == identity || strings.EqualFold(member.Display, identity) {
+				toRemove = append(toRemove, bi.GroupMember{Value: member.Value})
+				removed = append(removed, identity)
+				break
+			}
+		}
+	}
+
+	if len(toAdd) == 0 && len(toRemove) == 0 {
+		e.logger.Infof("Group %s membership already matches the plan", membership.Group)
+		return nil
+	}
+	e.logger.Infof("Updating group membership for group %s: +%d members, -%d members", membership.Group, len(toAdd), len(toRemove))
+	batches := splitMembership(toAdd, toRemove, e.config.Sync.MembershipBatchSize)
+	var appliedAdded, appliedRemoved []string
+	var batchErr error
+	for i, err := range e.updateMembersInBatches(ctx, client, group.ID, batches) {
+		if err != nil {
+			batchErr = err
+			continue
+		}
+		batch := batches[i]
+		appliedAdded = append(appliedAdded, added[batch.addStart:batch.addStart+len(batch.add)]...)
+		appliedRemoved = append(appliedRemoved, removed[batch.removeStart:batch.removeStart+len(batch.remove)]...)
+	}
+
+	// The state file knows which source group the Beyond Identity group is synced from
+	var groupEmail string
+	for email, recorded := range e.state.Groups() {
+		if recorded.BIGroupID == group.ID && recorded.Target == targetName {
+			groupEmail = email
+			break
+		}
+	}
+	result.MembershipsAdded += len(appliedAdded)
+	result.MembershipsRemoved += len(appliedRemoved)
+	for _, user := range appliedAdded {
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipAdded, User: user, Group: groupEmail, Target: targetName})
+	}
+	for _, user := range appliedRemoved {
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipRemoved, User: user, Group: groupEmail, Target: targetName})
+	}
+	result.recordDiff(targetName, membership.Group, appliedAdded, appliedRemoved)
+	if batchErr != nil {
+		return fmt.Errorf("failed to update group members: %w", batchErr)
+	}
+	return nil
+}
+
+// applyEnrollmentMembership adds and removes the planned members of the Google Workspace enrollment group
+func (e *Engine) applyEnrollmentMembership(ctx context.Context, membership report.PlannedMembership, result *SyncResult) error {
+	if membership.Group != e.config.Sync.EnrollmentGroupName {
+		return fmt.Errorf("%s is not the configured enrollment group %s", membership.Group, e.config.Sync.EnrollmentGroupName)
+	}
+	groupEmail := e.config.Sync.EnrollmentGroupEmail
+	members, err := e.gwsClient.GetGroupMembers(ctx, groupEmail)
+	if err != nil {
+		return fmt.Errorf("failed to get enrollment group members: %w", err)
+	}
+	isMember := make(map[string]bool)
+	for _, member := range members {
+		isMember[strings.ToLower(member.Email)] = true
+	}
+
+	var added, removed []string
+	for _, email := range membership.Add {
+		if isMember[strings.ToLower(email)] {
+			continue
+		}
+		if err := e.gwsClient.AddMemberToGroup(ctx, groupEmail, email); err != nil {
+			e.addError(result, "user", email, fmt.Errorf("failed to add to enrollment group: %w", err))
+			continue
+		}
+		result.MembershipsAdded++
+		added = append(added, email)
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipAdded, User: email, Group: groupEmail, Target: state.TargetGoogleWorkspace})
+	}
+	for _, email := range membership.Remove {
+		if !isMember[strings.ToLower(email)] {
+			continue
+		}
+		if err := e.gwsClient.RemoveMemberFromGroup(ctx, groupEmail, email); err != nil {
+			e.addError(result, "user", email, fmt.Errorf("failed to remove from enrollment group: %w", err))
+			continue
+		}
+		result.MembershipsRemoved++
+		removed = append(removed, email)
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipRemoved, User: email, Group: groupEmail, Target: state.TargetGoogleWorkspace})
+	}
+	result.recordDiff(state.TargetGoogleWorkspace, membership.Group, added, removed)
+	return nil
+}
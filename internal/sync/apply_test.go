@@ -0,0 +1,186 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// planSync plans a sync of the engine's groups in test mode, leaving test mode off afterwards
+func planSync(t *testing.T, engine *Engine) *report.DryRun {
+	t.Helper()
+	engine.config.App.TestMode = true
+	planned, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	engine.config.App.TestMode = false
+	plan := planned.DryRunReport(time.Now())
+	plan.ConfigHash = engine.PlanHash()
+	return plan
+}
+
+func TestApplyPlan(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+
+	engine.config.App.TestMode = true
+	planned, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	plan := planned.DryRunReport(time.Now())
+	plan.ConfigHash = engine.PlanHash()
+	if len(biClient.users) != 0 || len(biClient.groups) != 0 {
+		t.Fatal("Expected planning to change nothing")
+	}
+
+	engine.config.App.TestMode = false
+	result, err := engine.ApplyPlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("ApplyPlan() error = %v", err)
+	}
+	if result.UsersCreated != 3 || result.GroupsCreated != 2 || result.MembershipsAdded != 6 || len(result.Errors) != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	if group == nil || len(group.Members) != 2 {
+		t.Fatalf("Expected GWS_Engineering with two members, got %+v", group)
+	}
+
+	// Applying the plan again finds everything done
+	result, err = engine.ApplyPlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("ApplyPlan() error = %v", err)
+	}
+	if result.UsersCreated+result.GroupsCreated+result.MembershipsAdded != 0 || len(biClient.users) != 3 {
+		t.Errorf("Expected a second apply to change nothing, got %+v", result)
+	}
+
+	// A live run agrees the plan was applied in full
+	synced, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(synced.MembershipDiffs) != 0 || synced.UsersCreated != 0 {
+		t.Errorf("Expected nothing left to sync, got %+v", synced.MembershipDiffs)
+	}
+}
+
+func TestApplyPlan_Refused(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.App.TestMode = true
+	planned, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	plan := planned.DryRunReport(time.Now())
+	plan.ConfigHash = engine.PlanHash()
+
+	if _, err := engine.ApplyPlan(context.Background(), plan); err == nil {
+		t.Error("Expected apply to be refused in test mode")
+	}
+
+	engine.config.App.TestMode = false
+	engine.config.Sync.Groups = []string{"eng@example.com"}
+	if _, err := engine.ApplyPlan(context.Background(), plan); !errors.Is(err, ErrStalePlan) {
+		t.Errorf("Expected ErrStalePlan after the configuration changed, got %v", err)
+	}
+
+	engine.config.Sync.Groups = []string{"eng@example.com", "sales@example.com"}
+	plan.Errors = []string{"user bob@example.com: HTTP 503"}
+	if _, err := engine.ApplyPlan(context.Background(), plan); err == nil {
+		t.Error("Expected an incomplete plan to be refused")
+	}
+	if len(biClient.users) != 0 {
+		t.Errorf("Expected refused plans to change nothing, got %d users", len(biClient.users))
+	}
+}
+
+func TestApplyPlan_RecordsGroups(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	plan := planSync(t, engine)
+
+	result, err := engine.ApplyPlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("ApplyPlan() error = %v", err)
+	}
+
+	created := make(map[string]string) // Group name -> ID recorded as created
+	for _, change := range result.Changes {
+		if change.Action == state.ChangeGroupCreated {
+			created[change.Group] = change.ID
+		}
+	}
+	for groupEmail, name := range map[string]string{"eng@example.com": "GWS_Engineering", "sales@example.com": "GWS_Sales"} {
+		group, _ := biClient.FindGroupByDisplayName(context.Background(), name)
+		if group == nil || created[name] != group.ID {
+			t.Errorf("Expected the creation of %s to be recorded with its ID, got %v", name, created)
+			continue
+		}
+		recorded, ok := engine.state.Group(groupEmail)
+		if !ok || recorded.BIGroupID != group.ID || recorded.BIGroupName != name || recorded.Target != config.DefaultTargetName {
+			t.Errorf("Expected %s to be mapped to %s (%s) in the state file, got %+v", groupEmail, name, group.ID, recorded)
+		}
+	}
+}
+
+func TestApplyPlan_ConflictPolicy(t *testing.T) {
+	manual := func() *bi.User {
+		return &bi.User{ID: "manual-1", ExternalID: "HR-1001", UserName: "alice", Emails: []bi.Email{{Value: "alice@example.com", Primary: true}}, Active: true}
+	}
+
+	tests := []struct {
+		name       string
+		beforePlan bool   // The conflicting user exists when planning, and is adopted, rather than appearing before apply
+		policy     string // Set after planning
+		wantMember bool
+		wantErrors int
+	}{
+		{"adopted when planned as a member", true, config.ConflictAdopt, true, 0},
+		{"skipped when planned as a member", true, config.ConflictSkip, false, 0},
+		{"skipped when planned to be created", false, config.ConflictSkip, false, 0},
+		{"refused when planned to be created", false, config.ConflictError, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, biClient := newTargetedTestEngine()
+			engine.config.Sync.Groups = []string{"eng@example.com"}
+			if tt.beforePlan {
+				biClient.users["manual-1"] = manual()
+			}
+			plan := planSync(t, engine)
+			biClient.users["manual-1"] = manual()
+			engine.config.Sync.ConflictPolicy = tt.policy
+
+			result, err := engine.ApplyPlan(context.Background(), plan)
+			if err != nil {
+				t.Fatalf("ApplyPlan() error = %v", err)
+			}
+			if len(result.UserConflicts) != 1 || result.UserConflicts[0].Action != tt.policy {
+				t.Errorf("Expected alice to be recorded as a conflict, got %+v", result.UserConflicts)
+			}
+			if len(result.Errors) != tt.wantErrors {
+				t.Errorf("Expected %d errors, got %v", tt.wantErrors, result.Errors)
+			}
+			if len(biClient.users) != 2 {
+				t.Errorf("Expected only bob to be created, got %d users", len(biClient.users))
+			}
+
+			group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+			isMember := false
+			for _, member := range group.Members {
+				isMember = isMember || member.Value == "manual-1"
+			}
+			if isMember != tt.wantMember {
+				t.Errorf("Expected alice's membership to be %v, got %+v", tt.wantMember, group.Members)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/attributes"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// userReader is implemented by Google Workspace clients that can look up a user's directory
+// fields, which sync.attribute_mappings are rendered from
+type userReader interface {
+	GetUser(ctx context.Context, email string) (*gws.User, error)
+}
+
+// GetUser implements userReader when the current client does
+func (r *rotatingGWSClient) GetUser(ctx context.Context, email string) (*gws.User, error) {
+	var user *gws.User
+	err := r.do(func(client GWSClient) error {
+		reader, ok := client.(userReader)
+		if !ok {
+			return nil
+		}
+		var err error
+		user, err = reader.GetUser(ctx, email)
+		return err
+	})
+	return user, err
+}
+
+// GetUser implements userReader when the admins' clients do
+func (d *delegatingGWSClient) GetUser(ctx context.Context, email string) (*gws.User, error) {
+	var user *gws.User
+	err := d.do(email, func(client GWSClient) error {
+		reader, ok := client.(userReader)
+		if !ok {
+			return nil
+		}
+		var err error
+		user, err = reader.GetUser(ctx, email)
+		return err
+	})
+	return user, err
+}
+
+// userAttributes returns the directory fields of a user for attribute mappings; users the
+// directory does not know, e.g. external group members, only have their email
+func userAttributes(email string, user *gws.User) attributes.User {
+	source := attributes.User{Email: email}
+	if user == nil {
+		return source
+	}
+
+	source.GivenName = user.Name.GivenName
+	source.FamilyName = user.Name.FamilyName
+	source.FullName = user.Name.FullName
+	source.OrgUnit = user.OrgUnitPath
+	source.Department = user.Department
+	source.Title = user.Title
+	source.EmployeeID = user.EmployeeID
+	return source
+}
+
+// setUserAttribute assigns a mapped value to a SCIM attribute of user
+func setUserAttribute(user *bi.User, attribute, value string) {
+	switch attribute {
+	case attributes.DisplayName:
+		user.DisplayName = value
+	case attributes.GivenName:
+		userName(user).GivenName = value
+	case attributes.FamilyName:
+		userName(user).FamilyName = value
+	case attributes.FormattedName:
+		userName(user).Formatted = value
+	case attributes.Title:
+		user.Title = value
+	case attributes.EmployeeNumber:
+		enterpriseUser(user).EmployeeNumber = value
+	case attributes.Department:
+		enterpriseUser(user).Department = value
+	case attributes.Organization:
+		enterpriseUser(user).Organization = value
+	}
+}
+
+func userName(user *bi.User) *bi.Name {
+	if user.Name == nil {
+		user.Name = &bi.Name{}
+	}
+	return user.Name
+}
+
+func enterpriseUser(user *bi.User) *bi.EnterpriseUser {
+	if user.Enterprise == nil {
+		user.Enterprise = &bi.EnterpriseUser{}
+	}
+	return user.Enterprise
+}
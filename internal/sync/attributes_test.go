@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// directoryGWSClient also looks up users in the directory
+type directoryGWSClient struct {
+	*mockGWSClient
+	users   map[string]*gws.User
+	userErr error
+}
+
+func (d *directoryGWSClient) GetUser(ctx context.Context, email string) (*gws.User, error) {
+	if d.userErr != nil {
+		return nil, d.userErr
+	}
+	return d.users[email], nil
+}
+
+func TestSync_AttributeMappings(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.gwsClient = &directoryGWSClient{
+		mockGWSClient: gwsClient,
+		users: map[string]*gws.User{
+			"alice@example.com": {
+				PrimaryEmail: "alice@example.com",
+				Name:         gws.UserName{GivenName: "Alice", FamilyName: "Liddell", FullName: "Alice Liddell"},
+				Department:   "Platform",
+				EmployeeID:   "E1001",
+			},
+		},
+	}
+	engine.config.Sync.AttributeMappings = map[string]string{
+		"displayName":     "{{.GivenName}} {{.FamilyName}}",
+		"name.givenName":  "givenName",
+		"name.familyName": "familyName",
+		"department":      "department",
+		"employeeNumber":  "employeeId",
+	}
+
+	if _, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"}); err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+
+	users := make(map[string]*bi.User)
+	for _, user := range biClient.users {
+		users[user.UserName] = user
+	}
+
+	alice := users["alice@example.com"]
+	if alice == nil {
+		t.Fatal("alice@example.com was not created")
+	}
+	if alice.DisplayName != "Alice Liddell" {
+		t.Errorf("DisplayName = %q, want %q", alice.DisplayName, "Alice Liddell")
+	}
+	if alice.Name == nil || alice.Name.GivenName != "Alice" || alice.Name.FamilyName != "Liddell" {
+		t.Errorf("Name = %+v, want Alice Liddell", alice.Name)
+	}
+	if alice.Enterprise == nil || alice.Enterprise.Department != "Platform" || alice.Enterprise.EmployeeNumber != "E1001" {
+		t.Errorf("Enterprise = %+v, want department Platform and employee number E1001", alice.Enterprise)
+	}
+
+	// bob is not in the directory, so the display name is still derived from the email
+	bob := users["bob@example.com"]
+	if bob == nil {
+		t.Fatal("bob@example.com was not created")
+	}
+	if bob.DisplayName != "Bob" || bob.Name != nil || bob.Enterprise != nil {
+		t.Errorf("bob = %+v, want only the derived display name", bob)
+	}
+}
+
+func TestSync_AttributeMappingsLookupError(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.gwsClient = &directoryGWSClient{mockGWSClient: gwsClient, userErr: errors.New("directory unavailable")}
+	engine.config.Sync.AttributeMappings = map[string]string{"displayName": "fullName"}
+
+	result, _ := engine.SyncGroups(context.Background(), []string{"sales@example.com"})
+	if len(result.Errors) == 0 {
+		t.Error("expected an error when user attributes cannot be read")
+	}
+	if len(biClient.users) != 0 {
+		t.Errorf("created %d users, want none without their attributes", len(biClient.users))
+	}
+}
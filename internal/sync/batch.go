@@ -0,0 +1,72 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// memberBatch is one request of a group membership update split by sync.membership_batch_size
+type memberBatch struct {
+	add, remove []bi.GroupMember
+	addStart    int // Index of add[0] in the whole update
+	removeStart int // Index of remove[0] in the whole update
+}
+
+// splitMembership splits a membership update into batches of at most size operations, removals
+// first as in a single request
+func splitMembership(add, remove []bi.GroupMember, size int) []memberBatch {
+	if size <= 0 {
+		size = config.DefaultMembershipBatchSize
+	}
+
+	var batches []memberBatch
+	batch := memberBatch{}
+	full := func() bool { return len(batch.add)+len(batch.remove) == size }
+	for i, member := range remove {
+		if len(batch.remove) == 0 {
+			batch.removeStart = i
+		}
+		batch.remove = append(batch.remove, member)
+		if full() {
+			batches = append(batches, batch)
+			batch = memberBatch{}
+		}
+	}
+	for i, member := range add {
+		if len(batch.add) == 0 {
+			batch.addStart = i
+		}
+		batch.add = append(batch.add, member)
+		if full() {
+			batches = append(batches, batch)
+			batch = memberBatch{}
+		}
+	}
+	if len(batch.add)+len(batch.remove) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// updateMembersInBatches sends a membership update in batches of sync.membership_batch_size
+// operations, retrying each on its own so one failing batch does not undo the others. It returns
+// the error of each batch, nil for those applied; once the run is cancelled the remaining batches
+// fail with the context's error
+func (e *Engine) updateMembersInBatches(ctx context.Context, biClient BIClient, groupID string, batches []memberBatch) []error {
+	errs := make([]error, len(batches))
+	for i, batch := range batches {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		if len(batches) > 1 {
+			e.logger.Debugf("Updating members of group %s, batch %d of %d: +%d, -%d", groupID, i+1, len(batches), len(batch.add), len(batch.remove))
+		}
+		errs[i] = e.retry(ctx, func() error {
+			return biClient.UpdateGroupMembers(ctx, groupID, batch.add, batch.remove)
+		})
+	}
+	return errs
+}
@@ -0,0 +1,79 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+// batchFailingClient fails one UpdateGroupMembers call, counting from 1
+type batchFailingClient struct {
+	*mockBIClient
+	calls    int
+	failCall int
+}
+
+func (c *batchFailingClient) UpdateGroupMembers(ctx context.Context, groupID string, add, remove []bi.GroupMember) error {
+	c.calls++
+	if c.calls == c.failCall {
+		return errors.New("HTTP 413: request entity too large")
+	}
+	return c.mockBIClient.UpdateGroupMembers(ctx, groupID, add, remove)
+}
+
+func members(ids ...string) []bi.GroupMember {
+	var result []bi.GroupMember
+	for _, id := range ids {
+		result = append(result, bi.GroupMember{Value: id})
+	}
+	return result
+}
+
+func TestSplitMembership(t *testing.T) {
+	batches := splitMembership(members("a1", "a2", "a3"), members("r1", "r2"), 2)
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches, got %+v", batches)
+	}
+	if len(batches[0].remove) != 2 || len(batches[0].add) != 0 {
+		t.Errorf("Expected the removals first, got %+v", batches[0])
+	}
+	if len(batches[1].add) != 2 || batches[1].addStart != 0 || len(batches[2].add) != 1 || batches[2].addStart != 2 {
+		t.Errorf("Expected the additions in order after them, got %+v", batches[1:])
+	}
+
+	mixed := splitMembership(members("a1"), members("r1"), 0)
+	if len(mixed) != 1 || len(mixed[0].add) != 1 || len(mixed[0].remove) != 1 {
+		t.Errorf("Expected one batch with the default size, got %+v", mixed)
+	}
+	if empty := splitMembership(nil, nil, 10); len(empty) != 0 {
+		t.Errorf("Expected no batches, got %+v", empty)
+	}
+}
+
+func TestSync_MembershipBatchFailure(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.Sync.MembershipBatchSize = 1
+	client := &batchFailingClient{mockBIClient: biClient, failCall: 2}
+	engine.biClient = client
+
+	result, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+	if err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	if client.calls != 2 {
+		t.Errorf("Expected one request per member, got %d", client.calls)
+	}
+	if result.MembershipsAdded != 1 || len(result.Errors) != 1 {
+		t.Errorf("Expected one batch applied and one failed, got %d added and errors %v", result.MembershipsAdded, result.Errors)
+	}
+	if len(result.MembershipDiffs) != 1 || len(result.MembershipDiffs[0].Added) != 1 {
+		t.Errorf("Expected only the applied batch in the diff, got %+v", result.MembershipDiffs)
+	}
+
+	group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	if group == nil || len(group.Members) != 1 {
+		t.Errorf("Expected the first batch to be kept, got %+v", group)
+	}
+}
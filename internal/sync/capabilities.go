@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// capabilityProber is implemented by targets that can check what their credentials may access
+type capabilityProber interface {
+	ProbeCapabilities(ctx context.Context) []bi.CapabilityCheck
+}
+
+// TargetCapabilities is the capability matrix of one provisioning target
+type TargetCapabilities struct {
+	Target string               `json:"target"`
+	Checks []bi.CapabilityCheck `json:"checks"`
+}
+
+// ProbeCapabilities checks what each target's credentials may access with read-only requests;
+// targets that cannot be probed are left out
+func (e *Engine) ProbeCapabilities(ctx context.Context) []TargetCapabilities {
+	names := []string{config.DefaultTargetName}
+	for name := range e.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+
+	var matrix []TargetCapabilities
+	for _, name := range names {
+		client, err := e.clientForTarget(name)
+		if err != nil {
+			continue
+		}
+		prober, ok := client.(capabilityProber)
+		if !ok {
+			continue
+		}
+		matrix = append(matrix, TargetCapabilities{Target: name, Checks: prober.ProbeCapabilities(ctx)})
+	}
+	return matrix
+}
+
+// LogCapabilities probes every target and logs the capability matrix, warning about each API
+// the credentials cannot call; it reports whether everything was allowed
+func (e *Engine) LogCapabilities(ctx context.Context) bool {
+	allowed := true
+	for _, target := range e.ProbeCapabilities(ctx) {
+		for _, check := range target.Checks {
+			if check.Allowed {
+				e.logger.Infof("Capability [%s] %s (%s): allowed", target.Target, check.Capability, check.Endpoint)
+				continue
+			}
+			allowed = false
+			e.logger.Warnf("Capability [%s] %s (%s): denied, %s will fail: %s",
+				target.Target, check.Capability, check.Endpoint, check.RequiredFor, check.Error)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,44 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+// probingBIClient reports a fixed capability matrix
+type probingBIClient struct {
+	*mockBIClient
+	checks []bi.CapabilityCheck
+}
+
+func (c *probingBIClient) ProbeCapabilities(ctx context.Context) []bi.CapabilityCheck {
+	return c.checks
+}
+
+func TestProbeCapabilities(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.biClient = &probingBIClient{biClient, []bi.CapabilityCheck{
+		{Capability: "SCIM Users", Allowed: true},
+		{Capability: "SCIM Groups", Allowed: true},
+	}}
+	engine.AddTarget("subsidiary", &probingBIClient{biClient, []bi.CapabilityCheck{
+		{Capability: "SCIM Users", Allowed: true},
+		{Capability: "SCIM Groups", StatusCode: 403, Error: "HTTP 403: Forbidden"},
+	}})
+	engine.AddTarget("unprobed", biClient)
+
+	matrix := engine.ProbeCapabilities(context.Background())
+	if len(matrix) != 2 || matrix[0].Target != "default" || matrix[1].Target != "subsidiary" {
+		t.Fatalf("Expected the default and subsidiary targets, got %+v", matrix)
+	}
+	if engine.LogCapabilities(context.Background()) {
+		t.Error("Expected a denied capability to be reported")
+	}
+
+	engine.targets = map[string]BIClient{}
+	if !engine.LogCapabilities(context.Background()) {
+		t.Error("Expected all capabilities to be allowed")
+	}
+}
@@ -0,0 +1,297 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// groupLister is implemented by targets that can list their groups, used to find orphaned groups
+type groupLister interface {
+	ListGroups(ctx context.Context, prefix string) ([]bi.Group, error)
+}
+
+// groupDeleter is implemented by targets that can delete groups
+type groupDeleter interface {
+	DeleteGroup(ctx context.Context, groupID string) error
+}
+
+// Outcomes of cleaning up one orphaned group
+const (
+	CleanupWouldDelete = "would_delete"
+	CleanupDeleted     = "deleted"
+	CleanupWouldEmpty  = "would_empty"
+	CleanupEmptied     = "emptied"
+	CleanupFailed      = "failed"
+)
+
+// Reasons a prefixed group is no longer synced
+const (
+	OrphanReasonDeleted      = "deleted from the source"
+	OrphanReasonUnconfigured = "not in the sync configuration"
+)
+
+// OrphanedGroup is a Beyond Identity group carrying a managed prefix that no configured group syncs
+type OrphanedGroup struct {
+	Target     string
+	GroupID    string
+	Name       string
+	GroupEmail string // Source group the state file recorded for it, if any
+	Reason     string
+	Members    int
+	Status     string
+	Err        error
+}
+
+// FindOrphanedGroups lists the groups of every target whose name starts with the target's group
+// prefix but that no configured group, or alias group, syncs any more: either their source group
+// was deleted or it was removed from the sync configuration. Nothing is changed
+func (e *Engine) FindOrphanedGroups(ctx context.Context) ([]OrphanedGroup, error) {
+	keepNames, keepIDs, err := e.syncedGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The state file remembers which source group each Beyond Identity group was created for
+	recorded := make(map[string]string) // Target + group ID -> source group email
+	for email, group := range e.state.Groups() {
+		if group.BIGroupID != "" {
+			recorded[group.Target+"\x00"+group.BIGroupID] = email
+		}
+	}
+
+	names := []string{config.DefaultTargetName}
+	for name := range e.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+
+	var orphaned []OrphanedGroup
+	for _, targetName := range names {
+		prefix := e.config.GroupPrefixForTarget(targetName)
+		if prefix == "" {
+			return nil, fmt.Errorf("target %s has no group prefix, so its managed groups cannot be told apart", targetName)
+		}
+		client, err := e.clientForTarget(targetName)
+		if err != nil {
+			return nil, err
+		}
+		lister, ok := client.(groupLister)
+		if !ok {
+			e.logger.Warnf("Target %s cannot list groups, skipping orphaned group cleanup", targetName)
+			continue
+		}
+
+		groups, err := lister.ListGroups(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list groups of target %s: %w", targetName, err)
+		}
+		for _, group := range groups {
+			key := targetName + "\x00"
+			if keepIDs[key+group.ID] || keepNames[key+strings.ToLower(group.DisplayName)] {
+				continue
+			}
+
+			candidate := OrphanedGroup{Target: targetName, GroupID: group.ID, Name: group.DisplayName, Reason: OrphanReasonUnconfigured}
+			if email, ok := recorded[key+group.ID]; ok {
+				candidate.GroupEmail = email
+				if _, configured := e.configuredGroup(email); configured {
+					candidate.Reason = OrphanReasonDeleted
+				}
+			}
+			if members, err := client.GetGroupWithMembers(ctx, group.ID); err == nil && members != nil {
+				candidate.Members = len(members.Members)
+			}
+			orphaned = append(orphaned, candidate)
+		}
+	}
+	return orphaned, nil
+}
+
+// syncedGroups returns the names and IDs, keyed by target, of the Beyond Identity groups the
+// configured groups are synced to. Any source error other than a deleted group fails the lookup,
+// so a group is never cleaned up because its source could not be read
+func (e *Engine) syncedGroups(ctx context.Context) (names, ids map[string]bool, err error) {
+	names = make(map[string]bool)
+	ids = make(map[string]bool)
+	if e.source == nil {
+		return nil, nil, errors.New("no membership source configured")
+	}
+	lister, listsAliases := e.source.(aliasLister)
+
+	for _, groupEmail := range e.configuredGroups() {
+		targetName := e.config.TargetForGroup(groupEmail)
+		key := targetName + "\x00"
+		prefix := e.config.GroupPrefixForTarget(targetName)
+
+		sourceGroup, err := e.sourceGroup(ctx, groupEmail)
+		if isGroupNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get source group %s: %w", groupEmail, err)
+		}
+		names[key+strings.ToLower(prefix+sourceGroup.Name)] = true
+		if e.config.Sync.AdminGroups {
+			names[key+strings.ToLower(adminGroupName(prefix, sourceGroup.Name))] = true
+		}
+		if recorded, ok := e.state.Group(groupEmail); ok && recorded.BIGroupID != "" && !recorded.Orphaned {
+			ids[key+recorded.BIGroupID] = true
+		}
+
+		if e.config.Sync.Aliases.CreateGroups && listsAliases {
+			aliases, err := lister.GetGroupAliases(ctx, groupEmail)
+			if err != nil && !errors.Is(err, errAliasesUnsupported) {
+				return nil, nil, fmt.Errorf("failed to list aliases of group %s: %w", groupEmail, err)
+			}
+			for _, alias := range aliases {
+				names[key+strings.ToLower(prefix+alias)] = true
+			}
+		}
+	}
+	return names, ids, nil
+}
+
+// CleanupOrphanedGroups deletes the orphaned groups, or removes all their members with
+// config.OrphanCleanupEmpty. With dryRun, or in test or read-only mode, the changes are only
+// reported; emptying is also only reported when remove is listed in sync.test_mode_operations
+func (e *Engine) CleanupOrphanedGroups(ctx context.Context, orphaned []OrphanedGroup, mode string, dryRun bool) ([]OrphanedGroup, error) {
+	ctx, finish, err := e.beginRun(ctx, RunKindCleanup, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := e.cleanupGroups(ctx, orphaned, mode, dryRun)
+	finish(nil, err)
+	return results, err
+}
+
+func (e *Engine) cleanupGroups(ctx context.Context, orphaned []OrphanedGroup, mode string, dryRun bool) ([]OrphanedGroup, error) {
+	if mode != config.OrphanCleanupDelete && mode != config.OrphanCleanupEmpty {
+		return nil, fmt.Errorf("unknown cleanup mode %q: expected %s or %s", mode, config.OrphanCleanupDelete, config.OrphanCleanupEmpty)
+	}
+
+	results := make([]OrphanedGroup, 0, len(orphaned))
+	failed := 0
+	for _, group := range orphaned {
+		if mode == config.OrphanCleanupDelete {
+			group = e.deleteOrphanedGroup(ctx, group, dryRun || e.dryRun())
+		} else {
+			group = e.emptyOrphanedGroup(ctx, group, dryRun || e.simulated(config.OperationRemove))
+		}
+		if group.Status == CleanupFailed {
+			e.logger.Errorf("Failed to clean up group %s: %v", group.Name, group.Err)
+			failed++
+		}
+		results = append(results, group)
+	}
+
+	if err := e.state.Save(); err != nil {
+		e.logger.Errorf("Failed to save sync state: %v", err)
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d orphaned groups failed to clean up", failed, len(orphaned))
+	}
+	return results, nil
+}
+
+// deleteOrphanedGroup deletes one orphaned group and forgets it in the state file
+func (e *Engine) deleteOrphanedGroup(ctx context.Context, group OrphanedGroup, dryRun bool) OrphanedGroup {
+	client, err := e.clientForTarget(group.Target)
+	if err != nil {
+		group.Status, group.Err = CleanupFailed, err
+		return group
+	}
+	deleter, ok := client.(groupDeleter)
+	if !ok {
+		group.Status, group.Err = CleanupFailed, fmt.Errorf("target %s cannot delete groups", group.Target)
+		return group
+	}
+
+	if dryRun {
+		e.logger.Infof("TEST MODE: Would delete group '%s' (%s)", group.Name, group.Reason)
+		group.Status = CleanupWouldDelete
+		return group
+	}
+
+	if err := e.retry(ctx, func() error { return deleter.DeleteGroup(ctx, group.GroupID) }); err != nil {
+		group.Status, group.Err = CleanupFailed, fmt.Errorf("failed to delete group: %w", err)
+		return group
+	}
+	e.logger.Infof("Deleted group '%s' (%s)", group.Name, group.Reason)
+	group.Status = CleanupDeleted
+
+	if group.GroupEmail != "" {
+		e.state.UpdateGroup(group.GroupEmail, func(recorded *state.GroupState) {
+			if recorded.BIGroupID == group.GroupID {
+				recorded.BIGroupID = ""
+				recorded.BIGroupName = ""
+			}
+		})
+	}
+	return group
+}
+
+// emptyOrphanedGroup removes every member of one orphaned group, keeping the group
+func (e *Engine) emptyOrphanedGroup(ctx context.Context, group OrphanedGroup, dryRun bool) OrphanedGroup {
+	client, err := e.clientForTarget(group.Target)
+	if err != nil {
+		group.Status, group.Err = CleanupFailed, err
+		return group
+	}
+
+	current, err := client.GetGroupWithMembers(ctx, group.GroupID)
+	if err != nil {
+		group.Status, group.Err = CleanupFailed, fmt.Errorf("failed to get members: %w", err)
+		return group
+	}
+	group.Members = len(current.Members)
+
+	if dryRun {
+		e.logger.Infof("TEST MODE: Would remove all %d members of group '%s' (%s)", group.Members, group.Name, group.Reason)
+		group.Status = CleanupWouldEmpty
+		return group
+	}
+
+	batches := splitMembership(nil, current.Members, e.config.Sync.MembershipBatchSize)
+	for _, err := range e.updateMembersInBatches(ctx, client, group.GroupID, batches) {
+		if err != nil {
+			group.Status, group.Err = CleanupFailed, fmt.Errorf("failed to remove members: %w", err)
+			return group
+		}
+	}
+	e.logger.Infof("Removed all %d members of group '%s' (%s)", group.Members, group.Name, group.Reason)
+	group.Status = CleanupEmptied
+	return group
+}
+
+// cleanupAfterSync cleans up orphaned groups at the end of a full run when
+// sync.orphaned_groups.cleanup is set; failures are reported as sync errors
+func (e *Engine) cleanupAfterSync(ctx context.Context, result *SyncResult) {
+	mode := e.config.Sync.OrphanedGroups.Cleanup
+	if mode == "" || result.Aborted || e.runCancelled(ctx, result) {
+		return
+	}
+
+	orphaned, err := e.FindOrphanedGroups(ctx)
+	if err != nil {
+		e.logger.Errorf("Failed to find orphaned groups: %v", err)
+		e.addError(result, "cleanup", "", err)
+		return
+	}
+	results, _ := e.cleanupGroups(ctx, orphaned, mode, false)
+	for _, group := range results {
+		switch {
+		case group.Status == CleanupDeleted, group.Status == CleanupEmptied && group.Members > 0:
+			result.GroupsCleanedUp = append(result.GroupsCleanedUp, group.Name)
+		case group.Status == CleanupFailed:
+			e.addError(result, "cleanup", group.Name, group.Err)
+		}
+	}
+}
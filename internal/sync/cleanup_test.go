@@ -0,0 +1,149 @@
+package sync
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func (m *mockBIClient) ListGroups(ctx context.Context, prefix string) ([]bi.Group, error) {
+	var groups []bi.Group
+	for _, group := range m.groups {
+		if strings.HasPrefix(group.DisplayName, prefix) {
+			groups = append(groups, bi.Group{ID: group.ID, DisplayName: group.DisplayName})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].DisplayName < groups[j].DisplayName })
+	return groups, nil
+}
+
+func (m *mockBIClient) DeleteGroup(ctx context.Context, groupID string) error {
+	delete(m.groups, groupID)
+	return nil
+}
+
+// newCleanupTestEngine syncs both groups, then adds a stray prefixed group, an unmanaged group and
+// drops sales@example.com from the configuration
+func newCleanupTestEngine(t *testing.T) (*Engine, *mockGWSClient, *mockBIClient) {
+	t.Helper()
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	biClient.groups["stray"] = &bi.Group{ID: "stray", DisplayName: "GWS_Old Team", Members: []bi.GroupMember{{Value: "user-1"}}}
+	biClient.groups["unmanaged"] = &bi.Group{ID: "unmanaged", DisplayName: "Admins"}
+	engine.config.Sync.Groups = []string{"eng@example.com"}
+	return engine, gwsClient, biClient
+}
+
+func orphanedNames(groups []OrphanedGroup) []string {
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.Name+": "+group.Reason)
+	}
+	return names
+}
+
+func TestFindOrphanedGroups(t *testing.T) {
+	engine, _, _ := newCleanupTestEngine(t)
+
+	orphaned, err := engine.FindOrphanedGroups(context.Background())
+	if err != nil {
+		t.Fatalf("FindOrphanedGroups() error = %v", err)
+	}
+	got := strings.Join(orphanedNames(orphaned), "; ")
+	want := "GWS_Old Team: " + OrphanReasonUnconfigured + "; GWS_Sales: " + OrphanReasonUnconfigured
+	if got != want {
+		t.Errorf("orphaned = %q, want %q", got, want)
+	}
+	if orphaned[0].Members != 1 || orphaned[1].GroupEmail != "sales@example.com" {
+		t.Errorf("Expected member counts and recorded source groups, got %+v", orphaned)
+	}
+}
+
+func TestFindOrphanedGroups_DeletedSourceGroup(t *testing.T) {
+	engine, gwsClient, _ := newCleanupTestEngine(t)
+	engine.config.Sync.Groups = []string{"eng@example.com", "sales@example.com"}
+	gwsClient.deleted = map[string]bool{"sales@example.com": true}
+
+	orphaned, err := engine.FindOrphanedGroups(context.Background())
+	if err != nil {
+		t.Fatalf("FindOrphanedGroups() error = %v", err)
+	}
+	if len(orphaned) != 2 || orphaned[1].Name != "GWS_Sales" || orphaned[1].Reason != OrphanReasonDeleted {
+		t.Errorf("Expected GWS_Sales orphaned by its deleted source group, got %v", orphanedNames(orphaned))
+	}
+
+	// A source that cannot be read must not make its group look orphaned
+	gwsClient.shouldError = true
+	if _, err := engine.FindOrphanedGroups(context.Background()); err == nil {
+		t.Error("Expected an error when the source cannot be read")
+	}
+}
+
+func TestCleanupOrphanedGroups(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		dryRun    bool
+		status    string
+		remaining int // Groups left in the target
+	}{
+		{name: "delete", mode: config.OrphanCleanupDelete, status: CleanupDeleted, remaining: 2},
+		{name: "empty", mode: config.OrphanCleanupEmpty, status: CleanupEmptied, remaining: 4},
+		{name: "dry run", mode: config.OrphanCleanupDelete, dryRun: true, status: CleanupWouldDelete, remaining: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, biClient := newCleanupTestEngine(t)
+			orphaned, err := engine.FindOrphanedGroups(context.Background())
+			if err != nil {
+				t.Fatalf("FindOrphanedGroups() error = %v", err)
+			}
+
+			results, err := engine.CleanupOrphanedGroups(context.Background(), orphaned, tt.mode, tt.dryRun)
+			if err != nil {
+				t.Fatalf("CleanupOrphanedGroups() error = %v", err)
+			}
+			for _, result := range results {
+				if result.Status != tt.status {
+					t.Errorf("%s: status = %s, want %s", result.Name, result.Status, tt.status)
+				}
+			}
+			if len(biClient.groups) != tt.remaining {
+				t.Errorf("Expected %d groups left, got %d", tt.remaining, len(biClient.groups))
+			}
+			if tt.status == CleanupEmptied && len(biClient.groups["stray"].Members) != 0 {
+				t.Errorf("Expected the stray group to be emptied, got %+v", biClient.groups["stray"].Members)
+			}
+			if recorded, _ := engine.state.Group("sales@example.com"); (recorded.BIGroupID == "") != (tt.status == CleanupDeleted) {
+				t.Errorf("Expected the sales mapping to be forgotten only once deleted, got %+v", recorded)
+			}
+		})
+	}
+}
+
+func TestSync_OrphanedGroupCleanup(t *testing.T) {
+	engine, _, biClient := newCleanupTestEngine(t)
+	engine.config.Sync.OrphanedGroups.Cleanup = config.OrphanCleanupDelete
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if strings.Join(result.GroupsCleanedUp, ",") != "GWS_Old Team,GWS_Sales" {
+		t.Errorf("GroupsCleanedUp = %v", result.GroupsCleanedUp)
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering"); group == nil {
+		t.Error("Expected the synced group to be kept")
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "Admins"); group == nil {
+		t.Error("Expected groups without the prefix to be kept")
+	}
+}
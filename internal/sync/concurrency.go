@@ -0,0 +1,169 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	gosync "sync"
+)
+
+// Flags set once per run and shared by the results of groups synced concurrently
+const (
+	flagNativeAPIDown      = "native-api-down:" // + target name; enrollment steps are skipped for the rest of the run
+	flagRemediation        = "remediation:"     // + scope, or "privilege:" + admin; how to fix it has been logged
+	flagAliasesUnsupported = "aliases-unsupported"
+	flagAnnotationsFailed  = "annotations-failed:" // + target name; groups are not annotated for the rest of the run
+	flagSchemaStampFailed  = "schema-stamp-failed" // enrollment_schema fields are not written for the rest of the run
+)
+
+// runFlags are flags a run sets once, e.g. to warn only once however many groups hit a problem
+type runFlags struct {
+	mu  gosync.Mutex
+	set map[string]bool
+}
+
+// setOnce sets the flag and reports whether it was not already set
+func (f *runFlags) setOnce(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.set[name] {
+		return false
+	}
+	if f.set == nil {
+		f.set = make(map[string]bool)
+	}
+	f.set[name] = true
+	return true
+}
+
+func (f *runFlags) isSet(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.set[name]
+}
+
+// runFlags returns the run's flags; they are created before any group is synced concurrently
+func (r *SyncResult) runFlags() *runFlags {
+	if r.flags == nil {
+		r.flags = &runFlags{}
+	}
+	return r.flags
+}
+
+// forGroup returns an empty result for one group of the run, sharing what was planned for the
+// whole run
+func (r *SyncResult) forGroup() *SyncResult {
+	return &SyncResult{
+		ReadOnly:       r.ReadOnly,
+		DryRun:         r.DryRun,
+		pacer:          r.pacer,
+		members:        r.members,
+		sourceEmails:   r.sourceEmails,
+		flags:          r.runFlags(),
+		directory:      r.directory,
+		biUsers:        r.biUsers,
+		filter:         r.filter,
+		filterCompiled: r.filterCompiled,
+		trace:          r.trace,
+	}
+}
+
+// syncGroupsConcurrently syncs the groups on sync.concurrency workers, each into its own result.
+// Results are merged in configured order once every group is done, so reports stay stable; they
+// are also tallied as groups finish, so fail_fast and the error budget stop further groups from
+// starting
+func (e *Engine) syncGroupsConcurrently(ctx context.Context, groupEmails []string, result *SyncResult) {
+	workers := min(e.config.Sync.Concurrency, len(groupEmails))
+	e.logger.Infof("Syncing %d groups with %d workers", len(groupEmails), workers)
+
+	var mu gosync.Mutex
+	tally := result.forGroup()
+	groupResults := make([]*SyncResult, len(groupEmails))
+
+	jobs := make(chan int)
+	var wg gosync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				groupResult := result.forGroup()
+				e.runGroup(ctx, groupEmails[i], groupResult)
+				groupResults[i] = groupResult
+
+				mu.Lock()
+				e.mergeGroupResult(tally, groupResult)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range groupEmails {
+		e.waitWhilePaused(ctx)
+		mu.Lock()
+		stop := e.nextGroup(ctx, tally)
+		mu.Unlock()
+		if stop {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, groupResult := range groupResults {
+		if groupResult != nil {
+			e.mergeGroupResult(result, groupResult)
+		}
+	}
+	if tally.Stopped {
+		result.stop()
+	}
+	e.runCancelled(ctx, result)
+}
+
+// mergeGroupResult adds the outcome of one group to the run's result. Errors are recorded again
+// so fail_fast, the error budget and the authentication error threshold apply to the whole run
+func (e *Engine) mergeGroupResult(result, group *SyncResult) {
+	result.GroupsProcessed += group.GroupsProcessed
+	result.GroupsSkipped += group.GroupsSkipped
+	result.GroupsCreated += group.GroupsCreated
+	result.UsersCreated += group.UsersCreated
+	result.UsersUpdated += group.UsersUpdated
+	result.UsersSkipped += group.UsersSkipped
+	result.UsersFiltered += group.UsersFiltered
+	result.MembershipsAdded += group.MembershipsAdded
+	result.MembershipsRemoved += group.MembershipsRemoved
+	result.DeferredQueued += group.DeferredQueued
+	result.OrphanedGroups = append(result.OrphanedGroups, group.OrphanedGroups...)
+	result.PartialGroups = append(result.PartialGroups, group.PartialGroups...)
+	result.SkippedSteps = append(result.SkippedSteps, group.SkippedSteps...)
+
+	for _, diff := range group.MembershipDiffs {
+		result.recordDiff(diff.Target, diff.Group, diff.Added, diff.Removed)
+	}
+	for _, diff := range group.SimulatedDiffs {
+		result.recordSimulatedDiff(diff.Target, diff.Group, diff.Added, diff.Removed)
+	}
+	for _, user := range group.PlannedUsers {
+		result.planUser(user.Target, user.Email)
+	}
+	for _, planned := range group.PlannedGroups {
+		result.planGroup(planned.Target, planned.Name, planned.GroupEmail)
+	}
+	for _, conflict := range group.UserConflicts {
+		result.recordConflict(conflict)
+	}
+	result.Changes = append(result.Changes, group.Changes...)
+
+	for _, err := range group.Errors {
+		var syncErr *SyncError
+		if errors.As(err, &syncErr) {
+			e.addError(result, syncErr.Kind, syncErr.Subject, syncErr.Err)
+		}
+	}
+	if group.Aborted && !result.Aborted {
+		result.Aborted = true
+		result.AbortReason = group.AbortReason
+	}
+}
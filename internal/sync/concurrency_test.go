@@ -0,0 +1,168 @@
+package sync
+
+import (
+	"context"
+	"reflect"
+	gosync "sync"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// lockedGWSClient serializes calls to the GWS mock, which is not safe for concurrent use
+type lockedGWSClient struct {
+	mu     gosync.Mutex
+	client *mockGWSClient
+}
+
+func (l *lockedGWSClient) GetGroup(ctx context.Context, email string) (*gws.Group, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.GetGroup(ctx, email)
+}
+
+func (l *lockedGWSClient) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.GetGroupMembers(ctx, email)
+}
+
+func (l *lockedGWSClient) AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.AddMemberToGroup(ctx, groupEmail, userEmail)
+}
+
+func (l *lockedGWSClient) RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.RemoveMemberFromGroup(ctx, groupEmail, userEmail)
+}
+
+func (l *lockedGWSClient) EnsureGroup(ctx context.Context, groupEmail, groupName, description string) (*gws.Group, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.EnsureGroup(ctx, groupEmail, groupName, description)
+}
+
+// lockedBIClient serializes calls to the BI mock
+type lockedBIClient struct {
+	mu     gosync.Mutex
+	client *mockBIClient
+}
+
+func (l *lockedBIClient) FindGroupByDisplayName(ctx context.Context, name string) (*bi.Group, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.FindGroupByDisplayName(ctx, name)
+}
+
+func (l *lockedBIClient) CreateGroup(ctx context.Context, group *bi.Group) (*bi.Group, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.CreateGroup(ctx, group)
+}
+
+func (l *lockedBIClient) FindUserByEmail(ctx context.Context, email string) (*bi.User, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.FindUserByEmail(ctx, email)
+}
+
+func (l *lockedBIClient) CreateUser(ctx context.Context, user *bi.User) (*bi.User, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.CreateUser(ctx, user)
+}
+
+func (l *lockedBIClient) UpdateGroupMembers(ctx context.Context, groupID string, membersToAdd, membersToRemove []bi.GroupMember) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.UpdateGroupMembers(ctx, groupID, membersToAdd, membersToRemove)
+}
+
+func (l *lockedBIClient) GetUserStatus(ctx context.Context, userEmail string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.GetUserStatus(ctx, userEmail)
+}
+
+func (l *lockedBIClient) GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.GetGroupWithMembers(ctx, groupID)
+}
+
+// newConcurrentTestEngine returns the targeted test engine with four groups, one of which cannot
+// be read, and thread-safe clients
+func newConcurrentTestEngine(concurrency int) (*Engine, *mockBIClient) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	gwsClient.groups["ops@example.com"] = &gws.Group{Name: "Ops"}
+	gwsClient.members["ops@example.com"] = []*gws.GroupMember{{Email: "alice@example.com", Type: "USER", Status: "ACTIVE"}}
+	engine.config.Sync.Groups = []string{"eng@example.com", "missing@example.com", "sales@example.com", "ops@example.com"}
+	engine.config.Sync.Concurrency = concurrency
+
+	engine.gwsClient = &lockedGWSClient{client: gwsClient}
+	engine.source = engine.gwsClient
+	engine.biClient = &lockedBIClient{client: biClient}
+	return engine, biClient
+}
+
+func TestSyncConcurrently(t *testing.T) {
+	serialEngine, _ := newConcurrentTestEngine(1)
+	serial, err := serialEngine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	engine, biClient := newConcurrentTestEngine(4)
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The unreadable group fails on its own without stopping the others
+	if result.GroupsProcessed != 3 || len(result.Errors) != 1 {
+		t.Fatalf("Expected 3 groups processed and 1 error, got %d and %v", result.GroupsProcessed, result.Errors)
+	}
+	if len(biClient.users) != 3 {
+		t.Errorf("Expected each user to be created once, got %d", len(biClient.users))
+	}
+
+	// Merged in configured order, the result matches a serial run
+	if !reflect.DeepEqual(result.MembershipDiffs, serial.MembershipDiffs) {
+		t.Errorf("Expected diffs %+v, got %+v", serial.MembershipDiffs, result.MembershipDiffs)
+	}
+	if result.MembershipsAdded != serial.MembershipsAdded || result.Errors[0].Error() != serial.Errors[0].Error() {
+		t.Errorf("Expected the serial outcome %+v, got %+v", serial, result)
+	}
+}
+
+func TestSyncConcurrently_FailFast(t *testing.T) {
+	engine, _ := newConcurrentTestEngine(2)
+	engine.config.Sync.FailFast = true
+
+	result, err := engine.Sync(context.Background())
+	if err == nil || !result.Aborted {
+		t.Fatalf("Expected the run to abort, got %v", err)
+	}
+	if len(result.Errors) != 1 || result.AbortReason == "" {
+		t.Errorf("Expected the first error to abort the run, got %v", result.Errors)
+	}
+}
+
+func TestRunFlags(t *testing.T) {
+	result := &SyncResult{}
+	group := result.forGroup()
+
+	if !group.runFlags().setOnce(flagNativeAPIDown + "default") {
+		t.Error("Expected the flag to be set the first time")
+	}
+	if result.runFlags().setOnce(flagNativeAPIDown + "default") {
+		t.Error("Expected the flag to be shared with the run")
+	}
+	if !result.runFlags().isSet(flagNativeAPIDown+"default") || result.runFlags().isSet(flagNativeAPIDown+"eu") {
+		t.Error("Expected flags to be kept per name")
+	}
+}
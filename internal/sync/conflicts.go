@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// ErrUserConflict is returned for users that already exist in a target without having been
+// provisioned by the sync, with sync.conflict_policy: error
+var ErrUserConflict = errors.New("user already exists in the target but was not provisioned by the sync")
+
+// UserConflict is an existing user the sync found that it did not provision, and what it did
+type UserConflict struct {
+	Email       string `json:"email"`
+	Target      string `json:"target"`
+	UserID      string `json:"user_id"`
+	ExternalID  string `json:"external_id"`  // The existing user's externalId
+	DisplayName string `json:"display_name"` // The existing user's displayName
+	Action      string `json:"action"`       // The sync.conflict_policy applied
+}
+
+// isConflict reports whether an existing user was not provisioned by the sync, which sets the
+// externalId of the users it creates to their Google user ID, or to their email for users the
+// directory does not know and for users created before it used Google user IDs. Okta targets use
+// the Okta ID as externalId, so their users are never conflicts
+func (e *Engine) isConflict(targetName, email, googleID string, existing *bi.User) bool {
+	if target := e.config.FindTarget(targetName); target != nil && target.Type == config.TargetTypeOkta {
+		return false
+	}
+	if googleID != "" && existing.ExternalID == googleID {
+		return false
+	}
+	return !strings.EqualFold(existing.ExternalID, email)
+}
+
+// resolveConflict applies sync.conflict_policy to an existing user found for email, returning
+// the ID to sync them with, or "" to leave them out of their groups. The second result reports
+// whether the user was overwritten, so their attributes need no further update
+func (e *Engine) resolveConflict(ctx context.Context, biClient BIClient, targetName, email string, existing *bi.User, result *SyncResult) (string, bool, error) {
+	if !e.isConflict(targetName, email, e.googleUserID(ctx, email, result), existing) {
+		return existing.ID, false, nil
+	}
+
+	policy := e.config.Sync.ConflictPolicy
+	if policy == "" {
+		policy = config.ConflictAdopt
+	}
+	result.recordConflict(UserConflict{
+		Email:       email,
+		Target:      targetName,
+		UserID:      existing.ID,
+		ExternalID:  existing.ExternalID,
+		DisplayName: existing.DisplayName,
+		Action:      policy,
+	})
+	e.logger.Debugf("User %s (ID: %s) exists in target %s with externalId %q; applying conflict policy %s", email, existing.ID, targetName, existing.ExternalID, policy)
+
+	switch policy {
+	case config.ConflictSkip:
+		result.explain(email, targetName, report.ExplainStageLookup, "skipped: exists with externalId %q, not provisioned by the sync (sync.conflict_policy: skip)", existing.ExternalID)
+		return "", false, nil
+	case config.ConflictError:
+		return "", false, fmt.Errorf("%w (externalId %q)", ErrUserConflict, existing.ExternalID)
+	case config.ConflictOverwrite:
+		if err := e.overwriteBIUser(ctx, biClient, targetName, email, existing, result); err != nil {
+			return "", false, err
+		}
+		return existing.ID, true, nil
+	default:
+		result.explain(email, targetName, report.ExplainStageLookup, "adopted: exists with externalId %q, not provisioned by the sync", existing.ExternalID)
+		return existing.ID, false, nil
+	}
+}
+
+// overwriteBIUser replaces the attributes of an existing user with those the sync would create
+// them with, so later runs treat them as provisioned by the sync
+func (e *Engine) overwriteBIUser(ctx context.Context, biClient BIClient, targetName, email string, existing *bi.User, result *SyncResult) error {
+	updater, ok := biClient.(userUpdater)
+	if !ok {
+		return fmt.Errorf("%w, and target %s cannot overwrite users", ErrUserConflict, targetName)
+	}
+	desired, err := e.newBIUser(ctx, email, result)
+	if err != nil {
+		return err
+	}
+	desired.ID = existing.ID
+
+	if e.simulated(config.OperationUpdate) {
+		e.logger.Infof("TEST MODE: Would overwrite user '%s' (externalId %q)", email, existing.ExternalID)
+		result.explain(email, targetName, report.ExplainStageAction, "would overwrite the user (test mode)")
+		return nil
+	}
+
+	e.logger.Infof("Overwriting user %s (ID: %s, externalId %q)", email, existing.ID, existing.ExternalID)
+	if err := e.retry(ctx, func() error {
+		_, err := updater.UpdateUser(ctx, existing.ID, desired)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to overwrite user: %w", err)
+	}
+	result.UsersUpdated++
+	result.userWritten(targetName, email, existing.ID)
+	e.recordChange(result, report.ChangeRecord{Action: state.ChangeUserUpdated, User: email, ID: existing.ID, Target: targetName})
+	result.explain(email, targetName, report.ExplainStageAction, "overwrote the user, which the sync had not provisioned")
+	return nil
+}
+
+// recordConflict records a conflicting user once per target
+func (r *SyncResult) recordConflict(conflict UserConflict) {
+	for _, recorded := range r.UserConflicts {
+		if recorded.Target == conflict.Target && strings.EqualFold(recorded.Email, conflict.Email) {
+			return
+		}
+	}
+	r.UserConflicts = append(r.UserConflicts, conflict)
+}
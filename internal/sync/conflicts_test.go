@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// newConflictTestEngine adds alice to Beyond Identity as if she had been created by hand
+func newConflictTestEngine(policy string) (*Engine, *mockBIClient) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.Sync.ConflictPolicy = policy
+	biClient.users["manual-1"] = &bi.User{
+		ID:          "manual-1",
+		ExternalID:  "HR-1001",
+		UserName:    "alice",
+		DisplayName: "Alice (manual)",
+		Emails:      []bi.Email{{Value: "alice@example.com", Primary: true}},
+		Active:      true,
+	}
+	return engine, biClient
+}
+
+func TestSync_ConflictPolicy(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantMember bool
+		wantErrors int
+	}{
+		{config.ConflictAdopt, true, 0},
+		{config.ConflictSkip, false, 0},
+		{config.ConflictError, false, 1},
+		{config.ConflictOverwrite, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			engine, biClient := newConflictTestEngine(tt.policy)
+
+			result, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+			if err != nil {
+				t.Fatalf("SyncGroups() error = %v", err)
+			}
+			if len(result.UserConflicts) != 1 || result.UserConflicts[0].UserID != "manual-1" || result.UserConflicts[0].Action != tt.policy {
+				t.Errorf("Expected alice to be recorded as a conflict, got %+v", result.UserConflicts)
+			}
+			if len(result.Errors) != tt.wantErrors {
+				t.Errorf("Expected %d errors, got %v", tt.wantErrors, result.Errors)
+			}
+			if tt.wantErrors > 0 && !errors.Is(result.Errors[0], ErrUserConflict) {
+				t.Errorf("Expected a conflict error, got %v", result.Errors[0])
+			}
+
+			group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+			isMember := false
+			for _, member := range group.Members {
+				isMember = isMember || member.Value == "manual-1"
+			}
+			if isMember != tt.wantMember {
+				t.Errorf("Expected alice's membership to be %v, got %+v", tt.wantMember, group.Members)
+			}
+
+			overwritten := biClient.users["manual-1"].ExternalID == "alice@example.com"
+			if overwritten != (tt.policy == config.ConflictOverwrite) {
+				t.Errorf("Unexpected externalId after %s: %q", tt.policy, biClient.users["manual-1"].ExternalID)
+			}
+		})
+	}
+}
+
+func TestSync_NoConflictForProvisionedUsers(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.config.Sync.ConflictPolicy = config.ConflictError
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.UserConflicts) != 0 || len(result.Errors) != 0 {
+		t.Errorf("Expected users the sync created not to conflict, got %+v and %v", result.UserConflicts, result.Errors)
+	}
+}
@@ -0,0 +1,180 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	gosync "sync"
+	"sync/atomic"
+	"time"
+)
+
+// Errors returned when controlling the run in progress
+var (
+	ErrRunNotFound  = errors.New("no run in progress with that ID")
+	ErrRunCancelled = errors.New("cancelled by request")
+)
+
+// ActiveRun is the run in progress, as reported by RunInProgress
+type ActiveRun struct {
+	ID        string
+	Kind      string
+	Subject   string
+	StartedAt time.Time
+	Paused    bool // Waiting before its next group; see PauseRun
+	Stopping  bool // Stopping once its current group is done; see StopRun
+}
+
+// runControl lets operators pause, stop or cancel the run in progress
+type runControl struct {
+	run      ActiveRun
+	cancel   context.CancelCauseFunc
+	stopping atomic.Bool
+
+	mu      gosync.Mutex
+	resumed chan struct{} // Closed when a pause ends; nil while the run is not paused
+}
+
+// startControl makes the run controllable until the returned function is called, deriving the
+// context CancelRun cancels
+func (e *Engine) startControl(ctx context.Context, run ActiveRun) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	control := &runControl{run: run, cancel: cancel}
+	e.control.Store(control)
+	return ctx, func() {
+		e.control.CompareAndSwap(control, nil)
+		control.resume()
+		cancel(nil)
+	}
+}
+
+// RunInProgress returns the run in progress, if there is one
+func (e *Engine) RunInProgress() (ActiveRun, bool) {
+	control := e.control.Load()
+	if control == nil {
+		return ActiveRun{}, false
+	}
+	run := control.run
+	run.Stopping = control.stopping.Load()
+	control.mu.Lock()
+	run.Paused = control.resumed != nil
+	control.mu.Unlock()
+	return run, true
+}
+
+// CancelRun aborts the run with the given ID at once, including API requests in flight, as if its
+// context were cancelled. An empty ID selects whichever run is in progress
+func (e *Engine) CancelRun(id string) error {
+	control, err := e.controlFor(id)
+	if err != nil {
+		return err
+	}
+	e.logger.Warnf("Cancelling %s run %s by request", control.run.Kind, control.run.ID)
+	control.cancel(ErrRunCancelled)
+	control.resume()
+	return nil
+}
+
+// StopRun lets the run with the given ID finish the group it is syncing, then aborts it, so no
+// group is left half synced. An empty ID selects whichever run is in progress
+func (e *Engine) StopRun(id string) error {
+	control, err := e.controlFor(id)
+	if err != nil {
+		return err
+	}
+	e.logger.Warnf("Stopping %s run %s after its current group by request", control.run.Kind, control.run.ID)
+	control.stopping.Store(true)
+	control.resume()
+	return nil
+}
+
+// PauseRun holds the run with the given ID before its next group until ResumeRun, StopRun or
+// CancelRun. The group in progress is finished first. sync.timeout_seconds and the sync lock's
+// lease keep running while a run is paused
+func (e *Engine) PauseRun(id string) error {
+	control, err := e.controlFor(id)
+	if err != nil {
+		return err
+	}
+	control.mu.Lock()
+	if control.resumed == nil {
+		control.resumed = make(chan struct{})
+	}
+	control.mu.Unlock()
+	e.logger.Warnf("Pausing %s run %s before its next group by request", control.run.Kind, control.run.ID)
+	return nil
+}
+
+// ResumeRun continues the paused run with the given ID
+func (e *Engine) ResumeRun(id string) error {
+	control, err := e.controlFor(id)
+	if err != nil {
+		return err
+	}
+	if control.resume() {
+		e.logger.Infof("Resuming %s run %s by request", control.run.Kind, control.run.ID)
+	}
+	return nil
+}
+
+// controlFor returns the control of the run in progress if it has the given ID, or any ID when id
+// is empty
+func (e *Engine) controlFor(id string) (*runControl, error) {
+	control := e.control.Load()
+	if control == nil || (id != "" && control.run.ID != id) {
+		return nil, ErrRunNotFound
+	}
+	return control, nil
+}
+
+// resume ends a pause, reporting whether the run was paused
+func (c *runControl) resume() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumed == nil {
+		return false
+	}
+	close(c.resumed)
+	c.resumed = nil
+	return true
+}
+
+// waitWhilePaused blocks while the run is paused, or until ctx is done
+func (c *runControl) waitWhilePaused(ctx context.Context) {
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+	if resumed == nil {
+		return
+	}
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+	}
+}
+
+// nextGroup is checked before each group of a run: it waits while the run is paused and reports
+// whether the run must stop, because StopRun was called or for any reason of runCancelled
+func (e *Engine) nextGroup(ctx context.Context, result *SyncResult) bool {
+	e.waitWhilePaused(ctx)
+	if control := e.control.Load(); control != nil && control.stopping.Load() {
+		result.stop()
+	}
+	return e.runCancelled(ctx, result)
+}
+
+// waitWhilePaused blocks while the run in progress is paused, or until ctx is done
+func (e *Engine) waitWhilePaused(ctx context.Context) {
+	if control := e.control.Load(); control != nil {
+		control.waitWhilePaused(ctx)
+	}
+}
+
+// stop aborts the run because StopRun was called
+func (r *SyncResult) stop() {
+	if r.Aborted {
+		return
+	}
+	r.Aborted = true
+	r.Stopped = true
+	r.AbortReason = "stopped after the current group by request"
+}
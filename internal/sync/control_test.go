@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// hookedSource calls onMembers before reading the members of a group, to act mid-run
+type hookedSource struct {
+	*mockGWSClient
+	onMembers func(groupEmail string)
+}
+
+func (h *hookedSource) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	h.onMembers(email)
+	return h.mockGWSClient.GetGroupMembers(ctx, email)
+}
+
+func TestStopRun_FinishesCurrentGroup(t *testing.T) {
+	engine, gwsClient, _ := newTargetedTestEngine()
+	var read []string
+	engine.SetSource(&hookedSource{mockGWSClient: gwsClient, onMembers: func(groupEmail string) {
+		read = append(read, groupEmail)
+		if err := engine.StopRun(""); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}})
+
+	result, err := engine.SyncGroups(context.Background(), []string{"eng@example.com", "sales@example.com"})
+	if !errors.Is(err, ErrSyncAborted) {
+		t.Fatalf("Expected the run to be aborted, got %v", err)
+	}
+	if !result.Stopped || result.GroupsProcessed != 1 || len(read) != 1 {
+		t.Errorf("Expected the run to stop after the first group, got %+v (read %v)", result, read)
+	}
+	if _, ok := engine.RunInProgress(); ok {
+		t.Error("Expected no run in progress once it stopped")
+	}
+}
+
+func TestPauseRun_WaitsUntilResumed(t *testing.T) {
+	engine, gwsClient, _ := newTargetedTestEngine()
+	read := make(chan string, 2)
+	engine.SetSource(&hookedSource{mockGWSClient: gwsClient, onMembers: func(groupEmail string) {
+		if groupEmail == "eng@example.com" {
+			if err := engine.PauseRun(""); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}
+		read <- groupEmail
+	}})
+
+	done := make(chan *SyncResult)
+	go func() {
+		result, err := engine.SyncGroups(context.Background(), []string{"eng@example.com", "sales@example.com"})
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		done <- result
+	}()
+
+	<-read
+	var run ActiveRun
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		run, _ = engine.RunInProgress()
+		if run.Paused {
+			break
+		}
+	}
+	if !run.Paused || run.Kind != RunKindGroups {
+		t.Fatalf("Expected the groups run to be paused, got %+v", run)
+	}
+	select {
+	case group := <-read:
+		t.Fatalf("Expected no group to be synced while paused, got %s", group)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := engine.ResumeRun(run.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result := <-done
+	if result.GroupsProcessed != 2 {
+		t.Errorf("Expected both groups to be synced after resuming, got %d", result.GroupsProcessed)
+	}
+}
+
+func TestCancelRun(t *testing.T) {
+	engine, gwsClient, _ := newTargetedTestEngine()
+	engine.SetSource(&hookedSource{mockGWSClient: gwsClient, onMembers: func(groupEmail string) {
+		if err := engine.CancelRun("unknown"); !errors.Is(err, ErrRunNotFound) {
+			t.Errorf("Expected ErrRunNotFound for another run, got %v", err)
+		}
+		run, _ := engine.RunInProgress()
+		if err := engine.CancelRun(run.ID); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}})
+
+	result, err := engine.SyncGroups(context.Background(), []string{"eng@example.com", "sales@example.com"})
+	if !errors.Is(err, ErrSyncAborted) {
+		t.Fatalf("Expected the run to be aborted, got %v", err)
+	}
+	if result.Stopped || !strings.Contains(result.AbortReason, ErrRunCancelled.Error()) {
+		t.Errorf("Expected the run to be cancelled by request, got %q", result.AbortReason)
+	}
+
+	if err := engine.PauseRun(""); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Expected ErrRunNotFound without a run in progress, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// externalIDFinder is implemented by Beyond Identity clients that can look users up by externalId
+type externalIDFinder interface {
+	FindUserByExternalID(ctx context.Context, externalID string) (*bi.User, error)
+}
+
+// googleUserID returns the Google user ID of a member, which the sync sets as the externalId of
+// the users it creates, or "" when the directory does not know them, e.g. external members
+func (e *Engine) googleUserID(ctx context.Context, email string, result *SyncResult) string {
+	profile, err := e.userProfile(ctx, email, result)
+	if err != nil {
+		e.logger.Debugf("Failed to read the Google user ID of %s, matching by email only: %v", email, err)
+		return ""
+	}
+	if profile == nil {
+		return ""
+	}
+	return profile.ID
+}
+
+// reconcileBIUser returns the existing user a member is provisioned as, or nil when there is
+// none: the user whose externalId is the member's Google user ID, which survives renames, or else
+// the user with their email. A user found by externalId under an earlier address is renamed, and
+// a user found by email whose externalId is still their email, as the sync set it before using
+// Google user IDs, has it replaced so later renames are followed
+func (e *Engine) reconcileBIUser(ctx context.Context, biClient BIClient, targetName, email string, result *SyncResult) (*bi.User, error) {
+	googleID := e.googleUserID(ctx, email, result)
+
+	if finder, ok := biClient.(externalIDFinder); ok && googleID != "" {
+		existing, err := e.findRunBIUserByExternalID(ctx, finder, biClient, targetName, googleID, result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for user by externalId: %w", err)
+		}
+		if existing != nil {
+			e.logger.Debugf("Found existing user by externalId %s: %s (ID: %s)", googleID, email, existing.ID)
+			if !strings.EqualFold(existing.UserName, email) {
+				return e.renameBIUser(ctx, biClient, targetName, email, existing, result), nil
+			}
+			return existing, nil
+		}
+	}
+
+	existing, err := e.lookupRunBIUser(ctx, biClient, targetName, email, result)
+	if err != nil || existing == nil {
+		return existing, err
+	}
+	if googleID != "" && strings.EqualFold(existing.ExternalID, email) {
+		return e.correlateBIUser(ctx, biClient, targetName, email, googleID, existing, result), nil
+	}
+	return existing, nil
+}
+
+// renameBIUser gives a user found by their Google user ID the member's current address as
+// userName and primary email, returning the user as renamed. When the rename cannot be written
+// the user keeps their memberships under the earlier address
+func (e *Engine) renameBIUser(ctx context.Context, biClient BIClient, targetName, email string, existing *bi.User, result *SyncResult) *bi.User {
+	renamed := *existing
+	renamed.Groups = nil // Read-only; memberships are managed through groups
+	renamed.UserName = email
+	renamed.Emails = renamedEmails(existing.Emails, email)
+	result.explain(email, targetName, report.ExplainStageLookup, "renamed in the source from %s (externalId %s)", existing.UserName, existing.ExternalID)
+
+	if !e.writeBIUser(ctx, biClient, targetName, email, existing.ID, &renamed, "rename the user from "+existing.UserName, result) {
+		return existing
+	}
+	return &renamed
+}
+
+// correlateBIUser sets the Google user ID as the externalId of a user found by email, returning
+// the user as updated
+func (e *Engine) correlateBIUser(ctx context.Context, biClient BIClient, targetName, email, googleID string, existing *bi.User, result *SyncResult) *bi.User {
+	correlated := *existing
+	correlated.Groups = nil
+	correlated.ExternalID = googleID
+
+	if !e.writeBIUser(ctx, biClient, targetName, email, existing.ID, &correlated, "set the externalId to the Google user ID "+googleID, result) {
+		return existing
+	}
+	return &correlated
+}
+
+// writeBIUser replaces a user's attributes for reconciliation, reporting whether it did, or would
+// in test mode. Failures are recorded as user errors without failing the user
+func (e *Engine) writeBIUser(ctx context.Context, biClient BIClient, targetName, email, userID string, user *bi.User, action string, result *SyncResult) bool {
+	updater, ok := biClient.(userUpdater)
+	if !ok {
+		e.logger.Debugf("Target %s cannot update users, so user %s is not reconciled: %s", targetName, email, action)
+		return false
+	}
+	if e.simulated(config.OperationUpdate) {
+		e.logger.Infof("TEST MODE: Would %s for user '%s'", action, email)
+		result.explain(email, targetName, report.ExplainStageAction, "would %s (test mode)", action)
+		return true
+	}
+
+	e.logger.Infof("Reconciling user %s (ID: %s): %s", email, userID, action)
+	if err := e.retry(ctx, func() error {
+		_, err := updater.UpdateUser(ctx, userID, user)
+		return err
+	}); err != nil {
+		e.logger.Errorf("Failed to reconcile user %s: %v", email, err)
+		e.addError(result, "user", email, fmt.Errorf("failed to %s: %w", action, err))
+		return false
+	}
+	result.UsersUpdated++
+	result.userWritten(targetName, email, userID)
+	e.recordChange(result, report.ChangeRecord{Action: state.ChangeUserUpdated, User: email, ID: userID, Target: targetName})
+	result.explain(email, targetName, report.ExplainStageAction, "reconciled the user (ID: %s): %s", userID, action)
+	return true
+}
+
+// renamedEmails replaces the primary address of emails, keeping the others
+func renamedEmails(emails []bi.Email, email string) []bi.Email {
+	renamed := make([]bi.Email, 0, len(emails)+1)
+	replaced := false
+	for _, existing := range emails {
+		if existing.Primary && !replaced {
+			existing.Value = email
+			replaced = true
+		}
+		renamed = append(renamed, existing)
+	}
+	if !replaced {
+		renamed = append([]bi.Email{{Value: email, Type: "work", Primary: true}}, renamed...)
+	}
+	return renamed
+}
@@ -0,0 +1,110 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+func (m *mockBIClient) FindUserByExternalID(ctx context.Context, externalID string) (*bi.User, error) {
+	for _, user := range m.users {
+		if user.ExternalID == externalID {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+// newCorrelationTestEngine gives alice, bob and carol Google user IDs in the directory
+func newCorrelationTestEngine() (*Engine, *mockGWSClient, *mockBIClient) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.gwsClient = &directoryGWSClient{
+		mockGWSClient: gwsClient,
+		users: map[string]*gws.User{
+			"alice@example.com": {ID: "g-alice", PrimaryEmail: "alice@example.com"},
+			"bob@example.com":   {ID: "g-bob", PrimaryEmail: "bob@example.com"},
+			"carol@example.com": {ID: "g-carol", PrimaryEmail: "carol@example.com"},
+		},
+	}
+	return engine, gwsClient, biClient
+}
+
+func TestSync_ExternalIDIsGoogleUserID(t *testing.T) {
+	engine, _, biClient := newCorrelationTestEngine()
+
+	if _, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"}); err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	carol, _ := biClient.FindUserByEmail(context.Background(), "carol@example.com")
+	if carol == nil || carol.ExternalID != "g-carol" {
+		t.Fatalf("Expected carol to be created with her Google user ID as externalId, got %+v", carol)
+	}
+}
+
+func TestSync_RenamedUserIsReconciled(t *testing.T) {
+	engine, gwsClient, biClient := newCorrelationTestEngine()
+	if _, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"}); err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	carol, _ := biClient.FindUserByEmail(context.Background(), "carol@example.com")
+
+	// Carol changes her address; the directory keeps her ID
+	directory := engine.gwsClient.(*directoryGWSClient)
+	directory.users = map[string]*gws.User{"caroline@example.com": {ID: "g-carol", PrimaryEmail: "caroline@example.com"}}
+	gwsClient.members["sales@example.com"] = []*gws.GroupMember{{Email: "caroline@example.com", Type: "USER", Status: "ACTIVE"}}
+
+	result, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"})
+	if err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	if result.UsersCreated != 0 || len(biClient.users) != 1 {
+		t.Fatalf("Expected the renamed user to be matched instead of created, got %d users", len(biClient.users))
+	}
+	renamed := biClient.users[carol.ID]
+	if renamed.UserName != "caroline@example.com" || renamed.Emails[0].Value != "caroline@example.com" {
+		t.Errorf("Expected carol to be renamed, got %+v", renamed)
+	}
+
+	group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Sales")
+	if len(group.Members) != 1 || group.Members[0].Value != carol.ID {
+		t.Errorf("Expected the renamed user to keep her membership, got %+v", group.Members)
+	}
+}
+
+func TestSync_LegacyExternalIDIsCorrelated(t *testing.T) {
+	engine, _, biClient := newCorrelationTestEngine()
+	biClient.users["legacy-1"] = &bi.User{
+		ID:         "legacy-1",
+		ExternalID: "carol@example.com",
+		UserName:   "carol@example.com",
+		Emails:     []bi.Email{{Value: "carol@example.com", Primary: true}},
+		Active:     true,
+	}
+
+	result, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"})
+	if err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	if got := biClient.users["legacy-1"].ExternalID; got != "g-carol" {
+		t.Errorf("Expected the externalId to be replaced with the Google user ID, got %q", got)
+	}
+	if len(result.UserConflicts) != 0 {
+		t.Errorf("Expected no conflicts for a user the sync provisioned, got %+v", result.UserConflicts)
+	}
+}
+
+func TestRenamedEmails(t *testing.T) {
+	emails := renamedEmails([]bi.Email{
+		{Value: "old@example.com", Type: "work", Primary: true},
+		{Value: "alias@example.com", Type: "other"},
+	}, "new@example.com")
+	if len(emails) != 2 || emails[0].Value != "new@example.com" || emails[1].Value != "alias@example.com" {
+		t.Errorf("Expected the primary address to be replaced, got %+v", emails)
+	}
+
+	if emails := renamedEmails(nil, "new@example.com"); len(emails) != 1 || !emails[0].Primary {
+		t.Errorf("Expected a primary address to be added, got %+v", emails)
+	}
+}
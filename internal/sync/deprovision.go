@@ -0,0 +1,193 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// userDeactivator is implemented by targets that can deactivate users
+type userDeactivator interface {
+	DeactivateUser(ctx context.Context, userID string) error
+}
+
+// DeprovisionMembership is a managed group a user is, or was, removed from
+type DeprovisionMembership struct {
+	SourceGroup string `json:"source_group"`
+	Target      string `json:"target"`
+	GroupID     string `json:"group_id"`
+	GroupName   string `json:"group_name"`
+}
+
+// DeprovisionResult describes the memberships a deprovisioning removes and what it changed
+type DeprovisionResult struct {
+	Email       string                  `json:"email"`
+	Memberships []DeprovisionMembership `json:"memberships"`
+	Deactivated []string                `json:"deactivated,omitempty"` // Targets the user was deactivated in
+	Errors      []string                `json:"errors,omitempty"`
+
+	userIDs map[string]string // target -> user ID
+}
+
+// PlanDeprovision lists the managed groups the user would be removed from, without changing anything
+func (e *Engine) PlanDeprovision(ctx context.Context, email string) (*DeprovisionResult, error) {
+	if err := e.requireFeature(config.FeatureDeprovisioning); err != nil {
+		return nil, err
+	}
+
+	plan := &DeprovisionResult{
+		Email:       email,
+		Memberships: []DeprovisionMembership{},
+		userIDs:     make(map[string]string),
+	}
+
+	lookedUp := make(map[string]bool)
+	for _, groupEmail := range e.configuredGroups() {
+		targetName := e.config.TargetForGroup(groupEmail)
+		biClient, err := e.clientForTarget(targetName)
+		if err != nil {
+			return nil, err
+		}
+
+		if !lookedUp[targetName] {
+			lookedUp[targetName] = true
+			user, err := biClient.FindUserByEmail(ctx, email)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search for user in target %s: %w", targetName, err)
+			}
+			if user != nil {
+				plan.userIDs[targetName] = user.ID
+			}
+		}
+		userID, ok := plan.userIDs[targetName]
+		if !ok {
+			continue
+		}
+
+		groupID, groupName, err := e.managedGroup(ctx, biClient, groupEmail, targetName)
+		if err != nil {
+			return nil, err
+		}
+		if groupID == "" {
+			continue
+		}
+
+		isMember, err := e.isGroupMember(ctx, biClient, groupID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			plan.Memberships = append(plan.Memberships, DeprovisionMembership{
+				SourceGroup: groupEmail,
+				Target:      targetName,
+				GroupID:     groupID,
+				GroupName:   groupName,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// DeprovisionUser removes the user from every managed group and optionally deactivates them in
+// each target they exist in; failures are collected so one bad group does not block the rest
+func (e *Engine) DeprovisionUser(ctx context.Context, email string, deactivate bool) (*DeprovisionResult, error) {
+	plan, err := e.PlanDeprovision(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DeprovisionResult{Email: email, Memberships: []DeprovisionMembership{}}
+	for _, membership := range plan.Memberships {
+		biClient, _ := e.clientForTarget(membership.Target)
+		userID := plan.userIDs[membership.Target]
+
+		if e.simulatedOutsideRun(config.OperationRemove) {
+			e.logger.Infof("TEST MODE: Would remove %s from group %s", email, membership.GroupName)
+			result.Memberships = append(result.Memberships, membership)
+			continue
+		}
+
+		e.logger.Infof("Deprovisioning: removing %s from group %s", email, membership.GroupName)
+		if err := biClient.UpdateGroupMembers(ctx, membership.GroupID, nil, []bi.GroupMember{{Value: userID}}); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("group %s: %v", membership.GroupName, err))
+			continue
+		}
+		result.Memberships = append(result.Memberships, membership)
+		e.recordChange(nil, report.ChangeRecord{Action: state.ChangeMembershipRemoved, User: email, Group: membership.SourceGroup, Target: membership.Target})
+	}
+
+	if deactivate {
+		targetNames := make([]string, 0, len(plan.userIDs))
+		for targetName := range plan.userIDs {
+			targetNames = append(targetNames, targetName)
+		}
+		sort.Strings(targetNames)
+		for _, targetName := range targetNames {
+			e.deactivateUser(ctx, result, targetName, plan.userIDs[targetName])
+		}
+	}
+
+	if err := e.state.Save(); err != nil {
+		e.logger.Errorf("Failed to save sync state: %v", err)
+	}
+	return result, nil
+}
+
+// deactivateUser deactivates the user in one target and records the outcome
+func (e *Engine) deactivateUser(ctx context.Context, result *DeprovisionResult, targetName, userID string) {
+	biClient, _ := e.clientForTarget(targetName)
+	deactivator, ok := biClient.(userDeactivator)
+	if !ok {
+		result.Errors = append(result.Errors, fmt.Sprintf("target %s: deactivating users is not supported", targetName))
+		return
+	}
+
+	if e.simulatedOutsideRun(config.OperationDeactivate) {
+		e.logger.Infof("TEST MODE: Would deactivate %s in target %s", result.Email, targetName)
+		result.Deactivated = append(result.Deactivated, targetName)
+		return
+	}
+
+	e.logger.Infof("Deprovisioning: deactivating %s in target %s", result.Email, targetName)
+	if err := deactivator.DeactivateUser(ctx, userID); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("deactivate in target %s: %v", targetName, err))
+		return
+	}
+	result.Deactivated = append(result.Deactivated, targetName)
+	e.recordChange(nil, report.ChangeRecord{Action: state.ChangeUserDeactivated, User: result.Email, ID: userID, Target: targetName})
+}
+
+// managedGroup resolves the Beyond Identity group a configured source group is synced to, from
+// the recorded mapping or else by its expected display name; an empty ID means it does not exist
+func (e *Engine) managedGroup(ctx context.Context, biClient BIClient, groupEmail, targetName string) (string, string, error) {
+	if group, ok := e.state.Group(groupEmail); ok && group.BIGroupID != "" && group.Target == targetName {
+		return group.BIGroupID, group.BIGroupName, nil
+	}
+
+	gwsGroup, err := e.sourceGroup(ctx, groupEmail)
+	if err != nil {
+		if isGroupNotFound(err) {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("failed to get GWS group: %w", err)
+	}
+
+	groupName := e.config.GroupPrefixForTarget(targetName) + gwsGroup.Name
+	biGroup, err := e.findBIGroup(ctx, biClient, targetName, groupName)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to search for group: %w", err)
+	}
+	if biGroup == nil {
+		return "", "", nil
+	}
+	if biGroup.DisplayName != "" {
+		groupName = biGroup.DisplayName
+	}
+	return biGroup.ID, groupName, nil
+}
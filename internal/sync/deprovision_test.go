@@ -0,0 +1,99 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func (m *mockBIClient) DeactivateUser(ctx context.Context, userID string) error {
+	if user, exists := m.users[userID]; exists {
+		user.Active = false
+	}
+	return nil
+}
+
+func TestDeprovisionUser(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	plan, err := engine.PlanDeprovision(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(plan.Memberships) != 1 || plan.Memberships[0].SourceGroup != "eng@example.com" {
+		t.Fatalf("Expected alice's engineering membership in the plan, got %+v", plan.Memberships)
+	}
+	eng, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	if len(eng.Members) != 2 {
+		t.Fatalf("Expected planning not to change membership, got %+v", eng.Members)
+	}
+
+	result, err := engine.DeprovisionUser(context.Background(), "alice@example.com", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Memberships) != 1 || len(result.Errors) != 0 {
+		t.Errorf("Expected one membership removed without errors, got %+v", result)
+	}
+	if len(result.Deactivated) != 1 {
+		t.Errorf("Expected alice to be deactivated in one target, got %v", result.Deactivated)
+	}
+	if len(eng.Members) != 1 {
+		t.Errorf("Expected only bob to remain in GWS_Engineering, got %+v", eng.Members)
+	}
+	if user, _ := biClient.FindUserByEmail(context.Background(), "alice@example.com"); user == nil || user.Active {
+		t.Errorf("Expected alice to be inactive, got %+v", user)
+	}
+
+	// Unknown users have nothing to remove
+	plan, err = engine.PlanDeprovision(context.Background(), "nobody@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(plan.Memberships) != 0 {
+		t.Errorf("Expected empty plan for an unknown user, got %+v", plan.Memberships)
+	}
+}
+
+func TestDeprovisionUser_SimulatedDeactivation(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	engine.config.Sync.TestModeOperations = []string{config.OperationDeactivate}
+
+	result, err := engine.DeprovisionUser(context.Background(), "alice@example.com", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	eng, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	if len(result.Memberships) != 1 || len(eng.Members) != 1 {
+		t.Errorf("Expected alice to be removed from GWS_Engineering, got %+v", eng.Members)
+	}
+	if user, _ := biClient.FindUserByEmail(context.Background(), "alice@example.com"); user == nil || !user.Active {
+		t.Errorf("Expected the deactivation to be simulated, got %+v", user)
+	}
+}
+
+func TestDeprovisionUser_StaysInactiveWithUserUpdates(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.Features = map[string]bool{config.FeatureUserUpdates: true}
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := engine.DeprovisionUser(context.Background(), "alice@example.com", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// alice is still in the Google group, so the next run compares her attributes again
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user, _ := biClient.FindUserByEmail(context.Background(), "alice@example.com"); user == nil || user.Active {
+		t.Errorf("Expected the deprovisioned user to stay inactive, got %+v", user)
+	}
+}
@@ -0,0 +1,42 @@
+package sync
+
+import "sort"
+
+// GroupDiff lists the users added to and removed from one group during a run
+type GroupDiff struct {
+	Group   string   `json:"group"`            // Beyond Identity group name, or the enrollment group
+	Target  string   `json:"target,omitempty"` // Target the group is in; state.TargetGoogleWorkspace for the enrollment group
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// recordDiff merges membership changes to a group into the run's diffs, keeping groups in the order
+// first changed and users in each sorted
+func (r *SyncResult) recordDiff(targetName, group string, added, removed []string) {
+	r.MembershipDiffs = mergeDiff(r.MembershipDiffs, targetName, group, added, removed)
+}
+
+// recordSimulatedDiff merges membership changes that sync.test_mode_operations only logged
+func (r *SyncResult) recordSimulatedDiff(targetName, group string, added, removed []string) {
+	r.SimulatedDiffs = mergeDiff(r.SimulatedDiffs, targetName, group, added, removed)
+}
+
+func mergeDiff(diffs []GroupDiff, targetName, group string, added, removed []string) []GroupDiff {
+	if len(added) == 0 && len(removed) == 0 {
+		return diffs
+	}
+
+	for i := range diffs {
+		if diffs[i].Group == group && diffs[i].Target == targetName {
+			diffs[i].Added = append(diffs[i].Added, added...)
+			diffs[i].Removed = append(diffs[i].Removed, removed...)
+			sort.Strings(diffs[i].Added)
+			sort.Strings(diffs[i].Removed)
+			return diffs
+		}
+	}
+	diff := GroupDiff{Group: group, Target: targetName, Added: append([]string(nil), added...), Removed: append([]string(nil), removed...)}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	return append(diffs, diff)
+}
@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// extractDisplayName derives a display name from an email address, e.g. "Élodie Dupont" from
+// elodie.dupont+github2@example.com: separators become spaces, plus-address tags and numeric
+// suffixes are dropped and each word is title cased with the casing rules of locale
+func extractDisplayName(email string, locale language.Tag) string {
+	localPart := email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		localPart = email[:at]
+	}
+
+	// Drop plus-address tags such as the +github in alice+github
+	if plus := strings.Index(localPart, "+"); plus > 0 {
+		localPart = localPart[:plus]
+	}
+
+	caser := cases.Title(locale)
+	var words []string
+	for _, word := range strings.FieldsFunc(localPart, isNameSeparator) {
+		// Drop the numbers that make addresses unique, e.g. john.smith2 or john.smith.2
+		word = strings.TrimRightFunc(word, unicode.IsDigit)
+		if word != "" {
+			words = append(words, caser.String(word))
+		}
+	}
+
+	if len(words) == 0 {
+		// Keep numeric local parts such as employee IDs; fall back to the email if there is none
+		if localPart != "" {
+			return localPart
+		}
+		return email
+	}
+	return strings.Join(words, " ")
+}
+
+// isNameSeparator reports whether r separates the words of a name in an email local part
+func isNameSeparator(r rune) bool {
+	return r == '.' || r == '_' || r == '-' || unicode.IsSpace(r)
+}
@@ -0,0 +1,108 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmergencyStop_DisableFile(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.config.Sync.DisableFile = filepath.Join(t.TempDir(), "state.json.disabled")
+
+	stop, err := engine.EmergencyStop("bad upstream change", "oncall")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stop.Source != StopSourceFile {
+		t.Errorf("Expected the stop to be recorded in the disable file, got %s", stop.Source)
+	}
+
+	// Other processes see the stop through the file
+	other, _, _ := newTargetedTestEngine()
+	other.config.Sync.DisableFile = engine.config.Sync.DisableFile
+	seen := other.EmergencyStopped()
+	if seen == nil || seen.Reason != "bad upstream change" || seen.RequestedBy != "oncall" {
+		t.Fatalf("Expected the stop to be read from the disable file, got %+v", seen)
+	}
+
+	if _, err := engine.Sync(context.Background()); !errors.Is(err, ErrSyncDisabled) {
+		t.Errorf("Expected full runs to be refused, got %v", err)
+	}
+	if _, err := engine.SyncUser(context.Background(), "alice@example.com"); !errors.Is(err, ErrSyncDisabled) {
+		t.Errorf("Expected targeted runs to be refused, got %v", err)
+	}
+
+	if err := engine.ResumeSyncs(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(engine.config.Sync.DisableFile); !os.IsNotExist(err) {
+		t.Error("Expected resuming to remove the disable file")
+	}
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Errorf("Expected runs after resuming, got %v", err)
+	}
+}
+
+func TestEmergencyStop_ManualFile(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.config.Sync.DisableFile = filepath.Join(t.TempDir(), "disabled")
+	if err := os.WriteFile(engine.config.Sync.DisableFile, []byte("INC-42 duplicate users\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := engine.EmergencyStopped()
+	if stop == nil || stop.Reason != "INC-42 duplicate users" || stop.StoppedAt.IsZero() {
+		t.Fatalf("Expected a plain text file to stop syncs with its contents as the reason, got %+v", stop)
+	}
+
+	// A run in progress stops at its next group or user
+	result := &SyncResult{}
+	if !engine.runCancelled(context.Background(), result) || !strings.Contains(result.AbortReason, "INC-42") {
+		t.Errorf("Expected the run to be aborted by the emergency stop, got %q", result.AbortReason)
+	}
+}
+
+func TestEmergencyStop_InMemory(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+
+	if _, err := engine.EmergencyStop("", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stop := engine.EmergencyStopped(); stop == nil || stop.Source != StopSourceAPI {
+		t.Fatalf("Expected the stop to be kept in memory without a disable file, got %+v", stop)
+	}
+	if _, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"}); !errors.Is(err, ErrSyncDisabled) {
+		t.Errorf("Expected group runs to be refused, got %v", err)
+	}
+
+	if err := engine.ResumeSyncs(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if engine.EmergencyStopped() != nil {
+		t.Error("Expected syncs to be resumed")
+	}
+}
+
+func TestEmergencyStop_EnvVar(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	t.Setenv(DisabledEnvVar, "1")
+
+	if stop := engine.EmergencyStopped(); stop == nil || stop.Source != StopSourceEnv {
+		t.Fatalf("Expected %s to stop syncs, got %+v", DisabledEnvVar, stop)
+	}
+	if _, err := engine.Sync(context.Background()); !errors.Is(err, ErrSyncDisabled) {
+		t.Errorf("Expected runs to be refused, got %v", err)
+	}
+	if err := engine.ResumeSyncs(); err == nil {
+		t.Error("Expected a stop from the environment not to be lifted at runtime")
+	}
+
+	t.Setenv(DisabledEnvVar, "0")
+	if engine.EmergencyStopped() != nil {
+		t.Error("Expected a false value not to stop syncs")
+	}
+}
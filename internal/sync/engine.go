@@ -1,133 +1,464 @@
 package sync
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	gosync "sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/attributes"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
 	"github.com/sirupsen/logrus"
 )
 
 // Engine orchestrates the synchronization between Google Workspace and Beyond Identity
 type Engine struct {
 	gwsClient GWSClient
+	source    SourceClient
 	biClient  BIClient
+	targets   map[string]BIClient
 	config    *config.Config
+	state     *state.Store
 	logger    *logrus.Logger
+
+	lockPath string       // Sync lock shared with other processes using the state file
+	runMu    gosync.Mutex // Serializes runs within this process
+
+	readOnly  atomic.Bool                   // Toggled at runtime; see SetReadOnly
+	runDryRun atomic.Bool                   // Set while a run requested as a dry run is in progress; see RunOptions
+	stop      atomic.Pointer[EmergencyStop] // Set by EmergencyStop when there is no disable file
+
+	metrics      *MetricsSinks // Receives runs and groups; see SetMetricsSinks
+	changes      ChangeReader  // Audit log read by incremental runs; see ConfigureIncremental
+	explainEmail string        // User whose decisions runs record; see Explain
+	version      string        // Build written to group annotations; see SetVersion
+
+	onStuck    func(StuckRun)             // See OnStuckRun
+	onFinished func(*SyncResult, error)   // See OnSyncFinished
+	recent     *recentRuns                // See RecentRuns
+	cancelled  atomic.Bool                // Set by the watchdog to stop the current run
+	control    atomic.Pointer[runControl] // Pauses, stops or cancels the run in progress; see RunInProgress
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration)
 }
 
 // SyncResult contains the results of a synchronization operation
 type SyncResult struct {
-	GroupsProcessed    int
-	UsersCreated       int
-	UsersUpdated       int
-	GroupsCreated      int
-	MembershipsAdded   int
-	MembershipsRemoved int
-	Errors             []error
+	GroupsProcessed     int
+	UsersCreated        int
+	UsersUpdated        int
+	GroupsCreated       int
+	MembershipsAdded    int
+	MembershipsRemoved  int
+	Errors              []error
+	GroupsSkipped       int                   // Orphaned groups not retried
+	UsersSkipped        int                   // Users on the skip list
+	UsersFiltered       int                   // Members sync.filters kept from being provisioned
+	UserConflicts       []UserConflict        // Existing users the sync did not provision, and what sync.conflict_policy did
+	OrphanedGroups      []string              // Source groups found deleted during this run
+	GroupsCleanedUp     []string              // Orphaned Beyond Identity groups deleted or emptied by sync.orphaned_groups.cleanup
+	AuthErrors          int                   // Errors caused by rejected credentials
+	Aborted             bool                  // Run stopped early; see AbortReason
+	Stopped             bool                  // Aborted between groups by Engine.StopRun, e.g. on Ctrl-C
+	AbortReason         string                // Why the run was aborted
+	SkippedSteps        []string              // Steps skipped because a dependency was unavailable
+	MembershipDiffs     []GroupDiff           // Users added to and removed from each group
+	SimulatedDiffs      []GroupDiff           // Membership changes only logged because sync.test_mode_operations lists them
+	ReadOnly            bool                  // Run started in read-only mode; changes were reported but not written
+	DryRun              bool                  // Run was requested as a dry run; changes were reported but not written
+	QuotaUsage          []QuotaUsage          // Share of each target's API quota used by the run
+	PartialGroups       []string              // Groups synced from incomplete membership; no members were removed from them
+	DeferredQueued      int                   // Failed writes queued for retry on later runs
+	DeferredRetried     int                   // Queued writes that succeeded during this run
+	DeferredExpired     int                   // Queued writes dropped after sync.retry_queue_hours
+	QueueDepth          int                   // Writes still queued when the run finished
+	ShadowDiscrepancies []ShadowDiscrepancy   // Changes only one of the live run and the shadow planner made
+	Changes             []report.ChangeRecord // Each change made, planned or failed, in the order the run got to it
+	PlannedUsers        []report.PlannedUser  // Users a test mode or read-only run, or simulated creations, would create
+	PlannedGroups       []report.PlannedGroup // Groups a test mode or read-only run, or simulated creations, would create
+	Incremental         bool                  // Only groups changed since the last successful run were synced
+	GroupsUnchanged     int                   // Groups an incremental run skipped because they had not changed
+	Explanation         []report.ExplainStep  // Decisions made about the user set with Engine.Explain
+
+	pacer          *pacer                        // Spreads user operations over sync.spread_over
+	members        map[string][]*gws.GroupMember // Source members read while planning the pacing
+	quotaStart     map[string]int64              // Requests each target had made before the run
+	sourceEmails   map[string]string             // Canonical addresses of configured group aliases
+	flags          *runFlags                     // Shared with the results of groups synced concurrently
+	directory      *userDirectory                // Profiles of the users the run creates; nil reads each one
+	biUsers        *biUserCache                  // Users of each target listed with user_prefetch; nil looks each one up
+	filter         *userFilter                   // Compiled sync.filters; nil when none are configured
+	filterCompiled bool                          // filter was compiled for this run
+	trace          *explainTrace                 // Decisions about the explained user; nil when none is
+	explaining     string                        // Group the explained decisions are made for
 }
 
+// SkippedNativeAPIUnavailable is recorded against steps skipped because the Native API failed
+const SkippedNativeAPIUnavailable = "skipped: native API unavailable"
+
 // NewEngine creates a new sync engine
 func NewEngine(gwsClient GWSClient, biClient BIClient, cfg *config.Config, logger *logrus.Logger) *Engine {
-	return &Engine{
+	// Keep state in memory until ConfigureState loads the state file
+	store, _ := state.Open("")
+
+	engine := &Engine{
 		gwsClient: gwsClient,
+		source:    gwsClient,
 		biClient:  biClient,
+		targets:   make(map[string]BIClient),
 		config:    cfg,
+		state:     store,
 		logger:    logger,
+		now:       time.Now,
+		sleep:     sleepContext,
+		recent:    newRecentRuns(cfg.Sync.RecentRuns),
 	}
+	engine.readOnly.Store(cfg.App.ReadOnly)
+	return engine
 }
 
-// Sync performs the complete synchronization process
-func (e *Engine) Sync() (*SyncResult, error) {
-	result := &SyncResult{}
+// SetSource overrides where group membership is read from; the enrollment group is still managed in Google Workspace
+func (e *Engine) SetSource(source SourceClient) {
+	e.source = source
+}
+
+// AddTarget registers the client for an additional Beyond Identity tenant configured under targets
+func (e *Engine) AddTarget(name string, client BIClient) {
+	e.targets[name] = client
+}
+
+// clientForTarget returns the Beyond Identity client for the named target
+func (e *Engine) clientForTarget(name string) (BIClient, error) {
+	if name == config.DefaultTargetName {
+		return e.biClient, nil
+	}
+
+	client, ok := e.targets[name]
+	if !ok {
+		return nil, fmt.Errorf("no client registered for target %s", name)
+	}
+	return client, nil
+}
+
+// Sync performs the complete synchronization process; with sync.incremental.enabled set, only the
+// groups changed since the last successful run are synced unless a full sync is due
+func (e *Engine) Sync(ctx context.Context) (*SyncResult, error) {
+	return e.sync(ctx, false)
+}
+
+// SyncFull performs the complete synchronization process, syncing every configured group even
+// when incremental sync is enabled
+func (e *Engine) SyncFull(ctx context.Context) (*SyncResult, error) {
+	return e.sync(ctx, true)
+}
+
+func (e *Engine) sync(ctx context.Context, forceFull bool) (*SyncResult, error) {
+	ctx, finish, err := e.beginRun(ctx, RunKindFull, "")
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := e.now()
+	delta := e.planDelta(ctx, forceFull, startedAt)
+	result, err := e.syncGroupsDelta(ctx, e.configuredGroups(), delta)
+	if err == nil {
+		e.cleanupAfterSync(ctx, result)
+	}
+	e.recordCheckpoint(startedAt, result, err)
+	finish(result, err)
+	if e.onFinished != nil && result != nil {
+		e.onFinished(result, err)
+	}
+	return result, err
+}
+
+// OnSyncFinished sets a function called with the result of every full run that got as far as
+// syncing groups, failed or not, e.g. to send usage telemetry; it runs before Sync returns
+func (e *Engine) OnSyncFinished(handler func(*SyncResult, error)) {
+	e.onFinished = handler
+}
+
+// SyncGroups synchronizes only the given configured groups
+func (e *Engine) SyncGroups(ctx context.Context, groupEmails []string) (*SyncResult, error) {
+	groups := make([]string, 0, len(groupEmails))
+	for _, groupEmail := range groupEmails {
+		configured, ok := e.configuredGroup(groupEmail)
+		if !ok {
+			return nil, fmt.Errorf("group %s is not configured for sync", groupEmail)
+		}
+		groups = append(groups, configured)
+	}
+
+	ctx, finish, err := e.beginRun(ctx, RunKindGroups, strings.Join(groups, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := e.syncGroups(ctx, groups)
+	finish(result, err)
+	return result, err
+}
+
+// syncGroups runs the synchronization process for a list of groups
+func (e *Engine) syncGroups(ctx context.Context, groupEmails []string) (*SyncResult, error) {
+	return e.syncGroupsDelta(ctx, groupEmails, nil)
+}
+
+// syncGroupsDelta runs the synchronization process for the groups of a list that delta selects;
+// a nil delta syncs them all
+func (e *Engine) syncGroupsDelta(ctx context.Context, groupEmails []string, delta *deltaPlan) (*SyncResult, error) {
+	result := &SyncResult{directory: &userDirectory{}, biUsers: &biUserCache{}}
+	e.startExplaining(result)
+	defer result.finishExplaining()
 
 	e.logger.Info("Starting sync process...")
 
-	for _, groupEmail := range e.config.Sync.Groups {
-		e.logger.Infof("Processing group: %s", groupEmail)
+	// Compile sync.filters once, so groups synced concurrently share them
+	if _, err := e.runFilter(result); err != nil {
+		return nil, err
+	}
 
-		if err := e.syncGroup(groupEmail, result); err != nil {
-			e.logger.Errorf("Failed to sync group %s: %v", groupEmail, err)
-			result.Errors = append(result.Errors, fmt.Errorf("group %s: %w", groupEmail, err))
-			continue
+	// Reload sources such as CSV exports so each run sees the latest delivery
+	if r, ok := e.source.(refresher); ok {
+		if err := r.Refresh(ctx); err != nil {
+			return nil, fmt.Errorf("failed to refresh membership source: %w", err)
 		}
+	}
+
+	if e.ReadOnly() {
+		result.ReadOnly = true
+		e.logger.Warn("Read-only mode: changes will be reported but not written")
+	}
+	if e.runDryRun.Load() {
+		result.DryRun = true
+		e.logger.Info("Dry run requested: changes will be reported but not written")
+	}
+
+	e.startQuotaTracking(result)
+	e.retryDeferred(ctx, result)
+	groupEmails = e.resolveAliases(ctx, groupEmails, result)
+	if delta != nil {
+		groupEmails = e.changedGroups(groupEmails, delta, result)
+	}
+	e.planPacing(ctx, groupEmails, result)
 
-		result.GroupsProcessed++
+	// Plan before writing, so the shadow planner sees the same starting state as the live run
+	shadowMode := e.shadowEnabled()
+	var shadow []shadowPlan
+	if shadowMode {
+		shadow = e.planShadow(ctx, groupEmails, result)
 	}
 
+	if e.config.Sync.Concurrency > 1 && len(groupEmails) > 1 {
+		e.syncGroupsConcurrently(ctx, groupEmails, result)
+	} else {
+		for _, groupEmail := range groupEmails {
+			if e.nextGroup(ctx, result) {
+				break
+			}
+			if e.runGroup(ctx, groupEmail, result) {
+				break
+			}
+		}
+	}
+
+	if shadowMode {
+		e.compareShadow(shadow, result)
+	}
+
+	e.recordQuotaUsage(result)
+	result.QueueDepth = e.state.QueueDepth()
+
 	e.logger.Infof("Sync completed. Groups: %d, Users created: %d, Users updated: %d, Groups created: %d, Memberships added: %d, Memberships removed: %d, Errors: %d",
 		result.GroupsProcessed, result.UsersCreated, result.UsersUpdated, result.GroupsCreated,
 		result.MembershipsAdded, result.MembershipsRemoved, len(result.Errors))
 
+	if err := e.state.Save(); err != nil {
+		e.logger.Errorf("Failed to save sync state: %v", err)
+	}
+
+	if result.Aborted {
+		e.logger.Errorf("Sync aborted: %s", result.AbortReason)
+		return result, fmt.Errorf("%w: %s", ErrSyncAborted, result.AbortReason)
+	}
+
 	return result, nil
 }
 
+// runGroup syncs one group of a run and records its outcome in result; it reports whether the
+// run was aborted
+func (e *Engine) runGroup(ctx context.Context, groupEmail string, result *SyncResult) bool {
+	if e.skipOrphaned(groupEmail) {
+		result.GroupsSkipped++
+		result.explainGroup(groupEmail, e.config.TargetForGroup(groupEmail), "not synced: deleted from the source and not retried until the sync configuration changes")
+		return false
+	}
+
+	e.logger.Infof("Processing group: %s", groupEmail)
+
+	finishOp := e.startOp(OpGroup, groupEmail)
+	err := e.syncGroup(ctx, groupEmail, result)
+	finishOp(err)
+	if err != nil {
+		// The errors that caused an abort are already recorded
+		if result.Aborted {
+			return true
+		}
+		if errors.Is(err, errGroupOrphaned) {
+			return false
+		}
+
+		e.logger.Errorf("Failed to sync group %s: %v", groupEmail, err)
+		e.addError(result, "group", groupEmail, err)
+		result.explainGroup(groupEmail, e.config.TargetForGroup(groupEmail), "sync failed: "+err.Error())
+
+		// Explain how to fix missing delegation or admin privileges once per run rather than per group
+		var scopeErr *gws.ScopeError
+		var privilegeErr *gws.PrivilegeError
+		if errors.As(err, &scopeErr) && result.runFlags().setOnce(flagRemediation+scopeErr.Scope) {
+			e.logger.Error(scopeErr.Remediation())
+		} else if errors.As(err, &privilegeErr) && result.runFlags().setOnce(flagRemediation+"privilege:"+privilegeErr.Admin) {
+			e.logger.Error(privilegeErr.Remediation())
+		}
+		return false
+	}
+
+	result.GroupsProcessed++
+	return false
+}
+
+// addError records a failure for a user, group or step and aborts the run when fail_fast
+// is set, the error budget is spent or authentication errors reach the threshold
+func (e *Engine) addError(result *SyncResult, kind, subject string, err error) {
+	result.Errors = append(result.Errors, &SyncError{Kind: kind, Subject: subject, Err: err})
+	if isAuthError(err) {
+		result.AuthErrors++
+	}
+	if result.Aborted {
+		return
+	}
+
+	syncCfg := e.config.Sync
+	switch {
+	case syncCfg.FailFast:
+		result.AbortReason = fmt.Sprintf("fail_fast is enabled: %v", result.Errors[len(result.Errors)-1])
+	case syncCfg.ErrorBudget > 0 && len(result.Errors) >= syncCfg.ErrorBudget:
+		result.AbortReason = fmt.Sprintf("error budget of %d exhausted, last: %v", syncCfg.ErrorBudget, rootCause(err))
+	case isAuthError(err) && syncCfg.AuthErrorThreshold > 0 && result.AuthErrors >= syncCfg.AuthErrorThreshold:
+		result.AbortReason = fmt.Sprintf("%d authentication errors, last: %v", result.AuthErrors, rootCause(err))
+	default:
+		return
+	}
+	result.Aborted = true
+}
+
 // syncGroup synchronizes a single Google Workspace group to Beyond Identity
-func (e *Engine) syncGroup(groupEmail string, result *SyncResult) error {
-	// Get the Google Workspace group
-	gwsGroup, err := e.gwsClient.GetGroup(groupEmail)
+func (e *Engine) syncGroup(ctx context.Context, groupEmail string, result *SyncResult) error {
+	result.explaining = groupEmail
+
+	// Resolve the Beyond Identity tenant this group is provisioned into
+	targetName := e.config.TargetForGroup(groupEmail)
+	biClient, err := e.clientForTarget(targetName)
 	if err != nil {
+		return err
+	}
+	if targetName != config.DefaultTargetName {
+		e.logger.Infof("Provisioning group %s into target %s", groupEmail, targetName)
+	}
+
+	// Get the source group, by its canonical address if groupEmail is a resolved alias
+	sourceEmail := result.sourceEmail(groupEmail)
+	gwsGroup, err := e.sourceGroup(ctx, sourceEmail)
+	if err != nil {
+		if isGroupNotFound(err) {
+			return e.orphanGroup(ctx, groupEmail, biClient, result)
+		}
 		return fmt.Errorf("failed to get GWS group: %w", err)
 	}
 
-	// Get group members from Google Workspace
-	gwsMembers, err := e.gwsClient.GetGroupMembers(groupEmail)
+	// Get group members from the source
+	gwsMembers, err := e.groupMembers(ctx, groupEmail, result)
 	if err != nil {
 		return fmt.Errorf("failed to get GWS group members: %w", err)
 	}
 
 	e.logger.Infof("Found %d members in Google Workspace group %s", len(gwsMembers), groupEmail)
+	e.explainSourceMembership(groupEmail, targetName, gwsMembers, result)
 
 	// Create or get the Beyond Identity group
-	biGroupName := e.config.BeyondIdentity.GroupPrefix + gwsGroup.Name
-	biGroup, err := e.ensureBIGroup(biGroupName, gwsGroup.Description, result)
+	biGroupName := e.config.GroupPrefixForTarget(targetName) + gwsGroup.Name
+	biGroup, err := e.ensureBIGroup(ctx, biClient, targetName, groupEmail, biGroupName, gwsGroup.Description, result)
 	if err != nil {
 		return fmt.Errorf("failed to ensure BI group: %w", err)
 	}
+	if biGroup.DisplayName != "" {
+		biGroupName = biGroup.DisplayName // May carry an earlier prefix
+	}
+	e.recordGroup(groupEmail, targetName, biGroup.ID, biGroupName)
 
 	// Sync users and collect their IDs
-	userIDs, err := e.syncUsers(gwsMembers, result)
+	users, err := e.syncUsers(ctx, biClient, targetName, gwsMembers, result)
 	if err != nil {
 		return fmt.Errorf("failed to sync users: %w", err)
 	}
 
 	// Update group membership
-	if err := e.updateGroupMembership(biGroup.ID, userIDs, result); err != nil {
+	if err := e.updateGroupMembership(ctx, biClient, groupEmail, targetName, biGroup.ID, biGroupName, users, result); err != nil {
 		return fmt.Errorf("failed to update group membership: %w", err)
 	}
+	e.annotateGroup(ctx, biClient, groupEmail, targetName, biGroup.ID, result)
 
-	// Sync enrollment status to Google Workspace
+	if e.config.Sync.Aliases.CreateGroups {
+		e.syncAliasGroups(ctx, biClient, targetName, sourceEmail, users, result)
+	}
+	if e.config.Sync.AdminGroups {
+		e.syncAdminGroup(ctx, biClient, targetName, groupEmail, gwsGroup, gwsMembers, users, result)
+	}
+
+	// Sync enrollment status to Google Workspace, unless the Native API already failed this run
+	if result.runFlags().isSet(flagNativeAPIDown + targetName) {
+		e.logger.Debugf("Skipping enrollment status sync for group %s: native API unavailable", groupEmail)
+		return nil
+	}
 	e.logger.Infof("Starting enrollment status sync for %d members", len(gwsMembers))
-	if err := e.syncEnrollmentStatus(gwsMembers, result); err != nil {
+	if err := e.syncEnrollmentStatus(ctx, biClient, targetName, gwsMembers, result); err != nil {
 		e.logger.Errorf("Failed to sync enrollment status: %v", err)
-		result.Errors = append(result.Errors, fmt.Errorf("enrollment sync: %w", err))
+		e.addError(result, "enrollment", "", err)
 	}
 
 	return nil
 }
 
-// ensureBIGroup creates or retrieves a Beyond Identity group
-func (e *Engine) ensureBIGroup(groupName, description string, result *SyncResult) (*bi.Group, error) {
+// ensureBIGroup creates or retrieves a Beyond Identity group. groupEmail is the source group the
+// state file maps it to, if any, so a plan can record it when applied
+func (e *Engine) ensureBIGroup(ctx context.Context, biClient BIClient, targetName, groupEmail, groupName, description string, result *SyncResult) (*bi.Group, error) {
 	// Try to find existing group
-	existingGroup, err := e.biClient.FindGroupByDisplayName(groupName)
+	existingGroup, err := e.findBIGroup(ctx, biClient, targetName, groupName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search for group: %w", err)
 	}
 
 	if existingGroup != nil {
-		e.logger.Debugf("Using existing group: %s (ID: %s)", groupName, existingGroup.ID)
+		e.logger.Debugf("Using existing group: %s (ID: %s)", existingGroup.DisplayName, existingGroup.ID)
 		return existingGroup, nil
 	}
 
 	// Create new group
-	if e.config.App.TestMode {
+	if e.simulated(config.OperationCreate) {
 		e.logger.Infof("TEST MODE: Would create group '%s' with description '%s'", groupName, description)
+		result.planGroup(targetName, groupName, groupEmail)
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeGroupCreated, Group: groupName, Target: targetName, Planned: true})
 		// Return a mock group for test mode (no actual API call made)
 		return &bi.Group{
-			ID:          "mock-group-id-for-testing",
+			ID:          plannedGroupID,
 			DisplayName: groupName,
 		}, nil
 	}
@@ -143,78 +474,201 @@ func (e *Engine) ensureBIGroup(groupName, description string, result *SyncResult
 		e.logger.Debugf("Group description (not stored in SCIM): %s", description)
 	}
 
-	createdGroup, err := e.biClient.CreateGroup(newGroup)
+	createdGroup, err := biClient.CreateGroup(ctx, newGroup)
 	if err != nil {
+		e.recordFailedChange(result, report.ChangeRecord{Action: state.ChangeGroupCreated, Group: groupName, Target: targetName}, err)
 		return nil, fmt.Errorf("failed to create group: %w", err)
 	}
 
 	result.GroupsCreated++
+	e.recordChange(result, report.ChangeRecord{Action: state.ChangeGroupCreated, ID: createdGroup.ID, Group: groupName, Target: targetName})
 	e.logger.Infof("Created group: %s (ID: %s)", groupName, createdGroup.ID)
 
 	return createdGroup, nil
 }
 
-// syncUsers ensures all users exist in Beyond Identity and returns their IDs
-func (e *Engine) syncUsers(gwsMembers []*gws.GroupMember, result *SyncResult) ([]string, error) {
-	var userIDs []string
+// syncUsers ensures all users exist in Beyond Identity and returns their emails keyed by user ID
+func (e *Engine) syncUsers(ctx context.Context, biClient BIClient, targetName string, gwsMembers []*gws.GroupMember, result *SyncResult) (map[string]string, error) {
+	users := make(map[string]string)
+
+	// With the bulk_api flag, missing users are collected and created together after the lookups
+	bulk := e.bulkCreator(biClient)
+	var missing []string
 
 	for _, member := range gwsMembers {
 		// Skip non-user members (groups, etc.)
 		if member.Type != "USER" {
 			e.logger.Debugf("Skipping non-user member: %s (type: %s)", member.Email, member.Type)
+			result.explain(member.Email, targetName, report.ExplainStageFilter, "skipped: member type %s is not a user", member.Type)
 			continue
 		}
 
 		// Skip suspended members
 		if member.Status == "SUSPENDED" {
 			e.logger.Debugf("Skipping suspended member: %s", member.Email)
+			result.explain(member.Email, targetName, report.ExplainStageFilter, "skipped: suspended in the source")
 			continue
 		}
 
-		userID, err := e.ensureBIUser(member.Email, result)
+		if e.skipUser(member.Email, result) {
+			continue
+		}
+		if filtered, err := e.filterUser(ctx, member.Email, targetName, result); filtered || err != nil {
+			if err != nil {
+				e.addError(result, "user", member.Email, err)
+			}
+			continue
+		}
+		result.explain(member.Email, targetName, report.ExplainStageFilter, "passed: active user not on the skip list")
+
+		// Checked after pacing, which a cancelled run stops waiting for
+		result.pacer.wait(ctx)
+		if e.runCancelled(ctx, result) {
+			return nil, ErrSyncAborted
+		}
+
+		var userID string
+		var err error
+		if bulk != nil {
+			var existingUser *bi.User
+			existingUser, err = e.reconcileBIUser(ctx, biClient, targetName, member.Email, result)
+			if err == nil && existingUser == nil {
+				e.explainLookup(targetName, member.Email, "", result)
+				missing = append(missing, member.Email)
+				continue
+			}
+			if err == nil {
+				e.explainLookup(targetName, member.Email, existingUser.ID, result)
+				var overwritten bool
+				userID, overwritten, err = e.resolveConflict(ctx, biClient, targetName, member.Email, existingUser, result)
+				if err == nil && userID != "" && !overwritten {
+					e.updateBIUser(ctx, biClient, targetName, member.Email, existingUser, result)
+				}
+			}
+		} else {
+			userID, err = e.ensureBIUser(ctx, biClient, targetName, member.Email, result)
+		}
 		if err != nil {
-			e.logger.Errorf("Failed to ensure user %s: %v", member.Email, err)
-			result.Errors = append(result.Errors, fmt.Errorf("user %s: %w", member.Email, err))
+			if e.userFailed(member.Email, err, result) {
+				return nil, ErrSyncAborted
+			}
 			continue
 		}
 
 		if userID != "" {
-			userIDs = append(userIDs, userID)
+			users[userID] = member.Email
 		}
 	}
 
-	return userIDs, nil
+	if len(missing) > 0 {
+		if err := e.createBIUsersBulk(ctx, bulk, biClient, targetName, missing, users, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+// userFailed records a user that could not be ensured and reports whether the run must abort
+func (e *Engine) userFailed(email string, err error, result *SyncResult) bool {
+	e.logger.Errorf("Failed to ensure user %s: %v", email, err)
+	e.addError(result, "user", email, err)
+	result.explain(email, "", report.ExplainStageAction, "failed: %v", err)
+	e.skipPermanentFailure(email, err)
+	return result.Aborted
+}
+
+// findBIUser returns the ID of an existing user, or "" when there is none
+func (e *Engine) findBIUser(ctx context.Context, biClient BIClient, email string) (string, error) {
+	existingUser, err := e.lookupBIUser(ctx, biClient, email)
+	if err != nil || existingUser == nil {
+		return "", err
+	}
+	return existingUser.ID, nil
+}
+
+// lookupBIUser returns an existing user, or nil when there is none
+func (e *Engine) lookupBIUser(ctx context.Context, biClient BIClient, email string) (*bi.User, error) {
+	existingUser, err := biClient.FindUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for user: %w", err)
+	}
+	if existingUser != nil {
+		e.logger.Debugf("Found existing user: %s (ID: %s)", email, existingUser.ID)
+	}
+	return existingUser, nil
 }
 
 // ensureBIUser creates or updates a user in Beyond Identity
-func (e *Engine) ensureBIUser(email string, result *SyncResult) (string, error) {
-	// Try to find existing user
-	existingUser, err := e.biClient.FindUserByEmail(email)
+func (e *Engine) ensureBIUser(ctx context.Context, biClient BIClient, targetName, email string, result *SyncResult) (string, error) {
+	// Try to find existing user, by Google user ID first and email second
+	existingUser, err := e.reconcileBIUser(ctx, biClient, targetName, email, result)
 	if err != nil {
-		return "", fmt.Errorf("failed to search for user: %w", err)
+		return "", err
 	}
 
 	if existingUser != nil {
-		e.logger.Debugf("Found existing user: %s (ID: %s)", email, existingUser.ID)
-		// Check if user needs updating (could add logic here to update displayName, etc.)
-		return existingUser.ID, nil
+		e.explainLookup(targetName, email, existingUser.ID, result)
+		userID, overwritten, err := e.resolveConflict(ctx, biClient, targetName, email, existingUser, result)
+		if err == nil && userID != "" && !overwritten {
+			e.updateBIUser(ctx, biClient, targetName, email, existingUser, result)
+		}
+		return userID, err
 	}
+	e.explainLookup(targetName, email, "", result)
 
 	// Create new user
-	if e.config.App.TestMode {
+	if e.simulated(config.OperationCreate) {
 		e.logger.Infof("TEST MODE: Would create user '%s'", email)
-		return "mock-user-id-for-testing", nil
+		result.explain(email, targetName, report.ExplainStageAction, "would create the user (test mode)")
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeUserCreated, User: email, Target: targetName, Planned: true})
+		return result.planUser(targetName, email), nil
 	}
 
 	e.logger.Infof("Creating new user: %s", email)
 
-	// Extract display name from email
-	displayName := extractDisplayName(email)
+	newUser, err := e.newBIUser(ctx, email, result)
+	if err != nil {
+		e.deferOp(state.DeferredOp{Kind: state.DeferredCreateUser, Target: targetName, User: email}, err, result)
+		return "", err
+	}
 
-	newUser := &bi.User{
-		ExternalID:  email,
+	var createdUser *bi.User
+	err = e.retry(ctx, func() error {
+		createdUser, err = biClient.CreateUser(ctx, newUser)
+		return err
+	})
+	if err != nil {
+		e.deferOp(state.DeferredOp{Kind: state.DeferredCreateUser, Target: targetName, User: email}, err, result)
+		e.recordFailedChange(result, report.ChangeRecord{Action: state.ChangeUserCreated, User: email, Target: targetName}, err)
+		return "", fmt.Errorf("failed to create user: %w", err)
+	}
+
+	e.userCreated(targetName, email, createdUser.ID, result)
+	return createdUser.ID, nil
+}
+
+// userCreated counts and records a user created in a target
+func (e *Engine) userCreated(targetName, email, userID string, result *SyncResult) {
+	result.UsersCreated++
+	result.userWritten(targetName, email, userID)
+	e.recordChange(result, report.ChangeRecord{Action: state.ChangeUserCreated, User: email, ID: userID, Target: targetName})
+	if result.explains(email) {
+		result.trace.setUserID(targetName, userID)
+		result.explain(email, targetName, report.ExplainStageAction, "created the user (ID: %s)", userID)
+	}
+	e.logger.Infof("Created user: %s (ID: %s)", email, userID)
+}
+
+// newBIUser builds the Beyond Identity user provisioned for a Google Workspace email, named from
+// the user's directory profile, with their Google user ID as externalId and the attributes mapped
+// in sync.attribute_mappings. Users the directory does not know, e.g. external group members, are
+// named from their email, which is also their externalId
+func (e *Engine) newBIUser(ctx context.Context, email string, result *SyncResult) (*bi.User, error) {
+	user := &bi.User{
+		ExternalID:  email, // Replaced by the Google user ID for users the directory knows
 		UserName:    email,
-		DisplayName: displayName,
+		DisplayName: extractDisplayName(email, e.config.Sync.DisplayNameLanguage()),
 		Emails: []bi.Email{
 			{
 				Value:   email,
@@ -225,125 +679,202 @@ func (e *Engine) ensureBIUser(email string, result *SyncResult) (string, error)
 		Active: true,
 	}
 
-	createdUser, err := e.biClient.CreateUser(newUser)
+	profile, err := e.userProfile(ctx, email, result)
 	if err != nil {
-		return "", fmt.Errorf("failed to create user: %w", err)
+		// Mapped attributes cannot be rendered without the profile, but a name from the email will do
+		if len(e.config.Sync.AttributeMappings) > 0 {
+			return nil, fmt.Errorf("failed to read attributes of user %s: %w", email, err)
+		}
+		e.logger.Warnf("Failed to read the profile of user %s, naming them from their email: %v", email, err)
+	}
+	if profile != nil && profile.ID != "" {
+		user.ExternalID = profile.ID
+	}
+	setProfileName(user, profile)
+	if len(e.config.Sync.AttributeMappings) == 0 {
+		e.explainNewUser(email, profile, user, result)
+		return user, nil
 	}
 
-	result.UsersCreated++
-	e.logger.Infof("Created user: %s (ID: %s)", email, createdUser.ID)
-
-	return createdUser.ID, nil
+	mapper, err := attributes.New(e.config.Sync.AttributeMappings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync.attribute_mappings: %w", err)
+	}
+	values, err := mapper.Render(userAttributes(email, profile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to map attributes of user %s: %w", email, err)
+	}
+	for attribute, value := range values {
+		setUserAttribute(user, attribute, value)
+	}
+	e.explainNewUser(email, profile, user, result)
+	return user, nil
 }
 
 // updateGroupMembership updates the membership of a Beyond Identity group
-func (e *Engine) updateGroupMembership(groupID string, desiredUserIDs []string, result *SyncResult) error {
-	if e.config.App.TestMode {
-		e.logger.Infof("TEST MODE: Would update group %s with %d members", groupID, len(desiredUserIDs))
-		return nil
-	}
-
-	// Get current group members from BI to calculate what needs to change
-	e.logger.Debugf("Getting current members for group %s", groupID)
-	currentGroup, err := e.biClient.GetGroupWithMembers(groupID)
-	if err != nil {
-		return fmt.Errorf("failed to get current group members: %w", err)
+func (e *Engine) updateGroupMembership(ctx context.Context, biClient BIClient, groupEmail, targetName, groupID, groupName string, desiredUsers map[string]string, result *SyncResult) error {
+	// Get current group members from BI to calculate what needs to change; a group a dry run
+	// would create has none
+	var currentMembers []bi.GroupMember
+	if groupID != plannedGroupID {
+		e.logger.Debugf("Getting current members for group %s", groupID)
+		currentGroup, err := biClient.GetGroupWithMembers(ctx, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to get current group members: %w", err)
+		}
+		currentMembers = sortedBIMembers(currentGroup.Members)
 	}
 
 	// Create sets for easier comparison
 	currentMemberIDs := make(map[string]bool)
-	for _, member := range currentGroup.Members {
+	for _, member := range currentMembers {
 		currentMemberIDs[member.Value] = true
 	}
 
-	desiredMemberIDs := make(map[string]bool)
-	for _, userID := range desiredUserIDs {
-		desiredMemberIDs[userID] = true
-	}
-
-	// Calculate members to add (in desired but not in current)
+	// Calculate members to add (in desired but not in current); adding to or with a user or
+	// group that was only planned can only be planned too
+	simulateAdd := e.simulated(config.OperationAdd) || isPlannedID(groupID)
 	var membersToAdd []bi.GroupMember
-	for userID := range desiredMemberIDs {
-		if !currentMemberIDs[userID] {
-			membersToAdd = append(membersToAdd, bi.GroupMember{
-				Value: userID,
-			})
+	var added, simulatedAdded []string
+	for _, userID := range sortedUserIDs(desiredUsers) {
+		if currentMemberIDs[userID] {
+			continue
 		}
+		if simulateAdd || isPlannedID(userID) {
+			simulatedAdded = append(simulatedAdded, desiredUsers[userID])
+			continue
+		}
+		membersToAdd = append(membersToAdd, bi.GroupMember{
+			Value: userID,
+		})
+		added = append(added, desiredUsers[userID])
 	}
 
-	// Calculate members to remove (in current but not in desired)
+	// Calculate members to remove (in current but not in desired); when only part of the source
+	// membership was read, users missing from it may still be members, so nobody is removed
+	partial := result.isPartial(groupEmail)
+	simulateRemove := e.simulated(config.OperationRemove)
 	var membersToRemove []bi.GroupMember
-	for _, member := range currentGroup.Members {
-		if !desiredMemberIDs[member.Value] {
-			membersToRemove = append(membersToRemove, bi.GroupMember{
-				Value: member.Value,
-			})
+	var removed, simulatedRemoved []string
+	for _, member := range currentMembers {
+		if _, ok := desiredUsers[member.Value]; ok || partial {
+			continue
 		}
+		if simulateRemove {
+			simulatedRemoved = append(simulatedRemoved, memberIdentity(member))
+			continue
+		}
+		membersToRemove = append(membersToRemove, bi.GroupMember{
+			Value: member.Value,
+		})
+		removed = append(removed, memberIdentity(member))
 	}
 
+	e.explainMembership(targetName, groupName, currentMembers, desiredUsers, simulatedAdded, partial, result)
+
 	// Only make API call if there are changes needed
-	if len(membersToAdd) == 0 && len(membersToRemove) == 0 {
-		e.logger.Infof("Group %s membership is already up to date (%d members)", groupID, len(currentGroup.Members))
+	if len(membersToAdd)+len(simulatedAdded) == 0 && len(membersToRemove)+len(simulatedRemoved) == 0 {
+		e.logger.Infof("Group %s membership is already up to date (%d members)", groupID, len(currentMembers))
 		return nil
 	}
 
-	e.logger.Infof("Updating group membership for group %s: +%d members, -%d members", 
-		groupID, len(membersToAdd), len(membersToRemove))
-
-	// Update group membership with proper add/remove operations
-	err = e.biClient.UpdateGroupMembers(groupID, membersToAdd, membersToRemove)
-	if err != nil {
-		return fmt.Errorf("failed to update group members: %w", err)
+	if len(simulatedAdded) > 0 || len(simulatedRemoved) > 0 {
+		e.logger.Infof("TEST MODE: Would update group %s: +%d members, -%d members", groupName, len(simulatedAdded), len(simulatedRemoved))
+		if e.dryRun() {
+			result.recordDiff(targetName, groupName, simulatedAdded, simulatedRemoved)
+		} else {
+			result.recordSimulatedDiff(targetName, groupName, simulatedAdded, simulatedRemoved)
+		}
+		e.recordPlannedChanges(result, groupEmail, targetName, simulatedAdded, simulatedRemoved)
 	}
-
-	result.MembershipsAdded += len(membersToAdd)
-	result.MembershipsRemoved += len(membersToRemove)
-	
-	e.logger.Infof("Successfully updated group membership: added %d, removed %d members", 
-		len(membersToAdd), len(membersToRemove))
-
-	return nil
-}
-
-// extractDisplayName extracts a display name from an email address
-func extractDisplayName(email string) string {
-	parts := strings.Split(email, "@")
-	if len(parts) == 0 {
-		return email
+	if len(membersToAdd) == 0 && len(membersToRemove) == 0 {
+		return nil
 	}
 
-	localPart := parts[0]
+	e.logger.Infof("Updating group membership for group %s: +%d members, -%d members",
+		groupID, len(membersToAdd), len(membersToRemove))
 
-	// Try to make it more readable
-	// Replace common separators with spaces
-	displayName := strings.ReplaceAll(localPart, ".", " ")
-	displayName = strings.ReplaceAll(displayName, "_", " ")
-	displayName = strings.ReplaceAll(displayName, "-", " ")
+	// Update group membership with proper add/remove operations, in batches so large updates stay
+	// within request size limits; batches that succeed are kept when others fail
+	batches := splitMembership(membersToAdd, membersToRemove, e.config.Sync.MembershipBatchSize)
+	var appliedAdded, appliedRemoved []string
+	var lastErr error
+	failed := 0
+	for i, batchErr := range e.updateMembersInBatches(ctx, biClient, groupID, batches) {
+		batch := batches[i]
+		batchAdded := added[batch.addStart : batch.addStart+len(batch.add)]
+		batchRemoved := removed[batch.removeStart : batch.removeStart+len(batch.remove)]
+		if batchErr != nil {
+			e.deferOp(state.DeferredOp{
+				Kind:    state.DeferredUpdateGroupMembers,
+				Target:  targetName,
+				Group:   groupEmail,
+				GroupID: groupID,
+				Add:     memberIDs(batch.add),
+				Remove:  memberIDs(batch.remove),
+			}, batchErr, result)
+			e.explainMembershipFailed(targetName, batchAdded, batchRemoved, batchErr, result)
+			for _, user := range batchAdded {
+				e.recordFailedChange(result, report.ChangeRecord{Action: state.ChangeMembershipAdded, User: user, Group: groupEmail, Target: targetName}, batchErr)
+			}
+			for _, user := range batchRemoved {
+				e.recordFailedChange(result, report.ChangeRecord{Action: state.ChangeMembershipRemoved, User: user, Group: groupEmail, Target: targetName}, batchErr)
+			}
+			lastErr = batchErr
+			failed++
+			continue
+		}
 
-	// Title case each word
-	words := strings.Fields(displayName)
-	for i, word := range words {
-		if len(word) > 0 {
-			words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+		result.MembershipsAdded += len(batch.add)
+		result.MembershipsRemoved += len(batch.remove)
+		for _, user := range batchAdded {
+			e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipAdded, User: user, Group: groupEmail, Target: targetName})
+		}
+		for _, user := range batchRemoved {
+			e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipRemoved, User: user, Group: groupEmail, Target: targetName})
 		}
+		appliedAdded = append(appliedAdded, batchAdded...)
+		appliedRemoved = append(appliedRemoved, batchRemoved...)
 	}
+	result.recordDiff(targetName, groupName, appliedAdded, appliedRemoved)
 
-	result := strings.Join(words, " ")
-	if result == "" {
-		return email // Fallback to email if we can't parse it
+	if failed > 0 {
+		if failed < len(batches) {
+			e.logger.Warnf("Partially updated group membership for group %s: %d of %d batches failed; added %d, removed %d members",
+				groupID, failed, len(batches), len(appliedAdded), len(appliedRemoved))
+			return fmt.Errorf("failed to update group members (%d of %d batches): %w", failed, len(batches), lastErr)
+		}
+		return fmt.Errorf("failed to update group members: %w", lastErr)
 	}
 
-	return result
+	e.logger.Infof("Successfully updated group membership: added %d, removed %d members",
+		len(membersToAdd), len(membersToRemove))
+
+	return nil
 }
 
-// RetryWithBackoff executes a function with exponential backoff retry logic
-func (e *Engine) RetryWithBackoff(operation func() error, maxAttempts int, baseDelay time.Duration) error {
+// RetryWithBackoff executes a function with exponential backoff retry logic; errors that
+// retrying cannot fix are returned immediately, as is the last error once ctx is done, so
+// cancelled and timed out runs do not wait out the backoff
+func (e *Engine) RetryWithBackoff(ctx context.Context, operation func() error, maxAttempts int, baseDelay time.Duration) error {
+	return e.retryWhile(ctx, operation, maxAttempts, baseDelay, isRetryable)
+}
+
+// retryWhile is RetryWithBackoff, retrying only the errors retryable accepts
+func (e *Engine) retryWhile(ctx context.Context, operation func() error, maxAttempts int, baseDelay time.Duration, retryable func(error) bool) error {
 	var lastErr error
 
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		if err := operation(); err != nil {
 			lastErr = err
 
+			if !retryable(err) || ctx.Err() != nil {
+				return err
+			}
 			if attempt == maxAttempts {
 				break
 			}
@@ -351,22 +882,63 @@ func (e *Engine) RetryWithBackoff(operation func() error, maxAttempts int, baseD
 			delay := time.Duration(attempt) * baseDelay
 			e.logger.Warnf("Operation failed (attempt %d/%d), retrying in %v: %v",
 				attempt, maxAttempts, delay, err)
-			time.Sleep(delay)
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(delay):
+			}
 			continue
 		}
 
 		return nil // Success
 	}
 
+	if maxAttempts == 1 {
+		return lastErr
+	}
 	return fmt.Errorf("operation failed after %d attempts: %w", maxAttempts, lastErr)
 }
 
+// retry runs a Beyond Identity write with the configured retry policy. While sync.retry is
+// enabled, the HTTP client has already retried the responses worth retrying, so only failures
+// without an HTTP status, such as network errors, are retried again here
+func (e *Engine) retry(ctx context.Context, operation func() error) error {
+	retryable := isRetryable
+	if e.config.Sync.Retry.MaxAttempts > 1 {
+		retryable = func(err error) bool {
+			return errorStatusCode(err) == 0 && isRetryable(err)
+		}
+	}
+	return e.retryWhile(ctx, operation, e.config.Sync.RetryAttempts, time.Duration(e.config.Sync.RetryDelaySeconds)*time.Second, retryable)
+}
+
+// sleepContext waits for d, returning early once ctx is done so pacing never holds up a cancel,
+// pause, emergency stop or run timeout
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// skipNativeAPISteps stops enrollment steps for a target for the rest of the run so core
+// provisioning carries on while the Native API is down
+func (e *Engine) skipNativeAPISteps(result *SyncResult, targetName string, err error) {
+	if !result.runFlags().setOnce(flagNativeAPIDown + targetName) {
+		return
+	}
+	result.SkippedSteps = append(result.SkippedSteps, fmt.Sprintf("enrollment status sync (target %s): %s", targetName, SkippedNativeAPIUnavailable))
+	e.logger.Warnf("Native API for target %s is unavailable, skipping enrollment status sync for the rest of this run: %v", targetName, err)
+}
+
 // syncEnrollmentStatus manages the BYID_Enrolled Google group based on Beyond Identity user enrollment status (active + has active passkey)
-func (e *Engine) syncEnrollmentStatus(gwsMembers []*gws.GroupMember, result *SyncResult) error {
+func (e *Engine) syncEnrollmentStatus(ctx context.Context, biClient BIClient, targetName string, gwsMembers []*gws.GroupMember, result *SyncResult) error {
 	e.logger.Infof("Managing enrollment group: %s (%s)", e.config.Sync.EnrollmentGroupName, e.config.Sync.EnrollmentGroupEmail)
 
 	// Ensure the enrollment group exists
-	enrollmentGroup, err := e.gwsClient.EnsureGroup(
+	enrollmentGroup, err := e.gwsClient.EnsureGroup(ctx,
 		e.config.Sync.EnrollmentGroupEmail,
 		e.config.Sync.EnrollmentGroupName,
 		"Users who have successfully enrolled with Beyond Identity",
@@ -378,7 +950,7 @@ func (e *Engine) syncEnrollmentStatus(gwsMembers []*gws.GroupMember, result *Syn
 	e.logger.Debugf("Managing enrollment group: %s", enrollmentGroup.Email)
 
 	// Get current members of the enrollment group
-	currentMembers, err := e.gwsClient.GetGroupMembers(enrollmentGroup.Email)
+	currentMembers, err := e.gwsClient.GetGroupMembers(ctx, enrollmentGroup.Email)
 	if err != nil {
 		return fmt.Errorf("failed to get enrollment group members: %w", err)
 	}
@@ -402,38 +974,57 @@ func (e *Engine) syncEnrollmentStatus(gwsMembers []*gws.GroupMember, result *Syn
 		}
 
 		// Check Beyond Identity enrollment status (active AND has active passkey)
-		isEnrolled, err := e.biClient.GetUserStatus(member.Email)
+		isEnrolled, err := biClient.GetUserStatus(ctx, member.Email)
+		if errors.Is(err, bi.ErrNativeAPIUnavailable) {
+			e.skipNativeAPISteps(result, targetName, err)
+			return nil
+		}
 		if err != nil {
 			e.logger.Warnf("Failed to get BI enrollment status for %s: %v", member.Email, err)
+			result.explain(member.Email, targetName, report.ExplainStageEnrollment, "unchanged: enrollment status could not be read: %v", err)
 			continue
 		}
 
 		isCurrentlyInGroup := currentMemberMap[member.Email]
+		e.explainEnrollment(targetName, member.Email, isEnrolled, isCurrentlyInGroup, result)
+		e.stampEnrollmentSchema(ctx, member.Email, isEnrolled, result)
 
 		if isEnrolled && !isCurrentlyInGroup {
 			// User is enrolled in BI (active + has passkey) but not in enrollment group - add them
-			if e.config.App.TestMode {
+			if e.simulated(config.OperationAdd) {
 				e.logger.Infof("TEST MODE: Would add %s to enrollment group (active with passkey)", member.Email)
+				if !e.dryRun() {
+					result.recordSimulatedDiff(state.TargetGoogleWorkspace, e.config.Sync.EnrollmentGroupName, []string{member.Email}, nil)
+					continue
+				}
 			} else {
 				e.logger.Infof("Adding %s to enrollment group (active with passkey)", member.Email)
-				if err := e.gwsClient.AddMemberToGroup(enrollmentGroup.Email, member.Email); err != nil {
+				if err := e.gwsClient.AddMemberToGroup(ctx, enrollmentGroup.Email, member.Email); err != nil {
 					e.logger.Errorf("Failed to add %s to enrollment group: %v", member.Email, err)
 					continue
 				}
 			}
 			result.MembershipsAdded++
+			result.recordDiff(state.TargetGoogleWorkspace, e.config.Sync.EnrollmentGroupName, []string{member.Email}, nil)
+			e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipAdded, User: member.Email, Group: enrollmentGroup.Email, Target: state.TargetGoogleWorkspace, Planned: e.simulated(config.OperationAdd)})
 		} else if !isEnrolled && isCurrentlyInGroup {
 			// User is not enrolled in BI (inactive or no passkey) but still in enrollment group - remove them
-			if e.config.App.TestMode {
+			if e.simulated(config.OperationRemove) {
 				e.logger.Infof("TEST MODE: Would remove %s from enrollment group (not enrolled or no passkey)", member.Email)
+				if !e.dryRun() {
+					result.recordSimulatedDiff(state.TargetGoogleWorkspace, e.config.Sync.EnrollmentGroupName, nil, []string{member.Email})
+					continue
+				}
 			} else {
 				e.logger.Infof("Removing %s from enrollment group (not enrolled or no passkey)", member.Email)
-				if err := e.gwsClient.RemoveMemberFromGroup(enrollmentGroup.Email, member.Email); err != nil {
+				if err := e.gwsClient.RemoveMemberFromGroup(ctx, enrollmentGroup.Email, member.Email); err != nil {
 					e.logger.Errorf("Failed to remove %s from enrollment group: %v", member.Email, err)
 					continue
 				}
 			}
 			result.MembershipsRemoved++
+			result.recordDiff(state.TargetGoogleWorkspace, e.config.Sync.EnrollmentGroupName, nil, []string{member.Email})
+			e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipRemoved, User: member.Email, Group: enrollmentGroup.Email, Target: state.TargetGoogleWorkspace, Planned: e.simulated(config.OperationRemove)})
 		}
 	}
 
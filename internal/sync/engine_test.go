@@ -1,35 +1,51 @@
 package sync
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
+	"google.golang.org/api/googleapi"
 )
 
 // Mock clients for testing
 type mockGWSClient struct {
 	groups      map[string]*gws.Group
 	members     map[string][]*gws.GroupMember
+	deleted     map[string]bool
 	shouldError bool
 }
 
-func (m *mockGWSClient) GetGroup(email string) (*gws.Group, error) {
+func (m *mockGWSClient) GetGroup(ctx context.Context, email string) (*gws.Group, error) {
 	if m.shouldError {
 		return nil, errors.New("mock GWS error")
 	}
+	if m.deleted[email] {
+		return nil, &googleapi.Error{Code: 404, Message: "Resource Not Found: groupKey"}
+	}
 	if group, exists := m.groups[email]; exists {
 		return group, nil
 	}
 	return nil, fmt.Errorf("group not found: %s", email)
 }
 
-func (m *mockGWSClient) GetGroupMembers(email string) ([]*gws.GroupMember, error) {
+func (m *mockGWSClient) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
 	if m.shouldError {
 		return nil, errors.New("mock GWS members error")
 	}
@@ -39,7 +55,7 @@ func (m *mockGWSClient) GetGroupMembers(email string) ([]*gws.GroupMember, error
 	return []*gws.GroupMember{}, nil
 }
 
-func (m *mockGWSClient) AddMemberToGroup(groupEmail, memberEmail string) error {
+func (m *mockGWSClient) AddMemberToGroup(ctx context.Context, groupEmail, memberEmail string) error {
 	if m.shouldError {
 		return errors.New("mock GWS add member error")
 	}
@@ -54,7 +70,7 @@ func (m *mockGWSClient) AddMemberToGroup(groupEmail, memberEmail string) error {
 	return nil
 }
 
-func (m *mockGWSClient) RemoveMemberFromGroup(groupEmail, memberEmail string) error {
+func (m *mockGWSClient) RemoveMemberFromGroup(ctx context.Context, groupEmail, memberEmail string) error {
 	if m.shouldError {
 		return errors.New("mock GWS remove member error")
 	}
@@ -69,7 +85,7 @@ func (m *mockGWSClient) RemoveMemberFromGroup(groupEmail, memberEmail string) er
 	return nil
 }
 
-func (m *mockGWSClient) CreateGroup(name, email, description string) (*gws.Group, error) {
+func (m *mockGWSClient) CreateGroup(ctx context.Context, name, email, description string) (*gws.Group, error) {
 	if m.shouldError {
 		return nil, errors.New("mock GWS create group error")
 	}
@@ -82,23 +98,27 @@ func (m *mockGWSClient) CreateGroup(name, email, description string) (*gws.Group
 	return group, nil
 }
 
-func (m *mockGWSClient) EnsureGroup(name, email, description string) (*gws.Group, error) {
+func (m *mockGWSClient) EnsureGroup(ctx context.Context, email, name, description string) (*gws.Group, error) {
 	if m.shouldError {
 		return nil, errors.New("mock GWS ensure group error")
 	}
 	if group, exists := m.groups[email]; exists {
 		return group, nil
 	}
-	return m.CreateGroup(name, email, description)
+	return m.CreateGroup(ctx, name, email, description)
 }
 
 type mockBIClient struct {
-	groups      map[string]*bi.Group
-	users       map[string]*bi.User
-	shouldError bool
+	groups        map[string]*bi.Group
+	users         map[string]*bi.User
+	shouldError   bool
+	createUserErr error // Returned by CreateUser when set
+	userStatusErr error // Returned by GetUserStatus when set
+	statusCalls   int
+	updates       int // UpdateUser calls
 }
 
-func (m *mockBIClient) FindGroupByDisplayName(name string) (*bi.Group, error) {
+func (m *mockBIClient) FindGroupByDisplayName(ctx context.Context, name string) (*bi.Group, error) {
 	if m.shouldError {
 		return nil, errors.New("mock BI group search error")
 	}
@@ -110,7 +130,7 @@ func (m *mockBIClient) FindGroupByDisplayName(name string) (*bi.Group, error) {
 	return nil, nil
 }
 
-func (m *mockBIClient) CreateGroup(group *bi.Group) (*bi.Group, error) {
+func (m *mockBIClient) CreateGroup(ctx context.Context, group *bi.Group) (*bi.Group, error) {
 	if m.shouldError {
 		return nil, errors.New("mock BI group creation error")
 	}
@@ -122,7 +142,24 @@ func (m *mockBIClient) CreateGroup(group *bi.Group) (*bi.Group, error) {
 	return newGroup, nil
 }
 
-func (m *mockBIClient) FindUserByEmail(email string) (*bi.User, error) {
+func (m *mockBIClient) RenameGroup(ctx context.Context, groupID, displayName string) error {
+	if m.shouldError {
+		return errors.New("mock BI group rename error")
+	}
+	if group, exists := m.groups[groupID]; exists {
+		group.DisplayName = displayName
+	}
+	return nil
+}
+
+func (m *mockBIClient) GetUser(ctx context.Context, userID string) (*bi.User, error) {
+	if user, exists := m.users[userID]; exists {
+		return user, nil
+	}
+	return nil, fmt.Errorf("user not found: %s", userID)
+}
+
+func (m *mockBIClient) FindUserByEmail(ctx context.Context, email string) (*bi.User, error) {
 	if m.shouldError {
 		return nil, errors.New("mock BI user search error")
 	}
@@ -134,25 +171,43 @@ func (m *mockBIClient) FindUserByEmail(email string) (*bi.User, error) {
 	return nil, nil
 }
 
-func (m *mockBIClient) CreateUser(user *bi.User) (*bi.User, error) {
+func (m *mockBIClient) CreateUser(ctx context.Context, user *bi.User) (*bi.User, error) {
 	if m.shouldError {
 		return nil, errors.New("mock BI user creation error")
 	}
+	if m.createUserErr != nil {
+		return nil, m.createUserErr
+	}
 	newUser := &bi.User{
 		ID:          fmt.Sprintf("user-%d", len(m.users)+1),
+		ExternalID:  user.ExternalID,
 		UserName:    user.UserName,
 		DisplayName: user.DisplayName,
 		Emails:      user.Emails,
 		Active:      user.Active,
+		Name:        user.Name,
+		Title:       user.Title,
+		Enterprise:  user.Enterprise,
 	}
 	m.users[newUser.ID] = newUser
 	return newUser, nil
 }
 
-func (m *mockBIClient) UpdateGroupMembers(groupID string, membersToAdd []bi.GroupMember, membersToRemove []bi.GroupMember) error {
+func (m *mockBIClient) UpdateGroupMembers(ctx context.Context, groupID string, membersToAdd []bi.GroupMember, membersToRemove []bi.GroupMember) error {
 	if m.shouldError {
 		return errors.New("mock BI group update error")
 	}
+	if group, exists := m.groups[groupID]; exists {
+		for _, remove := range membersToRemove {
+			for i, member := range group.Members {
+				if member.Value == remove.Value {
+					group.Members = append(group.Members[:i], group.Members[i+1:]...)
+					break
+				}
+			}
+		}
+		group.Members = append(group.Members, membersToAdd...)
+	}
 	return nil
 }
 
@@ -164,7 +219,11 @@ func (m *mockBIClient) GetUserPasskeyStatus(userEmail string) (bool, error) {
 	return true, nil
 }
 
-func (m *mockBIClient) GetUserStatus(userEmail string) (bool, error) {
+func (m *mockBIClient) GetUserStatus(ctx context.Context, userEmail string) (bool, error) {
+	m.statusCalls++
+	if m.userStatusErr != nil {
+		return false, m.userStatusErr
+	}
 	if m.shouldError {
 		return false, errors.New("mock BI user status error")
 	}
@@ -172,23 +231,23 @@ func (m *mockBIClient) GetUserStatus(userEmail string) (bool, error) {
 	return true, nil
 }
 
-func (m *mockBIClient) GetGroupWithMembers(groupID string) (*bi.Group, error) {
+func (m *mockBIClient) GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error) {
 	if m.shouldError {
 		return nil, errors.New("mock BI get group error")
 	}
-	
+
 	// Find the group by ID
 	for _, group := range m.groups {
 		if group.ID == groupID {
-			// Return a copy with empty members for simplicity in tests
+			// Return a copy so callers cannot modify the stored members
 			return &bi.Group{
 				ID:          group.ID,
 				DisplayName: group.DisplayName,
-				Members:     []bi.GroupMember{}, // Start with empty for test simplicity
+				Members:     append([]bi.GroupMember{}, group.Members...),
 			}, nil
 		}
 	}
-	
+
 	return nil, fmt.Errorf("group not found: %s", groupID)
 }
 
@@ -436,7 +495,7 @@ func TestSync(t *testing.T) {
 			logger.SetLevel(logrus.FatalLevel) // Reduce log noise during tests
 
 			engine := NewEngine(gwsClient, biClient, tt.config, logger)
-			result, err := engine.Sync()
+			result, err := engine.Sync(context.Background())
 
 			if tt.expectError {
 				if err == nil {
@@ -464,6 +523,411 @@ func TestSync(t *testing.T) {
 	}
 }
 
+func TestSync_MultipleTargets(t *testing.T) {
+	gwsClient := &mockGWSClient{
+		groups: map[string]*gws.Group{
+			"eng@example.com":   {Name: "Engineering"},
+			"sales@example.com": {Name: "Sales"},
+		},
+		members: map[string][]*gws.GroupMember{
+			"eng@example.com":   {{Email: "eng@example.com", Type: "USER", Status: "ACTIVE"}},
+			"sales@example.com": {{Email: "sales@example.com", Type: "USER", Status: "ACTIVE"}},
+		},
+	}
+	defaultClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+	euClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+
+	cfg := &config.Config{
+		BeyondIdentity: config.BeyondIdentityConfig{GroupPrefix: "GWS_"},
+		Targets:        []config.TargetConfig{{Name: "eu", GroupPrefix: "EU_"}},
+		Sync: config.SyncConfig{
+			Groups:       []string{"eng@example.com", "sales@example.com"},
+			GroupTargets: map[string]string{"eng@example.com": "eu"},
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	engine := NewEngine(gwsClient, defaultClient, cfg, logger)
+	engine.AddTarget("eu", euClient)
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsProcessed != 2 {
+		t.Errorf("Expected 2 groups processed, got %d", result.GroupsProcessed)
+	}
+
+	if group, _ := euClient.FindGroupByDisplayName(context.Background(), "EU_Engineering"); group == nil {
+		t.Error("Expected EU_Engineering to be created in the eu target")
+	}
+	if group, _ := defaultClient.FindGroupByDisplayName(context.Background(), "GWS_Sales"); group == nil {
+		t.Error("Expected GWS_Sales to be created in the default target")
+	}
+	if group, _ := defaultClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering"); group != nil {
+		t.Error("Expected Engineering not to be created in the default target")
+	}
+}
+
+func TestSync_UnregisteredTarget(t *testing.T) {
+	gwsClient := &mockGWSClient{
+		groups:  map[string]*gws.Group{"eng@example.com": {Name: "Engineering"}},
+		members: map[string][]*gws.GroupMember{},
+	}
+	biClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+
+	cfg := &config.Config{
+		Sync: config.SyncConfig{
+			Groups:       []string{"eng@example.com"},
+			GroupTargets: map[string]string{"eng@example.com": "missing"},
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	result, err := NewEngine(gwsClient, biClient, cfg, logger).Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected 1 error for unregistered target, got %d", len(result.Errors))
+	}
+}
+
+func TestSync_OrphanedGroup(t *testing.T) {
+	gwsClient := &mockGWSClient{
+		groups:  map[string]*gws.Group{"eng@example.com": {Name: "Engineering"}},
+		members: map[string][]*gws.GroupMember{},
+		deleted: map[string]bool{},
+	}
+	biClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+
+	cfg := &config.Config{
+		BeyondIdentity: config.BeyondIdentityConfig{GroupPrefix: "GWS_"},
+		Sync: config.SyncConfig{
+			Groups:         []string{"eng@example.com"},
+			OrphanedGroups: config.OrphanedGroupsConfig{Archive: true, ArchiveSuffix: " (archived)"},
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	engine := NewEngine(gwsClient, biClient, cfg, logger)
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	gwsClient.deleted["eng@example.com"] = true
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected no errors for a deleted group, got %v", result.Errors)
+	}
+	if len(result.OrphanedGroups) != 1 || result.OrphanedGroups[0] != "eng@example.com" {
+		t.Errorf("Expected eng@example.com to be orphaned, got %v", result.OrphanedGroups)
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering (archived)"); group == nil {
+		t.Error("Expected the BI group to be renamed with the archive suffix")
+	}
+
+	// Orphaned groups are not retried while the configuration is unchanged
+	result, err = engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsSkipped != 1 || len(result.OrphanedGroups) != 0 {
+		t.Errorf("Expected the orphaned group to be skipped, got skipped=%d orphaned=%v", result.GroupsSkipped, result.OrphanedGroups)
+	}
+
+	// Editing the configuration retries the group
+	gwsClient.deleted["eng@example.com"] = false
+	cfg.Sync.Groups = append(cfg.Sync.Groups, "sales@example.com")
+	gwsClient.groups["sales@example.com"] = &gws.Group{Name: "Sales"}
+
+	result, err = engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsSkipped != 0 || result.GroupsProcessed != 2 {
+		t.Errorf("Expected both groups to sync after a config change, got skipped=%d processed=%d", result.GroupsSkipped, result.GroupsProcessed)
+	}
+}
+
+func TestSync_RecordsChanges(t *testing.T) {
+	gwsClient := &mockGWSClient{
+		groups: map[string]*gws.Group{"eng@example.com": {Name: "Engineering"}},
+		members: map[string][]*gws.GroupMember{
+			"eng@example.com": {{Email: "alice@example.com", Type: "USER", Status: "ACTIVE"}},
+		},
+	}
+	biClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+
+	cfg := &config.Config{
+		BeyondIdentity: config.BeyondIdentityConfig{GroupPrefix: "GWS_"},
+		Sync: config.SyncConfig{
+			Groups:               []string{"eng@example.com"},
+			EnrollmentGroupEmail: "enrolled@example.com",
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	engine := NewEngine(gwsClient, biClient, cfg, logger)
+	since := time.Now()
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	changes := engine.Changes(since, time.Time{})
+	expected := []state.Change{
+		{Action: state.ChangeUserCreated, User: "alice@example.com", Target: config.DefaultTargetName},
+		{Action: state.ChangeMembershipAdded, User: "alice@example.com", Group: "eng@example.com", Target: config.DefaultTargetName},
+		{Action: state.ChangeMembershipAdded, User: "alice@example.com", Group: "enrolled@example.com", Target: state.TargetGoogleWorkspace},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), changes)
+	}
+	for i, want := range expected {
+		got := changes[i]
+		got.Time = time.Time{}
+		if got != want {
+			t.Errorf("Expected change %+v, got %+v", want, got)
+		}
+	}
+
+	if changes := engine.Changes(time.Now(), time.Time{}); len(changes) != 0 {
+		t.Errorf("Expected no changes after the run, got %+v", changes)
+	}
+}
+
+func TestSync_ChangeRecords(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+
+	result, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Sales")
+	carol, _ := biClient.FindUserByEmail(context.Background(), "carol@example.com")
+	expected := []report.ChangeRecord{
+		{Action: state.ChangeGroupCreated, Entity: report.EntityGroup, ID: group.ID, Group: "GWS_Sales", Target: config.DefaultTargetName},
+		{Action: state.ChangeUserCreated, Entity: report.EntityUser, User: "carol@example.com", ID: carol.ID, Target: config.DefaultTargetName},
+		{Action: state.ChangeMembershipAdded, Entity: report.EntityMembership, User: "carol@example.com", Group: "sales@example.com", Target: config.DefaultTargetName},
+		{Action: state.ChangeMembershipAdded, Entity: report.EntityMembership, User: "carol@example.com", Target: state.TargetGoogleWorkspace}, // The enrollment group
+	}
+	assertChangeRecords(t, result.Changes, expected)
+
+	// Changes a test mode run would make are recorded as planned and kept out of the history
+	engine.config.App.TestMode = true
+	since := time.Now()
+	result, err = engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = []report.ChangeRecord{
+		{Action: state.ChangeGroupCreated, Entity: report.EntityGroup, Group: "GWS_Engineering", Target: config.DefaultTargetName, Planned: true},
+		{Action: state.ChangeUserCreated, Entity: report.EntityUser, User: "alice@example.com", Target: config.DefaultTargetName, Planned: true},
+		{Action: state.ChangeUserCreated, Entity: report.EntityUser, User: "bob@example.com", Target: config.DefaultTargetName, Planned: true},
+		{Action: state.ChangeMembershipAdded, Entity: report.EntityMembership, User: "alice@example.com", Group: "eng@example.com", Target: config.DefaultTargetName, Planned: true},
+		{Action: state.ChangeMembershipAdded, Entity: report.EntityMembership, User: "bob@example.com", Group: "eng@example.com", Target: config.DefaultTargetName, Planned: true},
+		{Action: state.ChangeMembershipAdded, Entity: report.EntityMembership, User: "alice@example.com", Target: state.TargetGoogleWorkspace, Planned: true},
+		{Action: state.ChangeMembershipAdded, Entity: report.EntityMembership, User: "bob@example.com", Target: state.TargetGoogleWorkspace, Planned: true},
+	}
+	assertChangeRecords(t, result.Changes, expected)
+	if changes := engine.Changes(since, time.Time{}); len(changes) != 0 {
+		t.Errorf("Expected planned changes to stay out of the history, got %+v", changes)
+	}
+}
+
+func assertChangeRecords(t *testing.T, got, expected []report.ChangeRecord) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d change records, got %+v", len(expected), got)
+	}
+	for i, want := range expected {
+		record := got[i]
+		if record.Time.IsZero() {
+			t.Errorf("Expected change record %d to be timestamped", i)
+		}
+		record.Time = time.Time{}
+		if record != want {
+			t.Errorf("Expected change record %+v, got %+v", want, record)
+		}
+	}
+}
+
+func TestSync_MembershipDiffs(t *testing.T) {
+	engine, gwsClient, _ := newTargetedTestEngine()
+	engine.config.Sync.EnrollmentGroupName = "BYID Enrolled"
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []GroupDiff{
+		{Group: "GWS_Engineering", Added: []string{"alice@example.com", "bob@example.com"}},
+		{Group: "BYID Enrolled", Added: []string{"alice@example.com", "bob@example.com", "carol@example.com"}},
+		{Group: "GWS_Sales", Added: []string{"carol@example.com"}},
+	}
+	if len(result.MembershipDiffs) != len(expected) {
+		t.Fatalf("Expected %d group diffs, got %+v", len(expected), result.MembershipDiffs)
+	}
+	for i, want := range expected {
+		got := result.MembershipDiffs[i]
+		sort.Strings(got.Added)
+		if got.Group != want.Group || strings.Join(got.Added, ",") != strings.Join(want.Added, ",") || len(got.Removed) != 0 {
+			t.Errorf("Expected diff %+v, got %+v", want, got)
+		}
+	}
+
+	gwsClient.members["eng@example.com"] = gwsClient.members["eng@example.com"][:1]
+	result, err = engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.MembershipDiffs) != 1 || result.MembershipDiffs[0].Group != "GWS_Engineering" ||
+		len(result.MembershipDiffs[0].Removed) != 1 || len(result.MembershipDiffs[0].Added) != 0 {
+		t.Errorf("Expected only bob's removal from GWS_Engineering, got %+v", result.MembershipDiffs)
+	}
+}
+
+func TestSync_NativeAPIUnavailable(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.config.Sync.EnrollmentGroupEmail = "enrolled@example.com"
+	biClient.userStatusErr = fmt.Errorf("%w: %w", bi.ErrNativeAPIUnavailable, &bi.HTTPError{StatusCode: 503, Body: "unavailable"})
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected the outage not to be reported as errors, got %v", result.Errors)
+	}
+	if result.GroupsProcessed != 2 || result.UsersCreated != 3 {
+		t.Errorf("Expected core provisioning to continue, got %+v", result)
+	}
+	if len(result.SkippedSteps) != 1 || !strings.Contains(result.SkippedSteps[0], SkippedNativeAPIUnavailable) {
+		t.Errorf("Expected enrollment status sync to be flagged as skipped once, got %v", result.SkippedSteps)
+	}
+	if biClient.statusCalls != 1 {
+		t.Errorf("Expected enrollment checks to stop after the first failure, got %d calls", biClient.statusCalls)
+	}
+	if members := gwsClient.members["enrolled@example.com"]; len(members) != 0 {
+		t.Errorf("Expected the enrollment group to be left alone, got %+v", members)
+	}
+}
+
+func TestAccessReview(t *testing.T) {
+	gwsClient := &mockGWSClient{
+		groups: map[string]*gws.Group{
+			"eng@example.com":  {Name: "Engineering"},
+			"gone@example.com": {Name: "Gone"},
+		},
+		members: map[string][]*gws.GroupMember{
+			"eng@example.com": {
+				{Email: "bob@example.com", Type: "USER", Status: "ACTIVE"},
+				{Email: "alice@example.com", Type: "USER", Status: "ACTIVE"},
+			},
+		},
+		deleted: map[string]bool{},
+	}
+	biClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+
+	cfg := &config.Config{
+		BeyondIdentity: config.BeyondIdentityConfig{GroupPrefix: "GWS_"},
+		Sync:           config.SyncConfig{Groups: []string{"eng@example.com", "gone@example.com"}},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	engine := NewEngine(gwsClient, biClient, cfg, logger)
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	gwsClient.deleted["gone@example.com"] = true
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	review, err := engine.AccessReview(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(review) != 1 {
+		t.Fatalf("Expected only the active group in the review, got %+v", review)
+	}
+
+	group := review[0]
+	if group.SourceGroup != "eng@example.com" || group.GroupName != "GWS_Engineering" || group.LastVerified.IsZero() {
+		t.Errorf("Unexpected group provenance: %+v", group)
+	}
+	if len(group.Members) != 2 || group.Members[0].Email != "alice@example.com" || group.Members[1].Email != "bob@example.com" {
+		t.Errorf("Expected alice and bob sorted by email, got %+v", group.Members)
+	}
+}
+
+func TestSync_CSVSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "members.csv")
+	export := "Department,Work Email\neng@example.com,alice@example.com\n"
+	if err := os.WriteFile(path, []byte(export), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+
+	gwsClient := &mockGWSClient{groups: map[string]*gws.Group{}, members: map[string][]*gws.GroupMember{}}
+	biClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+
+	cfg := &config.Config{
+		BeyondIdentity: config.BeyondIdentityConfig{GroupPrefix: "HR_"},
+		Sync:           config.SyncConfig{Groups: []string{"eng@example.com"}},
+		Source: config.SourceConfig{
+			Type: config.SourceTypeCSV,
+			CSV: config.CSVSourceConfig{
+				Path:    path,
+				Columns: config.CSVColumns{Group: "Department", Email: "Work Email"},
+			},
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	engine := NewEngine(gwsClient, biClient, cfg, logger)
+	if err := engine.ConfigureSource(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsProcessed != 1 {
+		t.Errorf("Expected 1 group processed, got %d", result.GroupsProcessed)
+	}
+	if result.UsersCreated != 1 {
+		t.Errorf("Expected 1 user created from the export, got %d", result.UsersCreated)
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "HR_eng@example.com"); group == nil {
+		t.Error("Expected group named after the export's group email to be created")
+	}
+
+	// A later export that fails validation aborts the run instead of removing members
+	if err := os.WriteFile(path, []byte("Department,Work Email\neng@example.com,not-an-email\n"), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+	if _, err := engine.Sync(context.Background()); err == nil {
+		t.Error("Expected sync to fail for an invalid export")
+	}
+}
+
 func TestExtractDisplayName(t *testing.T) {
 	tests := []struct {
 		email    string
@@ -501,11 +965,43 @@ func TestExtractDisplayName(t *testing.T) {
 			email:    "noemail",
 			expected: "Noemail",
 		},
+		{
+			email:    "élodie.dupont@example.com",
+			expected: "Élodie Dupont",
+		},
+		{
+			email:    "ÅSA.LINDQVIST@example.com",
+			expected: "Åsa Lindqvist",
+		},
+		{
+			email:    "jürgen_weiß@example.com",
+			expected: "Jürgen Weiß",
+		},
+		{
+			email:    "ΟΔΥΣΣΕΑΣ@example.com",
+			expected: "Οδυσσεας",
+		},
+		{
+			email:    "alice+github@example.com",
+			expected: "Alice",
+		},
+		{
+			email:    "john.smith2@example.com",
+			expected: "John Smith",
+		},
+		{
+			email:    "john.smith.02+test@example.com",
+			expected: "John Smith",
+		},
+		{
+			email:    "12345@example.com",
+			expected: "12345",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.email, func(t *testing.T) {
-			result := extractDisplayName(tt.email)
+			result := extractDisplayName(tt.email, language.Und)
 			if result != tt.expected {
 				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
 			}
@@ -513,6 +1009,28 @@ func TestExtractDisplayName(t *testing.T) {
 	}
 }
 
+func TestExtractDisplayName_Locale(t *testing.T) {
+	tests := []struct {
+		email    string
+		locale   string
+		expected string
+	}{
+		{"ismail.yilmaz@example.com", "", "Ismail Yilmaz"},
+		{"ismail.yilmaz@example.com", "tr", "İsmail Yilmaz"},
+		{"ijsbrand.de.vries@example.com", "", "Ijsbrand De Vries"},
+		{"ijsbrand.de.vries@example.com", "nl", "IJsbrand De Vries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.email, func(t *testing.T) {
+			sync := config.SyncConfig{DisplayNameLocale: tt.locale}
+			if result := extractDisplayName(tt.email, sync.DisplayNameLanguage()); result != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestRetryWithBackoff(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -555,6 +1073,51 @@ func TestRetryWithBackoff(t *testing.T) {
 			expectError: true,
 			expectCalls: 2,
 		},
+		{
+			name: "permanent error is not retried",
+			operation: func() error {
+				return fmt.Errorf("failed to create user: %w", &bi.HTTPError{StatusCode: 400, Body: "invalid userName"})
+			},
+			maxAttempts: 3,
+			expectError: true,
+			expectCalls: 1,
+		},
+		{
+			name: "rate limit is retried",
+			operation: func() error {
+				return &bi.HTTPError{StatusCode: 429, Body: "Too Many Requests"}
+			},
+			maxAttempts: 3,
+			expectError: true,
+			expectCalls: 3,
+		},
+		{
+			name: "cancelled request is not retried",
+			operation: func() error {
+				return &url.Error{Op: "Post", URL: "https://api.example.com/scim/v2/Users", Err: context.Canceled}
+			},
+			maxAttempts: 3,
+			expectError: true,
+			expectCalls: 1,
+		},
+		{
+			name: "timed out run is not retried",
+			operation: func() error {
+				return fmt.Errorf("failed to create user: %w", context.DeadlineExceeded)
+			},
+			maxAttempts: 3,
+			expectError: true,
+			expectCalls: 1,
+		},
+		{
+			name: "zero attempts still runs once",
+			operation: func() error {
+				return errors.New("always fails")
+			},
+			maxAttempts: 0,
+			expectError: true,
+			expectCalls: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -570,7 +1133,7 @@ func TestRetryWithBackoff(t *testing.T) {
 				return tt.operation()
 			}
 
-			err := engine.RetryWithBackoff(operation, tt.maxAttempts, 1*time.Millisecond)
+			err := engine.RetryWithBackoff(context.Background(), operation, tt.maxAttempts, 1*time.Millisecond)
 
 			if tt.expectError && err == nil {
 				t.Errorf("Expected error, got nil")
@@ -587,6 +1150,67 @@ func TestRetryWithBackoff(t *testing.T) {
 	}
 }
 
+func TestRetryWithBackoff_StopsWhenCancelled(t *testing.T) {
+	engine := &Engine{logger: logrus.New()}
+	engine.logger.SetLevel(logrus.FatalLevel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	operation := func() error {
+		calls++
+		// Cancel once the engine starts waiting out the backoff
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return &bi.HTTPError{StatusCode: 503, Body: "Service Unavailable"}
+	}
+
+	start := time.Now()
+	err := engine.RetryWithBackoff(ctx, operation, 3, time.Hour)
+	if err == nil {
+		t.Fatal("Expected the last error once cancelled")
+	}
+	if calls != 1 {
+		t.Errorf("Expected no retry after cancellation, got %d calls", calls)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to interrupt the backoff, waited %v", elapsed)
+	}
+}
+
+func TestRetry_LeavesHTTPErrorsToTransport(t *testing.T) {
+	tests := []struct {
+		name           string
+		transportRetry int
+		err            error
+		expectCalls    int
+	}{
+		{"http error already retried by the transport", 4, &bi.HTTPError{StatusCode: 429, Body: "Too Many Requests"}, 1},
+		{"network error", 4, &net.OpError{Op: "dial", Err: errors.New("connection refused")}, 3},
+		{"http error with transport retries disabled", 1, &bi.HTTPError{StatusCode: 503, Body: "Service Unavailable"}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Sync.RetryAttempts = 3
+			cfg.Sync.Retry.MaxAttempts = tt.transportRetry
+			engine := &Engine{config: cfg, logger: logrus.New()}
+			engine.logger.SetLevel(logrus.FatalLevel)
+
+			calls := 0
+			err := engine.retry(context.Background(), func() error {
+				calls++
+				return tt.err
+			})
+			if err == nil {
+				t.Fatal("Expected the write to fail")
+			}
+			if calls != tt.expectCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectCalls, calls)
+			}
+		})
+	}
+}
+
 func TestSyncResult(t *testing.T) {
 	result := &SyncResult{
 		GroupsProcessed:    5,
@@ -626,3 +1250,157 @@ func TestSyncResult(t *testing.T) {
 		t.Errorf("Expected 1 error, got %d", len(result.Errors))
 	}
 }
+
+// unauthorizedBIClient rejects every user lookup as if the API token had expired
+type unauthorizedBIClient struct {
+	*mockBIClient
+}
+
+func (m *unauthorizedBIClient) FindUserByEmail(ctx context.Context, email string) (*bi.User, error) {
+	return nil, fmt.Errorf("failed to search for user: %w", &bi.HTTPError{StatusCode: 401, Body: "Unauthorized"})
+}
+
+func TestSyncResult_ErrorSummary(t *testing.T) {
+	unauthorized := &bi.HTTPError{StatusCode: 401, Body: "Unauthorized"}
+	result := &SyncResult{
+		Errors: []error{
+			&SyncError{Kind: "user", Subject: "a@example.com", Err: fmt.Errorf("failed to search for user: %w", unauthorized)},
+			&SyncError{Kind: "group", Subject: "eng@example.com", Err: errors.New("mock GWS error")},
+			&SyncError{Kind: "user", Subject: "b@example.com", Err: fmt.Errorf("failed to create user: %w", unauthorized)},
+			&SyncError{Kind: "user", Subject: "c@example.com", Err: unauthorized},
+		},
+	}
+
+	summary := result.ErrorSummary()
+	if len(summary) != 2 {
+		t.Fatalf("Expected 2 error groups, got %d: %v", len(summary), summary)
+	}
+
+	if got, want := summary[0].String(), "3 users failed with: HTTP 401: Unauthorized"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if len(summary[0].Subjects) != 3 || summary[0].Subjects[0] != "a@example.com" {
+		t.Errorf("Expected affected users in order, got %v", summary[0].Subjects)
+	}
+	if got, want := summary[1].String(), "1 group failed with: mock GWS error"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"rate limited", &bi.HTTPError{StatusCode: 429}, ErrorClassTransient},
+		{"server error", fmt.Errorf("failed to create user: %w", &bi.HTTPError{StatusCode: 503}), ErrorClassTransient},
+		{"google backend error", &googleapi.Error{Code: 500}, ErrorClassTransient},
+		{"network timeout", &net.OpError{Op: "dial", Err: context.DeadlineExceeded}, ErrorClassTransient},
+		{"connection reset", fmt.Errorf("request failed: %w", syscall.ECONNRESET), ErrorClassTransient},
+		{"validation error", &bi.SCIMError{Status: "400", Detail: "invalid email"}, ErrorClassPermanent},
+		{"conflict", &bi.HTTPError{StatusCode: 409}, ErrorClassPermanent},
+		{"unauthorized", &bi.HTTPError{StatusCode: 401}, ""},
+		{"unknown", errors.New("something went wrong"), ""},
+		{"cancelled request", &url.Error{Op: "Post", URL: "https://api.example.com/scim/v2/Users", Err: context.Canceled}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err); got != tt.want {
+				t.Errorf("Expected class %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSync_AbortsOnRepeatedAuthErrors(t *testing.T) {
+	var members []*gws.GroupMember
+	for i := 0; i < 5; i++ {
+		members = append(members, &gws.GroupMember{Email: fmt.Sprintf("user%d@example.com", i), Type: "USER", Status: "ACTIVE"})
+	}
+
+	gwsClient := &mockGWSClient{
+		groups: map[string]*gws.Group{
+			"eng@example.com":   {Name: "Engineering"},
+			"sales@example.com": {Name: "Sales"},
+		},
+		members: map[string][]*gws.GroupMember{
+			"eng@example.com":   members,
+			"sales@example.com": members,
+		},
+	}
+	biClient := &unauthorizedBIClient{&mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}}
+
+	cfg := &config.Config{
+		Sync: config.SyncConfig{
+			Groups:             []string{"eng@example.com", "sales@example.com"},
+			AuthErrorThreshold: 3,
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	result, err := NewEngine(gwsClient, biClient, cfg, logger).Sync(context.Background())
+	if !errors.Is(err, ErrSyncAborted) {
+		t.Fatalf("Expected ErrSyncAborted, got %v", err)
+	}
+	if result == nil || !result.Aborted {
+		t.Fatal("Expected result to be marked as aborted")
+	}
+	if result.AuthErrors != 3 || len(result.Errors) != 3 {
+		t.Errorf("Expected the run to stop after 3 auth errors, got %d auth errors and %d errors", result.AuthErrors, len(result.Errors))
+	}
+	if result.GroupsProcessed != 0 {
+		t.Errorf("Expected no groups processed, got %d", result.GroupsProcessed)
+	}
+
+	// With the threshold disabled every user is attempted
+	cfg.Sync.AuthErrorThreshold = -1
+	result, err = NewEngine(gwsClient, biClient, cfg, logger).Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 10 {
+		t.Errorf("Expected 10 user errors, got %d", len(result.Errors))
+	}
+}
+
+func TestSync_ErrorLimits(t *testing.T) {
+	gwsClient := &mockGWSClient{groups: map[string]*gws.Group{}, shouldError: true}
+	biClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+	groups := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"}
+
+	tests := []struct {
+		name        string
+		syncCfg     config.SyncConfig
+		wantAborted bool
+		wantErrors  int
+	}{
+		{"no limits", config.SyncConfig{Groups: groups}, false, 4},
+		{"fail fast", config.SyncConfig{Groups: groups, FailFast: true}, true, 1},
+		{"error budget", config.SyncConfig{Groups: groups, ErrorBudget: 2}, true, 2},
+		{"error budget not reached", config.SyncConfig{Groups: groups, ErrorBudget: 5}, false, 4},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Sync: tt.syncCfg}
+
+			result, err := NewEngine(gwsClient, biClient, cfg, logger).Sync(context.Background())
+			if tt.wantAborted != errors.Is(err, ErrSyncAborted) {
+				t.Errorf("Expected aborted=%v, got error %v", tt.wantAborted, err)
+			}
+			if result.Aborted != tt.wantAborted {
+				t.Errorf("Expected result.Aborted=%v, got %v", tt.wantAborted, result.Aborted)
+			}
+			if len(result.Errors) != tt.wantErrors {
+				t.Errorf("Expected %d errors, got %d", tt.wantErrors, len(result.Errors))
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// SyncEnrollment only refreshes the enrollment group from the passkey status of the members of
+// the configured groups, without provisioning users or groups in Beyond Identity. Members of
+// several groups are checked once per target
+func (e *Engine) SyncEnrollment(ctx context.Context) (*SyncResult, error) {
+	ctx, finish, err := e.beginRun(ctx, RunKindEnrollment, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := e.syncEnrollment(ctx)
+	finish(result, err)
+	return result, err
+}
+
+func (e *Engine) syncEnrollment(ctx context.Context) (*SyncResult, error) {
+	result := &SyncResult{}
+	e.startExplaining(result)
+	defer result.finishExplaining()
+	e.logger.Info("Starting enrollment group sync...")
+
+	if r, ok := e.source.(refresher); ok {
+		if err := r.Refresh(ctx); err != nil {
+			return nil, fmt.Errorf("failed to refresh membership source: %w", err)
+		}
+	}
+	if e.ReadOnly() {
+		result.ReadOnly = true
+		e.logger.Warn("Read-only mode: changes will be reported but not written")
+	}
+
+	// Collect the members of every group by target, keeping the first occurrence of each user
+	var targets []string
+	members := make(map[string][]*gws.GroupMember)
+	seen := make(map[string]bool)
+	for _, groupEmail := range e.resolveAliases(ctx, e.configuredGroups(), result) {
+		if e.nextGroup(ctx, result) {
+			break
+		}
+		if e.skipOrphaned(groupEmail) {
+			result.GroupsSkipped++
+			result.explainGroup(groupEmail, e.config.TargetForGroup(groupEmail), "not read: deleted from the source")
+			continue
+		}
+
+		groupMembers, err := e.groupMembers(ctx, groupEmail, result)
+		if err != nil {
+			e.logger.Errorf("Failed to get members of group %s: %v", groupEmail, err)
+			e.addError(result, "group", groupEmail, fmt.Errorf("failed to get GWS group members: %w", err))
+			continue
+		}
+		result.GroupsProcessed++
+
+		targetName := e.config.TargetForGroup(groupEmail)
+		result.explaining = groupEmail
+		e.explainSourceMembership(groupEmail, targetName, groupMembers, result)
+		if _, ok := members[targetName]; !ok {
+			targets = append(targets, targetName)
+			members[targetName] = nil
+		}
+		for _, member := range groupMembers {
+			key := targetName + "\x00" + strings.ToLower(member.Email)
+			if !seen[key] {
+				seen[key] = true
+				members[targetName] = append(members[targetName], member)
+			}
+		}
+	}
+
+	result.explaining = ""
+	for _, targetName := range targets {
+		if result.Aborted || e.runCancelled(ctx, result) {
+			break
+		}
+		biClient, err := e.clientForTarget(targetName)
+		if err != nil {
+			e.addError(result, "enrollment", targetName, err)
+			continue
+		}
+
+		e.logger.Infof("Starting enrollment status sync for %d members of target %s", len(members[targetName]), targetName)
+		if err := e.syncEnrollmentStatus(ctx, biClient, targetName, members[targetName], result); err != nil {
+			e.logger.Errorf("Failed to sync enrollment status: %v", err)
+			e.addError(result, "enrollment", "", err)
+		}
+	}
+
+	e.logger.Infof("Enrollment group sync completed. Groups: %d, Memberships added: %d, Memberships removed: %d, Errors: %d",
+		result.GroupsProcessed, result.MembershipsAdded, result.MembershipsRemoved, len(result.Errors))
+
+	if err := e.state.Save(); err != nil {
+		e.logger.Errorf("Failed to save sync state: %v", err)
+	}
+
+	if result.Aborted {
+		e.logger.Errorf("Enrollment group sync aborted: %s", result.AbortReason)
+		return result, fmt.Errorf("%w: %s", ErrSyncAborted, result.AbortReason)
+	}
+	return result, nil
+}
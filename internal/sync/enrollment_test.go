@@ -0,0 +1,47 @@
+package sync
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+func TestSyncEnrollment(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.config.Sync.EnrollmentGroupEmail = "enrolled@example.com"
+	engine.config.Sync.EnrollmentGroupName = "BYID Enrolled"
+	// alice is in both groups, so her status is checked once
+	gwsClient.members["sales@example.com"] = append(gwsClient.members["sales@example.com"], &gws.GroupMember{Email: "alice@example.com", Type: "USER", Status: "ACTIVE"})
+
+	result, err := engine.SyncEnrollment(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.GroupsProcessed != 2 || result.MembershipsAdded != 3 {
+		t.Errorf("Expected 2 groups read and 3 users added to the enrollment group, got %+v", result)
+	}
+	if biClient.statusCalls != 3 {
+		t.Errorf("Expected 3 enrollment checks, got %d", biClient.statusCalls)
+	}
+	if len(biClient.users) != 0 || len(biClient.groups) != 0 {
+		t.Errorf("Expected nothing provisioned, got %d users and %d groups", len(biClient.users), len(biClient.groups))
+	}
+
+	var enrolled []string
+	for _, member := range gwsClient.members["enrolled@example.com"] {
+		enrolled = append(enrolled, member.Email)
+	}
+	sort.Strings(enrolled)
+	if strings.Join(enrolled, ",") != "alice@example.com,bob@example.com,carol@example.com" {
+		t.Errorf("Expected every member in the enrollment group, got %v", enrolled)
+	}
+
+	runs := engine.state.Runs()
+	if len(runs) != 1 || runs[0].Kind != RunKindEnrollment {
+		t.Errorf("Expected an enrollment run in the journal, got %+v", runs)
+	}
+}
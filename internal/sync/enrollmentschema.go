@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// errUserSchemasUnsupported is returned when the Google Workspace client cannot write custom user schema fields
+var errUserSchemasUnsupported = errors.New("custom user schema fields need the Admin SDK (google_workspace.api: admin_sdk)")
+
+// userSchemaWriter is implemented by Google Workspace clients that can set custom user schema
+// fields, which sync.enrollment_schema stamps enrollment status on
+type userSchemaWriter interface {
+	SetUserSchemaFields(ctx context.Context, email, schemaName string, fields map[string]interface{}) error
+}
+
+// SetUserSchemaFields implements userSchemaWriter, failing when the current client does not
+func (r *rotatingGWSClient) SetUserSchemaFields(ctx context.Context, email, schemaName string, fields map[string]interface{}) error {
+	return r.do(func(client GWSClient) error {
+		writer, ok := client.(userSchemaWriter)
+		if !ok {
+			return errUserSchemasUnsupported
+		}
+		return writer.SetUserSchemaFields(ctx, email, schemaName, fields)
+	})
+}
+
+// SetUserSchemaFields implements userSchemaWriter, failing when the admins' clients do not
+func (d *delegatingGWSClient) SetUserSchemaFields(ctx context.Context, email, schemaName string, fields map[string]interface{}) error {
+	return d.do(email, func(client GWSClient) error {
+		writer, ok := client.(userSchemaWriter)
+		if !ok {
+			return errUserSchemasUnsupported
+		}
+		return writer.SetUserSchemaFields(ctx, email, schemaName, fields)
+	})
+}
+
+// stampEnrollmentSchema records a user's enrollment status and when it was checked on the custom
+// schema fields of sync.enrollment_schema. Failures are only logged: once a write fails, e.g.
+// because the schema was not created in the domain, no fields are written for the rest of the run
+func (e *Engine) stampEnrollmentSchema(ctx context.Context, email string, enrolled bool, result *SyncResult) {
+	schema := e.config.Sync.EnrollmentSchema
+	if !schema.Enabled || result.runFlags().isSet(flagSchemaStampFailed) {
+		return
+	}
+
+	status := config.EnrollmentStatusNotEnrolled
+	if enrolled {
+		status = config.EnrollmentStatusEnrolled
+	}
+	if e.simulated(config.OperationUpdate) {
+		e.logger.Debugf("TEST MODE: Would set %s.%s of %s to %s", schema.Name, schema.StatusField, email, status)
+		return
+	}
+
+	writer, ok := e.gwsClient.(userSchemaWriter)
+	if !ok {
+		if result.runFlags().setOnce(flagSchemaStampFailed) {
+			e.logger.Warn("sync.enrollment_schema is set but the Google Workspace client cannot write custom user schema fields")
+		}
+		return
+	}
+	fields := map[string]interface{}{
+		schema.StatusField:  status,
+		schema.CheckedField: e.now().UTC().Format(time.RFC3339),
+	}
+	if err := writer.SetUserSchemaFields(ctx, email, schema.Name, fields); err != nil {
+		if result.runFlags().setOnce(flagSchemaStampFailed) {
+			e.logger.Warnf("Failed to write %s custom schema fields, skipping them for this run: %v", schema.Name, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// schemaRecorder records the custom schema fields written for each user, failing with err when set
+type schemaRecorder struct {
+	*mockGWSClient
+	fields map[string]map[string]interface{}
+	err    error
+	calls  int
+}
+
+func (s *schemaRecorder) SetUserSchemaFields(ctx context.Context, email, schemaName string, fields map[string]interface{}) error {
+	s.calls++
+	if s.err != nil {
+		return s.err
+	}
+	s.fields[schemaName+":"+email] = fields
+	return nil
+}
+
+func newSchemaTestEngine(err error) (*Engine, *schemaRecorder) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	recorder := &schemaRecorder{mockGWSClient: gwsClient, fields: make(map[string]map[string]interface{}), err: err}
+
+	cfg := engine.config
+	cfg.Sync.EnrollmentGroupEmail = "enrolled@example.com"
+	cfg.Sync.EnrollmentGroupName = "BYID Enrolled"
+	cfg.Sync.EnrollmentSchema = config.UserSchemaConfig{Enabled: true, Name: "BeyondIdentity", StatusField: "enrollmentStatus", CheckedField: "lastCheckedAt"}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	engine = NewEngine(recorder, biClient, cfg, logger)
+	engine.now = func() time.Time { return time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC) }
+	return engine, recorder
+}
+
+func TestSyncEnrollment_StampsEnrollmentSchema(t *testing.T) {
+	engine, recorder := newSchemaTestEngine(nil)
+
+	if _, err := engine.SyncEnrollment(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(recorder.fields) != 3 {
+		t.Fatalf("Expected fields written for 3 users, got %v", recorder.fields)
+	}
+	fields := recorder.fields["BeyondIdentity:alice@example.com"]
+	if fields["enrollmentStatus"] != config.EnrollmentStatusEnrolled || fields["lastCheckedAt"] != "2025-03-01T09:30:00Z" {
+		t.Errorf("Expected alice stamped as enrolled at the time of the check, got %v", fields)
+	}
+}
+
+func TestSyncEnrollment_SchemaFailureKeepsEnrollmentGroup(t *testing.T) {
+	engine, recorder := newSchemaTestEngine(errors.New("Invalid Input: custom schema BeyondIdentity not found"))
+
+	result, err := engine.SyncEnrollment(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.MembershipsAdded != 3 || len(result.Errors) != 0 {
+		t.Errorf("Expected the enrollment group to be synced despite the schema failure, got %+v", result)
+	}
+	if recorder.calls != 1 {
+		t.Errorf("Expected schema fields to be skipped for the rest of the run after the first failure, got %d writes", recorder.calls)
+	}
+}
@@ -0,0 +1,202 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"syscall"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/okta"
+	"google.golang.org/api/googleapi"
+)
+
+// maxErrorExamples is how many affected subjects are kept per aggregated error
+const maxErrorExamples = 5
+
+// ErrSyncAborted is returned when a run stops early because of fail_fast, the error
+// budget or repeated authentication errors
+var ErrSyncAborted = errors.New("sync aborted")
+
+// SyncError records which user, group or step an error occurred for
+type SyncError struct {
+	Kind    string // "user", "group" or "enrollment"
+	Subject string // Email of the user or group; empty for run-level steps
+	Err     error
+}
+
+func (e *SyncError) Error() string {
+	if e.Subject == "" {
+		return fmt.Sprintf("%s sync: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Kind, e.Subject, e.Err)
+}
+
+func (e *SyncError) Unwrap() error {
+	return e.Err
+}
+
+// Error classes, used to decide whether an operation is worth retrying
+const (
+	ErrorClassTransient = "transient" // Rate limits, server errors and network failures
+	ErrorClassPermanent = "permanent" // Requests the API rejected as invalid; retrying cannot help
+)
+
+// ErrorGroup is a set of errors that share the same kind and root cause
+type ErrorGroup struct {
+	Kind     string   `json:"kind"`
+	Cause    string   `json:"cause"`
+	Class    string   `json:"class,omitempty"` // ErrorClassTransient, ErrorClassPermanent or empty when unknown
+	Count    int      `json:"count"`
+	Subjects []string `json:"subjects,omitempty"` // First few affected subjects
+}
+
+func (g ErrorGroup) String() string {
+	noun := g.Kind
+	if noun == "" {
+		noun = "operation"
+	}
+	if g.Count != 1 {
+		noun += "s"
+	}
+	if g.Class != "" {
+		return fmt.Sprintf("%d %s failed with: %s (%s)", g.Count, noun, g.Cause, g.Class)
+	}
+	return fmt.Sprintf("%d %s failed with: %s", g.Count, noun, g.Cause)
+}
+
+// ErrorSummary aggregates Errors by kind and root cause, most frequent first
+func (r *SyncResult) ErrorSummary() []ErrorGroup {
+	index := make(map[string]int)
+	var groups []ErrorGroup
+
+	for _, err := range r.Errors {
+		kind, subject := "", ""
+		var syncErr *SyncError
+		if errors.As(err, &syncErr) {
+			kind, subject = syncErr.Kind, syncErr.Subject
+		}
+
+		cause := rootCause(err).Error()
+		key := kind + "\x00" + cause
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ErrorGroup{Kind: kind, Cause: cause, Class: classifyError(err)})
+		}
+
+		groups[i].Count++
+		if subject != "" && len(groups[i].Subjects) < maxErrorExamples {
+			groups[i].Subjects = append(groups[i].Subjects, subject)
+		}
+	}
+
+	sort.SliceStable(groups, func(a, b int) bool {
+		return groups[a].Count > groups[b].Count
+	})
+	return groups
+}
+
+// rootCause returns the innermost wrapped error
+func rootCause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// isAuthError reports whether err means the credentials for an API were rejected, so
+// every following call with them is expected to fail the same way
+func isAuthError(err error) bool {
+	if isCredentialError(err) {
+		return true
+	}
+
+	var scopeErr *gws.ScopeError
+	if errors.As(err, &scopeErr) {
+		return true
+	}
+
+	switch errorStatusCode(err) {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+	return false
+}
+
+// classifyError reports whether err is transient, permanent or of unknown class ("")
+func classifyError(err error) string {
+	switch code := errorStatusCode(err); {
+	case code == http.StatusTooManyRequests || code >= http.StatusInternalServerError:
+		return ErrorClassTransient
+	case code == http.StatusBadRequest || code == http.StatusConflict || code == http.StatusUnprocessableEntity:
+		return ErrorClassPermanent
+	case code != 0:
+		return ""
+	}
+
+	// Requests cut short by their run being cancelled failed neither transiently nor permanently
+	if errors.Is(err, context.Canceled) {
+		return ""
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorClassTransient
+	}
+	return ""
+}
+
+// isRetryable reports whether an operation that failed with err may succeed if repeated;
+// errors of unknown class are retried, permanent and authentication errors are not, and
+// neither are requests cut short because their run was cancelled or timed out
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return classifyError(err) != ErrorClassPermanent && !isAuthError(err)
+}
+
+// errorStatusCode extracts the HTTP status from the API error types used by the clients, or 0
+func errorStatusCode(err error) int {
+	var httpErr *bi.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+
+	var scimErr *bi.SCIMError
+	if errors.As(err, &scimErr) {
+		if scimErr.StatusCode != 0 {
+			return scimErr.StatusCode
+		}
+		code, _ := strconv.Atoi(scimErr.Status)
+		return code
+	}
+
+	var oktaErr *okta.APIError
+	if errors.As(err, &oktaErr) {
+		return oktaErr.StatusCode
+	}
+
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code
+	}
+
+	return 0
+}
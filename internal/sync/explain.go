@@ -0,0 +1,222 @@
+package sync
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	gosync "sync"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+)
+
+// explainTrace collects the decisions a run makes about the explained user; it is shared by the
+// results of groups synced concurrently
+type explainTrace struct {
+	email string
+
+	mu      gosync.Mutex
+	steps   []report.ExplainStep
+	userIDs map[string]string // Target -> Beyond Identity ID of the user, once looked up or created
+}
+
+// Explain makes the following runs record every decision they make about the user with email,
+// returned in SyncResult.Explanation; an empty email stops recording
+func (e *Engine) Explain(email string) {
+	e.explainEmail = strings.TrimSpace(email)
+}
+
+// startExplaining prepares result to record the explained user's decisions, if a user is explained
+func (e *Engine) startExplaining(result *SyncResult) {
+	if e.explainEmail != "" {
+		result.trace = &explainTrace{email: e.explainEmail, userIDs: make(map[string]string)}
+	}
+}
+
+// finishExplaining copies the recorded decisions to the result
+func (r *SyncResult) finishExplaining() {
+	if r.trace == nil {
+		return
+	}
+	r.trace.mu.Lock()
+	defer r.trace.mu.Unlock()
+	r.Explanation = append([]report.ExplainStep(nil), r.trace.steps...)
+}
+
+// explains reports whether decisions about email are recorded
+func (r *SyncResult) explains(email string) bool {
+	return r.trace != nil && strings.EqualFold(r.trace.email, email)
+}
+
+// explain records a decision about email in the group being synced, if email is the explained user
+func (r *SyncResult) explain(email, targetName, stage, format string, args ...any) {
+	if r.explains(email) {
+		r.trace.record(r.explaining, targetName, stage, fmt.Sprintf(format, args...))
+	}
+}
+
+// explainGroup records a decision about a whole configured group, e.g. why it was not synced
+func (r *SyncResult) explainGroup(groupEmail, targetName, decision string) {
+	if r.trace != nil {
+		r.trace.record(groupEmail, targetName, report.ExplainStageGroup, decision)
+	}
+}
+
+// explainSourceMembership records whether the group includes the explained user
+func (e *Engine) explainSourceMembership(groupEmail, targetName string, members []*gws.GroupMember, result *SyncResult) {
+	if result.trace == nil {
+		return
+	}
+	partial := ""
+	if result.isPartial(groupEmail) {
+		partial = "; membership was only partly read, so nobody is removed"
+	}
+	for _, member := range members {
+		if result.explains(member.Email) {
+			result.explainGroup(groupEmail, targetName, fmt.Sprintf("includes the user (type %s, status %s)%s", member.Type, member.Status, partial))
+			return
+		}
+	}
+	result.explainGroup(groupEmail, targetName, "does not include the user"+partial)
+}
+
+// explainLookup records whether the explained user was found in a target
+func (e *Engine) explainLookup(targetName, email, userID string, result *SyncResult) {
+	if !result.explains(email) {
+		return
+	}
+	if userID == "" {
+		result.explain(email, targetName, report.ExplainStageLookup, "no user with this email, so it is created")
+		return
+	}
+	result.trace.setUserID(targetName, userID)
+	result.explain(email, targetName, report.ExplainStageLookup, "found existing user (ID: %s)", userID)
+	if !e.config.FeatureEnabled(config.FeatureUserUpdates) {
+		result.explain(email, targetName, report.ExplainStageAttributes, "not compared: existing users are not updated")
+	}
+}
+
+// explainNewUser records the attributes the explained user is created with and where they came from
+func (e *Engine) explainNewUser(email string, profile *gws.User, user *bi.User, result *SyncResult) {
+	if !result.explains(email) {
+		return
+	}
+	source := "derived from the email, as the directory has no profile for the user"
+	if profile != nil && user.Name != nil {
+		source = "from the directory profile"
+	}
+	attrs := []string{"displayName=" + user.DisplayName}
+	if user.Name != nil {
+		attrs = append(attrs, "name.givenName="+user.Name.GivenName, "name.familyName="+user.Name.FamilyName)
+	}
+	result.explain(email, "", report.ExplainStageAttributes, "name %s: %s", source, strings.Join(attrs, ", "))
+
+	if len(e.config.Sync.AttributeMappings) > 0 {
+		mapped := make([]string, 0, len(e.config.Sync.AttributeMappings))
+		for attribute := range e.config.Sync.AttributeMappings {
+			mapped = append(mapped, attribute)
+		}
+		sort.Strings(mapped)
+		result.explain(email, "", report.ExplainStageAttributes, "mapped by sync.attribute_mappings: %s", strings.Join(mapped, ", "))
+	}
+}
+
+// explainMembership records the change to the explained user's membership of a Beyond Identity group
+func (e *Engine) explainMembership(targetName, groupName string, current []bi.GroupMember, desired map[string]string, simulatedAdded []string, partial bool, result *SyncResult) {
+	if result.trace == nil {
+		return
+	}
+	email := result.trace.email
+	userID := result.trace.userID(targetName)
+
+	inDesired := false
+	for id, desiredEmail := range desired {
+		if strings.EqualFold(desiredEmail, email) {
+			inDesired, userID = true, id
+		}
+	}
+	inCurrent := false
+	for _, member := range current {
+		if (userID != "" && member.Value == userID) || strings.EqualFold(memberIdentity(member), email) {
+			inCurrent = true
+		}
+	}
+
+	var decision string
+	switch {
+	case inDesired && inCurrent:
+		decision = "unchanged: already a member of " + groupName
+	case inDesired && containsFold(simulatedAdded, email):
+		decision = "would add to " + groupName + " (test mode)"
+	case inDesired:
+		decision = "add to " + groupName
+	case inCurrent && partial:
+		decision = "unchanged: kept in " + groupName + " because the source membership was only partly read"
+	case inCurrent && e.simulated(config.OperationRemove):
+		decision = "would remove from " + groupName + " (test mode): not an active user member of the source group"
+	case inCurrent:
+		decision = "remove from " + groupName + ": not an active user member of the source group"
+	default:
+		return
+	}
+	result.explain(email, targetName, report.ExplainStageMembership, "%s", decision)
+}
+
+// explainMembershipFailed records that a membership update including the explained user could not
+// be written
+func (e *Engine) explainMembershipFailed(targetName string, added, removed []string, err error, result *SyncResult) {
+	if result.trace == nil {
+		return
+	}
+	email := result.trace.email
+	if containsFold(added, email) || containsFold(removed, email) {
+		result.explain(email, targetName, report.ExplainStageMembership, "failed, queued for retry: %v", err)
+	}
+}
+
+// explainEnrollment records how the explained user's enrollment status decides their membership
+// of the enrollment group
+func (e *Engine) explainEnrollment(targetName, email string, enrolled, inGroup bool, result *SyncResult) {
+	if !result.explains(email) {
+		return
+	}
+	switch {
+	case enrolled && !inGroup:
+		result.explain(email, targetName, report.ExplainStageEnrollment, "add to the enrollment group: active with a passkey")
+	case !enrolled && inGroup:
+		result.explain(email, targetName, report.ExplainStageEnrollment, "remove from the enrollment group: inactive or without a passkey")
+	case enrolled:
+		result.explain(email, targetName, report.ExplainStageEnrollment, "unchanged: enrolled and already in the enrollment group")
+	default:
+		result.explain(email, targetName, report.ExplainStageEnrollment, "unchanged: not enrolled and not in the enrollment group")
+	}
+}
+
+func (t *explainTrace) record(groupEmail, targetName, stage, decision string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.steps = append(t.steps, report.ExplainStep{Group: groupEmail, Target: targetName, Stage: stage, Decision: decision})
+}
+
+func (t *explainTrace) setUserID(targetName, userID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.userIDs[targetName] = userID
+}
+
+func (t *explainTrace) userID(targetName string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.userIDs[targetName]
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+)
+
+// explainedDecisions returns the stage and decision of each step, one per line
+func explainedDecisions(steps []report.ExplainStep) string {
+	var lines []string
+	for _, step := range steps {
+		lines = append(lines, step.Group+" "+step.Stage+": "+step.Decision)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestSync_Explain(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.Explain("Alice@example.com")
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := explainedDecisions(result.Explanation)
+	for _, want := range []string{
+		"eng@example.com group: includes the user (type USER, status ACTIVE)",
+		"eng@example.com filter: passed: active user not on the skip list",
+		"eng@example.com lookup: no user with this email, so it is created",
+		"eng@example.com attributes: name derived from the email",
+		"eng@example.com action: created the user",
+		"eng@example.com membership: add to GWS_Engineering",
+		"eng@example.com enrollment: add to the enrollment group",
+		"sales@example.com group: does not include the user",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected trace to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "bob@example.com") {
+		t.Errorf("Expected only alice's decisions, got:\n%s", got)
+	}
+
+	// On the next run she is found and already a member
+	result, err = engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got = explainedDecisions(result.Explanation)
+	for _, want := range []string{
+		"eng@example.com lookup: found existing user",
+		"eng@example.com attributes: not compared: existing users are not updated",
+		"eng@example.com membership: unchanged: already a member of GWS_Engineering",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected trace to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestSync_ExplainSkipped(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	if _, err := engine.SkipUser("alice@example.com", "invalid address", "tester", 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	engine.Explain("alice@example.com")
+
+	result, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got := explainedDecisions(result.Explanation)
+	if !strings.Contains(got, "filter: skipped: on the skip list (invalid address)") || strings.Contains(got, "lookup") {
+		t.Errorf("Expected alice to be filtered out by the skip list, got:\n%s", got)
+	}
+
+	// Without an explained user nothing is recorded
+	engine.Explain("")
+	if result, _ = engine.SyncGroups(context.Background(), []string{"eng@example.com"}); len(result.Explanation) != 0 {
+		t.Errorf("Expected no trace, got %+v", result.Explanation)
+	}
+}
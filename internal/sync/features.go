@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// ErrFeatureDisabled is returned by operations gated behind a feature flag that is off
+var ErrFeatureDisabled = errors.New("feature disabled")
+
+// requireFeature fails unless the named feature flag is enabled
+func (e *Engine) requireFeature(name string) error {
+	if !e.config.FeatureEnabled(name) {
+		return fmt.Errorf("%w: enable features.%s to use it", ErrFeatureDisabled, name)
+	}
+	return nil
+}
+
+// bulkUserCreator is implemented by clients that can create users with SCIM Bulk requests
+type bulkUserCreator interface {
+	CreateUsersBulk(ctx context.Context, users []*bi.User) ([]bi.BulkUserResult, error)
+}
+
+// bulkCreator returns the client's bulk interface when the bulk_api flag is enabled; when
+// creations are simulated nothing is created, so each user keeps being logged individually
+func (e *Engine) bulkCreator(biClient BIClient) bulkUserCreator {
+	if e.simulated(config.OperationCreate) || !e.config.FeatureEnabled(config.FeatureBulkAPI) {
+		return nil
+	}
+	creator, _ := biClient.(bulkUserCreator)
+	return creator
+}
+
+// createBIUsersBulk creates the missing users in bulk, adding them to users keyed by ID. If the
+// target rejects the bulk request as a whole, the users are created one at a time instead
+func (e *Engine) createBIUsersBulk(ctx context.Context, creator bulkUserCreator, biClient BIClient, targetName string, emails []string, users map[string]string, result *SyncResult) error {
+	var newUsers []*bi.User
+	var bulkEmails []string
+	for _, email := range emails {
+		newUser, err := e.newBIUser(ctx, email, result)
+		if err != nil {
+			if e.userFailed(email, err, result) {
+				return ErrSyncAborted
+			}
+			continue
+		}
+		newUsers = append(newUsers, newUser)
+		bulkEmails = append(bulkEmails, email)
+	}
+	if len(newUsers) == 0 {
+		return nil
+	}
+
+	e.logger.Infof("Creating %d users in target %s with SCIM Bulk", len(newUsers), targetName)
+	results, err := creator.CreateUsersBulk(ctx, newUsers)
+	if err != nil {
+		e.logger.Warnf("Bulk user creation in target %s failed, creating users individually: %v", targetName, err)
+	}
+
+	for i, email := range bulkEmails {
+		var userID string
+		var userErr error
+		switch {
+		case i >= len(results):
+			// Not attempted before the bulk request failed
+			userID, userErr = e.ensureBIUser(ctx, biClient, targetName, email, result)
+		case results[i].Err != nil:
+			userErr = fmt.Errorf("failed to create user: %w", results[i].Err)
+		default:
+			userID = results[i].User.ID
+			e.userCreated(targetName, email, userID, result)
+		}
+
+		if userErr != nil {
+			if e.userFailed(email, userErr, result) {
+				return ErrSyncAborted
+			}
+			continue
+		}
+		if userID != "" {
+			users[userID] = email
+		}
+	}
+	return nil
+}
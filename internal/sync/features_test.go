@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// bulkBIClient adds SCIM Bulk user creation to the mock client
+type bulkBIClient struct {
+	*mockBIClient
+	bulkErr   error  // Returned for the whole bulk request when set
+	rejectFor string // Email whose operation fails within the bulk request
+	bulkCalls int
+	bulkUsers int
+}
+
+func (m *bulkBIClient) CreateUsersBulk(ctx context.Context, users []*bi.User) ([]bi.BulkUserResult, error) {
+	m.bulkCalls++
+	if m.bulkErr != nil {
+		return nil, m.bulkErr
+	}
+	results := make([]bi.BulkUserResult, len(users))
+	for i, user := range users {
+		if user.UserName == m.rejectFor {
+			results[i].Err = &bi.SCIMError{StatusCode: 409, Status: "409", Detail: "user already exists"}
+			continue
+		}
+		results[i].User, results[i].Err = m.CreateUser(ctx, user)
+		m.bulkUsers++
+	}
+	return results, nil
+}
+
+func newBulkTestEngine(features map[string]bool) (*Engine, *bulkBIClient) {
+	engine, _, biClient := newTargetedTestEngine()
+	client := &bulkBIClient{mockBIClient: biClient}
+	engine.biClient = client
+	engine.config.Features = features
+	return engine, client
+}
+
+func TestSync_BulkAPI(t *testing.T) {
+	tests := []struct {
+		name          string
+		features      map[string]bool
+		bulkErr       error
+		rejectFor     string
+		expectCalls   int
+		expectBulk    int
+		expectCreated int
+		expectErrors  int
+	}{
+		{
+			name:          "flag off creates users individually",
+			expectCalls:   0,
+			expectCreated: 3,
+		},
+		{
+			name:          "flag on creates users in one request per group",
+			features:      map[string]bool{config.FeatureBulkAPI: true},
+			expectCalls:   2,
+			expectBulk:    3,
+			expectCreated: 3,
+		},
+		{
+			name:          "rejected operations are sync errors",
+			features:      map[string]bool{config.FeatureBulkAPI: true},
+			rejectFor:     "bob@example.com",
+			expectCalls:   2,
+			expectBulk:    2,
+			expectCreated: 2,
+			expectErrors:  1,
+		},
+		{
+			name:          "failed bulk request falls back to individual creation",
+			features:      map[string]bool{config.FeatureBulkAPI: true},
+			bulkErr:       errors.New("HTTP 501: bulk not supported"),
+			expectCalls:   2,
+			expectCreated: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, client := newBulkTestEngine(tt.features)
+			client.bulkErr = tt.bulkErr
+			client.rejectFor = tt.rejectFor
+
+			result, err := engine.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if client.bulkCalls != tt.expectCalls || client.bulkUsers != tt.expectBulk {
+				t.Errorf("Expected %d bulk requests creating %d users, got %d creating %d", tt.expectCalls, tt.expectBulk, client.bulkCalls, client.bulkUsers)
+			}
+			if result.UsersCreated != tt.expectCreated {
+				t.Errorf("Expected %d users created, got %d", tt.expectCreated, result.UsersCreated)
+			}
+			if len(result.Errors) != tt.expectErrors {
+				t.Errorf("Expected %d errors, got %v", tt.expectErrors, result.Errors)
+			}
+			if tt.rejectFor != "" && !strings.Contains(result.Errors[0].Error(), tt.rejectFor) {
+				t.Errorf("Expected the error to name %s, got %v", tt.rejectFor, result.Errors[0])
+			}
+		})
+	}
+}
+
+func TestPlanDeprovision_FeatureDisabled(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.config.Features = map[string]bool{config.FeatureDeprovisioning: false}
+
+	if _, err := engine.PlanDeprovision(context.Background(), "alice@example.com"); !errors.Is(err, ErrFeatureDisabled) {
+		t.Errorf("Expected ErrFeatureDisabled, got %v", err)
+	}
+	if _, err := engine.DeprovisionUser(context.Background(), "alice@example.com", true); !errors.Is(err, ErrFeatureDisabled) {
+		t.Errorf("Expected ErrFeatureDisabled, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+)
+
+// userFilter is sync.filters compiled for a run
+type userFilter struct {
+	excludeDomains  map[string]bool
+	excludeEmails   map[string]bool
+	excludePatterns []*regexp.Regexp
+	includePatterns []*regexp.Regexp
+	includeOrgUnits []string
+}
+
+// newUserFilter compiles sync.filters; it returns nil when no filter is configured
+func newUserFilter(filters config.UserFiltersConfig) (*userFilter, error) {
+	f := &userFilter{
+		excludeDomains:  make(map[string]bool),
+		excludeEmails:   make(map[string]bool),
+		includeOrgUnits: filters.IncludeOrgUnits,
+	}
+	for _, domain := range filters.ExcludeDomains {
+		f.excludeDomains[strings.ToLower(strings.TrimPrefix(domain, "@"))] = true
+	}
+	for _, email := range filters.ExcludeEmails {
+		f.excludeEmails[strings.ToLower(email)] = true
+	}
+	for _, pattern := range filters.ExcludePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sync.filters.exclude_patterns entry %q: %w", pattern, err)
+		}
+		f.excludePatterns = append(f.excludePatterns, re)
+	}
+	for _, pattern := range filters.IncludePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sync.filters.include_patterns entry %q: %w", pattern, err)
+		}
+		f.includePatterns = append(f.includePatterns, re)
+	}
+
+	if len(f.excludeDomains)+len(f.excludeEmails)+len(f.excludePatterns)+len(f.includePatterns)+len(f.includeOrgUnits) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// excludedByEmail returns why the address alone keeps a user from being provisioned, or ""
+func (f *userFilter) excludedByEmail(email string) string {
+	lower := strings.ToLower(email)
+	if f.excludeEmails[lower] {
+		return "listed in sync.filters.exclude_emails"
+	}
+	if at := strings.LastIndex(lower, "@"); at >= 0 && f.excludeDomains[lower[at+1:]] {
+		return fmt.Sprintf("domain %s is in sync.filters.exclude_domains", lower[at+1:])
+	}
+	for _, re := range f.excludePatterns {
+		if re.MatchString(email) {
+			return fmt.Sprintf("matches sync.filters.exclude_patterns entry %s", re)
+		}
+	}
+	if len(f.includePatterns) == 0 {
+		return ""
+	}
+	for _, re := range f.includePatterns {
+		if re.MatchString(email) {
+			return ""
+		}
+	}
+	return "matches none of sync.filters.include_patterns"
+}
+
+// excludedByOrgUnit returns why a user in orgUnit, "" when the directory does not know them,
+// is not provisioned, or ""
+func (f *userFilter) excludedByOrgUnit(orgUnit string) string {
+	if orgUnit == "" {
+		return "not in the directory, so in none of sync.filters.include_org_units"
+	}
+	for _, include := range f.includeOrgUnits {
+		include = strings.TrimSuffix(include, "/")
+		if include == "" || orgUnit == include || strings.HasPrefix(orgUnit, include+"/") {
+			return ""
+		}
+	}
+	return fmt.Sprintf("org unit %s is in none of sync.filters.include_org_units", orgUnit)
+}
+
+// runFilter returns the run's compiled sync.filters, compiling them on first use
+func (e *Engine) runFilter(result *SyncResult) (*userFilter, error) {
+	if result.filterCompiled {
+		return result.filter, nil
+	}
+	filter, err := newUserFilter(e.config.Sync.Filters)
+	if err != nil {
+		return nil, err
+	}
+	result.filter, result.filterCompiled = filter, true
+	return filter, nil
+}
+
+// filteredOut returns why sync.filters keep a member from being provisioned, or "". The org unit
+// filter reads the user's directory profile
+func (e *Engine) filteredOut(ctx context.Context, email string, result *SyncResult) (string, error) {
+	filter, err := e.runFilter(result)
+	if err != nil || filter == nil {
+		return "", err
+	}
+
+	reason := filter.excludedByEmail(email)
+	if reason != "" || len(filter.includeOrgUnits) == 0 {
+		return reason, nil
+	}
+	profile, err := e.userProfile(ctx, email, result)
+	if err != nil {
+		return "", fmt.Errorf("failed to read org unit of %s: %w", email, err)
+	}
+	orgUnit := ""
+	if profile != nil {
+		orgUnit = profile.OrgUnitPath
+	}
+	return filter.excludedByOrgUnit(orgUnit), nil
+}
+
+// filterUser reports whether sync.filters keep a member from being provisioned, counting them
+// as filtered if so
+func (e *Engine) filterUser(ctx context.Context, email, targetName string, result *SyncResult) (bool, error) {
+	reason, err := e.filteredOut(ctx, email, result)
+	if err != nil || reason == "" {
+		return false, err
+	}
+
+	e.logger.Debugf("Filtering out %s: %s", email, reason)
+	result.UsersFiltered++
+	result.explain(email, targetName, report.ExplainStageFilter, "skipped: %s", reason)
+	return true, nil
+}
@@ -0,0 +1,87 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+func TestUserFilter(t *testing.T) {
+	filter, err := newUserFilter(config.UserFiltersConfig{
+		ExcludeDomains:  []string{"Contractors.example.com"},
+		ExcludeEmails:   []string{"test@example.com"},
+		ExcludePatterns: []string{`^svc-`},
+		IncludePatterns: []string{`@(contractors\.)?example\.com$`},
+		IncludeOrgUnits: []string{"/Employees/"},
+	})
+	if err != nil {
+		t.Fatalf("newUserFilter() error = %v", err)
+	}
+
+	for email, excluded := range map[string]bool{
+		"alice@example.com":             false,
+		"TEST@example.com":              true,
+		"bob@contractors.example.com":   true,
+		"svc-backup@example.com":        true,
+		"guest@gmail.com":               true,
+		"carol@sub.contractors.example": true,
+	} {
+		if got := filter.excludedByEmail(email) != ""; got != excluded {
+			t.Errorf("excludedByEmail(%s) = %q, want excluded %v", email, filter.excludedByEmail(email), excluded)
+		}
+	}
+
+	for orgUnit, excluded := range map[string]bool{
+		"/Employees":          false,
+		"/Employees/Platform": false,
+		"/EmployeesOld":       true,
+		"/Contractors":        true,
+		"":                    true,
+	} {
+		if got := filter.excludedByOrgUnit(orgUnit) != ""; got != excluded {
+			t.Errorf("excludedByOrgUnit(%q) = %q, want excluded %v", orgUnit, filter.excludedByOrgUnit(orgUnit), excluded)
+		}
+	}
+
+	if none, err := newUserFilter(config.UserFiltersConfig{}); none != nil || err != nil {
+		t.Errorf("Expected no filter without settings, got %v, %v", none, err)
+	}
+}
+
+func TestSync_Filters(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.gwsClient = &directoryGWSClient{
+		mockGWSClient: gwsClient,
+		users: map[string]*gws.User{
+			"alice@example.com": {PrimaryEmail: "alice@example.com", OrgUnitPath: "/Employees/Platform"},
+			"bob@example.com":   {PrimaryEmail: "bob@example.com", OrgUnitPath: "/Employees"},
+			"carol@example.com": {PrimaryEmail: "carol@example.com", OrgUnitPath: "/Contractors"},
+		},
+	}
+	engine.config.Sync.Filters = config.UserFiltersConfig{
+		ExcludeEmails:   []string{"bob@example.com"},
+		IncludeOrgUnits: []string{"/Employees"},
+	}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.UsersFiltered != 2 || result.UsersCreated != 1 {
+		t.Errorf("Expected bob and carol filtered out and only alice created, got %d filtered, %d created", result.UsersFiltered, result.UsersCreated)
+	}
+	if user, _ := biClient.FindUserByEmail(context.Background(), "carol@example.com"); user != nil {
+		t.Error("Expected carol not to be provisioned")
+	}
+
+	// A targeted sync treats a filtered user as not in the group
+	provisioned, err := engine.ProvisionUser(context.Background(), "bob@example.com")
+	if err != nil {
+		t.Fatalf("ProvisionUser() error = %v", err)
+	}
+	if len(provisioned.Groups) != 0 || provisioned.UsersCreated != 0 {
+		t.Errorf("Expected bob not to be provisioned, got %+v", provisioned.Groups)
+	}
+}
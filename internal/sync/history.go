@@ -0,0 +1,72 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// recordChange adds a change to the run's change records and, for user and membership changes
+// that were made, to the history queried by Changes. result may be nil for operations outside a
+// sync run, whose changes only go to the history
+func (e *Engine) recordChange(result *SyncResult, change report.ChangeRecord) {
+	change.Time = e.now()
+	change.Entity = changeEntity(change.Action)
+	if result != nil {
+		result.Changes = append(result.Changes, change)
+	}
+	if change.Planned || change.Error != "" || change.Entity == report.EntityGroup || e.dryRun() {
+		return
+	}
+
+	e.state.RecordChanges(state.Change{
+		Time:   change.Time,
+		Action: change.Action,
+		User:   change.User,
+		Group:  change.Group,
+		Target: change.Target,
+	})
+}
+
+// recordFailedChange adds a change that failed to the run's change records
+func (e *Engine) recordFailedChange(result *SyncResult, change report.ChangeRecord, err error) {
+	change.Error = err.Error()
+	e.recordChange(result, change)
+}
+
+// recordPlannedChanges adds the membership changes a group would have had to the run's change records
+func (e *Engine) recordPlannedChanges(result *SyncResult, groupEmail, targetName string, added, removed []string) {
+	for _, user := range added {
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipAdded, User: user, Group: groupEmail, Target: targetName, Planned: true})
+	}
+	for _, user := range removed {
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipRemoved, User: user, Group: groupEmail, Target: targetName, Planned: true})
+	}
+}
+
+// changeEntity returns what a change action is about
+func changeEntity(action string) string {
+	switch action {
+	case state.ChangeMembershipAdded, state.ChangeMembershipRemoved:
+		return report.EntityMembership
+	case state.ChangeGroupCreated:
+		return report.EntityGroup
+	default:
+		return report.EntityUser
+	}
+}
+
+// Changes returns the user and membership changes made between since and until
+func (e *Engine) Changes(since, until time.Time) []state.Change {
+	return e.state.Changes(since, until)
+}
+
+// memberIdentity names a Beyond Identity group member by email when the API returned one
+func memberIdentity(member bi.GroupMember) string {
+	if member.Display != "" {
+		return member.Display
+	}
+	return member.Value
+}
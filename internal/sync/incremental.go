@@ -0,0 +1,192 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// deltaPlan selects the groups an incremental run syncs
+type deltaPlan struct {
+	since   time.Time
+	changed map[string]bool // Lower-cased source addresses of groups changed since
+}
+
+// errChangesUnsupported is returned when the Google Workspace client cannot read the audit log
+var errChangesUnsupported = errors.New("the Google Workspace client cannot read the audit log")
+
+// auditLogClient lets the key rotation and admin failover wrappers manage an audit log
+// reader; only Changes is called on it
+type auditLogClient struct {
+	GWSClient
+	*gws.ChangeReader
+}
+
+// ConfigureIncremental creates the audit log reader incremental runs use when
+// sync.incremental.enabled is set. Like the directory client, the reader reloads changed
+// service account keys and fails over between keys and between the configured admins
+func (e *Engine) ConfigureIncremental(httpClient *http.Client) error {
+	if !e.config.Sync.Incremental.Enabled {
+		return nil
+	}
+
+	gwsCfg := e.config.GoogleWorkspace
+	client, err := newFailoverGWSClient(gwsCfg, func(keyPath, subject string) (GWSClient, error) {
+		reader, err := gws.NewChangeReader(keyPath, subject, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		reader.SetPageRetryAttempts(gwsCfg.PageRetryAttempts)
+		return auditLogClient{ChangeReader: reader}, nil
+	}, e.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log reader: %w", err)
+	}
+	reader, ok := client.(ChangeReader)
+	if !ok {
+		return errChangesUnsupported
+	}
+	e.SetChangeReader(reader)
+	return nil
+}
+
+// Changes implements ChangeReader, failing when the current client does not
+func (r *rotatingGWSClient) Changes(ctx context.Context, since time.Time) (*gws.Changes, error) {
+	var changes *gws.Changes
+	err := r.do(func(client GWSClient) error {
+		reader, ok := client.(ChangeReader)
+		if !ok {
+			return errChangesUnsupported
+		}
+		var err error
+		changes, err = reader.Changes(ctx, since)
+		return err
+	})
+	return changes, err
+}
+
+// Changes implements ChangeReader as the super admin and its fallbacks, since the audit log
+// covers every domain
+func (d *delegatingGWSClient) Changes(ctx context.Context, since time.Time) (*gws.Changes, error) {
+	var changes *gws.Changes
+	err := d.do("", func(client GWSClient) error {
+		reader, ok := client.(ChangeReader)
+		if !ok {
+			return errChangesUnsupported
+		}
+		var err error
+		changes, err = reader.Changes(ctx, since)
+		return err
+	})
+	return changes, err
+}
+
+// SetChangeReader sets where incremental runs read changes from; nil makes every run full
+func (e *Engine) SetChangeReader(reader ChangeReader) {
+	e.changes = reader
+}
+
+// planDelta decides whether a run is incremental, returning nil when every group must be synced
+func (e *Engine) planDelta(ctx context.Context, forceFull bool, startedAt time.Time) *deltaPlan {
+	if e.changes == nil {
+		return nil
+	}
+
+	incremental := e.config.Sync.Incremental
+	checkpoint, ok := e.state.Checkpoint()
+	switch {
+	case forceFull:
+		e.logger.Info("Running a full sync: requested")
+		return nil
+	case !ok || checkpoint.LastFullSync.IsZero():
+		e.logger.Info("Running a full sync: no successful full sync is recorded yet")
+		return nil
+	case checkpoint.RetryFull:
+		e.logger.Info("Running a full sync: the last run had errors")
+		return nil
+	case startedAt.Sub(checkpoint.LastFullSync) >= incremental.FullSyncEvery():
+		e.logger.Infof("Running a full sync: the last one started %s, more than %s ago",
+			checkpoint.LastFullSync.Format(time.RFC3339), incremental.FullSyncInterval)
+		return nil
+	}
+
+	since := checkpoint.LastSync.Add(-incremental.LookbackDuration())
+	changes, err := e.changes.Changes(ctx, since)
+	if err != nil {
+		e.logger.Warnf("Running a full sync: failed to read changes from the audit log: %v", err)
+		var scopeErr *gws.ScopeError
+		var privilegeErr *gws.PrivilegeError
+		if errors.As(err, &scopeErr) {
+			e.logger.Warn(scopeErr.Remediation())
+		} else if errors.As(err, &privilegeErr) {
+			e.logger.Warn(privilegeErr.Remediation())
+		}
+		return nil
+	}
+
+	// A suspended or renamed user changes every group they are in, and the audit event does not say which
+	if len(changes.Users) > 0 {
+		e.logger.Infof("Running a full sync: %d users were suspended, restored, renamed or deleted since %s",
+			len(changes.Users), since.Format(time.RFC3339))
+		return nil
+	}
+
+	plan := &deltaPlan{since: since, changed: make(map[string]bool, len(changes.Groups))}
+	for _, group := range changes.Groups {
+		plan.changed[strings.ToLower(group)] = true
+	}
+	e.logger.Infof("Running an incremental sync: %d groups changed since %s", len(changes.Groups), since.Format(time.RFC3339))
+	return plan
+}
+
+// changedGroups returns the groups an incremental run syncs: those changed in the source and
+// those not synced before. Groups are matched by their canonical address when aliases are resolved
+func (e *Engine) changedGroups(groupEmails []string, delta *deltaPlan, result *SyncResult) []string {
+	result.Incremental = true
+
+	changed := make([]string, 0, len(groupEmails))
+	for _, groupEmail := range groupEmails {
+		// The audit log does not say when users join the domain or move between org units, so
+		// groups read from the directory are always synced
+		_, synced := e.state.Group(groupEmail)
+		if isDirectoryGroup(groupEmail) {
+			synced = false
+		}
+		if synced && !delta.changed[strings.ToLower(result.sourceEmail(groupEmail))] {
+			e.logger.Debugf("Skipping group %s: unchanged since %s", groupEmail, delta.since.Format(time.RFC3339))
+			result.GroupsUnchanged++
+			result.explainGroup(groupEmail, e.config.TargetForGroup(groupEmail), "not synced: unchanged since the last successful run (incremental sync)")
+			continue
+		}
+		changed = append(changed, groupEmail)
+	}
+
+	e.logger.Infof("Incremental sync: %d groups to sync, %d unchanged", len(changed), result.GroupsUnchanged)
+	return changed
+}
+
+// recordCheckpoint records a finished run, so the next incremental run reads changes from when
+// this one started. Runs that only planned changes leave it as is
+func (e *Engine) recordCheckpoint(startedAt time.Time, result *SyncResult, err error) {
+	if result == nil || result.ReadOnly || e.dryRun() {
+		return
+	}
+
+	checkpoint, _ := e.state.Checkpoint()
+	if err != nil || len(result.Errors) > 0 {
+		// Unchanged groups that failed would not be retried by an incremental run
+		checkpoint.RetryFull = true
+	} else {
+		checkpoint.LastSync = startedAt
+		checkpoint.RetryFull = false
+		if !result.Incremental {
+			checkpoint.LastFullSync = startedAt
+		}
+	}
+	e.state.SetCheckpoint(checkpoint)
+}
@@ -0,0 +1,236 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+// fakeChangeReader returns fixed changes and records what it was asked for
+type fakeChangeReader struct {
+	changes *gws.Changes
+	err     error
+	since   []time.Time
+}
+
+func (f *fakeChangeReader) Changes(ctx context.Context, since time.Time) (*gws.Changes, error) {
+	f.since = append(f.since, since)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.changes, nil
+}
+
+// newIncrementalTestEngine returns a targeted test engine with incremental sync enabled and a
+// clock that advances an hour per run
+func newIncrementalTestEngine(reader *fakeChangeReader) (*Engine, *mockGWSClient, *time.Time) {
+	engine, gwsClient, _ := newTargetedTestEngine()
+	engine.config.Sync.Incremental.Enabled = true
+	engine.config.Sync.Incremental.FullSyncInterval = "24h"
+	engine.config.Sync.Incremental.Lookback = "10m"
+	engine.SetChangeReader(reader)
+
+	now := time.Date(2024, 6, 30, 9, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+	return engine, gwsClient, &now
+}
+
+func TestSync_Incremental(t *testing.T) {
+	reader := &fakeChangeReader{changes: &gws.Changes{Groups: []string{"eng@example.com"}}}
+	engine, _, now := newIncrementalTestEngine(reader)
+	firstRun := *now
+
+	// The first run is full, since there is no checkpoint to read changes from
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Incremental || result.GroupsProcessed != 2 || len(reader.since) != 0 {
+		t.Fatalf("Expected a full first run, got incremental=%t groups=%d reads=%d", result.Incremental, result.GroupsProcessed, len(reader.since))
+	}
+
+	*now = now.Add(time.Hour)
+	result, err = engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Incremental || result.GroupsProcessed != 1 || result.GroupsUnchanged != 1 {
+		t.Errorf("Expected only eng to be synced, got incremental=%t groups=%d unchanged=%d", result.Incremental, result.GroupsProcessed, result.GroupsUnchanged)
+	}
+	if len(reader.since) != 1 || !reader.since[0].Equal(firstRun.Add(-10*time.Minute)) {
+		t.Errorf("Expected changes to be read from the first run minus the lookback, got %v", reader.since)
+	}
+
+	checkpoint, _ := engine.state.Checkpoint()
+	if !checkpoint.LastSync.Equal(*now) || !checkpoint.LastFullSync.Equal(firstRun) {
+		t.Errorf("Expected the incremental run to advance only LastSync, got %+v", checkpoint)
+	}
+}
+
+func TestSync_IncrementalFallsBackToFull(t *testing.T) {
+	tests := []struct {
+		name    string
+		reader  *fakeChangeReader
+		advance time.Duration
+		full    bool // Run SyncFull instead of Sync
+	}{
+		{
+			name:    "full sync requested",
+			reader:  &fakeChangeReader{changes: &gws.Changes{}},
+			advance: time.Hour,
+			full:    true,
+		},
+		{
+			name:    "full sync interval elapsed",
+			reader:  &fakeChangeReader{changes: &gws.Changes{}},
+			advance: 25 * time.Hour,
+		},
+		{
+			name:    "audit log unreadable",
+			reader:  &fakeChangeReader{err: errors.New("mock reports error")},
+			advance: time.Hour,
+		},
+		{
+			name:    "user suspended",
+			reader:  &fakeChangeReader{changes: &gws.Changes{Users: []string{"bob@example.com"}}},
+			advance: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, now := newIncrementalTestEngine(tt.reader)
+			if _, err := engine.Sync(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			*now = now.Add(tt.advance)
+			run := engine.Sync
+			if tt.full {
+				run = engine.SyncFull
+			}
+			result, err := run(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Incremental || result.GroupsProcessed != 2 {
+				t.Errorf("Expected a full run, got incremental=%t groups=%d", result.Incremental, result.GroupsProcessed)
+			}
+
+			checkpoint, _ := engine.state.Checkpoint()
+			if !checkpoint.LastFullSync.Equal(*now) {
+				t.Errorf("Expected the full run to be recorded, got %+v", checkpoint)
+			}
+		})
+	}
+}
+
+func TestSync_IncrementalRetriesFailedRunInFull(t *testing.T) {
+	reader := &fakeChangeReader{changes: &gws.Changes{}}
+	engine, gwsClient, now := newIncrementalTestEngine(reader)
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// sales fails during an incremental run that syncs nothing else
+	sales := gwsClient.groups["sales@example.com"]
+	delete(gwsClient.groups, "sales@example.com")
+	reader.changes = &gws.Changes{Groups: []string{"sales@example.com"}}
+	*now = now.Add(time.Hour)
+	result, _ := engine.Sync(context.Background())
+	if !result.Incremental || len(result.Errors) == 0 {
+		t.Fatalf("Expected a failed incremental run, got incremental=%t errors=%v", result.Incremental, result.Errors)
+	}
+
+	// The next run is full even though sales has not changed again
+	gwsClient.groups["sales@example.com"] = sales
+	reader.changes = &gws.Changes{}
+	*now = now.Add(time.Hour)
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Incremental || result.GroupsProcessed != 2 {
+		t.Errorf("Expected a full run after a failed one, got incremental=%t groups=%d", result.Incremental, result.GroupsProcessed)
+	}
+}
+
+func TestSync_WithoutChangeReaderIsFull(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.config.Sync.Incremental.Enabled = true
+
+	for i := 0; i < 2; i++ {
+		result, err := engine.Sync(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Incremental || result.GroupsProcessed != 2 {
+			t.Errorf("Run %d: expected a full run, got incremental=%t groups=%d", i+1, result.Incremental, result.GroupsProcessed)
+		}
+	}
+}
+
+func TestChangeReaderFailsOverBetweenKeysAndAdmins(t *testing.T) {
+	dir := t.TempDir()
+	oldKey, newKey := filepath.Join(dir, "old.json"), filepath.Join(dir, "new.json")
+	writeKey(t, oldKey)
+	writeKey(t, newKey)
+
+	gwsConfig := config.GoogleWorkspaceConfig{
+		ServiceAccountKeyPath:     oldKey,
+		NextServiceAccountKeyPath: newKey,
+		SuperAdminEmail:           "admin@example.com",
+		FallbackAdminEmails:       []string{"backup@example.com"},
+	}
+
+	// The old key is revoked and the super admin is suspended
+	var used []string
+	build := func(keyPath, subject string) (GWSClient, error) {
+		reader := &fakeChangeReader{changes: &gws.Changes{Groups: []string{"eng@example.com"}}}
+		switch {
+		case keyPath == oldKey:
+			reader.err = &url.Error{Op: "Post", URL: "https://oauth2.googleapis.com/token", Err: &oauth2.RetrieveError{ErrorCode: "invalid_grant"}}
+		case subject == "admin@example.com":
+			reader.err = &googleapi.Error{Code: http.StatusForbidden, Message: "Not Authorized to access this resource/api"}
+		default:
+			used = append(used, filepath.Base(keyPath)+" as "+subject)
+		}
+		return struct {
+			GWSClient
+			ChangeReader
+		}{ChangeReader: reader}, nil
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client, err := newFailoverGWSClient(gwsConfig, build, logger)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reader, ok := client.(ChangeReader)
+	if !ok {
+		t.Fatal("Expected the failover client to read the audit log")
+	}
+
+	changes, err := reader.Changes(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(changes.Groups) != 1 || changes.Groups[0] != "eng@example.com" {
+		t.Errorf("Expected the changed group, got %v", changes.Groups)
+	}
+	if len(used) != 1 || used[0] != "new.json as backup@example.com" {
+		t.Errorf("Expected the new key and the backup admin to be used, got %v", used)
+	}
+}
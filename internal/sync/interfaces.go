@@ -1,26 +1,45 @@
 package sync
 
 import (
+	"context"
+	"time"
+
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
 )
 
 // GWSClient interface for Google Workspace operations
 type GWSClient interface {
-	GetGroup(email string) (*gws.Group, error)
-	GetGroupMembers(email string) ([]*gws.GroupMember, error)
-	AddMemberToGroup(groupEmail, userEmail string) error
-	RemoveMemberFromGroup(groupEmail, userEmail string) error
-	EnsureGroup(groupEmail, groupName, description string) (*gws.Group, error)
+	GetGroup(ctx context.Context, email string) (*gws.Group, error)
+	GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error)
+	AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error
+	RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error
+	EnsureGroup(ctx context.Context, groupEmail, groupName, description string) (*gws.Group, error)
+}
+
+// SourceClient provides the authoritative membership of the synced groups
+type SourceClient interface {
+	GetGroup(ctx context.Context, email string) (*gws.Group, error)
+	GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error)
+}
+
+// ChangeReader reports which groups and users changed in the source since a time
+type ChangeReader interface {
+	Changes(ctx context.Context, since time.Time) (*gws.Changes, error)
+}
+
+// refresher is implemented by sources that reload their data at the start of each sync
+type refresher interface {
+	Refresh(ctx context.Context) error
 }
 
 // BIClient interface for Beyond Identity operations
 type BIClient interface {
-	FindGroupByDisplayName(name string) (*bi.Group, error)
-	CreateGroup(group *bi.Group) (*bi.Group, error)
-	FindUserByEmail(email string) (*bi.User, error)
-	CreateUser(user *bi.User) (*bi.User, error)
-	UpdateGroupMembers(groupID string, membersToAdd []bi.GroupMember, membersToRemove []bi.GroupMember) error
-	GetUserStatus(userEmail string) (bool, error)
-	GetGroupWithMembers(groupID string) (*bi.Group, error)
+	FindGroupByDisplayName(ctx context.Context, name string) (*bi.Group, error)
+	CreateGroup(ctx context.Context, group *bi.Group) (*bi.Group, error)
+	FindUserByEmail(ctx context.Context, email string) (*bi.User, error)
+	CreateUser(ctx context.Context, user *bi.User) (*bi.User, error)
+	UpdateGroupMembers(ctx context.Context, groupID string, membersToAdd []bi.GroupMember, membersToRemove []bi.GroupMember) error
+	GetUserStatus(ctx context.Context, userEmail string) (bool, error)
+	GetGroupWithMembers(ctx context.Context, groupID string) (*bi.Group, error)
 }
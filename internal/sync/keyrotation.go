@@ -0,0 +1,265 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	gosync "sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+// keyCheckInterval is how often service account key files are checked for changes when
+// the client is used, in case the file system does not report them
+const keyCheckInterval = 30 * time.Second
+
+// rotatingGWSClient rebuilds the Google Workspace client when a service account key
+// file changes on disk and fails over to the next key when the active one is rejected
+type rotatingGWSClient struct {
+	keyPaths []string
+	build    func(keyPath string) (GWSClient, error)
+	logger   *logrus.Logger
+	now      func() time.Time
+
+	mu        gosync.Mutex
+	client    GWSClient
+	active    int // index into keyPaths of the key used by client
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+	watcher   *fsnotify.Watcher
+}
+
+// newRotatingGWSClient builds a client from the first usable key in keyPaths
+func newRotatingGWSClient(keyPaths []string, build func(keyPath string) (GWSClient, error), logger *logrus.Logger) (*rotatingGWSClient, error) {
+	r := &rotatingGWSClient{
+		keyPaths: keyPaths,
+		build:    build,
+		logger:   logger,
+		now:      time.Now,
+		modTimes: make(map[string]time.Time),
+	}
+
+	r.lastCheck = r.now()
+	for _, path := range keyPaths {
+		r.modTimes[path] = modTime(path)
+	}
+
+	if err := r.loadFrom(0); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// loadFrom builds a client from the first key that loads, starting at index start
+func (r *rotatingGWSClient) loadFrom(start int) error {
+	var lastErr error
+	for i := range r.keyPaths {
+		index := (start + i) % len(r.keyPaths)
+		client, err := r.build(r.keyPaths[index])
+		if err != nil {
+			r.logger.Warnf("Failed to load service account key %s: %v", r.keyPaths[index], err)
+			lastErr = err
+			continue
+		}
+
+		r.client = client
+		r.active = index
+		return nil
+	}
+	return lastErr
+}
+
+// refresh reloads the client if any key file changed since the last check, at most once
+// every keyCheckInterval; must hold mu
+func (r *rotatingGWSClient) refresh() {
+	now := r.now()
+	if now.Sub(r.lastCheck) < keyCheckInterval {
+		return
+	}
+	r.reload()
+}
+
+// reload rebuilds the client if any key file changed since the last check; must hold mu
+func (r *rotatingGWSClient) reload() {
+	r.lastCheck = r.now()
+
+	changed := false
+	for _, path := range r.keyPaths {
+		if mt := modTime(path); !mt.Equal(r.modTimes[path]) {
+			r.modTimes[path] = mt
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	r.logger.Info("Service account key files changed on disk, reloading Google Workspace client")
+	if err := r.loadFrom(0); err != nil {
+		r.logger.Errorf("Failed to reload service account key, keeping previous client: %v", err)
+		return
+	}
+	r.logger.Infof("Using service account key %s", r.keyPaths[r.active])
+}
+
+// watch reloads the client as soon as a key file changes. The directories holding the keys
+// are watched rather than the files, since editors and mounted secrets replace the file
+func (r *rotatingGWSClient) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		r.logger.Warnf("Cannot watch service account key files, checking them every %s instead: %v", keyCheckInterval, err)
+		return
+	}
+
+	watched := make(map[string]bool)
+	for _, path := range r.keyPaths {
+		dir := filepath.Dir(path)
+		if watched[dir] {
+			continue
+		}
+		watched[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			r.logger.Warnf("Cannot watch %s for service account key changes, checking it every %s instead: %v", dir, keyCheckInterval, err)
+		}
+	}
+
+	r.mu.Lock()
+	r.watcher = watcher
+	r.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				r.mu.Lock()
+				r.reload()
+				r.mu.Unlock()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				r.logger.Warnf("Error watching service account key files: %v", err)
+			}
+		}
+	}()
+}
+
+// Close stops watching the key files
+func (r *rotatingGWSClient) Close() error {
+	r.mu.Lock()
+	watcher := r.watcher
+	r.watcher = nil
+	r.mu.Unlock()
+
+	if watcher == nil {
+		return nil
+	}
+	return watcher.Close()
+}
+
+// do runs op against the current client, failing over to the next key once if the
+// active key is rejected
+func (r *rotatingGWSClient) do(op func(client GWSClient) error) error {
+	r.mu.Lock()
+	r.refresh()
+	client, active := r.client, r.active
+	r.mu.Unlock()
+
+	err := op(client)
+	if err == nil || len(r.keyPaths) < 2 || !isCredentialError(err) {
+		return err
+	}
+
+	r.mu.Lock()
+	if r.active == active {
+		if loadErr := r.loadFrom(active + 1); loadErr != nil || r.active == active {
+			r.mu.Unlock()
+			return err
+		}
+		r.logger.Warnf("Service account key %s was rejected, failing over to %s", r.keyPaths[active], r.keyPaths[r.active])
+	}
+	client = r.client
+	r.mu.Unlock()
+
+	return op(client)
+}
+
+// GetGroup implements GWSClient
+func (r *rotatingGWSClient) GetGroup(ctx context.Context, email string) (*gws.Group, error) {
+	var group *gws.Group
+	err := r.do(func(client GWSClient) error {
+		var err error
+		group, err = client.GetGroup(ctx, email)
+		return err
+	})
+	return group, err
+}
+
+// GetGroupMembers implements GWSClient
+func (r *rotatingGWSClient) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	var members []*gws.GroupMember
+	err := r.do(func(client GWSClient) error {
+		var err error
+		members, err = client.GetGroupMembers(ctx, email)
+		return err
+	})
+	return members, err
+}
+
+// AddMemberToGroup implements GWSClient
+func (r *rotatingGWSClient) AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error {
+	return r.do(func(client GWSClient) error {
+		return client.AddMemberToGroup(ctx, groupEmail, userEmail)
+	})
+}
+
+// RemoveMemberFromGroup implements GWSClient
+func (r *rotatingGWSClient) RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error {
+	return r.do(func(client GWSClient) error {
+		return client.RemoveMemberFromGroup(ctx, groupEmail, userEmail)
+	})
+}
+
+// EnsureGroup implements GWSClient
+func (r *rotatingGWSClient) EnsureGroup(ctx context.Context, groupEmail, groupName, description string) (*gws.Group, error) {
+	var group *gws.Group
+	err := r.do(func(client GWSClient) error {
+		var err error
+		group, err = client.EnsureGroup(ctx, groupEmail, groupName, description)
+		return err
+	})
+	return group, err
+}
+
+// isCredentialError reports whether err means the service account key itself was rejected
+func isCredentialError(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return true
+	}
+
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code == http.StatusUnauthorized
+	}
+	return false
+}
+
+// modTime returns the modification time of path, or the zero time if it cannot be read
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
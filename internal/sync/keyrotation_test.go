@@ -0,0 +1,173 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// keyedGWSClient records which key it was built from and optionally rejects it
+type keyedGWSClient struct {
+	mockGWSClient
+	keyPath string
+	reject  bool
+}
+
+func (k *keyedGWSClient) GetGroup(ctx context.Context, email string) (*gws.Group, error) {
+	if k.reject {
+		return nil, &url.Error{Op: "Get", URL: "https://oauth2.googleapis.com/token", Err: &oauth2.RetrieveError{ErrorCode: "invalid_grant"}}
+	}
+	return &gws.Group{Email: email, Name: k.keyPath}, nil
+}
+
+func newTestRotatingClient(t *testing.T, keyPaths []string, rejected map[string]bool) (*rotatingGWSClient, *int) {
+	builds := 0
+	build := func(keyPath string) (GWSClient, error) {
+		if _, err := os.Stat(keyPath); err != nil {
+			return nil, err
+		}
+		builds++
+		return &keyedGWSClient{keyPath: keyPath, reject: rejected[keyPath]}, nil
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client, err := newRotatingGWSClient(keyPaths, build, logger)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return client, &builds
+}
+
+func writeKey(t *testing.T, path string) {
+	if err := os.WriteFile(path, []byte(`{"type": "service_account"}`), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+}
+
+func TestRotatingGWSClient_FallsBackWhenCurrentKeyMissing(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current.json")
+	next := filepath.Join(dir, "next.json")
+	writeKey(t, next)
+
+	client, _ := newTestRotatingClient(t, []string{current, next}, nil)
+
+	group, err := client.GetGroup(context.Background(), "eng@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if group.Name != next {
+		t.Errorf("Expected next key to be used, got %s", group.Name)
+	}
+}
+
+func TestRotatingGWSClient_FailsOverWhenKeyRejected(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current.json")
+	next := filepath.Join(dir, "next.json")
+	writeKey(t, current)
+	writeKey(t, next)
+
+	client, _ := newTestRotatingClient(t, []string{current, next}, map[string]bool{current: true})
+
+	group, err := client.GetGroup(context.Background(), "eng@example.com")
+	if err != nil {
+		t.Fatalf("Expected failover to succeed, got error: %v", err)
+	}
+	if group.Name != next {
+		t.Errorf("Expected next key after failover, got %s", group.Name)
+	}
+}
+
+func TestRotatingGWSClient_ReloadsChangedKey(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current.json")
+	writeKey(t, current)
+
+	client, builds := newTestRotatingClient(t, []string{current}, nil)
+
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	// Unchanged file within the interval does not rebuild
+	_, _ = client.GetGroup(context.Background(), "eng@example.com")
+	if *builds != 1 {
+		t.Fatalf("Expected 1 build, got %d", *builds)
+	}
+
+	// Rewrite the key with a new modification time and advance past the check interval
+	writeKey(t, current)
+	later := now.Add(time.Hour)
+	if err := os.Chtimes(current, later, later); err != nil {
+		t.Fatalf("Failed to update key time: %v", err)
+	}
+	now = now.Add(keyCheckInterval + time.Second)
+
+	_, _ = client.GetGroup(context.Background(), "eng@example.com")
+	if *builds != 2 {
+		t.Errorf("Expected client to be rebuilt after key change, got %d builds", *builds)
+	}
+}
+
+func TestRotatingGWSClient_WatchReloadsReplacedKey(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current.json")
+	writeKey(t, current)
+
+	built := make(chan string, 10)
+	build := func(keyPath string) (GWSClient, error) {
+		built <- keyPath
+		return &keyedGWSClient{keyPath: keyPath}, nil
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client, err := newRotatingGWSClient([]string{current}, build, logger)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	<-built
+
+	client.watch()
+	t.Cleanup(func() { _ = client.Close() })
+
+	// Replace the key the way editors and mounted secrets do, without using the client
+	replacement := filepath.Join(dir, "replacement.json")
+	writeKey(t, replacement)
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(replacement, later, later); err != nil {
+		t.Fatalf("Failed to update key time: %v", err)
+	}
+	if err := os.Rename(replacement, current); err != nil {
+		t.Fatalf("Failed to replace key: %v", err)
+	}
+
+	select {
+	case keyPath := <-built:
+		if keyPath != current {
+			t.Errorf("Expected the client to be rebuilt from %s, got %s", current, keyPath)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the client to be rebuilt when the key file was replaced")
+	}
+}
+
+func TestIsCredentialError(t *testing.T) {
+	if !isCredentialError(&url.Error{Op: "Post", URL: "token", Err: &oauth2.RetrieveError{}}) {
+		t.Error("Expected token retrieval error to be a credential error")
+	}
+	if isCredentialError(errors.New("group not found")) {
+		t.Error("Expected plain error not to be a credential error")
+	}
+}
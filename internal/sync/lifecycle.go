@@ -0,0 +1,147 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/azuread"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/csvsource"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/ldap"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+	"google.golang.org/api/googleapi"
+)
+
+// errGroupOrphaned is returned by syncGroup when the source group was found to be deleted
+var errGroupOrphaned = errors.New("source group was deleted")
+
+// groupRenamer is implemented by targets that can rename groups, used to archive orphaned groups
+type groupRenamer interface {
+	RenameGroup(ctx context.Context, groupID, displayName string) error
+}
+
+// ConfigureState loads the state file configured under sync.state_path
+func (e *Engine) ConfigureState() error {
+	store, err := state.Open(e.config.Sync.StatePath)
+	if err != nil {
+		return err
+	}
+	e.state = store
+	if e.config.Sync.StatePath != "" {
+		e.lockPath = e.config.Sync.StatePath + ".lock"
+	}
+	return nil
+}
+
+// skipOrphaned reports whether a group was orphaned under the current sync configuration
+func (e *Engine) skipOrphaned(groupEmail string) bool {
+	group, ok := e.state.Group(groupEmail)
+	if !ok || !group.Orphaned || group.ConfigHash != e.syncConfigHash() {
+		return false
+	}
+
+	e.logger.Warnf("Skipping group %s: deleted from the source on %s; change the sync configuration to retry",
+		groupEmail, group.OrphanedAt.Format(time.RFC3339))
+	return true
+}
+
+// orphanGroup records that a source group was deleted and archives its Beyond Identity group if configured
+func (e *Engine) orphanGroup(ctx context.Context, groupEmail string, biClient BIClient, result *SyncResult) error {
+	e.logger.Warnf("Group %s no longer exists in the source, marking it orphaned", groupEmail)
+
+	previous, _ := e.state.Group(groupEmail)
+	if e.config.Sync.OrphanedGroups.Archive && previous.BIGroupID != "" {
+		if err := e.archiveGroup(ctx, biClient, previous); err != nil {
+			return fmt.Errorf("failed to archive Beyond Identity group %s: %w", previous.BIGroupName, err)
+		}
+	}
+
+	result.OrphanedGroups = append(result.OrphanedGroups, groupEmail)
+	if e.dryRun() {
+		return errGroupOrphaned
+	}
+
+	e.state.UpdateGroup(groupEmail, func(group *state.GroupState) {
+		group.Orphaned = true
+		group.OrphanedAt = time.Now()
+		group.ConfigHash = e.syncConfigHash()
+	})
+	return errGroupOrphaned
+}
+
+// archiveGroup renames the Beyond Identity group of an orphaned source group
+func (e *Engine) archiveGroup(ctx context.Context, biClient BIClient, group state.GroupState) error {
+	suffix := e.config.Sync.OrphanedGroups.ArchiveSuffix
+	if strings.HasSuffix(group.BIGroupName, suffix) {
+		return nil
+	}
+	archivedName := group.BIGroupName + suffix
+
+	renamer, ok := biClient.(groupRenamer)
+	if !ok {
+		e.logger.Warnf("Target %s cannot rename groups, leaving %s unchanged", group.Target, group.BIGroupName)
+		return nil
+	}
+
+	if e.simulated(config.OperationRename) {
+		e.logger.Infof("TEST MODE: Would rename group '%s' to '%s'", group.BIGroupName, archivedName)
+		return nil
+	}
+
+	if err := renamer.RenameGroup(ctx, group.BIGroupID, archivedName); err != nil {
+		return err
+	}
+	e.logger.Infof("Archived group '%s' as '%s'", group.BIGroupName, archivedName)
+	return nil
+}
+
+// recordGroup remembers the Beyond Identity group a source group was synced to
+func (e *Engine) recordGroup(groupEmail, targetName, biGroupID, biGroupName string) {
+	if e.dryRun() || isPlannedID(biGroupID) {
+		return
+	}
+
+	e.state.UpdateGroup(groupEmail, func(group *state.GroupState) {
+		*group = state.GroupState{
+			BIGroupID:   biGroupID,
+			BIGroupName: biGroupName,
+			Target:      targetName,
+			LastSynced:  time.Now(),
+		}
+	})
+}
+
+// syncConfigHash fingerprints the configuration that decides which groups are synced, so
+// orphaned groups are retried once an administrator edits it
+func (e *Engine) syncConfigHash() string {
+	data, _ := json.Marshal(struct {
+		Groups       []string
+		GroupTargets map[string]string
+		OrgUnits     []string `json:",omitempty"`
+		AllUsers     bool     `json:",omitempty"`
+	}{e.config.Sync.Groups, e.config.Sync.GroupTargets, e.config.Sync.OrgUnits, e.config.Sync.AllUsers})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// isGroupNotFound reports whether err means the source group does not exist
+func isGroupNotFound(err error) bool {
+	if errors.Is(err, csvsource.ErrGroupNotFound) || errors.Is(err, azuread.ErrGroupNotFound) ||
+		errors.Is(err, ldap.ErrGroupNotFound) {
+		return true
+	}
+
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code == http.StatusNotFound
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+// UserAccess explains which synced groups give a user access and why
+type UserAccess struct {
+	Email           string             `json:"email"`
+	Targets         []UserTargetStatus `json:"targets"`
+	Groups          []UserGroupAccess  `json:"groups"`
+	EnrollmentGroup *bool              `json:"in_enrollment_group,omitempty"` // Unset when the enrollment group is not managed
+	LastSync        *time.Time         `json:"last_sync,omitempty"`           // Most recent sync of any of the user's groups
+}
+
+// UserTargetStatus is the user's account in one Beyond Identity target
+type UserTargetStatus struct {
+	Target            string `json:"target"`
+	UserID            string `json:"user_id"`
+	Active            bool   `json:"active"`
+	Enrolled          bool   `json:"enrolled"`                     // Active with an active passkey
+	EnrollmentSkipped string `json:"enrollment_skipped,omitempty"` // Why Enrolled could not be determined
+}
+
+// UserGroupAccess relates one synced source group to the user's membership of its Beyond Identity group
+type UserGroupAccess struct {
+	SourceGroup  string     `json:"source_group"`
+	SourceStatus string     `json:"source_status,omitempty"` // Member status in the source group; empty if not a member
+	Target       string     `json:"target"`
+	GroupID      string     `json:"group_id,omitempty"`
+	GroupName    string     `json:"group_name,omitempty"`
+	InSource     bool       `json:"in_source"`   // Active user member of the source group
+	InBIGroup    bool       `json:"in_bi_group"` // Current member of the Beyond Identity group
+	Orphaned     bool       `json:"orphaned,omitempty"`
+	LastSynced   *time.Time `json:"last_synced,omitempty"`
+}
+
+// Found reports whether the user exists in any target or belongs to any synced group
+func (a *UserAccess) Found() bool {
+	if len(a.Targets) > 0 {
+		return true
+	}
+	for _, group := range a.Groups {
+		if group.SourceStatus != "" || group.InBIGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// UserAccess reports, for every synced group, whether the user is a member in the source and in
+// Beyond Identity, along with their enrollment status and when the groups were last synced
+func (e *Engine) UserAccess(ctx context.Context, email string) (*UserAccess, error) {
+	access := &UserAccess{
+		Email:   email,
+		Targets: []UserTargetStatus{},
+		Groups:  []UserGroupAccess{},
+	}
+
+	userIDs := make(map[string]string) // target -> user ID
+	for _, groupEmail := range e.configuredGroups() {
+		targetName := e.config.TargetForGroup(groupEmail)
+		biClient, err := e.clientForTarget(targetName)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, looked := userIDs[targetName]; !looked {
+			status, err := e.userTargetStatus(ctx, biClient, targetName, email)
+			if err != nil {
+				return nil, err
+			}
+			userIDs[targetName] = ""
+			if status != nil {
+				userIDs[targetName] = status.UserID
+				access.Targets = append(access.Targets, *status)
+			}
+		}
+
+		entry := UserGroupAccess{SourceGroup: groupEmail, Target: targetName}
+		if group, ok := e.state.Group(groupEmail); ok {
+			entry.Orphaned = group.Orphaned
+			if !group.LastSynced.IsZero() {
+				lastSynced := group.LastSynced
+				entry.LastSynced = &lastSynced
+			}
+		}
+
+		if !entry.Orphaned {
+			if err := e.sourceMembership(ctx, &entry, email); err != nil {
+				return nil, err
+			}
+
+			groupID, groupName, err := e.managedGroup(ctx, biClient, groupEmail, targetName)
+			if err != nil {
+				return nil, err
+			}
+			entry.GroupID, entry.GroupName = groupID, groupName
+
+			if groupID != "" && userIDs[targetName] != "" {
+				if entry.InBIGroup, err = e.isGroupMember(ctx, biClient, groupID, userIDs[targetName]); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if (entry.InSource || entry.InBIGroup) && entry.LastSynced != nil &&
+			(access.LastSync == nil || entry.LastSynced.After(*access.LastSync)) {
+			access.LastSync = entry.LastSynced
+		}
+		access.Groups = append(access.Groups, entry)
+	}
+
+	if e.gwsClient != nil && e.config.Sync.EnrollmentGroupEmail != "" {
+		members, err := e.gwsClient.GetGroupMembers(ctx, e.config.Sync.EnrollmentGroupEmail)
+		if err != nil && !isGroupNotFound(err) {
+			return nil, fmt.Errorf("failed to get enrollment group members: %w", err)
+		}
+		inGroup := false
+		for _, member := range members {
+			if strings.EqualFold(member.Email, email) {
+				inGroup = true
+				break
+			}
+		}
+		access.EnrollmentGroup = &inGroup
+	}
+
+	return access, nil
+}
+
+// userTargetStatus looks the user up in one target; nil means they do not exist there
+func (e *Engine) userTargetStatus(ctx context.Context, biClient BIClient, targetName, email string) (*UserTargetStatus, error) {
+	user, err := biClient.FindUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for user in target %s: %w", targetName, err)
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	status := &UserTargetStatus{
+		Target: targetName,
+		UserID: user.ID,
+		Active: user.Active,
+	}
+	status.Enrolled, err = biClient.GetUserStatus(ctx, email)
+	switch {
+	case errors.Is(err, bi.ErrNativeAPIUnavailable):
+		status.EnrollmentSkipped = SkippedNativeAPIUnavailable
+	case err != nil:
+		e.logger.Warnf("Failed to get BI enrollment status for %s: %v", email, err)
+	}
+	return status, nil
+}
+
+// sourceMembership fills in the user's membership of the source group
+func (e *Engine) sourceMembership(ctx context.Context, entry *UserGroupAccess, email string) error {
+	members, err := e.sourceMembers(ctx, entry.SourceGroup)
+	if err != nil {
+		if isGroupNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get GWS group members: %w", err)
+	}
+
+	for _, member := range members {
+		if strings.EqualFold(member.Email, email) && member.Type == "USER" {
+			entry.SourceStatus = member.Status
+			entry.InSource = member.Status != "SUSPENDED"
+			return nil
+		}
+	}
+	return nil
+}
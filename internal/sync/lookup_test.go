@@ -0,0 +1,54 @@
+package sync
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUserAccess(t *testing.T) {
+	engine, gwsClient, _ := newTargetedTestEngine()
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Alice is suspended in the source after the sync, so her access is about to be removed
+	gwsClient.members["eng@example.com"][0].Status = "SUSPENDED"
+
+	access, err := engine.UserAccess(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !access.Found() {
+		t.Fatal("Expected alice to be found")
+	}
+	if len(access.Targets) != 1 || !access.Targets[0].Enrolled {
+		t.Errorf("Expected alice to be enrolled in one target, got %+v", access.Targets)
+	}
+	if len(access.Groups) != 2 {
+		t.Fatalf("Expected both synced groups, got %+v", access.Groups)
+	}
+
+	eng := access.Groups[0]
+	if eng.SourceGroup != "eng@example.com" || eng.GroupName != "GWS_Engineering" {
+		t.Errorf("Unexpected group mapping: %+v", eng)
+	}
+	if eng.InSource || eng.SourceStatus != "SUSPENDED" || !eng.InBIGroup {
+		t.Errorf("Expected a suspended source member still in the BI group, got %+v", eng)
+	}
+	if eng.LastSynced == nil || access.LastSync == nil {
+		t.Error("Expected last sync times to be reported")
+	}
+
+	sales := access.Groups[1]
+	if sales.InSource || sales.InBIGroup {
+		t.Errorf("Expected alice to have no sales access, got %+v", sales)
+	}
+
+	access, err = engine.UserAccess(context.Background(), "nobody@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if access.Found() {
+		t.Errorf("Expected an unknown user not to be found, got %+v", access)
+	}
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"net/http"
+	gosync "sync"
+	"time"
+)
+
+// Kinds of operation published to metrics sinks
+const (
+	OpRun   = "run"   // A sync run; Name is the run kind
+	OpGroup = "group" // One group within a run; Name is the group email
+)
+
+// Op is a unit of work measured by the engine
+type Op struct {
+	Kind string
+	Name string
+}
+
+// APICall is an outbound request to Google Workspace, Beyond Identity or another target
+type APICall struct {
+	Host       string
+	Method     string
+	StatusCode int // 0 when the request failed without a response
+	Duration   time.Duration
+	Err        error
+}
+
+// MetricsSink receives the measurements the engine publishes; implementations must be safe for
+// concurrent use and should not block
+type MetricsSink interface {
+	OpStarted(op Op)
+	OpFinished(op Op, duration time.Duration, err error)
+	APICall(call APICall)
+}
+
+// MetricsSinks fans measurements out to every registered sink. It is created before the HTTP
+// client so ObserveHTTP can be passed as its observer, then handed to the engine with SetMetricsSinks
+type MetricsSinks struct {
+	mu    gosync.RWMutex
+	sinks []MetricsSink
+}
+
+// Add registers a sink
+func (m *MetricsSinks) Add(sink MetricsSink) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sinks = append(m.sinks, sink)
+}
+
+func (m *MetricsSinks) each(publish func(MetricsSink)) {
+	if m == nil {
+		return
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, sink := range m.sinks {
+		publish(sink)
+	}
+}
+
+// OpStarted implements MetricsSink
+func (m *MetricsSinks) OpStarted(op Op) {
+	m.each(func(sink MetricsSink) { sink.OpStarted(op) })
+}
+
+// OpFinished implements MetricsSink
+func (m *MetricsSinks) OpFinished(op Op, duration time.Duration, err error) {
+	m.each(func(sink MetricsSink) { sink.OpFinished(op, duration, err) })
+}
+
+// APICall implements MetricsSink
+func (m *MetricsSinks) APICall(call APICall) {
+	m.each(func(sink MetricsSink) { sink.APICall(call) })
+}
+
+// ObserveHTTP publishes an outbound request as an API call; it is an httpclient.CallObserver
+func (m *MetricsSinks) ObserveHTTP(req *http.Request, resp *http.Response, duration time.Duration, err error) {
+	call := APICall{Host: req.URL.Host, Method: req.Method, Duration: duration, Err: err}
+	if resp != nil {
+		call.StatusCode = resp.StatusCode
+	}
+	m.APICall(call)
+}
+
+// SetMetricsSinks sets where the engine publishes runs and groups; without it they are not published
+func (e *Engine) SetMetricsSinks(sinks *MetricsSinks) {
+	e.metrics = sinks
+}
+
+// startOp publishes the start of an operation and returns the function publishing its end
+func (e *Engine) startOp(kind, name string) func(error) {
+	op := Op{Kind: kind, Name: name}
+	started := e.now()
+	e.metrics.OpStarted(op)
+	return func(err error) {
+		e.metrics.OpFinished(op, e.now().Sub(started), err)
+	}
+}
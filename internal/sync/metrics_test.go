@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	gosync "sync"
+	"testing"
+	"time"
+)
+
+// recordingSink records the measurements published to it
+type recordingSink struct {
+	mu       gosync.Mutex
+	started  []Op
+	finished []Op
+	errs     []error
+	calls    []APICall
+}
+
+func (s *recordingSink) OpStarted(op Op) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = append(s.started, op)
+}
+
+func (s *recordingSink) OpFinished(op Op, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished = append(s.finished, op)
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordingSink) APICall(call APICall) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+func TestSync_PublishesOps(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	first, second := &recordingSink{}, &recordingSink{}
+	sinks := &MetricsSinks{}
+	sinks.Add(first)
+	sinks.Add(second)
+	engine.SetMetricsSinks(sinks)
+
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, sink := range []*recordingSink{first, second} {
+		if len(sink.started) != 3 || len(sink.finished) != 3 {
+			t.Fatalf("Expected a run and 2 groups started and finished, got %v and %v", sink.started, sink.finished)
+		}
+		if sink.started[0].Kind != OpRun {
+			t.Errorf("Expected the run to start first, got %v", sink.started[0])
+		}
+		if last := sink.finished[2]; last.Kind != OpRun || sink.errs[2] != nil {
+			t.Errorf("Expected the run to finish last without error, got %v: %v", last, sink.errs[2])
+		}
+		groups := map[string]bool{}
+		for _, op := range sink.finished[:2] {
+			if op.Kind != OpGroup {
+				t.Errorf("Expected a group op, got %v", op)
+			}
+			groups[op.Name] = true
+		}
+		if !groups["eng@example.com"] || !groups["sales@example.com"] {
+			t.Errorf("Expected both groups to be published, got %v", groups)
+		}
+	}
+}
+
+func TestMetricsSinks_ObserveHTTP(t *testing.T) {
+	req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "api.byndid.com", Path: "/v2/users"}}
+
+	tests := []struct {
+		name         string
+		resp         *http.Response
+		err          error
+		expectStatus int
+	}{
+		{"response", &http.Response{StatusCode: http.StatusTooManyRequests}, nil, http.StatusTooManyRequests},
+		{"no response", nil, errors.New("connection refused"), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			sinks := &MetricsSinks{}
+			sinks.Add(sink)
+
+			sinks.ObserveHTTP(req, tt.resp, 250*time.Millisecond, tt.err)
+
+			if len(sink.calls) != 1 {
+				t.Fatalf("Expected 1 API call, got %d", len(sink.calls))
+			}
+			call := sink.calls[0]
+			if call.Host != "api.byndid.com" || call.Method != http.MethodGet || call.StatusCode != tt.expectStatus || call.Err != tt.err {
+				t.Errorf("Unexpected API call %+v", call)
+			}
+		})
+	}
+}
+
+func TestMetricsSinks_Nil(t *testing.T) {
+	var sinks *MetricsSinks
+	sinks.OpStarted(Op{Kind: OpRun})
+	sinks.APICall(APICall{})
+}
@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"sort"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// Members are processed and reported in a fixed order, whatever order the APIs return them in,
+// so two runs against the same data log, record and report the same changes byte for byte
+
+// sortedMembers returns a copy of source group members ordered by email
+func sortedMembers(members []*gws.GroupMember) []*gws.GroupMember {
+	sorted := append([]*gws.GroupMember(nil), members...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Email < sorted[j].Email
+	})
+	return sorted
+}
+
+// sortedBIMembers returns a copy of Beyond Identity group members ordered by identity, then ID
+func sortedBIMembers(members []bi.GroupMember) []bi.GroupMember {
+	sorted := append([]bi.GroupMember(nil), members...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := memberIdentity(sorted[i]), memberIdentity(sorted[j])
+		if a != b {
+			return a < b
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	return sorted
+}
+
+// sortedUserIDs returns the IDs of users keyed by ID, ordered by email, then ID
+func sortedUserIDs(users map[string]string) []string {
+	ids := make([]string, 0, len(users))
+	for id := range users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if users[ids[i]] != users[ids[j]] {
+			return users[ids[i]] < users[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
@@ -0,0 +1,95 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/sirupsen/logrus"
+)
+
+// newOrderTestEngine syncs one group whose members the source returns in the given order
+func newOrderTestEngine(emails []string, testMode bool, output *bytes.Buffer) (*Engine, *mockBIClient) {
+	members := make([]*gws.GroupMember, 0, len(emails))
+	for _, email := range emails {
+		members = append(members, &gws.GroupMember{Email: email, Type: "USER", Status: "ACTIVE"})
+	}
+	gwsClient := &mockGWSClient{
+		groups:  map[string]*gws.Group{"eng@example.com": {Name: "Engineering"}},
+		members: map[string][]*gws.GroupMember{"eng@example.com": members},
+	}
+	biClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+
+	cfg := &config.Config{
+		App:            config.AppConfig{TestMode: testMode},
+		BeyondIdentity: config.BeyondIdentityConfig{GroupPrefix: "GWS_"},
+		Sync:           config.SyncConfig{Groups: []string{"eng@example.com"}},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+	logger.SetOutput(output)
+
+	return NewEngine(gwsClient, biClient, cfg, logger), biClient
+}
+
+func TestSync_DeterministicOrder(t *testing.T) {
+	var output bytes.Buffer
+	engine, biClient := newOrderTestEngine([]string{"dave@example.com", "bob@example.com", "carol@example.com", "alice@example.com"}, false, &output)
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sorted := []string{"alice@example.com", "bob@example.com", "carol@example.com", "dave@example.com"}
+	if len(result.MembershipDiffs) == 0 || !reflect.DeepEqual(result.MembershipDiffs[0].Added, sorted) {
+		t.Errorf("Expected users added in email order, got %+v", result.MembershipDiffs)
+	}
+
+	group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	var added []string
+	for _, member := range group.Members {
+		added = append(added, biClient.users[member.Value].UserName)
+	}
+	if !reflect.DeepEqual(added, sorted) {
+		t.Errorf("Expected members sent in email order, got %v", added)
+	}
+
+	var changed []string
+	for _, change := range engine.state.Changes(time.Time{}, time.Time{}) {
+		if change.Group == "eng@example.com" {
+			changed = append(changed, change.User)
+		}
+	}
+	if !reflect.DeepEqual(changed, sorted) {
+		t.Errorf("Expected membership changes recorded in email order, got %v", changed)
+	}
+}
+
+func TestSync_StableDryRunOutput(t *testing.T) {
+	orders := [][]string{
+		{"alice@example.com", "bob@example.com", "carol@example.com"},
+		{"carol@example.com", "alice@example.com", "bob@example.com"},
+	}
+
+	var outputs []string
+	for _, order := range orders {
+		var output bytes.Buffer
+		engine, _ := newOrderTestEngine(order, true, &output)
+		if _, err := engine.Sync(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		outputs = append(outputs, output.String())
+	}
+
+	if outputs[0] != outputs[1] {
+		t.Errorf("Expected identical dry-run output, got:\n%s\nand:\n%s", outputs[0], outputs[1])
+	}
+}
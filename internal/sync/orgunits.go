@@ -0,0 +1,136 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// orgUnitKeyPrefix marks the group keys standing in for the org units of sync.org_units, e.g.
+// ou:/Engineering, wherever configured group addresses are used
+const orgUnitKeyPrefix = "ou:"
+
+// errOrgUnitsUnsupported is returned when the Google Workspace client cannot list org unit users
+var errOrgUnitsUnsupported = errors.New("listing org unit users needs the Admin SDK (google_workspace.api: admin_sdk)")
+
+// orgUnitLister is implemented by Google Workspace clients that can list the users of an org unit
+type orgUnitLister interface {
+	GetOrgUnitUsers(ctx context.Context, orgUnitPath string) ([]*gws.User, error)
+}
+
+// GetOrgUnitUsers implements orgUnitLister, failing when the current client does not
+func (r *rotatingGWSClient) GetOrgUnitUsers(ctx context.Context, orgUnitPath string) ([]*gws.User, error) {
+	var users []*gws.User
+	err := r.do(func(client GWSClient) error {
+		lister, ok := client.(orgUnitLister)
+		if !ok {
+			return errOrgUnitsUnsupported
+		}
+		var err error
+		users, err = lister.GetOrgUnitUsers(ctx, orgUnitPath)
+		return err
+	})
+	return users, err
+}
+
+// GetOrgUnitUsers implements orgUnitLister, failing when the admins' clients do not
+func (d *delegatingGWSClient) GetOrgUnitUsers(ctx context.Context, orgUnitPath string) ([]*gws.User, error) {
+	var users []*gws.User
+	err := d.do(orgUnitPath, func(client GWSClient) error {
+		lister, ok := client.(orgUnitLister)
+		if !ok {
+			return errOrgUnitsUnsupported
+		}
+		var err error
+		users, err = lister.GetOrgUnitUsers(ctx, orgUnitPath)
+		return err
+	})
+	return users, err
+}
+
+// orgUnitPath returns the org unit a group key stands for, if it is one
+func orgUnitPath(groupEmail string) (string, bool) {
+	if !strings.HasPrefix(groupEmail, orgUnitKeyPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(groupEmail, orgUnitKeyPrefix), true
+}
+
+// orgUnitGroupName names the Beyond Identity group of an org unit, after the group prefix:
+// /Engineering/Backend becomes OU_Engineering_Backend
+func orgUnitGroupName(path string) string {
+	name := strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
+	if name == "" {
+		name = "root"
+	}
+	return "OU_" + name
+}
+
+// configuredGroups returns the configured groups followed by the keys of the org units in
+// sync.org_units and of the sync.all_users group, each synced like a group
+func (e *Engine) configuredGroups() []string {
+	if len(e.config.Sync.OrgUnits) == 0 && !e.config.Sync.AllUsers {
+		return e.config.Sync.Groups
+	}
+
+	groups := make([]string, 0, len(e.config.Sync.Groups)+len(e.config.Sync.OrgUnits)+1)
+	groups = append(groups, e.config.Sync.Groups...)
+	for _, path := range e.config.Sync.OrgUnits {
+		groups = append(groups, orgUnitKeyPrefix+strings.TrimSuffix(path, "/"))
+	}
+	if e.config.Sync.AllUsers {
+		groups = append(groups, allUsersKey)
+	}
+	return groups
+}
+
+// sourceGroup returns a configured group from the membership source, or the group an org unit
+// in sync.org_units or the domain's users with sync.all_users are synced as
+func (e *Engine) sourceGroup(ctx context.Context, groupEmail string) (*gws.Group, error) {
+	if groupEmail == allUsersKey {
+		name := e.config.Sync.AllUsersGroup
+		if name == "" {
+			name = config.DefaultAllUsersGroup
+		}
+		return &gws.Group{
+			Email:       groupEmail,
+			Name:        name,
+			Description: "All users of " + e.config.GoogleWorkspace.Domain,
+		}, nil
+	}
+	path, ok := orgUnitPath(groupEmail)
+	if !ok {
+		return e.source.GetGroup(ctx, groupEmail)
+	}
+	return &gws.Group{
+		Email:       groupEmail,
+		Name:        orgUnitGroupName(path),
+		Description: "Users in org unit " + path,
+	}, nil
+}
+
+// sourceMembers returns the members of a configured group from the membership source; the
+// members of an org unit are its active users, including those of the units below it, and those
+// of the sync.all_users group the domain's active users
+func (e *Engine) sourceMembers(ctx context.Context, groupEmail string) ([]*gws.GroupMember, error) {
+	if groupEmail == allUsersKey {
+		return e.allUsersMembers(ctx)
+	}
+	path, ok := orgUnitPath(groupEmail)
+	if !ok {
+		return e.source.GetGroupMembers(ctx, groupEmail)
+	}
+
+	lister, ok := e.gwsClient.(orgUnitLister)
+	if !ok {
+		return nil, errOrgUnitsUnsupported
+	}
+	users, err := lister.GetOrgUnitUsers(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return activeMembers(users), nil
+}
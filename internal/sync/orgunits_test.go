@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// orgUnitGWSClient lists the users of org units from a map
+type orgUnitGWSClient struct {
+	*mockGWSClient
+	orgUnits map[string][]*gws.User
+}
+
+func (c *orgUnitGWSClient) GetOrgUnitUsers(ctx context.Context, orgUnitPath string) ([]*gws.User, error) {
+	return c.orgUnits[orgUnitPath], nil
+}
+
+func TestSync_OrgUnits(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.config.Sync.Groups = []string{"sales@example.com"}
+	engine.config.Sync.OrgUnits = []string{"/Engineering/Backend/"}
+	engine.gwsClient = &orgUnitGWSClient{
+		mockGWSClient: gwsClient,
+		orgUnits: map[string][]*gws.User{
+			"/Engineering/Backend": {
+				{ID: "1", PrimaryEmail: "dave@example.com"},
+				{ID: "2", PrimaryEmail: "erin@example.com", Suspended: true},
+			},
+		},
+	}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsProcessed != 2 || len(result.Errors) != 0 {
+		t.Fatalf("Expected the group and the org unit to be synced, got %d groups and errors %v", result.GroupsProcessed, result.Errors)
+	}
+
+	group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_OU_Engineering_Backend")
+	if group == nil || len(group.Members) != 1 {
+		t.Fatalf("Expected a group with the active user of the org unit, got %+v", group)
+	}
+	if recorded, ok := engine.state.Group("ou:/Engineering/Backend"); !ok || recorded.BIGroupID != group.ID {
+		t.Errorf("Expected the org unit group to be recorded, got %+v", recorded)
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Sales"); group == nil || len(group.Members) != 1 {
+		t.Errorf("Expected the configured group to be synced as well, got %+v", group)
+	}
+}
+
+func TestSync_OrgUnitsUnsupported(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.config.Sync.Groups = nil
+	engine.config.Sync.OrgUnits = []string{"/Engineering"}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected the org unit to fail without a client that lists its users, got %v", result.Errors)
+	}
+}
@@ -0,0 +1,115 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	gosync "sync"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// pacer spaces user operations evenly across sync.spread_over so a large run stays under
+// tenant rate limits shared with other SCIM clients instead of bursting at the start
+type pacer struct {
+	mu    gosync.Mutex // Groups synced concurrently share the pacer
+	start time.Time
+	step  time.Duration
+	next  int // index of the next operation
+	now   func() time.Time
+	sleep func(context.Context, time.Duration)
+}
+
+// newPacer spreads total operations across window, starting now
+func newPacer(window time.Duration, total int, now func() time.Time, sleep func(context.Context, time.Duration)) *pacer {
+	return &pacer{
+		start: now(),
+		step:  window / time.Duration(total),
+		now:   now,
+		sleep: sleep,
+	}
+}
+
+// wait blocks until the next operation is due or ctx is done; operations running late are not
+// delayed further
+func (p *pacer) wait(ctx context.Context) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	due := p.start.Add(p.step * time.Duration(p.next))
+	p.next++
+	p.mu.Unlock()
+	if delay := due.Sub(p.now()); delay > 0 {
+		p.sleep(ctx, delay)
+	}
+}
+
+// planPacing reads the members of every group up front to size the pacer; the members are
+// kept for the run so each group is only read once
+func (e *Engine) planPacing(ctx context.Context, groupEmails []string, result *SyncResult) {
+	window := e.config.Sync.SpreadOverDuration()
+	if window <= 0 || e.dryRun() {
+		return
+	}
+
+	result.members = make(map[string][]*gws.GroupMember)
+	total := 0
+	for _, groupEmail := range groupEmails {
+		if e.skipOrphaned(groupEmail) {
+			continue
+		}
+
+		// Groups that cannot be read here fail with the same error when they are synced
+		members, err := e.sourceMembers(ctx, result.sourceEmail(groupEmail))
+		if err != nil {
+			continue
+		}
+		result.members[groupEmail] = members
+
+		for _, member := range members {
+			if member.Type == "USER" && member.Status != "SUSPENDED" {
+				total++
+			}
+		}
+	}
+
+	if total == 0 {
+		return
+	}
+
+	result.pacer = newPacer(window, total, e.now, e.sleep)
+	e.logger.Infof("Spreading %d user operations over %s (one every %s)", total, window, result.pacer.step.Round(time.Millisecond))
+}
+
+// groupMembers returns the members read while planning the run, or reads them from the source,
+// ordered by email. With sync.partial_membership, a group whose later pages could not be read is
+// returned with the members that were read and marked partial in the result
+func (e *Engine) groupMembers(ctx context.Context, groupEmail string, result *SyncResult) ([]*gws.GroupMember, error) {
+	members, ok := result.members[groupEmail]
+	if !ok {
+		var err error
+		members, err = e.sourceMembers(ctx, result.sourceEmail(groupEmail))
+		var partial *gws.PartialMembersError
+		if errors.As(err, &partial) && e.config.Sync.PartialMembership {
+			e.logger.Warnf("Syncing %s from the %d members read before the failure; no members will be removed: %v", groupEmail, len(members), err)
+			result.PartialGroups = append(result.PartialGroups, groupEmail)
+			err = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sortedMembers(members), nil
+}
+
+// isPartial reports whether the group was synced from incomplete membership
+func (r *SyncResult) isPartial(groupEmail string) bool {
+	for _, group := range r.PartialGroups {
+		if group == groupEmail {
+			return true
+		}
+	}
+	return false
+}
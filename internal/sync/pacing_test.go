@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock advances only when the engine sleeps
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func TestSync_SpreadOver(t *testing.T) {
+	tests := []struct {
+		name         string
+		spreadOver   string
+		testMode     bool
+		expectSleeps []time.Duration
+	}{
+		{"disabled", "", false, nil},
+		{"spread across the window", "30s", false, []time.Duration{10 * time.Second, 10 * time.Second}},
+		{"not paced in test mode", "30s", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := newTargetedTestEngine()
+			engine.config.Sync.SpreadOver = tt.spreadOver
+			engine.config.App.TestMode = tt.testMode
+			clock := &fakeClock{now: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)}
+			engine.now = clock.Now
+			engine.sleep = clock.Sleep
+
+			result, err := engine.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.GroupsProcessed != 2 {
+				t.Errorf("Expected 2 groups processed, got %d", result.GroupsProcessed)
+			}
+			if len(clock.sleeps) != len(tt.expectSleeps) {
+				t.Fatalf("Expected sleeps %v, got %v", tt.expectSleeps, clock.sleeps)
+			}
+			for i := range tt.expectSleeps {
+				if clock.sleeps[i] != tt.expectSleeps[i] {
+					t.Errorf("Expected sleeps %v, got %v", tt.expectSleeps, clock.sleeps)
+				}
+			}
+		})
+	}
+}
+
+func TestPacer_LateOperations(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)}
+	p := newPacer(time.Minute, 4, clock.Now, clock.Sleep)
+
+	p.wait(context.Background())
+	clock.now = clock.now.Add(40 * time.Second) // The first operation overran two slots
+	p.wait(context.Background())
+	p.wait(context.Background())
+	p.wait(context.Background())
+
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 5*time.Second {
+		t.Errorf("Expected late operations to run immediately and the schedule to resume, got %v", clock.sleeps)
+	}
+
+	var none *pacer
+	none.wait(context.Background())
+}
+
+func TestPacer_CancelInterruptsWait(t *testing.T) {
+	p := newPacer(time.Hour, 2, time.Now, sleepContext)
+	p.wait(context.Background()) // The first operation is due immediately
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	p.wait(ctx)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancelling the run to interrupt the pacing wait, waited %v", elapsed)
+	}
+}
@@ -0,0 +1,79 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// partialGWSClient fails after the first page of eng@example.com
+type partialGWSClient struct {
+	*mockGWSClient
+}
+
+func (c *partialGWSClient) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	members, err := c.mockGWSClient.GetGroupMembers(ctx, email)
+	if err != nil || email != "eng@example.com" {
+		return members, err
+	}
+	return members[:1], &gws.PartialMembersError{Group: email, Pages: 1, Err: errors.New("HTTP 503")}
+}
+
+func TestSync_PartialMembership(t *testing.T) {
+	tests := []struct {
+		name            string
+		allow           bool
+		expectProcessed int
+		expectPartial   bool
+		expectMembers   []string // Members of GWS_Engineering after the run
+	}{
+		{"group fails by default", false, 1, false, []string{"user-dave"}},
+		{"partial members synced without removals", true, 2, true, []string{"user-dave", "user-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, gwsClient, biClient := newTargetedTestEngine()
+			engine.SetSource(&partialGWSClient{mockGWSClient: gwsClient})
+			engine.config.Sync.PartialMembership = tt.allow
+
+			// dave is a member from an earlier run, on a page that can no longer be read
+			biClient.users["user-dave"] = &bi.User{ID: "user-dave", UserName: "dave@example.com"}
+			biClient.groups["group-eng"] = &bi.Group{
+				ID:          "group-eng",
+				DisplayName: "GWS_Engineering",
+				Members:     []bi.GroupMember{{Value: "user-dave", Display: "dave@example.com"}},
+			}
+
+			result, err := engine.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.GroupsProcessed != tt.expectProcessed {
+				t.Errorf("Expected %d groups processed, got %d", tt.expectProcessed, result.GroupsProcessed)
+			}
+			if tt.expectPartial != (len(result.PartialGroups) == 1 && result.PartialGroups[0] == "eng@example.com") {
+				t.Errorf("Expected partial %v, got %v", tt.expectPartial, result.PartialGroups)
+			}
+			if tt.allow && len(result.Errors) != 0 {
+				t.Errorf("Expected no errors, got %v", result.Errors)
+			}
+
+			var members []string
+			for _, member := range biClient.groups["group-eng"].Members {
+				members = append(members, member.Value)
+			}
+			if len(members) != len(tt.expectMembers) {
+				t.Fatalf("Expected members %v, got %v", tt.expectMembers, members)
+			}
+			for i := range members {
+				if members[i] != tt.expectMembers[i] {
+					t.Errorf("Expected members %v, got %v", tt.expectMembers, members)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+)
+
+// plannedUserPrefix marks the IDs given to users a dry run would create, so the memberships
+// they would get can still be planned
+const plannedUserPrefix = "planned-user:"
+
+// plannedGroupID stands in for the ID of a group a dry run would create
+const plannedGroupID = "mock-group-id-for-testing"
+
+// isPlannedID reports whether id stands in for a user or group that was only planned, so
+// changes involving it can only be planned too
+func isPlannedID(id string) bool {
+	return id == plannedGroupID || strings.HasPrefix(id, plannedUserPrefix)
+}
+
+// planUser records a user a dry run would create, once per target, and returns its stand-in ID
+func (r *SyncResult) planUser(targetName, email string) string {
+	for _, user := range r.PlannedUsers {
+		if user.Target == targetName && strings.EqualFold(user.Email, email) {
+			return plannedUserPrefix + user.Email
+		}
+	}
+	r.PlannedUsers = append(r.PlannedUsers, report.PlannedUser{Email: email, Target: targetName})
+	return plannedUserPrefix + email
+}
+
+// planGroup records a group a dry run would create, once per target, with the source group it is
+// synced from when it is one that the state file records
+func (r *SyncResult) planGroup(targetName, name, groupEmail string) {
+	for _, group := range r.PlannedGroups {
+		if group.Target == targetName && group.Name == name {
+			return
+		}
+	}
+	r.PlannedGroups = append(r.PlannedGroups, report.PlannedGroup{Name: name, Target: targetName, GroupEmail: groupEmail})
+}
+
+// DryRunReport returns the changes a dry run found, in the order it found them
+func (r *SyncResult) DryRunReport(now time.Time) *report.DryRun {
+	dryRun := &report.DryRun{
+		GeneratedAt:    now,
+		UsersToCreate:  append([]report.PlannedUser{}, r.PlannedUsers...),
+		GroupsToCreate: append([]report.PlannedGroup{}, r.PlannedGroups...),
+		Memberships:    []report.PlannedMembership{},
+	}
+	for _, diff := range r.MembershipDiffs {
+		dryRun.Memberships = append(dryRun.Memberships, report.PlannedMembership{Group: diff.Group, Target: diff.Target, Add: diff.Added, Remove: diff.Removed})
+	}
+	dryRun.Changes = append(dryRun.Changes, r.Changes...)
+	for _, err := range r.Errors {
+		dryRun.Errors = append(dryRun.Errors, err.Error())
+	}
+	return dryRun
+}
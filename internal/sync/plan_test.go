@@ -0,0 +1,70 @@
+package sync
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+)
+
+func TestDryRunReport(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.App.TestMode = true
+
+	// bob exists and is in Engineering with a member no longer in the source; Sales is new
+	biClient.users["user-1"] = &bi.User{ID: "user-1", UserName: "bob@example.com", Emails: []bi.Email{{Value: "bob@example.com"}}}
+	biClient.groups["group-1"] = &bi.Group{ID: "group-1", DisplayName: "GWS_Engineering", Members: []bi.GroupMember{
+		{Value: "user-1", Display: "bob@example.com"},
+		{Value: "user-9", Display: "mallory@example.com"},
+	}}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	dryRun := result.DryRunReport(now)
+
+	wantUsers := []report.PlannedUser{{Email: "alice@example.com", Target: "default"}, {Email: "carol@example.com", Target: "default"}}
+	if !reflect.DeepEqual(dryRun.UsersToCreate, wantUsers) {
+		t.Errorf("Expected users %v, got %v", wantUsers, dryRun.UsersToCreate)
+	}
+	if want := []report.PlannedGroup{{Name: "GWS_Sales", Target: "default", GroupEmail: "sales@example.com"}}; !reflect.DeepEqual(dryRun.GroupsToCreate, want) {
+		t.Errorf("Expected groups %v, got %v", want, dryRun.GroupsToCreate)
+	}
+
+	memberships := make(map[string]report.PlannedMembership)
+	for _, membership := range dryRun.Memberships {
+		memberships[membership.Group] = membership
+	}
+	if got := memberships["GWS_Engineering"]; !reflect.DeepEqual(got.Add, []string{"alice@example.com"}) || !reflect.DeepEqual(got.Remove, []string{"mallory@example.com"}) {
+		t.Errorf("Unexpected Engineering changes %+v", got)
+	}
+	if got := memberships["GWS_Sales"]; !reflect.DeepEqual(got.Add, []string{"carol@example.com"}) {
+		t.Errorf("Unexpected Sales changes %+v", got)
+	}
+
+	// Nothing was written
+	if len(biClient.users) != 1 || len(biClient.groups["group-1"].Members) != 2 {
+		t.Error("Expected a dry run not to change the target")
+	}
+}
+
+func TestPlanUser(t *testing.T) {
+	result := &SyncResult{}
+
+	first := result.planUser("default", "alice@example.com")
+	second := result.planUser("default", "Alice@example.com")
+	result.planUser("eu", "alice@example.com")
+
+	if first != second {
+		t.Errorf("Expected the same stand-in ID for a user planned twice, got %s and %s", first, second)
+	}
+	if len(result.PlannedUsers) != 2 {
+		t.Errorf("Expected one planned user per target, got %v", result.PlannedUsers)
+	}
+}
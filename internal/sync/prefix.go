@@ -0,0 +1,234 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// Outcomes of migrating one group to a new prefix
+const (
+	PrefixRenamed         = "renamed"
+	PrefixWouldRename     = "would_rename"
+	PrefixAlreadyMigrated = "already_migrated"
+	PrefixNotFound        = "not_found"
+	PrefixFailed          = "failed"
+)
+
+// PrefixMigration is the outcome of renaming the Beyond Identity group of one synced group
+type PrefixMigration struct {
+	GroupEmail string
+	Target     string
+	GroupID    string
+	OldName    string
+	NewName    string
+	Members    int // Members of the group, verified unchanged after the rename
+	Status     string
+	Err        error
+}
+
+// MigratePrefix renames the Beyond Identity groups of the configured groups from one group prefix
+// to another, so changing group_prefix does not orphan them. Each group keeps its ID and members,
+// which are compared before and after the rename, and the state mapping is updated to the new
+// name. With dryRun, in test or read-only mode, or when renames are listed in
+// sync.test_mode_operations, the renames are only reported
+func (e *Engine) MigratePrefix(ctx context.Context, from, to string, dryRun bool) ([]PrefixMigration, error) {
+	if from == to {
+		return nil, errors.New("the old and new prefixes are the same")
+	}
+
+	ctx, finish, err := e.beginRun(ctx, RunKindMigratePrefix, from+" -> "+to)
+	if err != nil {
+		return nil, err
+	}
+
+	groupEmails := append([]string{}, e.configuredGroups()...)
+	sort.Strings(groupEmails)
+
+	dryRun = dryRun || e.simulated(config.OperationRename)
+	migrations := make([]PrefixMigration, 0, len(groupEmails))
+	failed := 0
+	for _, groupEmail := range groupEmails {
+		migration := e.migrateGroupPrefix(ctx, groupEmail, from, to, dryRun)
+		if migration.Status == PrefixFailed {
+			e.logger.Errorf("Failed to migrate group %s: %v", groupEmail, migration.Err)
+			failed++
+		}
+		migrations = append(migrations, migration)
+	}
+
+	if failed > 0 {
+		err = fmt.Errorf("%d of %d groups failed to migrate", failed, len(groupEmails))
+	}
+	finish(nil, err)
+	return migrations, err
+}
+
+// migrateGroupPrefix renames the Beyond Identity group of one synced group
+func (e *Engine) migrateGroupPrefix(ctx context.Context, groupEmail, from, to string, dryRun bool) PrefixMigration {
+	migration := PrefixMigration{GroupEmail: groupEmail, Target: e.config.TargetForGroup(groupEmail)}
+	fail := func(err error) PrefixMigration {
+		migration.Status = PrefixFailed
+		migration.Err = err
+		return migration
+	}
+
+	client, err := e.clientForTarget(migration.Target)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Groups synced since the state file was introduced are found by ID, older ones by name
+	group, err := e.findPrefixedGroup(ctx, client, groupEmail, from)
+	if err != nil {
+		return fail(err)
+	}
+	if group == nil {
+		migration.Status = PrefixNotFound
+		if recorded, ok := e.state.Group(groupEmail); ok && strings.HasPrefix(recorded.BIGroupName, to) {
+			migration.Status = PrefixAlreadyMigrated
+			migration.GroupID = recorded.BIGroupID
+			migration.NewName = recorded.BIGroupName
+		}
+		return migration
+	}
+
+	migration.GroupID = group.ID
+	migration.OldName = group.DisplayName
+	migration.NewName = to + strings.TrimPrefix(group.DisplayName, from)
+	migration.Members = len(group.Members)
+
+	// Renaming onto another group's name would leave two groups the sync cannot tell apart
+	existing, err := client.FindGroupByDisplayName(ctx, migration.NewName)
+	if err != nil {
+		return fail(fmt.Errorf("failed to look up %s: %w", migration.NewName, err))
+	}
+	if existing != nil && existing.ID != group.ID {
+		return fail(fmt.Errorf("a group named %s already exists (ID %s)", migration.NewName, existing.ID))
+	}
+
+	renamer, ok := client.(groupRenamer)
+	if !ok {
+		return fail(fmt.Errorf("target %s cannot rename groups", migration.Target))
+	}
+
+	if dryRun {
+		e.logger.Infof("Would rename group '%s' to '%s'", migration.OldName, migration.NewName)
+		migration.Status = PrefixWouldRename
+		return migration
+	}
+
+	if err := renamer.RenameGroup(ctx, group.ID, migration.NewName); err != nil {
+		return fail(fmt.Errorf("failed to rename %s: %w", migration.OldName, err))
+	}
+	e.logger.Infof("Renamed group '%s' to '%s'", migration.OldName, migration.NewName)
+
+	// Record the new name before verifying, since the rename has already happened
+	e.state.UpdateGroup(groupEmail, func(recorded *state.GroupState) {
+		recorded.BIGroupID = group.ID
+		recorded.BIGroupName = migration.NewName
+		recorded.Target = migration.Target
+	})
+
+	renamed, err := client.GetGroupWithMembers(ctx, group.ID)
+	if err != nil {
+		return fail(fmt.Errorf("failed to verify %s: %w", migration.NewName, err))
+	}
+	if renamed.DisplayName != migration.NewName {
+		return fail(fmt.Errorf("group %s is still named %s after the rename", group.ID, renamed.DisplayName))
+	}
+	if !sameMembers(group.Members, renamed.Members) {
+		return fail(fmt.Errorf("members of %s changed during the rename: %d before, %d after",
+			migration.NewName, len(group.Members), len(renamed.Members)))
+	}
+
+	migration.Status = PrefixRenamed
+	return migration
+}
+
+// findPrefixedGroup returns the group recorded for groupEmail, or found by its source name,
+// with its members if it still carries the old prefix
+func (e *Engine) findPrefixedGroup(ctx context.Context, client BIClient, groupEmail, from string) (*bi.Group, error) {
+	if recorded, ok := e.state.Group(groupEmail); ok && recorded.BIGroupID != "" {
+		if !strings.HasPrefix(recorded.BIGroupName, from) {
+			return nil, nil
+		}
+		group, err := client.GetGroupWithMembers(ctx, recorded.BIGroupID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get group %s: %w", recorded.BIGroupName, err)
+		}
+		if group == nil || !strings.HasPrefix(group.DisplayName, from) {
+			return nil, nil
+		}
+		return group, nil
+	}
+
+	if e.source == nil {
+		return nil, fmt.Errorf("group %s is not recorded in the state file", groupEmail)
+	}
+	sourceGroup, err := e.sourceGroup(ctx, groupEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source group: %w", err)
+	}
+	found, err := client.FindGroupByDisplayName(ctx, from+sourceGroup.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", from+sourceGroup.Name, err)
+	}
+	if found == nil {
+		return nil, nil
+	}
+	return client.GetGroupWithMembers(ctx, found.ID)
+}
+
+// findBIGroup looks up the Beyond Identity group named groupName, which starts with the target's
+// group prefix. Failing that, it looks for the group under each of the target's earlier prefixes
+// in beyond_identity.group_prefixes, so groups created before the prefix changed are still found
+func (e *Engine) findBIGroup(ctx context.Context, biClient BIClient, targetName, groupName string) (*bi.Group, error) {
+	group, err := biClient.FindGroupByDisplayName(ctx, groupName)
+	if err != nil || group != nil {
+		return group, err
+	}
+
+	prefix := e.config.GroupPrefixForTarget(targetName)
+	if !strings.HasPrefix(groupName, prefix) {
+		return nil, nil
+	}
+	for _, legacy := range e.config.LegacyGroupPrefixesForTarget(targetName) {
+		legacyName := legacy + strings.TrimPrefix(groupName, prefix)
+		group, err := biClient.FindGroupByDisplayName(ctx, legacyName)
+		if err != nil {
+			return nil, err
+		}
+		if group != nil {
+			e.logger.Debugf("Found group %s under earlier prefix %s", groupName, legacy)
+			if group.DisplayName == "" {
+				group.DisplayName = legacyName
+			}
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+// sameMembers reports whether two member lists hold the same users
+func sameMembers(before, after []bi.GroupMember) bool {
+	if len(before) != len(after) {
+		return false
+	}
+	ids := make(map[string]bool, len(before))
+	for _, member := range before {
+		ids[member.Value] = true
+	}
+	for _, member := range after {
+		if !ids[member.Value] {
+			return false
+		}
+	}
+	return true
+}
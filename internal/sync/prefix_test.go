@@ -0,0 +1,107 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+func TestMigratePrefix(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A dry run changes nothing
+	migrations, err := engine.MigratePrefix(context.Background(), "GWS_", "Corp_", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Status != PrefixWouldRename {
+		t.Fatalf("Expected two planned renames, got %+v", migrations)
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering"); group == nil {
+		t.Fatal("Expected a dry run not to rename groups")
+	}
+
+	migrations, err = engine.MigratePrefix(context.Background(), "GWS_", "Corp_", false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, m := range migrations {
+		if m.Status != PrefixRenamed {
+			t.Errorf("Expected %s to be renamed, got %s: %v", m.GroupEmail, m.Status, m.Err)
+		}
+	}
+	if migrations[0].OldName != "GWS_Engineering" || migrations[0].NewName != "Corp_Engineering" || migrations[0].Members != 2 {
+		t.Errorf("Unexpected migration: %+v", migrations[0])
+	}
+	if recorded, _ := engine.state.Group("eng@example.com"); recorded.BIGroupName != "Corp_Engineering" {
+		t.Errorf("Expected the state mapping to be updated, got %+v", recorded)
+	}
+
+	// Migrating again is a no-op
+	migrations, _ = engine.MigratePrefix(context.Background(), "GWS_", "Corp_", false)
+	if migrations[0].Status != PrefixAlreadyMigrated {
+		t.Errorf("Expected the group to be reported as migrated, got %+v", migrations[0])
+	}
+
+	// The next sync with the new prefix reuses the renamed groups
+	engine.config.BeyondIdentity.GroupPrefix = "Corp_"
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsCreated != 0 || len(biClient.groups) != 2 {
+		t.Errorf("Expected no groups to be created, got %d of %d", result.GroupsCreated, len(biClient.groups))
+	}
+}
+
+func TestMigratePrefix_ByName(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	biClient.groups["legacy-1"] = &bi.Group{
+		ID:          "legacy-1",
+		DisplayName: "GoogleSCIM_Engineering",
+		Members:     []bi.GroupMember{{Value: "user-1"}},
+	}
+	biClient.groups["taken"] = &bi.Group{ID: "taken", DisplayName: "GWS_Sales"}
+	biClient.groups["legacy-2"] = &bi.Group{ID: "legacy-2", DisplayName: "GoogleSCIM_Sales"}
+
+	migrations, err := engine.MigratePrefix(context.Background(), "GoogleSCIM_", "GWS_", false)
+	if err == nil {
+		t.Fatal("Expected the name conflict to fail the migration")
+	}
+	if migrations[0].Status != PrefixRenamed || biClient.groups["legacy-1"].DisplayName != "GWS_Engineering" {
+		t.Errorf("Expected the unrecorded group to be found by name and renamed, got %+v", migrations[0])
+	}
+	if migrations[1].Status != PrefixFailed || biClient.groups["legacy-2"].DisplayName != "GoogleSCIM_Sales" {
+		t.Errorf("Expected a conflicting group not to be renamed, got %+v", migrations[1])
+	}
+}
+
+func TestSync_LegacyGroupPrefixes(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.BeyondIdentity.GroupPrefixes = []string{"GWS_", "GoogleSCIM_"}
+	biClient.groups["legacy-1"] = &bi.Group{ID: "legacy-1", DisplayName: "GoogleSCIM_Engineering"}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsCreated != 1 {
+		t.Errorf("Expected only Sales to be created, got %d groups", result.GroupsCreated)
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering"); group != nil {
+		t.Error("Expected no new group next to the one with the earlier prefix")
+	}
+	if len(biClient.groups["legacy-1"].Members) != 2 {
+		t.Errorf("Expected the members to be synced to the existing group, got %+v", biClient.groups["legacy-1"])
+	}
+	if recorded, _ := engine.state.Group("eng@example.com"); recorded.BIGroupID != "legacy-1" || recorded.BIGroupName != "GoogleSCIM_Engineering" {
+		t.Errorf("Expected the existing group to be recorded, got %+v", recorded)
+	}
+	if _, ok := biClient.groups["legacy-1"]; !ok || biClient.groups["legacy-1"].DisplayName != "GoogleSCIM_Engineering" {
+		t.Error("Expected the existing group to keep its name")
+	}
+}
@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	gosync "sync"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// userLister is implemented by Google Workspace clients that can list the domain's users, so the
+// profiles of the users a run creates are read in a few pages instead of one request per user
+type userLister interface {
+	GetUsers(ctx context.Context) ([]*gws.User, error)
+}
+
+// GetUsers implements userLister when the current client does
+func (r *rotatingGWSClient) GetUsers(ctx context.Context) ([]*gws.User, error) {
+	var users []*gws.User
+	err := r.do(func(client GWSClient) error {
+		lister, ok := client.(userLister)
+		if !ok {
+			return nil
+		}
+		var err error
+		users, err = lister.GetUsers(ctx)
+		return err
+	})
+	return users, err
+}
+
+// userDirectory caches Google Workspace profiles for a run. The domain's users are listed the
+// first time a profile is needed; addresses the listing does not have, such as aliases, other
+// domains or external members, are read one at a time
+type userDirectory struct {
+	mu     gosync.Mutex
+	listed bool
+	users  map[string]*gws.User // Lower-cased email -> profile, nil when the directory has no such user
+}
+
+// userProfile returns the Google Workspace profile of email, or nil when the directory does not
+// know the address. Runs with a directory share it across groups; without one, the user is read
+// on its own
+func (e *Engine) userProfile(ctx context.Context, email string, result *SyncResult) (*gws.User, error) {
+	reader, ok := e.gwsClient.(userReader)
+	if !ok {
+		return nil, nil
+	}
+	if result == nil || result.directory == nil {
+		return reader.GetUser(ctx, email)
+	}
+
+	dir := result.directory
+	key := strings.ToLower(email)
+	dir.mu.Lock()
+	if !dir.listed {
+		dir.listed = true
+		dir.users = make(map[string]*gws.User)
+		if lister, ok := e.gwsClient.(userLister); ok {
+			users, err := lister.GetUsers(ctx)
+			if err != nil {
+				e.logger.Warnf("Failed to list Google Workspace users, reading profiles one at a time: %v", err)
+			}
+			for _, user := range users {
+				dir.users[strings.ToLower(user.PrimaryEmail)] = user
+			}
+			e.logger.Debugf("Listed %d Google Workspace users for profile lookups", len(users))
+		}
+	}
+
+	user, ok := dir.users[key]
+	dir.mu.Unlock()
+	if ok {
+		return user, nil
+	}
+
+	user, err := reader.GetUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	dir.mu.Lock()
+	dir.users[key] = user
+	dir.mu.Unlock()
+	return user, nil
+}
+
+// setProfileName names user after their directory profile; a profile without a name keeps the
+// display name derived from the email
+func setProfileName(user *bi.User, profile *gws.User) {
+	if profile == nil || (profile.Name.GivenName == "" && profile.Name.FamilyName == "") {
+		return
+	}
+
+	fullName := profile.Name.FullName
+	if fullName == "" {
+		fullName = strings.TrimSpace(profile.Name.GivenName + " " + profile.Name.FamilyName)
+	}
+	user.Name = &bi.Name{
+		GivenName:  profile.Name.GivenName,
+		FamilyName: profile.Name.FamilyName,
+		Formatted:  fullName,
+	}
+	user.DisplayName = fullName
+}
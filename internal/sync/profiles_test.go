@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// listingGWSClient also lists the domain's users, counting the requests made
+type listingGWSClient struct {
+	*directoryGWSClient
+	listErr error
+	lists   int
+	gets    int
+}
+
+func (l *listingGWSClient) GetUsers(ctx context.Context) ([]*gws.User, error) {
+	l.lists++
+	if l.listErr != nil {
+		return nil, l.listErr
+	}
+	var users []*gws.User
+	for _, user := range l.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (l *listingGWSClient) GetUser(ctx context.Context, email string) (*gws.User, error) {
+	l.gets++
+	return l.directoryGWSClient.GetUser(ctx, email)
+}
+
+func newProfileTestEngine(listErr error) (*Engine, *listingGWSClient, *mockBIClient) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	client := &listingGWSClient{
+		directoryGWSClient: &directoryGWSClient{
+			mockGWSClient: gwsClient,
+			users: map[string]*gws.User{
+				"alice@example.com": {PrimaryEmail: "Alice@example.com", Name: gws.UserName{GivenName: "Alice", FamilyName: "Liddell", FullName: "Alice Liddell"}},
+				"carol@example.com": {PrimaryEmail: "carol@example.com", Name: gws.UserName{GivenName: "Carol", FamilyName: "Danvers"}},
+			},
+		},
+		listErr: listErr,
+	}
+	engine.gwsClient = client
+	return engine, client, biClient
+}
+
+func createdUsers(biClient *mockBIClient) map[string]*bi.User {
+	users := make(map[string]*bi.User)
+	for _, user := range biClient.users {
+		users[user.UserName] = user
+	}
+	return users
+}
+
+func TestSync_ProfileNames(t *testing.T) {
+	engine, gwsClient, biClient := newProfileTestEngine(nil)
+
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	users := createdUsers(biClient)
+	alice := users["alice@example.com"]
+	if alice == nil || alice.DisplayName != "Alice Liddell" || alice.Name == nil || alice.Name.GivenName != "Alice" || alice.Name.FamilyName != "Liddell" {
+		t.Errorf("alice = %+v, want named Alice Liddell", alice)
+	}
+	carol := users["carol@example.com"]
+	if carol == nil || carol.DisplayName != "Carol Danvers" || carol.Name == nil || carol.Name.Formatted != "Carol Danvers" {
+		t.Errorf("carol = %+v, want named Carol Danvers from her given and family names", carol)
+	}
+	// bob is not in the directory, so he is named from his email
+	if bob := users["bob@example.com"]; bob == nil || bob.DisplayName != "Bob" || bob.Name != nil {
+		t.Errorf("bob = %+v, want only the derived display name", bob)
+	}
+
+	// One listing for the run; only bob, whom it did not have, is read on his own
+	if gwsClient.lists != 1 || gwsClient.gets != 1 {
+		t.Errorf("listed users %d times and read %d users, want 1 and 1", gwsClient.lists, gwsClient.gets)
+	}
+}
+
+func TestSync_ProfileListingFails(t *testing.T) {
+	engine, gwsClient, biClient := newProfileTestEngine(errors.New("HTTP 503"))
+
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if alice := createdUsers(biClient)["alice@example.com"]; alice == nil || alice.DisplayName != "Alice Liddell" {
+		t.Errorf("alice = %+v, want named Alice Liddell from her own profile", alice)
+	}
+	if gwsClient.lists != 1 || gwsClient.gets != 3 {
+		t.Errorf("listed users %d times and read %d users, want 1 and 3", gwsClient.lists, gwsClient.gets)
+	}
+}
+
+func TestSync_ProfileLookupError(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	engine.gwsClient = &directoryGWSClient{mockGWSClient: gwsClient, userErr: errors.New("directory unavailable")}
+
+	result, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"})
+	if err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none when only the name is missing", result.Errors)
+	}
+	if carol := createdUsers(biClient)["carol@example.com"]; carol == nil || carol.DisplayName != "Carol" {
+		t.Errorf("carol = %+v, want named from her email", carol)
+	}
+}
@@ -0,0 +1,144 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// deferOp queues a write that failed with a transient error so later runs retry it until it
+// succeeds or sync.retry_queue_hours pass; other failures are not queued since repeating them
+// cannot help
+func (e *Engine) deferOp(op state.DeferredOp, err error, result *SyncResult) {
+	hours := e.config.Sync.RetryQueueHours
+	if e.dryRun() || hours <= 0 || classifyError(err) != ErrorClassTransient {
+		return
+	}
+
+	now := e.now()
+	op.ID = newRunID()
+	op.LastError = err.Error()
+	op.EnqueuedAt = now
+	op.ExpiresAt = now.Add(time.Duration(hours) * time.Hour)
+	e.state.Enqueue(op)
+
+	result.DeferredQueued++
+	e.logger.Warnf("Queued %s (target %s) for retry on later runs: %v", op.Kind, op.Target, err)
+}
+
+// retryDeferred replays the queued writes before a run, dropping those that succeed, fail
+// permanently or have expired
+func (e *Engine) retryDeferred(ctx context.Context, result *SyncResult) {
+	if e.dryRun() {
+		return
+	}
+
+	for _, op := range e.state.DeferredOps() {
+		if e.runCancelled(ctx, result) {
+			return
+		}
+
+		if op.Expired(e.now()) {
+			e.state.RemoveDeferred(op.ID)
+			result.DeferredExpired++
+			e.logger.Warnf("Dropped queued %s %s (target %s) after %d attempts: %s",
+				op.Kind, deferredSubject(op), op.Target, op.Attempts, op.LastError)
+			continue
+		}
+
+		if e.deferredSimulated(op) {
+			continue
+		}
+
+		err := e.replayDeferred(ctx, op, result)
+		switch {
+		case err == nil:
+			e.state.RemoveDeferred(op.ID)
+			result.DeferredRetried++
+			e.logger.Infof("Retried queued %s %s (target %s)", op.Kind, deferredSubject(op), op.Target)
+		case classifyError(err) == ErrorClassTransient:
+			op.Attempts++
+			op.LastError = err.Error()
+			e.state.UpdateDeferred(op)
+			e.logger.Warnf("Queued %s %s (target %s) failed again, will retry: %v", op.Kind, deferredSubject(op), op.Target, err)
+		default:
+			e.state.RemoveDeferred(op.ID)
+			e.addError(result, "queued "+op.Kind, deferredSubject(op), err)
+		}
+	}
+}
+
+// deferredSimulated reports whether a queued write includes an operation listed in
+// sync.test_mode_operations; it stays queued until the operation is applied again
+func (e *Engine) deferredSimulated(op state.DeferredOp) bool {
+	switch op.Kind {
+	case state.DeferredCreateUser:
+		return e.simulated(config.OperationCreate)
+	case state.DeferredUpdateGroupMembers:
+		return (len(op.Add) > 0 && e.simulated(config.OperationAdd)) || (len(op.Remove) > 0 && e.simulated(config.OperationRemove))
+	}
+	return false
+}
+
+// replayDeferred performs a queued write once
+func (e *Engine) replayDeferred(ctx context.Context, op state.DeferredOp, result *SyncResult) error {
+	biClient, err := e.clientForTarget(op.Target)
+	if err != nil {
+		return err
+	}
+
+	switch op.Kind {
+	case state.DeferredCreateUser:
+		existingID, err := e.findBIUser(ctx, biClient, op.User)
+		if err != nil || existingID != "" {
+			return err
+		}
+		newUser, err := e.newBIUser(ctx, op.User, result)
+		if err != nil {
+			return err
+		}
+		created, err := biClient.CreateUser(ctx, newUser)
+		if err != nil {
+			return err
+		}
+		e.userCreated(op.Target, op.User, created.ID, result)
+		return nil
+	case state.DeferredUpdateGroupMembers:
+		if err := biClient.UpdateGroupMembers(ctx, op.GroupID, toGroupMembers(op.Add), toGroupMembers(op.Remove)); err != nil {
+			return err
+		}
+		result.MembershipsAdded += len(op.Add)
+		result.MembershipsRemoved += len(op.Remove)
+		return nil
+	}
+	return nil
+}
+
+// deferredSubject names what a queued operation acts on in logs and errors
+func deferredSubject(op state.DeferredOp) string {
+	if op.Kind == state.DeferredCreateUser {
+		return op.User
+	}
+	return op.Group
+}
+
+// memberIDs returns the IDs of Beyond Identity group members
+func memberIDs(members []bi.GroupMember) []string {
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.Value)
+	}
+	return ids
+}
+
+// toGroupMembers builds Beyond Identity group members from user IDs
+func toGroupMembers(ids []string) []bi.GroupMember {
+	members := make([]bi.GroupMember, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, bi.GroupMember{Value: id})
+	}
+	return members
+}
@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+func TestRetryQueue(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.Sync.RetryQueueHours = 24
+	biClient.createUserErr = &bi.HTTPError{StatusCode: 503, Body: "unavailable"}
+
+	result, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.DeferredQueued != 2 || result.QueueDepth != 2 {
+		t.Fatalf("Expected both creates queued, got %d queued and depth %d", result.DeferredQueued, result.QueueDepth)
+	}
+
+	// A second failure keeps the operations queued and counts the attempt
+	result, _ = engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+	if result.QueueDepth != 2 {
+		t.Fatalf("Expected queue depth 2 after another outage, got %d", result.QueueDepth)
+	}
+	if ops := engine.state.DeferredOps(); ops[0].Attempts != 1 {
+		t.Errorf("Expected 1 recorded attempt, got %d", ops[0].Attempts)
+	}
+
+	biClient.createUserErr = nil
+	result, err = engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.DeferredRetried != 2 || result.UsersCreated != 2 || result.QueueDepth != 0 {
+		t.Errorf("Expected the queued creates to be retried once, got %+v", result)
+	}
+	if len(biClient.users) != 2 {
+		t.Errorf("Expected 2 users, got %d", len(biClient.users))
+	}
+}
+
+func TestRetryQueue_NotQueued(t *testing.T) {
+	tests := []struct {
+		name     string
+		hours    int
+		testMode bool
+		err      error
+	}{
+		{name: "permanent error", hours: 24, err: &bi.HTTPError{StatusCode: 400, Body: "invalid userName"}},
+		{name: "disabled", hours: -1, err: &bi.HTTPError{StatusCode: 503, Body: "unavailable"}},
+		{name: "test mode", hours: 24, testMode: true, err: &bi.HTTPError{StatusCode: 503, Body: "unavailable"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, biClient := newTargetedTestEngine()
+			engine.config.Sync.RetryQueueHours = tt.hours
+			engine.config.App.TestMode = tt.testMode
+			biClient.createUserErr = tt.err
+
+			result, _ := engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+			if result.DeferredQueued != 0 || engine.state.QueueDepth() != 0 {
+				t.Errorf("Expected nothing queued, got %d", engine.state.QueueDepth())
+			}
+		})
+	}
+}
+
+func TestRetryQueue_Expired(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.Sync.RetryQueueHours = 24
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	engine.state.Enqueue(state.DeferredOp{
+		ID:        "op-1",
+		Kind:      state.DeferredCreateUser,
+		Target:    "default",
+		User:      "dave@example.com",
+		ExpiresAt: now.Add(-time.Minute),
+	})
+
+	result, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.DeferredExpired != 1 || result.QueueDepth != 0 {
+		t.Errorf("Expected the expired operation to be dropped, got %+v", result)
+	}
+	if user, _ := biClient.FindUserByEmail(context.Background(), "dave@example.com"); user != nil {
+		t.Error("Expected the expired create not to be retried")
+	}
+}
@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// quotaReporter is implemented by targets that report their API rate limit from response headers
+type quotaReporter interface {
+	Quota() (bi.Quota, bool)
+}
+
+// QuotaUsage is the share of a target's API quota used by a run
+type QuotaUsage struct {
+	Target    string
+	Limit     int       // Requests allowed per window
+	Remaining int       // Requests left when the run finished
+	Reset     time.Time // When the window resets; zero if not reported
+	Used      int64     // Requests made during the run
+	Fraction  float64   // Used as a share of the quota available when the run started
+	Exceeded  bool      // Fraction is above beyond_identity.quota_warning_fraction
+}
+
+// quotaReporters returns the targets that report their rate limit, by target name
+func (e *Engine) quotaReporters() map[string]quotaReporter {
+	reporters := make(map[string]quotaReporter)
+	if reporter, ok := e.biClient.(quotaReporter); ok {
+		reporters[config.DefaultTargetName] = reporter
+	}
+	for name, client := range e.targets {
+		if reporter, ok := client.(quotaReporter); ok {
+			reporters[name] = reporter
+		}
+	}
+	return reporters
+}
+
+// startQuotaTracking notes the requests each target has made before the run
+func (e *Engine) startQuotaTracking(result *SyncResult) {
+	result.quotaStart = make(map[string]int64)
+	for name, reporter := range e.quotaReporters() {
+		quota, _ := reporter.Quota()
+		result.quotaStart[name] = quota.Requests
+	}
+}
+
+// recordQuotaUsage records the share of each target's quota used by the run and warns when it is
+// above beyond_identity.quota_warning_fraction. The quota available at the start is taken as what
+// remains plus what the run used, so it is understated if the window reset during the run
+func (e *Engine) recordQuotaUsage(result *SyncResult) {
+	reporters := e.quotaReporters()
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		quota, ok := reporters[name].Quota()
+		if !ok {
+			continue
+		}
+
+		usage := QuotaUsage{
+			Target:    name,
+			Limit:     quota.Limit,
+			Remaining: quota.Remaining,
+			Reset:     quota.Reset,
+			Used:      quota.Requests - result.quotaStart[name],
+		}
+		if available := int64(quota.Remaining) + usage.Used; available > 0 {
+			usage.Fraction = float64(usage.Used) / float64(available)
+		}
+		usage.Exceeded = usage.Fraction > e.config.BeyondIdentity.QuotaWarningFraction
+
+		if usage.Exceeded {
+			e.logger.Warnf("Run used %.0f%% of the available API quota for target %s (%d requests, %d of %d remaining)",
+				usage.Fraction*100, name, usage.Used, usage.Remaining, usage.Limit)
+		}
+		result.QuotaUsage = append(result.QuotaUsage, usage)
+	}
+}
+
+// QuotaExceeded returns the targets whose quota use was above the warning fraction
+func (r *SyncResult) QuotaExceeded() []QuotaUsage {
+	var exceeded []QuotaUsage
+	for _, usage := range r.QuotaUsage {
+		if usage.Exceeded {
+			exceeded = append(exceeded, usage)
+		}
+	}
+	return exceeded
+}
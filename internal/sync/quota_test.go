@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+)
+
+// quotaBIClient reports a scripted quota, one entry per call
+type quotaBIClient struct {
+	*mockBIClient
+	quotas []bi.Quota
+	calls  int
+}
+
+func (c *quotaBIClient) Quota() (bi.Quota, bool) {
+	if len(c.quotas) == 0 {
+		return bi.Quota{}, false
+	}
+	quota := c.quotas[min(c.calls, len(c.quotas)-1)]
+	c.calls++
+	return quota, !quota.UpdatedAt.IsZero()
+}
+
+func TestSync_QuotaUsage(t *testing.T) {
+	reported := bi.Quota{Limit: 1000, UpdatedAt: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name           string
+		quotas         []bi.Quota
+		fraction       float64
+		expectUsage    bool
+		expectFraction float64
+		expectExceeded bool
+	}{
+		{"no rate limit headers", nil, 0.5, false, 0, false},
+		{
+			name:           "below the warning fraction",
+			quotas:         []bi.Quota{{Requests: 10}, withUsage(reported, 30, 870)},
+			fraction:       0.5,
+			expectUsage:    true,
+			expectFraction: 20.0 / 890,
+		},
+		{
+			name:           "above the warning fraction",
+			quotas:         []bi.Quota{{Requests: 10}, withUsage(reported, 310, 100)},
+			fraction:       0.5,
+			expectUsage:    true,
+			expectFraction: 0.75,
+			expectExceeded: true,
+		},
+		{
+			name:           "warning disabled",
+			quotas:         []bi.Quota{{Requests: 10}, withUsage(reported, 310, 0)},
+			fraction:       1,
+			expectUsage:    true,
+			expectFraction: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, biClient := newTargetedTestEngine()
+			engine.biClient = &quotaBIClient{mockBIClient: biClient, quotas: tt.quotas}
+			engine.config.BeyondIdentity.QuotaWarningFraction = tt.fraction
+
+			result, err := engine.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !tt.expectUsage {
+				if len(result.QuotaUsage) != 0 {
+					t.Errorf("Expected no quota usage, got %+v", result.QuotaUsage)
+				}
+				return
+			}
+
+			if len(result.QuotaUsage) != 1 {
+				t.Fatalf("Expected usage for the default target, got %+v", result.QuotaUsage)
+			}
+			usage := result.QuotaUsage[0]
+			if usage.Target != "default" || usage.Limit != 1000 || usage.Used != tt.quotas[1].Requests-10 {
+				t.Errorf("Unexpected usage %+v", usage)
+			}
+			if diff := usage.Fraction - tt.expectFraction; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Expected fraction %.4f, got %.4f", tt.expectFraction, usage.Fraction)
+			}
+			if usage.Exceeded != tt.expectExceeded || (len(result.QuotaExceeded()) == 1) != tt.expectExceeded {
+				t.Errorf("Expected exceeded: %v, got %+v", tt.expectExceeded, usage)
+			}
+		})
+	}
+}
+
+// withUsage returns quota after requests in total, with remaining left in the window
+func withUsage(quota bi.Quota, requests int64, remaining int) bi.Quota {
+	quota.Requests = requests
+	quota.Remaining = remaining
+	return quota
+}
@@ -0,0 +1,36 @@
+package sync
+
+// SetReadOnly switches read-only mode, in which syncs compute and report changes as in
+// test mode but never write; it applies from the next operation, including a run in progress
+func (e *Engine) SetReadOnly(enabled bool) {
+	e.readOnly.Store(enabled)
+}
+
+// ReadOnly reports whether read-only mode is on
+func (e *Engine) ReadOnly() bool {
+	return e.readOnly.Load()
+}
+
+// dryRun reports whether writes are suppressed by test mode, read-only mode or a dry run
+// requested for the run in progress
+func (e *Engine) dryRun() bool {
+	return e.writesSuppressed() || e.runDryRun.Load()
+}
+
+// writesSuppressed reports whether writes are suppressed by test mode or read-only mode.
+// Operations outside runs, such as deprovisioning, check it instead of dryRun, so a dry run in
+// progress does not suppress them
+func (e *Engine) writesSuppressed() bool {
+	return e.config.App.TestMode || e.readOnly.Load()
+}
+
+// simulated reports whether an operation is only logged: in test or read-only mode, or when it
+// is listed in sync.test_mode_operations
+func (e *Engine) simulated(op string) bool {
+	return e.dryRun() || e.config.Sync.SimulatesOperation(op)
+}
+
+// simulatedOutsideRun is simulated for operations outside runs; see writesSuppressed
+func (e *Engine) simulatedOutsideRun(op string) bool {
+	return e.writesSuppressed() || e.config.Sync.SimulatesOperation(op)
+}
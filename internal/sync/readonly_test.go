@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+func TestSync_ReadOnly(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.SetReadOnly(true)
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.ReadOnly || result.MembershipsAdded != 3 {
+		t.Errorf("Expected 3 memberships reported in a read-only run, got %+v", result)
+	}
+	if len(biClient.users) != 0 || len(biClient.groups) != 0 {
+		t.Errorf("Expected nothing written in read-only mode, got %d users and %d groups", len(biClient.users), len(biClient.groups))
+	}
+
+	// Switched off without recreating the engine
+	engine.SetReadOnly(false)
+	result, err = engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ReadOnly || len(biClient.users) != 3 {
+		t.Errorf("Expected users to be written once read-only mode is off, got %d", len(biClient.users))
+	}
+}
+
+func TestSync_TestModeOperations(t *testing.T) {
+	tests := []struct {
+		name              string
+		operations        []string
+		expectUsers       int // Users in Beyond Identity after the run
+		expectAdded       int
+		expectRemoved     int
+		expectSimAdded    int
+		expectSimRemoved  int
+		expectEngMembers  int
+		expectPlannedUser bool
+	}{
+		{"none", nil, 4, 1, 1, 0, 0, 2, false},
+		{"removals simulated", []string{config.OperationRemove}, 4, 1, 0, 0, 1, 3, false},
+		{"creations simulated", []string{config.OperationCreate}, 3, 0, 1, 1, 0, 1, true},
+		{"memberships simulated", []string{config.OperationAdd, config.OperationRemove}, 4, 0, 0, 1, 1, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, gwsClient, biClient := newTargetedTestEngine()
+			if _, err := engine.Sync(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// bob leaves Engineering and dave, who has no Beyond Identity account yet, joins
+			gwsClient.members["eng@example.com"] = []*gws.GroupMember{
+				{Email: "alice@example.com", Type: "USER", Status: "ACTIVE"},
+				{Email: "dave@example.com", Type: "USER", Status: "ACTIVE"},
+			}
+			engine.config.Sync.TestModeOperations = tt.operations
+
+			result, err := engine.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(biClient.users) != tt.expectUsers {
+				t.Errorf("Expected %d users, got %d", tt.expectUsers, len(biClient.users))
+			}
+			applied, simulated := groupDiff(result.MembershipDiffs, "GWS_Engineering"), groupDiff(result.SimulatedDiffs, "GWS_Engineering")
+			if len(applied.Added) != tt.expectAdded || len(applied.Removed) != tt.expectRemoved {
+				t.Errorf("Expected +%d -%d memberships applied, got %+v", tt.expectAdded, tt.expectRemoved, applied)
+			}
+			if len(simulated.Added) != tt.expectSimAdded || len(simulated.Removed) != tt.expectSimRemoved {
+				t.Errorf("Expected +%d -%d memberships simulated, got %+v", tt.expectSimAdded, tt.expectSimRemoved, simulated)
+			}
+			if (len(result.PlannedUsers) == 1) != tt.expectPlannedUser {
+				t.Errorf("Expected planned user %t, got %+v", tt.expectPlannedUser, result.PlannedUsers)
+			}
+
+			group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+			if group == nil || len(group.Members) != tt.expectEngMembers {
+				t.Errorf("Expected Engineering to have %d members, got %+v", tt.expectEngMembers, group)
+			}
+		})
+	}
+}
+
+// groupDiff returns the diff of one group, or an empty one
+func groupDiff(diffs []GroupDiff, group string) GroupDiff {
+	for _, diff := range diffs {
+		if diff.Group == group {
+			return diff
+		}
+	}
+	return GroupDiff{}
+}
@@ -0,0 +1,113 @@
+package sync
+
+import (
+	gosync "sync"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// RecentRun is a finished run kept in memory with its result, so operators can inspect recent
+// runs without reading logs. Unlike the run journal of the state file, it holds the counts and
+// change records of each run, and is lost when the process exits
+type RecentRun struct {
+	ID         string
+	Kind       string // One of the RunKind constants
+	Subject    string // Groups, user, cleanup mode or prefixes the run was for
+	StartedAt  time.Time
+	FinishedAt time.Time
+	DryRun     bool
+	Labels     map[string]string
+	Error      string      // Why the run failed; empty when it succeeded
+	Result     *SyncResult // nil for runs that produce none, such as cleanups, or that failed before syncing
+}
+
+// Duration returns how long the run took
+func (r RecentRun) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// recentRuns keeps the last runs in a ring buffer of sync.recent_runs entries
+type recentRuns struct {
+	mu   gosync.Mutex
+	runs []RecentRun
+	next int // Index the next run overwrites once runs is full
+	size int
+}
+
+// newRecentRuns creates the buffer for size runs; it returns nil, which keeps nothing, when size is
+// negative
+func newRecentRuns(size int) *recentRuns {
+	if size < 0 {
+		return nil
+	}
+	if size == 0 {
+		size = config.DefaultRecentRuns
+	}
+	return &recentRuns{runs: make([]RecentRun, 0, size), size: size}
+}
+
+// add keeps a finished run, dropping the oldest one when the buffer is full
+func (r *recentRuns) add(run RecentRun) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.runs) < r.size {
+		r.runs = append(r.runs, run)
+		return
+	}
+	r.runs[r.next] = run
+	r.next = (r.next + 1) % r.size
+}
+
+// list returns the kept runs, newest first
+func (r *recentRuns) list() []RecentRun {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	runs := make([]RecentRun, 0, len(r.runs))
+	for i := len(r.runs) - 1; i >= 0; i-- {
+		runs = append(runs, r.runs[(r.next+i)%len(r.runs)])
+	}
+	return runs
+}
+
+// RecentRuns returns the last sync.recent_runs runs of this process, newest first
+func (e *Engine) RecentRuns() []RecentRun {
+	return e.recent.list()
+}
+
+// RecentRun returns the kept run with the given ID
+func (e *Engine) RecentRun(id string) (RecentRun, bool) {
+	for _, run := range e.recent.list() {
+		if run.ID == id {
+			return run, true
+		}
+	}
+	return RecentRun{}, false
+}
+
+// retained returns a copy of a run's result without the caches of the run, which can hold every
+// user of a target, so kept runs only hold what they report
+func (r *SyncResult) retained() *SyncResult {
+	if r == nil {
+		return nil
+	}
+	kept := *r
+	kept.pacer = nil
+	kept.members = nil
+	kept.quotaStart = nil
+	kept.sourceEmails = nil
+	kept.flags = nil
+	kept.directory = nil
+	kept.biUsers = nil
+	kept.filter = nil
+	kept.filterCompiled = false
+	kept.trace = nil
+	kept.explaining = ""
+	return &kept
+}
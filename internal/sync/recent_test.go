@@ -0,0 +1,72 @@
+package sync
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRecentRuns_KeepsLastRuns(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.recent = newRecentRuns(2)
+
+	ctx := WithRunOptions(context.Background(), RunOptions{DryRun: true, Labels: map[string]string{"ticket": "OPS-1"}})
+	if _, err := engine.SyncGroups(ctx, []string{"eng@example.com"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := engine.SyncUser(context.Background(), "alice@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	runs := engine.RecentRuns()
+	if len(runs) != 2 {
+		t.Fatalf("Expected the last 2 runs, got %d", len(runs))
+	}
+	if runs[0].Kind != RunKindUser || runs[0].Subject != "alice@example.com" {
+		t.Errorf("Expected the user sync first, got %+v", runs[0])
+	}
+	if runs[1].Kind != RunKindGroups || runs[1].Subject != "sales@example.com" || runs[1].DryRun {
+		t.Errorf("Expected the sales sync second, got %+v", runs[1])
+	}
+
+	sales := runs[1]
+	if sales.Error != "" || sales.Result == nil || sales.Result.GroupsProcessed != 1 || len(sales.Result.Changes) == 0 {
+		t.Errorf("Expected the sales result with its changes, got %+v", sales.Result)
+	}
+	if sales.Result.directory != nil || sales.Result.biUsers != nil {
+		t.Error("Expected the caches of the run not to be kept")
+	}
+	if sales.FinishedAt.Before(sales.StartedAt) || sales.Duration() < 0 {
+		t.Errorf("Expected the run to finish after it started, got %v to %v", sales.StartedAt, sales.FinishedAt)
+	}
+
+	if found, ok := engine.RecentRun(sales.ID); !ok || found.Subject != "sales@example.com" {
+		t.Errorf("Expected to find the sales run by ID, got %+v", found)
+	}
+	if _, ok := engine.RecentRun("unknown"); ok {
+		t.Error("Expected no run for an unknown ID")
+	}
+}
+
+func TestRecentRuns_Ring(t *testing.T) {
+	recent := newRecentRuns(3)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		recent.add(RecentRun{ID: id})
+	}
+
+	var ids []string
+	for _, run := range recent.list() {
+		ids = append(ids, run.ID)
+	}
+	if len(ids) != 3 || ids[0] != "5" || ids[1] != "4" || ids[2] != "3" {
+		t.Errorf("Expected runs 5, 4, 3, got %v", ids)
+	}
+
+	disabled := newRecentRuns(-1)
+	disabled.add(RecentRun{ID: "1"})
+	if runs := disabled.list(); len(runs) != 0 {
+		t.Errorf("Expected nothing kept when disabled, got %+v", runs)
+	}
+}
@@ -0,0 +1,200 @@
+package sync
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// Run kinds recorded in the run journal
+const (
+	RunKindFull       = "full"
+	RunKindGroups     = "groups"
+	RunKindUser       = "user"
+	RunKindEnrollment = "enrollment"
+
+	RunKindMigratePrefix = "migrate_prefix"
+	RunKindCleanup       = "cleanup"
+	RunKindApply         = "apply"
+)
+
+// DefaultLockLease is how long the sync lock is held without renewal before another process may take it over
+const DefaultLockLease = 10 * time.Minute
+
+// Recovery describes what was cleaned up after a previous process crashed
+type Recovery struct {
+	StaleLock   *state.LockInfo // Expired lease that was taken over
+	Interrupted []state.Run     // Runs marked failed with reason "crash"
+	TempFiles   []string        // Partial state files removed
+}
+
+// Empty reports whether there was nothing to recover
+func (r *Recovery) Empty() bool {
+	return r.StaleLock == nil && len(r.Interrupted) == 0 && len(r.TempFiles) == 0
+}
+
+// Recover detects a crash of a previous process at startup: it takes over an expired sync
+// lock, marks runs that never finished as failed and removes partial state files; it does
+// nothing while another process holds the lock
+func (e *Engine) Recover() (*Recovery, error) {
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	lock, recovery, err := e.acquireLock()
+	if errors.Is(err, state.ErrLocked) {
+		e.logger.Infof("Skipping crash recovery: %v", err)
+		return &Recovery{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil {
+		if err := lock.Release(); err != nil {
+			e.logger.Errorf("Failed to release sync lock: %v", err)
+		}
+	}
+	return recovery, nil
+}
+
+// beginRun serializes sync runs within the process and across processes sharing the state
+// file, and records the run in the journal. The run uses the returned context, which is also
+// cancelled after sync.timeout_seconds; the returned function must be called with the run's
+// result, if it has one, when it ends
+func (e *Engine) beginRun(ctx context.Context, kind, subject string) (context.Context, func(*SyncResult, error), error) {
+	e.runMu.Lock()
+
+	if stop := e.EmergencyStopped(); stop != nil {
+		e.runMu.Unlock()
+		return nil, nil, fmt.Errorf("%w: %s", ErrSyncDisabled, stop)
+	}
+
+	lock, _, err := e.acquireLock()
+	if err != nil {
+		e.runMu.Unlock()
+		return nil, nil, fmt.Errorf("failed to acquire sync lock: %w", err)
+	}
+	if lock != nil {
+		lock.KeepAlive(func(err error) {
+			e.logger.Errorf("Failed to renew sync lock: %v", err)
+		})
+	}
+
+	opts := runOptions(ctx)
+	e.runDryRun.Store(opts.DryRun)
+
+	finishOp := e.startOp(OpRun, kind)
+	id := newRunID()
+	startedAt := time.Now()
+	e.state.StartRun(state.Run{
+		ID:        id,
+		Kind:      kind,
+		Subject:   subject,
+		Owner:     state.LockOwner(),
+		StartedAt: startedAt,
+		DryRun:    opts.DryRun,
+		Labels:    opts.Labels,
+	})
+	stopWatchdog := e.startWatchdog(StuckRun{ID: id, Kind: kind, Subject: subject, StartedAt: startedAt})
+	if err := e.state.Save(); err != nil {
+		e.logger.Errorf("Failed to save sync state: %v", err)
+	}
+
+	ctx, cancel := e.runContext(ctx)
+	ctx, stopControl := e.startControl(ctx, ActiveRun{ID: id, Kind: kind, Subject: subject, StartedAt: startedAt})
+	return ctx, func(result *SyncResult, runErr error) {
+		defer e.runMu.Unlock()
+
+		stopControl()
+		cancel()
+		stopWatchdog()
+		finishOp(runErr)
+		e.runDryRun.Store(false)
+		finishedAt := time.Now()
+		e.state.FinishRun(id, finishedAt, runErr)
+		recent := RecentRun{
+			ID:         id,
+			Kind:       kind,
+			Subject:    subject,
+			StartedAt:  startedAt,
+			FinishedAt: finishedAt,
+			DryRun:     opts.DryRun,
+			Labels:     opts.Labels,
+			Result:     result.retained(),
+		}
+		if runErr != nil {
+			recent.Error = runErr.Error()
+		}
+		e.recent.add(recent)
+		if err := e.state.Save(); err != nil {
+			e.logger.Errorf("Failed to save sync state: %v", err)
+		}
+		if lock != nil {
+			if err := lock.Release(); err != nil {
+				e.logger.Errorf("Failed to release sync lock: %v", err)
+			}
+		}
+	}, nil
+}
+
+// acquireLock takes the sync lock, if the state is persisted, and cleans up after any crash:
+// while the lock and runMu are held no run can be in progress, so runs still recorded as
+// running were interrupted
+func (e *Engine) acquireLock() (*state.Lock, *Recovery, error) {
+	recovery := &Recovery{}
+	var lock *state.Lock
+	if e.lockPath != "" {
+		acquired, stale, err := state.AcquireLock(e.lockPath, e.lockLease())
+		if err != nil {
+			return nil, nil, err
+		}
+		lock = acquired
+		if stale != nil {
+			recovery.StaleLock = stale
+			e.logger.Warnf("Took over expired sync lock held by %s (lease ended %s); the previous process likely crashed",
+				stale.Owner, stale.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+
+	recovery.Interrupted = e.state.FailInterruptedRuns(time.Now())
+	for _, run := range recovery.Interrupted {
+		e.logger.Warnf("Marked %s run %s started %s by %s as failed: %s",
+			run.Kind, run.ID, run.StartedAt.Format(time.RFC3339), run.Owner, state.RunErrorCrash)
+	}
+
+	tempFiles, err := e.state.RemoveTempFiles()
+	recovery.TempFiles = tempFiles
+	if len(tempFiles) > 0 {
+		e.logger.Warnf("Removed partial state files left by an interrupted save: %s", strings.Join(tempFiles, ", "))
+	}
+	if err != nil {
+		e.logger.Errorf("Failed to clean up partial state files: %v", err)
+	}
+
+	if len(recovery.Interrupted) > 0 {
+		if err := e.state.Save(); err != nil {
+			e.logger.Errorf("Failed to save sync state: %v", err)
+		}
+	}
+	return lock, recovery, nil
+}
+
+// lockLease returns the configured sync lock lease
+func (e *Engine) lockLease() time.Duration {
+	if e.config.Sync.LockLeaseSeconds > 0 {
+		return time.Duration(e.config.Sync.LockLeaseSeconds) * time.Second
+	}
+	return DefaultLockLease
+}
+
+// newRunID returns a random run identifier
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+func TestRecover_CleansUpAfterCrash(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	dir := t.TempDir()
+	engine.config.Sync.StatePath = filepath.Join(dir, "sync-state.json")
+
+	// Leave behind what a process killed mid-run would: an unfinished run, its expired lease
+	// and the temporary file of an interrupted save
+	crashed, err := state.Open(engine.config.Sync.StatePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	crashed.StartRun(state.Run{ID: "run-1", Kind: RunKindFull, Owner: "crashed:42", StartedAt: time.Now().Add(-time.Hour)})
+	if err := crashed.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lease, _ := json.Marshal(state.LockInfo{Owner: "crashed:42", ExpiresAt: time.Now().Add(-time.Minute)})
+	if err := os.WriteFile(engine.config.Sync.StatePath+".lock", lease, 0600); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".state-1.json"), []byte("{"), 0600); err != nil {
+		t.Fatalf("Failed to write temporary file: %v", err)
+	}
+
+	if err := engine.ConfigureState(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	recovery, err := engine.Recover()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if recovery.StaleLock == nil || recovery.StaleLock.Owner != "crashed:42" {
+		t.Errorf("Expected the expired lease to be taken over, got %+v", recovery.StaleLock)
+	}
+	if len(recovery.Interrupted) != 1 || recovery.Interrupted[0].Error != state.RunErrorCrash {
+		t.Errorf("Expected the unfinished run to be marked crashed, got %+v", recovery.Interrupted)
+	}
+	if len(recovery.TempFiles) != 1 {
+		t.Errorf("Expected the partial state file to be removed, got %v", recovery.TempFiles)
+	}
+	if _, err := os.Stat(engine.config.Sync.StatePath + ".lock"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the lock to be released after recovery, got %v", err)
+	}
+
+	reopened, err := state.Open(engine.config.Sync.StatePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if runs := reopened.Runs(); len(runs) != 1 || runs[0].Status != state.RunStatusFailed || runs[0].Error != state.RunErrorCrash {
+		t.Errorf("Expected the crashed run to be persisted as failed, got %+v", runs)
+	}
+
+	if again, err := engine.Recover(); err != nil || !again.Empty() {
+		t.Errorf("Expected nothing left to recover, got %+v, %v", again, err)
+	}
+}
+
+func TestSync_RefusedWhileLocked(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.config.Sync.StatePath = filepath.Join(t.TempDir(), "sync-state.json")
+	if err := engine.ConfigureState(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	held, _, err := state.AcquireLock(engine.config.Sync.StatePath+".lock", time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := engine.Sync(context.Background()); !errors.Is(err, state.ErrLocked) {
+		t.Errorf("Expected sync to be refused while another process holds the lock, got %v", err)
+	}
+	if recovery, err := engine.Recover(); err != nil || !recovery.Empty() {
+		t.Errorf("Expected recovery to leave a live holder alone, got %+v, %v", recovery, err)
+	}
+
+	if err := held.Release(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := engine.SyncUser(context.Background(), "alice@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	runs := engine.state.Runs()
+	if len(runs) != 2 || runs[0].Kind != RunKindUser || runs[0].Status != state.RunStatusSucceeded || runs[1].Kind != RunKindFull {
+		t.Errorf("Expected both runs to be journaled as succeeded, got %+v", runs)
+	}
+}
@@ -0,0 +1,23 @@
+package sync
+
+import "context"
+
+// RunOptions are settings requested for a single run, e.g. by an API caller
+type RunOptions struct {
+	DryRun bool              // Report changes as in test mode without writing them
+	Labels map[string]string // Free-form labels recorded with the run in the journal, e.g. a ticket
+}
+
+// runOptionsKey is the context key of RunOptions
+type runOptionsKey struct{}
+
+// WithRunOptions returns a context whose runs use opts
+func WithRunOptions(ctx context.Context, opts RunOptions) context.Context {
+	return context.WithValue(ctx, runOptionsKey{}, opts)
+}
+
+// runOptions returns the options requested for runs started with ctx
+func runOptions(ctx context.Context) RunOptions {
+	opts, _ := ctx.Value(runOptionsKey{}).(RunOptions)
+	return opts
+}
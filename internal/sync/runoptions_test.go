@@ -0,0 +1,49 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func TestSync_DryRunOption(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	ctx := WithRunOptions(context.Background(), RunOptions{DryRun: true, Labels: map[string]string{"ticket": "OPS-1"}})
+
+	result, err := engine.SyncGroups(ctx, []string{"eng@example.com"})
+	if err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	if !result.DryRun || len(result.PlannedUsers) != 2 {
+		t.Errorf("Expected a dry run planning alice and bob, got %+v", result)
+	}
+	if len(biClient.users) != 0 || len(biClient.groups) != 0 {
+		t.Errorf("Expected nothing to be written, got %d users and %d groups", len(biClient.users), len(biClient.groups))
+	}
+	runs := engine.state.Runs()
+	if len(runs) != 1 || !runs[0].DryRun || runs[0].Labels["ticket"] != "OPS-1" {
+		t.Errorf("Expected the run to be journaled as a labelled dry run, got %+v", runs)
+	}
+
+	// The next run without the option writes again
+	result, err = engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+	if err != nil {
+		t.Fatalf("SyncGroups() error = %v", err)
+	}
+	if result.DryRun || len(biClient.users) != 2 {
+		t.Errorf("Expected the next run to create alice and bob, got %d users", len(biClient.users))
+	}
+}
+
+func TestDeprovisionIgnoresRunDryRun(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.runDryRun.Store(true)
+
+	if !engine.simulated(config.OperationRemove) {
+		t.Error("Expected operations of the run to be simulated")
+	}
+	if engine.simulatedOutsideRun(config.OperationRemove) {
+		t.Error("Expected operations outside runs not to be simulated by a dry run in progress")
+	}
+}
@@ -0,0 +1,193 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// ShadowDiscrepancy is a membership change that only one of the live engine and the shadow
+// planner made or planned
+type ShadowDiscrepancy struct {
+	Group  string `json:"group"`  // Beyond Identity group name
+	User   string `json:"user"`   // Email, or the member's ID for removals of users without one
+	Change string `json:"change"` // "added" or "removed"
+	Only   string `json:"only"`   // "live" or "shadow"
+}
+
+func (d ShadowDiscrepancy) String() string {
+	return fmt.Sprintf("%s %s %s: only in %s", d.Group, d.Change, d.User, d.Only)
+}
+
+// shadowPlan is the change set the shadow planner computed for one group
+type shadowPlan struct {
+	groupEmail string
+	diff       GroupDiff
+}
+
+// shadowEnabled reports whether runs are also planned by the shadow planner; test mode applies
+// nothing to compare against
+func (e *Engine) shadowEnabled() bool {
+	return !e.dryRun() && e.config.FeatureEnabled(config.FeatureShadowMode)
+}
+
+// planShadow computes every group's membership changes up front, reading the source and the
+// targets but writing nothing. Groups it cannot plan are left out of the comparison
+func (e *Engine) planShadow(ctx context.Context, groupEmails []string, result *SyncResult) []shadowPlan {
+	var plans []shadowPlan
+	for _, groupEmail := range groupEmails {
+		if e.skipOrphaned(groupEmail) {
+			continue
+		}
+		diff, err := e.planShadowGroup(ctx, groupEmail, result)
+		if err != nil {
+			e.logger.Warnf("Shadow planner could not plan group %s: %v", groupEmail, err)
+			continue
+		}
+		plans = append(plans, shadowPlan{groupEmail: groupEmail, diff: diff})
+	}
+	return plans
+}
+
+// planShadowGroup plans the users added to and removed from one group; users missing from the
+// target are planned as added, since the live engine creates them first
+func (e *Engine) planShadowGroup(ctx context.Context, groupEmail string, result *SyncResult) (GroupDiff, error) {
+	targetName := e.config.TargetForGroup(groupEmail)
+	biClient, err := e.clientForTarget(targetName)
+	if err != nil {
+		return GroupDiff{}, err
+	}
+
+	gwsGroup, err := e.sourceGroup(ctx, result.sourceEmail(groupEmail))
+	if err != nil {
+		return GroupDiff{}, fmt.Errorf("failed to get GWS group: %w", err)
+	}
+	members, err := e.groupMembers(ctx, groupEmail, result)
+	if err != nil {
+		return GroupDiff{}, fmt.Errorf("failed to get GWS group members: %w", err)
+	}
+
+	diff := GroupDiff{Group: e.config.GroupPrefixForTarget(targetName) + gwsGroup.Name, Target: targetName}
+	desired := make(map[string]string)
+	for _, member := range members {
+		if member.Type != "USER" || member.Status == "SUSPENDED" {
+			continue
+		}
+		if _, skipped := e.state.SkippedUser(member.Email, e.now()); skipped {
+			continue
+		}
+		if reason, err := e.filteredOut(ctx, member.Email, result); err != nil || reason != "" {
+			if err != nil {
+				return GroupDiff{}, err
+			}
+			continue
+		}
+		userID, err := e.findBIUser(ctx, biClient, member.Email)
+		if err != nil {
+			return GroupDiff{}, err
+		}
+		if userID == "" {
+			diff.Added = append(diff.Added, member.Email)
+			continue
+		}
+		desired[userID] = member.Email
+	}
+
+	biGroup, err := e.findBIGroup(ctx, biClient, targetName, diff.Group)
+	if err != nil {
+		return GroupDiff{}, fmt.Errorf("failed to search for group: %w", err)
+	}
+	current := make(map[string]bool)
+	if biGroup != nil {
+		if biGroup.DisplayName != "" {
+			diff.Group = biGroup.DisplayName
+		}
+		withMembers, err := biClient.GetGroupWithMembers(ctx, biGroup.ID)
+		if err != nil {
+			return GroupDiff{}, fmt.Errorf("failed to get current group members: %w", err)
+		}
+		for _, member := range withMembers.Members {
+			current[member.Value] = true
+			if _, ok := desired[member.Value]; !ok && !result.isPartial(groupEmail) {
+				diff.Removed = append(diff.Removed, memberIdentity(member))
+			}
+		}
+	}
+	for userID, email := range desired {
+		if !current[userID] {
+			diff.Added = append(diff.Added, email)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	return diff, nil
+}
+
+// compareShadow reports where the live run's membership diffs differ from the shadow plans.
+// Groups and users the live run failed on are left out, since it made no changes to them
+func (e *Engine) compareShadow(plans []shadowPlan, result *SyncResult) {
+	if result.Aborted {
+		e.logger.Warn("Skipping the shadow comparison: the run was aborted")
+		return
+	}
+
+	live := make(map[string]GroupDiff)
+	for _, diff := range result.MembershipDiffs {
+		live[diff.Group] = diff
+	}
+	failed := make(map[string]bool)
+	for _, err := range result.Errors {
+		var syncErr *SyncError
+		if errors.As(err, &syncErr) {
+			failed[syncErr.Kind+":"+syncErr.Subject] = true
+		}
+	}
+
+	for _, plan := range plans {
+		if failed["group:"+plan.groupEmail] {
+			continue
+		}
+		actual := live[plan.diff.Group]
+		group := plan.diff.Group
+		result.ShadowDiscrepancies = append(result.ShadowDiscrepancies,
+			shadowDiscrepancies(group, "added", actual.Added, plan.diff.Added, failed)...)
+		result.ShadowDiscrepancies = append(result.ShadowDiscrepancies,
+			shadowDiscrepancies(group, "removed", actual.Removed, plan.diff.Removed, failed)...)
+	}
+
+	for _, discrepancy := range result.ShadowDiscrepancies {
+		e.logger.Warnf("Shadow discrepancy: %s", discrepancy)
+	}
+	if len(result.ShadowDiscrepancies) == 0 {
+		e.logger.Infof("Shadow planner agreed with the live run on %d groups", len(plans))
+	}
+}
+
+// shadowDiscrepancies returns the users in only one of the sorted live and shadow lists
+func shadowDiscrepancies(group, change string, live, shadow []string, failed map[string]bool) []ShadowDiscrepancy {
+	inLive := make(map[string]bool, len(live))
+	for _, user := range live {
+		inLive[user] = true
+	}
+	inShadow := make(map[string]bool, len(shadow))
+	for _, user := range shadow {
+		inShadow[user] = true
+	}
+
+	var discrepancies []ShadowDiscrepancy
+	for _, user := range live {
+		if !inShadow[user] {
+			discrepancies = append(discrepancies, ShadowDiscrepancy{Group: group, User: user, Change: change, Only: "live"})
+		}
+	}
+	for _, user := range shadow {
+		if !inLive[user] && !failed["user:"+user] {
+			discrepancies = append(discrepancies, ShadowDiscrepancy{Group: group, User: user, Change: change, Only: "shadow"})
+		}
+	}
+	return discrepancies
+}
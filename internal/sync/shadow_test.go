@@ -0,0 +1,81 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+func TestShadowMode(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.Features = map[string]bool{config.FeatureShadowMode: true}
+
+	// bob already exists and the group has a member no longer in the source
+	biClient.users["user-1"] = &bi.User{ID: "user-1", UserName: "bob@example.com", Emails: []bi.Email{{Value: "bob@example.com"}}}
+	biClient.groups["group-1"] = &bi.Group{ID: "group-1", DisplayName: "GWS_Engineering", Members: []bi.GroupMember{{Value: "user-9", Display: "mallory@example.com"}}}
+
+	result, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.ShadowDiscrepancies) != 0 {
+		t.Errorf("Expected the shadow planner to agree with the live run, got %v", result.ShadowDiscrepancies)
+	}
+	want := GroupDiff{Group: "GWS_Engineering", Target: config.DefaultTargetName, Added: []string{"alice@example.com", "bob@example.com"}, Removed: []string{"mallory@example.com"}}
+	if !reflect.DeepEqual(result.MembershipDiffs[0], want) {
+		t.Errorf("Expected the live run to apply its changes, got %+v", result.MembershipDiffs[0])
+	}
+}
+
+func TestShadowMode_Disabled(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.Features = map[string]bool{config.FeatureShadowMode: true}
+	engine.config.App.TestMode = true
+	if engine.shadowEnabled() {
+		t.Error("Expected no shadow planning in test mode")
+	}
+
+	engine.config.App.TestMode = false
+	engine.config.Features = nil
+	if _, err := engine.SyncGroups(context.Background(), []string{"eng@example.com"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(biClient.users) != 2 {
+		t.Errorf("Expected the live run to be unaffected, got %d users", len(biClient.users))
+	}
+}
+
+func TestCompareShadow(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+
+	result := &SyncResult{
+		MembershipDiffs: []GroupDiff{
+			{Group: "GWS_Engineering", Added: []string{"alice@example.com"}, Removed: []string{"eve@example.com"}},
+		},
+		Errors: []error{
+			&SyncError{Kind: "user", Subject: "carol@example.com", Err: errors.New("HTTP 400")},
+			&SyncError{Kind: "group", Subject: "sales@example.com", Err: errors.New("HTTP 503")},
+		},
+	}
+	plans := []shadowPlan{
+		{groupEmail: "eng@example.com", diff: GroupDiff{
+			Group: "GWS_Engineering",
+			Added: []string{"alice@example.com", "bob@example.com", "carol@example.com"},
+		}},
+		{groupEmail: "sales@example.com", diff: GroupDiff{Group: "GWS_Sales", Added: []string{"dave@example.com"}}},
+	}
+
+	engine.compareShadow(plans, result)
+
+	want := []ShadowDiscrepancy{
+		{Group: "GWS_Engineering", User: "bob@example.com", Change: "added", Only: "shadow"},
+		{Group: "GWS_Engineering", User: "eve@example.com", Change: "removed", Only: "live"},
+	}
+	if !reflect.DeepEqual(result.ShadowDiscrepancies, want) {
+		t.Errorf("Expected %v, got %v", want, result.ShadowDiscrepancies)
+	}
+}
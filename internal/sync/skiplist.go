@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// skipUser reports whether the user is on the skip list, counting them as skipped if so
+func (e *Engine) skipUser(email string, result *SyncResult) bool {
+	skipped, ok := e.state.SkippedUser(email, time.Now())
+	if !ok {
+		return false
+	}
+	e.logger.Debugf("Skipping %s (on skip list: %s)", email, skipped.Reason)
+	result.UsersSkipped++
+	result.explain(email, "", report.ExplainStageFilter, "skipped: on the skip list (%s)", skipped.Reason)
+	return true
+}
+
+// autoSkipReason prefixes the reason of skip-list entries added by the engine
+const autoSkipReason = "permanent failure: "
+
+// skipPermanentFailure puts a user whose provisioning failed permanently on the skip list for
+// sync.auto_skip_days, so later runs do not retry them
+func (e *Engine) skipPermanentFailure(email string, err error) {
+	days := e.config.Sync.AutoSkipDays
+	if days <= 0 || e.dryRun() || classifyError(err) != ErrorClassPermanent {
+		return
+	}
+
+	if _, err := e.SkipUser(email, autoSkipReason+rootCause(err).Error(), "scim-sync", time.Duration(days)*24*time.Hour); err != nil {
+		e.logger.Errorf("Failed to add %s to the skip list: %v", email, err)
+	}
+}
+
+// SkipUser puts a user on the skip list so syncs stop trying to provision them; a zero ttl
+// keeps the entry until it is removed
+func (e *Engine) SkipUser(email, reason, addedBy string, ttl time.Duration) (state.SkippedUser, error) {
+	entry := state.SkippedUser{
+		Email:   strings.ToLower(strings.TrimSpace(email)),
+		Reason:  reason,
+		AddedBy: addedBy,
+		AddedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		entry.ExpiresAt = entry.AddedAt.Add(ttl)
+	}
+
+	e.state.SkipUser(entry)
+	if err := e.state.Save(); err != nil {
+		return state.SkippedUser{}, fmt.Errorf("failed to save sync state: %w", err)
+	}
+	e.logger.Infof("Added %s to the skip list: %s", entry.Email, reason)
+	return entry, nil
+}
+
+// UnskipUser removes a user from the skip list, reporting whether they were on it
+func (e *Engine) UnskipUser(email string) (bool, error) {
+	if !e.state.UnskipUser(email) {
+		return false, nil
+	}
+	if err := e.state.Save(); err != nil {
+		return true, fmt.Errorf("failed to save sync state: %w", err)
+	}
+	e.logger.Infof("Removed %s from the skip list", email)
+	return true, nil
+}
+
+// SkippedUsers returns the skip-list entries currently in effect
+func (e *Engine) SkippedUsers() []state.SkippedUser {
+	return e.state.SkippedUsers(time.Now())
+}
@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+func TestSync_SkipsSkippedUsers(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+
+	if _, err := engine.SkipUser("Bob@example.com", "invalid email", "admin", 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	engine.state.SkipUser(state.SkippedUser{
+		Email:     "carol@example.com",
+		Reason:    "expired",
+		AddedAt:   time.Now().Add(-2 * time.Hour),
+		ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	if skipped := engine.SkippedUsers(); len(skipped) != 1 || skipped[0].Email != "bob@example.com" {
+		t.Fatalf("Unexpected skip list: %+v", skipped)
+	}
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.UsersSkipped != 1 {
+		t.Errorf("Expected 1 user skipped, got %d", result.UsersSkipped)
+	}
+	if user, _ := biClient.FindUserByEmail(context.Background(), "bob@example.com"); user != nil {
+		t.Error("Expected bob not to be provisioned")
+	}
+
+	provisioned, err := engine.ProvisionUser(context.Background(), "bob@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if provisioned.UsersSkipped != 1 || len(provisioned.Groups) != 0 {
+		t.Errorf("Expected targeted provisioning to skip bob, got %+v", provisioned)
+	}
+
+	removed, err := engine.UnskipUser("bob@example.com")
+	if err != nil || !removed {
+		t.Fatalf("Expected bob to be removed from the skip list, got %v, %v", removed, err)
+	}
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user, _ := biClient.FindUserByEmail(context.Background(), "bob@example.com"); user == nil {
+		t.Error("Expected bob to be provisioned once removed from the skip list")
+	}
+}
+
+func TestSync_SkipsPermanentFailures(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+	engine.config.Sync.AutoSkipDays = 7
+	biClient.createUserErr = fmt.Errorf("failed: %w", &bi.SCIMError{Status: "400", Detail: "invalid userName"})
+
+	result, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected carol's creation to fail, got %v", result.Errors)
+	}
+	if got, want := result.ErrorSummary()[0].String(), "1 user failed with: SCIM API error (status 400): invalid userName (permanent)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	skipped := engine.SkippedUsers()
+	if len(skipped) != 1 || skipped[0].Email != "carol@example.com" || skipped[0].ExpiresAt.IsZero() {
+		t.Fatalf("Expected carol to be skipped for a week, got %+v", skipped)
+	}
+
+	// Transient failures are retried on the next run rather than skipped
+	engine.state.UnskipUser("carol@example.com")
+	biClient.createUserErr = &bi.HTTPError{StatusCode: 503, Body: "Service Unavailable"}
+	result, err = engine.SyncGroups(context.Background(), []string{"sales@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.UsersSkipped != 0 || len(engine.SkippedUsers()) != 0 {
+		t.Errorf("Expected a transient failure not to be skipped, got %+v", engine.SkippedUsers())
+	}
+}
@@ -0,0 +1,141 @@
+package sync
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/azuread"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/csvsource"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/ldap"
+	"github.com/sirupsen/logrus"
+)
+
+// ConfigureSource replaces the Google Workspace membership source when another source type is
+// configured; sources read over HTTP send their requests through httpClient
+func (e *Engine) ConfigureSource(httpClient *http.Client) error {
+	source, err := NewSourceClient(e.config, e.gwsClient, httpClient)
+	if err != nil {
+		return err
+	}
+	e.SetSource(source)
+	return nil
+}
+
+// NewSourceClient returns where the configured source reads group membership from; the
+// Google Workspace client itself unless another source type is configured
+func NewSourceClient(cfg *config.Config, gwsClient GWSClient, httpClient *http.Client) (SourceClient, error) {
+	switch cfg.Source.Type {
+	case "", config.SourceTypeGoogleWorkspace:
+		return gwsClient, nil
+	case config.SourceTypeCSV:
+		return csvsource.NewSource(cfg.Source.CSV, cfg.Network.MaxResponseBytes), nil
+	case config.SourceTypeAzureAD:
+		return azuread.NewSource(cfg.Source.AzureAD, httpClient), nil
+	case config.SourceTypeLDAP:
+		return ldap.NewSource(cfg.Source.LDAP), nil
+	default:
+		return nil, fmt.Errorf("unsupported source type: %s", cfg.Source.Type)
+	}
+}
+
+// NewGWSClient creates the Google Workspace client for the group API selected in configuration.
+// The client reloads its service account key when the key files change on disk, fails
+// over to the next key when the current one is rejected, and fails over between the
+// configured admin subjects when one is suspended or loses privileges.
+func NewGWSClient(cfg *config.Config, httpClient *http.Client, logger *logrus.Logger) (GWSClient, error) {
+	return NewGWSClientWithCallMetrics(cfg, httpClient, NewGWSCallMetrics(cfg, logger), logger)
+}
+
+// NewGWSClientWithCallMetrics creates the Google Workspace client as NewGWSClient does, timing its
+// Admin SDK requests with calls so callers can report them
+func NewGWSClientWithCallMetrics(cfg *config.Config, httpClient *http.Client, calls *gws.CallMetrics, logger *logrus.Logger) (GWSClient, error) {
+	gwsCfg := cfg.GoogleWorkspace
+
+	var build func(keyPath, subject string) (GWSClient, error)
+	switch gwsCfg.API {
+	case "", config.GWSAPIAdminSDK:
+		build = func(keyPath, subject string) (GWSClient, error) {
+			client, err := gws.NewClientWithHTTPClient(keyPath, gwsCfg.Domain, subject, httpClient)
+			if err != nil {
+				return nil, err
+			}
+			client.SetPageRetryAttempts(gwsCfg.PageRetryAttempts)
+			client.SetCallMetrics(calls)
+			return client, nil
+		}
+	case config.GWSAPICloudIdentity:
+		build = func(keyPath, subject string) (GWSClient, error) {
+			client, err := gws.NewCloudIdentityClient(keyPath, subject, gwsCfg.CustomerID, httpClient)
+			if err != nil {
+				return nil, err
+			}
+			client.SetPageRetryAttempts(gwsCfg.PageRetryAttempts)
+			return client, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Google Workspace API: %s", gwsCfg.API)
+	}
+
+	return newFailoverGWSClient(gwsCfg, build, logger)
+}
+
+// newFailoverGWSClient wraps the clients build creates for each service account key and admin
+// subject so they reload changed keys, fail over to the next key when one is rejected and fail
+// over between the configured admins
+func newFailoverGWSClient(gwsCfg config.GoogleWorkspaceConfig, build func(keyPath, subject string) (GWSClient, error), logger *logrus.Logger) (GWSClient, error) {
+	newSubjectClient := func(subject string) (GWSClient, error) {
+		client, err := newRotatingGWSClient(gwsCfg.ServiceAccountKeyPaths(), func(keyPath string) (GWSClient, error) {
+			return build(keyPath, subject)
+		}, logger)
+		if err != nil {
+			return nil, err
+		}
+		client.watch()
+		return client, nil
+	}
+
+	// A single admin needs no failover wrapper
+	if len(gwsCfg.FallbackAdminEmails) == 0 && len(gwsCfg.DomainAdmins) == 0 {
+		return newSubjectClient(gwsCfg.SuperAdminEmail)
+	}
+
+	return newDelegatingGWSClient(gwsCfg.AdminSubjects, newSubjectClient, logger), nil
+}
+
+// NewGWSCallMetrics times Admin SDK requests by method and logs those slower than
+// google_workspace.slow_call_threshold with their group and page, so directory latency can be
+// told apart from slow targets
+func NewGWSCallMetrics(cfg *config.Config, logger *logrus.Logger) *gws.CallMetrics {
+	return gws.NewCallMetrics(cfg.GoogleWorkspace.SlowCallDuration(), func(call gws.SlowCall) {
+		subject := ""
+		if call.Group != "" {
+			subject = " for group " + call.Group
+		}
+		if call.Page > 0 {
+			subject += fmt.Sprintf(" (page %d)", call.Page)
+		}
+		if call.Err != nil {
+			logger.Warnf("Slow Google Workspace call: %s%s took %s and failed: %v", call.Method, subject, call.Duration.Round(time.Millisecond), call.Err)
+			return
+		}
+		logger.Warnf("Slow Google Workspace call: %s%s took %s", call.Method, subject, call.Duration.Round(time.Millisecond))
+	})
+}
+
+// LogGWSCalls logs how many Admin SDK requests of each method were made and how long they took
+func LogGWSCalls(logger *logrus.Logger, calls *gws.CallMetrics) {
+	stats := calls.Stats()
+	methods := make([]string, 0, len(stats))
+	for method := range stats {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		call := stats[method]
+		logger.Infof("Google Workspace %s: %d calls (%d failed), average %s, slowest %s", method, call.Calls, call.Errors, call.AverageDuration.Round(time.Millisecond), call.MaxDuration.Round(time.Millisecond))
+	}
+}
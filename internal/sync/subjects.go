@@ -0,0 +1,159 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	gosync "sync"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/googleapi"
+)
+
+// delegatingGWSClient impersonates the admin configured for each group's domain and
+// fails over to the next admin when one is suspended or loses privileges
+type delegatingGWSClient struct {
+	subjectsFor func(groupEmail string) []string
+	newClient   func(subject string) (GWSClient, error)
+	logger      *logrus.Logger
+
+	mu      gosync.Mutex
+	clients map[string]GWSClient
+	failed  map[string]bool // subjects that were rejected; tried last until they succeed again
+}
+
+// newDelegatingGWSClient creates a client that builds one underlying client per admin subject on demand
+func newDelegatingGWSClient(subjectsFor func(groupEmail string) []string, newClient func(subject string) (GWSClient, error), logger *logrus.Logger) *delegatingGWSClient {
+	return &delegatingGWSClient{
+		subjectsFor: subjectsFor,
+		newClient:   newClient,
+		logger:      logger,
+		clients:     make(map[string]GWSClient),
+		failed:      make(map[string]bool),
+	}
+}
+
+// orderedSubjects returns the subjects for a group with previously rejected ones moved to the end
+func (d *delegatingGWSClient) orderedSubjects(groupEmail string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var healthy, rejected []string
+	for _, subject := range d.subjectsFor(groupEmail) {
+		if d.failed[subject] {
+			rejected = append(rejected, subject)
+		} else {
+			healthy = append(healthy, subject)
+		}
+	}
+	return append(healthy, rejected...)
+}
+
+// clientFor returns the client impersonating subject, building it on first use
+func (d *delegatingGWSClient) clientFor(subject string) (GWSClient, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if client, ok := d.clients[subject]; ok {
+		return client, nil
+	}
+
+	client, err := d.newClient(subject)
+	if err != nil {
+		return nil, err
+	}
+	d.clients[subject] = client
+	return client, nil
+}
+
+// setFailed records whether subject was rejected on its last use
+func (d *delegatingGWSClient) setFailed(subject string, failed bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failed[subject] = failed
+}
+
+// do runs op as each admin subject for the group in turn until one is not rejected
+func (d *delegatingGWSClient) do(groupEmail string, op func(client GWSClient) error) error {
+	var lastErr error
+
+	for _, subject := range d.orderedSubjects(groupEmail) {
+		client, err := d.clientFor(subject)
+		if err != nil {
+			return err
+		}
+
+		err = op(client)
+		if err == nil || !isSubjectError(err) {
+			d.setFailed(subject, false)
+			return err
+		}
+
+		d.logger.Warnf("Admin %s cannot be impersonated for %s, trying next admin: %v", subject, groupEmail, err)
+		d.setFailed(subject, true)
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+// GetGroup implements GWSClient
+func (d *delegatingGWSClient) GetGroup(ctx context.Context, email string) (*gws.Group, error) {
+	var group *gws.Group
+	err := d.do(email, func(client GWSClient) error {
+		var err error
+		group, err = client.GetGroup(ctx, email)
+		return err
+	})
+	return group, err
+}
+
+// GetGroupMembers implements GWSClient
+func (d *delegatingGWSClient) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	var members []*gws.GroupMember
+	err := d.do(email, func(client GWSClient) error {
+		var err error
+		members, err = client.GetGroupMembers(ctx, email)
+		return err
+	})
+	return members, err
+}
+
+// AddMemberToGroup implements GWSClient
+func (d *delegatingGWSClient) AddMemberToGroup(ctx context.Context, groupEmail, userEmail string) error {
+	return d.do(groupEmail, func(client GWSClient) error {
+		return client.AddMemberToGroup(ctx, groupEmail, userEmail)
+	})
+}
+
+// RemoveMemberFromGroup implements GWSClient
+func (d *delegatingGWSClient) RemoveMemberFromGroup(ctx context.Context, groupEmail, userEmail string) error {
+	return d.do(groupEmail, func(client GWSClient) error {
+		return client.RemoveMemberFromGroup(ctx, groupEmail, userEmail)
+	})
+}
+
+// EnsureGroup implements GWSClient
+func (d *delegatingGWSClient) EnsureGroup(ctx context.Context, groupEmail, groupName, description string) (*gws.Group, error) {
+	var group *gws.Group
+	err := d.do(groupEmail, func(client GWSClient) error {
+		var err error
+		group, err = client.EnsureGroup(ctx, groupEmail, groupName, description)
+		return err
+	})
+	return group, err
+}
+
+// isSubjectError reports whether err means the impersonated admin is suspended or lacks privileges
+func isSubjectError(err error) bool {
+	if isCredentialError(err) {
+		return true
+	}
+
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code == http.StatusForbidden
+	}
+	return false
+}
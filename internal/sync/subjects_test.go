@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/googleapi"
+)
+
+// subjectGWSClient answers as a given admin, failing when that admin is marked suspended
+type subjectGWSClient struct {
+	mockGWSClient
+	subject   string
+	suspended bool
+	calls     *int
+}
+
+func (s *subjectGWSClient) GetGroup(ctx context.Context, email string) (*gws.Group, error) {
+	*s.calls++
+	if s.suspended {
+		return nil, &googleapi.Error{Code: http.StatusForbidden, Message: "Not Authorized to access this resource/api"}
+	}
+	return &gws.Group{Email: email, Name: s.subject}, nil
+}
+
+func TestDelegatingGWSClient(t *testing.T) {
+	gwsConfig := config.GoogleWorkspaceConfig{
+		SuperAdminEmail:     "admin@example.com",
+		FallbackAdminEmails: []string{"backup@example.com"},
+		DomainAdmins:        map[string][]string{"subsidiary.com": {"admin@subsidiary.com"}},
+	}
+	suspended := map[string]bool{"admin@example.com": true}
+	calls := map[string]*int{}
+
+	newClient := func(subject string) (GWSClient, error) {
+		calls[subject] = new(int)
+		return &subjectGWSClient{subject: subject, suspended: suspended[subject], calls: calls[subject]}, nil
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client := newDelegatingGWSClient(gwsConfig.AdminSubjects, newClient, logger)
+
+	group, err := client.GetGroup(context.Background(), "eng@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if group.Name != "backup@example.com" {
+		t.Errorf("Expected failover to backup admin, got %s", group.Name)
+	}
+
+	// The suspended admin is tried last on later calls
+	if _, err := client.GetGroup(context.Background(), "eng@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *calls["admin@example.com"] != 1 {
+		t.Errorf("Expected suspended admin to be skipped after failing, got %d calls", *calls["admin@example.com"])
+	}
+
+	group, err = client.GetGroup(context.Background(), "eng@subsidiary.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if group.Name != "admin@subsidiary.com" {
+		t.Errorf("Expected domain admin for subsidiary.com, got %s", group.Name)
+	}
+}
+
+func TestDelegatingGWSClient_AllAdminsRejected(t *testing.T) {
+	newClient := func(subject string) (GWSClient, error) {
+		return &subjectGWSClient{subject: subject, suspended: true, calls: new(int)}, nil
+	}
+	subjects := func(string) []string { return []string{"a@example.com", "b@example.com"} }
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	if _, err := newDelegatingGWSClient(subjects, newClient, logger).GetGroup(context.Background(), "eng@example.com"); err == nil {
+		t.Error("Expected error when every admin is rejected")
+	}
+}
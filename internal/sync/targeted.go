@@ -0,0 +1,254 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// configuredGroup returns the group as spelled under sync.groups, matching case-insensitively
+func (e *Engine) configuredGroup(groupEmail string) (string, bool) {
+	for _, group := range e.configuredGroups() {
+		if strings.EqualFold(group, groupEmail) {
+			return group, true
+		}
+	}
+	return "", false
+}
+
+// UserProvisionResult describes a single-user provisioning run
+type UserProvisionResult struct {
+	*SyncResult
+	Groups []string `json:"groups"` // Synced source groups the user is a member of
+}
+
+// SyncUser brings a single user's memberships in the synced groups in line with the source,
+// creating the user in Beyond Identity if they belong to any of them; other members are untouched
+func (e *Engine) SyncUser(ctx context.Context, email string) (*SyncResult, error) {
+	provisioned, err := e.syncUser(ctx, email, true)
+	if provisioned == nil {
+		return nil, err
+	}
+	return provisioned.SyncResult, err
+}
+
+// ProvisionUser creates the user in Beyond Identity and adds them to the groups they belong to
+// among the synced groups, without removing any memberships
+func (e *Engine) ProvisionUser(ctx context.Context, email string) (*UserProvisionResult, error) {
+	return e.syncUser(ctx, email, false)
+}
+
+// syncUser adds the user to their synced groups and, if removeStale is set, removes them
+// from the synced groups they are no longer a member of
+func (e *Engine) syncUser(ctx context.Context, email string, removeStale bool) (provisioned *UserProvisionResult, err error) {
+	ctx, finish, err := e.beginRun(ctx, RunKindUser, email)
+	if err != nil {
+		return nil, err
+	}
+	result := &SyncResult{}
+	defer func() { finish(result, err) }()
+
+	provisioned = &UserProvisionResult{SyncResult: result, Groups: []string{}}
+
+	e.logger.Infof("Starting targeted sync for user %s", email)
+
+	if r, ok := e.source.(refresher); ok {
+		if err := r.Refresh(ctx); err != nil {
+			return nil, fmt.Errorf("failed to refresh membership source: %w", err)
+		}
+	}
+
+	for _, groupEmail := range e.configuredGroups() {
+		if e.nextGroup(ctx, result) {
+			break
+		}
+		if e.skipOrphaned(groupEmail) {
+			result.GroupsSkipped++
+			continue
+		}
+
+		if err := e.syncUserInGroup(ctx, email, groupEmail, removeStale, provisioned); err != nil {
+			if result.Aborted {
+				break
+			}
+			if errors.Is(err, errGroupOrphaned) {
+				continue
+			}
+
+			e.logger.Errorf("Failed to sync user %s in group %s: %v", email, groupEmail, err)
+			e.addError(result, "group", groupEmail, err)
+			continue
+		}
+
+		result.GroupsProcessed++
+	}
+	result.QueueDepth = e.state.QueueDepth()
+
+	if err := e.state.Save(); err != nil {
+		e.logger.Errorf("Failed to save sync state: %v", err)
+	}
+
+	e.logger.Infof("Targeted sync for %s completed. Users created: %d, Memberships added: %d, Memberships removed: %d, Errors: %d",
+		email, result.UsersCreated, result.MembershipsAdded, result.MembershipsRemoved, len(result.Errors))
+
+	if result.Aborted {
+		return provisioned, fmt.Errorf("%w: %s", ErrSyncAborted, result.AbortReason)
+	}
+	return provisioned, nil
+}
+
+// syncUserInGroup adds the user to the Beyond Identity group if they are an active member of
+// the source group, and removes them otherwise when removeStale is set
+func (e *Engine) syncUserInGroup(ctx context.Context, email, groupEmail string, removeStale bool, provisioned *UserProvisionResult) error {
+	result := provisioned.SyncResult
+
+	targetName := e.config.TargetForGroup(groupEmail)
+	biClient, err := e.clientForTarget(targetName)
+	if err != nil {
+		return err
+	}
+
+	gwsGroup, err := e.sourceGroup(ctx, groupEmail)
+	if err != nil {
+		if isGroupNotFound(err) {
+			return e.orphanGroup(ctx, groupEmail, biClient, result)
+		}
+		return fmt.Errorf("failed to get GWS group: %w", err)
+	}
+
+	gwsMembers, err := e.sourceMembers(ctx, groupEmail)
+	if err != nil {
+		return fmt.Errorf("failed to get GWS group members: %w", err)
+	}
+
+	inSource := false
+	for _, member := range gwsMembers {
+		if strings.EqualFold(member.Email, email) && member.Type == "USER" && member.Status != "SUSPENDED" {
+			inSource = true
+			break
+		}
+	}
+
+	// Users sync.filters keep out are synced as if they were not in the group
+	if inSource {
+		filtered, err := e.filterUser(ctx, email, targetName, result)
+		if err != nil {
+			return err
+		}
+		inSource = !filtered
+	}
+
+	biGroupName := e.config.GroupPrefixForTarget(targetName) + gwsGroup.Name
+	if inSource {
+		if e.skipUser(email, result) {
+			return nil
+		}
+		provisioned.Groups = append(provisioned.Groups, groupEmail)
+		return e.addUserToGroup(ctx, biClient, email, groupEmail, targetName, biGroupName, gwsGroup.Description, result)
+	}
+	if !removeStale {
+		return nil
+	}
+	return e.removeUserFromGroup(ctx, biClient, email, groupEmail, targetName, biGroupName, result)
+}
+
+// addUserToGroup ensures the user and group exist in Beyond Identity and the user is a member
+func (e *Engine) addUserToGroup(ctx context.Context, biClient BIClient, email, groupEmail, targetName, biGroupName, description string, result *SyncResult) error {
+	biGroup, err := e.ensureBIGroup(ctx, biClient, targetName, groupEmail, biGroupName, description, result)
+	if err != nil {
+		return fmt.Errorf("failed to ensure BI group: %w", err)
+	}
+	if biGroup.DisplayName != "" {
+		biGroupName = biGroup.DisplayName
+	}
+
+	userID, err := e.ensureBIUser(ctx, biClient, targetName, email, result)
+	if err != nil {
+		e.addError(result, "user", email, err)
+		e.skipPermanentFailure(email, err)
+		return nil
+	}
+	if userID == "" {
+		return nil // Left out by sync.conflict_policy
+	}
+
+	if e.simulated(config.OperationAdd) || isPlannedID(biGroup.ID) || isPlannedID(userID) {
+		e.logger.Infof("TEST MODE: Would add %s to group %s", email, biGroupName)
+		if !e.dryRun() {
+			result.recordSimulatedDiff(targetName, biGroupName, []string{email}, nil)
+		}
+		return nil
+	}
+
+	isMember, err := e.isGroupMember(ctx, biClient, biGroup.ID, userID)
+	if err != nil || isMember {
+		return err
+	}
+
+	e.logger.Infof("Adding %s to group %s", email, biGroupName)
+	if err := biClient.UpdateGroupMembers(ctx, biGroup.ID, []bi.GroupMember{{Value: userID}}, nil); err != nil {
+		return fmt.Errorf("failed to update group members: %w", err)
+	}
+	result.MembershipsAdded++
+	result.recordDiff(targetName, biGroupName, []string{email}, nil)
+	e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipAdded, User: email, Group: groupEmail, Target: targetName})
+	return nil
+}
+
+// removeUserFromGroup removes the user from the Beyond Identity group if both exist
+func (e *Engine) removeUserFromGroup(ctx context.Context, biClient BIClient, email, groupEmail, targetName, biGroupName string, result *SyncResult) error {
+	biGroup, err := e.findBIGroup(ctx, biClient, targetName, biGroupName)
+	if err != nil {
+		return fmt.Errorf("failed to search for group: %w", err)
+	}
+	user, err := biClient.FindUserByEmail(ctx, email)
+	if err != nil {
+		e.addError(result, "user", email, fmt.Errorf("failed to search for user: %w", err))
+		return nil
+	}
+	if biGroup == nil || user == nil {
+		return nil
+	}
+
+	isMember, err := e.isGroupMember(ctx, biClient, biGroup.ID, user.ID)
+	if err != nil || !isMember {
+		return err
+	}
+
+	if e.simulated(config.OperationRemove) {
+		e.logger.Infof("TEST MODE: Would remove %s from group %s", email, biGroupName)
+		if !e.dryRun() {
+			result.recordSimulatedDiff(targetName, biGroupName, nil, []string{email})
+		}
+		return nil
+	}
+
+	e.logger.Infof("Removing %s from group %s", email, biGroupName)
+	if err := biClient.UpdateGroupMembers(ctx, biGroup.ID, nil, []bi.GroupMember{{Value: user.ID}}); err != nil {
+		return fmt.Errorf("failed to update group members: %w", err)
+	}
+	result.MembershipsRemoved++
+	result.recordDiff(targetName, biGroupName, nil, []string{email})
+	e.recordChange(result, report.ChangeRecord{Action: state.ChangeMembershipRemoved, User: email, Group: groupEmail, Target: targetName})
+	return nil
+}
+
+// isGroupMember reports whether a user ID is a current member of a Beyond Identity group
+func (e *Engine) isGroupMember(ctx context.Context, biClient BIClient, groupID, userID string) (bool, error) {
+	group, err := biClient.GetGroupWithMembers(ctx, groupID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get current group members: %w", err)
+	}
+	for _, member := range group.Members {
+		if member.Value == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
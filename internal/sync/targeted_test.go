@@ -0,0 +1,150 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/sirupsen/logrus"
+)
+
+func newTargetedTestEngine() (*Engine, *mockGWSClient, *mockBIClient) {
+	gwsClient := &mockGWSClient{
+		groups: map[string]*gws.Group{
+			"eng@example.com":   {Name: "Engineering"},
+			"sales@example.com": {Name: "Sales"},
+		},
+		members: map[string][]*gws.GroupMember{
+			"eng@example.com": {
+				{Email: "alice@example.com", Type: "USER", Status: "ACTIVE"},
+				{Email: "bob@example.com", Type: "USER", Status: "ACTIVE"},
+			},
+			"sales@example.com": {{Email: "carol@example.com", Type: "USER", Status: "ACTIVE"}},
+		},
+	}
+	biClient := &mockBIClient{groups: make(map[string]*bi.Group), users: make(map[string]*bi.User)}
+
+	cfg := &config.Config{
+		BeyondIdentity: config.BeyondIdentityConfig{GroupPrefix: "GWS_"},
+		Sync:           config.SyncConfig{Groups: []string{"eng@example.com", "sales@example.com"}},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	return NewEngine(gwsClient, biClient, cfg, logger), gwsClient, biClient
+}
+
+func TestSyncGroups(t *testing.T) {
+	engine, _, biClient := newTargetedTestEngine()
+
+	result, err := engine.SyncGroups(context.Background(), []string{"Sales@example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.GroupsProcessed != 1 {
+		t.Errorf("Expected 1 group processed, got %d", result.GroupsProcessed)
+	}
+	if group, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering"); group != nil {
+		t.Error("Expected Engineering not to be synced")
+	}
+
+	if _, err := engine.SyncGroups(context.Background(), []string{"other@example.com"}); err == nil {
+		t.Error("Expected error for a group that is not configured")
+	}
+}
+
+func TestSyncUser(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+
+	result, err := engine.SyncUser(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.UsersCreated != 1 || result.MembershipsAdded != 1 || len(result.Errors) != 0 {
+		t.Errorf("Expected alice to be created and added once, got %+v", result)
+	}
+	if user, _ := biClient.FindUserByEmail(context.Background(), "bob@example.com"); user != nil {
+		t.Error("Expected other members of the group to be left alone")
+	}
+
+	eng, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	if eng == nil || len(eng.Members) != 1 {
+		t.Fatalf("Expected alice in GWS_Engineering, got %+v", eng)
+	}
+
+	// A repeated sync is a no-op
+	result, err = engine.SyncUser(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.UsersCreated != 0 || result.MembershipsAdded != 0 {
+		t.Errorf("Expected no changes on a repeated sync, got %+v", result)
+	}
+
+	// Leaving the source group removes the membership
+	gwsClient.members["eng@example.com"] = gwsClient.members["eng@example.com"][1:]
+	result, err = engine.SyncUser(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.MembershipsRemoved != 1 || len(eng.Members) != 0 {
+		t.Errorf("Expected alice to be removed from GWS_Engineering, got %+v", result)
+	}
+}
+
+func TestProvisionUser(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+
+	if _, err := engine.ProvisionUser(context.Background(), "alice@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Provisioning never removes memberships
+	gwsClient.members["eng@example.com"] = gwsClient.members["eng@example.com"][1:]
+	gwsClient.members["sales@example.com"] = append(gwsClient.members["sales@example.com"],
+		&gws.GroupMember{Email: "alice@example.com", Type: "USER", Status: "ACTIVE"})
+
+	result, err := engine.ProvisionUser(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Groups) != 1 || result.Groups[0] != "sales@example.com" {
+		t.Errorf("Expected alice to be reported in sales only, got %v", result.Groups)
+	}
+	if result.MembershipsAdded != 1 || result.MembershipsRemoved != 0 {
+		t.Errorf("Expected one membership added and none removed, got %+v", result.SyncResult)
+	}
+
+	eng, _ := biClient.FindGroupByDisplayName(context.Background(), "GWS_Engineering")
+	if eng == nil || len(eng.Members) != 1 {
+		t.Errorf("Expected alice to stay in GWS_Engineering, got %+v", eng)
+	}
+}
+
+func TestOnSyncFinished(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+
+	var finished []*SyncResult
+	engine.OnSyncFinished(func(result *SyncResult, err error) {
+		finished = append(finished, result)
+	})
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(finished) != 1 || finished[0] != result {
+		t.Fatalf("Expected the handler to receive the run's result once, got %d calls", len(finished))
+	}
+
+	// Targeted runs are not reported
+	if _, err := engine.SyncGroups(context.Background(), []string{"sales@example.com"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(finished) != 1 {
+		t.Errorf("Expected targeted runs not to call the handler, got %d calls", len(finished))
+	}
+}
@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/httpclient"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/okta"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// NewPrimaryClient creates the provisioning client for the primary target: the Beyond Identity
+// tenant of the beyond_identity section, or the SCIM service of the target section
+func NewPrimaryClient(cfg *config.Config, httpClient *http.Client) *bi.Client {
+	if cfg.PrimaryTargetType() == config.TargetTypeGenericSCIM {
+		return newGenericSCIMClient(cfg.Target, httpClient)
+	}
+	client := bi.NewClientWithHTTPClient(cfg.BeyondIdentity.APIToken, cfg.BeyondIdentity.SCIMBaseURL, cfg.BeyondIdentity.NativeAPIURL, httpClient)
+	client.SetPatchFallback(cfg.BeyondIdentity.PatchFallback)
+	client.SetLookupCache(cfg.BeyondIdentity.LookupCacheDuration())
+	return client
+}
+
+// NewTargetClient creates the provisioning client for an additional target block
+func NewTargetClient(target config.TargetConfig, httpClient *http.Client) (BIClient, error) {
+	switch target.Type {
+	case "", config.TargetTypeBeyondIdentity:
+		client := bi.NewClientWithHTTPClient(target.APIToken, target.SCIMBaseURL, target.NativeAPIURL, httpClient)
+		client.SetPatchFallback(target.PatchFallback)
+		client.SetLookupCache(target.LookupCacheDuration())
+		return client, nil
+	case config.TargetTypeOkta:
+		if target.Auth == config.OktaAuthOAuth {
+			return okta.NewOAuthClient(target.OrgURL, target.ClientID, target.ClientSecret, httpClient), nil
+		}
+		return okta.NewClient(target.OrgURL, target.APIToken, httpClient), nil
+	case config.TargetTypeGenericSCIM:
+		return newGenericSCIMClient(target, httpClient), nil
+	default:
+		return nil, fmt.Errorf("unsupported target type: %s", target.Type)
+	}
+}
+
+// RegisterTargets creates and registers clients for every additional target in the configuration
+func (e *Engine) RegisterTargets(httpClient *http.Client) error {
+	for _, target := range e.config.Targets {
+		client, err := NewTargetClient(target, httpClient)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		e.AddTarget(target.Name, client)
+	}
+	return nil
+}
+
+// newGenericSCIMClient creates a SCIM client for a SCIM 2.0 service other than Beyond Identity. It
+// has no Native API, so enrollment status is never known for its users
+func newGenericSCIMClient(target config.TargetConfig, httpClient *http.Client) *bi.Client {
+	var client *bi.Client
+	switch target.Auth {
+	case config.SCIMAuthBasic:
+		client = bi.NewClientWithHTTPClient("", target.SCIMBaseURL, "", httpClient)
+		credentials := base64.StdEncoding.EncodeToString([]byte(target.Username + ":" + target.Password))
+		client.SetAuthorization("Basic " + credentials)
+	case config.SCIMAuthOAuth:
+		cc := &clientcredentials.Config{
+			ClientID:     target.ClientID,
+			ClientSecret: target.ClientSecret,
+			TokenURL:     target.TokenURL,
+			Scopes:       target.Scopes,
+		}
+		ctx := httpclient.OAuthContext(httpClient)
+		oauthClient := cc.Client(ctx)
+		oauthClient.Timeout = httpClient.Timeout
+
+		client = bi.NewClientWithHTTPClient("", target.SCIMBaseURL, "", oauthClient)
+		client.SetAuthorization("")
+	default:
+		client = bi.NewClientWithHTTPClient(target.APIToken, target.SCIMBaseURL, "", httpClient)
+	}
+
+	client.SetPatchFallback(target.PatchFallback)
+	if target.MembershipUpdates == config.MembershipUpdatesPut {
+		client.SetPutForGroups()
+	}
+	client.SetRemoveMembersByValue(target.MemberRemoval == config.MemberRemovalValue)
+	client.SetLookupCache(target.LookupCacheDuration())
+	return client
+}
@@ -0,0 +1,154 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// scimRequest is a request received by the fake SCIM service
+type scimRequest struct {
+	Method        string
+	Path          string
+	Authorization string
+	Body          string
+}
+
+// newFakeSCIMService serves a group g1 with members u1 and u2 and a user alice, recording every request
+func newFakeSCIMService(t *testing.T) (*httptest.Server, *[]scimRequest) {
+	t.Helper()
+	var requests []scimRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, scimRequest{Method: r.Method, Path: r.URL.Path, Authorization: r.Header.Get("Authorization"), Body: string(body)})
+
+		w.Header().Set("Content-Type", "application/scim+json")
+		switch {
+		case r.URL.Path == "/oauth/token":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token":"scim-token","token_type":"Bearer","expires_in":3600}`)
+		case r.Method == "GET" && r.URL.Path == "/scim/v2/Groups/g1":
+			w.Header().Set("ETag", `W/"1"`)
+			fmt.Fprint(w, `{"id":"g1","displayName":"Engineering","members":[{"value":"u1"},{"value":"u2"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/scim/v2/Users":
+			fmt.Fprint(w, `{"totalResults":1,"Resources":[{"id":"u1","userName":"alice@example.com","active":true}]}`)
+		case r.Method == "PATCH" || r.Method == "PUT":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"detail":"not found","status":"404"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestNewTargetClient_GenericSCIM(t *testing.T) {
+	tests := []struct {
+		name          string
+		target        config.TargetConfig
+		authorization string
+		method        string
+		body          string
+	}{
+		{
+			name:          "basic auth removing members by value",
+			target:        config.TargetConfig{Auth: config.SCIMAuthBasic, Username: "sync", Password: "secret", MemberRemoval: config.MemberRemovalValue},
+			authorization: "Basic c3luYzpzZWNyZXQ=",
+			method:        "PATCH",
+			body:          `{"op":"remove","path":"members","value":[{"value":"u2"}]}`,
+		},
+		{
+			name:          "bearer token with PUT membership updates",
+			target:        config.TargetConfig{APIToken: "token", MembershipUpdates: config.MembershipUpdatesPut},
+			authorization: "Bearer token",
+			method:        "PUT",
+			body:          `"members":[{"value":"u1"},{"value":"u3"}]`,
+		},
+		{
+			name:          "oauth client credentials",
+			target:        config.TargetConfig{Auth: config.SCIMAuthOAuth, ClientID: "id", ClientSecret: "secret"},
+			authorization: "Bearer scim-token",
+			method:        "PATCH",
+			body:          `{"op":"remove","path":"members[value eq \"u2\"]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newFakeSCIMService(t)
+			target := tt.target
+			target.Type = config.TargetTypeGenericSCIM
+			target.SCIMBaseURL = server.URL + "/scim/v2"
+			target.TokenURL = server.URL + "/oauth/token"
+
+			client, err := NewTargetClient(target, server.Client())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			err = client.UpdateGroupMembers(context.Background(), "g1", []bi.GroupMember{{Value: "u3"}}, []bi.GroupMember{{Value: "u2"}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var write *scimRequest
+			for i := range *requests {
+				if request := &(*requests)[i]; request.Method == tt.method {
+					write = request
+				}
+			}
+			if write == nil {
+				t.Fatalf("Expected a %s request, got %+v", tt.method, *requests)
+			}
+			if write.Authorization != tt.authorization {
+				t.Errorf("Expected Authorization %q, got %q", tt.authorization, write.Authorization)
+			}
+			if !strings.Contains(write.Body, tt.body) {
+				t.Errorf("Expected the body to contain %s, got %s", tt.body, write.Body)
+			}
+			if !json.Valid([]byte(write.Body)) {
+				t.Errorf("Expected a JSON body, got %s", write.Body)
+			}
+
+			// Generic SCIM services have no Native API to read enrollment from
+			if _, err := client.GetUserStatus(context.Background(), "alice@example.com"); !errors.Is(err, bi.ErrNativeAPIUnavailable) {
+				t.Errorf("Expected enrollment status to be unavailable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewPrimaryClient_GenericSCIM(t *testing.T) {
+	server, requests := newFakeSCIMService(t)
+	cfg := &config.Config{Target: config.TargetConfig{
+		Type:        config.TargetTypeGenericSCIM,
+		SCIMBaseURL: server.URL + "/scim/v2",
+		APIToken:    "token",
+		GroupPrefix: "Apps_",
+	}}
+	cfg.SetDefaults()
+	if got := cfg.GroupPrefixForTarget(config.DefaultTargetName); got != "Apps_" {
+		t.Errorf("Expected the target's group prefix for the primary groups, got %q", got)
+	}
+
+	client := NewPrimaryClient(cfg, server.Client())
+	user, err := client.FindUserByEmail(context.Background(), "alice@example.com")
+	if err != nil || user == nil || user.ID != "u1" {
+		t.Fatalf("Expected alice to be found in the SCIM service, got %+v and %v", user, err)
+	}
+	if len(*requests) != 1 || (*requests)[0].Authorization != "Bearer token" {
+		t.Errorf("Expected one request with the target's token, got %+v", *requests)
+	}
+	if _, err := client.GetUserStatus(context.Background(), "alice@example.com"); !errors.Is(err, bi.ErrNativeAPIUnavailable) {
+		t.Errorf("Expected no Native API for a generic SCIM primary target, got %v", err)
+	}
+}
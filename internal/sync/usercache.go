@@ -0,0 +1,160 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	gosync "sync"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+)
+
+// biUserLister is implemented by Beyond Identity clients that can list every user of a target
+type biUserLister interface {
+	ListUsers(ctx context.Context) ([]bi.User, error)
+}
+
+// biUserCache holds the users of each target for a run with the user_prefetch flag. A target's
+// users are listed the first time one of them is looked up, so members are found in memory
+// instead of with one filtered request each. Users the run writes are marked stale and read again
+type biUserCache struct {
+	mu      gosync.Mutex
+	targets map[string]*biUserListing // Target -> its users, nil when they could not be listed
+}
+
+// biUserListing is the users of one target as listed at the start of its lookups
+type biUserListing struct {
+	byEmail      map[string]*bi.User // Lower-cased userName -> user
+	byExternalID map[string]*bi.User
+	stale        map[string]bool // Lower-cased emails and IDs of users written since the listing
+}
+
+// cachedBIUsers returns the run's user cache once the users of targetName are listed, or nil when
+// lookups must be made one at a time: without the user_prefetch flag, outside runs, for targets
+// that cannot list users, or when listing them failed
+func (e *Engine) cachedBIUsers(ctx context.Context, biClient BIClient, targetName string, result *SyncResult) *biUserCache {
+	if result == nil || result.biUsers == nil || !e.config.FeatureEnabled(config.FeatureUserPrefetch) {
+		return nil
+	}
+	lister, ok := biClient.(biUserLister)
+	if !ok {
+		return nil
+	}
+
+	cache := result.biUsers
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.targets == nil {
+		cache.targets = make(map[string]*biUserListing)
+	}
+	listing, listed := cache.targets[targetName]
+	if !listed {
+		users, err := lister.ListUsers(ctx)
+		if err != nil {
+			e.logger.Warnf("Failed to list users of target %s, looking them up one at a time: %v", targetName, err)
+		} else {
+			listing = newBIUserListing(users)
+			e.logger.Debugf("Listed %d users of target %s for lookups", len(users), targetName)
+		}
+		cache.targets[targetName] = listing
+	}
+	if listing == nil {
+		return nil
+	}
+	return cache
+}
+
+func newBIUserListing(users []bi.User) *biUserListing {
+	listing := &biUserListing{
+		byEmail:      make(map[string]*bi.User, len(users)),
+		byExternalID: make(map[string]*bi.User, len(users)),
+		stale:        make(map[string]bool),
+	}
+	for i := range users {
+		user := &users[i]
+		listing.byEmail[strings.ToLower(user.UserName)] = user
+		if user.ExternalID != "" {
+			listing.byExternalID[user.ExternalID] = user
+		}
+	}
+	return listing
+}
+
+// userByEmail returns the listed user with email, nil when there is none. ok is false when the
+// user was written since the listing, so the listing cannot answer
+func (c *biUserCache) userByEmail(targetName, email string) (user *bi.User, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	listing := c.targets[targetName]
+	if listing.stale[strings.ToLower(email)] {
+		return nil, false
+	}
+	return listing.fresh(listing.byEmail[strings.ToLower(email)])
+}
+
+// userByExternalID returns the listed user with externalID, as userByEmail
+func (c *biUserCache) userByExternalID(targetName, externalID string) (user *bi.User, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	listing := c.targets[targetName]
+	return listing.fresh(listing.byExternalID[externalID])
+}
+
+// fresh returns a copy of a listed user, or ok false when the user was written since the listing
+func (l *biUserListing) fresh(user *bi.User) (*bi.User, bool) {
+	if user == nil {
+		return nil, true
+	}
+	if l.stale[user.ID] {
+		return nil, false
+	}
+	copied := *user
+	return &copied, true
+}
+
+// forget marks a user the run created or updated as stale, so later lookups read them again
+func (c *biUserCache) forget(targetName, email, userID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	listing := c.targets[targetName]
+	if listing == nil {
+		return
+	}
+	listing.stale[strings.ToLower(email)] = true
+	if userID != "" {
+		listing.stale[userID] = true
+	}
+}
+
+// lookupRunBIUser is lookupBIUser answered from the run's user cache when it can be
+func (e *Engine) lookupRunBIUser(ctx context.Context, biClient BIClient, targetName, email string, result *SyncResult) (*bi.User, error) {
+	if cache := e.cachedBIUsers(ctx, biClient, targetName, result); cache != nil {
+		if user, ok := cache.userByEmail(targetName, email); ok {
+			if user != nil {
+				e.logger.Debugf("Found existing user: %s (ID: %s)", email, user.ID)
+			}
+			return user, nil
+		}
+	}
+	return e.lookupBIUser(ctx, biClient, email)
+}
+
+// findRunBIUserByExternalID looks a user up by externalId, from the run's user cache when it can be
+func (e *Engine) findRunBIUserByExternalID(ctx context.Context, finder externalIDFinder, biClient BIClient, targetName, externalID string, result *SyncResult) (*bi.User, error) {
+	if cache := e.cachedBIUsers(ctx, biClient, targetName, result); cache != nil {
+		if user, ok := cache.userByExternalID(targetName, externalID); ok {
+			return user, nil
+		}
+	}
+	return finder.FindUserByExternalID(ctx, externalID)
+}
+
+// userWritten drops a user the run created or updated from its user cache
+func (r *SyncResult) userWritten(targetName, email, userID string) {
+	if r != nil {
+		r.biUsers.forget(targetName, email, userID)
+	}
+}
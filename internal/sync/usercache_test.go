@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// listingBIClient counts the listings and email lookups made against a mock target
+type listingBIClient struct {
+	*mockBIClient
+	lists   int
+	lookups int
+}
+
+func (c *listingBIClient) ListUsers(ctx context.Context) ([]bi.User, error) {
+	c.lists++
+	var users []bi.User
+	for _, user := range c.users {
+		users = append(users, *user)
+	}
+	return users, nil
+}
+
+func (c *listingBIClient) FindUserByEmail(ctx context.Context, email string) (*bi.User, error) {
+	c.lookups++
+	return c.mockBIClient.FindUserByEmail(ctx, email)
+}
+
+func TestSync_UserPrefetch(t *testing.T) {
+	engine, gwsClient, biClient := newTargetedTestEngine()
+	client := &listingBIClient{mockBIClient: biClient}
+	engine.biClient = client
+	engine.config.Features = map[string]bool{config.FeatureUserPrefetch: true}
+	biClient.users["existing-1"] = &bi.User{
+		ID:         "existing-1",
+		ExternalID: "alice@example.com",
+		UserName:   "alice@example.com",
+		Emails:     []bi.Email{{Value: "alice@example.com", Primary: true}},
+		Active:     true,
+	}
+	// Carol is in both groups, so she is created in the first and looked up again in the second
+	gwsClient.members["eng@example.com"] = append(gwsClient.members["eng@example.com"], &gws.GroupMember{Email: "carol@example.com", Type: "USER", Status: "ACTIVE"})
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.lists != 1 {
+		t.Errorf("Expected the target's users to be listed once, got %d listings", client.lists)
+	}
+	if client.lookups != 1 {
+		t.Errorf("Expected only the user created during the run to be looked up, got %d lookups", client.lookups)
+	}
+	if result.UsersCreated != 2 || len(biClient.users) != 3 {
+		t.Errorf("Expected bob and carol to be created once each, got %d created and %d users", result.UsersCreated, len(biClient.users))
+	}
+
+	// Without the flag every member is looked up
+	engine.config.Features = nil
+	client.lists, client.lookups = 0, 0
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.lists != 0 || client.lookups != 4 {
+		t.Errorf("Expected 4 lookups and no listing without user_prefetch, got %d lookups and %d listings", client.lookups, client.lists)
+	}
+}
@@ -0,0 +1,156 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/report"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+// userUpdater is implemented by clients that can replace a user's attributes
+type userUpdater interface {
+	UpdateUser(ctx context.Context, userID string, user *bi.User) (*bi.User, error)
+}
+
+// updateBIUser brings an existing user's attributes back in line with Google Workspace when
+// the user_updates flag is enabled. A failed update is reported as a sync error, but the user
+// still exists, so the run carries on with their memberships
+func (e *Engine) updateBIUser(ctx context.Context, biClient BIClient, targetName, email string, existing *bi.User, result *SyncResult) {
+	if !e.config.FeatureEnabled(config.FeatureUserUpdates) {
+		return
+	}
+	updater, ok := biClient.(userUpdater)
+	if !ok {
+		return
+	}
+
+	desired, err := e.newBIUser(ctx, email, result)
+	if err != nil {
+		e.logger.Errorf("Failed to compare user %s: %v", email, err)
+		e.addError(result, "user", email, err)
+		return
+	}
+
+	updated, drift := driftedUser(existing, desired)
+	if len(drift) == 0 {
+		result.explain(email, targetName, report.ExplainStageAttributes, "unchanged: display name, emails and mapped attributes match")
+		return
+	}
+	result.explain(email, targetName, report.ExplainStageAttributes, "drifted: %s", strings.Join(drift, "; "))
+
+	if e.simulated(config.OperationUpdate) {
+		e.logger.Infof("TEST MODE: Would update user '%s': %s", email, strings.Join(drift, "; "))
+		result.explain(email, targetName, report.ExplainStageAction, "would update the user (test mode)")
+		e.recordChange(result, report.ChangeRecord{Action: state.ChangeUserUpdated, User: email, ID: existing.ID, Target: targetName, Planned: true})
+		return
+	}
+
+	e.logger.Infof("Updating user %s: %s", email, strings.Join(drift, "; "))
+	err = e.retry(ctx, func() error {
+		_, err := updater.UpdateUser(ctx, existing.ID, updated)
+		return err
+	})
+	if err != nil {
+		e.logger.Errorf("Failed to update user %s: %v", email, err)
+		e.addError(result, "user", email, fmt.Errorf("failed to update user: %w", err))
+		e.recordFailedChange(result, report.ChangeRecord{Action: state.ChangeUserUpdated, User: email, ID: existing.ID, Target: targetName}, err)
+		result.explain(email, targetName, report.ExplainStageAction, "update failed: %v", err)
+		return
+	}
+
+	result.UsersUpdated++
+	result.userWritten(targetName, email, existing.ID)
+	e.recordChange(result, report.ChangeRecord{Action: state.ChangeUserUpdated, User: email, ID: existing.ID, Target: targetName})
+	result.explain(email, targetName, report.ExplainStageAction, "updated the user (ID: %s)", existing.ID)
+}
+
+// driftedUser returns existing with the attributes desired sets applied, and a description of
+// each attribute that differed. Attributes desired leaves empty are kept, so values managed
+// outside the sync, e.g. a title without an attribute mapping, are not cleared. The active flag is
+// kept too: users are deactivated on purpose, e.g. by POST /users/deprovision, and never by a sync
+func driftedUser(existing, desired *bi.User) (*bi.User, []string) {
+	updated := *existing
+	updated.Groups = nil // Read-only; memberships are managed through groups
+	var drift []string
+
+	changed := func(attribute, from, to string) {
+		drift = append(drift, fmt.Sprintf("%s %q -> %q", attribute, from, to))
+	}
+
+	if desired.DisplayName != "" && existing.DisplayName != desired.DisplayName {
+		changed("displayName", existing.DisplayName, desired.DisplayName)
+		updated.DisplayName = desired.DisplayName
+	}
+	if primary := primaryEmail(existing); !strings.EqualFold(primary, primaryEmail(desired)) {
+		changed("emails", primary, primaryEmail(desired))
+		updated.Emails = desired.Emails
+	}
+
+	if desired.Name != nil {
+		name := bi.Name{}
+		if existing.Name != nil {
+			name = *existing.Name
+		}
+		for _, field := range []struct {
+			attribute string
+			current   *string
+			desired   string
+		}{
+			{"name.givenName", &name.GivenName, desired.Name.GivenName},
+			{"name.familyName", &name.FamilyName, desired.Name.FamilyName},
+			{"name.formatted", &name.Formatted, desired.Name.Formatted},
+		} {
+			if field.desired != "" && *field.current != field.desired {
+				changed(field.attribute, *field.current, field.desired)
+				*field.current = field.desired
+			}
+		}
+		updated.Name = &name
+	}
+
+	if desired.Title != "" && existing.Title != desired.Title {
+		changed("title", existing.Title, desired.Title)
+		updated.Title = desired.Title
+	}
+
+	if desired.Enterprise != nil {
+		enterprise := bi.EnterpriseUser{}
+		if existing.Enterprise != nil {
+			enterprise = *existing.Enterprise
+		}
+		for _, field := range []struct {
+			attribute string
+			current   *string
+			desired   string
+		}{
+			{"employeeNumber", &enterprise.EmployeeNumber, desired.Enterprise.EmployeeNumber},
+			{"department", &enterprise.Department, desired.Enterprise.Department},
+			{"organization", &enterprise.Organization, desired.Enterprise.Organization},
+		} {
+			if field.desired != "" && *field.current != field.desired {
+				changed(field.attribute, *field.current, field.desired)
+				*field.current = field.desired
+			}
+		}
+		updated.Enterprise = &enterprise
+	}
+
+	return &updated, drift
+}
+
+// primaryEmail returns the user's primary email, or their first one when none is marked primary
+func primaryEmail(user *bi.User) string {
+	for _, email := range user.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(user.Emails) > 0 {
+		return user.Emails[0].Value
+	}
+	return ""
+}
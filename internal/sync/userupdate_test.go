@@ -0,0 +1,135 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/bi"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/state"
+)
+
+func (m *mockBIClient) UpdateUser(ctx context.Context, userID string, user *bi.User) (*bi.User, error) {
+	m.updates++
+	updated := *user
+	updated.ID = userID
+	m.users[userID] = &updated
+	return &updated, nil
+}
+
+// newUserUpdateTestEngine syncs once, then renames alice in the directory, so she has drifted for
+// the next run, and deactivates bob in Beyond Identity, which is not drift
+func newUserUpdateTestEngine(t *testing.T, features map[string]bool) (*Engine, *mockBIClient) {
+	t.Helper()
+	engine, gwsClient, biClient := newProfileTestEngine(nil)
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	gwsClient.users["alice@example.com"].Name = gws.UserName{GivenName: "Alice", FamilyName: "Kingsleigh", FullName: "Alice Kingsleigh"}
+	createdUsers(biClient)["bob@example.com"].Active = false
+	engine.config.Features = features
+	return engine, biClient
+}
+
+func TestSync_UserUpdates(t *testing.T) {
+	engine, biClient := newUserUpdateTestEngine(t, map[string]bool{config.FeatureUserUpdates: true})
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.UsersUpdated != 1 || biClient.updates != 1 {
+		t.Errorf("UsersUpdated = %d with %d requests, want 1 each", result.UsersUpdated, biClient.updates)
+	}
+
+	users := createdUsers(biClient)
+	alice := users["alice@example.com"]
+	if alice.DisplayName != "Alice Kingsleigh" || alice.Name == nil || alice.Name.FamilyName != "Kingsleigh" {
+		t.Errorf("alice = %+v, want renamed to Alice Kingsleigh", alice)
+	}
+	if users["bob@example.com"].Active {
+		t.Error("Expected bob to stay deactivated")
+	}
+	var updated []string
+	for _, change := range engine.state.Changes(time.Time{}, time.Time{}) {
+		if change.Action == state.ChangeUserUpdated {
+			updated = append(updated, change.User)
+		}
+	}
+	if len(updated) != 1 {
+		t.Errorf("Expected 1 user update in the change history, got %v", updated)
+	}
+
+	// Nothing drifted since, so the next run updates nobody
+	result, err = engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.UsersUpdated != 0 || biClient.updates != 1 {
+		t.Errorf("UsersUpdated = %d with %d requests, want no more updates", result.UsersUpdated, biClient.updates)
+	}
+}
+
+func TestSync_UserUpdatesNotApplied(t *testing.T) {
+	tests := []struct {
+		name     string
+		features map[string]bool
+		testMode []string
+	}{
+		{name: "flag disabled"},
+		{name: "test mode operation", features: map[string]bool{config.FeatureUserUpdates: true}, testMode: []string{config.OperationUpdate}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, biClient := newUserUpdateTestEngine(t, tt.features)
+			engine.config.Sync.TestModeOperations = tt.testMode
+
+			result, err := engine.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+			if result.UsersUpdated != 0 || biClient.updates != 0 {
+				t.Errorf("UsersUpdated = %d with %d requests, want none", result.UsersUpdated, biClient.updates)
+			}
+			if alice := createdUsers(biClient)["alice@example.com"]; alice.DisplayName != "Alice Liddell" {
+				t.Errorf("Expected alice to keep her name, got %q", alice.DisplayName)
+			}
+		})
+	}
+}
+
+func TestDriftedUser_KeepsUnmappedAttributes(t *testing.T) {
+	existing := &bi.User{
+		ID:          "user-1",
+		DisplayName: "Alice",
+		Active:      true,
+		Emails:      []bi.Email{{Value: "Alice@example.com", Primary: true}},
+		Title:       "Engineer",
+		Enterprise:  &bi.EnterpriseUser{Department: "R&D", EmployeeNumber: "42"},
+		Groups:      []bi.UserGroup{{Value: "group-1"}},
+	}
+	desired := &bi.User{
+		DisplayName: "Alice",
+		Active:      true,
+		Emails:      []bi.Email{{Value: "alice@example.com", Primary: true}},
+		Enterprise:  &bi.EnterpriseUser{Department: "Research"},
+	}
+
+	updated, drift := driftedUser(existing, desired)
+	if len(drift) != 1 {
+		t.Fatalf("Expected only the department to drift, got %v", drift)
+	}
+	if updated.Enterprise.Department != "Research" || updated.Enterprise.EmployeeNumber != "42" || updated.Title != "Engineer" {
+		t.Errorf("Expected only the department replaced, got %+v %+v", updated, updated.Enterprise)
+	}
+	if updated.Groups != nil {
+		t.Errorf("Expected read-only groups to be left out of the update, got %+v", updated.Groups)
+	}
+	if existing.Enterprise.Department != "R&D" {
+		t.Error("Expected the existing user not to be modified")
+	}
+}
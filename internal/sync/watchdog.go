@@ -0,0 +1,95 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"time"
+)
+
+// StuckRun describes a run still in progress after sync.max_expected_duration
+type StuckRun struct {
+	ID        string
+	Kind      string
+	Subject   string
+	StartedAt time.Time
+	Limit     time.Duration
+	Cancelled bool // The run stops at the next group or user; see sync.cancel_stuck_runs
+}
+
+// OnStuckRun sets a function called when a run exceeds sync.max_expected_duration, e.g. to alert;
+// it is called from the watchdog's goroutine while the run continues
+func (e *Engine) OnStuckRun(handler func(StuckRun)) {
+	e.onStuck = handler
+}
+
+// startWatchdog flags the run as stuck if it outlives sync.max_expected_duration and returns the
+// function stopping the watchdog when the run finishes
+func (e *Engine) startWatchdog(run StuckRun) func() {
+	e.cancelled.Store(false)
+
+	limit := e.config.Sync.WatchdogTimeout()
+	if limit == 0 {
+		return func() {}
+	}
+	run.Limit = limit
+	timer := time.AfterFunc(limit, func() { e.runStuck(run) })
+	return func() { timer.Stop() }
+}
+
+// runStuck logs a goroutine dump showing where the run is blocked, cancels it when configured and
+// calls the OnStuckRun handler
+func (e *Engine) runStuck(run StuckRun) {
+	run.Cancelled = e.config.Sync.CancelStuckRuns
+	if run.Cancelled {
+		e.cancelled.Store(true)
+	}
+
+	var dump bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&dump, 1)
+
+	action := "it continues"
+	if run.Cancelled {
+		action = "it will be cancelled at the next group or user"
+	}
+	e.logger.Errorf("Watchdog: %s run %s started at %s is still running after %s; %s. Goroutine dump:\n%s",
+		run.Kind, run.ID, run.StartedAt.Format(time.RFC3339), run.Limit, action, dump.String())
+
+	if e.onStuck != nil {
+		e.onStuck(run)
+	}
+}
+
+// runCancelled aborts the run once its context is done, the watchdog has cancelled it or an
+// emergency stop is in effect, and reports whether it is aborted
+func (e *Engine) runCancelled(ctx context.Context, result *SyncResult) bool {
+	if result.Aborted {
+		return true
+	}
+	if ctx.Err() != nil {
+		result.Aborted = true
+		result.AbortReason = fmt.Sprintf("cancelled: %v", context.Cause(ctx))
+	} else if e.cancelled.Load() {
+		result.Aborted = true
+		result.AbortReason = fmt.Sprintf("cancelled by the watchdog after sync.max_expected_duration of %s", e.config.Sync.WatchdogTimeout())
+	} else if stop := e.EmergencyStopped(); stop != nil {
+		result.Aborted = true
+		result.AbortReason = fmt.Sprintf("emergency stop: %s", stop)
+	}
+	return result.Aborted
+}
+
+// ErrSyncTimedOut is the cause of runs cancelled after sync.timeout_seconds
+var ErrSyncTimedOut = errors.New("sync timed out")
+
+// runContext derives the context of a run, cancelled after sync.timeout_seconds when it is set.
+// Unlike the watchdog, the timeout also aborts API requests in flight
+func (e *Engine) runContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := e.config.Sync.Timeout()
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after sync.timeout_seconds of %s", ErrSyncTimedOut, timeout))
+}
@@ -0,0 +1,130 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/gws"
+)
+
+// slowGWSClient takes longer than the watchdog allows to list members
+type slowGWSClient struct {
+	*mockGWSClient
+	delay time.Duration
+}
+
+func (c *slowGWSClient) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	time.Sleep(c.delay)
+	return c.mockGWSClient.GetGroupMembers(ctx, email)
+}
+
+func TestSync_Watchdog(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxExpected     string
+		cancel          bool
+		expectStuck     bool
+		expectAborted   bool
+		expectProcessed int
+	}{
+		{"disabled", "", false, false, false, 2},
+		{"within the limit", "1h", false, false, false, 2},
+		{"stuck run continues", "1ms", false, true, false, 2},
+		{"stuck run cancelled", "1ms", true, true, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, gwsClient, _ := newTargetedTestEngine()
+			engine.SetSource(&slowGWSClient{mockGWSClient: gwsClient, delay: 50 * time.Millisecond})
+			engine.config.Sync.MaxExpectedDuration = tt.maxExpected
+			engine.config.Sync.CancelStuckRuns = tt.cancel
+
+			var mu gosync.Mutex
+			var stuck []StuckRun
+			engine.OnStuckRun(func(run StuckRun) {
+				mu.Lock()
+				defer mu.Unlock()
+				stuck = append(stuck, run)
+			})
+
+			result, err := engine.Sync(context.Background())
+			if tt.expectAborted != errors.Is(err, ErrSyncAborted) {
+				t.Fatalf("Expected aborted %v, got error %v", tt.expectAborted, err)
+			}
+			if result.Aborted != tt.expectAborted || result.GroupsProcessed != tt.expectProcessed {
+				t.Errorf("Expected aborted %v with %d groups processed, got %v with %d", tt.expectAborted, tt.expectProcessed, result.Aborted, result.GroupsProcessed)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if (len(stuck) == 1) != tt.expectStuck {
+				t.Fatalf("Expected stuck %v, got %d stuck runs", tt.expectStuck, len(stuck))
+			}
+			if tt.expectStuck && (stuck[0].Kind != "full" || stuck[0].Limit != time.Millisecond || stuck[0].Cancelled != tt.cancel) {
+				t.Errorf("Unexpected stuck run %+v", stuck[0])
+			}
+		})
+	}
+}
+
+func TestSync_WatchdogResetsCancellation(t *testing.T) {
+	engine, _, _ := newTargetedTestEngine()
+	engine.cancelled.Store(true)
+
+	result, err := engine.Sync(context.Background())
+	if err != nil || result.Aborted {
+		t.Fatalf("Expected a cancellation from an earlier run not to abort the next, got %v", err)
+	}
+}
+
+// blockingGWSClient lists members only once the request is cancelled, like a hung API call
+type blockingGWSClient struct {
+	*mockGWSClient
+}
+
+func (c *blockingGWSClient) GetGroupMembers(ctx context.Context, email string) ([]*gws.GroupMember, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSync_ContextCancellation(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       int           // sync.timeout_seconds
+		cancelAfter   time.Duration // Cancel the caller's context after this long; 0 never
+		expectedCause string
+	}{
+		{"caller cancels", 0, 10 * time.Millisecond, "context canceled"},
+		{"timeout", 1, 0, "sync timed out after sync.timeout_seconds of 1s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, gwsClient, _ := newTargetedTestEngine()
+			engine.SetSource(&blockingGWSClient{mockGWSClient: gwsClient})
+			engine.config.Sync.TimeoutSeconds = tt.timeout
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelAfter > 0 {
+				time.AfterFunc(tt.cancelAfter, cancel)
+			}
+
+			result, err := engine.Sync(ctx)
+			if !errors.Is(err, ErrSyncAborted) {
+				t.Fatalf("Expected the run to be aborted, got %v", err)
+			}
+			if !result.Aborted || !strings.Contains(result.AbortReason, tt.expectedCause) {
+				t.Errorf("Expected an abort reason containing %q, got %q", tt.expectedCause, result.AbortReason)
+			}
+			if result.GroupsProcessed != 0 {
+				t.Errorf("Expected no groups to be processed, got %d", result.GroupsProcessed)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// Prometheus keeps counters of runs, groups and API calls and renders them in the Prometheus text
+// exposition format, served at GET /metrics/prometheus or written to a textfile after one-shot runs
+type Prometheus struct {
+	mu             sync.Mutex
+	runs           map[labels]int     // kind, outcome
+	runSeconds     map[labels]summary // kind
+	runsInProgress int
+	groups         map[labels]int     // outcome
+	apiCalls       map[labels]int     // host, status
+	apiSeconds     map[labels]summary // host
+}
+
+// labels are the label values of one series, in the order of the metric's label names
+type labels [2]string
+
+// summary accumulates observed durations
+type summary struct {
+	sum   float64
+	count int
+}
+
+func (s summary) add(d time.Duration) summary {
+	return summary{sum: s.sum + d.Seconds(), count: s.count + 1}
+}
+
+// NewPrometheus creates a Prometheus sink with no observations
+func NewPrometheus() *Prometheus {
+	return &Prometheus{
+		runs:       make(map[labels]int),
+		runSeconds: make(map[labels]summary),
+		groups:     make(map[labels]int),
+		apiCalls:   make(map[labels]int),
+		apiSeconds: make(map[labels]summary),
+	}
+}
+
+// OpStarted implements syncengine.MetricsSink
+func (p *Prometheus) OpStarted(op syncengine.Op) {
+	if op.Kind != syncengine.OpRun {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.runsInProgress++
+}
+
+// OpFinished implements syncengine.MetricsSink
+func (p *Prometheus) OpFinished(op syncengine.Op, duration time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch op.Kind {
+	case syncengine.OpRun:
+		p.runsInProgress--
+		p.runs[labels{op.Name, outcome(err)}]++
+		p.runSeconds[labels{op.Name}] = p.runSeconds[labels{op.Name}].add(duration)
+	case syncengine.OpGroup:
+		// Groups are counted by outcome only, since labelling by email would grow without bound
+		p.groups[labels{outcome(err)}]++
+	}
+}
+
+// APICall implements syncengine.MetricsSink
+func (p *Prometheus) APICall(call syncengine.APICall) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.apiCalls[labels{call.Host, statusClass(call)}]++
+	p.apiSeconds[labels{call.Host}] = p.apiSeconds[labels{call.Host}].add(call.Duration)
+}
+
+// WriteTo renders the metrics in the Prometheus text exposition format
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	var out bytes.Buffer
+	writeCounter(&out, "scim_sync_runs_total", "Sync runs by kind and outcome", []string{"kind", "outcome"}, p.runs)
+	writeSummary(&out, "scim_sync_run_duration_seconds", "Duration of sync runs by kind", "kind", p.runSeconds)
+	fmt.Fprintf(&out, "# HELP scim_sync_runs_in_progress Sync runs currently in progress\n# TYPE scim_sync_runs_in_progress gauge\nscim_sync_runs_in_progress %d\n", p.runsInProgress)
+	writeCounter(&out, "scim_sync_groups_total", "Groups synced by outcome", []string{"outcome"}, p.groups)
+	writeCounter(&out, "scim_sync_api_calls_total", "Outbound API calls by host and response status class", []string{"host", "status"}, p.apiCalls)
+	writeSummary(&out, "scim_sync_api_call_duration_seconds", "Duration of outbound API calls by host", "host", p.apiSeconds)
+	p.mu.Unlock()
+
+	return out.WriteTo(w)
+}
+
+// ServeHTTP serves the metrics to a Prometheus scraper
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+// WriteTextfile replaces path with the current metrics, for node_exporter's textfile collector,
+// which must never see a partly written file
+func (p *Prometheus) WriteTextfile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".scim-sync-*.prom")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics textfile: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := p.WriteTo(tmp); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write metrics textfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace metrics textfile %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeCounter renders a counter with one series per label set, sorted for stable output
+func writeCounter(out *bytes.Buffer, name, help string, names []string, series map[labels]int) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, key := range sortedKeys(series) {
+		fmt.Fprintf(out, "%s%s %d\n", name, formatLabels(names, key), series[key])
+	}
+}
+
+// writeSummary renders the sum and count of a summary with one label
+func writeSummary(out *bytes.Buffer, name, help, label string, series map[labels]summary) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s summary\n", name, help, name)
+	for _, key := range sortedKeys(series) {
+		l := formatLabels([]string{label}, key)
+		fmt.Fprintf(out, "%s_sum%s %g\n%s_count%s %d\n", name, l, series[key].sum, name, l, series[key].count)
+	}
+}
+
+func sortedKeys[V any](series map[labels]V) []labels {
+	keys := make([]labels, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
+
+// labelEscaper escapes label values as the text format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names []string, values labels) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+func recordRun(p *Prometheus) {
+	run := syncengine.Op{Kind: syncengine.OpRun, Name: "full"}
+	p.OpStarted(run)
+	p.OpFinished(syncengine.Op{Kind: syncengine.OpGroup, Name: "eng@example.com"}, time.Second, nil)
+	p.OpFinished(syncengine.Op{Kind: syncengine.OpGroup, Name: "sales@example.com"}, time.Second, errors.New("boom"))
+	p.APICall(syncengine.APICall{Host: "api.byndid.com", StatusCode: 200, Duration: 500 * time.Millisecond})
+	p.APICall(syncengine.APICall{Host: "api.byndid.com", StatusCode: 201, Duration: 500 * time.Millisecond})
+	p.APICall(syncengine.APICall{Host: "admin.googleapis.com", Err: errors.New("timeout")})
+	p.OpFinished(run, 3*time.Second, nil)
+}
+
+func TestPrometheus_WriteTo(t *testing.T) {
+	p := NewPrometheus()
+	recordRun(p)
+
+	var out strings.Builder
+	if _, err := p.WriteTo(&out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, line := range []string{
+		"# TYPE scim_sync_runs_total counter",
+		`scim_sync_runs_total{kind="full",outcome="success"} 1`,
+		`scim_sync_run_duration_seconds_sum{kind="full"} 3`,
+		`scim_sync_run_duration_seconds_count{kind="full"} 1`,
+		"scim_sync_runs_in_progress 0",
+		`scim_sync_groups_total{outcome="error"} 1`,
+		`scim_sync_groups_total{outcome="success"} 1`,
+		`scim_sync_api_calls_total{host="api.byndid.com",status="2xx"} 2`,
+		`scim_sync_api_calls_total{host="admin.googleapis.com",status="error"} 1`,
+		`scim_sync_api_call_duration_seconds_sum{host="api.byndid.com"} 1`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected line %q in:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestPrometheus_ServeHTTP(t *testing.T) {
+	p := NewPrometheus()
+	p.OpStarted(syncengine.Op{Kind: syncengine.OpRun, Name: "full"})
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/prometheus", nil))
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "scim_sync_runs_in_progress 1\n") {
+		t.Errorf("Expected a run in progress, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestPrometheus_WriteTextfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scim_sync.prom")
+	if err := os.WriteFile(path, []byte("stale"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPrometheus()
+	recordRun(p)
+	if err := p.WriteTextfile(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `scim_sync_runs_total{kind="full",outcome="success"} 1`) {
+		t.Errorf("Expected the run in the textfile, got:\n%s", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected the temporary file to be removed, got %d files", len(entries))
+	}
+
+	if err := p.WriteTextfile(filepath.Join(dir, "missing", "scim_sync.prom")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	got := formatLabels([]string{"host", "status"}, labels{`a"b\c`, "2xx"})
+	if want := `{host="a\"b\\c",status="2xx"}`; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// Statsd sends counters and timings to a statsd agent over UDP, e.g.
+// scim_sync.run.full.success:1|c and scim_sync.api.api_byndid_com.2xx:1|c
+type Statsd struct {
+	conn   net.Conn
+	prefix string
+}
+
+// NewStatsd creates a sink sending to the agent at address; UDP is connectionless, so an agent
+// that is not running only loses the metrics
+func NewStatsd(address, prefix string) (*Statsd, error) {
+	if address == "" {
+		return nil, errors.New("statsd address is required")
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create statsd client for %s: %w", address, err)
+	}
+	return &Statsd{conn: conn, prefix: prefix}, nil
+}
+
+// Close closes the socket
+func (s *Statsd) Close() error {
+	return s.conn.Close()
+}
+
+// OpStarted implements syncengine.MetricsSink
+func (s *Statsd) OpStarted(op syncengine.Op) {
+	if op.Kind == syncengine.OpRun {
+		s.send("run.%s.started:1|c", metricName(op.Name))
+	}
+}
+
+// OpFinished implements syncengine.MetricsSink
+func (s *Statsd) OpFinished(op syncengine.Op, duration time.Duration, err error) {
+	switch op.Kind {
+	case syncengine.OpRun:
+		s.send("run.%s.%s:1|c", metricName(op.Name), outcome(err))
+		s.send("run.%s.duration:%d|ms", metricName(op.Name), duration.Milliseconds())
+	case syncengine.OpGroup:
+		s.send("group.%s:1|c", outcome(err))
+		s.send("group.duration:%d|ms", duration.Milliseconds())
+	}
+}
+
+// APICall implements syncengine.MetricsSink
+func (s *Statsd) APICall(call syncengine.APICall) {
+	host := metricName(call.Host)
+	s.send("api.%s.%s:1|c", host, statusClass(call))
+	s.send("api.%s.duration:%d|ms", host, call.Duration.Milliseconds())
+}
+
+// send writes one metric; failures are ignored so an unavailable agent never slows a sync
+func (s *Statsd) send(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(s.conn, s.prefix+"."+format, args...)
+}
+
+// metricNameReplacer replaces the separators statsd and Graphite give meaning to
+var metricNameReplacer = strings.NewReplacer(".", "_", ":", "_", "|", "_", "@", "_", " ", "_")
+
+func metricName(name string) string {
+	return metricNameReplacer.Replace(name)
+}
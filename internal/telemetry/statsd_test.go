@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// listenStatsd starts a UDP listener standing in for a statsd agent
+func listenStatsd(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// receive reads n packets from the listener
+func receive(t *testing.T, conn net.PacketConn, n int) []string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	packets := make([]string, 0, n)
+	buf := make([]byte, 512)
+	for len(packets) < n {
+		size, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Expected %d packets, got %v: %v", n, packets, err)
+		}
+		packets = append(packets, string(buf[:size]))
+	}
+	sort.Strings(packets)
+	return packets
+}
+
+func TestStatsd(t *testing.T) {
+	agent := listenStatsd(t)
+	statsd, err := NewStatsd(agent.LocalAddr().String(), "scim_sync")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer statsd.Close()
+
+	statsd.OpStarted(syncengine.Op{Kind: syncengine.OpRun, Name: "full"})
+	statsd.OpFinished(syncengine.Op{Kind: syncengine.OpRun, Name: "full"}, 1500*time.Millisecond, nil)
+	statsd.APICall(syncengine.APICall{Host: "api.byndid.com", StatusCode: 404, Duration: 20 * time.Millisecond})
+
+	expected := []string{
+		"scim_sync.api.api_byndid_com.4xx:1|c",
+		"scim_sync.api.api_byndid_com.duration:20|ms",
+		"scim_sync.run.full.duration:1500|ms",
+		"scim_sync.run.full.started:1|c",
+		"scim_sync.run.full.success:1|c",
+	}
+	packets := receive(t, agent, len(expected))
+	for i := range expected {
+		if packets[i] != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], packets[i])
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	agent := listenStatsd(t)
+
+	tests := []struct {
+		name             string
+		cfg              config.MetricsConfig
+		expectPrometheus bool
+		expectStatsd     bool
+	}{
+		{"nothing configured", config.MetricsConfig{}, false, false},
+		{"prometheus endpoint", config.MetricsConfig{Prometheus: config.PrometheusConfig{Enabled: true}}, true, false},
+		{"textfile only", config.MetricsConfig{Prometheus: config.PrometheusConfig{TextfilePath: "/tmp/scim_sync.prom"}}, true, false},
+		{"statsd", config.MetricsConfig{Statsd: config.StatsdConfig{Address: agent.LocalAddr().String(), Prefix: "scim_sync"}}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sinks := &syncengine.MetricsSinks{}
+			prometheus, err := FromConfig(tt.cfg, sinks)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (prometheus != nil) != tt.expectPrometheus {
+				t.Errorf("Expected prometheus sink %v, got %v", tt.expectPrometheus, prometheus != nil)
+			}
+			if tt.expectStatsd {
+				sinks.APICall(syncengine.APICall{Host: "api.byndid.com", StatusCode: 200})
+				receive(t, agent, 2)
+			}
+		})
+	}
+}
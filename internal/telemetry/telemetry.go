@@ -0,0 +1,51 @@
+// Package telemetry publishes the engine's run, group and API call measurements to Prometheus
+// and statsd, and records the anonymous usage report sent when telemetry.enabled is set
+package telemetry
+
+import (
+	"strconv"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+)
+
+// Outcomes of a run or group
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+)
+
+// FromConfig registers the sinks configured under metrics and returns the Prometheus sink, or nil
+// when neither prometheus.enabled nor prometheus.textfile_path is set
+func FromConfig(cfg config.MetricsConfig, sinks *syncengine.MetricsSinks) (*Prometheus, error) {
+	if cfg.Statsd.Address != "" {
+		statsd, err := NewStatsd(cfg.Statsd.Address, cfg.Statsd.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		sinks.Add(statsd)
+	}
+
+	if !cfg.Prometheus.Enabled && cfg.Prometheus.TextfilePath == "" {
+		return nil, nil
+	}
+	prometheus := NewPrometheus()
+	sinks.Add(prometheus)
+	return prometheus, nil
+}
+
+// outcome classifies a finished operation
+func outcome(err error) string {
+	if err != nil {
+		return outcomeError
+	}
+	return outcomeSuccess
+}
+
+// statusClass groups response codes as 2xx, 4xx and so on, or "error" when there was no response
+func statusClass(call syncengine.APICall) string {
+	if call.StatusCode == 0 {
+		return outcomeError
+	}
+	return strconv.Itoa(call.StatusCode/100) + "xx"
+}
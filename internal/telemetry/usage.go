@@ -0,0 +1,299 @@
+package telemetry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/sirupsen/logrus"
+)
+
+// UsageSchemaVersion is bumped whenever a field is added to or removed from UsageReport
+const UsageSchemaVersion = 1
+
+// Modes a usage report is recorded in
+const (
+	UsageModeRun    = "run"    // One-shot scim-sync run
+	UsageModeServer = "server" // Runs of scim-sync server, scheduled or requested
+)
+
+// usageInterval is how often a report is sent; runs in between only update the local copy
+const usageInterval = 24 * time.Hour
+
+// usageTimeout bounds sending a report so an unreachable endpoint never holds up a run
+const usageTimeout = 5 * time.Second
+
+// UsageReport is the complete anonymous usage payload. It carries no names, emails, domains,
+// tenant URLs or error messages: counts are bucketed and errors are reduced to their category
+type UsageReport struct {
+	SchemaVersion      int               `json:"schema_version"`
+	InstallationID     string            `json:"installation_id"` // Random, generated on first use; identifies nothing else
+	Version            string            `json:"version"`
+	OS                 string            `json:"os"`
+	Arch               string            `json:"arch"`
+	Mode               string            `json:"mode"`      // run or server
+	Source             string            `json:"source"`    // google_workspace or csv
+	GroupAPI           string            `json:"group_api"` // admin_sdk or cloud_identity
+	Targets            int               `json:"targets"`   // Beyond Identity tenants provisioned
+	GroupsConfigured   string            `json:"groups_configured"`
+	GroupsSynced       string            `json:"groups_synced"`
+	UsersCreated       string            `json:"users_created"`
+	MembershipsAdded   string            `json:"memberships_added"`
+	MembershipsRemoved string            `json:"memberships_removed"`
+	Features           []string          `json:"features"`         // Feature flags enabled, sorted
+	Errors             map[string]string `json:"errors,omitempty"` // Error category -> bucketed count
+	Aborted            bool              `json:"aborted"`
+	TestMode           bool              `json:"test_mode"`
+}
+
+// Bucket reduces a count to a range, so reports do not reveal the exact size of a directory
+func Bucket(n int) string {
+	switch {
+	case n <= 0:
+		return "0"
+	case n < 10:
+		return "1-9"
+	case n < 100:
+		return "10-99"
+	case n < 1000:
+		return "100-999"
+	case n < 10000:
+		return "1000-9999"
+	}
+	return "10000+"
+}
+
+// usageFile is the local record of the installation ID and the latest report
+type usageFile struct {
+	InstallationID string       `json:"installation_id"`
+	LastReport     *UsageReport `json:"last_report,omitempty"`
+	LastSentAt     *time.Time   `json:"last_sent_at,omitempty"`
+}
+
+// UsagePath returns where usage reports are recorded for a state file, or "" to keep them in memory
+func UsagePath(statePath string) string {
+	if statePath == "" {
+		return ""
+	}
+	return statePath + ".usage"
+}
+
+// Usage records an anonymous usage report after every full run and, when telemetry.enabled is
+// set, sends it to telemetry.endpoint at most once a day. The report is recorded even when
+// telemetry is disabled, so scim-sync telemetry show can print it before opting in
+type Usage struct {
+	cfg     *config.Config
+	client  *http.Client
+	path    string
+	version string
+	mode    string
+	logger  *logrus.Logger
+	now     func() time.Time
+
+	mu     gosync.Mutex
+	memory usageFile // Used when there is no state file
+}
+
+// NewUsage creates the usage recorder for a configuration
+func NewUsage(cfg *config.Config, httpClient *http.Client, version, mode string, logger *logrus.Logger) *Usage {
+	return &Usage{
+		cfg:     cfg,
+		client:  httpClient,
+		path:    UsagePath(cfg.Sync.StatePath),
+		version: version,
+		mode:    mode,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// RunFinished records the report for a finished run and sends it when due; it has the signature
+// of syncengine.Engine.OnSyncFinished. Failures are logged and never affect the run
+func (u *Usage) RunFinished(result *syncengine.SyncResult, _ error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	file, err := u.load()
+	if err != nil {
+		u.logger.Debugf("Skipping usage report: %v", err)
+		return
+	}
+	report := u.report(file.InstallationID, result)
+	file.LastReport = &report
+
+	now := u.now()
+	if u.cfg.Telemetry.Enabled && (file.LastSentAt == nil || now.Sub(*file.LastSentAt) >= usageInterval) {
+		if err := u.send(report); err != nil {
+			u.logger.Debugf("Failed to send usage report: %v", err)
+		} else {
+			file.LastSentAt = &now
+			u.logger.Debug("Sent anonymous usage report")
+		}
+	}
+
+	if err := u.save(file); err != nil {
+		u.logger.Debugf("Failed to record usage report: %v", err)
+	}
+}
+
+// Preview returns the report recorded after the last run, exactly as it was or would have been
+// sent; before the first run, it returns one built from the configuration alone
+func (u *Usage) Preview() (UsageReport, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	file, err := u.load()
+	if err != nil {
+		return UsageReport{}, err
+	}
+	if file.LastReport != nil {
+		return *file.LastReport, nil
+	}
+	if err := u.save(file); err != nil {
+		return UsageReport{}, err
+	}
+	return u.report(file.InstallationID, nil), nil
+}
+
+// report builds the payload for a run; a nil result reports no activity
+func (u *Usage) report(installationID string, result *syncengine.SyncResult) UsageReport {
+	report := UsageReport{
+		SchemaVersion:    UsageSchemaVersion,
+		InstallationID:   installationID,
+		Version:          u.version,
+		OS:               runtime.GOOS,
+		Arch:             runtime.GOARCH,
+		Mode:             u.mode,
+		Source:           u.cfg.Source.Type,
+		GroupAPI:         u.cfg.GoogleWorkspace.API,
+		Targets:          1 + len(u.cfg.Targets),
+		GroupsConfigured: Bucket(len(u.cfg.Sync.Groups)),
+		Features:         []string{},
+		TestMode:         u.cfg.App.TestMode,
+	}
+	for _, feature := range u.cfg.FeatureStates() {
+		if feature.Enabled {
+			report.Features = append(report.Features, feature.Name)
+		}
+	}
+
+	if result == nil {
+		result = &syncengine.SyncResult{}
+	}
+	report.GroupsSynced = Bucket(result.GroupsProcessed)
+	report.UsersCreated = Bucket(result.UsersCreated)
+	report.MembershipsAdded = Bucket(result.MembershipsAdded)
+	report.MembershipsRemoved = Bucket(result.MembershipsRemoved)
+	report.Aborted = result.Aborted
+	report.Errors = errorCategories(result)
+	return report
+}
+
+// errorCategories counts a run's errors by kind and class, e.g. user.transient, dropping the
+// messages and subjects
+func errorCategories(result *syncengine.SyncResult) map[string]string {
+	counts := make(map[string]int)
+	for _, group := range result.ErrorSummary() {
+		kind := strings.ReplaceAll(group.Kind, " ", "_")
+		if kind == "" {
+			kind = "other"
+		}
+		class := group.Class
+		if class == "" {
+			class = "unknown"
+		}
+		counts[kind+"."+class] += group.Count
+	}
+	if result.AuthErrors > 0 {
+		counts["auth"] = result.AuthErrors
+	}
+
+	if len(counts) == 0 {
+		return nil
+	}
+	categories := make(map[string]string, len(counts))
+	for category, count := range counts {
+		categories[category] = Bucket(count)
+	}
+	return categories
+}
+
+// send posts a report to the configured endpoint
+func (u *Usage) send(report UsageReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, u.cfg.Telemetry.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := *u.client
+	client.Timeout = usageTimeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, u.cfg.Telemetry.Endpoint)
+	}
+	return nil
+}
+
+// load reads the usage file, assigning an installation ID on first use
+func (u *Usage) load() (usageFile, error) {
+	file := u.memory
+	if u.path != "" {
+		file = usageFile{}
+		raw, err := os.ReadFile(u.path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return usageFile{}, fmt.Errorf("failed to read usage file: %w", err)
+		default:
+			if err := json.Unmarshal(raw, &file); err != nil {
+				return usageFile{}, fmt.Errorf("failed to parse usage file %s: %w", u.path, err)
+			}
+		}
+	}
+
+	if file.InstallationID == "" {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return usageFile{}, fmt.Errorf("failed to generate installation ID: %w", err)
+		}
+		file.InstallationID = hex.EncodeToString(id)
+	}
+	return file, nil
+}
+
+// save writes the usage file
+func (u *Usage) save(file usageFile) error {
+	if u.path == "" {
+		u.memory = file
+		return nil
+	}
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage file: %w", err)
+	}
+	if err := os.WriteFile(u.path, raw, 0600); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	syncengine "github.com/gobeyondidentity/google-workspace-provisioner/internal/sync"
+	"github.com/sirupsen/logrus"
+)
+
+func TestBucket(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{1, "1-9"},
+		{9, "1-9"},
+		{10, "10-99"},
+		{999, "100-999"},
+		{1000, "1000-9999"},
+		{25000, "10000+"},
+	}
+
+	for _, tt := range tests {
+		if got := Bucket(tt.n); got != tt.want {
+			t.Errorf("Bucket(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+// newTestUsage returns a recorder for a state file in a temporary directory, posting to endpoint
+func newTestUsage(t *testing.T, enabled bool, endpoint string) *Usage {
+	t.Helper()
+	cfg := &config.Config{
+		Sync:      config.SyncConfig{Groups: []string{"eng@acme.com", "sales@acme.com"}, StatePath: filepath.Join(t.TempDir(), "state.json")},
+		Features:  map[string]bool{config.FeatureShadowMode: true},
+		Telemetry: config.TelemetryConfig{Enabled: enabled, Endpoint: endpoint},
+	}
+	cfg.SetDefaults()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewUsage(cfg, http.DefaultClient, "v1.2.0", UsageModeRun, logger)
+}
+
+func testResult() *syncengine.SyncResult {
+	return &syncengine.SyncResult{
+		GroupsProcessed:  2,
+		UsersCreated:     14,
+		MembershipsAdded: 3,
+		Errors: []error{
+			&syncengine.SyncError{Kind: "user", Subject: "alice@acme.com", Err: errors.New("alice@acme.com is invalid")},
+			&syncengine.SyncError{Kind: "user", Subject: "bob@acme.com", Err: errors.New("bob@acme.com is invalid")},
+		},
+	}
+}
+
+func TestUsage_RunFinished(t *testing.T) {
+	var received []UsageReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report UsageReport
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("Failed to decode report: %v", err)
+		}
+		received = append(received, report)
+	}))
+	defer server.Close()
+
+	usage := newTestUsage(t, true, server.URL)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	usage.now = func() time.Time { return now }
+
+	usage.RunFinished(testResult(), nil)
+	if len(received) != 1 {
+		t.Fatalf("Expected one report to be sent, got %d", len(received))
+	}
+	report := received[0]
+	if report.InstallationID == "" || report.Version != "v1.2.0" || report.Mode != UsageModeRun {
+		t.Errorf("Unexpected report identity: %+v", report)
+	}
+	if report.GroupsConfigured != "1-9" || report.UsersCreated != "10-99" || report.MembershipsRemoved != "0" {
+		t.Errorf("Expected bucketed counts, got %+v", report)
+	}
+	if len(report.Features) != 2 || report.Features[0] != config.FeatureDeprovisioning || report.Features[1] != config.FeatureShadowMode {
+		t.Errorf("Expected the enabled feature flags, got %v", report.Features)
+	}
+	if report.Errors["user.unknown"] != "1-9" {
+		t.Errorf("Expected the errors by category, got %v", report.Errors)
+	}
+
+	// Later runs the same day are recorded but not sent
+	now = now.Add(time.Hour)
+	usage.RunFinished(&syncengine.SyncResult{}, nil)
+	if len(received) != 1 {
+		t.Errorf("Expected at most one report a day, got %d", len(received))
+	}
+	preview, err := usage.Preview()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if preview.InstallationID != report.InstallationID || preview.UsersCreated != "0" {
+		t.Errorf("Expected the latest run's report with the same installation ID, got %+v", preview)
+	}
+
+	now = now.Add(usageInterval)
+	usage.RunFinished(&syncengine.SyncResult{}, nil)
+	if len(received) != 2 {
+		t.Errorf("Expected a report once a day has passed, got %d", len(received))
+	}
+}
+
+func TestUsage_Disabled(t *testing.T) {
+	sent := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = true
+	}))
+	defer server.Close()
+
+	usage := newTestUsage(t, false, server.URL)
+	usage.RunFinished(testResult(), nil)
+	if sent {
+		t.Error("Expected nothing to be sent without telemetry.enabled")
+	}
+
+	// The report is still recorded for telemetry show, without any names or messages
+	raw, err := os.ReadFile(usage.path)
+	if err != nil {
+		t.Fatalf("Expected the report to be recorded: %v", err)
+	}
+	if strings.Contains(string(raw), "acme.com") {
+		t.Errorf("Expected no emails or domains in the report, got %s", raw)
+	}
+}
+
+func TestUsage_PreviewBeforeFirstRun(t *testing.T) {
+	usage := newTestUsage(t, false, "")
+
+	first, err := usage.Preview()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.InstallationID == "" || first.GroupsSynced != "0" || first.GroupsConfigured != "1-9" {
+		t.Errorf("Expected a report from the configuration, got %+v", first)
+	}
+
+	second, err := usage.Preview()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second.InstallationID != first.InstallationID {
+		t.Error("Expected the installation ID to be kept")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/gobeyondidentity/google-workspace-provisioner/internal/config"
+	"github.com/gobeyondidentity/google-workspace-provisioner/internal/setup"
 )
 
 // ANSI color codes
@@ -40,6 +41,11 @@ func (w *Wizard) Run() error {
 	fmt.Println("This wizard will help you set up your configuration for syncing users from Google Workspace to Beyond Identity.")
 	fmt.Println()
 
+	// Deployment mode, which decides the sections asked for below
+	if err := w.configureMode(); err != nil {
+		return fmt.Errorf("failed to configure deployment mode: %w", err)
+	}
+
 	// Application settings
 	if err := w.configureApp(); err != nil {
 		return fmt.Errorf("failed to configure app settings: %w", err)
@@ -60,9 +66,13 @@ func (w *Wizard) Run() error {
 		return fmt.Errorf("failed to configure sync settings: %w", err)
 	}
 
-	// Server settings
-	if err := w.configureServer(); err != nil {
-		return fmt.Errorf("failed to configure server settings: %w", err)
+	// Server settings, or the cron schedule of one-shot runs
+	if w.config.RunsServer() {
+		if err := w.configureServer(); err != nil {
+			return fmt.Errorf("failed to configure server settings: %w", err)
+		}
+	} else if err := w.configureOneShotSchedule(); err != nil {
+		return fmt.Errorf("failed to configure run schedule: %w", err)
 	}
 
 	// Set defaults and validate (skip API token validation if not set)
@@ -83,6 +93,28 @@ func (w *Wizard) Run() error {
 	return w.saveConfiguration()
 }
 
+// configureMode asks how the sync will be deployed
+func (w *Wizard) configureMode() error {
+	fmt.Printf("%sDeployment Mode%s\n", colorTeal, colorReset)
+	fmt.Println("═════════════════")
+	fmt.Println("How will the sync run?")
+	fmt.Println("  oneshot - 'scim-sync run' started by cron or CI; no server")
+	fmt.Println("  server  - a long-running server with an HTTP API and scheduled syncs")
+	fmt.Println("  hybrid  - the server, with one-shot runs alongside it")
+
+	for {
+		mode := strings.ToLower(w.promptWithDefault("Deployment mode (oneshot, server, hybrid)", config.DeploymentModeHybrid))
+		for _, valid := range config.DeploymentModes {
+			if mode == valid {
+				w.config.App.Mode = mode
+				fmt.Println()
+				return nil
+			}
+		}
+		fmt.Printf("%sPlease enter one of: %s%s\n", colorRed, strings.Join(config.DeploymentModes, ", "), colorReset)
+	}
+}
+
 // configureApp configures application-level settings
 func (w *Wizard) configureApp() error {
 	fmt.Printf("%sApplication Settings%s\n", colorTeal, colorReset)
@@ -137,6 +169,7 @@ func (w *Wizard) configureGoogleWorkspace() error {
 		fmt.Println("Make sure to place your service account file there before running sync.")
 	} else {
 		fmt.Println("Service account file found")
+		w.restrictKeyPermissions(keyPath)
 	}
 
 	w.config.GoogleWorkspace.ServiceAccountKeyPath = keyPath
@@ -145,6 +178,24 @@ func (w *Wizard) configureGoogleWorkspace() error {
 	return nil
 }
 
+// restrictKeyPermissions offers to make a service account key readable by its owner only
+func (w *Wizard) restrictKeyPermissions(keyPath string) {
+	issue, err := config.CheckFilePermissions(keyPath)
+	if err != nil || issue == nil {
+		return
+	}
+
+	fmt.Printf("%sWarning: %s%s\n", colorRed, issue, colorReset)
+	if !w.promptYesNo("Restrict the key to its owner (chmod 600)?", true) {
+		return
+	}
+	if err := os.Chmod(keyPath, config.SecretFileMode); err != nil {
+		fmt.Printf("%sFailed to change permissions: %v%s\n", colorRed, err, colorReset)
+		return
+	}
+	fmt.Println("Service account file permissions restricted")
+}
+
 // configureBeyondIdentity configures Beyond Identity settings
 func (w *Wizard) configureBeyondIdentity() error {
 	fmt.Printf("%sBeyond Identity Configuration%s\n", colorTeal, colorReset)
@@ -260,6 +311,18 @@ func (w *Wizard) configureServer() error {
 	return nil
 }
 
+// configureOneShotSchedule asks for the cron schedule of one-shot runs, written to the generated crontab
+func (w *Wizard) configureOneShotSchedule() error {
+	fmt.Printf("%sRun Schedule%s\n", colorTeal, colorReset)
+	fmt.Println("══════════════")
+	fmt.Println("Enter a cron schedule for 'scim-sync run', used in the generated crontab.")
+
+	w.config.Server.Schedule = w.promptWithDefault("Cron schedule", "0 */6 * * *")
+
+	fmt.Println()
+	return nil
+}
+
 // saveConfiguration saves the configuration to a file
 func (w *Wizard) saveConfiguration() error {
 	fmt.Printf("%sSave Configuration%s\n", colorTeal, colorReset)
@@ -292,12 +355,50 @@ func (w *Wizard) saveConfiguration() error {
 	fmt.Printf("Configuration saved to: %s\n", configPath)
 	fmt.Println()
 
+	// Offer ready-to-use deployment files for the saved config
+	if err := w.writeDeploymentArtifacts(configPath); err != nil {
+		fmt.Printf("%sFailed to write deployment files: %v%s\n", colorRed, err, colorReset)
+		fmt.Println()
+	}
+
 	// Show next steps
 	w.showNextSteps(configPath)
 
 	return nil
 }
 
+// writeDeploymentArtifacts writes the deployment files of the chosen mode if requested: the
+// systemd unit and docker-compose service for the server, the crontab for one-shot runs
+func (w *Wizard) writeDeploymentArtifacts(configPath string) error {
+	files := "systemd unit, docker-compose service, crontab"
+	switch w.config.App.Mode {
+	case config.DeploymentModeServer:
+		files = "systemd unit, docker-compose service"
+	case config.DeploymentModeOneShot:
+		files = "crontab"
+	}
+	if !w.promptYesNo(fmt.Sprintf("Generate deployment files (%s)?", files), false) {
+		return nil
+	}
+	outputDir := w.promptWithDefault("Deployment files directory", filepath.Join(filepath.Dir(configPath), "deploy"))
+
+	opts, err := setup.NewDeploymentOptions(w.config, configPath)
+	if err != nil {
+		return err
+	}
+	paths, err := setup.WriteDeploymentArtifacts(outputDir, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Deployment files written:")
+	for _, path := range paths {
+		fmt.Printf("   - %s\n", path)
+	}
+	fmt.Println()
+	return nil
+}
+
 // showNextSteps displays next steps for the user
 func (w *Wizard) showNextSteps(configPath string) {
 	fmt.Printf("%sSetup Complete!%s\n", colorTeal, colorReset)
@@ -314,13 +415,23 @@ func (w *Wizard) showNextSteps(configPath string) {
 
 	fmt.Println("Next steps:")
 	fmt.Println("1. Validate config:   ./scim-sync validate-config")
-	fmt.Println("2. Test sync:         ./scim-sync run")
-	fmt.Println("3. Start server:      ./scim-sync server")
+	step := 2
+	if w.config.RunsOneShot() {
+		fmt.Printf("%d. Test sync:         ./scim-sync run\n", step)
+		step++
+	}
+	if w.config.RunsServer() {
+		fmt.Printf("%d. Start server:      ./scim-sync server\n", step)
+	} else {
+		fmt.Printf("%d. Schedule runs:     add the crontab entry for './scim-sync run'\n", step)
+	}
 	fmt.Println()
 	fmt.Println("Documentation:")
 	fmt.Println("   - Run './scim-sync --help' for command options")
-	fmt.Println("   - Server API will be available at http://localhost:8080")
-	fmt.Println("   - Health check: curl http://localhost:8080/health")
+	if w.config.RunsServer() {
+		fmt.Printf("   - Server API will be available at http://localhost:%d\n", w.config.Server.Port)
+		fmt.Printf("   - Health check: curl http://localhost:%d/health\n", w.config.Server.Port)
+	}
 	fmt.Println()
 
 	if w.config.App.TestMode {
@@ -365,3 +365,97 @@ BI_TENANT_API_TOKEN = "invalid.token"`,
 		})
 	}
 }
+
+func TestConfigureMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"default", "\n", config.DeploymentModeHybrid},
+		{"one-shot", "oneshot\n", config.DeploymentModeOneShot},
+		{"retry after invalid input", "daemon\nServer\n", config.DeploymentModeServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wizard := &Wizard{
+				reader: bufio.NewReaderSize(strings.NewReader(tt.input), 8192),
+				config: &config.Config{},
+			}
+
+			if err := wizard.configureMode(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if wizard.config.App.Mode != tt.expected {
+				t.Errorf("Expected mode '%s', got '%s'", tt.expected, wizard.config.App.Mode)
+			}
+		})
+	}
+}
+
+func TestWriteDeploymentArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+
+	tests := []struct {
+		name        string
+		input       string
+		expectFiles bool
+	}{
+		{"declined", "\n", false},
+		{"default directory", "y\n\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wizard := &Wizard{
+				reader: bufio.NewReaderSize(strings.NewReader(tt.input), 8192),
+				config: &config.Config{Server: config.ServerConfig{Port: 9090}},
+			}
+
+			if err := wizard.writeDeploymentArtifacts(configPath); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			_, err := os.Stat(filepath.Join(dir, "deploy", "scim-sync.service"))
+			if tt.expectFiles != (err == nil) {
+				t.Errorf("Expected deployment files written: %v, got stat error %v", tt.expectFiles, err)
+			}
+		})
+	}
+}
+
+func TestRestrictKeyPermissions(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		expectMode os.FileMode
+	}{
+		{"accepted by default", "\n", 0600},
+		{"declined", "n\n", 0644},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyPath := filepath.Join(t.TempDir(), "key.json")
+			if err := os.WriteFile(keyPath, []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(keyPath, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			wizard := &Wizard{reader: bufio.NewReaderSize(strings.NewReader(tt.input), 8192)}
+			wizard.restrictKeyPermissions(keyPath)
+
+			info, err := os.Stat(keyPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != tt.expectMode {
+				t.Errorf("Expected mode %04o, got %04o", tt.expectMode, info.Mode().Perm())
+			}
+		})
+	}
+}